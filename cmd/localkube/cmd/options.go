@@ -28,7 +28,8 @@ import (
 
 func NewLocalkubeServer() *localkube.LocalkubeServer {
 	// net.ParseCIDR returns multiple values. Use the IPNet return value
-	_, defaultServiceClusterIPRange, _ := net.ParseCIDR(util.DefaultServiceClusterIP + "/24")
+	_, defaultServiceClusterIPRange, _ := net.ParseCIDR(util.DefaultServiceCIDR)
+	_, defaultPodCIDR, _ := net.ParseCIDR(util.DefaultPodCIDR)
 
 	return &localkube.LocalkubeServer{
 		Containerized:            false,
@@ -36,6 +37,7 @@ func NewLocalkubeServer() *localkube.LocalkubeServer {
 		DNSIP:                    net.ParseIP(util.DefaultDNSIP),
 		LocalkubeDirectory:       util.DefaultLocalkubeDirectory,
 		ServiceClusterIPRange:    *defaultServiceClusterIPRange,
+		PodCIDR:                  *defaultPodCIDR,
 		APIServerAddress:         net.ParseIP("0.0.0.0"),
 		APIServerPort:            constants.APIServerPort,
 		APIServerInsecureAddress: net.ParseIP("127.0.0.1"),
@@ -43,6 +45,7 @@ func NewLocalkubeServer() *localkube.LocalkubeServer {
 		APIServerName:            constants.APIServerName,
 		ShouldGenerateCerts:      true,
 		ShowVersion:              false,
+		StorageProvisioner:       true,
 		RuntimeConfig:            map[string]string{"api/all": "true"},
 		ExtraConfig:              util.ExtraOptionSlice{},
 	}
@@ -56,6 +59,7 @@ func AddFlags(s *localkube.LocalkubeServer) {
 	flag.IPVar(&s.DNSIP, "dns-ip", s.DNSIP, "The cluster dns IP")
 	flag.StringVar(&s.LocalkubeDirectory, "localkube-directory", s.LocalkubeDirectory, "The directory localkube will store files in")
 	flag.IPNetVar(&s.ServiceClusterIPRange, "service-cluster-ip-range", s.ServiceClusterIPRange, "The service-cluster-ip-range for the apiserver")
+	flag.IPNetVar(&s.PodCIDR, "pod-network-cidr", s.PodCIDR, "The range of the pods' IPs")
 	flag.IPVar(&s.APIServerAddress, "apiserver-address", s.APIServerAddress, "The address the apiserver will listen securely on")
 	flag.IntVar(&s.APIServerPort, "apiserver-port", s.APIServerPort, "The port the apiserver will listen securely on")
 	flag.IPVar(&s.APIServerInsecureAddress, "apiserver-insecure-address", s.APIServerInsecureAddress, "The address the apiserver will listen insecurely on")
@@ -68,8 +72,12 @@ func AddFlags(s *localkube.LocalkubeServer) {
 	flag.Var(&s.RuntimeConfig, "runtime-config", "A set of key=value pairs that describe runtime configuration that may be passed to apiserver. apis/<groupVersion> key can be used to turn on/off specific api versions. apis/<groupVersion>/<resource> can be used to turn on/off specific resources. api/all and api/legacy are special keys to control all and legacy api versions respectively.")
 	flag.IPVar(&s.NodeIP, "node-ip", s.NodeIP, "IP address of the node. If set, kubelet will use this IP address for the node.")
 	flag.StringVar(&s.ContainerRuntime, "container-runtime", "", "The container runtime to be used")
+	flag.StringVar(&s.ContainerRuntimeEndpoint, "container-runtime-endpoint", "", "The CRI socket of the container runtime, with --container-runtime=remote")
 	flag.StringVar(&s.NetworkPlugin, "network-plugin", "", "The name of the network plugin")
 	flag.StringVar(&s.FeatureGates, "feature-gates", "", "A set of key=value pairs that describe feature gates for alpha/experimental features.")
+	flag.StringVar(&s.AuditLogPath, "audit-log-path", "", "If set, the apiserver logs the requests it gets to this file")
+	flag.StringVar(&s.AuditPolicyFile, "audit-policy-file", "", "The audit policy of the requests the apiserver logs to --audit-log-path")
+	flag.BoolVar(&s.StorageProvisioner, "storage-provisioner", s.StorageProvisioner, "If localkube should provision the volumes of the k8s.io/minikube-hostpath storage classes itself, rather than the storage-provisioner addon")
 	flag.Var(&s.ExtraConfig, "extra-config", "A set of key=value pairs that describe configuration that may be passed to different components. The key should be '.' separated, and the first part before the dot is the component to apply the configuration to.")
 
 	// These two come from vendor/ packages that use flags. We should hide them
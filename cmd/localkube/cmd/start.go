@@ -75,6 +75,12 @@ func SetupServer(s *localkube.LocalkubeServer) {
 	}
 
 	//Set feature gates
+	gates, err := s.GetFeatureGates()
+	if err != nil {
+		fmt.Printf("Error setting feature gates: %s", err)
+	} else {
+		s.FeatureGates = gates
+	}
 	if s.FeatureGates != "" {
 		glog.Infof("Setting Feature Gates: %s", s.FeatureGates)
 		err := feature.DefaultFeatureGate.Set(s.FeatureGates)
@@ -127,6 +133,9 @@ func SetupServer(s *localkube.LocalkubeServer) {
 	proxy := s.NewProxyServer()
 	s.AddServer(proxy)
 
-	storageProvisioner := s.NewStorageProvisionerServer()
-	s.AddServer(storageProvisioner)
+	// setup storage provisioner, unless the storage-provisioner addon runs it
+	if s.StorageProvisioner {
+		storageProvisioner := s.NewStorageProvisionerServer()
+		s.AddServer(storageProvisioner)
+	}
 }
@@ -0,0 +1,234 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	units "github.com/docker/go-units"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/images"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+var (
+	cacheDeleteAll   bool
+	cachePruneKeep   int
+	cacheMigrateFrom string
+)
+
+// defaultCacheKeep is how many unused ISOs and localkube binaries pruning the cache keeps.
+const defaultCacheKeep = 1
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages the images cached on the host and loaded into the VM, and prunes the cache",
+	Long: `Manages the images cached in ~/.minikube/cache/images, or MINIKUBE_CACHE_DIR/images. Cached images are pulled on the host,
+and loaded into the VM's docker daemon by "minikube start", so that they don't need to be pulled from within the VM.
+"minikube cache prune" removes the ISOs and localkube binaries which aren't used anymore, and
+"minikube cache migrate" moves the cache into MINIKUBE_CACHE_DIR.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// cacheAddCmd represents the cache add command
+var cacheAddCmd = &cobra.Command{
+	Use:   "add IMAGE [IMAGE...]",
+	Short: "Pulls images into the cache",
+	Long:  `Pulls images from their registries into the cache, replacing them if they are cached already.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: minikube cache add IMAGE [IMAGE...]")
+			os.Exit(1)
+		}
+		if err := images.Add(images.CacheDir, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error caching images: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// cacheDeleteCmd represents the cache delete command
+var cacheDeleteCmd = &cobra.Command{
+	Use:   "delete IMAGE [IMAGE...]",
+	Short: "Deletes images from the cache",
+	Long:  `Deletes images from the cache. Images already loaded into the VM are left there.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cacheDeleteAll {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "Pass either images or --all to delete")
+				os.Exit(1)
+			}
+			if err := images.DeleteAll(images.CacheDir); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: minikube cache delete IMAGE [IMAGE...] | --all")
+			os.Exit(1)
+		}
+		if err := images.Delete(images.CacheDir, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// cacheListCmd represents the cache list command
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the cached images",
+	Long:  `Lists the cached images.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cached, err := images.List(images.CacheDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, img := range cached {
+			fmt.Println(img)
+		}
+	},
+}
+
+// cachePruneCmd represents the cache prune command
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Removes unused ISOs and localkube binaries from the cache",
+	Long: `Removes the ISOs and localkube binaries from the cache which neither a machine nor the
+configured iso-url and kubernetes-version use, except for the most recently downloaded ones.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := pruneCache(cachePruneKeep, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error pruning the cache: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// cacheMigrateCmd represents the cache migrate command
+var cacheMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Moves the cache into MINIKUBE_CACHE_DIR",
+	Long: `Moves the ISOs, images and localkube binaries cached in ~/.minikube/cache, or the directory given
+with --from, into the cache dir set with MINIKUBE_CACHE_DIR. The files it holds already are left in place.
+As XDG_CACHE_HOME is ignored while ~/.minikube exists, an existing install only moves its cache out with
+MINIKUBE_CACHE_DIR set; otherwise the cache dir is ~/.minikube/cache itself, and migrate fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := migrateCache(cacheMigrateFrom, constants.GetCacheDir(), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating the cache: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// migrateCache moves the cache in from into to, and prints what was moved on out.
+func migrateCache(from, to string, out io.Writer) error {
+	summary, err := cluster.MigrateCache(from, to)
+	for _, name := range summary.Moved {
+		fmt.Fprintf(out, "Moved %s\n", name)
+	}
+	for _, name := range summary.Skipped {
+		fmt.Fprintf(out, "Left %s in place, %s holds it already\n", name, to)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Moved %d files from %s to %s\n", len(summary.Moved), from, to)
+	return nil
+}
+
+// pruneCache prunes the cache, keeping what the configured ISO and Kubernetes version use,
+// and prints what was removed on out.
+func pruneCache(keep int, out io.Writer) error {
+	summary, err := cluster.PruneCache(viper.GetString(kubernetesVersion), isoLocation(), keep)
+	if err != nil {
+		return err
+	}
+	for _, a := range summary.Removed {
+		fmt.Fprintf(out, "Removed %s (%s)\n", a.Path, units.HumanSize(float64(a.Size)))
+	}
+	fmt.Fprintf(out, "Reclaimed %s\n", units.HumanSize(float64(summary.Reclaimed)))
+	return nil
+}
+
+// maybePruneCache prunes the cache if it is larger than maxSize, printing what was removed on out.
+// Failing to prune it doesn't fail the command.
+func maybePruneCache(maxSize string, out io.Writer) {
+	if maxSize == "" {
+		return
+	}
+	limit, err := units.FromHumanSize(maxSize)
+	if err != nil {
+		glog.Warningf("Not pruning the cache, %s %q is invalid: %s", config.CacheMaxSize, maxSize, err)
+		return
+	}
+	size, err := cluster.CacheSize()
+	if err != nil {
+		glog.Warningf("Not pruning the cache: %s", err)
+		return
+	}
+	if size <= limit {
+		return
+	}
+	fmt.Fprintf(out, "The cache is larger than %s %s, pruning it...\n", config.CacheMaxSize, maxSize)
+	if err := pruneCache(defaultCacheKeep, out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error pruning the cache: %s\n", err)
+	}
+}
+
+// loadCachedImages loads the cached images into the VM's container runtime, listing them on out.
+func loadCachedImages(d drivers.Driver, runtime string, out io.Writer) error {
+	cached, err := images.List(images.CacheDir)
+	if err != nil || len(cached) == 0 {
+		return err
+	}
+	rt, err := cruntime.Lookup(runtime)
+	if err != nil {
+		return err
+	}
+	client, err := sshutil.NewSSHClient(d)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return images.LoadCached(images.NewSSHRunner(client), images.CacheDir, rt, out)
+}
+
+func init() {
+	cacheDeleteCmd.Flags().BoolVar(&cacheDeleteAll, "all", false, "Delete every cached image")
+	cacheCmd.AddCommand(cacheAddCmd)
+	cacheCmd.AddCommand(cacheDeleteCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cachePruneCmd.Flags().IntVar(&cachePruneKeep, "keep", defaultCacheKeep, "How many of the most recently downloaded unused ISOs, and of the localkube binaries, to keep")
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheMigrateCmd.Flags().StringVar(&cacheMigrateFrom, "from", constants.MakeMiniPath("cache"), "The cache dir to move the cache out of")
+	cacheCmd.AddCommand(cacheMigrateCmd)
+	RootCmd.AddCommand(cacheCmd)
+}
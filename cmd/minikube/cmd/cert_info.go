@@ -0,0 +1,71 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
+)
+
+// certInfoCmd prints when each of the cluster's certificates expires.
+var certInfoCmd = &cobra.Command{
+	Use:    "ssl-cert-info",
+	Short:  "Prints when the cluster's certificates expire",
+	Long:   `Prints the path of each of the certificates minikube generated for the cluster, and the time it expires at.`,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		infos, err := cluster.CertsInfo()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error getting certificates: ", err)
+			os.Exit(1)
+		}
+		printCertsInfo(os.Stdout, infos, time.Now())
+	},
+}
+
+// printCertsInfo writes one line per certificate, noting the ones which have expired.
+func printCertsInfo(w io.Writer, infos []cluster.CertInfo, now time.Time) {
+	for _, c := range infos {
+		fmt.Fprintf(w, "%s notAfter=%s", c.Path, c.NotAfter.UTC().Format(time.RFC3339))
+		if c.Expired(now) {
+			fmt.Fprint(w, " expired")
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// warnCertExpiry warns about the cluster's certificates which expire within 30 days.
+func warnCertExpiry(w io.Writer) {
+	infos, err := cluster.CertsInfo()
+	if err != nil {
+		glog.Warningln("Error checking certificate expiry:", err)
+		return
+	}
+	for _, warning := range cluster.CertExpiryWarnings(infos, time.Now()) {
+		fmt.Fprintln(w, "Warning:", warning)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(certInfoCmd)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/cluster"
+)
+
+func TestPrintCertsInfo(t *testing.T) {
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	infos := []cluster.CertInfo{
+		{Name: "ca.crt", Path: "/home/user/.minikube/ca.crt", NotAfter: now.AddDate(10, 0, 0)},
+		{Name: "apiserver.crt", Path: "/home/user/.minikube/apiserver.crt", NotAfter: now.Add(-time.Hour)},
+	}
+	var b bytes.Buffer
+	printCertsInfo(&b, infos, now)
+	expected := `/home/user/.minikube/ca.crt notAfter=2027-06-01T12:00:00Z
+/home/user/.minikube/apiserver.crt notAfter=2017-06-01T11:00:00Z expired
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
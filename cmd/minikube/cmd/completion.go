@@ -17,17 +17,26 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
 	cmdutil "k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/service"
 )
 
 const longDescription = `
-	Outputs minikube shell completion for the given shell (bash)
+	Outputs minikube shell completion for the given shell (bash or zsh)
 
 	This depends on the bash-completion binary.  Example installation instructions:
 	OS X:
@@ -40,7 +49,10 @@ const longDescription = `
 		$ source /etc/bash-completion
 		$ source <(minikube completion bash)
 
-	Additionally, you may want to output completion to a file and source in your .bashrc
+	zsh:
+		$ source <(minikube completion zsh)
+
+	Additionally, you may want to output completion to a file and source in your .bashrc or .zshrc
 `
 
 const boilerPlate = `
@@ -59,40 +71,360 @@ const boilerPlate = `
 # limitations under the License.
 `
 
+// serviceCompletionTimeout is how long the completion of the service names waits for the
+// cluster, which offers none when it is down.
+const serviceCompletionTimeout = 2 * time.Second
+
+// bashCompletionFunction completes the arguments cobra knows nothing of, with the values
+// minikube completion values prints. It follows cobra's functions in the script, and
+// __custom_func is called when no subcommand matches.
+const bashCompletionFunction = `
+__minikube_values()
+{
+    local values
+    values=$(minikube completion values "$@" 2>/dev/null)
+    COMPREPLY=( $(compgen -W "${values}" -- "$cur") )
+}
+
+__minikube_get_profiles()
+{
+    __minikube_values profiles
+}
+
+# __minikube_flag_value prints the value given to the flag named by its long and short names
+# on the command line so far.
+__minikube_flag_value()
+{
+    local long=$1 short=$2
+    if [[ -n ${flaghash[${long}=]} ]]; then
+        echo "${flaghash[${long}=]}"
+    elif [[ -n ${flaghash[${long}]} ]]; then
+        echo "${flaghash[${long}]}"
+    elif [[ -n ${short} && -n ${flaghash[${short}]} ]]; then
+        echo "${flaghash[${short}]}"
+    fi
+}
+
+# __minikube_service_names prints the services of the namespace given with -n, in the cluster of
+# the profile given with -p.
+__minikube_service_names()
+{
+    local profile namespace
+    profile=$(__minikube_flag_value --profile -p)
+    namespace=$(__minikube_flag_value --namespace -n)
+    minikube completion values ${profile:+--profile=${profile}} services ${namespace} 2>/dev/null
+}
+
+# minikube service offers the service names along with its list subcommand, which would otherwise
+# always match an empty word before __custom_func is called. cobra's __handle_reply is renamed, and
+# run by the one taking its place once the names are added to the commands.
+eval "__minikube_cobra$(declare -f __handle_reply)"
+
+__handle_reply()
+{
+    if [[ ${last_command} == minikube_service && ${cur} != -* && ${#nouns[@]} -eq 0 ]] &&
+        ! __contains_word "${prev}" "${two_word_flags[@]}"; then
+        commands+=($(__minikube_service_names))
+    fi
+    __minikube_cobra__handle_reply
+}
+
+__custom_func() {
+    if [[ ${#nouns[@]} -ne 0 ]]; then
+        return
+    fi
+    case ${last_command} in
+        minikube_addons_enable | minikube_addons_disable | minikube_addons_open | minikube_addons_configure)
+            __minikube_values addons
+            return
+            ;;
+        minikube_config_set | minikube_config_get | minikube_config_unset)
+            __minikube_values config
+            return
+            ;;
+        *)
+            ;;
+    esac
+}
+`
+
 var completionCmd = &cobra.Command{
 	Use:   "completion SHELL",
-	Short: "Outputs minikube shell completion for the given shell (bash)",
+	Short: "Outputs minikube shell completion for the given shell (bash or zsh)",
 	Long:  longDescription,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Println("Usage: minikube completion SHELL")
 			os.Exit(1)
 		}
-		if args[0] != "bash" {
-			fmt.Println("Only bash is supported for minikube completion")
+		var err error
+		switch args[0] {
+		case "bash":
+			err = GenerateBashCompletion(os.Stdout, cmd.Parent())
+		case "zsh":
+			err = GenerateZshCompletion(os.Stdout, cmd.Parent())
+		default:
+			fmt.Println("Only bash and zsh are supported for minikube completion")
 			os.Exit(1)
 		}
-		err := GenerateBashCompletion(os.Stdout, cmd.Parent())
 		if err != nil {
 			cmdutil.MaybeReportErrorAndExit(err)
 		}
 	},
 }
 
+var completionValuesCmd = &cobra.Command{
+	Use:    "values KIND [NAMESPACE]",
+	Short:  "Prints the addons, config, profiles or services the shell completion offers, one a line",
+	Long:   "Prints the addon names, config properties, profiles or service names of the namespace the shell completion offers, one a line.",
+	Hidden: true,
+	// The completion runs on every key press, without the checks and notices of the other commands.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Usage: minikube completion values KIND [NAMESPACE]")
+			os.Exit(1)
+		}
+		values, err := completionValues(args[0], args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, v := range values {
+			fmt.Println(v)
+		}
+	},
+}
+
+// completionValues returns the candidates of the given kind the shell completion offers.
+// The service names are queried from the cluster, in the namespace args holds or default.
+func completionValues(kind string, args []string) ([]string, error) {
+	switch kind {
+	case "addons":
+		var names []string
+		for name := range assets.Addons {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	case "config":
+		return configCmd.PropertyNames(), nil
+	case "profiles":
+		return config.ListProfiles()
+	case "services":
+		namespace := "default"
+		if len(args) > 0 && args[0] != "" {
+			namespace = args[0]
+		}
+		return service.GetServiceNames(namespace, serviceCompletionTimeout)
+	default:
+		return nil, errors.Errorf("Unknown kind of completion values %q, expected addons, config, profiles or services", kind)
+	}
+}
+
 func GenerateBashCompletion(w io.Writer, cmd *cobra.Command) error {
 	_, err := w.Write([]byte(boilerPlate))
 	if err != nil {
 		return err
 	}
 
-	err = cmd.GenBashCompletion(w)
+	buf := new(bytes.Buffer)
+	err = cmd.GenBashCompletion(buf)
 	if err != nil {
 		return errors.Wrap(err, "Error generating bash completion")
 	}
 
-	return nil
+	_, err = w.Write([]byte(posixFunctionNames(buf.String())))
+	return err
 }
 
+var bashFunctionDefinition = regexp.MustCompile(`(?m)^_[A-Za-z0-9_-]+\(\)$`)
+
+// posixFunctionNames replaces the hyphens of the names of the functions cobra generates for the
+// commands, such as _minikube_docker-env, with underscores, which bash only allows outside of
+// posix mode, and has the functions looked up by those names.
+func posixFunctionNames(script string) string {
+	script = bashFunctionDefinition.ReplaceAllStringFunc(script, func(def string) string {
+		return strings.Replace(def, "-", "_", -1)
+	})
+	return strings.Replace(script, bashLookupCommand, "    next_command=${next_command//-/_}\n"+bashLookupCommand, 1)
+}
+
+// bashLookupCommand is where the bash completion calls the function of a command.
+const bashLookupCommand = `    __debug "${FUNCNAME[0]}: looking for ${next_command}"
+`
+
+// GenerateZshCompletion writes the bash completion, converted to run in zsh's bash completion
+// emulation, as kubectl does.
+func GenerateZshCompletion(w io.Writer, cmd *cobra.Command) error {
+	if _, err := w.Write([]byte("#compdef minikube\n" + boilerPlate + zshInitialization)); err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := GenerateBashCompletion(buf, cmd); err != nil {
+		return err
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte(zshTail))
+	return err
+}
+
+const zshInitialization = `
+__minikube_bash_source() {
+	alias shopt=':'
+	alias _expand=_bash_expand
+	alias _complete=_bash_comp
+	emulate -L sh
+	setopt kshglob noshglob braceexpand
+
+	source "$@"
+}
+
+__minikube_type() {
+	# -t is not supported by zsh
+	if [ "$1" == "-t" ]; then
+		shift
+
+		# fake Bash 4 to disable "complete -o nospace". Instead
+		# "compopt +-o nospace" is used in the code to toggle trailing
+		# spaces. We don't support that, but leave trailing spaces on
+		# all the time
+		if [ "$1" = "__minikube_compopt" ]; then
+			echo builtin
+			return 0
+		fi
+	fi
+	type "$@"
+}
+
+__minikube_compgen() {
+	local completions w
+	completions=( $(compgen "$@") ) || return $?
+
+	# filter by given word as prefix
+	while [[ "$1" = -* && "$1" != -- ]]; do
+		shift
+		shift
+	done
+	if [[ "$1" == -- ]]; then
+		shift
+	fi
+	for w in "${completions[@]}"; do
+		if [[ "${w}" = "$1"* ]]; then
+			echo "${w}"
+		fi
+	done
+}
+
+__minikube_compopt() {
+	true # don't do anything. Not supported by bashcompinit in zsh
+}
+
+__minikube_ltrim_colon_completions()
+{
+	if [[ "$1" == *:* && "$COMP_WORDBREAKS" == *:* ]]; then
+		# Remove colon-word prefix from COMPREPLY items
+		local colon_word=${1%${1##*:}}
+		local i=${#COMPREPLY[*]}
+		while [[ $((--i)) -ge 0 ]]; do
+			COMPREPLY[$i]=${COMPREPLY[$i]#"$colon_word"}
+		done
+	fi
+}
+
+__minikube_get_comp_words_by_ref() {
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[${COMP_CWORD}-1]}"
+	words=("${COMP_WORDS[@]}")
+	cword=("${COMP_CWORD[@]}")
+}
+
+__minikube_filedir() {
+	local RET OLD_IFS w qw
+
+	__debug "_filedir $@ cur=$cur"
+	if [[ "$1" = \~* ]]; then
+		# somehow does not work. Maybe, zsh does not call this at all
+		eval echo "$1"
+		return 0
+	fi
+
+	OLD_IFS="$IFS"
+	IFS=$'\n'
+	if [ "$1" = "-d" ]; then
+		shift
+		RET=( $(compgen -d) )
+	else
+		RET=( $(compgen -f) )
+	fi
+	IFS="$OLD_IFS"
+
+	IFS="," __debug "RET=${RET[@]} len=${#RET[@]}"
+
+	for w in ${RET[@]}; do
+		if [[ ! "${w}" = "${cur}"* ]]; then
+			continue
+		fi
+		if eval "[[ \"\${w}\" = *.$1 || -d \"\${w}\" ]]"; then
+			qw="$(__minikube_quote "${w}")"
+			if [ -d "${w}" ]; then
+				COMPREPLY+=("${qw}/")
+			else
+				COMPREPLY+=("${qw}")
+			fi
+		fi
+	done
+}
+
+__minikube_quote() {
+	if [[ $1 == \'* || $1 == \"* ]]; then
+		# Leave out first character
+		printf %q "${1:1}"
+	else
+		printf %q "$1"
+	fi
+}
+
+autoload -U +X bashcompinit && bashcompinit
+
+# use word boundary patterns for BSD or GNU sed
+LWORD='[[:<:]]'
+RWORD='[[:>:]]'
+if sed --help 2>&1 | grep -q GNU; then
+	LWORD='\<'
+	RWORD='\>'
+fi
+
+__minikube_convert_bash_to_zsh() {
+	sed \
+	-e 's/declare -F/whence -w/' \
+	-e 's/_get_comp_words_by_ref "\$@"/_get_comp_words_by_ref "\$*"/' \
+	-e 's/local \([a-zA-Z0-9_]*\)=/local \1; \1=/' \
+	-e 's/flags+=("\(--.*\)=")/flags+=("\1"); two_word_flags+=("\1")/' \
+	-e 's/must_have_one_flag+=("\(--.*\)=")/must_have_one_flag+=("\1")/' \
+	-e "s/${LWORD}_filedir${RWORD}/__minikube_filedir/g" \
+	-e "s/${LWORD}_get_comp_words_by_ref${RWORD}/__minikube_get_comp_words_by_ref/g" \
+	-e "s/${LWORD}__ltrim_colon_completions${RWORD}/__minikube_ltrim_colon_completions/g" \
+	-e "s/${LWORD}compgen${RWORD}/__minikube_compgen/g" \
+	-e "s/${LWORD}compopt${RWORD}/__minikube_compopt/g" \
+	-e "s/${LWORD}declare${RWORD}/builtin declare/g" \
+	-e "s/\\\$(type${RWORD}/\$(__minikube_type/g" \
+	<<'BASH_COMPLETION_EOF'
+`
+
+const zshTail = `
+BASH_COMPLETION_EOF
+}
+
+__minikube_bash_source <(__minikube_convert_bash_to_zsh)
+`
+
 func init() {
+	RootCmd.BashCompletionFunction = bashCompletionFunction
+	completionCmd.AddCommand(completionValuesCmd)
 	RootCmd.AddCommand(completionCmd)
 }
@@ -0,0 +1,297 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/assets"
+)
+
+// completionHelperEnv has the test binary run minikube with the arguments after --, in place
+// of the minikube the completion script calls.
+const completionHelperEnv = "GO_WANT_COMPLETION_HELPER"
+
+func TestCompletionHelperProcess(t *testing.T) {
+	if os.Getenv(completionHelperEnv) != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	RootCmd.SetArgs(args)
+	Execute()
+	os.Exit(0)
+}
+
+// completionHarness completes the words given as arguments with the bash completion, and prints
+// the candidates one a line. _get_comp_words_by_ref and __ltrim_colon_completions stand in for
+// the ones of the bash-completion package.
+const completionHarness = `
+_get_comp_words_by_ref() {
+    cur=${COMP_WORDS[COMP_CWORD]}
+    prev=${COMP_WORDS[COMP_CWORD-1]}
+    words=("${COMP_WORDS[@]}")
+    cword=${COMP_CWORD}
+}
+__ltrim_colon_completions() { :; }
+minikube() {
+    GO_WANT_COMPLETION_HELPER=1 "${MINIKUBE_TEST_BINARY}" -test.run='^TestCompletionHelperProcess$' -- "$@"
+}
+source "${MINIKUBE_COMPLETION}"
+COMP_WORDS=("$@")
+COMP_CWORD=$(($# - 1))
+__start_minikube
+if [[ ${#COMPREPLY[@]} -ne 0 ]]; then
+    printf '%s\n' "${COMPREPLY[@]}"
+fi
+`
+
+// fakeAPIServer serves the services of the default and kube-system namespaces.
+func fakeAPIServer() *httptest.Server {
+	services := map[string][]string{
+		"/api/v1/namespaces/default/services":     {"web", "db"},
+		"/api/v1/namespaces/kube-system/services": {"kube-dns"},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names, ok := services[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var items []string
+		for _, name := range names {
+			items = append(items, fmt.Sprintf(`{"metadata": {"name": %q}}`, name))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind": "ServiceList", "apiVersion": "v1", "items": [%s]}`, strings.Join(items, ","))
+	}))
+}
+
+const completionKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: minikube
+  cluster:
+    server: %s
+- name: down
+  cluster:
+    server: %s
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+- name: down
+  context:
+    cluster: down
+    user: minikube
+users:
+- name: minikube
+  user: {}
+`
+
+// completionFixture writes the completion script and the state it is run against: the profiles
+// dev, down and staging, and a kubeconfig with the running cluster of the default profile and
+// the stopped one of down. It returns the environment of the harness.
+func completionFixture(t *testing.T, dir, running string) []string {
+	minipath := filepath.Join(dir, ".minikube")
+	for _, profile := range []string{"dev", "down", "staging"} {
+		if err := os.MkdirAll(filepath.Join(minipath, "config", "profiles", profile), 0777); err != nil {
+			t.Fatalf("Error creating profile: %s", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(minipath, "config", "config.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("Error writing config: %s", err)
+	}
+
+	stopped := httptest.NewServer(http.NotFoundHandler())
+	stopped.Close()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := ioutil.WriteFile(kubeconfig, []byte(fmt.Sprintf(completionKubeconfig, running, stopped.URL)), 0644); err != nil {
+		t.Fatalf("Error writing kubeconfig: %s", err)
+	}
+
+	script := new(bytes.Buffer)
+	if err := GenerateBashCompletion(script, RootCmd); err != nil {
+		t.Fatalf("Error generating bash completion: %s", err)
+	}
+	completion := filepath.Join(dir, "completion.bash")
+	if err := ioutil.WriteFile(completion, script.Bytes(), 0644); err != nil {
+		t.Fatalf("Error writing bash completion: %s", err)
+	}
+
+	var env []string
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, "MINIKUBE_") && !strings.HasPrefix(e, "KUBECONFIG=") {
+			env = append(env, e)
+		}
+	}
+	return append(env,
+		"MINIKUBE_HOME="+minipath,
+		"KUBECONFIG="+kubeconfig,
+		"MINIKUBE_COMPLETION="+completion,
+		"MINIKUBE_TEST_BINARY="+os.Args[0],
+	)
+}
+
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir, err := ioutil.TempDir("", "completion")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	server := fakeAPIServer()
+	defer server.Close()
+	env := completionFixture(t, dir, server.URL)
+
+	var addons []string
+	for name := range assets.Addons {
+		addons = append(addons, name)
+	}
+	sort.Strings(addons)
+
+	var tests = []struct {
+		description string
+		words       []string
+		expected    []string
+	}{
+		{
+			description: "addon names",
+			words:       []string{"minikube", "addons", "enable", ""},
+			expected:    addons,
+		},
+		{
+			description: "addon names with a prefix",
+			words:       []string{"minikube", "addons", "disable", "reg"},
+			expected:    []string{"registry", "registry-creds"},
+		},
+		{
+			description: "addon named already",
+			words:       []string{"minikube", "addons", "enable", "heapster", ""},
+		},
+		{
+			description: "config keys",
+			words:       []string{"minikube", "config", "set", ""},
+			expected:    configCmd.PropertyNames(),
+		},
+		{
+			description: "config keys with a prefix",
+			words:       []string{"minikube", "config", "get", "cpus.k"},
+			expected:    []string{"cpus.kvm", "cpus.kvm2"},
+		},
+		{
+			description: "config value",
+			words:       []string{"minikube", "config", "set", "memory", ""},
+		},
+		{
+			description: "profiles",
+			words:       []string{"minikube", "start", "-p", ""},
+			expected:    []string{"minikube", "dev", "down", "staging"},
+		},
+		{
+			description: "profiles with a prefix",
+			words:       []string{"minikube", "status", "--profile", "d"},
+			expected:    []string{"dev", "down"},
+		},
+		{
+			description: "services",
+			words:       []string{"minikube", "service", ""},
+			expected:    []string{"list", "db", "web"},
+		},
+		{
+			description: "services with a prefix",
+			words:       []string{"minikube", "service", "w"},
+			expected:    []string{"web"},
+		},
+		{
+			description: "services of a namespace",
+			words:       []string{"minikube", "service", "-n", "kube-system", ""},
+			expected:    []string{"list", "kube-dns"},
+		},
+		{
+			description: "namespace",
+			words:       []string{"minikube", "service", "-n", ""},
+		},
+		{
+			description: "services of a stopped cluster",
+			words:       []string{"minikube", "-p", "down", "service", ""},
+			expected:    []string{"list"},
+		},
+		{
+			description: "command with a hyphen",
+			words:       []string{"minikube", "docker-env", "--shel"},
+			expected:    []string{"--shell="},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cmd := exec.Command(bash, append([]string{"--posix", "-c", completionHarness, "--"}, test.words...)...)
+			cmd.Env = env
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Error running the completion: %s\n%s", err, stderr.String())
+			}
+			got := strings.Fields(string(out))
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected candidates %v completing %q, got %v", test.expected, test.words, got)
+			}
+		})
+	}
+}
+
+func TestGenerateZshCompletion(t *testing.T) {
+	var out bytes.Buffer
+	if err := GenerateZshCompletion(&out, RootCmd); err != nil {
+		t.Fatalf("Error generating zsh completion: %s", err)
+	}
+	script := out.String()
+	if !strings.HasPrefix(script, "#compdef minikube\n") {
+		t.Errorf("Expected the zsh completion to start with #compdef minikube, got %q", strings.SplitN(script, "\n", 2)[0])
+	}
+	for _, s := range []string{"__start_minikube", "__minikube_service_names", "__minikube_bash_source <(__minikube_convert_bash_to_zsh)"} {
+		if !strings.Contains(script, s) {
+			t.Errorf("Expected the zsh completion to contain %s", s)
+		}
+	}
+}
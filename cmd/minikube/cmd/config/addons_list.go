@@ -17,33 +17,52 @@ limitations under the License.
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"text/template"
 
+	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/addons"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
 )
 
-var addonListFormat string
+var (
+	addonListFormat string
+	addonListOutput string
+)
 
 type AddonListTemplate struct {
 	AddonName   string
 	AddonStatus string
+	Profile     string
+	// Readiness is whether the workloads of the enabled addon are ready, unknown when the cluster
+	// isn't running, and empty for an addon without workloads.
+	Readiness string
+	Workloads []addons.Workload
 }
 
 var addonsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Lists all available minikube addons as well as there current status (enabled/disabled)",
-	Long:  "Lists all available minikube addons as well as there current status (enabled/disabled)",
+	Long: `Lists all available minikube addons as well as there current status (enabled/disabled), and whether the pods
+of the enabled addons are ready when the cluster is running.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 0 {
 			fmt.Fprintln(os.Stderr, "usage: minikube addons list")
 			os.Exit(1)
 		}
-		err := addonList()
+		err := addonList(cmd.Flags().Changed("format"))
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
@@ -52,9 +71,10 @@ var addonsListCmd = &cobra.Command{
 }
 
 func init() {
-	AddonsCmd.Flags().StringVar(&addonListFormat, "format", constants.DefaultAddonListFormat,
-		`Go template format string for the addon list output.  The format for Go templates can be found here: https://golang.org/pkg/text/template/
+	addonsListCmd.Flags().StringVar(&addonListFormat, "format", constants.DefaultAddonListFormat,
+		`Go template format string for the addon list output, instead of --output.  The format for Go templates can be found here: https://golang.org/pkg/text/template/
 For the list of accessible variables for the template, see the struct values here: https://godoc.org/k8s.io/minikube/cmd/minikube/cmd/config#AddonListTemplate`)
+	addonsListCmd.Flags().StringVarP(&addonListOutput, "output", "o", "table", "The output format, table or json")
 	AddonsCmd.AddCommand(addonsListCmd)
 }
 
@@ -65,23 +85,141 @@ func stringFromStatus(addonStatus bool) string {
 	return "disabled"
 }
 
-func addonList() error {
-	for addonName, addonBundle := range assets.Addons {
-		addonStatus, err := addonBundle.IsEnabled()
+func addonList(format bool) error {
+	addonList, err := addonListEntries()
+	if err != nil {
+		return err
+	}
+	if !format {
+		return printAddonList(os.Stdout, addonList, addonListOutput)
+	}
+	tmpl, err := template.New("list").Parse(addonListFormat)
+	if err != nil {
+		glog.Errorln("Error creating list template:", err)
+		os.Exit(1)
+	}
+	for _, listTmplt := range addonList {
+		err = tmpl.Execute(os.Stdout, listTmplt)
 		if err != nil {
-			return err
+			glog.Errorln("Error executing list template:", err)
+			os.Exit(1)
 		}
-		tmpl, err := template.New("list").Parse(addonListFormat)
+	}
+	return nil
+}
+
+// addonListEntries returns the addons by name, with the readiness of the enabled ones.
+func addonListEntries() ([]AddonListTemplate, error) {
+	names := []string{}
+	for name := range assets.Addons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clients := runningClients()
+	var entries []AddonListTemplate
+	for _, addonName := range names {
+		addonBundle := assets.Addons[addonName]
+		addonStatus, err := addonBundle.IsEnabled()
 		if err != nil {
-			glog.Errorln("Error creating list template:", err)
-			os.Exit(1)
+			return nil, err
 		}
-		listTmplt := AddonListTemplate{addonName, stringFromStatus(addonStatus)}
-		err = tmpl.Execute(os.Stdout, listTmplt)
+		e := AddonListTemplate{AddonName: addonName, AddonStatus: stringFromStatus(addonStatus), Profile: config.GetMachineName()}
+		if addonStatus {
+			e.Readiness, e.Workloads = addonReadiness(clients, addonBundle)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// runningClients returns the clients of the cluster, or nil if it isn't running.
+func runningClients() *addons.Clients {
+	api, err := machine.NewAPIClient(GetClientType())
+	if err != nil {
+		glog.Warningf("Error getting client: %s", err)
+		return nil
+	}
+	defer api.Close()
+	if s, err := cluster.GetHostStatus(api); err != nil || s != state.Running.String() {
+		return nil
+	}
+	clients, err := addons.NewClients()
+	if err != nil {
+		glog.Warningf("Error getting the clients of the cluster: %s", err)
+		return nil
+	}
+	return clients
+}
+
+// addonReadiness returns whether the workloads of the addon are ready, unknown if they can't be
+// gotten, such as without clients, along with the workloads.
+func addonReadiness(clients *addons.Clients, addon *assets.Addon) (string, []addons.Workload) {
+	if clients == nil {
+		return addons.Unknown, nil
+	}
+	configured, err := configuredAddon(addon)
+	if err != nil {
+		glog.Warningf("Error configuring addon %s: %s", addon.Name(), err)
+		return addons.Unknown, nil
+	}
+	objs, err := addons.Objects(configured)
+	if err != nil {
+		glog.Warningf("Error decoding the objects of addon %s: %s", addon.Name(), err)
+		return addons.Unknown, nil
+	}
+	workloads, err := clients.Workloads(objs)
+	if err != nil {
+		glog.Warningf("Error getting the workloads of addon %s: %s", addon.Name(), err)
+		return addons.Unknown, nil
+	}
+	return addons.Readiness(workloads), workloads
+}
+
+// addonListJSON is an addon as minikube addons list --output=json shows it.
+type addonListJSON struct {
+	Name      string            `json:"name"`
+	Profile   string            `json:"profile"`
+	Enabled   bool              `json:"enabled"`
+	Status    string            `json:"status,omitempty"`
+	Workloads []addons.Workload `json:"workloads,omitempty"`
+}
+
+// printAddonList writes the addons to out as a table, or as JSON.
+func printAddonList(out io.Writer, entries []AddonListTemplate, output string) error {
+	switch output {
+	case "json":
+		list := []addonListJSON{}
+		for _, e := range entries {
+			list = append(list, addonListJSON{Name: e.AddonName, Profile: e.Profile, Enabled: e.AddonStatus == "enabled",
+				Status: e.Readiness, Workloads: e.Workloads})
+		}
+		b, err := json.MarshalIndent(list, "", "    ")
 		if err != nil {
-			glog.Errorln("Error executing list template:", err)
-			os.Exit(1)
+			return errors.Wrap(err, "Error marshalling the addons")
 		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case "table":
+	default:
+		return errors.Errorf("Invalid --output %q, expected table or json", output)
 	}
+
+	var data [][]string
+	for _, e := range entries {
+		status := e.Readiness
+		if status == "" {
+			status = "-"
+		}
+		data = append(data, []string{e.AddonName, e.Profile, e.AddonStatus, status})
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Profile", "Enabled", "Status"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetCenterSeparator("|")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.AppendBulk(data)
+	table.Render()
 	return nil
 }
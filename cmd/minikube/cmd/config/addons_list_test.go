@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/addons"
+)
+
+func TestPrintAddonList(t *testing.T) {
+	entries := []AddonListTemplate{
+		{AddonName: "dashboard", AddonStatus: "enabled", Profile: "minikube", Readiness: addons.Ready,
+			Workloads: []addons.Workload{{Kind: "replicationcontroller", Namespace: "kube-system", Name: "kubernetes-dashboard", Ready: 1, Desired: 1}}},
+		{AddonName: "default-storageclass", AddonStatus: "enabled", Profile: "minikube"},
+		{AddonName: "heapster", AddonStatus: "disabled", Profile: "minikube"},
+		{AddonName: "kube-dns", AddonStatus: "enabled", Profile: "minikube", Readiness: addons.Unknown},
+	}
+
+	var b bytes.Buffer
+	if err := printAddonList(&b, entries, "table"); err != nil {
+		t.Fatalf("Error printing the table: %s", err)
+	}
+	for _, row := range []string{
+		"| dashboard            | minikube | enabled  | ready   |",
+		"| default-storageclass | minikube | enabled  | -       |",
+		"| heapster             | minikube | disabled | -       |",
+		"| kube-dns             | minikube | enabled  | unknown |",
+	} {
+		if !strings.Contains(b.String(), row) {
+			t.Errorf("Expected the row %q in the table:\n%s", row, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := printAddonList(&b, entries, "json"); err != nil {
+		t.Fatalf("Error printing JSON: %s", err)
+	}
+	var printed []addonListJSON
+	if err := json.Unmarshal(b.Bytes(), &printed); err != nil {
+		t.Fatalf("Error unmarshalling %s: %s", b.String(), err)
+	}
+	if len(printed) != 4 || !printed[0].Enabled || printed[0].Status != "ready" || len(printed[0].Workloads) != 1 || printed[2].Enabled || printed[2].Status != "" {
+		t.Errorf("Unexpected addons %+v", printed)
+	}
+	if !strings.Contains(b.String(), `"status": "unknown"`) {
+		t.Errorf("Expected the status in the JSON: %s", b.String())
+	}
+
+	if err := printAddonList(&b, entries, "yaml"); err == nil {
+		t.Error("Expected an error printing yaml")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"k8s.io/minikube/pkg/minikube/config"
@@ -41,7 +42,7 @@ type Setting struct {
 }
 
 // These are all the settings that are configurable
-// and their validation and callback fn run on Set
+// and their validation and callback fn run on Set, and their validation on minikube config validate
 var settings = []Setting{
 	{
 		name:        "vm-driver",
@@ -74,7 +75,7 @@ var settings = []Setting{
 	{
 		name:        "memory",
 		set:         SetInt,
-		validations: []setFn{IsPositive},
+		validations: []setFn{IsValidMemory},
 		callbacks:   []setFn{RequiresRestartMsg},
 	},
 	{
@@ -83,8 +84,9 @@ var settings = []Setting{
 		validations: []setFn{IsValidPath},
 	},
 	{
-		name: "kubernetes-version",
-		set:  SetString,
+		name:        "kubernetes-version",
+		set:         SetString,
+		validations: []setFn{IsValidKubernetesVersion},
 	},
 	{
 		name:        "iso-url",
@@ -92,84 +94,166 @@ var settings = []Setting{
 		validations: []setFn{IsValidURL},
 	},
 	{
-		name: config.WantUpdateNotification,
-		set:  SetBool,
+		name:        config.ISOBaseURL,
+		set:         SetString,
+		validations: []setFn{IsValidURL},
+	},
+	{
+		name:        config.ImageRepository,
+		set:         SetString,
+		validations: []setFn{IsValidImageRepository},
+	},
+	{
+		name:        config.EmbedCerts,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
+	},
+	{
+		name:        config.ExtraConfig,
+		set:         SetExtraConfig,
+		validations: []setFn{IsValidExtraConfig},
+	},
+	{
+		name:        config.DNSDomain,
+		set:         SetString,
+		validations: []setFn{IsValidDNSDomain},
+	},
+	{
+		name:        config.ContainerRuntime,
+		set:         SetString,
+		validations: []setFn{IsValidContainerRuntime},
+		callbacks:   []setFn{RequiresRestartMsg},
+	},
+	{
+		name:        config.InsecureRegistry,
+		set:         SetInsecureRegistry,
+		validations: []setFn{IsValidInsecureRegistry},
+	},
+	{
+		name:        config.CacheMaxSize,
+		set:         SetString,
+		validations: []setFn{IsValidSize},
+	},
+	{
+		name:        config.WantUpdateNotification,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
+	},
+	{
+		name:        config.ReminderWaitPeriodInHours,
+		set:         SetInt,
+		validations: []setFn{IsPositive},
+	},
+	{
+		name:        config.WantBetaUpdateNotification,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
+	},
+	{
+		name:        config.ReleasesURL,
+		set:         SetString,
+		validations: []setFn{IsValidURL},
 	},
 	{
-		name: config.ReminderWaitPeriodInHours,
-		set:  SetInt,
+		name:        config.WantReportError,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
 	},
 	{
-		name: config.WantReportError,
-		set:  SetBool,
+		name:        config.WantReportErrorPrompt,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
 	},
 	{
-		name: config.WantReportErrorPrompt,
-		set:  SetBool,
+		name:        config.WantKubectlDownloadMsg,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
 	},
 	{
-		name: config.WantKubectlDownloadMsg,
-		set:  SetBool,
+		name:        config.MachineProfile,
+		set:         SetString,
+		validations: []setFn{IsValidProfile},
 	},
 	{
-		name: config.MachineProfile,
-		set:  SetString,
+		name:        config.AutoRestart,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
+		callbacks:   []setFn{EnableOrDisableAutoRestart},
 	},
 	{
 		name:        "dashboard",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "addon-manager",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "default-storageclass",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "kube-dns",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "heapster",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon, IsNotConflictingAddon},
+		callbacks:   []setFn{EnableOrDisableAddon},
+	},
+	{
+		name:        "metrics",
+		set:         SetBool,
+		validations: []setFn{IsValidBool, IsValidAddon, IsNotConflictingAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "ingress",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
+		callbacks:   []setFn{EnableOrDisableAddon},
+	},
+	{
+		name:        "registry",
+		set:         SetBool,
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
 		name:        "registry-creds",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
+		validations: []setFn{IsValidBool, IsValidAddon},
 		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
-		name:        "default-storageclass",
+		name:        "storage-provisioner",
 		set:         SetBool,
-		validations: []setFn{IsValidAddon},
-		callbacks:   []setFn{EnableOrDisableDefaultStorageClass},
+		validations: []setFn{IsValidBool, IsValidAddon},
+		callbacks:   []setFn{EnableOrDisableAddon},
 	},
 	{
-		name: "hyperv-virtual-switch",
-		set:  SetString,
+		name:        "hyperv-virtual-switch",
+		set:         SetString,
+		validations: []setFn{IsNotEmpty},
 	},
 	{
-		name: useVendoredDriver,
-		set:  SetBool,
+		name:        "hyperv-use-external-switch",
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
+	},
+	{
+		name:        useVendoredDriver,
+		set:         SetBool,
+		validations: []setFn{IsValidBool},
 	},
 }
 
@@ -188,19 +272,56 @@ func configurableFields() string {
 	for _, s := range settings {
 		fields = append(fields, " * "+s.name)
 	}
+	for _, name := range config.DriverSettings {
+		fields = append(fields, " * "+config.DriverKey(name, "<driver>"))
+	}
+	fields = append(fields, " * <addon>.<field>, the fields of minikube addons configure")
 	return strings.Join(fields, "\n")
 }
 
-// WriteConfig writes a minikube config to the JSON file
+// WriteConfig writes the minikube config of the current profile to the JSON files: its global
+// settings to the global config file, and the others to the profile's own.
 func WriteConfig(m config.MinikubeConfig) error {
-	f, err := os.Create(constants.ConfigFile)
+	profile := config.GetMachineName()
+	if profile == constants.DefaultMachineName {
+		return writeConfigFile(constants.ConfigFile, m)
+	}
+	global, err := config.ReadConfigFile(constants.ConfigFile)
+	if err != nil {
+		return err
+	}
+	own := config.MinikubeConfig{}
+	for k, v := range m {
+		if !config.IsGlobalSetting(k) {
+			own[k] = v
+		}
+	}
+	for _, k := range config.GlobalSettings {
+		if v, ok := m[k]; ok {
+			global[k] = v
+		} else {
+			delete(global, k)
+		}
+	}
+	if err := writeConfigFile(constants.ConfigFile, global); err != nil {
+		return err
+	}
+	path := constants.ProfileConfigFile(profile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Could not create directory %s: %s", filepath.Dir(path), err)
+	}
+	return writeConfigFile(path, own)
+}
+
+func writeConfigFile(path string, m config.MinikubeConfig) error {
+	f, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("Could not open file %s: %s", constants.ConfigFile, err)
+		return fmt.Errorf("Could not open file %s: %s", path, err)
 	}
 	defer f.Close()
 	err = encode(f, m)
 	if err != nil {
-		return fmt.Errorf("Error encoding config %s: %s", constants.ConfigFile, err)
+		return fmt.Errorf("Error encoding config %s: %s", path, err)
 	}
 	return nil
 }
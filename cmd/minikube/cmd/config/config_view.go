@@ -18,14 +18,18 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"text/template"
 
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
 )
 
 var configViewFormat string
@@ -33,12 +37,19 @@ var configViewFormat string
 type ConfigViewTemplate struct {
 	ConfigKey   string
 	ConfigValue interface{}
+	// Source is where the value comes from: flag, env, profile (the config the cluster was last
+	// started with), config or default.
+	Source string
 }
 
 var configViewCmd = &cobra.Command{
 	Use:   "view",
-	Short: "Display values currently set in the minikube config file",
-	Long:  "Display values currently set in the minikube config file.",
+	Short: "Display the effective config of the profile, and where each value comes from",
+	Long: `Display the effective config of the profile of --profile: the values the next start uses for the
+settings kept along with the cluster, and where they come from, followed by the other values set in
+the environment, as MINIKUBE_<SETTING>, or in the minikube config file. The settings given neither
+as flags nor in the environment keep the values the cluster was last started with (profile), which
+take precedence over the minikube config (config) and the defaults (default).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := configView()
 		if err != nil {
@@ -60,17 +71,61 @@ func configView() error {
 	if err != nil {
 		return err
 	}
-	for k, v := range cfg {
-		tmpl, err := template.New("view").Parse(configViewFormat)
-		if err != nil {
-			glog.Errorln("Error creating view template:", err)
-			os.Exit(1)
+	sources := cluster.SettingSources{Env: settingsEnv(os.Getenv), Config: cfg}
+	api, err := machine.NewAPIClient(GetClientType())
+	if err != nil {
+		glog.Warningf("Not showing the config the cluster was last started with: %s", err)
+	} else {
+		defer api.Close()
+		if sources.Cluster, err = cluster.LoadClusterConfig(api, config.GetMachineName()); err != nil {
+			glog.Warningf("Not showing the config the cluster was last started with: %s", err)
 		}
-		viewTmplt := ConfigViewTemplate{k, v}
-		err = tmpl.Execute(os.Stdout, viewTmplt)
-		if err != nil {
-			glog.Errorln("Error executing view template:", err)
-			os.Exit(1)
+	}
+	tmpl, err := template.New("view").Parse(configViewFormat)
+	if err != nil {
+		glog.Errorln("Error creating view template:", err)
+		os.Exit(1)
+	}
+	if err := printConfigView(os.Stdout, tmpl, configViewEntries(sources)); err != nil {
+		glog.Errorln("Error executing view template:", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// configViewEntries returns the effective values of the stored settings, followed by the other
+// values of the environment and the minikube config, sorted.
+func configViewEntries(sources cluster.SettingSources) []ConfigViewTemplate {
+	var entries []ConfigViewTemplate
+	for _, s := range sources.Resolve() {
+		entries = append(entries, ConfigViewTemplate{ConfigKey: s.Name, ConfigValue: s.Value, Source: s.Source})
+	}
+	var keys []string
+	for k := range sources.Config {
+		if _, ok := sources.Env[k]; !ok && !cluster.IsStoredSetting(k) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range sources.Env {
+		if !cluster.IsStoredSetting(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := sources.Env[k]; ok {
+			entries = append(entries, ConfigViewTemplate{ConfigKey: k, ConfigValue: v, Source: cluster.SourceEnv})
+			continue
+		}
+		entries = append(entries, ConfigViewTemplate{ConfigKey: k, ConfigValue: sources.Config[k], Source: cluster.SourceConfig})
+	}
+	return entries
+}
+
+func printConfigView(w io.Writer, tmpl *template.Template, entries []ConfigViewTemplate) error {
+	for _, e := range entries {
+		if err := tmpl.Execute(w, e); err != nil {
+			return err
 		}
 	}
 	return nil
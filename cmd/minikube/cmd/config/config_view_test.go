@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	"k8s.io/minikube/pkg/minikube/cluster"
+	pkgConfig "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestConfigView(t *testing.T) {
+	sources := cluster.SettingSources{
+		Env:     map[string]string{"cpus": "4"},
+		Cluster: &cluster.ClusterConfig{KubernetesConfig: cluster.KubernetesConfig{KubernetesVersion: "v1.6.4"}},
+		Config:  pkgConfig.MinikubeConfig{"memory": 4096.0, "kubernetes-version": "v1.7.0", "dashboard": false},
+	}
+	entries := configViewEntries(sources)
+	if len(entries) != len(cluster.StoredSettings)+1 {
+		t.Fatalf("Expected the stored settings and dashboard, got %+v", entries)
+	}
+
+	var b bytes.Buffer
+	if err := printConfigView(&b, template.Must(template.New("view").Parse(constants.DefaultConfigViewFormat)), entries); err != nil {
+		t.Fatalf("Error printing the config: %s", err)
+	}
+	for _, line := range []string{
+		"- vm-driver: virtualbox (default)\n",
+		"- memory: 4096 (config)\n",
+		"- cpus: 4 (env)\n",
+		"- kubernetes-version: v1.6.4 (profile)\n",
+		"- dashboard: false (config)\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected the line %q in the config:\n%s", line, b.String())
+		}
+	}
+	if !strings.HasSuffix(b.String(), "- dashboard: false (config)\n") {
+		t.Errorf("Expected the other settings after the stored ones:\n%s", b.String())
+	}
+}
@@ -18,136 +18,136 @@ package config
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
+	"strings"
 
-	"k8s.io/minikube/pkg/minikube/service"
-
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 )
 
+var addonsConfigureValues []string
+
 var addonsConfigureCmd = &cobra.Command{
 	Use:   "configure ADDON_NAME",
 	Short: "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list ",
-	Long:  "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list ",
+	Long: `Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list
+The values are asked for, or set with --set FIELD=VALUE, and kept in the config as ADDON_NAME.FIELD. An enabled addon is applied again with them.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "usage: minikube addons configure ADDON_NAME")
 			os.Exit(1)
 		}
 
-		addon := args[0]
-		// allows for additional prompting of information when enabling addons
-		switch addon {
-		case "registry-creds":
-			posResponses := []string{"yes", "y"}
-			negResponses := []string{"no", "n"}
-
-			// Default values
-			awsAccessID := "changeme"
-			awsAccessKey := "changeme"
-			awsRegion := "changeme"
-			awsAccount := "changeme"
-			gcrApplicationDefaultCredentials := "changeme"
-			dockerServer := "changeme"
-			dockerUser := "changeme"
-			dockerPass := "changeme"
-
-			enableAWSECR := AskForYesNoConfirmation("\nDo you want to enable AWS Elastic Container Registry?", posResponses, negResponses)
-			if enableAWSECR {
-				awsAccessID = AskForStaticValue("-- Enter AWS Access Key ID: ")
-				awsAccessKey = AskForStaticValue("-- Enter AWS Secret Access Key: ")
-				awsRegion = AskForStaticValue("-- Enter AWS Region: ")
-				awsAccount = AskForStaticValue("-- Enter 12 digit AWS Account ID: ")
-			}
-
-			enableGCR := AskForYesNoConfirmation("\nDo you want to enable Google Container Registry?", posResponses, negResponses)
-			if enableGCR {
-				gcrPath := AskForStaticValue("-- Enter path to credentials (e.g. /home/user/.config/gcloud/application_default_credentials.json):")
-
-				// Read file from disk
-				dat, err := ioutil.ReadFile(gcrPath)
-
-				if err != nil {
-					fmt.Println("Could not read file for application_default_credentials.json")
-				} else {
-					gcrApplicationDefaultCredentials = string(dat)
-				}
-			}
-
-			enableDR := AskForYesNoConfirmation("\nDo you want to enable Docker Registry?", posResponses, negResponses)
-			if enableDR {
-				dockerServer = AskForStaticValue("-- Enter docker registry server url: ")
-				dockerUser = AskForStaticValue("-- Enter docker registry username: ")
-				dockerPass = AskForStaticValue("-- Enter docker registry password: ")
-			}
-
-			// Create ECR Secret
-			err := service.CreateSecret(
-				"kube-system",
-				"registry-creds-ecr",
-				map[string]string{
-					"AWS_ACCESS_KEY_ID":     awsAccessID,
-					"AWS_SECRET_ACCESS_KEY": awsAccessKey,
-					"aws-account":           awsAccount,
-					"aws-region":            awsRegion,
-				},
-				map[string]string{
-					"app":   "registry-creds",
-					"cloud": "ecr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
-
-			if err != nil {
-				fmt.Println("ERROR creating `registry-creds-ecr` secret")
-			}
-
-			// Create GCR Secret
-			err = service.CreateSecret(
-				"kube-system",
-				"registry-creds-gcr",
-				map[string]string{
-					"application_default_credentials.json": gcrApplicationDefaultCredentials,
-				},
-				map[string]string{
-					"app":   "registry-creds",
-					"cloud": "gcr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
+		addonName := args[0]
+		addon, ok := assets.Addons[addonName]
+		if !ok {
+			fmt.Fprintln(os.Stderr, fmt.Sprintf("addon '%s' is not a valid addon packaged with minikube", addonName))
+			os.Exit(1)
+		}
+		if len(addon.Config) == 0 {
+			fmt.Fprintln(os.Stdout, fmt.Sprintf("%s has no available configuration options", addonName))
+			return
+		}
 
-			if err != nil {
-				fmt.Println("ERROR creating `registry-creds-gcr` secret")
-			}
+		var values map[string]string
+		var err error
+		if len(addonsConfigureValues) > 0 {
+			values, err = parseConfigValues(addon, addonsConfigureValues)
+		} else if !console.Interactive() {
+			err = fmt.Errorf("minikube is not interactive, pass --set FIELD=VALUE to configure %s", addonName)
+		} else {
+			values, err = askForConfigValues(addon)
+		}
+		if err == nil {
+			err = ConfigureAddon(addon, values)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, fmt.Sprintf("%s was successfully configured", addonName))
+	},
+}
 
-			// Create Docker Secret
-			err = service.CreateSecret(
-				"kube-system",
-				"registry-creds-dpr",
-				map[string]string{
-					"DOCKER_PRIVATE_REGISTRY_SERVER":   dockerServer,
-					"DOCKER_PRIVATE_REGISTRY_USER":     dockerUser,
-					"DOCKER_PRIVATE_REGISTRY_PASSWORD": dockerPass,
-				},
-				map[string]string{
-					"app":   "registry-creds",
-					"cloud": "dpr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
+// parseConfigValues returns the values of the fields of the addon set with --set FIELD=VALUE, by field.
+func parseConfigValues(addon *assets.Addon, sets []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, set := range sets {
+		kv := strings.SplitN(set, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid value %q, FIELD=VALUE was expected", set)
+		}
+		f, ok := addon.ConfigField(kv[0])
+		if !ok {
+			return nil, fmt.Errorf("%s has no config field %s", addon.Name(), kv[0])
+		}
+		v, err := f.Parse(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		values[f.Name] = v
+	}
+	return values, nil
+}
 
+// askForConfigValues asks for the value of each field of the addon, keeping the configured one when none is entered.
+func askForConfigValues(addon *assets.Addon) (map[string]string, error) {
+	m, err := config.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	current := addon.ConfigValues(m)
+	values := map[string]string{}
+	for _, f := range addon.Config {
+		shown, ok := current[f.Name]
+		switch {
+		case ok && f.Secret != "" && f.Kind != assets.ConfigFile:
+			shown = "set"
+		case !ok && f.Kind != assets.ConfigFile:
+			shown = f.Default
+		}
+		for {
+			v, err := f.Parse(AskForValue(fmt.Sprintf("-- %s [%s]: ", f.Description, shown), current[f.Name]))
 			if err != nil {
-				fmt.Println("ERROR creating `registry-creds-dpr` secret")
+				fmt.Println(err)
+				continue
 			}
-
+			values[f.Name] = v
 			break
-		default:
-			fmt.Fprintln(os.Stdout, fmt.Sprintf("%s has no available configuration options", addon))
-			return
 		}
+	}
+	return values, nil
+}
 
-		fmt.Fprintln(os.Stdout, fmt.Sprintf("%s was successfully configured", addon))
-	},
+// ConfigureAddon keeps the values of the fields of the addon in the config, an empty value unsetting
+// its field, and applies the addon again with them if it is enabled.
+func ConfigureAddon(addon *assets.Addon, values map[string]string) error {
+	m, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+	for name, v := range values {
+		key := assets.ConfigKey(addon.Name(), name)
+		if v == "" {
+			delete(m, key)
+		} else {
+			m[key] = v
+		}
+	}
+	if err := WriteConfig(m); err != nil {
+		return errors.Wrapf(err, "Error storing the configuration of addon %s", addon.Name())
+	}
+	enabled, err := addon.IsEnabled()
+	if err != nil || !enabled {
+		return err
+	}
+	return EnableOrDisableAddon(addon.Name(), "true")
 }
 
 func init() {
+	addonsConfigureCmd.Flags().StringArrayVar(&addonsConfigureValues, "set", nil, "Set a field of the configuration of the addon, as in --set FIELD=VALUE, instead of asking for each")
 	AddonsCmd.AddCommand(addonsConfigureCmd)
 }
@@ -26,7 +26,8 @@ import (
 var addonsDisableCmd = &cobra.Command{
 	Use:   "disable ADDON_NAME",
 	Short: "Disables the addon w/ADDON_NAME within minikube (example: minikube addons disable dashboard). For a list of available addons use: minikube addons list ",
-	Long:  "Disables the addon w/ADDON_NAME within minikube (example: minikube addons disable dashboard). For a list of available addons use: minikube addons list ",
+	Long: `Disables the addon w/ADDON_NAME within minikube (example: minikube addons disable dashboard). For a list of available addons use: minikube addons list
+When the cluster is running, the objects of the addon are deleted right away.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "usage: minikube addons disable ADDON_NAME")
@@ -40,6 +41,9 @@ var addonsDisableCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		fmt.Fprintln(os.Stdout, fmt.Sprintf("%s was successfully disabled", addon))
+		if addon == "storage-provisioner" {
+			fmt.Fprintln(os.Stdout, "Run minikube start again for localkube to provision the volumes itself.")
+		}
 	},
 }
 
@@ -23,11 +23,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var addonsRefresh bool
+
 var addonsEnableCmd = &cobra.Command{
 	Use:   "enable ADDON_NAME",
 	Short: "Enables the addon w/ADDON_NAME within minikube (example: minikube addons enable dashboard). For a list of available addons use: minikube addons list ",
-	Long:  "Enables the addon w/ADDON_NAME within minikube (example: minikube addons enable dashboard). For a list of available addons use: minikube addons list ",
+	Long: `Enables the addon w/ADDON_NAME within minikube (example: minikube addons enable dashboard). For a list of available addons use: minikube addons list
+When the cluster is running, the objects of the addon are created right away. "minikube addons enable --refresh" applies all the enabled addons again.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if addonsRefresh {
+			if len(args) != 0 {
+				fmt.Fprintln(os.Stderr, "usage: minikube addons enable --refresh")
+				os.Exit(1)
+			}
+			if err := RefreshAddons(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "usage: minikube addons enable ADDON_NAME")
 			os.Exit(1)
@@ -37,12 +51,26 @@ var addonsEnableCmd = &cobra.Command{
 		err := Set(addon, "true")
 		if err != nil {
 			fmt.Fprintln(os.Stdout, err)
-		} else {
-			fmt.Fprintln(os.Stdout, fmt.Sprintf("%s was successfully enabled", addon))
+			return
+		}
+		fmt.Fprintln(os.Stdout, fmt.Sprintf("%s was successfully enabled", addon))
+		// The config is written once Set returns, so the registry is added to its insecure registries after.
+		if addon == "registry" {
+			if err := TrustRegistry(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		if addon == "storage-provisioner" {
+			fmt.Fprintln(os.Stdout, "Run minikube start again for localkube to leave provisioning the volumes to the addon.")
+		}
+		if addon == "metrics" {
+			fmt.Fprintln(os.Stdout, "If extra config turns off the read-only port of the kubelet, run minikube start again for it to serve the metrics to the addon.")
 		}
 	},
 }
 
 func init() {
+	addonsEnableCmd.Flags().BoolVar(&addonsRefresh, "refresh", false, "Apply all the enabled addons again, instead of enabling one")
 	AddonsCmd.AddCommand(addonsEnableCmd)
 }
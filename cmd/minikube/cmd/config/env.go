@@ -0,0 +1,85 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+var configEnvDocsCmd = &cobra.Command{
+	Use:    "env-docs",
+	Short:  "Prints the environment variables overriding the settings, as a markdown table",
+	Long:   "Prints the environment variables overriding the settings of minikube config, as a markdown table, for docs/env_vars.md.",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		printEnvDocs(os.Stdout)
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(configEnvDocsCmd)
+}
+
+// EnvConfig returns a copy of m with the values of the environment variables of the settings,
+// which take precedence over the config, parsed and validated as minikube config set does.
+func EnvConfig(m config.MinikubeConfig) (config.MinikubeConfig, error) {
+	c := config.MinikubeConfig{}
+	for k, v := range m {
+		c[k] = v
+	}
+	for _, s := range settings {
+		v := os.Getenv(config.EnvName(s.name))
+		if v == "" {
+			continue
+		}
+		if err := run(s.name, v, s.validations); err != nil {
+			return nil, fmt.Errorf("Invalid %s=%q: %v", config.EnvName(s.name), v, err)
+		}
+		if err := s.set(c, s.name, v); err != nil {
+			return nil, fmt.Errorf("Invalid %s=%q: %v", config.EnvName(s.name), v, err)
+		}
+	}
+	return c, nil
+}
+
+// ValidateEnv checks the values of the environment variables of the settings.
+func ValidateEnv() error {
+	_, err := EnvConfig(config.MinikubeConfig{})
+	return err
+}
+
+// settingsEnv returns the values of the environment variables of the settings which getenv has, by name.
+func settingsEnv(getenv func(string) string) map[string]string {
+	var names []string
+	for _, s := range settings {
+		names = append(names, s.name)
+	}
+	return config.EnvValues(names, getenv)
+}
+
+func printEnvDocs(w io.Writer) {
+	fmt.Fprintln(w, "| Setting | Environment variable |")
+	fmt.Fprintln(w, "|---------|----------------------|")
+	for _, s := range settings {
+		fmt.Fprintf(w, "| `%s` | `%s` |\n", s.name, config.EnvName(s.name))
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// envSamples are values tried in turn for each setting, until one is valid for it.
+var envSamples = []string{"true", "2048", "virtualbox", "20g", "192.168.99.1/24", "kubelet.MaxPods=100", "cluster.local", "docker", "minikube", "v1.7.0", "https://storage.googleapis.com/minikube/iso/", os.TempDir()}
+
+func TestEnvDocs(t *testing.T) {
+	defer useTempMinikubeHome(t)()
+
+	var b bytes.Buffer
+	printEnvDocs(&b)
+	for _, s := range settings {
+		name := config.EnvName(s.name)
+		row := fmt.Sprintf("| `%s` | `%s` |\n", s.name, name)
+		if !strings.Contains(b.String(), row) {
+			t.Errorf("Expected the row %q in the docs:\n%s", row, b.String())
+		}
+
+		// Every setting's variable overrides the config.
+		t.Run(s.name, func(t *testing.T) {
+			defer os.Unsetenv(name)
+			for _, v := range envSamples {
+				os.Setenv(name, v)
+				m, err := EnvConfig(config.MinikubeConfig{s.name: "config"})
+				if err != nil {
+					continue
+				}
+				if m[s.name] == "config" {
+					t.Fatalf("Expected %s=%s to override the config, got %v", name, v, m[s.name])
+				}
+				if got, err := config.Get(s.name); err != nil || got != v {
+					t.Errorf("Expected %s to be %s, got %q, %v", s.name, v, got, err)
+				}
+				return
+			}
+			t.Errorf("None of %v is a valid value of %s, add one to envSamples", envSamples, s.name)
+		})
+	}
+}
+
+func TestEnvConfigInvalid(t *testing.T) {
+	var tests = []struct {
+		env   string
+		value string
+	}{
+		{env: "MINIKUBE_MEMORY", value: "lots"},
+		{env: "MINIKUBE_EMBED_CERTS", value: "yes please"},
+		{env: "MINIKUBE_VM_DRIVER", value: "qemu"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.env, func(t *testing.T) {
+			os.Setenv(test.env, test.value)
+			defer os.Unsetenv(test.env)
+			err := ValidateEnv()
+			if err == nil {
+				t.Fatalf("Expected an error for %s=%s", test.env, test.value)
+			}
+			if !strings.Contains(err.Error(), test.env) {
+				t.Errorf("Expected the error to name %s: %s", test.env, err)
+			}
+		})
+	}
+}
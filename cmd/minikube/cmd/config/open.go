@@ -21,9 +21,11 @@ import (
 	"os"
 	"text/template"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/spf13/cobra"
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/service"
 )
@@ -84,6 +86,30 @@ minikube addons enable %s`, addonName, addonName))
 			os.Exit(1)
 		}
 
+		if addonName == "ingress" {
+			printIngressHosts(api)
+			return
+		}
+		if addonName == "dashboard" {
+			fmt.Fprintln(os.Stdout, `The dashboard is not exposed on a node port. To open it through a proxy
+on the loopback interface, run:
+minikube dashboard`)
+			return
+		}
+
+		if addonName == "metrics" {
+			t, err := metricsURLTemplate(addonsURLFormat)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "The value passed to --format is invalid:\n\n", err)
+				os.Exit(1)
+			}
+			if err := service.WaitAndMaybeOpenService(api, "kube-system", "monitoring-grafana", t, addonsURLMode, https, service.DefaultWaitTimeout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening grafana: %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		namespace := "kube-system"
 		key := "kubernetes.io/minikube-addons-endpoint"
 
@@ -101,12 +127,40 @@ You can add one by annotating a service with the label %s:%s
 		}
 		for i := range serviceList.Items {
 			svc := serviceList.Items[i].ObjectMeta.Name
-			service.WaitAndMaybeOpenService(api, namespace, svc, addonsURLTemplate, addonsURLMode, https)
+			service.WaitAndMaybeOpenService(api, namespace, svc, addonsURLTemplate, addonsURLMode, https, service.DefaultWaitTimeout)
 
 		}
 	},
 }
 
+// metricsURLTemplate returns the template of the URL of grafana, which the metrics addon serves on
+// a node port. The default format opens its dashboard of the cluster, others are left as they are.
+func metricsURLTemplate(format string) (*template.Template, error) {
+	if format == defaultAddonsFormatTemplate {
+		format += constants.MetricsDashboardPath
+	}
+	return template.New("metricsURL").Parse(format)
+}
+
+// printIngressHosts prints the IP the hosts of the ingresses resolve to, the ingress controller
+// listening on the ports 80 and 443 of the VM.
+func printIngressHosts(api libmachine.API) {
+	host, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting host: %s\n", err)
+		os.Exit(1)
+	}
+	ip, err := host.Driver.GetIP()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting the IP of the VM: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stdout, `The ingress controller serves the ports 80 and 443 of %s.
+To reach the hosts of your ingresses, add them to /etc/hosts with this IP, as in:
+%s myapp.example.com
+`, ip, ip)
+}
+
 func init() {
 	addonsOpenCmd.Flags().BoolVar(&addonsURLMode, "url", false, "Display the kubernetes addons URL in the CLI instead of opening it in the default browser")
 	addonsOpenCmd.Flags().BoolVar(&https, "https", false, "Open the addons URL with https instead of http")
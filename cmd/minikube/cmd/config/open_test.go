@@ -0,0 +1,64 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetricsURLTemplate(t *testing.T) {
+	var tests = []struct {
+		description string
+		format      string
+		expected    string
+	}{
+		{
+			description: "default format",
+			format:      defaultAddonsFormatTemplate,
+			expected:    "http://192.168.99.100:30123/dashboard/db/cluster",
+		},
+		{
+			description: "custom format",
+			format:      "{{.IP}}:{{.Port}}",
+			expected:    "192.168.99.100:30123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			tmpl, err := metricsURLTemplate(test.format)
+			if err != nil {
+				t.Fatalf("Error parsing the format: %s", err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, struct {
+				IP   string
+				Port int32
+			}{"192.168.99.100", 30123}); err != nil {
+				t.Fatalf("Error executing the template: %s", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, buf.String())
+			}
+		})
+	}
+
+	if _, err := metricsURLTemplate("{{.IP"); err == nil {
+		t.Error("Expected an error parsing an invalid format")
+	}
+}
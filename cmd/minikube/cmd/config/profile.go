@@ -18,16 +18,30 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"text/tabwriter"
 
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	pkgConfig "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+var switchContext bool
+
 var ProfileCmd = &cobra.Command{
 	Use:   "profile MINIKUBE_PROFILE_NAME.  You can return the the default minikube name by running `minikube profile default`",
 	Short: "Profile sets the current minikube profile",
-	Long:  "profile sets the current minikube profile.  This is used to run and manage multiple minikube instance.  You can return to the default minikube name by running `minikube profile default`",
+	Long: "profile sets the current minikube profile.  This is used to run and manage multiple minikube instance.  You can return to the default minikube name by running `minikube profile default`, and list the profiles with `minikube profile list`.\n\n" +
+		"The profile is recorded in the minikube directory, and used by the commands run without --profile or $MINIKUBE_PROFILE. It doesn't need to exist yet, minikube start creates it.",
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "usage: minikube profile MINIKUBE_PROFILE_NAME")
@@ -36,13 +50,160 @@ var ProfileCmd = &cobra.Command{
 
 		profile := args[0]
 		if profile == "default" {
-			profile = "minikube"
+			profile = constants.DefaultMachineName
+		}
+		if err := pkgConfig.SetActiveProfile(profile); err != nil {
+			fmt.Fprintln(os.Stderr, "Error setting the profile:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, fmt.Sprintf("minikube profile was successfully set to %s", profile))
+		if exists, err := profileExists(profile); err != nil {
+			glog.Warningf("Error checking that profile %s exists: %s", profile, err)
+		} else if !exists {
+			fmt.Fprintf(os.Stdout, "Profile %s doesn't exist yet, `minikube start` will create it\n", profile)
+		}
+		if switchContext {
+			if err := kubeconfig.SetCurrentContext(kubeconfig.DefaultPath(), profile); err != nil {
+				fmt.Fprintln(os.Stderr, "Error switching the kubectl context:", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stdout, "kubectl is now using the %s context\n", profile)
+		}
+	},
+}
+
+// profileExists returns whether the named profile has a config or a VM.
+func profileExists(profile string) (bool, error) {
+	profiles, err := pkgConfig.ListProfiles()
+	if err != nil {
+		return false, err
+	}
+	if profile != constants.DefaultMachineName && containsString(profiles, profile) {
+		return true, nil
+	}
+	api, err := machine.NewAPIClient(GetClientType())
+	if err != nil {
+		return false, err
+	}
+	defer api.Close()
+	return api.Exists(profile)
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the minikube profiles and their clusters",
+	Long:  "Lists the minikube profiles, the ones with a config or a VM, with the driver, Kubernetes version and status of their clusters. The current profile is marked with *.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			fmt.Fprintln(os.Stderr, "usage: minikube profile list")
+			os.Exit(1)
 		}
-		err := Set(pkgConfig.MachineProfile, profile)
+		api, err := machine.NewAPIClient(GetClientType())
 		if err != nil {
-			fmt.Fprintln(os.Stdout, err)
-		} else {
-			fmt.Fprintln(os.Stdout, fmt.Sprintf("minikube profile was successfully set the %s", profile))
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
 		}
+		defer api.Close()
+		profiles, err := listProfiles(api)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		printProfileList(os.Stdout, profiles)
 	},
 }
+
+func init() {
+	ProfileCmd.Flags().BoolVar(&switchContext, "switch-context", false, "Also make the profile's context the current one of kubectl")
+	ProfileCmd.AddCommand(profileListCmd)
+}
+
+// profileListEntry is a profile, the driver, Kubernetes version and status of its cluster's VM,
+// and whether it is the current one.
+type profileListEntry struct {
+	Name              string
+	Driver            string
+	KubernetesVersion string
+	Status            string
+	Current           bool
+}
+
+// listProfiles returns the profiles with a config, along with the ones with a VM, the default one first.
+func listProfiles(api libmachine.API) ([]profileListEntry, error) {
+	names, err := pkgConfig.ListProfiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing the profiles")
+	}
+	machines, err := api.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing the machines")
+	}
+	sort.Strings(machines)
+	for _, m := range machines {
+		if !containsString(names, m) {
+			names = append(names, m)
+		}
+	}
+	sort.Strings(names[1:])
+	var profiles []profileListEntry
+	for _, name := range names {
+		s, err := machine.GetState(api, name)
+		status := s.String()
+		switch {
+		case err != nil:
+			status = "Error: " + err.Error()
+		case s == state.None:
+			status = constants.MachineDoesNotExist
+		}
+		driver, version := profileDriverAndVersion(api, name, s != state.None)
+		profiles = append(profiles, profileListEntry{Name: name, Driver: driver, KubernetesVersion: version, Status: status,
+			Current: name == pkgConfig.GetMachineName()})
+	}
+	return profiles, nil
+}
+
+// profileDriverAndVersion returns the driver of the named profile's VM and the Kubernetes version
+// it was last started with, falling back to the ones of its config before it was.
+func profileDriverAndVersion(api libmachine.API, name string, exists bool) (string, string) {
+	var driver, version string
+	if exists {
+		if h, err := api.Load(name); err == nil {
+			driver = h.DriverName
+		}
+	}
+	if s, err := cluster.LoadStartState(name); err == nil {
+		version = s.RunningKubernetesVersion
+		if version == "" {
+			version = s.KubernetesVersion
+		}
+	}
+	if m, err := pkgConfig.ReadProfileConfig(name); err == nil {
+		if d, ok := m["vm-driver"].(string); ok && driver == "" {
+			driver = d
+		}
+		if v, ok := m["kubernetes-version"].(string); ok && version == "" {
+			version = v
+		}
+	}
+	return driver, version
+}
+
+func printProfileList(out io.Writer, profiles []profileListEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tVM DRIVER\tKUBERNETES VERSION\tSTATUS\tCURRENT")
+	for _, p := range profiles {
+		current := ""
+		if p.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, orDash(p.Driver), orDash(p.KubernetesVersion), p.Status, current)
+	}
+	w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/spf13/viper"
+	pkgConfig "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+// useTempMinikubeHome points MINIKUBE_HOME and the global config file at a temp dir,
+// returning a function to remove it and restore them.
+func useTempMinikubeHome(t *testing.T) func() {
+	dir := tests.MakeTempDir()
+	configFile := constants.ConfigFile
+	constants.ConfigFile = filepath.Join(dir, "config", "config.json")
+	if err := os.MkdirAll(filepath.Dir(constants.ConfigFile), 0755); err != nil {
+		t.Fatalf("Error creating dir: %s", err)
+	}
+	return func() {
+		constants.ConfigFile = configFile
+		os.Unsetenv(constants.MinikubeHome)
+		os.RemoveAll(filepath.Dir(dir))
+		viper.Reset()
+	}
+}
+
+func TestWriteProfileConfig(t *testing.T) {
+	defer useTempMinikubeHome(t)()
+
+	if err := WriteConfig(pkgConfig.MinikubeConfig{"memory": 4096.0}); err != nil {
+		t.Fatalf("Error writing the default profile's config: %s", err)
+	}
+	viper.Set(pkgConfig.MachineProfile, "project")
+	if err := WriteConfig(pkgConfig.MinikubeConfig{"cpus": 4.0, "profile": "project", "WantUpdateNotification": false}); err != nil {
+		t.Fatalf("Error writing the profile's config: %s", err)
+	}
+
+	var tests = []struct {
+		profile  string
+		expected pkgConfig.MinikubeConfig
+	}{
+		{
+			profile:  constants.DefaultMachineName,
+			expected: pkgConfig.MinikubeConfig{"memory": 4096.0, "profile": "project", "WantUpdateNotification": false},
+		},
+		{
+			profile:  "project",
+			expected: pkgConfig.MinikubeConfig{"cpus": 4.0},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.profile, func(t *testing.T) {
+			m, err := pkgConfig.ReadConfigFile(constants.ProfileConfigFile(test.profile))
+			if err != nil {
+				t.Fatalf("Error reading config: %s", err)
+			}
+			if !reflect.DeepEqual(m, test.expected) {
+				t.Errorf("Expected config %v, got %v", test.expected, m)
+			}
+		})
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	defer useTempMinikubeHome(t)()
+	viper.Set(pkgConfig.MachineProfile, "project")
+	path := constants.ProfileConfigFile("project")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Error creating dir: %s", err)
+	}
+	if err := writeConfigFile(path, pkgConfig.MinikubeConfig{"vm-driver": "xhyve", "kubernetes-version": "v1.7.0"}); err != nil {
+		t.Fatalf("Error writing config: %s", err)
+	}
+
+	api := tests.NewMockAPI()
+	api.Hosts["minikube"] = &host.Host{Name: "minikube", DriverName: "virtualbox", Driver: &tests.MockDriver{CurrentState: state.Running}}
+	api.Hosts["other"] = &host.Host{Name: "other", DriverName: "kvm2", Driver: &tests.MockDriver{CurrentState: state.Stopped}}
+
+	profiles, err := listProfiles(api)
+	if err != nil {
+		t.Fatalf("Error listing profiles: %s", err)
+	}
+	expected := []profileListEntry{
+		{Name: "minikube", Driver: "virtualbox", Status: state.Running.String()},
+		{Name: "other", Driver: "kvm2", Status: state.Stopped.String()},
+		{Name: "project", Driver: "xhyve", KubernetesVersion: "v1.7.0", Status: constants.MachineDoesNotExist, Current: true},
+	}
+	if !reflect.DeepEqual(profiles, expected) {
+		t.Errorf("Expected profiles %+v, got %+v", expected, profiles)
+	}
+
+	var b bytes.Buffer
+	printProfileList(&b, profiles)
+	for _, row := range []string{
+		"PROFILE   VM DRIVER   KUBERNETES VERSION  STATUS",
+		"minikube  virtualbox  -                   Running",
+		"project   xhyve       v1.7.0              Does Not Exist  *",
+	} {
+		if !strings.Contains(b.String(), row) {
+			t.Errorf("Expected the row %q in the list:\n%s", row, b.String())
+		}
+	}
+}
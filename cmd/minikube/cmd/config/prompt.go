@@ -22,13 +22,19 @@ import (
 	"log"
 	"os"
 	"strings"
+
+	"k8s.io/minikube/pkg/minikube/console"
 )
 
 // AskForYesNoConfirmation asks the user for confirmation. A user must type in "yes" or "no" and
 // then press enter. It has fuzzy matching, so "y", "Y", "yes", "YES", and "Yes" all count as
 // confirmations. If the input is not recognized, it will ask again. The function does not return
-// until it gets a valid response from the user.
+// until it gets a valid response from the user. When minikube isn't interactive, it returns
+// false without asking.
 func AskForYesNoConfirmation(s string, posResponses, negResponses []string) bool {
+	if !console.Interactive() {
+		return false
+	}
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
@@ -52,8 +58,12 @@ func AskForYesNoConfirmation(s string, posResponses, negResponses []string) bool
 	}
 }
 
-// AskForStaticValue asks for a single value to enter
+// AskForStaticValue asks for a single value to enter. As there is no default value, it
+// fails when minikube isn't interactive.
 func AskForStaticValue(s string) string {
+	if !console.Interactive() {
+		log.Fatalf("%sminikube is not interactive, pass --interactive to enter a value", s)
+	}
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
@@ -75,6 +85,26 @@ func AskForStaticValue(s string) string {
 	}
 }
 
+// stdin is shared by the questions of AskForValue, so that the answers piped to them aren't lost.
+var stdin = bufio.NewReader(os.Stdin)
+
+// AskForValue asks for a single value, returning current if none is entered, or without
+// asking when minikube isn't interactive.
+func AskForValue(s string, current string) string {
+	if !console.Interactive() {
+		return current
+	}
+	fmt.Printf("%s", s)
+	response, err := stdin.ReadString('\n')
+	if err != nil {
+		log.Fatal(err)
+	}
+	if response = strings.TrimSpace(response); response == "" {
+		return current
+	}
+	return response
+}
+
 // posString returns the first index of element in slice.
 // If slice does not contain element, returns -1.
 func posString(slice []string, element string) int {
@@ -20,17 +20,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/addons"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/autorestart"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/sshutil"
-	"k8s.io/minikube/pkg/minikube/storageclass"
+	"k8s.io/minikube/pkg/util"
 )
 
 // Runs all the validation or callback functions and collects errors
@@ -54,7 +59,59 @@ func findSetting(name string) (Setting, error) {
 			return s, nil
 		}
 	}
-	return Setting{}, fmt.Errorf("Property name %s not found", name)
+	if setting, driver, ok := config.SplitDriverKey(name); ok {
+		return driverSetting(setting, driver)
+	}
+	if s, ok := addonConfigSetting(name); ok {
+		return s, nil
+	}
+	return Setting{}, unknownPropertyError(name)
+}
+
+// addonConfigSetting returns the setting of the field of the configuration of an addon, named
+// <addon>.<field>, which is validated and kept as the field is.
+func addonConfigSetting(name string) (Setting, bool) {
+	i := strings.Index(name, ".")
+	if i < 0 {
+		return Setting{}, false
+	}
+	addon, ok := assets.Addons[name[:i]]
+	if !ok {
+		return Setting{}, false
+	}
+	f, ok := addon.ConfigField(name[i+1:])
+	if !ok {
+		return Setting{}, false
+	}
+	validate := func(_ string, value string) error {
+		return f.Validate(value)
+	}
+	set := func(m config.MinikubeConfig, name string, value string) error {
+		v, err := f.Parse(value)
+		if err != nil {
+			return err
+		}
+		return SetString(m, name, v)
+	}
+	return Setting{name: name, set: set, validations: []setFn{validate}}, true
+}
+
+// driverSetting returns the setting overriding the named setting for the driver,
+// which is validated like the setting it overrides.
+func driverSetting(name, driver string) (Setting, error) {
+	s, err := findSetting(name)
+	if err != nil {
+		return Setting{}, err
+	}
+	validDriver := func(string, string) error {
+		return IsValidDriver(name, driver)
+	}
+	return Setting{
+		name:        config.DriverKey(name, driver),
+		set:         s.set,
+		validations: append([]setFn{validDriver}, s.validations...),
+		callbacks:   s.callbacks,
+	}, nil
 }
 
 // Set Functions
@@ -82,18 +139,44 @@ func SetBool(m config.MinikubeConfig, name string, val string) error {
 	return nil
 }
 
+// SetExtraConfig merges the extra config in val with the one already set, the value
+// in val replacing the one for the same component and key.
+func SetExtraConfig(m config.MinikubeConfig, name string, val string) error {
+	stored, err := util.ParseExtraOptions(config.StoredExtraConfig(m))
+	if err != nil {
+		return err
+	}
+	e, err := util.ParseExtraOptions([]string{val})
+	if err != nil {
+		return err
+	}
+	m[name] = stored.Merge(e).Strings()
+	return nil
+}
+
+// SetInsecureRegistry adds the comma separated insecure registries in val to the ones already set.
+func SetInsecureRegistry(m config.MinikubeConfig, name string, val string) error {
+	m[name] = util.MergeInsecureRegistries(config.StoredInsecureRegistries(m), strings.Split(val, ","))
+	return nil
+}
+
 func GetClientType() machine.ClientType {
+	if viper.GetString(config.RemoteHost) != "" {
+		return machine.ClientTypeSSH
+	}
 	if viper.GetBool(useVendoredDriver) {
 		return machine.ClientTypeLocal
 	}
 	return machine.ClientTypeRPC
 }
 
+// EnableOrDisableAddon transfers or deletes the manifests of the addon in the VM and, the cluster
+// running, creates or deletes its objects through the apiserver right away. When the cluster is not
+// running, the addon is only enabled or disabled in the config, which the next start applies.
 func EnableOrDisableAddon(name string, val string) error {
-
 	enable, err := strconv.ParseBool(val)
 	if err != nil {
-		errors.Wrapf(err, "error attempted to parse enabled/disable value addon %s", name)
+		return errors.Wrapf(err, "error attempted to parse enabled/disable value addon %s", name)
 	}
 
 	//TODO(r2d4): config package should not reference API, pull this out
@@ -103,13 +186,19 @@ func EnableOrDisableAddon(name string, val string) error {
 		os.Exit(1)
 	}
 	defer api.Close()
-	cluster.EnsureMinikubeRunningOrExit(api, 0)
+	if s, err := cluster.GetHostStatus(api); err != nil || s != state.Running.String() {
+		fmt.Fprintf(os.Stdout, "minikube is not running, %s takes effect on the next start\n", name)
+		return nil
+	}
 
-	addon, _ := assets.Addons[name] // validation done prior
+	addon, err := configuredAddon(assets.Addons[name]) // validation done prior
 	if err != nil {
 		return err
 	}
 	host, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error loading host")
+	}
 	if enable {
 		if err = transferAddon(addon, host.Driver); err != nil {
 			return errors.Wrapf(err, "Error transferring addon %s to VM", name)
@@ -119,6 +208,147 @@ func EnableOrDisableAddon(name string, val string) error {
 			return errors.Wrapf(err, "Error deleting addon %s from VM", name)
 		}
 	}
+	return applyAddon(name, addon, enable)
+}
+
+// configuredAddon returns the addon with its manifests set up as the config asks: with the values
+// of its configuration, and their images pulled from the image repository.
+func configuredAddon(addon *assets.Addon) (*assets.Addon, error) {
+	m, err := config.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	addon, err = addon.WithConfigValues(addon.ConfigValues(m))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error configuring addon %s", addon.Name())
+	}
+	return cluster.AddonWithImageRepository(addon, viper.GetString(config.ImageRepository)), nil
+}
+
+// TrustRegistry prints the address the host pushes images to the registry addon at, and adds it
+// to the insecure registries of the docker daemon of the VM, and of the config for later starts,
+// unless they cover it already.
+func TrustRegistry() error {
+	api, err := machine.NewAPIClient(GetClientType())
+	if err != nil {
+		return errors.Wrap(err, "Error getting client")
+	}
+	defer api.Close()
+	if s, err := cluster.GetHostStatus(api); err != nil || s != state.Running.String() {
+		fmt.Fprintln(os.Stdout, "Enable registry again once minikube is running to add it to the insecure registries of the docker daemon")
+		return nil
+	}
+	h, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error loading host")
+	}
+	registry, err := cluster.RegistryAddress(h)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, `The registry is at %s on the host, and at localhost:5000 in the cluster. Push images to it with:
+docker tag myimage %s/myimage && docker push %s/myimage
+and run them as localhost:5000/myimage.
+`, registry, registry, registry)
+	if h.DriverName == "none" {
+		fmt.Fprintf(os.Stdout, "Add %s to the insecure registries of your docker daemon to push to it.\n", registry)
+		return nil
+	}
+
+	m, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+	if !util.InsecureRegistryCovered(config.StoredInsecureRegistries(m), registry) {
+		if err := Set(config.InsecureRegistry, registry); err != nil {
+			return errors.Wrapf(err, "Error adding %s to the insecure registries", registry)
+		}
+	}
+	added, err := cluster.AddInsecureRegistry(api, h, registry)
+	if added {
+		fmt.Fprintf(os.Stdout, "Restarted the docker daemon of the VM with %s among its insecure registries\n", registry)
+	}
+	return err
+}
+
+// applyAddon creates or deletes the objects of the addon through the apiserver, rather than
+// waiting for the addon manager, printing the result for each object. Deleting them also deletes
+// the objects the addon manager doesn't prune, such as storage classes.
+func applyAddon(name string, addon *assets.Addon, enable bool) error {
+	objs, err := addons.Objects(addon)
+	if err != nil {
+		return errors.Wrapf(err, "Error decoding the manifests of addon %s", name)
+	}
+	clients, err := addons.NewClients()
+	if err != nil {
+		return err
+	}
+	var results []addons.Result
+	if enable {
+		results = clients.Apply(objs)
+		if !addons.Failed(results) {
+			unset, err := clients.UnsetOtherDefaultClasses(objs)
+			if err != nil {
+				return err
+			}
+			results = append(results, unset...)
+		}
+	} else {
+		results = clients.Delete(objs)
+	}
+	for _, r := range results {
+		fmt.Fprintln(os.Stdout, r)
+	}
+	if addons.Failed(results) {
+		return errors.Errorf("Error applying the objects of addon %s", name)
+	}
+	return nil
+}
+
+// RefreshAddons transfers the manifests of the enabled addons to the VM and applies their
+// objects again, such as after they were changed or deleted in the cluster.
+func RefreshAddons() error {
+	api, err := machine.NewAPIClient(GetClientType())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+		os.Exit(1)
+	}
+	defer api.Close()
+	cluster.EnsureMinikubeRunningOrExit(api, 1)
+	host, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error loading host")
+	}
+
+	names := []string{}
+	for name := range assets.Addons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var failed []string
+	for _, name := range names {
+		addon := assets.Addons[name]
+		enabled, err := addon.IsEnabled()
+		if err != nil {
+			return errors.Wrapf(err, "Error getting whether addon %s is enabled", name)
+		}
+		if !enabled {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "Refreshing %s\n", name)
+		if addon, err = configuredAddon(addon); err != nil {
+			return err
+		}
+		if err := transferAddon(addon, host.Driver); err != nil {
+			return errors.Wrapf(err, "Error transferring addon %s to VM", name)
+		}
+		if err := applyAddon(name, addon, true); err != nil {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("Error refreshing addons %s", strings.Join(failed, ", "))
+	}
 	return nil
 }
 
@@ -149,7 +379,7 @@ func deleteAddon(addon *assets.Addon, d drivers.Driver) error {
 func deleteAddonLocal(addon *assets.Addon, d drivers.Driver) error {
 	var err error
 	for _, f := range addon.Assets {
-		if err = os.Remove(filepath.Join(f.GetTargetDir(), f.GetTargetName())); err != nil {
+		if err = os.Remove(filepath.Join(f.GetTargetDir(), f.GetTargetName())); err != nil && !os.IsNotExist(err) {
 			return err
 		}
 	}
@@ -167,20 +397,20 @@ func transferAddonSSH(addon *assets.Addon, d drivers.Driver) error {
 	return nil
 }
 
-func EnableOrDisableDefaultStorageClass(name, val string) error {
+// EnableOrDisableAutoRestart installs or removes the unit starting the cluster of the current profile at login.
+func EnableOrDisableAutoRestart(name, val string) error {
 	enable, err := strconv.ParseBool(val)
 	if err != nil {
 		return errors.Wrap(err, "Error parsing boolean")
 	}
-
-	// Special logic to disable the default storage class
 	if !enable {
-		err := storageclass.DisableDefaultStorageClass()
-		if err != nil {
-			return errors.Wrap(err, "Error disabling default storage class")
-		}
+		return autorestart.Uninstall(config.GetMachineName())
+	}
+	u, err := autorestart.NewUnit(config.GetMachineName())
+	if err != nil {
+		return err
 	}
-	return EnableOrDisableAddon(name, val)
+	return autorestart.Install(u)
 }
 
 func transferAddon(addon *assets.Addon, d drivers.Driver) error {
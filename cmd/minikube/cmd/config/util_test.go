@@ -17,8 +17,12 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"reflect"
 	"testing"
 
+	"k8s.io/minikube/pkg/minikube/assets"
 	pkgConfig "k8s.io/minikube/pkg/minikube/config"
 )
 
@@ -45,6 +49,65 @@ func TestFindSetting(t *testing.T) {
 	}
 }
 
+func TestFindDriverSetting(t *testing.T) {
+	s, err := findSetting("memory.virtualbox")
+	if err != nil {
+		t.Fatalf("Couldn't find setting, memory.virtualbox: %s", err)
+	}
+	if s.name != "memory.virtualbox" {
+		t.Fatalf("Found wrong setting, expected memory.virtualbox, got %s", s.name)
+	}
+	if err := run(s.name, "4096", s.validations); err != nil {
+		t.Errorf("Unexpected error validating memory.virtualbox: %s", err)
+	}
+	if err := run(s.name, "256", s.validations); err == nil {
+		t.Errorf("Expected an error validating memory.virtualbox below the minimum")
+	}
+	if s, err := findSetting("memory.notadriver"); err != nil {
+		t.Fatalf("Couldn't find setting, memory.notadriver: %s", err)
+	} else if err := run(s.name, "4096", s.validations); err == nil {
+		t.Errorf("Expected an error validating a setting for an unsupported driver")
+	}
+	if _, err := findSetting("iso-url.virtualbox"); err == nil {
+		t.Errorf("Shouldn't have found setting iso-url.virtualbox")
+	}
+}
+
+func TestFindAddonConfigSetting(t *testing.T) {
+	s, err := findSetting("registry.storage-size")
+	if err != nil {
+		t.Fatalf("Couldn't find setting, registry.storage-size: %s", err)
+	}
+	if err := run(s.name, "20Gi", s.validations); err != nil {
+		t.Errorf("Unexpected error validating registry.storage-size: %s", err)
+	}
+	if err := run(s.name, "big", s.validations); err == nil {
+		t.Errorf("Expected an error validating an invalid registry.storage-size")
+	}
+	for _, name := range []string{"registry.unknown", "notanaddon.storage-size"} {
+		if _, err := findSetting(name); err == nil {
+			t.Errorf("Shouldn't have found setting %s", name)
+		}
+	}
+}
+
+func TestParseConfigValues(t *testing.T) {
+	addon := assets.Addons["registry-creds"]
+	values, err := parseConfigValues(addon, []string{"aws-region=eu-west-1", "docker-password=a=b", "docker-user="})
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the values: %s", err)
+	}
+	expected := map[string]string{"aws-region": "eu-west-1", "docker-password": "a=b", "docker-user": ""}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+	for _, set := range []string{"aws-region", "unknown=value", "gcr-credentials=/missing/credentials.json"} {
+		if _, err := parseConfigValues(addon, []string{set}); err == nil {
+			t.Errorf("Expected an error parsing %q", set)
+		}
+	}
+}
+
 func TestSetString(t *testing.T) {
 	err := SetString(minikubeConfig, "vm-driver", "virtualbox")
 	if err != nil {
@@ -79,3 +142,43 @@ func TestSetBool(t *testing.T) {
 		t.Fatalf("SetBool set wrong value")
 	}
 }
+
+func TestSetExtraConfig(t *testing.T) {
+	m := pkgConfig.MinikubeConfig{}
+	for _, v := range []string{"kubelet.MaxPods=5", "apiserver.Authorization.Mode=RBAC", "kubelet.MaxPods=10"} {
+		if err := SetExtraConfig(m, pkgConfig.ExtraConfig, v); err != nil {
+			t.Fatalf("Couldn't set extra config %s: %s", v, err)
+		}
+	}
+
+	// The config is read back from JSON, as a list of interfaces.
+	var b bytes.Buffer
+	if err := encode(&b, m); err != nil {
+		t.Fatalf("Error encoding config: %s", err)
+	}
+	var decoded pkgConfig.MinikubeConfig
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatalf("Error decoding config: %s", err)
+	}
+	expected := []string{"kubelet.MaxPods=10", "apiserver.Authorization.Mode=RBAC"}
+	if got := pkgConfig.StoredExtraConfig(decoded); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected extra config %v, got %v", expected, got)
+	}
+
+	if err := SetExtraConfig(m, pkgConfig.ExtraConfig, "kubelet"); err == nil {
+		t.Errorf("Expected an error setting extra config without a key")
+	}
+}
+
+func TestSetInsecureRegistry(t *testing.T) {
+	m := pkgConfig.MinikubeConfig{}
+	for _, v := range []string{"registry.lan:5000", "192.168.1.0/24,registry.lan:5000"} {
+		if err := SetInsecureRegistry(m, pkgConfig.InsecureRegistry, v); err != nil {
+			t.Fatalf("Couldn't set insecure registry %s: %s", v, err)
+		}
+	}
+	expected := []string{"registry.lan:5000", "192.168.1.0/24"}
+	if got := pkgConfig.StoredInsecureRegistries(m); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected insecure registries %v, got %v", expected, got)
+	}
+}
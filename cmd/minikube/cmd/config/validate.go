@@ -0,0 +1,166 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+)
+
+// invalidConfigExitCode is what minikube config validate exits with when a value is invalid.
+const invalidConfigExitCode = 2
+
+// maxSuggestionDistance is how many edits away from an unknown property the ones suggested instead can be.
+const maxSuggestionDistance = 2
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks every value of the minikube config",
+	Long: `Checks every value kept in the minikube config of the profile, as minikube config set checks them, and
+reports all of the invalid ones at once, exiting with code 2 if there is any. Unknown properties are only
+warned about, along with the known ones close to them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(validateConfig(os.Stdout, os.Stderr))
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(configValidateCmd)
+}
+
+// validateConfig checks the config of the current profile, printing its problems to errOut,
+// and returns the code minikube config validate exits with.
+func validateConfig(out, errOut io.Writer) int {
+	m, err := config.ReadConfig()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return invalidConfigExitCode
+	}
+	errs, warnings := configProblems(m)
+	for _, w := range warnings {
+		fmt.Fprintln(errOut, "Warning:", w)
+	}
+	for _, err := range errs {
+		fmt.Fprintln(errOut, err)
+	}
+	if len(errs) > 0 {
+		return invalidConfigExitCode
+	}
+	fmt.Fprintln(out, "The config is valid")
+	return 0
+}
+
+// configProblems returns what is wrong with m, by property: the errors of its invalid values,
+// and warnings about the properties which are no settings.
+func configProblems(m config.MinikubeConfig) (errs []error, warnings []string) {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s, err := findSetting(name)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+			continue
+		}
+		for _, v := range storedValues(m[name]) {
+			if err := validateValue(s, name, v); err != nil {
+				errs = append(errs, fmt.Errorf("Invalid %s=%q: %v", name, v, err))
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// validateValue checks a value as minikube config set does, without its callbacks.
+func validateValue(s Setting, name, value string) error {
+	if err := run(name, value, s.validations); err != nil {
+		return err
+	}
+	return s.set(config.MinikubeConfig{}, name, value)
+}
+
+// storedValues returns the values kept in the config for a property, as they are set: each
+// one of a list, or the value itself.
+func storedValues(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%v", v)}
+	}
+	var values []string
+	for _, e := range list {
+		values = append(values, fmt.Sprintf("%v", e))
+	}
+	return values
+}
+
+// PropertyNames returns the sorted names of all of the properties which can be set.
+func PropertyNames() []string {
+	var names []string
+	for _, s := range settings {
+		names = append(names, s.name)
+	}
+	for _, name := range config.DriverSettings {
+		for _, driver := range constants.SupportedVMDrivers {
+			names = append(names, config.DriverKey(name, driver))
+		}
+	}
+	for addonName, addon := range assets.Addons {
+		for _, f := range addon.Config {
+			names = append(names, assets.ConfigKey(addonName, f.Name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closeProperties returns the properties within a few edits of name, closest first.
+func closeProperties(name string) []string {
+	distances := map[string]int{}
+	var matches []string
+	for _, p := range PropertyNames() {
+		if d := util.EditDistance(name, p); d <= maxSuggestionDistance {
+			distances[p] = d
+			matches = append(matches, p)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if distances[matches[i]] != distances[matches[j]] {
+			return distances[matches[i]] < distances[matches[j]]
+		}
+		return matches[i] < matches[j]
+	})
+	return matches
+}
+
+// unknownPropertyError returns the error for a property which is no setting, suggesting the ones close to it.
+func unknownPropertyError(name string) error {
+	if matches := closeProperties(name); len(matches) > 0 {
+		return fmt.Errorf("Property name %s not found, did you mean %s?", name, strings.Join(matches, " or "))
+	}
+	return fmt.Errorf("Property name %s not found", name)
+}
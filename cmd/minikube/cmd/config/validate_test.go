@@ -0,0 +1,180 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	pkgConfig "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestConfigProblems(t *testing.T) {
+	var tests = []struct {
+		description string
+		config      pkgConfig.MinikubeConfig
+		invalid     []string
+		warnings    []string
+	}{
+		{
+			description: "valid",
+			config: pkgConfig.MinikubeConfig{
+				"memory":                         4096.0,
+				"vm-driver":                      "virtualbox",
+				"memory.kvm":                     8192.0,
+				"embed-certs":                    true,
+				"insecure-registry":              []interface{}{"10.0.0.0/24", "registry.lan:5000"},
+				"registry.storage-size":          "10Gi",
+				pkgConfig.ImageRepository:        "registry.lan:5000/minikube",
+				pkgConfig.ContainerRuntime:       "containerd",
+				pkgConfig.ExtraConfig:            []interface{}{"apiserver.Authorization.Mode=RBAC"},
+				pkgConfig.WantUpdateNotification: false,
+			},
+		},
+		{
+			description: "invalid values",
+			config: pkgConfig.MinikubeConfig{
+				"memory":             "lots",
+				"cpus":               1.5,
+				"vm-driver":          "qemu",
+				"container-runtime":  "crio",
+				"iso-url":            "minikube.iso",
+				"host-only-cidr":     "192.168.99.1",
+				"kubernetes-version": "1.7.0",
+				"embed-certs":        "yes",
+				"insecure-registry":  []interface{}{"10.0.0.0/24", "http://registry.lan"},
+			},
+			invalid: []string{"container-runtime", "cpus", "embed-certs", "host-only-cidr", "insecure-registry", "iso-url", "kubernetes-version", "memory", "vm-driver"},
+		},
+		{
+			description: "driver key of an unknown driver",
+			config:      pkgConfig.MinikubeConfig{"memory.qemu": 4096.0},
+			invalid:     []string{"memory.qemu"},
+		},
+		{
+			description: "unknown properties",
+			config:      pkgConfig.MinikubeConfig{"memroy": 4096.0, "vm_driver": "kvm", "something-else": "value"},
+			warnings: []string{
+				"Property name memroy not found, did you mean memory?",
+				"Property name something-else not found",
+				"Property name vm_driver not found, did you mean vm-driver?",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			defer useTempMinikubeHome(t)()
+
+			errs, warnings := configProblems(test.config)
+			var invalid []string
+			for _, err := range errs {
+				name := strings.SplitN(strings.TrimPrefix(err.Error(), "Invalid "), "=", 2)[0]
+				if len(invalid) == 0 || invalid[len(invalid)-1] != name {
+					invalid = append(invalid, name)
+				}
+			}
+			if !reflect.DeepEqual(invalid, test.invalid) {
+				t.Errorf("Expected the invalid properties %v, got %v: %v", test.invalid, invalid, errs)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("Expected the warnings %v, got %v", test.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	var tests = []struct {
+		description string
+		config      string
+		code        int
+		errOut      []string
+	}{
+		{
+			description: "no config",
+		},
+		{
+			description: "valid",
+			config:      `{"memory": 4096, "cpus": 2}`,
+		},
+		{
+			description: "unknown property",
+			config:      `{"memory": 4096, "cpu": 2}`,
+			errOut:      []string{"Warning: Property name cpu not found, did you mean cpus?"},
+		},
+		{
+			description: "every problem reported",
+			config:      `{"memory": "lots", "cpus": 0, "cpu": 2}`,
+			code:        invalidConfigExitCode,
+			errOut:      []string{"Warning: Property name cpu not found", `Invalid cpus="0"`, `Invalid memory="lots"`},
+		},
+		{
+			description: "undecodable",
+			config:      `{"memory": `,
+			code:        invalidConfigExitCode,
+			errOut:      []string{"Could not decode config"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			defer useTempMinikubeHome(t)()
+			if test.config != "" {
+				if err := ioutil.WriteFile(constants.ConfigFile, []byte(test.config), 0644); err != nil {
+					t.Fatalf("Error writing config: %s", err)
+				}
+			}
+
+			var out, errOut bytes.Buffer
+			if code := validateConfig(&out, &errOut); code != test.code {
+				t.Errorf("Expected exit code %d, got %d: %s", test.code, code, errOut.String())
+			}
+			for _, s := range test.errOut {
+				if !strings.Contains(errOut.String(), s) {
+					t.Errorf("Expected %q in the output:\n%s", s, errOut.String())
+				}
+			}
+			if test.code == 0 && !strings.Contains(out.String(), "The config is valid") {
+				t.Errorf("Expected the config to be reported valid, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestSetSuggestsProperty(t *testing.T) {
+	defer useTempMinikubeHome(t)()
+
+	err := Set("memroy", "4096")
+	if err == nil || !strings.Contains(err.Error(), "did you mean memory?") {
+		t.Errorf("Expected the error to suggest memory, got %v", err)
+	}
+	if err := Set("memory", "lots"); err == nil {
+		t.Errorf("Expected setting memory to lots to fail")
+	}
+	m, err := pkgConfig.ReadConfig()
+	if err != nil {
+		t.Fatalf("Error reading config: %s", err)
+	}
+	if len(m) != 0 {
+		t.Errorf("Expected nothing to be set, got %v", m)
+	}
+}
@@ -22,11 +22,17 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/blang/semver"
 	units "github.com/docker/go-units"
 	"github.com/pkg/errors"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/kubernetes_versions"
+	"k8s.io/minikube/pkg/util"
 )
 
 func IsValidDriver(string, driver string) error {
@@ -44,18 +50,100 @@ func RequiresRestartMsg(string, string) error {
 }
 
 func IsValidDiskSize(name string, disksize string) error {
-	_, err := units.FromHumanSize(disksize)
+	size, err := units.FromHumanSize(disksize)
 	if err != nil {
 		return fmt.Errorf("Not valid disk size: %v", err)
 	}
+	if size/units.MB < constants.MinimumDiskSizeMB {
+		return fmt.Errorf("%s must be at least %dMB", name, constants.MinimumDiskSizeMB)
+	}
+	return nil
+}
+
+// IsValidMemory checks that the memory size, in MB, is enough to run the kubelet.
+func IsValidMemory(name string, val string) error {
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return fmt.Errorf("%s:%v", name, err)
+	}
+	if i < constants.MinimumMemoryMB {
+		return fmt.Errorf("%s must be at least %dMB", name, constants.MinimumMemoryMB)
+	}
+	return nil
+}
+
+func IsValidSize(name string, size string) error {
+	if _, err := units.FromHumanSize(size); err != nil {
+		return fmt.Errorf("Not valid size: %v", err)
+	}
 	return nil
 }
 
+// IsValidURL checks that location is an absolute http, https or file URL.
 func IsValidURL(name string, location string) error {
-	_, err := url.Parse(location)
+	u, err := url.Parse(location)
 	if err != nil {
 		return fmt.Errorf("%s is not a valid URL", location)
 	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("%s is not a valid URL: it has no host", location)
+		}
+	case "file":
+		if u.Path == "" {
+			return fmt.Errorf("%s is not a valid URL: it has no path", location)
+		}
+	default:
+		return fmt.Errorf("%s is not a valid URL: it has to be an http, https or file URL", location)
+	}
+	return nil
+}
+
+// IsValidBool checks that val is a boolean, such as true or false.
+func IsValidBool(name string, val string) error {
+	if _, err := strconv.ParseBool(val); err != nil {
+		return fmt.Errorf("%s must be true or false, not %q", name, val)
+	}
+	return nil
+}
+
+// IsNotEmpty checks that val is set to something.
+func IsNotEmpty(name string, val string) error {
+	if strings.TrimSpace(val) == "" {
+		return fmt.Errorf("%s must not be empty", name)
+	}
+	return nil
+}
+
+// IsValidKubernetesVersion checks that val is a Kubernetes version, as in v1.7.0, a release
+// channel, or the URL of a localkube binary.
+func IsValidKubernetesVersion(name string, val string) error {
+	if kubernetes_versions.IsChannel(val) {
+		return nil
+	}
+	if strings.Contains(val, "://") {
+		return IsValidURL(name, val)
+	}
+	if !strings.HasPrefix(val, "v") {
+		return fmt.Errorf("%s must be a version such as v1.7.0, %s, %s or the URL of a localkube binary, not %q",
+			name, kubernetes_versions.ChannelStable, kubernetes_versions.ChannelLatest, val)
+	}
+	if _, err := semver.Make(strings.TrimPrefix(val, "v")); err != nil {
+		return fmt.Errorf("%s is not a valid version: %v", val, err)
+	}
+	return nil
+}
+
+// IsValidImageRepository checks that val is the name of a repository images can be pulled
+// from, as in registry.lan:5000/minikube, rather than a URL.
+func IsValidImageRepository(name string, val string) error {
+	if err := IsNotEmpty(name, val); err != nil {
+		return err
+	}
+	if strings.Contains(val, "://") || strings.ContainsAny(val, " \t") {
+		return fmt.Errorf("%s must be a repository such as registry.lan:5000/minikube, without a scheme or spaces, not %q", name, val)
+	}
 	return nil
 }
 
@@ -86,9 +174,68 @@ func IsValidPath(name string, path string) error {
 	return nil
 }
 
+// IsValidExtraConfig checks that val is extra config of a component localkube runs.
+func IsValidExtraConfig(name string, val string) error {
+	e, err := util.ParseExtraOptions([]string{val})
+	if err != nil {
+		return err
+	}
+	return e.Validate()
+}
+
+// IsValidDNSDomain checks that val can be the cluster's DNS domain.
+func IsValidDNSDomain(name string, val string) error {
+	return util.ValidateDNSDomain(val)
+}
+
+// IsValidProfile checks that val can name a profile, which its VM is named after.
+func IsValidProfile(name string, val string) error {
+	return config.ValidateProfileName(val)
+}
+
+// IsValidContainerRuntime checks that val is a container runtime the cluster can run with.
+func IsValidContainerRuntime(name string, val string) error {
+	_, err := cruntime.Lookup(val)
+	return err
+}
+
+// IsValidInsecureRegistry checks that val is a comma separated list of insecure registries.
+func IsValidInsecureRegistry(name string, val string) error {
+	for _, entry := range strings.Split(val, ",") {
+		if err := util.ValidateInsecureRegistry(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func IsValidAddon(name string, val string) error {
 	if _, ok := assets.Addons[name]; ok {
 		return nil
 	}
 	return errors.Errorf("Cannot enable/disable invalid addon %s", name)
 }
+
+// conflictingAddons are the addons which deploy objects of the same names, such as the heapster
+// service the dashboard gets its metrics from, so only one of them can be enabled.
+var conflictingAddons = map[string]string{
+	"heapster": "metrics",
+	"metrics":  "heapster",
+}
+
+// IsNotConflictingAddon checks that enabling the addon doesn't deploy the objects of another enabled addon.
+func IsNotConflictingAddon(name string, val string) error {
+	enable, err := strconv.ParseBool(val)
+	other, ok := conflictingAddons[name]
+	if err != nil || !enable || !ok {
+		return nil
+	}
+	enabled, err := assets.Addons[other].IsEnabled()
+	if err != nil {
+		return errors.Wrapf(err, "Error getting whether addon %s is enabled", other)
+	}
+	if enabled {
+		return errors.Errorf("Addon %s deploys the same objects as addon %s, disable %s first", name, other, other)
+	}
+	return nil
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package config
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 type validationTest struct {
 	value     string
@@ -94,3 +97,311 @@ func TestValidCIDR(t *testing.T) {
 
 	runValidations(t, tests, "cidr", IsValidCIDR)
 }
+
+func TestValidMemory(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "2048",
+			shouldErr: false,
+		},
+		{
+			value:     "512",
+			shouldErr: false,
+		},
+		{
+			value:     "511",
+			shouldErr: true,
+		},
+		{
+			value:     "0",
+			shouldErr: true,
+		},
+		{
+			value:     "2g",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "memory", IsValidMemory)
+}
+
+func TestValidDiskSize(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "20g",
+			shouldErr: false,
+		},
+		{
+			value:     "2000mb",
+			shouldErr: false,
+		},
+		{
+			value:     "1g",
+			shouldErr: true,
+		},
+		{
+			value:     "big",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "disk-size", IsValidDiskSize)
+}
+
+func TestValidExtraConfig(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "apiserver.Authorization.Mode=RBAC",
+			shouldErr: false,
+		},
+		{
+			value:     "kubelet.cgroup-driver=systemd",
+			shouldErr: true,
+		},
+		{
+			value:     "kubedns.Domain=cluster.local",
+			shouldErr: true,
+		},
+		{
+			value:     "apiserver",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "extra-config", IsValidExtraConfig)
+}
+
+func TestValidDNSDomain(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "cluster.local",
+			shouldErr: false,
+		},
+		{
+			value:     "dev.example.com",
+			shouldErr: false,
+		},
+		{
+			value:     "cluster.local.",
+			shouldErr: true,
+		},
+		{
+			value:     "my_cluster",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "dns-domain", IsValidDNSDomain)
+}
+
+func TestValidContainerRuntime(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "docker",
+			shouldErr: false,
+		},
+		{
+			value:     "containerd",
+			shouldErr: false,
+		},
+		{
+			value:     "cri-o",
+			shouldErr: false,
+		},
+		{
+			value:     "crio",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "container-runtime", IsValidContainerRuntime)
+}
+
+func TestValidProfile(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "minikube",
+			shouldErr: false,
+		},
+		{
+			value:     "project-2",
+			shouldErr: false,
+		},
+		{
+			value:     "-project",
+			shouldErr: true,
+		},
+		{
+			value:     "my_project",
+			shouldErr: true,
+		},
+		{
+			value:     "projects/a",
+			shouldErr: true,
+		},
+		{
+			value:     "",
+			shouldErr: true,
+		},
+		{
+			value:     strings.Repeat("a", 64),
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "profile", IsValidProfile)
+}
+
+func TestValidURL(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "https://storage.googleapis.com/minikube/iso/minikube-v0.19.0.iso",
+			shouldErr: false,
+		},
+		{
+			value:     "file:///home/user/minikube.iso",
+			shouldErr: false,
+		},
+		{
+			value:     "lots",
+			shouldErr: true,
+		},
+		{
+			value:     "https://",
+			shouldErr: true,
+		},
+		{
+			value:     "ftp://mirror.lan/minikube.iso",
+			shouldErr: true,
+		},
+		{
+			value:     "",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "iso-url", IsValidURL)
+}
+
+func TestValidBool(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "true",
+			shouldErr: false,
+		},
+		{
+			value:     "false",
+			shouldErr: false,
+		},
+		{
+			value:     "yes",
+			shouldErr: true,
+		},
+		{
+			value:     "",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "embed-certs", IsValidBool)
+}
+
+func TestPositive(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "2",
+			shouldErr: false,
+		},
+		{
+			value:     "0",
+			shouldErr: true,
+		},
+		{
+			value:     "-1",
+			shouldErr: true,
+		},
+		{
+			value:     "two",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "cpus", IsPositive)
+}
+
+func TestValidKubernetesVersion(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "v1.7.0",
+			shouldErr: false,
+		},
+		{
+			value:     "v1.8.0-alpha.1",
+			shouldErr: false,
+		},
+		{
+			value:     "stable",
+			shouldErr: false,
+		},
+		{
+			value:     "https://storage.googleapis.com/minikube/k8sReleases/v1.3.0/localkube-linux-amd64",
+			shouldErr: false,
+		},
+		{
+			value:     "1.7.0",
+			shouldErr: true,
+		},
+		{
+			value:     "v1.7",
+			shouldErr: true,
+		},
+		{
+			value:     "newest",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "kubernetes-version", IsValidKubernetesVersion)
+}
+
+func TestValidImageRepository(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "registry.lan:5000/minikube",
+			shouldErr: false,
+		},
+		{
+			value:     "registry.cn-hangzhou.aliyuncs.com/google_containers",
+			shouldErr: false,
+		},
+		{
+			value:     "https://registry.lan/minikube",
+			shouldErr: true,
+		},
+		{
+			value:     "my registry",
+			shouldErr: true,
+		},
+		{
+			value:     "",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "image-repository", IsValidImageRepository)
+}
+
+func TestNotEmpty(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "minikube",
+			shouldErr: false,
+		},
+		{
+			value:     " ",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "hyperv-virtual-switch", IsNotEmpty)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+var copyOptions sshutil.CopyOptions
+
+// cpCmd represents the cp command
+var cpCmd = &cobra.Command{
+	Use:   "cp SRC DST",
+	Short: "Copies files between the host and the VM",
+	Long: `Copies files between the host and the VM over the machine's SSH connection, preserving their mode.
+The side in the VM is prefixed with "minikube:", or with the name of the profile followed by ":",
+as in "minikube cp ./pod.yaml minikube:/etc/kubernetes/manifests". Copying into a directory keeps the name of the source.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: minikube cp [-r] [-f] SRC DST")
+			os.Exit(1)
+		}
+		if err := copyFiles(args[0], args[1], copyOptions); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+// parseCopyPath returns the path arg designates, and whether it is in the VM.
+func parseCopyPath(arg string) (string, bool) {
+	for _, name := range []string{constants.DefaultMachineName, config.GetMachineName()} {
+		if p := strings.TrimPrefix(arg, name+":"); p != arg {
+			if p == "" {
+				p = "."
+			}
+			return p, true
+		}
+	}
+	return arg, false
+}
+
+func copyFiles(srcArg, dstArg string, opts sshutil.CopyOptions) error {
+	src, srcInVM := parseCopyPath(srcArg)
+	dst, dstInVM := parseCopyPath(dstArg)
+	if srcInVM == dstInVM {
+		return errors.Errorf("Either the source or the destination must be in the VM, prefixed with %q", config.GetMachineName()+":")
+	}
+
+	api, err := machine.NewAPIClient(clientType)
+	if err != nil {
+		return errors.Wrap(err, "Error getting client")
+	}
+	defer api.Close()
+	h, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error getting host")
+	}
+	if h.Driver.DriverName() == "none" {
+		return errors.New(`'none' driver does not support 'minikube cp' command`)
+	}
+	if s, err := h.Driver.GetState(); err != nil || s != state.Running {
+		return errors.Errorf("Cannot copy files: Host %q is not running", config.GetMachineName())
+	}
+
+	client, err := sshutil.NewSSHClient(h.Driver)
+	if err != nil {
+		return errors.Wrap(err, "Error creating ssh client")
+	}
+	defer client.Close()
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return errors.Wrap(err, "Error starting an sftp session")
+	}
+	defer sftpClient.Close()
+
+	if dstInVM {
+		return sshutil.CopyToVM(sftpClient, src, dst, opts)
+	}
+	return sshutil.CopyFromVM(sftpClient, src, dst, opts)
+}
+
+func init() {
+	cpCmd.Flags().BoolVarP(&copyOptions.Recursive, "recursive", "r", false, "Copy directories along with their contents")
+	cpCmd.Flags().BoolVarP(&copyOptions.Force, "force", "f", false, "Overwrite an existing destination")
+	RootCmd.AddCommand(cpCmd)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestParseCopyPath(t *testing.T) {
+	viper.Set(config.MachineProfile, "dev")
+	defer viper.Set(config.MachineProfile, "")
+
+	var tests = []struct {
+		arg      string
+		path     string
+		remoteVM bool
+	}{
+		{arg: "minikube:/etc/hosts", path: "/etc/hosts", remoteVM: true},
+		{arg: "dev:manifests/pod.yaml", path: "manifests/pod.yaml", remoteVM: true},
+		{arg: "minikube:", path: ".", remoteVM: true},
+		{arg: "other:/etc/hosts", path: "other:/etc/hosts"},
+		{arg: `C:\Users\dev\pod.yaml`, path: `C:\Users\dev\pod.yaml`},
+		{arg: "./pod.yaml", path: "./pod.yaml"},
+	}
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			p, remoteVM := parseCopyPath(test.arg)
+			if p != test.path || remoteVM != test.remoteVM {
+				t.Errorf("Expected %q, %t, got %q, %t", test.path, test.remoteVM, p, remoteVM)
+			}
+		})
+	}
+}
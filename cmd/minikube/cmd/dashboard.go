@@ -18,29 +18,34 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
-	"text/template"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/service"
-
-	commonutil "k8s.io/minikube/pkg/util"
 )
 
 var (
 	dashboardURLMode bool
+	dashboardPort    int
+	dashboardWait    time.Duration
 )
 
 // dashboardCmd represents the dashboard command
 var dashboardCmd = &cobra.Command{
 	Use:   "dashboard",
 	Short: "Opens/displays the kubernetes dashboard URL for your local cluster",
-	Long:  `Opens/displays the kubernetes dashboard URL for your local cluster`,
+	Long: `Opens/displays the kubernetes dashboard URL for your local cluster.
+
+The dashboard is served by a proxy on the loopback interface, which forwards its requests to the
+dashboard service through the apiserver, with the credentials of the cluster. It runs until
+interrupted.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
@@ -53,32 +58,62 @@ var dashboardCmd = &cobra.Command{
 		namespace := "kube-system"
 		svc := "kubernetes-dashboard"
 
-		if err = commonutil.RetryAfter(20, func() error { return service.CheckService(namespace, svc) }, 6*time.Second); err != nil {
-			fmt.Fprintf(os.Stderr, "Could not find finalized endpoint being pointed to by %s: %s\n", svc, err)
+		enabled, err := assets.Addons["dashboard"].IsEnabled()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !enabled {
+			fmt.Fprintln(os.Stderr, `The dashboard addon is not enabled.
+To enable it run:
+minikube addons enable dashboard`)
 			os.Exit(1)
 		}
 
-		urls, err := service.GetServiceURLsForService(api, namespace, svc, template.Must(template.New("dashboardServiceFormat").Parse(defaultServiceFormatTemplate)))
+		config, err := service.GetClientConfig()
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			fmt.Fprintln(os.Stderr, "Check that minikube is running.")
 			os.Exit(1)
 		}
-		if len(urls) == 0 {
-			errMsg := "There appears to be no url associated with dashboard, this is not expected, exiting"
-			glog.Infoln(errMsg)
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating kubernetes client: %s\n", err)
 			os.Exit(1)
 		}
+		fmt.Fprintln(os.Stderr, "Waiting for the dashboard to be ready...")
+		if err := cluster.WaitForComponent(client.CoreV1(), cluster.DashboardComponent, dashboardWait); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		handler, err := service.NewProxyHandler(config, namespace, svc)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		l, err := service.ListenLoopback(dashboardPort)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		url := fmt.Sprintf("http://%s/", l.Addr())
 		if dashboardURLMode {
-			fmt.Fprintln(os.Stdout, urls[0])
+			fmt.Fprintln(os.Stdout, url)
 		} else {
 			fmt.Fprintln(os.Stdout, "Opening kubernetes dashboard in default browser...")
-			browser.OpenURL(urls[0])
+			browser.OpenURL(url)
+		}
+		fmt.Fprintf(os.Stderr, "Proxying the dashboard on %s, press Ctrl-C to stop.\n", url)
+		if err := http.Serve(l, handler); err != nil {
+			fmt.Fprintf(os.Stderr, "Error proxying the dashboard: %s\n", err)
+			os.Exit(1)
 		}
 	},
 }
 
 func init() {
-	dashboardCmd.Flags().BoolVar(&dashboardURLMode, "url", false, "Display the kubernetes dashboard in the CLI instead of opening it in the default browser")
+	dashboardCmd.Flags().BoolVar(&dashboardURLMode, "url", false, "Display the kubernetes dashboard URL in the CLI instead of opening it in the default browser")
+	dashboardCmd.Flags().IntVar(&dashboardPort, "port", 0, "The local port to proxy the dashboard on, a random one by default")
+	dashboardCmd.Flags().DurationVar(&dashboardWait, "wait", service.DefaultWaitTimeout, "How long to wait for the dashboard pod to be ready")
 	RootCmd.AddCommand(dashboardCmd)
 }
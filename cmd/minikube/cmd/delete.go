@@ -17,42 +17,130 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"os"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/spf13/cobra"
-	cmdUtil "k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/autorestart"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+var (
+	deleteAll   bool
+	deletePurge bool
+)
+
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Deletes a local kubernetes cluster",
 	Long: `Deletes a local kubernetes cluster. This command deletes the VM, and removes all
-associated files.`,
+associated files. With --all, the clusters of every profile are deleted, and with --purge
+the cache, the certs and minikube's kubeconfig entries are removed too.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Deleting local Kubernetes cluster...")
+		if deletePurge && !deleteAll {
+			console.ErrLn("--purge can only be used with --all, as every profile shares the cache and certs")
+			os.Exit(1)
+		}
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			console.Err("Error getting client: %s\n", err)
 			os.Exit(1)
 		}
 		defer api.Close()
 
-		if err = cluster.DeleteHost(api); err != nil {
-			fmt.Println("Errors occurred deleting machine: ", err)
-			os.Exit(1)
+		if deleteAll {
+			deleteAllClusters(api)
+			return
 		}
-		fmt.Println("Machine deleted.")
 
-		if err := cmdUtil.KillMountProcess(); err != nil {
-			fmt.Println("Errors occurred deleting mount process: ", err)
+		// The mounts are recorded in the machine directory, which is deleted with it.
+		if err := killMounts(cfg.GetMachineName(), cluster.AllMounts, console.ProgressWriter()); err != nil {
+			console.ErrLn("Errors occurred stopping mounts: ", err)
 		}
+		console.Progress("Deleting local Kubernetes cluster...")
+		if err = cluster.DeleteHost(context.Background(), api); err != nil {
+			console.ErrLn("Errors occurred deleting machine: ", err)
+			os.Exit(1)
+		}
+		console.OutLn("Machine deleted.")
+		removeAutoRestart(cfg.GetMachineName())
+		resetActiveProfile(cfg.GetMachineName())
 	},
 }
 
+// deleteAllClusters deletes the machine of every profile, carrying on past the ones which
+// fail, and purges minikube's other files if asked to. It exits with an error if any failed.
+func deleteAllClusters(api libmachine.API) {
+	console.Progress("Deleting all local Kubernetes clusters...")
+	if names, err := api.List(); err == nil {
+		for _, name := range names {
+			if err := killMounts(name, cluster.AllMounts, console.ProgressWriter()); err != nil {
+				console.Err("  %s: failed stopping mounts: %s\n", name, err)
+			}
+		}
+	}
+	results, err := cluster.DeleteAllHosts(context.Background(), api)
+	if err != nil {
+		console.ErrLn("Error listing machines: ", err)
+		os.Exit(1)
+	}
+	deleted, failed := 0, false
+	for _, r := range results {
+		if r.Err != nil {
+			failed = true
+			console.Err("  %s: failed: %s\n", r.Name, r.Err)
+			continue
+		}
+		deleted++
+		console.Out("  %s: deleted\n", r.Name)
+		removeAutoRestart(r.Name)
+		resetActiveProfile(r.Name)
+		if deletePurge {
+			if err := kubeconfig.DeleteKubeConfigContext(kubeConfigPath(), r.Name); err != nil {
+				failed = true
+				console.Err("  %s: failed removing it from kubeconfig: %s\n", r.Name, err)
+			}
+		}
+	}
+	console.Out("Deleted %d of %d machines.\n", deleted, len(results))
+
+	if deletePurge {
+		console.Progress("Removing the cache and certs...")
+		if err := cluster.PurgeFiles(); err != nil {
+			failed = true
+			console.ErrLn("Errors occurred removing files: ", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// resetActiveProfile makes the default profile the active one again if the deleted one was.
+func resetActiveProfile(profile string) {
+	reset, err := cfg.ResetActiveProfile(profile)
+	if err != nil {
+		console.ErrLn("Error resetting the active profile: ", err)
+	} else if reset {
+		console.Progress("The active profile is minikube again.")
+	}
+}
+
+// removeAutoRestart removes the unit starting the profile's cluster at login, if there is one.
+func removeAutoRestart(profile string) {
+	if err := autorestart.Uninstall(profile); err != nil {
+		console.ErrLn("Error removing the auto-restart unit: ", err)
+	}
+}
+
 func init() {
+	deleteCmd.Flags().BoolVar(&deleteAll, "all", false, "Delete the clusters of every profile")
+	deleteCmd.Flags().BoolVar(&deletePurge, "purge", false, "With --all, also remove the cache, the certs, and minikube's contexts from kubeconfig")
 	RootCmd.AddCommand(deleteCmd)
 }
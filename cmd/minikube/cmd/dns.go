@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/dns"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+var (
+	dnsAddress string
+	dnsPort    int
+)
+
+// dnsCmd represents the dns command
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Resolves <service>.<namespace>.minikube to the minikube VM from the host",
+	Long: `Runs a DNS server on the host which resolves <service>.<namespace>.minikube to the IP of the minikube VM,
+where the node ports of the services are reachable, and names of services which don't exist to NXDOMAIN. It prints
+how to add it as the resolver of the minikube domain, and runs until Ctrl-C.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+		cluster.EnsureMinikubeRunningOrExit(api, 1)
+		host, err := cluster.CheckIfApiExistsAndLoad(api)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting host: %s\n", err)
+			os.Exit(1)
+		}
+		core, err := (&service.K8sClientGetter{}).GetCoreClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting the kubernetes client: %s\n", err)
+			os.Exit(1)
+		}
+
+		s, err := dns.Listen(net.JoinHostPort(dnsAddress, strconv.Itoa(dnsPort)), dns.NewResolver(core, host.Driver.GetIP, dns.DefaultTTL))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		stopped := make(chan struct{})
+		go func() {
+			<-signals
+			close(stopped)
+			s.Close()
+		}()
+		fmt.Printf("Resolving <service>.<namespace>.minikube on %s. Press Ctrl-C to stop the server.\n", s.Addr())
+		fmt.Print(dns.ResolverInstructions(runtime.GOOS, dnsAddress, dnsPort))
+		if err := s.Serve(); err != nil {
+			select {
+			case <-stopped:
+			default:
+				fmt.Fprintln(os.Stderr, "Error serving DNS: ", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	dnsCmd.Flags().StringVar(&dnsAddress, "address", "127.0.0.1", "The address the DNS server listens on")
+	dnsCmd.Flags().IntVar(&dnsPort, "port", 1053, "The UDP port the DNS server listens on")
+	RootCmd.AddCommand(dnsCmd)
+}
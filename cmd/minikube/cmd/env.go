@@ -21,12 +21,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/template"
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/shell"
+	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -38,7 +40,7 @@ import (
 )
 
 const (
-	envTmpl = `{{ .Prefix }}DOCKER_TLS_VERIFY{{ .Delimiter }}{{ .DockerTLSVerify }}{{ .Suffix }}{{ .Prefix }}DOCKER_HOST{{ .Delimiter }}{{ .DockerHost }}{{ .Suffix }}{{ .Prefix }}DOCKER_CERT_PATH{{ .Delimiter }}{{ .DockerCertPath }}{{ .Suffix }}{{ .Prefix }}DOCKER_API_VERSION{{ .Delimiter }}{{ .DockerAPIVersion }}{{ .Suffix }}{{ if .NoProxyVar }}{{ .Prefix }}{{ .NoProxyVar }}{{ .Delimiter }}{{ .NoProxyValue }}{{ .Suffix }}{{end}}{{ .UsageHint }}`
+	envTmpl = `{{ .Prefix }}DOCKER_TLS_VERIFY{{ .Delimiter }}{{ .DockerTLSVerify }}{{ .Suffix }}{{ .Prefix }}DOCKER_HOST{{ .Delimiter }}{{ .DockerHost }}{{ .Suffix }}{{ .Prefix }}DOCKER_CERT_PATH{{ .Delimiter }}{{ .DockerCertPath }}{{ .Suffix }}{{ .Prefix }}DOCKER_API_VERSION{{ .Delimiter }}{{ .DockerAPIVersion }}{{ .Suffix }}{{ .Prefix }}DOCKER_MINIKUBE_ID{{ .Delimiter }}{{ .MinikubeID }}{{ .Suffix }}{{ if .NoProxyVar }}{{ .Prefix }}{{ .NoProxyVar }}{{ .Delimiter }}{{ .NoProxyValue }}{{ .Suffix }}{{end}}{{ .UsageHint }}`
 
 	fishSetPfx   = "set -gx "
 	fishSetSfx   = "\";\n"
@@ -52,7 +54,7 @@ const (
 	psSetSfx   = "\"\n"
 	psSetDelim = " = \""
 
-	psUnsetPfx   = `Remove-Item Env:\\`
+	psUnsetPfx   = `Remove-Item Env:\`
 	psUnsetSfx   = "\n"
 	psUnsetDelim = ""
 
@@ -72,6 +74,14 @@ const (
 	emacsUnsetSfx   = ")\n"
 	emacsUnsetDelim = "\" nil"
 
+	tcshSetPfx   = "setenv "
+	tcshSetSfx   = "\";\n"
+	tcshSetDelim = " \""
+
+	tcshUnsetPfx   = "unsetenv "
+	tcshUnsetSfx   = ";\n"
+	tcshUnsetDelim = ""
+
 	bashSetPfx   = "export "
 	bashSetSfx   = "\"\n"
 	bashSetDelim = "=\""
@@ -81,24 +91,40 @@ const (
 	bashUnsetDelim = ""
 )
 
+// The escapers of the values of the variables, for the quotes each shell sets them in. The
+// backslashes of Windows paths would otherwise make escapes for emacs, and halve the ones
+// starting UNC paths for bash and fish.
+var (
+	bashEscaper  = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	fishEscaper  = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`)
+	psEscaper    = strings.NewReplacer("`", "``", `"`, "`\"", "$", "`$")
+	emacsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	tcshEscaper  = strings.NewReplacer("!", `\!`)
+)
+
+// usageHintMap holds the hint printed for each shell, which its command line is formatted into.
 var usageHintMap = map[string]string{
 	"bash": `# Run this command to configure your shell:
-# eval $(minikube docker-env)
+# eval $(%s)
 `,
 	"fish": `# Run this command to configure your shell:
-# eval (minikube docker-env)
+# eval (%s)
 `,
+	"tcsh": "# Run this command to configure your shell:\n# eval `%s`\n",
 	"powershell": `# Run this command to configure your shell:
-# & minikube docker-env | Invoke-Expression
+# & %s | Invoke-Expression
 `,
 	"cmd": `REM Run this command to configure your shell:
-REM @FOR /f "tokens=*" %i IN ('minikube docker-env') DO @%i
+REM @FOR /f "tokens=*" %%i IN ('%s') DO @%%i
 `,
 	"emacs": `;; Run this command to configure your shell:
-;; (with-temp-buffer (shell-command "minikube docker-env" (current-buffer)) (eval-buffer))
+;; (with-temp-buffer (shell-command "%s" (current-buffer)) (eval-buffer))
 `,
 }
 
+// supportedShells are the shells --shell can be set to. sh and zsh take the syntax of bash, and csh the one of tcsh.
+var supportedShells = []string{"bash", "zsh", "sh", "fish", "tcsh", "csh", "powershell", "cmd", "emacs"}
+
 type ShellConfig struct {
 	Prefix           string
 	Delimiter        string
@@ -107,6 +133,7 @@ type ShellConfig struct {
 	DockerHost       string
 	DockerTLSVerify  string
 	DockerAPIVersion string
+	MinikubeID       string
 	UsageHint        string
 	NoProxyVar       string
 	NoProxyValue     string
@@ -132,12 +159,51 @@ type NoProxyGetter interface {
 
 type EnvNoProxyGetter struct{}
 
-func generateUsageHint(userShell string) string {
+// generateUsageHint returns the hint on running the command line in the shell to configure it.
+func generateUsageHint(userShell, commandLine string) string {
 	hint, ok := usageHintMap[userShell]
 	if !ok {
-		return usageHintMap["bash"]
+		hint = usageHintMap["bash"]
+	}
+	return fmt.Sprintf(hint, commandLine)
+}
+
+// dockerEnvCommandLine returns the minikube docker-env command line printing the same variables.
+func dockerEnvCommandLine() string {
+	commandLine := "minikube docker-env"
+	if unset {
+		commandLine += " -u"
+	}
+	if noProxy {
+		commandLine += " --no-proxy"
+	}
+	return commandLine
+}
+
+// validateShell checks that the shell is one the variables can be printed for.
+func validateShell(userShell string) error {
+	for _, s := range supportedShells {
+		if userShell == s {
+			return nil
+		}
+	}
+	return errors.Errorf("Unsupported shell %q, --shell has to be one of %s", userShell, strings.Join(supportedShells, ", "))
+}
+
+// checkDockerEnvHost checks that the VM runs a docker daemon the variables can point at.
+func checkDockerEnvHost(api libmachine.API) error {
+	s, err := cluster.GetHostStatus(api)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case state.Running.String():
+		return nil
+	case constants.MachineDoesNotExist:
+		return errors.Errorf("The %s VM does not exist, run minikube start to create it before pointing docker at its daemon", config.GetMachineName())
+	default:
+		return errors.Errorf("The %s VM is %s, run minikube start before pointing docker at its daemon", config.GetMachineName(), s)
 	}
-	return hint
 }
 
 func shellCfgSet(api libmachine.API) (*ShellConfig, error) {
@@ -157,7 +223,8 @@ func shellCfgSet(api libmachine.API) (*ShellConfig, error) {
 		DockerHost:       envMap["DOCKER_HOST"],
 		DockerTLSVerify:  envMap["DOCKER_TLS_VERIFY"],
 		DockerAPIVersion: constants.DockerAPIVersion,
-		UsageHint:        generateUsageHint(userShell),
+		MinikubeID:       envMap[cluster.DockerEnvIDVar],
+		UsageHint:        generateUsageHint(userShell, dockerEnvCommandLine()),
 	}
 
 	if noProxy {
@@ -204,15 +271,44 @@ func shellCfgSet(api libmachine.API) (*ShellConfig, error) {
 		shellCfg.Prefix = emacsSetPfx
 		shellCfg.Suffix = emacsSetSfx
 		shellCfg.Delimiter = emacsSetDelim
+	case "tcsh", "csh":
+		shellCfg.Prefix = tcshSetPfx
+		shellCfg.Suffix = tcshSetSfx
+		shellCfg.Delimiter = tcshSetDelim
 	default:
 		shellCfg.Prefix = bashSetPfx
 		shellCfg.Suffix = bashSetSfx
 		shellCfg.Delimiter = bashSetDelim
 	}
+	escapeValues(shellCfg, userShell)
 
 	return shellCfg, nil
 }
 
+// escapeValues escapes the values of the variables for the quotes the shell sets them in. cmd
+// doesn't quote them, and takes them up to the end of the line as they are.
+func escapeValues(shellCfg *ShellConfig, userShell string) {
+	var r *strings.Replacer
+	switch userShell {
+	case "fish":
+		r = fishEscaper
+	case "powershell":
+		r = psEscaper
+	case "cmd":
+		return
+	case "emacs":
+		r = emacsEscaper
+	case "tcsh", "csh":
+		r = tcshEscaper
+	default:
+		r = bashEscaper
+	}
+	for _, v := range []*string{&shellCfg.DockerTLSVerify, &shellCfg.DockerHost, &shellCfg.DockerCertPath, &shellCfg.DockerAPIVersion,
+		&shellCfg.MinikubeID, &shellCfg.NoProxyValue} {
+		*v = r.Replace(*v)
+	}
+}
+
 func shellCfgUnset() (*ShellConfig, error) {
 
 	userShell, err := defaultShellDetector.GetShell(forceShell)
@@ -221,11 +317,11 @@ func shellCfgUnset() (*ShellConfig, error) {
 	}
 
 	shellCfg := &ShellConfig{
-		UsageHint: generateUsageHint(userShell),
+		UsageHint: generateUsageHint(userShell, dockerEnvCommandLine()),
 	}
 
 	if noProxy {
-		shellCfg.NoProxyVar, shellCfg.NoProxyValue = defaultNoProxyGetter.GetNoProxyVar()
+		shellCfg.NoProxyVar, _ = defaultNoProxyGetter.GetNoProxyVar()
 	}
 
 	switch userShell {
@@ -245,6 +341,10 @@ func shellCfgUnset() (*ShellConfig, error) {
 		shellCfg.Prefix = emacsUnsetPfx
 		shellCfg.Suffix = emacsUnsetSfx
 		shellCfg.Delimiter = emacsUnsetDelim
+	case "tcsh", "csh":
+		shellCfg.Prefix = tcshUnsetPfx
+		shellCfg.Suffix = tcshUnsetSfx
+		shellCfg.Delimiter = tcshUnsetDelim
 	default:
 		shellCfg.Prefix = bashUnsetPfx
 		shellCfg.Suffix = bashUnsetSfx
@@ -254,11 +354,21 @@ func shellCfgUnset() (*ShellConfig, error) {
 	return shellCfg, nil
 }
 
-func executeTemplateStdout(shellCfg *ShellConfig) error {
+// warnStaleDockerEnv warns when the docker variables of the environment were set for a deleted
+// machine of the current profile, or another IP of it.
+func warnStaleDockerEnv(w io.Writer, api libmachine.API) {
+	if err := cluster.CheckShellDockerEnv(api); err != nil {
+		fmt.Fprintln(w, "Warning:", err)
+	}
+}
+
+func executeTemplate(w io.Writer, shellCfg *ShellConfig) error {
 	tmpl := template.Must(template.New("envConfig").Parse(envTmpl))
-	return tmpl.Execute(os.Stdout, shellCfg)
+	return tmpl.Execute(w, shellCfg)
 }
 
+// GetShell returns the shell set with --shell, or else the one minikube runs in: the one of
+// $SHELL, or on Windows the one of the parent process.
 func (LibmachineShellDetector) GetShell(userShell string) (string, error) {
 	if userShell != "" {
 		return userShell, nil
@@ -283,26 +393,19 @@ func (EnvNoProxyGetter) GetNoProxyVar() (string, string) {
 var dockerEnvCmd = &cobra.Command{
 	Use:   "docker-env",
 	Short: "Sets up docker env variables; similar to '$(docker-machine env)'",
-	Long:  `sets up docker env variables; similar to '$(docker-machine env)'`,
+	Long: `sets up docker env variables; similar to '$(docker-machine env)'
+The variables are printed in the syntax of the shell of --shell, or else the one minikube runs in.
+With --unset, the commands unsetting them are printed instead, which doesn't need the VM to run.`,
 	Run: func(cmd *cobra.Command, args []string) {
-
-		api, err := machine.NewAPIClient(clientType)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
-			os.Exit(1)
-		}
-		defer api.Close()
-		host, err := cluster.CheckIfApiExistsAndLoad(api)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting host: %s\n", err)
-			os.Exit(1)
-		}
-		if host.Driver.DriverName() == "none" {
-			fmt.Println(`'none' driver does not support 'minikube docker-env' command`)
-			os.Exit(0)
+		if forceShell != "" {
+			if err := validateShell(forceShell); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
 		}
 
 		var shellCfg *ShellConfig
+		var err error
 
 		if unset {
 			shellCfg, err = shellCfgUnset()
@@ -311,14 +414,34 @@ var dockerEnvCmd = &cobra.Command{
 				cmdUtil.MaybeReportErrorAndExit(err)
 			}
 		} else {
+			api, err := machine.NewAPIClient(clientType)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+				os.Exit(1)
+			}
+			defer api.Close()
+			if err := checkDockerEnvHost(api); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			host, err := cluster.CheckIfApiExistsAndLoad(api)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting host: %s\n", err)
+				os.Exit(1)
+			}
+			if host.Driver.DriverName() == "none" {
+				fmt.Println(`'none' driver does not support 'minikube docker-env' command`)
+				os.Exit(0)
+			}
 			shellCfg, err = shellCfgSet(api)
 			if err != nil {
 				glog.Errorln("Error setting machine env variable(s):", err)
 				cmdUtil.MaybeReportErrorAndExit(err)
 			}
+			warnStaleDockerEnv(os.Stderr, api)
 		}
 
-		executeTemplateStdout(shellCfg)
+		executeTemplate(os.Stdout, shellCfg)
 	},
 }
 
@@ -327,6 +450,6 @@ func init() {
 	defaultShellDetector = &LibmachineShellDetector{}
 	defaultNoProxyGetter = &EnvNoProxyGetter{}
 	dockerEnvCmd.Flags().BoolVar(&noProxy, "no-proxy", false, "Add machine IP to NO_PROXY environment variable")
-	dockerEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Force environment to be configured for a specified shell: [fish, cmd, powershell, tcsh, bash, zsh], default is auto-detect")
+	dockerEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Force environment to be configured for a specified shell: [bash, zsh, sh, fish, tcsh, csh, powershell, cmd, emacs], default is auto-detect")
 	dockerEnvCmd.Flags().BoolVarP(&unset, "unset", "u", false, "Unset variables instead of setting them")
 }
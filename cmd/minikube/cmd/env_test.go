@@ -17,11 +17,15 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"os/exec"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/tests"
@@ -52,19 +56,19 @@ var defaultAPI = &tests.MockAPI{
 	Hosts: map[string]*host.Host{
 		config.GetMachineName(): {
 			Name:   config.GetMachineName(),
-			Driver: &tests.MockDriver{},
+			Driver: &tests.MockDriver{CurrentState: state.Running},
 		},
 	},
 }
 
-//Most of the shell cfg isn't configurable
+// Most of the shell cfg isn't configurable
 func newShellCfg(shell, prefix, suffix, delim string) *ShellConfig {
 	return &ShellConfig{
 		DockerCertPath:   constants.MakeMiniPath("certs"),
 		DockerTLSVerify:  "1",
 		DockerHost:       "tcp://127.0.0.1:2376",
 		DockerAPIVersion: constants.DockerAPIVersion,
-		UsageHint:        generateUsageHint(shell),
+		UsageHint:        generateUsageHint(shell, "minikube docker-env"),
 		Prefix:           prefix,
 		Suffix:           suffix,
 		Delimiter:        delim,
@@ -133,6 +137,13 @@ func TestShellCfgSet(t *testing.T) {
 			expectedShellCfg: newShellCfg("emacs", emacsSetPfx, emacsSetSfx, emacsSetDelim),
 			shouldErr:        false,
 		},
+		{
+			description:      "tcsh",
+			api:              defaultAPI,
+			shell:            "tcsh",
+			expectedShellCfg: newShellCfg("tcsh", tcshSetPfx, tcshSetSfx, tcshSetDelim),
+			shouldErr:        false,
+		},
 		{
 			description:  "no proxy add uppercase",
 			api:          defaultAPI,
@@ -145,7 +156,7 @@ func TestShellCfgSet(t *testing.T) {
 				DockerTLSVerify:  "1",
 				DockerHost:       "tcp://127.0.0.1:2376",
 				DockerAPIVersion: constants.DockerAPIVersion,
-				UsageHint:        usageHintMap["bash"],
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
 				Prefix:           bashSetPfx,
 				Suffix:           bashSetSfx,
 				Delimiter:        bashSetDelim,
@@ -165,7 +176,7 @@ func TestShellCfgSet(t *testing.T) {
 				DockerTLSVerify:  "1",
 				DockerHost:       "tcp://127.0.0.1:2376",
 				DockerAPIVersion: constants.DockerAPIVersion,
-				UsageHint:        usageHintMap["bash"],
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
 				Prefix:           bashSetPfx,
 				Suffix:           bashSetSfx,
 				Delimiter:        bashSetDelim,
@@ -185,7 +196,7 @@ func TestShellCfgSet(t *testing.T) {
 				DockerTLSVerify:  "1",
 				DockerHost:       "tcp://127.0.0.1:2376",
 				DockerAPIVersion: constants.DockerAPIVersion,
-				UsageHint:        usageHintMap["bash"],
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
 				Prefix:           bashSetPfx,
 				Suffix:           bashSetSfx,
 				Delimiter:        bashSetDelim,
@@ -205,7 +216,7 @@ func TestShellCfgSet(t *testing.T) {
 				DockerTLSVerify:  "1",
 				DockerHost:       "tcp://127.0.0.1:2376",
 				DockerAPIVersion: constants.DockerAPIVersion,
-				UsageHint:        usageHintMap["bash"],
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
 				Prefix:           bashSetPfx,
 				Suffix:           bashSetSfx,
 				Delimiter:        bashSetDelim,
@@ -225,7 +236,7 @@ func TestShellCfgSet(t *testing.T) {
 				DockerTLSVerify:  "1",
 				DockerHost:       "tcp://127.0.0.1:2376",
 				DockerAPIVersion: constants.DockerAPIVersion,
-				UsageHint:        usageHintMap["bash"],
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
 				Prefix:           bashSetPfx,
 				Suffix:           bashSetSfx,
 				Delimiter:        bashSetDelim,
@@ -261,6 +272,8 @@ func TestShellCfgUnset(t *testing.T) {
 	var tests = []struct {
 		description      string
 		shell            string
+		noProxyFlag      bool
+		noProxyValue     string
 		expectedShellCfg *ShellConfig
 	}{
 		{
@@ -270,7 +283,7 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    bashUnsetPfx,
 				Suffix:    bashUnsetSfx,
 				Delimiter: bashUnsetDelim,
-				UsageHint: usageHintMap["bash"],
+				UsageHint: generateUsageHint("bash", "minikube docker-env -u"),
 			},
 		},
 		{
@@ -280,7 +293,7 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    bashUnsetPfx,
 				Suffix:    bashUnsetSfx,
 				Delimiter: bashUnsetDelim,
-				UsageHint: usageHintMap["bash"],
+				UsageHint: generateUsageHint("bash", "minikube docker-env -u"),
 			},
 		},
 		{
@@ -290,7 +303,7 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    fishUnsetPfx,
 				Suffix:    fishUnsetSfx,
 				Delimiter: fishUnsetDelim,
-				UsageHint: usageHintMap["fish"],
+				UsageHint: generateUsageHint("fish", "minikube docker-env -u"),
 			},
 		},
 		{
@@ -300,7 +313,7 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    psUnsetPfx,
 				Suffix:    psUnsetSfx,
 				Delimiter: psUnsetDelim,
-				UsageHint: usageHintMap["powershell"],
+				UsageHint: generateUsageHint("powershell", "minikube docker-env -u"),
 			},
 		},
 		{
@@ -310,7 +323,7 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    cmdUnsetPfx,
 				Suffix:    cmdUnsetSfx,
 				Delimiter: cmdUnsetDelim,
-				UsageHint: usageHintMap["cmd"],
+				UsageHint: generateUsageHint("cmd", "minikube docker-env -u"),
 			},
 		},
 		{
@@ -320,15 +333,40 @@ func TestShellCfgUnset(t *testing.T) {
 				Prefix:    emacsUnsetPfx,
 				Suffix:    emacsUnsetSfx,
 				Delimiter: emacsUnsetDelim,
-				UsageHint: usageHintMap["emacs"],
+				UsageHint: generateUsageHint("emacs", "minikube docker-env -u"),
+			},
+		},
+		{
+			description: "unset tcsh",
+			shell:       "tcsh",
+			expectedShellCfg: &ShellConfig{
+				Prefix:    tcshUnsetPfx,
+				Suffix:    tcshUnsetSfx,
+				Delimiter: tcshUnsetDelim,
+				UsageHint: generateUsageHint("tcsh", "minikube docker-env -u"),
+			},
+		},
+		{
+			description:  "unset no proxy",
+			shell:        "bash",
+			noProxyFlag:  true,
+			noProxyValue: "0.0.0.0,127.0.0.1",
+			expectedShellCfg: &ShellConfig{
+				Prefix:     bashUnsetPfx,
+				Suffix:     bashUnsetSfx,
+				Delimiter:  bashUnsetDelim,
+				UsageHint:  generateUsageHint("bash", "minikube docker-env -u --no-proxy"),
+				NoProxyVar: "no_proxy",
 			},
 		},
 	}
 
+	defer func() { unset, noProxy = false, false }()
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
 			defaultShellDetector = &FakeShellDetector{test.shell}
-			defaultNoProxyGetter = &FakeNoProxyGetter{}
+			defaultNoProxyGetter = &FakeNoProxyGetter{"no_proxy", test.noProxyValue}
+			unset, noProxy = true, test.noProxyFlag
 			actual, _ := shellCfgUnset()
 			if !reflect.DeepEqual(actual, test.expectedShellCfg) {
 				t.Errorf("Actual shell config did not match expected: \n\n actual: \n%+v \n\n expected: \n%+v \n\n", actual, test.expectedShellCfg)
@@ -336,3 +374,326 @@ func TestShellCfgUnset(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteTemplate(t *testing.T) {
+	set := func(prefix, suffix, delim string) *ShellConfig {
+		cfg := newShellCfg("", prefix, suffix, delim)
+		cfg.DockerCertPath = "/home/user/.minikube/certs"
+		cfg.DockerHost = "tcp://192.168.99.100:2376"
+		cfg.DockerAPIVersion = "1.23"
+		cfg.MinikubeID = "minikube/0b4d2a36"
+		cfg.UsageHint = ""
+		return cfg
+	}
+	var tests = []struct {
+		description string
+		shellCfg    *ShellConfig
+		expected    string
+	}{
+		{
+			description: "bash",
+			shellCfg:    set(bashSetPfx, bashSetSfx, bashSetDelim),
+			expected: `export DOCKER_TLS_VERIFY="1"
+export DOCKER_HOST="tcp://192.168.99.100:2376"
+export DOCKER_CERT_PATH="/home/user/.minikube/certs"
+export DOCKER_API_VERSION="1.23"
+export DOCKER_MINIKUBE_ID="minikube/0b4d2a36"
+`,
+		},
+		{
+			description: "fish",
+			shellCfg:    set(fishSetPfx, fishSetSfx, fishSetDelim),
+			expected: `set -gx DOCKER_TLS_VERIFY "1";
+set -gx DOCKER_HOST "tcp://192.168.99.100:2376";
+set -gx DOCKER_CERT_PATH "/home/user/.minikube/certs";
+set -gx DOCKER_API_VERSION "1.23";
+set -gx DOCKER_MINIKUBE_ID "minikube/0b4d2a36";
+`,
+		},
+		{
+			description: "tcsh",
+			shellCfg:    set(tcshSetPfx, tcshSetSfx, tcshSetDelim),
+			expected: `setenv DOCKER_TLS_VERIFY "1";
+setenv DOCKER_HOST "tcp://192.168.99.100:2376";
+setenv DOCKER_CERT_PATH "/home/user/.minikube/certs";
+setenv DOCKER_API_VERSION "1.23";
+setenv DOCKER_MINIKUBE_ID "minikube/0b4d2a36";
+`,
+		},
+		{
+			description: "powershell",
+			shellCfg:    set(psSetPfx, psSetSfx, psSetDelim),
+			expected: `$Env:DOCKER_TLS_VERIFY = "1"
+$Env:DOCKER_HOST = "tcp://192.168.99.100:2376"
+$Env:DOCKER_CERT_PATH = "/home/user/.minikube/certs"
+$Env:DOCKER_API_VERSION = "1.23"
+$Env:DOCKER_MINIKUBE_ID = "minikube/0b4d2a36"
+`,
+		},
+		{
+			description: "cmd",
+			shellCfg:    set(cmdSetPfx, cmdSetSfx, cmdSetDelim),
+			expected: `SET DOCKER_TLS_VERIFY=1
+SET DOCKER_HOST=tcp://192.168.99.100:2376
+SET DOCKER_CERT_PATH=/home/user/.minikube/certs
+SET DOCKER_API_VERSION=1.23
+SET DOCKER_MINIKUBE_ID=minikube/0b4d2a36
+`,
+		},
+		{
+			description: "emacs",
+			shellCfg:    set(emacsSetPfx, emacsSetSfx, emacsSetDelim),
+			expected: `(setenv "DOCKER_TLS_VERIFY" "1")
+(setenv "DOCKER_HOST" "tcp://192.168.99.100:2376")
+(setenv "DOCKER_CERT_PATH" "/home/user/.minikube/certs")
+(setenv "DOCKER_API_VERSION" "1.23")
+(setenv "DOCKER_MINIKUBE_ID" "minikube/0b4d2a36")
+`,
+		},
+		{
+			description: "bash unset",
+			shellCfg:    &ShellConfig{Prefix: bashUnsetPfx, Suffix: bashUnsetSfx, Delimiter: bashUnsetDelim, NoProxyVar: "NO_PROXY"},
+			expected: `unset DOCKER_TLS_VERIFY
+unset DOCKER_HOST
+unset DOCKER_CERT_PATH
+unset DOCKER_API_VERSION
+unset DOCKER_MINIKUBE_ID
+unset NO_PROXY
+`,
+		},
+		{
+			description: "fish unset",
+			shellCfg:    &ShellConfig{Prefix: fishUnsetPfx, Suffix: fishUnsetSfx, Delimiter: fishUnsetDelim},
+			expected: `set -e DOCKER_TLS_VERIFY;
+set -e DOCKER_HOST;
+set -e DOCKER_CERT_PATH;
+set -e DOCKER_API_VERSION;
+set -e DOCKER_MINIKUBE_ID;
+`,
+		},
+		{
+			description: "tcsh unset",
+			shellCfg:    &ShellConfig{Prefix: tcshUnsetPfx, Suffix: tcshUnsetSfx, Delimiter: tcshUnsetDelim},
+			expected: `unsetenv DOCKER_TLS_VERIFY;
+unsetenv DOCKER_HOST;
+unsetenv DOCKER_CERT_PATH;
+unsetenv DOCKER_API_VERSION;
+unsetenv DOCKER_MINIKUBE_ID;
+`,
+		},
+		{
+			description: "powershell unset",
+			shellCfg:    &ShellConfig{Prefix: psUnsetPfx, Suffix: psUnsetSfx, Delimiter: psUnsetDelim},
+			expected: `Remove-Item Env:\DOCKER_TLS_VERIFY
+Remove-Item Env:\DOCKER_HOST
+Remove-Item Env:\DOCKER_CERT_PATH
+Remove-Item Env:\DOCKER_API_VERSION
+Remove-Item Env:\DOCKER_MINIKUBE_ID
+`,
+		},
+		{
+			description: "cmd unset",
+			shellCfg:    &ShellConfig{Prefix: cmdUnsetPfx, Suffix: cmdUnsetSfx, Delimiter: cmdUnsetDelim},
+			expected: `SET DOCKER_TLS_VERIFY=
+SET DOCKER_HOST=
+SET DOCKER_CERT_PATH=
+SET DOCKER_API_VERSION=
+SET DOCKER_MINIKUBE_ID=
+`,
+		},
+		{
+			description: "emacs unset",
+			shellCfg:    &ShellConfig{Prefix: emacsUnsetPfx, Suffix: emacsUnsetSfx, Delimiter: emacsUnsetDelim},
+			expected: `(setenv "DOCKER_TLS_VERIFY" nil)
+(setenv "DOCKER_HOST" nil)
+(setenv "DOCKER_CERT_PATH" nil)
+(setenv "DOCKER_API_VERSION" nil)
+(setenv "DOCKER_MINIKUBE_ID" nil)
+`,
+		},
+		{
+			description: "no proxy and hint",
+			shellCfg: &ShellConfig{
+				Prefix:           bashSetPfx,
+				Suffix:           bashSetSfx,
+				Delimiter:        bashSetDelim,
+				DockerTLSVerify:  "1",
+				DockerHost:       "tcp://192.168.99.100:2376",
+				DockerCertPath:   "/home/user/.minikube/certs",
+				DockerAPIVersion: "1.23",
+				MinikubeID:       "minikube/0b4d2a36",
+				NoProxyVar:       "no_proxy",
+				NoProxyValue:     "localhost,192.168.99.100",
+				UsageHint:        generateUsageHint("bash", "minikube docker-env --no-proxy"),
+			},
+			expected: `export DOCKER_TLS_VERIFY="1"
+export DOCKER_HOST="tcp://192.168.99.100:2376"
+export DOCKER_CERT_PATH="/home/user/.minikube/certs"
+export DOCKER_API_VERSION="1.23"
+export DOCKER_MINIKUBE_ID="minikube/0b4d2a36"
+export no_proxy="localhost,192.168.99.100"
+# Run this command to configure your shell:
+# eval $(minikube docker-env --no-proxy)
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := executeTemplate(&b, test.shellCfg); err != nil {
+				t.Fatalf("Error executing template: %s", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", test.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestEscapeValues(t *testing.T) {
+	const uncPath = `\\fileserver\home\First Last\.minikube\certs`
+	var tests = []struct {
+		shell    string
+		prefix   string
+		suffix   string
+		delim    string
+		expected string
+	}{
+		{shell: "bash", prefix: bashSetPfx, suffix: bashSetSfx, delim: bashSetDelim,
+			expected: `export DOCKER_CERT_PATH="\\\\fileserver\\home\\First Last\\.minikube\\certs"`},
+		{shell: "zsh", prefix: bashSetPfx, suffix: bashSetSfx, delim: bashSetDelim,
+			expected: `export DOCKER_CERT_PATH="\\\\fileserver\\home\\First Last\\.minikube\\certs"`},
+		{shell: "fish", prefix: fishSetPfx, suffix: fishSetSfx, delim: fishSetDelim,
+			expected: `set -gx DOCKER_CERT_PATH "\\\\fileserver\\home\\First Last\\.minikube\\certs";`},
+		{shell: "powershell", prefix: psSetPfx, suffix: psSetSfx, delim: psSetDelim,
+			expected: `$Env:DOCKER_CERT_PATH = "\\fileserver\home\First Last\.minikube\certs"`},
+		{shell: "cmd", prefix: cmdSetPfx, suffix: cmdSetSfx, delim: cmdSetDelim,
+			expected: `SET DOCKER_CERT_PATH=\\fileserver\home\First Last\.minikube\certs`},
+		{shell: "emacs", prefix: emacsSetPfx, suffix: emacsSetSfx, delim: emacsSetDelim,
+			expected: `(setenv "DOCKER_CERT_PATH" "\\\\fileserver\\home\\First Last\\.minikube\\certs")`},
+		{shell: "tcsh", prefix: tcshSetPfx, suffix: tcshSetSfx, delim: tcshSetDelim,
+			expected: `setenv DOCKER_CERT_PATH "\\fileserver\home\First Last\.minikube\certs";`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.shell, func(t *testing.T) {
+			cfg := newShellCfg(test.shell, test.prefix, test.suffix, test.delim)
+			cfg.DockerCertPath = uncPath
+			escapeValues(cfg, test.shell)
+			var b bytes.Buffer
+			if err := executeTemplate(&b, cfg); err != nil {
+				t.Fatalf("Error executing the template: %s", err)
+			}
+			if !strings.Contains(b.String(), test.expected+"\n") {
+				t.Errorf("Expected the line %s, got:\n%s", test.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestEscapeValuesBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	paths := []string{
+		`C:\Users\First Last\.minikube\certs`,
+		`C:\Users\Jürgen\.minikube\certs`,
+		`\\fileserver\home\alice\.minikube\certs`,
+		"/home/o'neil/$HOME/`id`/\"quoted\"/.minikube/certs",
+	}
+	for _, p := range paths {
+		t.Run(p, func(t *testing.T) {
+			cfg := newShellCfg("bash", bashSetPfx, bashSetSfx, bashSetDelim)
+			cfg.DockerCertPath = p
+			escapeValues(cfg, "bash")
+			var b bytes.Buffer
+			if err := executeTemplate(&b, cfg); err != nil {
+				t.Fatalf("Error executing the template: %s", err)
+			}
+			// As the usage hint has it, the output is evaluated unquoted.
+			cmd := exec.Command(bash, "-c", `eval $(cat); printf %s "$DOCKER_CERT_PATH"`)
+			cmd.Stdin = &b
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Error evaluating the output: %s", err)
+			}
+			if string(out) != p {
+				t.Errorf("Expected DOCKER_CERT_PATH %q, got %q", p, out)
+			}
+		})
+	}
+}
+
+func TestGenerateUsageHint(t *testing.T) {
+	var tests = []struct {
+		shell    string
+		expected string
+	}{
+		{shell: "bash", expected: "# eval $(minikube docker-env -u)\n"},
+		{shell: "zsh", expected: "# eval $(minikube docker-env -u)\n"},
+		{shell: "fish", expected: "# eval (minikube docker-env -u)\n"},
+		{shell: "tcsh", expected: "# eval `minikube docker-env -u`\n"},
+		{shell: "powershell", expected: "# & minikube docker-env -u | Invoke-Expression\n"},
+		{shell: "cmd", expected: `REM @FOR /f "tokens=*" %i IN ('minikube docker-env -u') DO @%i` + "\n"},
+		{shell: "emacs", expected: `;; (with-temp-buffer (shell-command "minikube docker-env -u" (current-buffer)) (eval-buffer))` + "\n"},
+	}
+
+	for _, test := range tests {
+		if hint := generateUsageHint(test.shell, "minikube docker-env -u"); !strings.HasSuffix(hint, test.expected) {
+			t.Errorf("Expected the hint for %s to end with %q, got %q", test.shell, test.expected, hint)
+		}
+	}
+}
+
+func TestValidateShell(t *testing.T) {
+	for _, shell := range supportedShells {
+		if err := validateShell(shell); err != nil {
+			t.Errorf("Expected %s to be supported: %s", shell, err)
+		}
+	}
+	if err := validateShell("nushell"); err == nil {
+		t.Errorf("Expected nushell not to be supported")
+	}
+}
+
+func TestCheckDockerEnvHost(t *testing.T) {
+	var cases = []struct {
+		description string
+		hosts       map[string]*host.Host
+		expectedErr string
+	}{
+		{
+			description: "running",
+			hosts:       defaultAPI.Hosts,
+		},
+		{
+			description: "stopped",
+			hosts: map[string]*host.Host{
+				config.GetMachineName(): {Name: config.GetMachineName(), Driver: &tests.MockDriver{CurrentState: state.Stopped}},
+			},
+			expectedErr: "VM is Stopped, run minikube start",
+		},
+		{
+			description: "no host",
+			hosts:       map[string]*host.Host{},
+			expectedErr: "VM does not exist",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			err := checkDockerEnvHost(&tests.MockAPI{Hosts: test.hosts})
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("Expected an error containing %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
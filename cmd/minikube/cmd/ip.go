@@ -19,13 +19,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+var (
+	ipWait        bool
+	ipWaitTimeout time.Duration
+)
+
 // ipCmd represents the ip command
 var ipCmd = &cobra.Command{
 	Use:   "ip",
@@ -38,6 +45,15 @@ var ipCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		defer api.Close()
+		if ipWait {
+			ip, err := cluster.WaitForHostIP(api, ipWaitTimeout)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Println(ip)
+			return
+		}
 		host, err := api.Load(config.GetMachineName())
 		if err != nil {
 			glog.Errorln("Error getting IP: ", err)
@@ -53,5 +69,7 @@ var ipCmd = &cobra.Command{
 }
 
 func init() {
+	ipCmd.Flags().BoolVar(&ipWait, "wait", false, "Wait for the VM to get an IP, such as right after it was started")
+	ipCmd.Flags().DurationVar(&ipWaitTimeout, "wait-timeout", 2*time.Minute, "How long --wait waits for an IP")
 	RootCmd.AddCommand(ipCmd)
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+var (
+	kubeconfigFile        string
+	kubeconfigEmbedCerts  bool
+	kubeconfigKeepContext bool
+)
+
+// kubeconfigCmd represents the kubeconfig command
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Writes the kubeconfig entry of the running cluster",
+	Long: `Writes the cluster, user and context of the running cluster, as minikube start does, without starting it.
+They are merged into the kubeconfig file, leaving its other entries as they are, or printed to stdout with --file=-.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+		h, err := cluster.CheckIfApiExistsAndLoad(api)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading machine: ", err)
+			os.Exit(1)
+		}
+		kubeHost, err := apiServerURL(h)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error getting the apiserver URL: ", err)
+			os.Exit(1)
+		}
+		setup := newKubeConfigSetup(kubeHost)
+		setup.EmbedCerts = kubeconfigEmbedCerts || viper.GetBool(cfg.EmbedCerts)
+		setup.KeepContext = kubeconfigKeepContext
+
+		if kubeconfigFile == "-" {
+			data, err := kubeconfig.EncodeKubeConfig(setup)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error encoding kubeconfig: ", err)
+				os.Exit(1)
+			}
+			os.Stdout.Write(data)
+			return
+		}
+		filename := kubeconfigFile
+		if filename == "" {
+			filename = kubeConfigPath()
+		}
+		setup.SetKubeConfigFile(filename)
+		if err := kubeconfig.SetupKubeConfig(setup); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing kubeconfig: ", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote the %s context to %s.\n", setup.ClusterName, filename)
+	},
+}
+
+func init() {
+	kubeconfigCmd.Flags().StringVar(&kubeconfigFile, "file", "", "The kubeconfig file to merge the entry into, or - to print it. Defaults to the file minikube start writes to")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigEmbedCerts, "embed-certs", false, "Inline the certificates and key in the entry rather than referencing their files. Always on when the embed-certs config setting is")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigKeepContext, "keep-context", false, "Leave the current context of the kubeconfig file as it is")
+	RootCmd.AddCommand(kubeconfigCmd)
+}
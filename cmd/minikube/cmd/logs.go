@@ -28,31 +28,50 @@ import (
 )
 
 var (
-	follow bool
+	follow   bool
+	audit    bool
+	problems bool
+	length   int
 )
 
 // logsCmd represents the logs command
 var logsCmd = &cobra.Command{
 	Use:   "logs",
-	Short: "Gets the logs of the running localkube instance, used for debugging minikube, not user code",
-	Long:  `Gets the logs of the running localkube instance, used for debugging minikube, not user code.`,
+	Short: "Gets the logs of the VM: localkube's, the container runtime's, the kernel's and those of the kube-system containers, used for debugging minikube, not user code",
+	Long: `Gets the logs of the VM, used for debugging minikube, not user code: the last lines of the logs of localkube, which
+runs the kubelet, of the container runtime, of the kernel and of the containers of kube-system, each prefixed with its log.
+When the VM is unreachable, the logs kept on the host are shown instead: how the last start went and the driver's logs.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if length <= 0 {
+			fmt.Fprintln(os.Stderr, "--length must be positive")
+			os.Exit(1)
+		}
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
 			os.Exit(1)
 		}
 		defer api.Close()
-		s, err := cluster.GetHostLogs(api, follow)
-		if err != nil {
+		if audit {
+			s, err := cluster.GetAuditLogs(api, follow)
+			if err != nil {
+				log.Println("Error getting machine logs:", err)
+				cmdUtil.MaybeReportErrorAndExit(err)
+			}
+			fmt.Fprintln(os.Stdout, s)
+			return
+		}
+		if err := cluster.WriteLogs(api, os.Stdout, cluster.LogsOptions{Follow: follow, Problems: problems, Length: length}); err != nil {
 			log.Println("Error getting machine logs:", err)
 			cmdUtil.MaybeReportErrorAndExit(err)
 		}
-		fmt.Fprintln(os.Stdout, s)
 	},
 }
 
 func init() {
-	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Show only the most recent journal entries, and continuously print new entries as they are appended to the journal.")
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Show the last lines of the logs, and continuously print the lines appended to them, interleaved as they come.")
+	logsCmd.Flags().BoolVar(&problems, "problems", false, "Show only the lines pointing out problems, such as errors, failures, and the kernel running out of memory.")
+	logsCmd.Flags().IntVarP(&length, "length", "n", 60, "How many of the last lines of each log to show.")
+	logsCmd.Flags().BoolVar(&audit, "audit", false, "Show the requests the apiserver audited, when the cluster was started with --audit-policy.")
 	RootCmd.AddCommand(logsCmd)
 }
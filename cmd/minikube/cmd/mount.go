@@ -18,36 +18,62 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"sync"
+	"text/tabwriter"
+	"time"
 
 	"strings"
 
 	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	cmdUtil "k8s.io/minikube/cmd/util"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/third_party/go9p/ufs"
 )
 
 var mountIP string
-var isKill bool
+var mountKill string
+var mountDaemon bool
+var mountList bool
+var mountUID string
+var mountGID string
+var mountFileMode string
+var mountDirMode string
+var mountMsize int
 
 // mountCmd represents the mount command
 var mountCmd = &cobra.Command{
 	Use:   "mount [flags] MOUNT_DIRECTORY(ex:\"/home\")",
 	Short: "Mounts the specified directory into minikube",
-	Long:  `Mounts the specified directory into minikube.`,
+	Long: `Mounts the specified directory into minikube.
+With --daemon, the mount runs in the background, where it outlives the terminal. "minikube mount --list"
+lists the background mounts and "minikube mount --kill <id|all>" stops them.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if isKill {
-			if err := cmdUtil.KillMountProcess(); err != nil {
-				fmt.Println("Errors occurred deleting mount process: ", err)
+		if mountList {
+			listMounts(os.Stdout)
+			return
+		}
+		if mountKill != "" {
+			id := mountKill
+			// Without "=", the ID following --kill is parsed as an argument.
+			if id == cluster.AllMounts && len(args) == 1 {
+				id = args[0]
+			}
+			if err := killMounts(config.GetMachineName(), id, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "Errors occurred stopping mounts: ", err)
 				os.Exit(1)
 			}
-			os.Exit(0)
+			return
 		}
 
 		if len(args) != 1 {
@@ -101,6 +127,25 @@ var mountCmd = &cobra.Command{
 			fmt.Println(`'none' driver does not support 'minikube mount' command`)
 			os.Exit(0)
 		}
+		mountConfig, err := cluster.NewMountConfig(mountUID, mountGID, mountFileMode, mountDirMode, mountMsize, host.RunSSHCommand)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid mount options: ", err)
+			os.Exit(1)
+		}
+		if mountDaemon {
+			if err := startMountDaemon(mountString, hostPath, vmPath, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "Error starting the mount in the background: ", err)
+				os.Exit(1)
+			}
+			return
+		}
+		// A background mount runs as a child process, which its parent replaced the other mounts into vmPath for.
+		if os.Getenv(constants.IsMinikubeChildProcess) == "" {
+			if err := replaceMounts(vmPath, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "Error stopping the mounts into the directory: ", err)
+				os.Exit(1)
+			}
+		}
 		var ip net.IP
 		if mountIP == "" {
 			ip, err = cluster.GetVMHostIP(host)
@@ -125,20 +170,119 @@ var mountCmd = &cobra.Command{
 		var wg sync.WaitGroup
 		wg.Add(1)
 		go func() {
-			ufs.StartServer(net.JoinHostPort(ip.String(), port), debugVal, hostPath)
+			ufs.StartServer(net.JoinHostPort(ip.String(), port), debugVal, hostPath, ufs.Options{
+				UID:      mountConfig.UID,
+				GID:      mountConfig.GID,
+				FileMode: uint32(mountConfig.FileMode),
+				DirMode:  uint32(mountConfig.DirMode),
+				Msize:    uint32(mountConfig.Msize),
+			})
 			wg.Done()
 		}()
-		err = cluster.MountHost(api, vmPath, ip, port)
+		session, err := cluster.MountHost(api, vmPath, ip, port, mountConfig)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
+		defer session.Close()
 		wg.Wait()
 	},
 }
 
+// startMountDaemon runs "minikube mount" for mountString in the background, detached from the
+// terminal, and records it as a mount of the machine, printing its ID on out. It replaces the
+// background mounts into vmPath, which may have been started with other options.
+func startMountDaemon(mountString, hostPath, vmPath string, out io.Writer) error {
+	if err := replaceMounts(vmPath, out); err != nil {
+		return err
+	}
+	args := []string{"mount", mountString, "--profile", config.GetMachineName(), fmt.Sprintf("--v=%d", mountDebugLevel()),
+		"--uid", mountUID, "--gid", mountGID, "--msize", strconv.Itoa(mountMsize)}
+	if mountIP != "" {
+		args = append(args, "--ip", mountIP)
+	}
+	if mountFileMode != "" {
+		args = append(args, "--file-mode", mountFileMode)
+	}
+	if mountDirMode != "" {
+		args = append(args, "--dir-mode", mountDirMode)
+	}
+	c := exec.Command(os.Args[0], args...)
+	c.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+	cluster.DetachProcess(c)
+	if err := c.Start(); err != nil {
+		return errors.Wrap(err, "Error running minikube mount")
+	}
+	if abs, err := filepath.Abs(hostPath); err == nil {
+		hostPath = abs
+	}
+	m, err := cluster.RecordMount(config.GetMachineName(), cluster.Mount{PID: c.Process.Pid, HostPath: hostPath, VMPath: vmPath, Started: time.Now()})
+	if err != nil {
+		c.Process.Kill()
+		return err
+	}
+	fmt.Fprintf(out, "Mounting %s into %s on the minikubeVM in the background, as mount %d.\n", hostPath, vmPath, m.ID)
+	fmt.Fprintf(out, "Stop it with: minikube mount --kill %d\n", m.ID)
+	return nil
+}
+
+// mountDebugLevel is the verbosity a mount running in the background is started with.
+func mountDebugLevel() int {
+	if glog.V(8) {
+		return 1
+	}
+	return 0
+}
+
+// listMounts prints the background mounts of the machine on out.
+func listMounts(out io.Writer) {
+	mounts, err := cluster.LoadMounts(config.GetMachineName())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error listing mounts: ", err)
+		os.Exit(1)
+	}
+	if len(mounts) == 0 {
+		fmt.Fprintln(out, "No background mounts")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPID\tMOUNT\tSTARTED")
+	for _, m := range mounts {
+		fmt.Fprintf(w, "%d\t%d\t%s:%s\t%s\n", m.ID, m.PID, m.HostPath, m.VMPath, m.Started.Format(time.RFC3339))
+	}
+	w.Flush()
+}
+
+// replaceMounts stops the machine's background mounts into vmPath, which a new mount replaces
+// with its own options, printing the ones it stopped on out.
+func replaceMounts(vmPath string, out io.Writer) error {
+	killed, err := cluster.KillMountsInto(config.GetMachineName(), vmPath)
+	for _, m := range killed {
+		fmt.Fprintf(out, "Replacing mount %d of %s into %s\n", m.ID, m.HostPath, m.VMPath)
+	}
+	return err
+}
+
+// killMounts stops the machine's background mount with the given ID, or all of them for
+// cluster.AllMounts, printing the ones it stopped on out.
+func killMounts(name, id string, out io.Writer) error {
+	killed, err := cluster.KillMounts(name, id)
+	for _, m := range killed {
+		fmt.Fprintf(out, "Stopped mount %d of %s into %s\n", m.ID, m.HostPath, m.VMPath)
+	}
+	return err
+}
+
 func init() {
 	mountCmd.Flags().StringVar(&mountIP, "ip", "", "Specify the ip that the mount should be setup on")
-	mountCmd.Flags().BoolVar(&isKill, "kill", false, "Kill the mount process spawned by minikube start")
+	mountCmd.Flags().BoolVar(&mountDaemon, "daemon", false, "Run the mount in the background, where it outlives the terminal")
+	mountCmd.Flags().BoolVar(&mountList, "list", false, "List the mounts running in the background, the one spawned by minikube start included")
+	mountCmd.Flags().StringVar(&mountKill, "kill", "", "Stop the mount running in the background with the given ID, or all of them with \"all\", the default")
+	mountCmd.Flags().Lookup("kill").NoOptDefVal = cluster.AllMounts
+	mountCmd.Flags().StringVar(&mountUID, "uid", cluster.DefaultMountUID, "The id or name of the VM's user owning the mounted files")
+	mountCmd.Flags().StringVar(&mountGID, "gid", cluster.DefaultMountGID, "The id or name of the VM's group owning the mounted files")
+	mountCmd.Flags().StringVar(&mountFileMode, "file-mode", "", "The octal permissions of the mounted files, as on the host by default")
+	mountCmd.Flags().StringVar(&mountDirMode, "dir-mode", "", "The octal permissions of the mounted directories, as on the host by default")
+	mountCmd.Flags().IntVar(&mountMsize, "msize", cluster.DefaultMountMsize, "The maximum size of the 9P messages, in bytes, larger ones speeding up big reads and writes")
 	RootCmd.AddCommand(mountCmd)
 }
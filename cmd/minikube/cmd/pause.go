@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	cmdUtil "k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pauses the local kubernetes cluster, leaving its VM running",
+	Long: `Freezes localkube and the cluster's containers, so that they stop using the CPU, while the VM
+keeps running. The cluster can be resumed with the "unpause" command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+
+		fmt.Println("Pausing local Kubernetes cluster...")
+		if err := cluster.PauseCluster(api); err != nil {
+			fmt.Println("Error pausing cluster: ", err)
+			cmdUtil.MaybeReportErrorAndExit(err)
+		}
+		fmt.Println("Cluster paused.")
+	},
+}
+
+// unpauseCmd represents the unpause command
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause",
+	Short: "Resumes the paused local kubernetes cluster",
+	Long:  `Resumes localkube and the cluster's containers, and waits for the apiserver to respond.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+
+		fmt.Println("Unpausing local Kubernetes cluster...")
+		if err := cluster.UnpauseCluster(api); err != nil {
+			fmt.Println("Error unpausing cluster: ", err)
+			cmdUtil.MaybeReportErrorAndExit(err)
+		}
+		fmt.Println("Cluster unpaused.")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(pauseCmd)
+	RootCmd.AddCommand(unpauseCmd)
+}
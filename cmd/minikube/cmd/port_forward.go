@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/portforward"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+var (
+	portForwardNamespace string
+	portForwardAddresses []string
+)
+
+// portForwardCmd represents the port-forward command
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward [flags] TYPE/NAME [LOCAL_PORT:]REMOTE_PORT...",
+	Short: "Forwards local ports to a pod of a service or deployment, across pod restarts",
+	Long: `Forwards local ports to the ports of a ready pod of a service, deployment or pod, such as
+"minikube port-forward service/web 8080:80". The remote port of a service is one of its ports. When
+the pod terminates, the local ports stay open and their next connections are forwarded to another
+ready pod, once there is one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Please specify a target and the ports to forward, as in: minikube port-forward service/web 8080:80")
+			os.Exit(1)
+		}
+		target, err := portforward.ParseTarget(args[0], portForwardNamespace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		pairs, err := portforward.ParsePortPairs(args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		cluster.EnsureMinikubeRunningOrExit(api, 1)
+		api.Close()
+
+		config, err := service.GetClientConfig()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		f, err := portforward.NewForwarder(config, target, pairs, portForwardAddresses, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+		if err := f.Run(stop); err != nil {
+			fmt.Fprintln(os.Stderr, "Error forwarding ports: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	portForwardCmd.Flags().StringVarP(&portForwardNamespace, "namespace", "n", "default", "The namespace of the target")
+	portForwardCmd.Flags().StringSliceVar(&portForwardAddresses, "address", []string{"localhost"}, "The addresses to listen on, comma-separated, such as 0.0.0.0 to accept connections from other hosts")
+	RootCmd.AddCommand(portForwardCmd)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// profileTimingsCmd prints the recorded timings of the machine's starts and stops.
+var profileTimingsCmd = &cobra.Command{
+	Use:    "profile-timings",
+	Short:  "Prints how long the recent starts and stops took",
+	Long:   `Prints how long each of the recent starts and stops of the cluster took, oldest first, and how long each of their steps took.`,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		timings, err := cluster.LoadTimings(cfg.GetMachineName())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error getting timings: ", err)
+			os.Exit(1)
+		}
+		printTimings(os.Stdout, timings)
+	},
+}
+
+// printTimings writes one line per timing, followed by an indented line per step.
+func printTimings(w io.Writer, timings []cluster.Timing) {
+	for _, t := range timings {
+		fmt.Fprintf(w, "%s %s %s", t.Time.Format(time.RFC3339), t.Command, t.Duration)
+		if t.Failed() {
+			fmt.Fprintf(w, " failed: %s", t.Error)
+		}
+		fmt.Fprintln(w)
+		steps := make([]string, 0, len(t.Durations))
+		for step := range t.Durations {
+			steps = append(steps, step)
+		}
+		sort.Strings(steps)
+		for _, step := range steps {
+			fmt.Fprintf(w, "    %s %s\n", step, t.Durations[step])
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(profileTimingsCmd)
+}
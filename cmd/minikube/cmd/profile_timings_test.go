@@ -0,0 +1,69 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/cluster"
+)
+
+var testTimings = []cluster.Timing{
+	{
+		Command:   cluster.TimingStart,
+		Time:      time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  90 * time.Second,
+		Durations: map[string]time.Duration{"creating-vm": 30 * time.Second, "bootstrapping": time.Minute},
+	},
+	{
+		Command:  cluster.TimingStop,
+		Time:     time.Date(2017, 6, 1, 13, 0, 0, 0, time.UTC),
+		Duration: 2 * time.Minute,
+		Error:    "timed out",
+	},
+}
+
+func TestStatusTimingsTemplate(t *testing.T) {
+	status := Status{
+		LastStart: cluster.LastTiming(testTimings, cluster.TimingStart),
+		LastStop:  cluster.LastTiming(testTimings, cluster.TimingStop),
+	}
+	tmpl := template.Must(template.New("status").Parse("{{.LastStart.Durations}} {{.LastStop.Duration}}"))
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, status); err != nil {
+		t.Fatalf("Error executing template: %s", err)
+	}
+	if expected := "map[bootstrapping:1m0s creating-vm:30s] 2m0s"; b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestPrintTimings(t *testing.T) {
+	var b bytes.Buffer
+	printTimings(&b, testTimings)
+	expected := `2017-06-01T12:00:00Z start 1m30s
+    bootstrapping 1m0s
+    creating-vm 30s
+2017-06-01T13:00:00Z stop 2m0s failed: timed out
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
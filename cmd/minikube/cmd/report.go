@@ -0,0 +1,179 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/report"
+	"k8s.io/minikube/pkg/minikube/service"
+	"k8s.io/minikube/pkg/version"
+)
+
+// reportLogLength is how many of the last lines of each log of the VM the report keeps.
+const reportLogLength = 1000
+
+var reportFile string
+
+// virtualizationTools are the commands printing the versions of the hypervisors and drivers minikube runs.
+var virtualizationTools = [][]string{
+	{"virsh", "--version"},
+	{"qemu-system-x86_64", "--version"},
+	{"docker-machine-driver-kvm2", "version"},
+	{"hyperkit", "-v"},
+	{"docker-machine-driver-hyperkit", "version"},
+	{"docker", "--version"},
+	{"kubectl", "version", "--client"},
+}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Writes a bundle of what minikube knows about the host and the cluster, to attach to issues",
+	Long: `Writes a tar.gz of what minikube knows about the host and the cluster, to attach to issues: the versions of
+minikube and of the virtualization tools, the config of the profile, how its last starts went, the logs of the
+driver and of the VM, and the kube-system pods. Proxy and registry credentials and private keys are redacted.
+index.txt lists the files of the bundle, and why those which could not be collected were not.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+
+		name := reportFile
+		if name == "" {
+			name = fmt.Sprintf("minikube-report-%s-%s.tar.gz", cfg.GetMachineName(), time.Now().Format("20060102-150405"))
+		}
+		f, err := os.Create(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		fmt.Fprintln(os.Stderr, "Collecting the report, which takes a minute when the VM is unreachable...")
+		if err := report.Write(f, reportItems(api), report.NewRedactor(addonSecretKeys()...)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote the report to %s. Check that it holds nothing private before attaching it to an issue.\n", name)
+	},
+}
+
+// addonSecretKeys returns the config keys of the settings of the addons kept in secrets, such as registry credentials.
+func addonSecretKeys() []string {
+	var keys []string
+	for name, addon := range assets.Addons {
+		for _, f := range addon.Config {
+			if f.Secret != "" {
+				keys = append(keys, assets.ConfigKey(name, f.Name))
+			}
+		}
+	}
+	return keys
+}
+
+// reportItems returns the files of the report of the profile.
+func reportItems(api libmachine.API) []report.Item {
+	machineDir := filepath.Join(constants.GetMinipath(), "machines", cfg.GetMachineName())
+	items := []report.Item{
+		{Name: "host.txt", Collect: writeHostInfo},
+		{Name: "config/config.json", Collect: copyFile(constants.ConfigFile)},
+		{Name: "machine/config.json", Collect: copyFile(filepath.Join(machineDir, "config.json"))},
+		{Name: "machine/start-state.json", Collect: copyFile(filepath.Join(machineDir, "start-state.json"))},
+		{Name: "machine/timings.json", Collect: copyFile(filepath.Join(machineDir, "timings.json"))},
+	}
+	if path := viper.GetString(traceFile); path != "" {
+		items = append(items, report.Item{Name: "trace.jsonl", Collect: copyFile(path)})
+	}
+	if profile := cfg.GetMachineName(); profile != constants.DefaultMachineName {
+		items = append(items, report.Item{Name: "config/profile.json", Collect: copyFile(constants.ProfileConfigFile(profile))})
+	}
+	if h, err := cluster.CheckIfApiExistsAndLoad(api); err == nil {
+		for _, p := range cluster.HostLogPaths(h) {
+			items = append(items, report.Item{Name: "driver/" + filepath.Base(p), Collect: copyFile(p)})
+		}
+	}
+	return append(items,
+		report.Item{Name: "vm-logs.txt", Collect: func(w io.Writer) error {
+			return cluster.WriteLogs(api, w, cluster.LogsOptions{Length: reportLogLength})
+		}},
+		report.Item{Name: "kube-system-pods.txt", Collect: func(w io.Writer) error {
+			client, err := (&service.K8sClientGetter{}).GetCoreClient()
+			if err != nil {
+				return errors.Wrap(err, "Error getting the kubernetes client")
+			}
+			return cluster.DescribePods(client, w)
+		}},
+	)
+}
+
+func copyFile(path string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
+
+// writeHostInfo writes the versions of minikube, of the host OS and of the virtualization tools found on the host.
+func writeHostInfo(w io.Writer) error {
+	fmt.Fprintf(w, "minikube version: %s\n", version.GetVersion())
+	fmt.Fprintf(w, "ISO version: %s\n", version.GetIsoVersion())
+	fmt.Fprintf(w, "OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "Profile: %s\n\n", cfg.GetMachineName())
+	tools := append([][]string{{cluster.DetectVBoxManageCmd(), "--version"}}, virtualizationTools...)
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			fmt.Fprintf(w, "%s: not found\n", tool[0])
+			continue
+		}
+		out, err := exec.Command(tool[0], tool[1:]...).CombinedOutput()
+		if err != nil {
+			fmt.Fprintf(w, "%s: %s: %s\n", tool[0], err, strings.TrimSpace(string(out)))
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", tool[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportFile, "file", "", "The file the report is written to, minikube-report-<profile>-<time>.tar.gz in the current directory by default")
+	RootCmd.AddCommand(reportCmd)
+}
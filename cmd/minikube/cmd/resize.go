@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	cmdUtil "k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+var (
+	resizeMemory int
+	resizeCPUs   int
+)
+
+// resizeCmd represents the resize command
+var resizeCmd = &cobra.Command{
+	Use:   "resize",
+	Short: "Changes the memory and CPUs of the local kubernetes cluster's VM",
+	Long: `Changes the memory and CPUs of the minikube VM without recreating it. The VM is stopped
+while they are changed, and started again if it was running. Only the virtualbox and kvm2 drivers support this.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if resizeMemory == 0 && resizeCPUs == 0 {
+			fmt.Fprintln(os.Stderr, "Pass --memory or --cpus to resize the VM")
+			os.Exit(1)
+		}
+		if resizeMemory != 0 && resizeMemory < constants.MinimumMemoryMB {
+			fmt.Fprintf(os.Stderr, "Memory %dMB is invalid, the minimum memory is %dMB\n", resizeMemory, constants.MinimumMemoryMB)
+			os.Exit(1)
+		}
+		if resizeCPUs < 0 {
+			fmt.Fprintf(os.Stderr, "CPUs %d is invalid\n", resizeCPUs)
+			os.Exit(1)
+		}
+
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+
+		fmt.Println("Resizing local Kubernetes cluster VM...")
+		restarted, err := cluster.ResizeHost(api, resizeMemory, resizeCPUs, retryPolicy())
+		if _, ok := err.(cluster.ErrResizeUnsupported); ok {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error resizing machine: ", err)
+			cmdUtil.MaybeReportErrorAndExit(err)
+		}
+		fmt.Println("Machine resized.")
+		if restarted {
+			fmt.Println(`The VM was started again, run "minikube start" with the same --memory and --cpus to start the cluster.`)
+		}
+	},
+}
+
+func init() {
+	resizeCmd.Flags().IntVar(&resizeMemory, memory, 0, "Amount of RAM to give the minikube VM, in MB")
+	resizeCmd.Flags().IntVar(&resizeCPUs, cpus, 0, "Number of CPUs to give the minikube VM")
+	RootCmd.AddCommand(resizeCmd)
+}
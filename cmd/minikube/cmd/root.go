@@ -17,9 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	goflag "flag"
-	"fmt"
-	"io/ioutil"
 	"os"
 	"runtime"
 	"strings"
@@ -31,7 +31,9 @@ import (
 	"github.com/spf13/viper"
 	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
 	"k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/notify"
@@ -41,9 +43,9 @@ var dirs = [...]string{
 	constants.GetMinipath(),
 	constants.MakeMiniPath("certs"),
 	constants.MakeMiniPath("machines"),
-	constants.MakeMiniPath("cache"),
-	constants.MakeMiniPath("cache", "iso"),
-	constants.MakeMiniPath("cache", "localkube"),
+	constants.GetCacheDir(),
+	constants.MakeCachePath("iso"),
+	constants.MakeCachePath("localkube"),
 	constants.MakeMiniPath("config"),
 	constants.MakeMiniPath("addons"),
 	constants.MakeMiniPath("logs"),
@@ -52,6 +54,11 @@ var dirs = [...]string{
 const (
 	showLibmachineLogs = "show-libmachine-logs"
 	useVendoredDriver  = "use-vendored-driver"
+	machineOpRetries   = "machine-op-retries"
+	machineOpBackoff   = "machine-op-backoff"
+	interactive        = "interactive"
+	quiet              = "quiet"
+	traceFile          = "trace-file"
 )
 
 var (
@@ -65,36 +72,70 @@ var viperWhiteList = []string{
 	"log_dir",
 }
 
+// retryPolicy returns how driver operations are retried, as set by --machine-op-retries
+// and --machine-op-backoff.
+func retryPolicy() cluster.RetryPolicy {
+	return cluster.RetryPolicy{
+		Retries: viper.GetInt(machineOpRetries),
+		Backoff: viper.GetDuration(machineOpBackoff),
+	}
+}
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "minikube",
 	Short: "Minikube is a tool for managing local Kubernetes clusters.",
 	Long:  `Minikube is a CLI tool that provisions and manages single-node Kubernetes clusters optimized for development workflows.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// An invalid profile can still be switched away from.
+		if cmd != configCmd.ProfileCmd && cmd.Parent() != configCmd.ProfileCmd {
+			if err := config.ValidateProfileName(config.GetMachineName()); err != nil {
+				console.ErrLn(err)
+				os.Exit(1)
+			}
+		}
+
+		// The settings' environment variables are checked up front, as the flags are.
+		if err := configCmd.ValidateEnv(); err != nil {
+			console.ErrLn(err)
+			os.Exit(1)
+		}
+
 		for _, path := range dirs {
 			if err := os.MkdirAll(path, 0777); err != nil {
 				glog.Exitf("Error creating minikube directory: %s", err)
 			}
 		}
 
-		// Log level 3 or greater enables libmachine logs
-		if !glog.V(3) {
-			log.SetOutWriter(ioutil.Discard)
-			log.SetErrWriter(ioutil.Discard)
+		if path := viper.GetString(traceFile); path != "" {
+			if err := console.SetTraceFile(path); err != nil {
+				console.ErrLn(err)
+				os.Exit(1)
+			}
 		}
 
-		// Log level 7 or greater enables debug level logs
-		if glog.V(7) {
+		// libmachine logs to the minikube log from level 3, and its debug logs from level 7,
+		// rather than to the terminal. --alsologtostderr shows them.
+		log.SetOutWriter(console.LogWriter(console.LevelCommand))
+		log.SetErrWriter(console.LogWriter(console.LevelCommand))
+		if glog.V(console.LevelTranscript) {
 			log.SetDebug(true)
 		}
 
 		if viper.GetBool(showLibmachineLogs) {
-			fmt.Println(`
+			console.ErrLn(`
 --show-libmachine-logs is deprecated.
-Please use --v=3 to show libmachine logs, and --v=7 for debug level libmachine logs
+Please use --v=3 --alsologtostderr to show libmachine logs, and --v=7 for debug level libmachine logs
 `)
 		}
 
+		// Without --interactive, minikube only prompts when stdout is a terminal.
+		_, interactiveEnv := os.LookupEnv(constants.MinikubeEnvPrefix + "_INTERACTIVE")
+		if cmd.Flags().Changed(interactive) || interactiveEnv {
+			console.SetInteractive(viper.GetBool(interactive))
+		}
+		console.SetQuiet(viper.GetBool(quiet))
+
 		//TODO(r2d4): config should not reference API
 		clientType = configCmd.GetClientType()
 
@@ -103,7 +144,10 @@ Please use --v=3 to show libmachine logs, and --v=7 for debug level libmachine l
 			logDir.Value.Set(constants.MakeMiniPath("logs"))
 		}
 
-		if enableUpdateNotification {
+		if console.Quiet() {
+			return
+		}
+		if enableUpdateNotification && !viper.GetBool(offline) {
 			notify.MaybePrintUpdateTextFromGithub(os.Stderr)
 		}
 		util.MaybePrintKubectlDownloadMsg(runtime.GOOS, os.Stderr)
@@ -134,10 +178,22 @@ func setFlagsUsingViper() {
 }
 
 func init() {
-	RootCmd.PersistentFlags().Bool(showLibmachineLogs, false, "Deprecated: To enable libmachine logs, set --v=3 or higher")
+	RootCmd.PersistentFlags().Bool(showLibmachineLogs, false, "Deprecated: To enable libmachine logs, set --v=3 or higher with --alsologtostderr")
 	RootCmd.PersistentFlags().Bool(useVendoredDriver, false, "Use the vendored in drivers instead of RPC")
-	RootCmd.PersistentFlags().StringP(config.MachineProfile, "p", constants.DefaultMachineName, `The name of the minikube VM being used.  
-	This can be modified to allow for multiple minikube instances to be run independently`)
+	RootCmd.PersistentFlags().Int(machineOpRetries, constants.DefaultMachineOpRetries, "How many times to retry creating, starting or stopping the VM when the driver fails with a transient error")
+	RootCmd.PersistentFlags().Duration(machineOpBackoff, constants.DefaultMachineOpBackoff, "How long to wait before the first retry of a driver operation, which doubles for each following retry")
+	RootCmd.PersistentFlags().StringP(config.MachineProfile, "p", constants.DefaultMachineName, `The name of the minikube VM being used, which must be a valid hostname. Also set with MINIKUBE_PROFILE.  
+	This can be modified to allow for multiple minikube instances to be run independently, each with its own config, certs and kubeconfig context`)
+	// The profiles are completed by a function of the bash completion, see completion.go.
+	cobra.MarkFlagCustom(RootCmd.PersistentFlags(), config.MachineProfile, "__minikube_get_profiles")
+	RootCmd.PersistentFlags().Bool(interactive, true, "Whether minikube may prompt for input, rather than taking the default answers or failing. Defaults to whether stdout is a terminal")
+	RootCmd.PersistentFlags().BoolP(quiet, "q", false, "Only print the result and the errors, without the progress output")
+	RootCmd.PersistentFlags().String(traceFile, "", "The file the commands minikube runs on the host and in the VM are appended to, as JSON lines with their duration, exit code and output. Also set with MINIKUBE_TRACE_FILE")
+	RootCmd.PersistentFlags().String(config.RemoteHost, "", "The host[:port] of a remote machine to manage the minikube VM on over SSH")
+	RootCmd.PersistentFlags().String(config.RemoteUser, "", "The user to log into the remote host with (only used with --remote-host)")
+	RootCmd.PersistentFlags().String(config.RemoteSSHKey, "", "The private key used to log into the remote host (only used with --remote-host)")
+	RootCmd.PersistentFlags().String(config.RemoteHostKey, "", "The SHA256 fingerprint of the remote host's SSH host key, as printed by ssh-keygen -lf, which it must present. Also set with MINIKUBE_REMOTE_HOST_KEY (only used with --remote-host)")
+	RootCmd.PersistentFlags().String(config.RemoteStorePath, "", "The path of the machine store on the remote host. Defaults to ~/.minikube (only used with --remote-host)")
 	RootCmd.AddCommand(configCmd.ConfigCmd)
 	RootCmd.AddCommand(configCmd.AddonsCmd)
 	RootCmd.AddCommand(configCmd.ProfileCmd)
@@ -148,8 +204,9 @@ func init() {
 
 }
 
-// initConfig reads in config file and ENV variables if set.
+// initConfig reads in the config file of the profile and ENV variables if set.
 func initConfig() {
+	// The global config file, which may set the profile, is read first.
 	configPath := constants.ConfigFile
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("json")
@@ -157,15 +214,44 @@ func initConfig() {
 	if err != nil {
 		glog.Warningf("Error reading config file at %s: %s", configPath, err)
 	}
+	// The profile can be set in the environment too, which wins over the flag, and else falls back
+	// to the active one. The profiles other than the default one only share the global settings
+	// of the global config file, the rest of theirs is their own.
+	bindEnv()
+	flag := RootCmd.PersistentFlags().Lookup(config.MachineProfile)
+	viper.Set(config.MachineProfile, config.ResolveProfile(flag.Value.String(), flag.Changed))
+	if profile := config.GetMachineName(); profile != constants.DefaultMachineName {
+		if err := readProfileConfig(profile); err != nil {
+			glog.Warningf("Error reading the config of profile %s: %s", profile, err)
+		}
+	}
 	setupViper()
 }
 
-func setupViper() {
+// readProfileConfig replaces the config viper holds with the one of the named profile.
+func readProfileConfig(profile string) error {
+	m, err := config.ReadProfileConfig(profile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return viper.ReadConfig(bytes.NewReader(data))
+}
+
+// bindEnv has viper read the settings from the MINIKUBE_ environment variables too.
+func bindEnv() {
 	viper.SetEnvPrefix(constants.MinikubeEnvPrefix)
-	// Replaces '-' in flags with '_' in env variables
+	// Replaces '-' in flags and '.' in settings with '_' in env variables, as config.EnvName does
 	// e.g. iso-url => $ENVPREFIX_ISO_URL
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 	viper.AutomaticEnv()
+}
+
+func setupViper() {
+	bindEnv()
 
 	viper.SetDefault(config.WantUpdateNotification, true)
 	viper.SetDefault(config.ReminderWaitPeriodInHours, 24)
@@ -21,10 +21,12 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/tests"
 )
@@ -194,3 +196,13 @@ func TestViperAndFlags(t *testing.T) {
 		unsetValues(tt)
 	}
 }
+
+func TestRetryPolicy(t *testing.T) {
+	defer viper.Reset()
+	viper.Set(machineOpRetries, 4)
+	viper.Set(machineOpBackoff, "500ms")
+	expected := cluster.RetryPolicy{Retries: 4, Backoff: 500 * time.Millisecond}
+	if policy := retryPolicy(); policy != expected {
+		t.Errorf("Expected the retry policy %+v, got %+v", expected, policy)
+	}
+}
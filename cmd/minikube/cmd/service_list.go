@@ -17,11 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"strings"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/kubernetes/pkg/api/v1"
 
@@ -29,47 +31,86 @@ import (
 	"k8s.io/minikube/pkg/minikube/service"
 )
 
-var serviceListNamespace string
+var (
+	serviceListNamespace     string
+	serviceListAllNamespaces bool
+	serviceListOutput        string
+)
 
 // serviceListCmd represents the service list command
 var serviceListCmd = &cobra.Command{
 	Use:   "list [flags]",
 	Short: "Lists the URLs for the services in your local cluster",
-	Long:  `Lists the URLs for the services in your local cluster`,
+	Long: `Lists the URLs of the node ports of the services in your local cluster, one port a line. The ports without a
+node port are shown with a dash. Headless services are left out.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if serviceListAllNamespaces && cmd.Flags().Changed("namespace") {
+			fmt.Fprintln(os.Stderr, "Pass either --namespace or --all-namespaces")
+			os.Exit(1)
+		}
+		ns := serviceListNamespace
+		if serviceListAllNamespaces {
+			ns = v1.NamespaceAll
+		}
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
 			os.Exit(1)
 		}
 		defer api.Close()
-		serviceURLs, err := service.GetServiceURLs(api, serviceListNamespace, serviceURLTemplate)
+		urls, err := service.GetServicePortURLs(api, ns, serviceURLTemplate)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			fmt.Fprintln(os.Stderr, "Check that minikube is running and that you have specified the correct namespace (-n flag) if required.")
 			os.Exit(1)
 		}
+		if err := printServiceList(os.Stdout, urls, serviceListOutput); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
 
-		var data [][]string
-		for _, serviceURL := range serviceURLs {
-			if len(serviceURL.URLs) == 0 {
-				data = append(data, []string{serviceURL.Namespace, serviceURL.Name, "No node port"})
-			} else {
-				data = append(data, []string{serviceURL.Namespace, serviceURL.Name, strings.Join(serviceURL.URLs, "\n")})
-			}
+// printServiceList writes the URLs to out as a table, or as JSON.
+func printServiceList(out io.Writer, urls []service.ServicePortURL, output string) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(urls, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling the service URLs")
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case "table":
+	default:
+		return errors.Errorf("Invalid --output %q, expected table or json", output)
+	}
 
+	var data [][]string
+	for _, u := range urls {
+		target, url := u.TargetPort, u.URL
+		if target == "" {
+			target = "-"
+		}
+		if url == "" {
+			url = "-"
 		}
+		data = append(data, []string{u.Namespace, u.Name, target, url})
+	}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Namespace", "Name", "URL"})
-		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
-		table.SetCenterSeparator("|")
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
-	},
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Namespace", "Name", "Target Port", "URL"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetCenterSeparator("|")
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.AppendBulk(data) // Add Bulk Data
+	table.Render()
+	return nil
 }
 
 func init() {
-	serviceListCmd.Flags().StringVarP(&serviceListNamespace, "namespace", "n", v1.NamespaceAll, "The services namespace")
+	serviceListCmd.Flags().StringVarP(&serviceListNamespace, "namespace", "n", v1.NamespaceAll, "The services namespace, all of them by default")
+	serviceListCmd.Flags().BoolVar(&serviceListAllNamespaces, "all-namespaces", false, "List the services of all namespaces")
+	serviceListCmd.Flags().StringVarP(&serviceListOutput, "output", "o", "table", "The output format, table or json")
 	serviceCmd.AddCommand(serviceListCmd)
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+func TestPrintServiceList(t *testing.T) {
+	urls := []service.ServicePortURL{
+		{Namespace: "web", Name: "shop", TargetPort: "http", URL: "http://192.168.99.100:30080"},
+		{Namespace: "web", Name: "shop", TargetPort: "5432"},
+		{Namespace: "default", Name: "no-ports"},
+	}
+
+	var b bytes.Buffer
+	if err := printServiceList(&b, urls, "table"); err != nil {
+		t.Fatalf("Error printing the table: %s", err)
+	}
+	for _, row := range []string{
+		"| web       | shop     | http        | http://192.168.99.100:30080 |",
+		"| web       | shop     | 5432        | -                           |",
+		"| default   | no-ports | -           | -                           |",
+	} {
+		if !strings.Contains(b.String(), row) {
+			t.Errorf("Expected the row %q in the table:\n%s", row, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := printServiceList(&b, urls, "json"); err != nil {
+		t.Fatalf("Error printing JSON: %s", err)
+	}
+	var printed []service.ServicePortURL
+	if err := json.Unmarshal(b.Bytes(), &printed); err != nil {
+		t.Fatalf("Error unmarshalling %s: %s", b.String(), err)
+	}
+	if !reflect.DeepEqual(printed, urls) {
+		t.Errorf("Expected %+v, got %+v", urls, printed)
+	}
+	if !strings.Contains(b.String(), `"targetPort": "http"`) {
+		t.Errorf("Expected the target port in the JSON: %s", b.String())
+	}
+
+	if err := printServiceList(&b, urls, "yaml"); err == nil {
+		t.Error("Expected an error printing yaml")
+	}
+}
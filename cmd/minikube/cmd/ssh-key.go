@@ -18,24 +18,64 @@ package cmd
 
 import (
 	"fmt"
-
-	"path/filepath"
+	"os"
 
 	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
-	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+// machineNotExistExitCode is the exit code of the commands which need a machine, when it
+// hasn't been created, which lets scripts tell it apart from other errors.
+const machineNotExistExitCode = 2
+
 // sshKeyCmd represents the sshKey command
 var sshKeyCmd = &cobra.Command{
 	Use:   "ssh-key",
 	Short: "Retrieve the ssh identity key path of the specified cluster",
-	Long:  "Retrieve the ssh identity key path of the specified cluster.",
+	Long:  "Retrieve the ssh identity key path of the specified cluster. Exits with 2 when the cluster doesn't exist.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(filepath.Join(constants.GetMinipath(), "machines", config.GetMachineName(), "id_rsa"))
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+		// The key path is read without resolving the host, which fails while the VM is stopped.
+		path, err := cluster.GetSSHKeyPath(api)
+		exitOnSSHError(err)
+		fmt.Println(path)
 	},
 }
 
+// sshInfoOrExit returns the SSH coordinates of the machine, or exits with
+// machineNotExistExitCode if it hasn't been created.
+func sshInfoOrExit() cluster.SSHInfo {
+	api, err := machine.NewAPIClient(clientType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+		os.Exit(1)
+	}
+	defer api.Close()
+	info, err := cluster.GetSSHInfo(api)
+	exitOnSSHError(err)
+	return info
+}
+
+// exitOnSSHError exits with machineNotExistExitCode if the machine hasn't been created,
+// or with 1 on any other error reading its SSH coordinates.
+func exitOnSSHError(err error) {
+	if err == cluster.ErrMachineNotExist {
+		fmt.Fprintf(os.Stderr, "Machine %q does not exist\n", config.GetMachineName())
+		os.Exit(machineNotExistExitCode)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting ssh info: %s\n", err)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	RootCmd.AddCommand(sshKeyCmd)
 }
@@ -19,7 +19,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -27,12 +29,19 @@ import (
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+var nativeSSHClient bool
+
 // sshCmd represents the docker-ssh command
 var sshCmd = &cobra.Command{
 	Use:   "ssh",
 	Short: "Log into or run a command on a machine with SSH; similar to 'docker-machine ssh'",
-	Long:  "Log into or run a command on a machine with SSH; similar to 'docker-machine ssh'",
+	Long: `Log into or run a command on a machine with SSH; similar to 'docker-machine ssh'.
+With a command, it is run without a PTY: its standard output and error are kept apart and
+minikube ssh exits with its exit code.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if nativeSSHClient {
+			ssh.SetDefaultClient(ssh.Native)
+		}
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
@@ -48,14 +57,23 @@ var sshCmd = &cobra.Command{
 			fmt.Println(`'none' driver does not support 'minikube ssh' command`)
 			os.Exit(0)
 		}
+		if len(args) > 0 {
+			code, err := cluster.RunSSHCommand(api, strings.Join(args, " "), os.Stdout, os.Stderr)
+			if err != nil {
+				glog.Errorln(errors.Wrap(err, "Error attempting to run-ssh-command"))
+				os.Exit(1)
+			}
+			os.Exit(code)
+		}
 		err = cluster.CreateSSHShell(api, args)
 		if err != nil {
-			glog.Errorln(errors.Wrap(err, "Error attempting to ssh/run-ssh-command"))
+			glog.Errorln(errors.Wrap(err, "Error attempting to ssh"))
 			os.Exit(1)
 		}
 	},
 }
 
 func init() {
+	sshCmd.Flags().BoolVar(&nativeSSHClient, "native-ssh", false, "Use the native Go SSH client instead of the external ssh binary, which can break on Windows paths")
 	RootCmd.AddCommand(sshCmd)
 }
@@ -17,27 +17,45 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	units "github.com/docker/go-units"
+	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/host"
+	"github.com/ghodss/yaml"
 	"github.com/golang/glog"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
 	cmdUtil "k8s.io/minikube/cmd/util"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/autorestart"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/images"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/kubernetes_versions"
 	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/preflight"
+	"k8s.io/minikube/pkg/minikube/service"
+	"k8s.io/minikube/pkg/provision"
 	"k8s.io/minikube/pkg/util"
 	pkgutil "k8s.io/minikube/pkg/util"
 )
@@ -54,6 +72,7 @@ const (
 	containerRuntime      = "container-runtime"
 	networkPlugin         = "network-plugin"
 	hypervVirtualSwitch   = "hyperv-virtual-switch"
+	hypervExternalSwitch  = "hyperv-use-external-switch"
 	kvmNetwork            = "kvm-network"
 	keepContext           = "keep-context"
 	createMount           = "mount"
@@ -61,13 +80,51 @@ const (
 	apiServerName         = "apiserver-name"
 	dnsDomain             = "dns-domain"
 	mountString           = "mount-string"
+	forceRecreate         = "force-recreate"
+	recreateOnChange      = "recreate-on-config-change"
+	keepFailed            = "keep-failed"
+	dryRun                = "dry-run"
+	skipPreflightChecks   = "skip-preflight-checks"
+	extraDisks            = "extra-disks"
+	extraDiskSize         = "extra-disk-size"
+	natForwardKubeconfig  = "nat-forward-kubeconfig"
+	gpu                   = "gpu"
+	waitTimeout           = "wait-timeout"
+	offline               = "offline"
+	httpProxy             = "http-proxy"
+	httpsProxy            = "https-proxy"
+	noProxyList           = "no-proxy"
+	dockerEnvProxy        = "docker-env-proxy"
+	printProxyConfig      = "print-proxy-config"
+	outputFormat          = "output"
+	force                 = "force"
+	skipDNSCheck          = "skip-dns-check"
+	serviceClusterIPRange = "service-cluster-ip-range"
+	podNetworkCIDR        = "pod-network-cidr"
+	forceFresh            = "force-fresh"
+	wait                  = "wait"
+	forceDowngrade        = "force-downgrade"
+	auditPolicy           = "audit-policy"
+	staticManifests       = "static-manifests"
+	insecureRegistryFlag  = "insecure-registry"
+	dockerOptFlag         = "docker-opt"
+	registryMirrorFlag    = "registry-mirror"
 )
 
+// dnsCheckTimeout is how long the DNS check waits for kube-dns to start and the lookup to complete.
+const dnsCheckTimeout = 3 * time.Minute
+
 var (
 	registryMirror   []string
 	dockerEnv        []string
 	dockerOpt        []string
 	insecureRegistry []string
+	natForward       []string
+	apiServerNames   []string
+	apiServerIPs     []string
+	caCertPath       string
+	caKeyPath        string
+	registryCAs      []string
 	extraOptions     util.ExtraOptionSlice
 )
 
@@ -76,19 +133,46 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts a local kubernetes cluster",
 	Long: `Starts a local kubernetes cluster using VM. This command
-assumes you have already installed one of the VM drivers: virtualbox/vmwarefusion/kvm/xhyve/hyperv.`,
+assumes you have already installed one of the VM drivers: virtualbox/vmwarefusion/kvm/xhyve/hyperkit/hyperv.`,
 	Run: runStart,
 }
 
 func runStart(cmd *cobra.Command, args []string) {
+	if viper.GetString(vmDriver) == "help" {
+		printDrivers(console.OutWriter())
+		return
+	}
+
 	api, err := machine.NewAPIClient(clientType)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+		console.Err("Error getting client: %s\n", err)
 		os.Exit(1)
 	}
 	defer api.Close()
 
-	diskSize := viper.GetString(humanReadableDiskSize)
+	m, err := effectiveConfig()
+	if err != nil {
+		glog.Warningf("Not using driver-specific settings: %s", err)
+		m = cfg.MinikubeConfig{}
+	}
+
+	// The settings which aren't given are the ones the cluster was last started with.
+	requestedSettings := applyClusterConfig(api, cmd.Flags(), m)
+	driver := viper.GetString(vmDriver)
+	// Creating the VM without the GPU it was asked for would only fail later, in the pods needing it.
+	if def, _ := machine.FindDriverDef(driver); viper.GetBool(gpu) && !def.SupportsFlag(gpu) {
+		console.Err("--%s is not supported by the %s driver, use --vm-driver=kvm2\n", gpu, driver)
+		os.Exit(1)
+	}
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags = append(flags, f.Name)
+	})
+	for _, f := range machine.UnsupportedFlags(driver, flags) {
+		console.Err("Warning: --%s is not supported by the %s driver and will be ignored\n", f, driver)
+	}
+
+	diskSize := driverSetting(cmd.Flags(), m, humanReadableDiskSize, driver)
 	diskSizeMB := calculateDiskSizeInMB(diskSize)
 
 	if diskSizeMB < constants.MinimumDiskSizeMB {
@@ -97,148 +181,653 @@ func runStart(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if dv := viper.GetString(kubernetesVersion); dv != constants.DefaultKubernetesVersion {
-		validateK8sVersion(dv)
+	memorySetting := driverSetting(cmd.Flags(), m, memory, driver)
+	memoryMB, err := strconv.Atoi(memorySetting)
+	if err != nil || memoryMB < constants.MinimumMemoryMB {
+		console.Err("Memory %q is invalid, the minimum memory is %dMB\n", memorySetting, constants.MinimumMemoryMB)
+		os.Exit(1)
+	}
+	cpusSetting := driverSetting(cmd.Flags(), m, cpus, driver)
+	cpuCount, err := strconv.Atoi(cpusSetting)
+	if err != nil || cpuCount < 1 {
+		console.Err("CPUs %q is invalid, at least 1 CPU is needed\n", cpusSetting)
+		os.Exit(1)
 	}
 
-	config := cluster.MachineConfig{
-		MinikubeISO:         viper.GetString(isoURL),
-		Memory:              viper.GetInt(memory),
-		CPUs:                viper.GetInt(cpus),
-		DiskSize:            diskSizeMB,
-		VMDriver:            viper.GetString(vmDriver),
-		XhyveDiskDriver:     viper.GetString(xhyveDiskDriver),
-		DockerEnv:           dockerEnv,
-		DockerOpt:           dockerOpt,
-		InsecureRegistry:    insecureRegistry,
-		RegistryMirror:      registryMirror,
-		HostOnlyCIDR:        viper.GetString(hostOnlyCIDR),
-		HypervVirtualSwitch: viper.GetString(hypervVirtualSwitch),
-		KvmNetwork:          viper.GetString(kvmNetwork),
-		Downloader:          pkgutil.DefaultDownloader{},
-	}
-
-	fmt.Printf("Starting local Kubernetes %s cluster...\n", viper.GetString(kubernetesVersion))
-	fmt.Println("Starting VM...")
-	var host *host.Host
-	start := func() (err error) {
-		host, err = cluster.StartHost(api, config)
+	extraDiskSizeMB, err := validateExtraDisks(viper.GetInt(extraDisks), viper.GetString(extraDiskSize))
+	if err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+
+	natForwards, err := cluster.ParsePortForwards(natForward)
+	if err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+
+	sharedFolder, err := nativeSharedFolder(cmd.Flags(), driver)
+	if err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+
+	// The names and IPs of the last start are kept, unless others are given.
+	sans := cluster.StoredAPIServerSANs(cfg.GetMachineName())
+	if cmd.Flags().Changed("apiserver-names") || cmd.Flags().Changed("apiserver-ips") {
+		sans, err = cluster.ParseAPIServerSANs(apiServerNames, apiServerIPs)
 		if err != nil {
-			glog.Errorf("Error starting host: %s.\n\n Retrying.\n", err)
+			console.ErrLn(err)
+			os.Exit(1)
 		}
-		return err
 	}
-	err = util.RetryAfter(5, start, 2*time.Second)
+
+	// The service and pod ranges of the last start are kept, unless others are given.
+	serviceCIDR, podCIDR := cluster.StoredClusterCIDRs(cfg.GetMachineName())
+	if serviceCIDR, err = clusterCIDR(cmd.Flags(), serviceClusterIPRange, serviceCIDR); err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+	if podCIDR, err = clusterCIDR(cmd.Flags(), podNetworkCIDR, podCIDR); err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+
+	// A supplied CA replaces the one the cluster's certificates are signed with.
+	caChanged := false
+	if caCertPath != "" || caKeyPath != "" {
+		if caCertPath == "" || caKeyPath == "" {
+			console.ErrLn("--ca-cert and --ca-key must be passed together")
+			os.Exit(1)
+		}
+		caChanged, err = cluster.InstallCA(caCertPath, caKeyPath)
+		if err != nil {
+			console.ErrLn(err)
+			os.Exit(1)
+		}
+	}
+
+	if _, err := cruntime.Lookup(viper.GetString(containerRuntime)); err != nil {
+		console.Err("Invalid --%s: %s\n", containerRuntime, err)
+		os.Exit(1)
+	}
+
+	// The extra config of earlier starts is kept, the values passed now replacing theirs.
+	extraConfig, err := mergeExtraConfig(extraOptions)
 	if err != nil {
-		glog.Errorln("Error starting host: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+		console.ErrLn(err)
+		os.Exit(1)
 	}
 
-	ip, err := host.Driver.GetIP()
+	// The insecure registries of earlier starts are kept, along with the ones passed now.
+	registries, err := mergeInsecureRegistries(insecureRegistry)
 	if err != nil {
-		glog.Errorln("Error starting host: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+		console.Err("Invalid --%s: %s\n", insecureRegistryFlag, err)
+		os.Exit(1)
 	}
-	kubernetesConfig := cluster.KubernetesConfig{
-		KubernetesVersion: viper.GetString(kubernetesVersion),
-		NodeIP:            ip,
-		APIServerName:     viper.GetString(apiServerName),
-		DNSDomain:         viper.GetString(dnsDomain),
-		FeatureGates:      viper.GetString(featureGates),
-		ContainerRuntime:  viper.GetString(containerRuntime),
-		NetworkPlugin:     viper.GetString(networkPlugin),
-		ExtraOptions:      extraOptions,
+
+	// The docker daemon's flags and registry mirrors passed replace the ones kept in the minikube config.
+	daemonOpts, err := keptList(cfg.DockerOpt, dockerOpt, cmd.Flags().Changed(dockerOptFlag))
+	if err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+	for _, m := range registryMirror {
+		if err := util.ValidateRegistryMirror(m); err != nil {
+			console.Err("Invalid --%s: %s\n", registryMirrorFlag, err)
+			os.Exit(1)
+		}
+	}
+	mirrors, err := keptList(cfg.RegistryMirror, registryMirror, cmd.Flags().Changed(registryMirrorFlag))
+	if err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
 	}
 
-	fmt.Println("Moving files into cluster...")
-	if err := cluster.UpdateCluster(host.Driver, kubernetesConfig); err != nil {
-		glog.Errorln("Error updating cluster: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+	// The domain passed is kept in the minikube config for later starts.
+	domain := viper.GetString(dnsDomain)
+	if domain != "" {
+		if err := util.ValidateDNSDomain(domain); err != nil {
+			console.Err("Invalid --%s: %s\n", dnsDomain, err)
+			os.Exit(1)
+		}
+	}
+	if cmd.Flags().Changed(dnsDomain) {
+		if err := storeConfig(cfg.DNSDomain, domain); err != nil {
+			console.ErrLn(err)
+			os.Exit(1)
+		}
 	}
 
-	fmt.Println("Setting up certs...")
-	if err := cluster.SetupCerts(host.Driver, kubernetesConfig.APIServerName); err != nil {
-		glog.Errorln("Error configuring authentication: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+	// Sorting the gates keeps the cluster current when they are only passed in another order.
+	gates, err := util.NormalizeFeatureGates(viper.GetString(featureGates))
+	if err != nil {
+		console.Err("Invalid --%s: %s\n", featureGates, err)
+		os.Exit(1)
 	}
 
-	fmt.Println("Starting cluster components...")
+	// The apiserver doesn't start with an invalid audit policy, which would leave localkube restarting.
+	policy, err := readAuditPolicy(viper.GetString(auditPolicy))
+	if err != nil {
+		console.Err("Invalid --%s: %s\n", auditPolicy, err)
+		os.Exit(1)
+	}
+	// The kubelet only logs the static manifests it can't read, so they are checked beforehand.
+	manifestDirs, err := staticManifestsDirs(viper.GetString(staticManifests))
+	if err != nil {
+		console.Err("Invalid --%s: %s\n", staticManifests, err)
+		os.Exit(1)
+	}
+	if err := cluster.ValidateStaticManifests(manifestDirs); err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
 
-	if err := cluster.StartCluster(api, kubernetesConfig); err != nil {
-		glog.Errorln("Error starting cluster: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+	if err := cluster.AddRegistryCerts(registryCAs); err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+
+	// With --output=json, stdout only holds the events, the text goes to stderr. With --quiet,
+	// the progress text is left out, only the result is written.
+	out := console.OutWriter()
+	if viper.GetString(outputFormat) == "json" {
+		out = console.ErrWriter()
+	}
+	progress := out
+	if console.Quiet() {
+		progress = ioutil.Discard
+	}
+	var steps *pkgutil.StepReporter
+	switch viper.GetString(outputFormat) {
+	case "text":
+		steps = pkgutil.NewStepReporter(progress)
+	case "json":
+		steps = pkgutil.NewJSONStepReporter(console.OutWriter(), progress)
+	default:
+		console.Err("--%s must be text or json, not %q\n", outputFormat, viper.GetString(outputFormat))
+		os.Exit(1)
 	}
 
-	fmt.Println("Connecting to cluster...")
-	kubeHost, err := host.Driver.GetURL()
+	k8sVersion, err := cluster.ResolveKubernetesVersion(cfg.GetMachineName(), viper.GetString(kubernetesVersion), constants.KubernetesReleaseChannelURL, viper.GetBool(offline))
 	if err != nil {
-		glog.Errorln("Error connecting to cluster: ", err)
+		steps.Fail(err)
+		recordStartTiming(steps, err)
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+	// The versions can only be validated online, offline a version is valid if its localkube is cached.
+	if k8sVersion != constants.DefaultKubernetesVersion && !viper.GetBool(offline) {
+		validateK8sVersion(k8sVersion, console.ErrWriter())
 	}
-	kubeHost = strings.Replace(kubeHost, "tcp://", "https://", -1)
-	kubeHost = strings.Replace(kubeHost, ":2376", ":"+strconv.Itoa(constants.APIServerPort), -1)
 
-	fmt.Println("Setting up kubeconfig...")
-	// setup kubeconfig
+	// The downloads run alongside the other steps of start, so their output
+	// goes through the reporter to keep its lines whole.
+	config := cluster.MachineConfig{
+		MinikubeISO:             isoLocation(),
+		Memory:                  memoryMB,
+		CPUs:                    cpuCount,
+		DiskSize:                diskSizeMB,
+		VMDriver:                driver,
+		XhyveDiskDriver:         viper.GetString(xhyveDiskDriver),
+		DockerEnv:               dockerEnv,
+		DockerOpt:               daemonOpts,
+		InsecureRegistry:        insecureRegistries(serviceCIDR, registries),
+		RegistryMirror:          mirrors,
+		HostOnlyCIDR:            viper.GetString(hostOnlyCIDR),
+		HypervVirtualSwitch:     viper.GetString(hypervVirtualSwitch),
+		HypervUseExternalSwitch: viper.GetBool(hypervExternalSwitch),
+		KvmNetwork:              viper.GetString(kvmNetwork),
+		Downloader:              pkgutil.DefaultDownloader{Offline: viper.GetBool(offline), Progress: steps.Writer(pkgutil.StepISODownload)},
+		ForceRecreate:           viper.GetBool(forceRecreate),
+		RecreateOnConfigChange:  viper.GetBool(recreateOnChange),
+		KeepFailed:              viper.GetBool(keepFailed),
+		RetryPolicy:             retryPolicy(),
+		ExtraDisks:              viper.GetInt(extraDisks),
+		ExtraDiskSize:           extraDiskSizeMB,
+		NatForwards:             natForwards,
+		GPU:                     viper.GetBool(gpu),
+		SharedFolder:            sharedFolder,
+		Offline:                 viper.GetBool(offline),
+		Steps:                   steps,
+		RequestedSettings:       requestedSettings,
+	}
 
-	kubeConfigEnv := os.Getenv(constants.KubeconfigEnvVar)
-	var kubeConfigFile string
-	if kubeConfigEnv == "" {
-		kubeConfigFile = constants.KubeconfigPath
-	} else {
-		kubeConfigFile = filepath.SplitList(kubeConfigEnv)[0]
+	proxy := proxyConfig(serviceCIDR)
+	if viper.GetBool(dockerEnvProxy) {
+		config.Proxy = proxy
+		for _, p := range proxy.LoopbackProxies() {
+			console.Err("Warning: The proxy %s is on localhost, which the minikube VM can't reach. Pass --%s or --%s with an address of the host the VM can reach instead.\n", p, httpProxy, httpsProxy)
+		}
+	}
+	if viper.GetBool(printProxyConfig) {
+		if err := printProxy(console.OutWriter(), config); err != nil {
+			glog.Errorln("Error printing proxy config: ", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	kubeCfgSetup := &kubeconfig.KubeConfigSetup{
-		ClusterName:          cfg.GetMachineName(),
-		ClusterServerAddress: kubeHost,
-		ClientCertificate:    constants.MakeMiniPath("apiserver.crt"),
-		ClientKey:            constants.MakeMiniPath("apiserver.key"),
-		CertificateAuthority: constants.MakeMiniPath("ca.crt"),
-		KeepContext:          viper.GetBool(keepContext),
+	if viper.GetBool(dryRun) {
+		if err := printHostConfig(console.OutWriter(), api, config, viper.GetString(cfg.ImageRepository)); err != nil {
+			glog.Errorln("Error printing machine config: ", err)
+			os.Exit(1)
+		}
+		return
 	}
-	kubeCfgSetup.SetKubeConfigFile(kubeConfigFile)
 
-	if err := kubeconfig.SetupKubeConfig(kubeCfgSetup); err != nil {
-		glog.Errorln("Error setting up kubeconfig: ", err)
-		cmdUtil.MaybeReportErrorAndExit(err)
+	if config.Offline {
+		if err := cluster.CheckOffline(api, config, k8sVersion); err != nil {
+			console.ErrLn(err)
+			console.ErrLn("Run minikube start without --offline once to download them.")
+			os.Exit(1)
+		}
 	}
 
-	// start 9p server mount
-	if viper.GetBool(createMount) {
-		fmt.Printf("Setting up hostmount on %s...\n", viper.GetString(mountString))
+	storageProvisionerAddon, err := assets.Addons["storage-provisioner"].IsEnabled()
+	if err != nil {
+		glog.Errorln("Error checking whether the storage-provisioner addon is enabled: ", err)
+		os.Exit(1)
+	}
+	metricsAddon, err := assets.Addons["metrics"].IsEnabled()
+	if err != nil {
+		glog.Errorln("Error checking whether the metrics addon is enabled: ", err)
+		os.Exit(1)
+	}
+
+	kubernetesConfig := cluster.KubernetesConfig{
+		KubernetesVersion: k8sVersion,
+		APIServerName:     viper.GetString(apiServerName),
+		DNSDomain:         domain,
+		FeatureGates:      gpuFeatureGates(gates, config.GPU),
+		ContainerRuntime:  viper.GetString(containerRuntime),
+		NetworkPlugin:     viper.GetString(networkPlugin),
+		ExtraOptions:      extraConfig,
+		Offline:           config.Offline,
+		ImageRepository:   viper.GetString(cfg.ImageRepository),
+		APIServerSANs:     sans,
+		ServiceCIDR:       serviceCIDR,
+		PodCIDR:           podCIDR,
+		AuditPolicy:       policy,
 
-		path := os.Args[0]
-		mountDebugVal := 0
-		if glog.V(8) {
-			mountDebugVal = 1
+		StorageProvisionerAddon: storageProvisionerAddon,
+		MetricsAddon:            metricsAddon,
+	}
+	if kubernetes_versions.IsChannel(viper.GetString(kubernetesVersion)) {
+		kubernetesConfig.KubernetesChannel = viper.GetString(kubernetesVersion)
+	}
+	console.Phase("Starting Kubernetes %s (requested %q) with the %s driver, the ISO at %s and the %q container runtime",
+		k8sVersion, viper.GetString(kubernetesVersion), config.VMDriver, config.MinikubeISO, kubernetesConfig.ContainerRuntime)
+
+	if err := cluster.ValidateClusterCIDRs(kubernetesConfig, config); err != nil {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+	// The containers and images of the existing VM are in its runtime, so switching runtimes needs a new VM.
+	if c := cluster.ContainerRuntimeChanged(cfg.GetMachineName(), kubernetesConfig); c != nil {
+		console.Err("The cluster was started with --%s=%s, which can't be changed to %s on the existing VM.\n", c.Setting, c.Existing, c.Requested)
+		console.Err("Run \"minikube delete\" and start again to switch to %s, or start it with --%s=%s.\n", c.Requested, c.Setting, c.Existing)
+		os.Exit(1)
+	}
+	if cmd.Flags().Changed(containerRuntime) {
+		if err := storeConfig(cfg.ContainerRuntime, kubernetesConfig.ContainerRuntime); err != nil {
+			console.ErrLn(err)
+			os.Exit(1)
 		}
-		mountCmd := exec.Command(path, "mount", fmt.Sprintf("--v=%d", mountDebugVal), viper.GetString(mountString))
-		mountCmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
-		if glog.V(8) {
-			mountCmd.Stdout = os.Stdout
-			mountCmd.Stderr = os.Stderr
+	}
+	// Services and pods keep the IPs they were given, so moving them to other ranges removes the cluster's data.
+	cidrChanges := cluster.ClusterCIDRsChanged(cfg.GetMachineName(), kubernetesConfig)
+	fresh := viper.GetBool(forceFresh)
+	if len(cidrChanges) > 0 && !fresh {
+		for _, c := range cidrChanges {
+			console.Err("The cluster was started with --%s=%s, which can't be changed to %s.\n", c.Setting, c.Existing, c.Requested)
 		}
-		err = mountCmd.Start()
-		if err != nil {
-			glog.Errorf("Error running command minikube mount %s", err)
-			cmdUtil.MaybeReportErrorAndExit(err)
+		console.Err("The existing services and pods keep their IPs. Pass --%s to remove all of the cluster's data and start it afresh with the new ranges.\n", forceFresh)
+		os.Exit(1)
+	}
+	// Older versions may not read the data newer ones stored, so downgrading removes the cluster's data.
+	versionChange := cluster.CheckKubernetesVersionChange(cfg.GetMachineName(), k8sVersion)
+	switch versionChange.Change {
+	case cluster.VersionDowngrade:
+		if !viper.GetBool(forceDowngrade) {
+			console.Err("The cluster runs Kubernetes %s, which can't be downgraded to %s: the older version may not read the data the cluster stored.\n", versionChange.From, versionChange.To)
+			console.Err("Pass --%s to remove all of the cluster's data and start it afresh with %s, or start it with --%s=%s.\n", forceDowngrade, versionChange.To, kubernetesVersion, versionChange.From)
+			os.Exit(1)
+		}
+		steps.Println(fmt.Sprintf("Downgrading from Kubernetes %s to %s, which removes the cluster's data...", versionChange.From, versionChange.To))
+		fresh = true
+	case cluster.VersionIncomparable:
+		glog.Infof("Not comparing Kubernetes %s with %s, which the cluster runs", versionChange.To, versionChange.From)
+	}
+	upgrade := versionChange.Change == cluster.VersionUpgrade
+
+	// Expired certificates are removed, so that setting up the certs generates them
+	// again, and the kubeconfig entry is updated for them.
+	renewedCerts, err := cluster.RenewExpiredCerts(time.Now())
+	if err != nil {
+		glog.Warningln("Error renewing expired certificates:", err)
+	}
+	for _, c := range renewedCerts {
+		console.Err("The certificate %s expired on %s, generating it again.\n", c.Path, c.NotAfter.Format(time.RFC1123))
+	}
+	warnCertExpiry(console.ErrWriter())
+
+	// A healthy cluster started the same way is left running, starting it again would
+	// only restart it.
+	if !viper.GetBool(force) && len(renewedCerts) == 0 && !caChanged && !fresh {
+		current, reason := cluster.ClusterIsCurrent(api, config, kubernetesConfig)
+		if current {
+			host, err := api.Load(cfg.GetMachineName())
+			if err != nil {
+				exitStart(steps, err)
+			}
+			// Only the docker daemon is restarted for its changed engine options.
+			if err := cluster.UpdateEngineOptions(api, host, config); err != nil {
+				exitStart(steps, err)
+			}
+			// The kubelet picks up the changed static manifests without restarting.
+			if err := syncStaticManifests(host, manifestDirs, steps); err != nil {
+				exitStart(steps, err)
+			}
+			setUpKubeconfig(host, natForwards, steps)
+			recordStartTiming(steps, nil)
+			fmt.Fprintf(out, "The local Kubernetes %s cluster is already running, pass --%s to start it again.\n", k8sVersion, force)
+			return
+		}
+		glog.Infof("Starting the cluster, as %s", reason)
+	}
+
+	steps.Println(fmt.Sprintf("Starting local Kubernetes %s cluster...", k8sVersion))
+	localkubeConfig := kubernetesConfig
+
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration(waitTimeout))
+	defer cancel()
+	var host *host.Host
+	// Whether this start created the host, which is removed if the start fails.
+	var hostCreated bool
+	tasks := []pkgutil.Task{
+		{
+			Name: "preflight",
+			Run: func() error {
+				// The checks look at the local host, which doesn't run the VM with --remote-host.
+				if viper.GetBool(skipPreflightChecks) || clientType == machine.ClientTypeSSH {
+					return nil
+				}
+				steps.Start(pkgutil.StepPreflight)
+				checks := preflight.DriverChecks(driver, cluster.DetectVBoxManageCmd())
+				if config.GPU {
+					checks = append(checks, preflight.GPUChecks()...)
+				}
+				if err := preflight.Run(preflight.HostEnv{}, checks); err != nil {
+					return err
+				}
+				steps.Complete(pkgutil.StepPreflight)
+				return nil
+			},
+		},
+		{
+			Name: "iso",
+			Run: func() error {
+				if config.VMDriver == "none" {
+					return nil
+				}
+				steps.Start(pkgutil.StepISODownload)
+				if err := config.Downloader.CacheMinikubeISOFromURL(config.MinikubeISO); err != nil {
+					glog.Errorln("Error caching minikube ISO: ", err)
+					return err
+				}
+				steps.Complete(pkgutil.StepISODownload)
+				return nil
+			},
+		},
+		{
+			Name: "localkube",
+			Run: func() error {
+				steps.Start(pkgutil.StepLocalkubeDownload)
+				if err := cluster.CacheLocalkube(localkubeConfig, steps.Writer(pkgutil.StepLocalkubeDownload)); err != nil {
+					glog.Errorln("Error caching localkube: ", err)
+					return err
+				}
+				steps.Complete(pkgutil.StepLocalkubeDownload)
+				return nil
+			},
+		},
+		{
+			Name: "vm",
+			Deps: []string{"preflight", "iso"},
+			Run: func() error {
+				steps.Println("Starting VM...")
+				var err error
+				host, hostCreated, err = cluster.StartHostWithRetries(ctx, api, config, 5, 2*time.Second)
+				if _, ok := err.(cluster.ErrMachineMissing); ok {
+					cluster.RemoveFailedHost(api, config, hostCreated)
+					steps.Fail(err, taskSteps["vm"]...)
+					recordStartTiming(steps, err)
+					console.ErrLn(err)
+					os.Exit(1)
+				}
+				if err != nil && ctx.Err() != nil {
+					// StartHostWithRetries removed the host already, if this start created it.
+					steps.Fail(err, taskSteps["vm"]...)
+					recordStartTiming(steps, err)
+					console.Err("%s. Pass a longer --%s to wait for it longer.\n", err, waitTimeout)
+					os.Exit(1)
+				}
+				if err != nil {
+					glog.Errorln("Error starting host: ", err)
+					return err
+				}
+
+				ip, err := host.Driver.GetIP()
+				if err != nil {
+					glog.Errorln("Error starting host: ", err)
+					return err
+				}
+				if old, changed := cluster.APIServerIPChanged(ip); changed {
+					steps.Println(fmt.Sprintf("The VM's IP changed from %s to %s, regenerating its certificate and kubeconfig entry...", old, ip))
+				}
+				kubernetesConfig.NodeIP = ip
+				if config.Proxy.IsSet() {
+					if err := cluster.AddVMToNoProxy(api, host, ip); err != nil {
+						glog.Errorln("Error starting host: ", err)
+						return err
+					}
+				}
+				if devices := cluster.ExtraDiskDevices(driver, config.ExtraDisks); len(devices) > 0 {
+					steps.Println(fmt.Sprintf("Extra disks are attached to the VM as %s", strings.Join(devices, ", ")))
+				}
+				return nil
+			},
+		},
+		{
+			Name: "registry-certs",
+			Deps: []string{"vm"},
+			Run: func() error {
+				if config.VMDriver == "none" {
+					return nil
+				}
+				steps.Start(pkgutil.StepBootstrapping)
+				registries, changed, err := cluster.SyncRegistryCerts(host)
+				if err != nil {
+					glog.Errorln("Error copying registry CAs: ", err)
+					return err
+				}
+				if changed {
+					steps.Println(fmt.Sprintf("Restarted Docker to trust the CAs of the registries %s", strings.Join(registries, ", ")))
+				}
+				return nil
+			},
+		},
+		{
+			Name: "container-runtime",
+			Deps: []string{"vm"},
+			Run: func() error {
+				steps.Start(pkgutil.StepBootstrapping)
+				if err := cluster.ConfigureContainerRuntime(host, kubernetesConfig); err != nil {
+					glog.Errorln("Error configuring container runtime: ", err)
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			Name: "images",
+			// Copying the registry CAs may restart Docker, so the images are loaded after it, and
+			// the images are loaded into the container runtime once it is started.
+			Deps: []string{"vm", "registry-certs", "container-runtime"},
+			Run: func() error {
+				if config.VMDriver == "none" {
+					return nil
+				}
+				steps.Start(pkgutil.StepBootstrapping)
+				// The cluster can start without the cached images, they would only be pulled again.
+				if err := loadCachedImages(host.Driver, kubernetesConfig.ContainerRuntime, steps.Writer(pkgutil.StepBootstrapping)); err != nil {
+					console.Err("Error loading cached images: %s\n", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "update",
+			Deps: []string{"vm", "localkube"},
+			Run: func() error {
+				steps.Start(pkgutil.StepBootstrapping)
+				if upgrade {
+					steps.Println(fmt.Sprintf("Upgrading from Kubernetes %s to %s...", versionChange.From, versionChange.To))
+					if err := cluster.StopClusterComponents(api); err != nil {
+						glog.Errorln("Error upgrading cluster: ", err)
+						return err
+					}
+				}
+				steps.Println("Moving files into cluster...")
+				if err := cluster.UpdateCluster(host.Driver, kubernetesConfig); err != nil {
+					glog.Errorln("Error updating cluster: ", err)
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			Name: "certs",
+			Deps: []string{"vm"},
+			Run: func() error {
+				steps.Start(pkgutil.StepBootstrapping)
+				steps.Println("Setting up certs...")
+				if err := cluster.SetupCerts(host.Driver, kubernetesConfig); err != nil {
+					glog.Errorln("Error configuring authentication: ", err)
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			Name: "static-manifests",
+			Deps: []string{"vm"},
+			Run: func() error {
+				steps.Start(pkgutil.StepBootstrapping)
+				return syncStaticManifests(host, manifestDirs, steps)
+			},
+		},
+		{
+			Name: "cluster",
+			// The static pods are started along with the kubelet.
+			Deps: []string{"update", "certs", "images", "static-manifests"},
+			Run: func() error {
+				steps.Start(pkgutil.StepBootstrapping)
+				if fresh {
+					steps.Println("Removing the cluster's data...")
+					if err := cluster.ResetClusterData(api); err != nil {
+						glog.Errorln("Error starting cluster: ", err)
+						return err
+					}
+				}
+				if upgrade {
+					if err := cluster.RunMigrations(api, versionChange, steps.Writer(pkgutil.StepBootstrapping)); err != nil {
+						glog.Errorln("Error upgrading cluster: ", err)
+						return err
+					}
+				}
+				steps.Println("Starting cluster components...")
+				if err := cluster.StartCluster(api, kubernetesConfig); err != nil {
+					glog.Errorln("Error starting cluster: ", err)
+					return err
+				}
+				steps.Complete(pkgutil.StepBootstrapping)
+				return nil
+			},
+		},
+	}
+	durations, err := pkgutil.RunTasks(tasks)
+	// These are only shown with --alsologtostderr.
+	for _, t := range tasks {
+		if d, ok := durations[t.Name]; ok {
+			glog.Infof("Start step %s took %s", t.Name, d)
 		}
-		err = ioutil.WriteFile(filepath.Join(constants.GetMinipath(), constants.MountProcessFileName), []byte(strconv.Itoa(mountCmd.Process.Pid)), 0644)
+	}
+	if err != nil {
+		cluster.RemoveFailedHost(api, config, hostCreated)
+		taskErr, ok := err.(pkgutil.TaskError)
+		if !ok {
+			exitStart(steps, err)
+		}
+		if taskErr.Task == "preflight" {
+			steps.Fail(taskErr.Err, pkgutil.StepPreflight)
+			recordStartTiming(steps, taskErr.Err)
+			console.ErrLn(taskErr.Err)
+			os.Exit(1)
+		}
+		exitStart(steps, taskErr.Err, taskSteps[taskErr.Task]...)
+	}
+
+	kubeCfgSetup, kubeHost := setUpKubeconfig(host, natForwards, steps)
+	// An upgraded cluster is waited for anyway, to check that the new version works.
+	if viper.GetBool(wait) || upgrade {
+		waitForCluster(host, kubeCfgSetup.ClusterName, steps)
+	}
+	if !viper.GetBool(skipDNSCheck) && !config.Offline {
+		checkClusterDNS(kubeCfgSetup.ClusterName, kubernetesConfig, steps)
+	}
+
+	// start 9p server mount
+	if viper.GetBool(createMount) {
+		steps.Println(fmt.Sprintf("Setting up hostmount on %s...", viper.GetString(mountString)))
+		f, err := cluster.ParseMountString(viper.GetString(mountString))
 		if err != nil {
-			glog.Errorf("Error writing mount process pid to file: %s", err)
-			cmdUtil.MaybeReportErrorAndExit(err)
+			exitStart(steps, err)
+		}
+		if err := startMountDaemon(viper.GetString(mountString), f.HostPath, f.GuestPath, progress); err != nil {
+			glog.Errorf("Error running command minikube mount %s", err)
+			exitStart(steps, err)
 		}
 	}
 
+	recordStartTiming(steps, nil)
+	if err := cluster.SaveClusterConfig(cfg.GetMachineName(), config, kubernetesConfig); err != nil {
+		glog.Warningf("Error recording the cluster config: %s", err)
+	}
+
 	if kubeCfgSetup.KeepContext {
-		fmt.Printf("The local Kubernetes cluster has started. The kubectl context has not been altered, kubectl will require \"--context=%s\" to use the local Kubernetes cluster.\n",
+		fmt.Fprintf(out, "The local Kubernetes cluster has started. The kubectl context has not been altered, kubectl will require \"--context=%s\" to use the local Kubernetes cluster.\n",
 			kubeCfgSetup.ClusterName)
 	} else {
-		fmt.Println("Kubectl is now configured to use the cluster.")
+		fmt.Fprintln(out, "Kubectl is now configured to use the cluster.")
+	}
+	printKubectlProxyHint(console.ErrWriter(), cluster.ProxyFromEnv(os.Getenv), kubeHost)
+	if policy != "" {
+		printAuditLogHint(progress, k8sVersion)
+	}
+
+	// What this start uses is in the cache by now, so pruning it can't remove it.
+	if maxSize, ok := m[cfg.CacheMaxSize]; ok {
+		maybePruneCache(fmt.Sprintf("%v", maxSize), progress)
+	}
+
+	if viper.GetBool(cfg.AutoRestart) {
+		installAutoRestart(progress)
 	}
 
 	if config.VMDriver == "none" {
-		fmt.Println(`===================
+		fmt.Fprintln(console.ErrWriter(), `===================
 WARNING: IT IS RECOMMENDED NOT TO RUN THE NONE DRIVER ON PERSONAL WORKSTATIONS
 	The 'none' driver will run an insecure kubernetes apiserver as root that may leave the host vulnerable to CSRF attacks
 
@@ -255,19 +844,474 @@ This can also be done automatically by setting the env var CHANGE_MINIKUBE_NONE_
 	}
 }
 
-func validateK8sVersion(version string) {
+// waitForCluster waits for the apiserver and the kube-system pods of the cluster to be ready,
+// so that kubectl can use the cluster once the start returns.
+func waitForCluster(h *host.Host, context string, steps *pkgutil.StepReporter) {
+	steps.Start(pkgutil.StepWaiting)
+	components, err := cluster.ClusterComponents(cluster.LocalkubeBootstrapper)
+	if err != nil {
+		exitStart(steps, err, pkgutil.StepWaiting)
+	}
+	client, err := service.CoreClientForContext(context)
+	if err != nil {
+		exitStart(steps, err, pkgutil.StepWaiting)
+	}
+	if err := cluster.WaitForCluster(h, client, components, viper.GetDuration(waitTimeout), steps.Writer(pkgutil.StepWaiting)); err != nil {
+		steps.Fail(err, pkgutil.StepWaiting)
+		recordStartTiming(steps, err)
+		console.Err("%s. Pass a longer --%s to wait for it longer, or --%s=false not to wait for it.\n", err, waitTimeout, wait)
+		os.Exit(1)
+	}
+	steps.Complete(pkgutil.StepWaiting)
+}
+
+// staticManifestsDirs returns the directories of the static pod manifests to sync into the VM,
+// along with dir, which has to exist if it is set.
+func staticManifestsDirs(dir string) ([]string, error) {
+	if dir == "" {
+		return cluster.StaticManifestsDirs(""), nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return cluster.StaticManifestsDirs(dir), nil
+}
+
+// syncStaticManifests syncs the static pod manifests in dirs into the host's VM.
+func syncStaticManifests(h *host.Host, dirs []string, steps *pkgutil.StepReporter) error {
+	synced, err := cluster.SyncStaticManifests(h, dirs)
+	if err != nil {
+		glog.Errorln("Error syncing static manifests: ", err)
+		return err
+	}
+	if synced {
+		steps.Println("Synced the static pod manifests, \"minikube logs\" shows the kubelet's logs about them.")
+	}
+	return nil
+}
+
+// readAuditPolicy checks the audit policy at the path, returning its absolute path. There is no policy without a path.
+func readAuditPolicy(p string) (string, error) {
+	if p == "" {
+		return "", nil
+	}
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	if err := util.ValidateAuditPolicy(data); err != nil {
+		return "", errors.Wrap(err, p)
+	}
+	return p, nil
+}
+
+// printAuditLogHint points at the log the apiserver audits requests to.
+func printAuditLogHint(w io.Writer, k8sVersion string) {
+	fmt.Fprintf(w, "The apiserver audits requests to %s in the VM, run \"minikube logs --audit\" to see them.\n", constants.RemoteAuditLogPath)
+	// Kubernetes v1.7.0 introduced audit policies, older versions log every request.
+	if cluster.CompareKubernetesVersions(k8sVersion, "v1.7.0") == cluster.VersionUpgrade {
+		fmt.Fprintf(w, "Kubernetes %s logs every request, the rules of the audit policy apply from v1.7.0.\n", k8sVersion)
+	}
+}
+
+// checkClusterDNS warns when a pod can't look up the kubernetes service in the cluster's
+// domain, which the other pods resolve services in. It needs the kube-dns addon.
+func checkClusterDNS(context string, k cluster.KubernetesConfig, steps *pkgutil.StepReporter) {
+	if enabled, err := assets.Addons["kube-dns"].IsEnabled(); err != nil || !enabled {
+		return
+	}
+	domain := k.DNSDomain
+	if domain == "" {
+		domain = pkgutil.DefaultDNSDomain
+	}
+	steps.Println(fmt.Sprintf("Checking that pods resolve kubernetes.default.svc.%s...", domain))
+	image := images.WithRepository(constants.DNSCheckImage, k.ImageRepository)
+	if err := service.CheckClusterDNS(context, domain, image, dnsCheckTimeout); err != nil {
+		glog.Errorln("Error checking the cluster's DNS: ", err)
+		console.Err("Warning: %s. Pods may not resolve the cluster's services, pass --%s to skip this check.\n", err, skipDNSCheck)
+	}
+}
+
+// installAutoRestart sets the cluster up to be started again at login. Failing to doesn't fail the start.
+func installAutoRestart(out io.Writer) {
+	u, err := autorestart.NewUnit(cfg.GetMachineName())
+	if err == nil {
+		err = autorestart.Install(u)
+	}
+	if err != nil {
+		glog.Errorln("Error installing auto-restart unit: ", err)
+		fmt.Fprintln(out, "The cluster could not be set up to start at login: ", err)
+		return
+	}
+	fmt.Fprintf(out, "The cluster will be started at login, run \"minikube config set %s false\" to stop this.\n", cfg.AutoRestart)
+}
+
+func validateK8sVersion(version string, out io.Writer) {
 	validVersion, err := kubernetes_versions.IsValidLocalkubeVersion(version, constants.KubernetesVersionGCSURL)
 	if err != nil {
 		glog.Errorln("Error getting valid kubernetes versions", err)
 		os.Exit(1)
 	}
 	if !validVersion {
-		fmt.Println("Invalid Kubernetes version.")
-		kubernetes_versions.PrintKubernetesVersionsFromGCS(os.Stdout)
+		fmt.Fprintln(out, "Invalid Kubernetes version.")
+		kubernetes_versions.PrintKubernetesVersionsFromGCS(out)
 		os.Exit(1)
 	}
 }
 
+// taskSteps are the phases each task of the start is reported in.
+var taskSteps = map[string][]string{
+	"preflight":         {pkgutil.StepPreflight},
+	"iso":               {pkgutil.StepISODownload},
+	"localkube":         {pkgutil.StepLocalkubeDownload},
+	"vm":                {pkgutil.StepCreatingVM, pkgutil.StepProvisioning},
+	"images":            {pkgutil.StepBootstrapping},
+	"registry-certs":    {pkgutil.StepBootstrapping},
+	"update":            {pkgutil.StepBootstrapping},
+	"certs":             {pkgutil.StepBootstrapping},
+	"cluster":           {pkgutil.StepBootstrapping},
+	"container-runtime": {pkgutil.StepBootstrapping},
+	"static-manifests":  {pkgutil.StepBootstrapping},
+}
+
+// mergeExtraConfig validates the extra config passed to start, and merges it into the
+// one kept in the minikube config, which is updated for later starts.
+func mergeExtraConfig(passed util.ExtraOptionSlice) (util.ExtraOptionSlice, error) {
+	if err := passed.Validate(); err != nil {
+		return nil, err
+	}
+	m, err := cfg.ReadConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the stored extra config")
+	}
+	stored, err := util.ParseExtraOptions(cfg.StoredExtraConfig(m))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing the stored extra config, run minikube config unset %s to clear it", cfg.ExtraConfig)
+	}
+	merged := stored.Merge(passed)
+	if len(passed) > 0 {
+		if err := storeConfig(cfg.ExtraConfig, merged.Strings()); err != nil {
+			return nil, err
+		}
+	}
+	// The extra config of the environment is used, but not stored.
+	em, err := configCmd.EnvConfig(m)
+	if err != nil {
+		return nil, err
+	}
+	env, err := util.ParseExtraOptions(cfg.StoredExtraConfig(em))
+	if err != nil {
+		return nil, err
+	}
+	return env.Merge(passed), nil
+}
+
+// mergeInsecureRegistries validates the insecure registries passed to start, and adds them
+// to the ones kept in the minikube config, which is updated for later starts.
+func mergeInsecureRegistries(passed []string) ([]string, error) {
+	for _, r := range passed {
+		if err := util.ValidateInsecureRegistry(r); err != nil {
+			return nil, err
+		}
+	}
+	m, err := cfg.ReadConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the stored insecure registries")
+	}
+	stored := cfg.StoredInsecureRegistries(m)
+	merged := util.MergeInsecureRegistries(stored, passed)
+	if len(merged) > len(stored) {
+		if err := storeConfig(cfg.InsecureRegistry, merged); err != nil {
+			return nil, err
+		}
+	}
+	// The insecure registries of the environment are used, but not stored.
+	em, err := configCmd.EnvConfig(m)
+	if err != nil {
+		return nil, err
+	}
+	return util.MergeInsecureRegistries(cfg.StoredInsecureRegistries(em), passed), nil
+}
+
+// keptList returns the list passed to start if it was, storing it in the minikube config
+// for later starts, or the one stored under key otherwise.
+func keptList(key string, passed []string, changed bool) ([]string, error) {
+	if changed {
+		return passed, storeConfig(key, passed)
+	}
+	m, err := effectiveConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading the stored %s", key)
+	}
+	return cfg.StoredList(m, key), nil
+}
+
+// effectiveConfig returns the minikube config, with the values of the settings' environment
+// variables. Only the config itself is stored.
+func effectiveConfig() (cfg.MinikubeConfig, error) {
+	m, err := cfg.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return configCmd.EnvConfig(m)
+}
+
+// storeConfig sets the key of the minikube config to value, for later starts.
+func storeConfig(key string, value interface{}) error {
+	m, err := cfg.ReadConfig()
+	if err != nil {
+		return errors.Wrapf(err, "Error reading the config to store %s", key)
+	}
+	m[key] = value
+	if err := configCmd.WriteConfig(m); err != nil {
+		return errors.Wrapf(err, "Error storing %s in the config", key)
+	}
+	return nil
+}
+
+// exitStart reports err as ending the start in one of steps, and exits. With --output=json,
+// nobody is there to answer the error reporting prompt, which would also corrupt the events.
+func exitStart(steps *pkgutil.StepReporter, err error, inSteps ...string) {
+	steps.Fail(err, inSteps...)
+	recordStartTiming(steps, err)
+	if steps.JSON() {
+		console.ErrLn(err)
+		os.Exit(1)
+	}
+	cmdUtil.MaybeReportErrorAndExit(err)
+}
+
+// printHostConfig writes the configuration a new host would be created with as YAML, or the stored
+// one of the existing host along with the settings asked for which it ignores, and the images
+// pulled from the image repository instead.
+func printHostConfig(w io.Writer, api libmachine.API, config cluster.MachineConfig, imageRepository string) error {
+	stored, err := cluster.LoadStoredHostConfig(api, cfg.GetMachineName(), config)
+	if err != nil {
+		return err
+	}
+	var out []byte
+	if stored != nil {
+		fmt.Fprintf(w, "# Machine %q already exists, and keeps the settings it was created with.\n", stored.Name)
+		out, err = yaml.Marshal(stored)
+	} else {
+		var hc *cluster.HostConfig
+		if hc, err = cluster.NewHostConfig(config); err != nil {
+			return err
+		}
+		out, err = yaml.Marshal(hc)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling machine config")
+	}
+	if imageRepository != "" {
+		images, err := yaml.Marshal(struct {
+			ImageRepository string
+			Images          []cluster.ImageRewrite
+		}{imageRepository, cluster.ImageRepositoryRewrites(imageRepository)})
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling image repository")
+		}
+		out = append(out, images...)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// isoLocation returns the ISO URL, which is the default ISO under --iso-base-url when only that is set.
+func isoLocation() string {
+	location := viper.GetString(isoURL)
+	base := viper.GetString(cfg.ISOBaseURL)
+	if base == "" || location != constants.DefaultIsoUrl {
+		console.Phase("Using the ISO of --%s: %s", isoURL, location)
+		return location
+	}
+	location = strings.TrimSuffix(base, "/") + "/" + path.Base(constants.DefaultIsoUrl)
+	console.Phase("Using the default ISO under --%s: %s", cfg.ISOBaseURL, location)
+	return location
+}
+
+// proxyConfig returns the proxy of the environment, overridden by the proxy flags,
+// which doesn't proxy the service IPs of the range.
+func proxyConfig(serviceCIDR string) cluster.ProxyConfig {
+	p := cluster.ProxyFromEnv(os.Getenv)
+	if v := viper.GetString(httpProxy); v != "" {
+		p.HTTPProxy = v
+	}
+	if v := viper.GetString(httpsProxy); v != "" {
+		p.HTTPSProxy = v
+	}
+	if v := viper.GetString(noProxyList); v != "" {
+		p.NoProxy = v
+	}
+	return p.WithClusterNoProxy(serviceCIDR)
+}
+
+// clusterCIDR returns the range passed to the flag, if it was, otherwise the stored one.
+func clusterCIDR(flags *pflag.FlagSet, name, stored string) (string, error) {
+	if !flags.Changed(name) {
+		return stored, nil
+	}
+	ipNet, err := util.ParseClusterCIDR(viper.GetString(name))
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid --%s", name)
+	}
+	return ipNet.String(), nil
+}
+
+// printProxy prints the proxy settings and the environment of the Docker daemon they end up in.
+func printProxy(w io.Writer, config cluster.MachineConfig) error {
+	hc, err := cluster.NewHostConfig(config)
+	if err != nil {
+		return err
+	}
+	p := config.Proxy
+	if !p.IsSet() {
+		fmt.Fprintf(w, "No proxy is passed to the Docker daemon, set HTTP_PROXY or HTTPS_PROXY or pass --%s or --%s.\n", httpProxy, httpsProxy)
+	} else {
+		fmt.Fprintf(w, "HTTP_PROXY:  %s\nHTTPS_PROXY: %s\nNO_PROXY:    %s\n", p.HTTPProxy, p.HTTPSProxy, p.NoProxy)
+		fmt.Fprintln(w, "The IP of the minikube VM is added to NO_PROXY once it has started.")
+	}
+	fmt.Fprintln(w, "Docker daemon environment:")
+	for _, e := range hc.EngineOptions.Env {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+	return nil
+}
+
+// printKubectlProxyHint warns when kubectl would reach the apiserver through the
+// proxy of the environment, which kubectl reads too.
+func printKubectlProxyHint(w io.Writer, env cluster.ProxyConfig, kubeHost string) {
+	u, err := url.Parse(kubeHost)
+	if err != nil || !env.IsSet() {
+		return
+	}
+	vmIP := u.Hostname()
+	if ip := net.ParseIP(vmIP); ip == nil || ip.IsLoopback() || env.Bypasses(vmIP) {
+		return
+	}
+	fmt.Fprintf(w, "Warning: NO_PROXY doesn't include the minikube VM, so kubectl will reach it through the proxy. Run \"export NO_PROXY=$NO_PROXY,%s\" to reach it directly.\n", vmIP)
+}
+
+// printDrivers prints the drivers available on this OS, and whether the program each needs was found.
+func printDrivers(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Driver", "Requires", "Found", "Needs root setup"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetCenterSeparator("|")
+	for _, d := range machine.Drivers() {
+		table.Append([]string{d.Name, d.Binary, strconv.FormatBool(d.BinaryFound()), strconv.FormatBool(d.Privileged)})
+	}
+	table.Render()
+}
+
+// applyClusterConfig sets the stored settings which weren't given, as flags or in the
+// environment, to the values the cluster was last started with, which take precedence over
+// the minikube config. It returns the settings which were given, as flags, in the environment
+// or in the minikube config, rather than left to their stored values or defaults.
+func applyClusterConfig(api libmachine.API, flags *pflag.FlagSet, m cfg.MinikubeConfig) map[string]bool {
+	stored, err := cluster.LoadClusterConfig(api, cfg.GetMachineName())
+	if err != nil {
+		glog.Warningf("Not using the config the cluster was last started with: %s", err)
+		stored = nil
+	}
+	sources := cluster.SettingSources{Flags: map[string]string{}, Env: cluster.SettingsEnv(os.Getenv), Cluster: stored, Config: m}
+	flags.Visit(func(f *pflag.Flag) {
+		sources.Flags[f.Name] = f.Value.String()
+	})
+	requested := map[string]bool{}
+	for _, s := range sources.Resolve() {
+		switch s.Source {
+		case cluster.SourceProfile:
+			glog.Infof("Using --%s=%s, which the cluster was last started with", s.Name, s.Value)
+			viper.Set(s.Name, s.Value)
+		case cluster.SourceFlag, cluster.SourceEnv, cluster.SourceConfig:
+			requested[s.Name] = true
+		}
+	}
+	return requested
+}
+
+// driverSetting returns the value of a setting which can be overridden per driver.
+// A flag takes precedence over the driver-specific config value, which takes
+// precedence over the generic config value and then the flag's default.
+func driverSetting(flags *pflag.FlagSet, m cfg.MinikubeConfig, name, driver string) string {
+	if flags.Changed(name) {
+		return viper.GetString(name)
+	}
+	if v, ok := m[cfg.DriverKey(name, driver)]; ok {
+		return fmt.Sprintf("%v", v)
+	}
+	return viper.GetString(name)
+}
+
+// insecureRegistries returns the registries the docker daemon is allowed to pull from without TLS:
+// the given ones, and the services of the cluster, in localkube's default range on a cluster
+// started without one, as the first start of a cluster is.
+func insecureRegistries(serviceCIDR string, registries []string) []string {
+	if serviceCIDR == "" {
+		serviceCIDR = pkgutil.DefaultServiceCIDR
+	}
+	return pkgutil.MergeInsecureRegistries([]string{serviceCIDR}, registries)
+}
+
+// gpuFeatureGates enables the Accelerators feature gate the kubelet needs to schedule GPU pods.
+func gpuFeatureGates(gates string, gpu bool) string {
+	if !gpu || strings.Contains(gates, "Accelerators=") {
+		return gates
+	}
+	if gates == "" {
+		return "Accelerators=true"
+	}
+	return gates + ",Accelerators=true"
+}
+
+// nativeSharedFolder returns the folder to share natively with the VM. That is the
+// --mount-string folder when it is passed without --mount, which runs the 9p server instead.
+func nativeSharedFolder(flags *pflag.FlagSet, driver string) (cluster.SharedFolder, error) {
+	if !flags.Changed(mountString) || viper.GetBool(createMount) {
+		return cluster.SharedFolder{}, nil
+	}
+	if !provision.SupportsSharedFolder(driver) {
+		console.Err("Warning: the %s driver can't share folders natively, pass --%s to mount %s\n", driver, createMount, viper.GetString(mountString))
+		return cluster.SharedFolder{}, nil
+	}
+	f, err := cluster.ParseMountString(viper.GetString(mountString))
+	if err != nil {
+		return f, err
+	}
+	if _, err := os.Stat(f.HostPath); err != nil {
+		return f, errors.Wrapf(err, "Error sharing %s", f.HostPath)
+	}
+	return f, nil
+}
+
+// validateExtraDisks checks the number of extra disks asked for, returning their size in MB.
+func validateExtraDisks(n int, size string) (int, error) {
+	if n < 0 || n > constants.MaximumExtraDisks {
+		return 0, fmt.Errorf("Extra disks %d is invalid, at most %d extra disks can be attached", n, constants.MaximumExtraDisks)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	sizeMB := calculateDiskSizeInMB(size)
+	if sizeMB < 1 {
+		return 0, fmt.Errorf("Extra disk size %q is invalid", size)
+	}
+	return sizeMB, nil
+}
+
 func calculateDiskSizeInMB(humanReadableDiskSize string) int {
 	diskSize, err := units.FromHumanSize(humanReadableDiskSize)
 	if err != nil {
@@ -276,33 +1320,137 @@ func calculateDiskSizeInMB(humanReadableDiskSize string) int {
 	return int(diskSize / units.MB)
 }
 
+// recordStartTiming records how long the start and each of its steps took, for
+// minikube status and minikube profile-timings.
+func recordStartTiming(steps *pkgutil.StepReporter, err error) {
+	t := cluster.Timing{Command: cluster.TimingStart, Duration: steps.Elapsed(), Durations: steps.Durations()}
+	t.Time = time.Now().Add(-t.Duration)
+	if err != nil {
+		t.Error = err.Error()
+	}
+	cluster.RecordTiming(cfg.GetMachineName(), t)
+}
+
+// setUpKubeconfig points minikube's kubeconfig context at the apiserver of the host.
+func setUpKubeconfig(host *host.Host, natForwards []cluster.PortForward, steps *pkgutil.StepReporter) (*kubeconfig.KubeConfigSetup, string) {
+	steps.Println("Connecting to cluster...")
+	kubeHost, err := apiServerURL(host)
+	if err != nil {
+		glog.Errorln("Error connecting to cluster: ", err)
+	}
+	if viper.GetBool(natForwardKubeconfig) {
+		if port, ok := cluster.ForwardedPort(natForwards, constants.APIServerPort); ok {
+			kubeHost = fmt.Sprintf("https://127.0.0.1:%d", port)
+		} else {
+			console.Err("Warning: --%s is set but --nat-forward doesn't forward the apiserver port %d, using %s\n", natForwardKubeconfig, constants.APIServerPort, kubeHost)
+		}
+	}
+
+	steps.Start(pkgutil.StepKubeconfig)
+	steps.Println("Setting up kubeconfig...")
+	kubeCfgSetup := newKubeConfigSetup(kubeHost)
+	kubeCfgSetup.KeepContext = viper.GetBool(keepContext)
+	kubeCfgSetup.EmbedCerts = viper.GetBool(cfg.EmbedCerts)
+	kubeCfgSetup.SetKubeConfigFile(kubeConfigPath())
+
+	if err := kubeconfig.SetupKubeConfig(kubeCfgSetup); err != nil {
+		glog.Errorln("Error setting up kubeconfig: ", err)
+		exitStart(steps, err, pkgutil.StepKubeconfig)
+	}
+	steps.Complete(pkgutil.StepKubeconfig)
+	return kubeCfgSetup, kubeHost
+}
+
+// apiServerURL returns the URL of the apiserver of the host, which is on the
+// VM's IP like its Docker daemon.
+func apiServerURL(h *host.Host) (string, error) {
+	url, err := h.Driver.GetURL()
+	url = strings.Replace(url, "tcp://", "https://", -1)
+	url = strings.Replace(url, ":2376", ":"+strconv.Itoa(constants.APIServerPort), -1)
+	return url, err
+}
+
+// newKubeConfigSetup returns the kubeconfig entry of the cluster with the apiserver at kubeHost.
+func newKubeConfigSetup(kubeHost string) *kubeconfig.KubeConfigSetup {
+	return &kubeconfig.KubeConfigSetup{
+		ClusterName:          cfg.GetMachineName(),
+		ClusterServerAddress: kubeHost,
+		ClientCertificate:    cluster.CertPath("apiserver.crt"),
+		ClientKey:            cluster.CertPath("apiserver.key"),
+		CertificateAuthority: cluster.CertPath("ca.crt"),
+	}
+}
+
+// kubeConfigPath returns the kubeconfig file minikube sets its context up in.
+func kubeConfigPath() string {
+	return kubeconfig.DefaultPath()
+}
+
 func init() {
 	startCmd.Flags().Bool(keepContext, constants.DefaultKeepContext, "This will keep the existing kubectl context and will create a minikube context.")
+	startCmd.Flags().Bool(cfg.EmbedCerts, false, "Inline the certificates and key in the kubeconfig rather than referencing their files in the minikube directory")
 	startCmd.Flags().Bool(createMount, false, "This will start the mount daemon and automatically mount files into minikube")
-	startCmd.Flags().String(mountString, constants.DefaultMountDir+":"+constants.DefaultMountEndpoint, "The argument to pass the minikube mount command on start")
+	startCmd.Flags().String(mountString, constants.DefaultMountDir+":"+constants.DefaultMountEndpoint, "The argument to pass the minikube mount command on start. Without --mount, the folder is shared natively with the virtualbox and kvm2 drivers when the VM is created")
+	startCmd.Flags().Bool(dryRun, false, "Print the configuration the minikube VM would be created with, or the stored one of an existing VM, and exit without creating or starting it")
+	startCmd.Flags().Bool(skipPreflightChecks, false, "Skip the checks that the host can run the minikube VM, such as hardware virtualization and free disk space")
+	startCmd.Flags().Bool(forceRecreate, false, "Delete and recreate the minikube VM if its stored config is corrupt or the VM was deleted outside of minikube")
+	startCmd.Flags().Bool(cfg.AutoRestart, false, "Start the cluster again when you log in after the host rebooted, with the cached ISO and localkube")
+	startCmd.Flags().Bool(force, false, "Start the cluster again even if it is already running and healthy")
+	startCmd.Flags().Bool(offline, false, "Only use the cached ISO and localkube, failing with the files which are missing rather than downloading them")
+	startCmd.Flags().Duration(waitTimeout, constants.DefaultWaitTimeout, "How long to wait for the minikube VM to be created and started, and then for the cluster to be ready, before giving up. A VM created by a start which timed out is removed")
+	startCmd.Flags().String(httpProxy, "", "The HTTP proxy the Docker daemon pulls images through. Defaults to HTTP_PROXY")
+	startCmd.Flags().String(httpsProxy, "", "The HTTPS proxy the Docker daemon pulls images through. Defaults to HTTPS_PROXY")
+	startCmd.Flags().String(noProxyList, "", "The hosts the Docker daemon reaches without the proxy. Defaults to NO_PROXY, the minikube VM and the service IPs are always added")
+	startCmd.Flags().Bool(dockerEnvProxy, true, "Pass the proxy of the environment, or of --http-proxy, --https-proxy and --no-proxy, to the Docker daemon when the minikube VM is created. --docker-env takes precedence")
+	startCmd.Flags().Bool(printProxyConfig, false, "Print the proxy settings passed to the Docker daemon, and exit without creating or starting the minikube VM")
+	startCmd.Flags().Bool(recreateOnChange, false, "Delete and recreate the minikube VM if its memory, cpus, disk size, ISO, extra disks or shared folder differ from the ones asked for")
+	startCmd.Flags().Bool(keepFailed, false, "Keep the VM this start created if the start fails before the cluster is bootstrapped, rather than removing it")
 	startCmd.Flags().String(isoURL, constants.DefaultIsoUrl, "Location of the minikube iso")
-	startCmd.Flags().String(vmDriver, constants.DefaultVMDriver, fmt.Sprintf("VM driver is one of: %v", constants.SupportedVMDrivers))
+	startCmd.Flags().String(cfg.ISOBaseURL, "", "A mirror of the minikube iso's storage to download it from, e.g. https://mirror.example.com/minikube/iso. --iso-url takes precedence")
+	startCmd.Flags().String(cfg.ImageRepository, "", "A mirror of gcr.io/google_containers to pull the pause image and the addons' images from, e.g. registry.example.com:5000/google_containers")
+	startCmd.Flags().String(vmDriver, constants.DefaultVMDriver, fmt.Sprintf("VM driver is one of: %v, or help to list the drivers and their requirements", constants.SupportedVMDrivers))
 	startCmd.Flags().Int(memory, constants.DefaultMemory, "Amount of RAM allocated to the minikube VM")
 	startCmd.Flags().Int(cpus, constants.DefaultCPUS, "Number of CPUs allocated to the minikube VM")
 	startCmd.Flags().String(humanReadableDiskSize, constants.DefaultDiskSize, "Disk size allocated to the minikube VM (format: <number>[<unit>], where unit = b, k, m or g)")
-	startCmd.Flags().String(hostOnlyCIDR, "192.168.99.1/24", "The CIDR to be used for the minikube VM (only supported with Virtualbox driver)")
+	startCmd.Flags().Int(extraDisks, 0, "Number of extra empty disks attached to the minikube VM when it is created (only supported with virtualbox and kvm2 drivers)")
+	startCmd.Flags().String(extraDiskSize, constants.DefaultExtraDiskSize, "Size of each extra disk (format: <number>[<unit>], where unit = b, k, m or g)")
+	startCmd.Flags().String(hostOnlyCIDR, constants.DefaultHostOnlyCIDR, "The CIDR to be used for the minikube VM (only supported with Virtualbox driver)")
+	startCmd.Flags().StringSliceVar(&natForward, "nat-forward", nil, "Ports on 127.0.0.1 of the host to forward to the minikube VM through its NAT network (format: <host port>:<guest port>[/<tcp|udp>]) (only supported with Virtualbox driver)")
+	startCmd.Flags().Bool(natForwardKubeconfig, false, "Point the kubeconfig at the apiserver port forwarded with --nat-forward, rather than the host-only IP of the VM (only supported with Virtualbox driver)")
 	startCmd.Flags().String(hypervVirtualSwitch, "", "The hyperv virtual switch name. Defaults to first found. (only supported with HyperV driver)")
-	startCmd.Flags().String(kvmNetwork, "default", "The KVM network name. (only supported with KVM driver)")
-	startCmd.Flags().String(xhyveDiskDriver, "ahci-hd", "The disk driver to use [ahci-hd|virtio-blk] (only supported with xhyve driver)")
+	startCmd.Flags().Bool(hypervExternalSwitch, false, "Use an external virtual switch when --hyperv-virtual-switch isn't set, creating one on the active network adapter if there is none. (only supported with HyperV driver)")
+	startCmd.Flags().Bool(gpu, false, "Pass the host's NVIDIA GPUs through to the minikube VM with VFIO (only supported with kvm2 driver)")
+	startCmd.Flags().String(kvmNetwork, constants.DefaultKvmNetwork, "The KVM network name. (only supported with KVM driver)")
+	startCmd.Flags().String(xhyveDiskDriver, constants.DefaultXhyveDisk, "The disk driver to use [ahci-hd|virtio-blk] (only supported with xhyve driver)")
 	startCmd.Flags().StringArrayVar(&dockerEnv, "docker-env", nil, "Environment variables to pass to the Docker daemon. (format: key=value)")
-	startCmd.Flags().StringArrayVar(&dockerOpt, "docker-opt", nil, "Specify arbitrary flags to pass to the Docker daemon. (format: key=value)")
+	startCmd.Flags().StringArrayVar(&dockerOpt, dockerOptFlag, nil, "Specify arbitrary flags to pass to the Docker daemon, such as storage-driver=overlay2. (format: key=value) Replace the ones kept in the minikube config for later starts")
 	startCmd.Flags().String(apiServerName, constants.APIServerName, "The apiserver name which is used in the generated certificate for localkube/kubernetes.  This can be used if you want to make the apiserver available from outside the machine")
-	startCmd.Flags().String(dnsDomain, "", "The cluster dns domain name used in the kubernetes cluster")
-	startCmd.Flags().StringSliceVar(&insecureRegistry, "insecure-registry", nil, "Insecure Docker registries to pass to the Docker daemon")
-	startCmd.Flags().StringSliceVar(&registryMirror, "registry-mirror", nil, "Registry mirrors to pass to the Docker daemon")
-	startCmd.Flags().String(kubernetesVersion, constants.DefaultKubernetesVersion, "The kubernetes version that the minikube VM will use (ex: v1.2.3) \n OR a URI which contains a localkube binary (ex: https://storage.googleapis.com/minikube/k8sReleases/v1.3.0/localkube-linux-amd64)")
-	startCmd.Flags().String(containerRuntime, "", "The container runtime to be used")
+	startCmd.Flags().String(dnsDomain, "", "The cluster dns domain name used in the kubernetes cluster, by the kubelet, kube-dns and the apiserver certificate. Kept in the minikube config for later starts")
+	startCmd.Flags().Bool(forceDowngrade, false, "Remove all of the cluster's data, such as its services and pods, to start it with an older --kubernetes-version than it runs")
+	startCmd.Flags().String(auditPolicy, "", "The path of an audit policy file, to audit the requests to the apiserver as it says. The log is shown by minikube logs --audit")
+	startCmd.Flags().String(staticManifests, "", "A directory of pod manifests which the kubelet runs as static pods, along with those in ~/.minikube/files/etc/kubernetes/manifests. Synced on every start")
+	startCmd.Flags().Bool(wait, true, "Wait for the apiserver to be healthy and the kube-system pods to be ready before returning")
+	startCmd.Flags().Bool(skipDNSCheck, false, "Skip checking that a pod can look up the kubernetes service in the cluster's dns domain once the cluster started")
+	startCmd.Flags().String(serviceClusterIPRange, pkgutil.DefaultServiceCIDR, "The range of the service IPs, which must not overlap the network the host reaches the VM on. Kept by later starts")
+	startCmd.Flags().String(podNetworkCIDR, pkgutil.DefaultPodCIDR, "The range of the pod IPs, which must not overlap the network the host reaches the VM on. Kept by later starts")
+	startCmd.Flags().Bool(forceFresh, false, "Remove all of the cluster's data, such as its services and pods, and start it afresh. Needed to change the --service-cluster-ip-range or --pod-network-cidr of an existing cluster")
+	startCmd.Flags().StringSliceVar(&apiServerNames, "apiserver-names", nil, "Extra DNS names for the apiserver certificate, such as the name of a bastion the apiserver is forwarded through. Kept by later starts")
+	startCmd.Flags().StringSliceVar(&apiServerIPs, "apiserver-ips", nil, "Extra IPs for the apiserver certificate. Kept by later starts")
+	startCmd.Flags().StringVar(&caCertPath, "ca-cert", "", "A CA certificate to sign the cluster's certificates with, instead of the one minikube generates. Needs --ca-key")
+	startCmd.Flags().StringVar(&caKeyPath, "ca-key", "", "The private key of --ca-cert")
+	startCmd.Flags().StringSliceVar(&insecureRegistry, insecureRegistryFlag, nil, "Insecure Docker registries for the Docker daemon to trust without TLS, as host:port or CIDR, along with the service CIDR. Kept in the minikube config for later starts")
+	startCmd.Flags().StringSliceVar(&registryCAs, "insecure-registry-ca", nil, "The CA of a Docker registry for the Docker daemon to trust, as <registry>=<CA file>. Kept in ~/.minikube/files/certs for later starts")
+	startCmd.Flags().StringSliceVar(&registryMirror, registryMirrorFlag, nil, "Registry mirrors to pass to the Docker daemon, as http or https URLs. Replace the ones kept in the minikube config for later starts")
+	startCmd.Flags().String(kubernetesVersion, constants.DefaultKubernetesVersion, "The kubernetes version that the minikube VM will use (ex: v1.2.3), the stable or latest release \n OR a URI which contains a localkube binary (ex: https://storage.googleapis.com/minikube/k8sReleases/v1.3.0/localkube-linux-amd64)")
+	startCmd.Flags().String(containerRuntime, "", "The container runtime the cluster's containers run with: docker, containerd, cri-o or rkt. Docker if empty. Kept in the minikube config for later starts, changing it needs a new VM")
 	startCmd.Flags().String(networkPlugin, "", "The name of the network plugin")
-	startCmd.Flags().String(featureGates, "", "A set of key=value pairs that describe feature gates for alpha/experimental features.")
+	startCmd.Flags().String(featureGates, "", "A set of key=value pairs that describe feature gates for alpha/experimental features, passed to every Kubernetes component. An --extra-config value for a component's FeatureGates takes precedence.")
 	startCmd.Flags().Var(&extraOptions, "extra-config",
 		`A set of key=value pairs that describe configuration that may be passed to different components.
 		The key should be '.' separated, and the first part before the dot is the component to apply the configuration to.
-		Valid components are: kubelet, apiserver, controller-manager, etcd, proxy, scheduler.`)
+		Valid components are: kubelet, apiserver, controller-manager, etcd, proxy, scheduler.
+		It is kept for later starts, which merge the values passed to them into it. Run minikube config unset extra-config to clear it.`)
+	startCmd.Flags().String(outputFormat, "text", "The format of the progress of the start: text, or json for one JSON event per line on stdout for each phase started, completed or failed and each download percent, with the text on stderr")
 	viper.BindPFlags(startCmd.Flags())
 	RootCmd.AddCommand(startCmd)
 }
@@ -0,0 +1,242 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	pkgutil "k8s.io/minikube/pkg/util"
+)
+
+func TestDriverSetting(t *testing.T) {
+	var tests = []struct {
+		description string
+		flag        string
+		config      string
+		expected    string
+	}{
+		{
+			description: "constant default",
+			config:      `{}`,
+			expected:    "2048",
+		},
+		{
+			description: "generic config",
+			config:      `{ "memory": 3072 }`,
+			expected:    "3072",
+		},
+		{
+			description: "driver config overrides generic config",
+			config:      `{ "memory": 3072, "memory.hyperkit": 4096 }`,
+			expected:    "4096",
+		},
+		{
+			description: "other driver config is ignored",
+			config:      `{ "memory": 3072, "memory.virtualbox": 4096 }`,
+			expected:    "3072",
+		},
+		{
+			description: "flag overrides driver config",
+			flag:        "1024",
+			config:      `{ "memory": 3072, "memory.hyperkit": 4096 }`,
+			expected:    "1024",
+		},
+	}
+	restore := hideEnv(t)
+	defer restore(t)
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			defer viper.Reset()
+			viper.BindPFlags(startCmd.Flags())
+			f := startCmd.Flags().Lookup(memory)
+			defer func() {
+				f.Value.Set(f.DefValue)
+				f.Changed = false
+			}()
+			if test.flag != "" {
+				startCmd.Flags().Set(memory, test.flag)
+			}
+			if err := initTestConfig(test.config); err != nil {
+				t.Fatalf("Error reading config: %s", err)
+			}
+			m := config.MinikubeConfig{}
+			if err := json.Unmarshal([]byte(test.config), &m); err != nil {
+				t.Fatalf("Error decoding config: %s", err)
+			}
+			if actual := driverSetting(startCmd.Flags(), m, memory, "hyperkit"); actual != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateExtraDisks(t *testing.T) {
+	var tests = []struct {
+		description string
+		disks       int
+		size        string
+		expected    int
+		err         bool
+	}{
+		{
+			description: "no extra disks",
+			size:        "10g",
+			expected:    0,
+		},
+		{
+			description: "extra disks",
+			disks:       2,
+			size:        "10g",
+			expected:    10000,
+		},
+		{
+			description: "negative number of disks",
+			disks:       -1,
+			size:        "10g",
+			err:         true,
+		},
+		{
+			description: "too many disks",
+			disks:       9,
+			size:        "10g",
+			err:         true,
+		},
+		{
+			description: "invalid size",
+			disks:       1,
+			size:        "big",
+			err:         true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			actual, err := validateExtraDisks(test.disks, test.size)
+			if err != nil && !test.err {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Error("Expected an error, got none")
+			}
+			if actual != test.expected {
+				t.Errorf("Expected %d MB, got %d MB", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGPUFeatureGates(t *testing.T) {
+	var tests = []struct {
+		description string
+		gates       string
+		gpu         bool
+		expected    string
+	}{
+		{
+			description: "no gpu",
+			gates:       "Foo=true",
+			expected:    "Foo=true",
+		},
+		{
+			description: "gpu",
+			gpu:         true,
+			expected:    "Accelerators=true",
+		},
+		{
+			description: "gpu with other gates",
+			gates:       "Foo=true",
+			gpu:         true,
+			expected:    "Foo=true,Accelerators=true",
+		},
+		{
+			description: "gpu with accelerators already set",
+			gates:       "Accelerators=false",
+			gpu:         true,
+			expected:    "Accelerators=false",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := gpuFeatureGates(test.gates, test.gpu); actual != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInsecureRegistries(t *testing.T) {
+	var tests = []struct {
+		description string
+		serviceCIDR string
+		registries  []string
+		expected    []string
+	}{
+		{
+			description: "first start",
+			expected:    []string{pkgutil.DefaultServiceCIDR},
+		},
+		{
+			description: "stored range",
+			serviceCIDR: "10.96.0.0/12",
+			registries:  []string{"registry.lan:5000"},
+			expected:    []string{"10.96.0.0/12", "registry.lan:5000"},
+		},
+		{
+			description: "first start with registries",
+			registries:  []string{"registry.lan:5000"},
+			expected:    []string{pkgutil.DefaultServiceCIDR, "registry.lan:5000"},
+		},
+	}
+	for _, test := range tests {
+		if actual := insecureRegistries(test.serviceCIDR, test.registries); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.description, test.expected, actual)
+		}
+	}
+}
+
+func TestKeptList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minikube-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(path string) { constants.ConfigFile = path }(constants.ConfigFile)
+	constants.ConfigFile = filepath.Join(dir, "config.json")
+
+	opts := []string{"storage-driver=overlay2", "log-opt=max-size=50m"}
+	if kept, err := keptList(config.DockerOpt, opts, true); err != nil || !reflect.DeepEqual(kept, opts) {
+		t.Fatalf("Expected the passed options %v, got %v, %v", opts, kept, err)
+	}
+	// The stored options are read back from JSON by a later start.
+	if kept, err := keptList(config.DockerOpt, nil, false); err != nil || !reflect.DeepEqual(kept, opts) {
+		t.Errorf("Expected the stored options %v, got %v, %v", opts, kept, err)
+	}
+	if kept, err := keptList(config.RegistryMirror, nil, false); err != nil || len(kept) != 0 {
+		t.Errorf("Expected no registry mirrors, got %v, %v", kept, err)
+	}
+}
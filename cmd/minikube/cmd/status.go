@@ -17,31 +17,224 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"text/template"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	cmdUtil "k8s.io/minikube/cmd/util"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
-var statusFormat string
+var (
+	statusFormat string
+	statusOutput string
+)
+
+// The states of the components in the status.
+const (
+	StateRunning       = "Running"
+	StateStopped       = "Stopped"
+	StatePaused        = "Paused"
+	StateNonexistent   = "Nonexistent"
+	StateUnreachable   = "Unreachable"
+	StateConfigured    = "Configured"
+	StateMisconfigured = "Misconfigured"
+	StateUnknown       = "Unknown"
+)
+
+// The exit codes of minikube status, by the first component, in this order, that is not running or configured.
+// 1 is left to the errors getting the status.
+const (
+	statusExitHostStopped             = 2
+	statusExitKubeletStopped          = 3
+	statusExitAPIServerUnreachable    = 4
+	statusExitKubeconfigMisconfigured = 5
+	statusExitNonexistent             = 6
+)
 
 type Status struct {
-	MinikubeStatus  string
-	LocalkubeStatus string
+	// Host is Running, Stopped or Nonexistent.
+	Host string `json:"host"`
+	// Kubelet is the state of localkube, which runs the kubelet: Running, Paused, Stopped or Nonexistent.
+	Kubelet string `json:"kubelet"`
+	// APIServer is Running, Unreachable while localkube runs but the apiserver fails its health check,
+	// Stopped or Nonexistent.
+	APIServer string `json:"apiServer"`
+	// Kubeconfig is Configured if the minikube context points at the apiserver of the VM, Misconfigured
+	// if it points elsewhere or is missing, Unknown while the host is not running, or Nonexistent.
+	Kubeconfig string `json:"kubeconfig"`
+
+	// MinikubeStatus and LocalkubeStatus are the libmachine states of the host and localkube,
+	// kept for the existing templates.
+	MinikubeStatus  string `json:"-"`
+	LocalkubeStatus string `json:"-"`
+	// LastStartError describes why the last start failed, if it did.
+	LastStartError string `json:"lastStartError,omitempty"`
+	// LastStart and LastStop are how long the last start and stop took, and each of their steps.
+	LastStart cluster.Timing `json:"-"`
+	LastStop  cluster.Timing `json:"-"`
+}
+
+// statusChecker gets the states the status is made of, replaced in tests.
+type statusChecker struct {
+	// hostState returns the state of the host, as cluster.GetHostStatus does.
+	hostState func() (string, error)
+	// localkubeState returns the state of localkube, as cluster.GetLocalkubeStatus does.
+	localkubeState   func() (string, error)
+	apiserverHealthy func() error
+	vmIP             func() (string, error)
+	// kubeconfigServer returns the server the minikube context points at, "" if it is missing.
+	kubeconfigServer func() (string, error)
+}
+
+func newStatusChecker(api libmachine.API) statusChecker {
+	return statusChecker{
+		hostState:      func() (string, error) { return cluster.GetHostStatus(api) },
+		localkubeState: func() (string, error) { return cluster.GetLocalkubeStatus(api) },
+		apiserverHealthy: func() error {
+			h, err := cluster.CheckIfApiExistsAndLoad(api)
+			if err != nil {
+				return err
+			}
+			return cluster.APIServerHealthy(h)
+		},
+		vmIP: func() (string, error) {
+			h, err := cluster.CheckIfApiExistsAndLoad(api)
+			if err != nil {
+				return "", err
+			}
+			return h.Driver.GetIP()
+		},
+		kubeconfigServer: func() (string, error) {
+			return kubeconfig.ContextServer(kubeConfigPath(), cfg.GetMachineName())
+		},
+	}
+}
+
+// status returns the states of the host, localkube, the apiserver and the kubeconfig context.
+func (c statusChecker) status() (Status, error) {
+	ms, err := c.hostState()
+	if err != nil {
+		return Status{}, errors.Wrap(err, "Error getting machine status")
+	}
+	s := Status{MinikubeStatus: ms, LocalkubeStatus: state.None.String()}
+	switch ms {
+	case constants.MachineDoesNotExist:
+		s.Host, s.Kubelet, s.APIServer, s.Kubeconfig = StateNonexistent, StateNonexistent, StateNonexistent, StateNonexistent
+		return s, nil
+	case state.Running.String():
+		s.Host = StateRunning
+	default:
+		s.Host, s.Kubelet, s.APIServer, s.Kubeconfig = StateStopped, StateStopped, StateStopped, StateUnknown
+		return s, nil
+	}
+
+	s.LocalkubeStatus, err = c.localkubeState()
+	if err != nil {
+		return s, errors.Wrap(err, "Error getting localkube status")
+	}
+	s.Kubelet, s.APIServer = StateStopped, StateStopped
+	switch s.LocalkubeStatus {
+	case state.Running.String():
+		s.Kubelet, s.APIServer = StateRunning, StateRunning
+		if err := c.apiserverHealthy(); err != nil {
+			glog.Infoln("The apiserver is not healthy:", err)
+			s.APIServer = StateUnreachable
+		}
+	case state.Paused.String():
+		s.Kubelet = StatePaused
+	}
+	s.Kubeconfig, err = c.kubeconfigState()
+	return s, err
+}
+
+// kubeconfigState returns whether the minikube context points at the apiserver of the VM, at its IP
+// or, when the apiserver is forwarded to through the NAT network, at the loopback interface.
+func (c statusChecker) kubeconfigState() (string, error) {
+	server, err := c.kubeconfigServer()
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading kubeconfig")
+	}
+	u, err := url.Parse(server)
+	if server == "" || err != nil {
+		return StateMisconfigured, nil
+	}
+	if net.ParseIP(u.Hostname()).IsLoopback() {
+		return StateConfigured, nil
+	}
+	ip, err := c.vmIP()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting VM IP")
+	}
+	if u.Hostname() != ip || u.Port() != strconv.Itoa(constants.APIServerPort) {
+		glog.Infof("The minikube context points at %s rather than the apiserver at %s", server, ip)
+		return StateMisconfigured, nil
+	}
+	return StateConfigured, nil
+}
+
+// statusExitCode returns the exit code of minikube status for s, 0 if everything runs and kubeconfig is configured.
+func statusExitCode(s Status) int {
+	switch {
+	case s.Host == StateNonexistent:
+		return statusExitNonexistent
+	case s.Host != StateRunning:
+		return statusExitHostStopped
+	case s.Kubelet != StateRunning:
+		return statusExitKubeletStopped
+	case s.APIServer != StateRunning:
+		return statusExitAPIServerUnreachable
+	case s.Kubeconfig != StateConfigured:
+		return statusExitKubeconfigMisconfigured
+	}
+	return 0
+}
+
+// printStatus writes the status to out with the Go template format, or as JSON.
+func printStatus(out io.Writer, status Status, output, format string) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(status, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling the status")
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case "text":
+	default:
+		return errors.Errorf("Invalid --output %q, expected text or json", output)
+	}
+
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return errors.Wrap(err, "Error creating status template")
+	}
+	return errors.Wrap(tmpl.Execute(out, status), "Error executing status template")
 }
 
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Gets the status of a local kubernetes cluster",
-	Long:  `Gets the status of a local kubernetes cluster.`,
+	Long: `Gets the status of a local kubernetes cluster.
+
+Exits with 0 if the host, localkube and the apiserver run and the minikube context of kubeconfig points at the
+apiserver, with 2 if the host is stopped, 3 if localkube is stopped or paused, 4 if the apiserver is unreachable, 5 if the context
+is missing or points elsewhere, and 6 if the machine does not exist.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
@@ -50,32 +243,33 @@ var statusCmd = &cobra.Command{
 		}
 		defer api.Close()
 
-		ms, err := cluster.GetHostStatus(api)
+		status, err := newStatusChecker(api).status()
 		if err != nil {
-			glog.Errorln("Error getting machine status:", err)
+			glog.Errorln("Error getting status:", err)
 			cmdUtil.MaybeReportErrorAndExit(err)
 		}
-
-		ls := state.None.String()
-		if ms == state.Running.String() {
-			ls, err = cluster.GetLocalkubeStatus(api)
+		if status.Host != StateNonexistent {
+			ss, err := cluster.LoadStartState(cfg.GetMachineName())
+			if err != nil {
+				glog.Warningln("Error getting last start state:", err)
+			} else if ss.Failed() {
+				status.LastStartError = ss.String()
+			}
+			timings, err := cluster.LoadTimings(cfg.GetMachineName())
 			if err != nil {
-				glog.Errorln("Error localkube status:", err)
-				cmdUtil.MaybeReportErrorAndExit(err)
+				glog.Warningln("Error getting timings:", err)
 			}
+			status.LastStart = cluster.LastTiming(timings, cluster.TimingStart)
+			status.LastStop = cluster.LastTiming(timings, cluster.TimingStop)
+			warnCertExpiry(os.Stderr)
 		}
-		status := Status{ms, ls}
+		warnStaleDockerEnv(os.Stderr, api)
 
-		tmpl, err := template.New("status").Parse(statusFormat)
-		if err != nil {
-			glog.Errorln("Error creating status template:", err)
-			os.Exit(1)
-		}
-		err = tmpl.Execute(os.Stdout, status)
-		if err != nil {
-			glog.Errorln("Error executing status template:", err)
+		if err := printStatus(os.Stdout, status, statusOutput, statusFormat); err != nil {
+			glog.Errorln(err)
 			os.Exit(1)
 		}
+		os.Exit(statusExitCode(status))
 	},
 }
 
@@ -83,5 +277,6 @@ func init() {
 	statusCmd.Flags().StringVar(&statusFormat, "format", constants.DefaultStatusFormat,
 		`Go template format string for the status output.  The format for Go templates can be found here: https://golang.org/pkg/text/template/
 For the list accessible variables for the template, see the struct values here: https://godoc.org/k8s.io/minikube/cmd/minikube/cmd#Status`)
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "text", "The output format, text, as --format formats it, or json")
 	RootCmd.AddCommand(statusCmd)
 }
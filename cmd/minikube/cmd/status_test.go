@@ -0,0 +1,193 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestStatus(t *testing.T) {
+	var tests = []struct {
+		description string
+		host        string
+		localkube   string
+		health      error
+		server      string
+		expected    Status
+		exitCode    int
+	}{
+		{
+			description: "all running",
+			host:        "Running",
+			localkube:   "Running",
+			server:      "https://192.168.99.100:8443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Configured"},
+		},
+		{
+			description: "forwarded through the nat network",
+			host:        "Running",
+			localkube:   "Running",
+			server:      "https://127.0.0.1:50443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Configured"},
+		},
+		{
+			description: "nonexistent machine",
+			host:        constants.MachineDoesNotExist,
+			expected:    Status{Host: "Nonexistent", Kubelet: "Nonexistent", APIServer: "Nonexistent", Kubeconfig: "Nonexistent"},
+			exitCode:    6,
+		},
+		{
+			description: "stopped host",
+			host:        "Stopped",
+			expected:    Status{Host: "Stopped", Kubelet: "Stopped", APIServer: "Stopped", Kubeconfig: "Unknown"},
+			exitCode:    2,
+		},
+		{
+			description: "paused host",
+			host:        "Paused",
+			expected:    Status{Host: "Stopped", Kubelet: "Stopped", APIServer: "Stopped", Kubeconfig: "Unknown"},
+			exitCode:    2,
+		},
+		{
+			description: "stopped localkube",
+			host:        "Running",
+			localkube:   "Stopped",
+			server:      "https://192.168.99.100:8443",
+			expected:    Status{Host: "Running", Kubelet: "Stopped", APIServer: "Stopped", Kubeconfig: "Configured"},
+			exitCode:    3,
+		},
+		{
+			description: "paused localkube",
+			host:        "Running",
+			localkube:   "Paused",
+			server:      "https://192.168.99.100:8443",
+			expected:    Status{Host: "Running", Kubelet: "Paused", APIServer: "Stopped", Kubeconfig: "Configured"},
+			exitCode:    3,
+		},
+		{
+			description: "unreachable apiserver",
+			host:        "Running",
+			localkube:   "Running",
+			health:      errors.New("connection refused"),
+			server:      "https://192.168.99.100:8443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Unreachable", Kubeconfig: "Configured"},
+			exitCode:    4,
+		},
+		{
+			description: "missing context",
+			host:        "Running",
+			localkube:   "Running",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Misconfigured"},
+			exitCode:    5,
+		},
+		{
+			description: "context of another ip",
+			host:        "Running",
+			localkube:   "Running",
+			server:      "https://192.168.99.101:8443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Misconfigured"},
+			exitCode:    5,
+		},
+		{
+			description: "context of another port",
+			host:        "Running",
+			localkube:   "Running",
+			server:      "https://192.168.99.100:6443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Running", Kubeconfig: "Misconfigured"},
+			exitCode:    5,
+		},
+		{
+			description: "unreachable apiserver and misconfigured context",
+			host:        "Running",
+			localkube:   "Running",
+			health:      errors.New("connection refused"),
+			server:      "https://192.168.99.101:8443",
+			expected:    Status{Host: "Running", Kubelet: "Running", APIServer: "Unreachable", Kubeconfig: "Misconfigured"},
+			exitCode:    4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			checker := statusChecker{
+				hostState:        func() (string, error) { return test.host, nil },
+				localkubeState:   func() (string, error) { return test.localkube, nil },
+				apiserverHealthy: func() error { return test.health },
+				vmIP:             func() (string, error) { return "192.168.99.100", nil },
+				kubeconfigServer: func() (string, error) { return test.server, nil },
+			}
+			s, err := checker.status()
+			if err != nil {
+				t.Fatalf("Error getting status: %s", err)
+			}
+			s.MinikubeStatus, s.LocalkubeStatus = "", ""
+			if !reflect.DeepEqual(s, test.expected) {
+				t.Errorf("Expected status %+v, got %+v", test.expected, s)
+			}
+			if code := statusExitCode(s); code != test.exitCode {
+				t.Errorf("Expected exit code %d, got %d", test.exitCode, code)
+			}
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	checker := statusChecker{
+		hostState:      func() (string, error) { return "Running", nil },
+		localkubeState: func() (string, error) { return "", errors.New("ssh: handshake failed") },
+	}
+	if _, err := checker.status(); err == nil {
+		t.Error("Expected an error getting the state of localkube")
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	status := Status{Host: "Running", Kubelet: "Running", APIServer: "Unreachable", Kubeconfig: "Configured",
+		MinikubeStatus: "Running", LocalkubeStatus: "Running"}
+
+	var b bytes.Buffer
+	if err := printStatus(&b, status, "text", constants.DefaultStatusFormat); err != nil {
+		t.Fatalf("Error printing the status: %s", err)
+	}
+	expected := "minikube: Running\nlocalkube: Running\napiserver: Unreachable\nkubeconfig: Configured\n"
+	if b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	if err := printStatus(&b, status, "json", ""); err != nil {
+		t.Fatalf("Error printing JSON: %s", err)
+	}
+	var printed map[string]string
+	if err := json.Unmarshal(b.Bytes(), &printed); err != nil {
+		t.Fatalf("Error unmarshalling %s: %s", b.String(), err)
+	}
+	expectedJSON := map[string]string{"host": "Running", "kubelet": "Running", "apiServer": "Unreachable", "kubeconfig": "Configured"}
+	if !reflect.DeepEqual(printed, expectedJSON) {
+		t.Errorf("Expected %v, got %v", expectedJSON, printed)
+	}
+
+	if err := printStatus(&b, status, "yaml", ""); err == nil {
+		t.Error("Expected an error for an invalid output")
+	}
+}
@@ -17,42 +17,75 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	cmdUtil "k8s.io/minikube/cmd/util"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
+var (
+	stopForce   bool
+	stopTimeout time.Duration
+)
+
 // stopCmd represents the stop command
 var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stops a running local kubernetes cluster",
 	Long: `Stops a local kubernetes cluster running in Virtualbox. This command stops the VM
-itself, leaving all files intact. The cluster can be started again with the "start" command.`,
+itself, leaving all files intact. The cluster can be started again with the "start" command.
+With --force, the VM is shut down from inside the guest, then by the driver, and killed if it
+hasn't stopped once --timeout elapses.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Stopping local Kubernetes cluster...")
+		console.Progress("Stopping local Kubernetes cluster...")
 		api, err := machine.NewAPIClient(clientType)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			console.Err("Error getting client: %s\n", err)
 			os.Exit(1)
 		}
 		defer api.Close()
 
-		if err = cluster.StopHost(api); err != nil {
-			fmt.Println("Error stopping machine: ", err)
+		opts := cluster.StopOptions{Force: stopForce, Timeout: stopTimeout}
+		began := time.Now()
+		method, err := cluster.StopHost(context.Background(), api, retryPolicy(), opts)
+		recordStopTiming(began, method, err)
+		if err != nil {
+			console.ErrLn("Error stopping machine: ", err)
 			cmdUtil.MaybeReportErrorAndExit(err)
 		}
-		fmt.Println("Machine stopped.")
+		if method == cluster.StopKill {
+			console.Out("Machine killed, as it didn't shut down within %s.\n", stopTimeout)
+		} else {
+			console.OutLn("Machine stopped.")
+		}
 
-		if err := cmdUtil.KillMountProcess(); err != nil {
-			fmt.Println("Errors occurred deleting mount process: ", err)
+		if err := killMounts(config.GetMachineName(), cluster.AllMounts, console.ProgressWriter()); err != nil {
+			console.ErrLn("Errors occurred stopping mounts: ", err)
 		}
 	},
 }
 
+// recordStopTiming records how long the stop took, by the method which stopped the VM.
+func recordStopTiming(began time.Time, method cluster.StopMethod, err error) {
+	t := cluster.Timing{Command: cluster.TimingStop, Time: began, Duration: time.Since(began)}
+	if method != "" {
+		t.Durations = map[string]time.Duration{string(method): t.Duration}
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	cluster.RecordTiming(config.GetMachineName(), t)
+}
+
 func init() {
+	stopCmd.Flags().BoolVar(&stopForce, "force", false, "Kill the VM if it doesn't shut down within --timeout")
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", constants.DefaultStopTimeout, "How long to wait for the VM to shut down. Without --force, stopping fails once it elapses")
 	RootCmd.AddCommand(stopCmd)
 }
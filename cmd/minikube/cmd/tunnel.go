@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/service"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+)
+
+var tunnelCleanup bool
+
+// tunnelCmd represents the tunnel command
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "Routes the services' cluster IPs to minikube and gives the LoadBalancer services an external IP",
+	Long: `Routes the service cluster IP range to the minikube VM, adding a host route which takes root, or Administrator
+on Windows, and gives the services of type LoadBalancer their cluster IP as external IP. The route and external IPs
+are removed on Ctrl-C, or by "minikube tunnel --cleanup" when the tunnel was killed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, err := machine.NewAPIClient(clientType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting client: %s\n", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+		core, err := (&service.K8sClientGetter{}).GetCoreClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting the kubernetes client: %s\n", err)
+			os.Exit(1)
+		}
+
+		if tunnelCleanup {
+			err = tunnel.Cleanup(config.GetMachineName(), core, os.Stdout)
+		} else {
+			stop := make(chan struct{})
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-signals
+				close(stop)
+			}()
+			err = tunnel.Run(api, config.GetMachineName(), core, os.Stdout, stop)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error running the tunnel: ", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	tunnelCmd.Flags().BoolVar(&tunnelCleanup, "cleanup", false, "Remove the route and external IPs left by a tunnel which was killed")
+	RootCmd.AddCommand(tunnelCmd)
+}
@@ -17,27 +17,155 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"github.com/docker/machine/libmachine/state"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 
+	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/service"
 	"k8s.io/minikube/pkg/version"
 )
 
+// serverVersionTimeout is how long --components waits for the apiserver.
+const serverVersionTimeout = 5 * time.Second
+
+var (
+	versionOutput     string
+	versionComponents bool
+)
+
+// versionInfo is what minikube version --output prints. Its fields are read by tools, so they
+// are only ever added to.
+type versionInfo struct {
+	MinikubeVersion          string   `json:"minikubeVersion"`
+	Commit                   string   `json:"commit"`
+	DefaultKubernetesVersion string   `json:"defaultKubernetesVersion"`
+	ISOVersion               string   `json:"isoVersion"`
+	ISOURL                   string   `json:"isoURL"`
+	Drivers                  []string `json:"drivers"`
+	// Components are the versions of the running cluster, with --components.
+	Components *componentVersions `json:"components,omitempty"`
+}
+
+// componentVersions are the versions the running cluster reports, which are left out when it isn't running.
+type componentVersions struct {
+	Kubernetes string `json:"kubernetes,omitempty"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version of minikube",
-	Long:  `Print the version of minikube.`,
+	Long: `Print the version of minikube. With --output json or yaml, the commit it was built from, the default Kubernetes
+version, the ISO it uses and the drivers of this platform are printed too, and with --components the Kubernetes version of the
+running cluster.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		// Explicitly disable update checking for the version command
 		enableUpdateNotification = false
 	},
 	Run: func(command *cobra.Command, args []string) {
-
-		fmt.Println("minikube version:", version.GetVersion())
+		info := getVersionInfo()
+		if versionComponents {
+			info.Components = &componentVersions{}
+			v, err := serverKubernetesVersion()
+			if err != nil {
+				console.Err("Error getting the Kubernetes version of the cluster: %s\n", err)
+			}
+			info.Components.Kubernetes = v
+		}
+		if err := printVersion(os.Stdout, info, versionOutput); err != nil {
+			console.ErrLn(err)
+			os.Exit(1)
+		}
 	},
 }
 
+// getVersionInfo returns the versions of this build of minikube.
+func getVersionInfo() versionInfo {
+	return versionInfo{
+		MinikubeVersion:          version.GetVersion(),
+		Commit:                   version.GetGitCommitID(),
+		DefaultKubernetesVersion: constants.DefaultKubernetesVersion,
+		ISOVersion:               version.GetIsoVersion(),
+		ISOURL:                   constants.DefaultIsoUrl,
+		Drivers:                  constants.SupportedVMDrivers[:],
+	}
+}
+
+// serverKubernetesVersion returns the version the apiserver of the cluster reports, or "" if the
+// cluster isn't running.
+func serverKubernetesVersion() (string, error) {
+	api, err := machine.NewAPIClient(configCmd.GetClientType())
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting client")
+	}
+	defer api.Close()
+	s, err := cluster.GetHostStatus(api)
+	if err != nil {
+		return "", err
+	}
+	if s != state.Running.String() {
+		return "", nil
+	}
+	config, err := service.GetClientConfig()
+	if err != nil {
+		return "", err
+	}
+	config.Timeout = serverVersionTimeout
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the Kubernetes client")
+	}
+	v, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting the server version")
+	}
+	return v.GitVersion, nil
+}
+
+// printVersion writes the version as the plain text minikube version always printed, or all of
+// info as JSON or YAML.
+func printVersion(out io.Writer, info versionInfo, output string) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(info, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling the version")
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case "yaml":
+		b, err := yaml.Marshal(info)
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling the version")
+		}
+		_, err = out.Write(b)
+		return err
+	case "text":
+	default:
+		return errors.Errorf("Invalid --output %q, expected text, json or yaml", output)
+	}
+
+	fmt.Fprintln(out, "minikube version:", info.MinikubeVersion)
+	if info.Components != nil && info.Components.Kubernetes != "" {
+		fmt.Fprintln(out, "kubernetes version:", info.Components.Kubernetes)
+	}
+	return nil
+}
+
 func init() {
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", "text", "The output format, text, json or yaml")
+	versionCmd.Flags().BoolVar(&versionComponents, "components", false, "Also print the Kubernetes version of the running cluster")
 	RootCmd.AddCommand(versionCmd)
 }
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/minikube/pkg/version"
+)
+
+// versionJSONKeys are the fields of minikube version --output json, which tools rely on.
+var versionJSONKeys = []string{"commit", "defaultKubernetesVersion", "drivers", "isoURL", "isoVersion", "minikubeVersion"}
+
+// versionJSONKeysWithComponents are those fields with the one --components adds, sorted.
+var versionJSONKeysWithComponents = []string{"commit", "components", "defaultKubernetesVersion", "drivers", "isoURL", "isoVersion", "minikubeVersion"}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestVersionJSONSchema(t *testing.T) {
+	var tests = []struct {
+		description string
+		components  *componentVersions
+		expected    []string
+		component   []string
+	}{
+		{
+			description: "without components",
+			expected:    versionJSONKeys,
+		},
+		{
+			description: "cluster stopped",
+			components:  &componentVersions{},
+			expected:    versionJSONKeysWithComponents,
+		},
+		{
+			description: "cluster running",
+			components:  &componentVersions{Kubernetes: "v1.7.0"},
+			expected:    versionJSONKeysWithComponents,
+			component:   []string{"kubernetes"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			info := getVersionInfo()
+			info.Components = test.components
+			var b bytes.Buffer
+			if err := printVersion(&b, info, "json"); err != nil {
+				t.Fatalf("Error printing the version: %s", err)
+			}
+			m := map[string]interface{}{}
+			if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+				t.Fatalf("Error parsing the version %s: %s", b.String(), err)
+			}
+			if keys := sortedKeys(m); !reflect.DeepEqual(keys, test.expected) {
+				t.Errorf("Expected the fields %v, got %v", test.expected, keys)
+			}
+			if m["minikubeVersion"] != version.GetVersion() {
+				t.Errorf("Expected minikubeVersion %s, got %v", version.GetVersion(), m["minikubeVersion"])
+			}
+			if _, ok := m["drivers"].([]interface{}); !ok {
+				t.Errorf("Expected the drivers to be a list, got %v", m["drivers"])
+			}
+			if test.components != nil {
+				components, ok := m["components"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("Expected the components to be an object, got %v", m["components"])
+				}
+				if keys := sortedKeys(components); !reflect.DeepEqual(keys, test.component) {
+					t.Errorf("Expected the component fields %v, got %v", test.component, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	info := getVersionInfo()
+
+	var b bytes.Buffer
+	if err := printVersion(&b, info, "text"); err != nil {
+		t.Fatalf("Error printing the version: %s", err)
+	}
+	if expected := "minikube version: " + version.GetVersion() + "\n"; b.String() != expected {
+		t.Errorf("Expected the plain output %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	info.Components = &componentVersions{Kubernetes: "v1.7.0"}
+	if err := printVersion(&b, info, "text"); err != nil {
+		t.Fatalf("Error printing the version: %s", err)
+	}
+	if expected := "minikube version: " + version.GetVersion() + "\nkubernetes version: v1.7.0\n"; b.String() != expected {
+		t.Errorf("Expected the plain output %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	if err := printVersion(&b, info, "yaml"); err != nil {
+		t.Fatalf("Error printing the version: %s", err)
+	}
+	var parsed versionInfo
+	if err := yaml.Unmarshal(b.Bytes(), &parsed); err != nil {
+		t.Fatalf("Error parsing the version %s: %s", b.String(), err)
+	}
+	if !reflect.DeepEqual(parsed, info) {
+		t.Errorf("Expected the YAML version to be %+v, got %+v", info, parsed)
+	}
+
+	if err := printVersion(&b, info, "xml"); err == nil {
+		t.Error("Expected an error printing xml")
+	}
+}
@@ -33,7 +33,7 @@ func main() {
 		defer profile.Start(profile.TraceProfile).Stop()
 	}
 	if os.Getenv(constants.IsMinikubeChildProcess) == "" {
-		machine.StartDriver()
+		machine.StartDriverFromEnv()
 	}
 	cmd.Execute()
 }
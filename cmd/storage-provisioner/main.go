@@ -0,0 +1,44 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// storage-provisioner provisions host path volumes for the claims of the standard storage class,
+// running in a pod of the storage-provisioner addon.
+package main
+
+import (
+	"flag"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/rest"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/storage"
+)
+
+var pvDir = flag.String("pv-dir", constants.DefaultStorageProvisionerDir, "The directory of the node the volumes are provisioned in")
+
+func main() {
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		glog.Fatalf("Error getting the in-cluster config: %s", err)
+	}
+	glog.Infof("Provisioning volumes in %s", *pvDir)
+	if err := storage.StartStorageProvisioner(config, *pvDir); err != nil {
+		glog.Fatalf("Error running the storage provisioner: %s", err)
+	}
+}
@@ -22,12 +22,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -37,9 +35,9 @@ import (
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
-	"golang.org/x/crypto/ssh/terminal"
 	minikubeConfig "k8s.io/minikube/cmd/minikube/cmd/config"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/version"
 )
@@ -130,7 +128,7 @@ func MaybeReportErrorAndExit(errToReport error) {
 	var err error
 	if viper.GetBool(config.WantReportError) {
 		err = ReportError(errToReport, constants.ReportingURL)
-	} else if viper.GetBool(config.WantReportErrorPrompt) {
+	} else if viper.GetBool(config.WantReportErrorPrompt) && console.Interactive() {
 		fmt.Println(
 			`================================================================================
 An error has occurred. Would you like to opt in to sending anonymized crash
@@ -159,8 +157,11 @@ func getInput(input chan string, r io.Reader) {
 	input <- response
 }
 
+// PromptUserForAccept reads whether the user accepts from r, an empty answer accepting.
+// Without an answer within 30 seconds, or when minikube isn't interactive, the user doesn't
+// accept, as sending crash reports needs their consent.
 func PromptUserForAccept(r io.Reader) bool {
-	if !terminal.IsTerminal(int(os.Stdout.Fd())) {
+	if !console.Interactive() {
 		return false
 	}
 	input := make(chan string, 1)
@@ -230,19 +231,3 @@ func GetPort() (string, error) {
 	defer l.Close()
 	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
 }
-
-func KillMountProcess() error {
-	out, err := ioutil.ReadFile(filepath.Join(constants.GetMinipath(), constants.MountProcessFileName))
-	if err != nil {
-		return nil // no mount process to kill
-	}
-	pid, err := strconv.Atoi(string(out))
-	if err != nil {
-		return errors.Wrap(err, "error converting mount string to pid")
-	}
-	mountProc, err := os.FindProcess(pid)
-	if err != nil {
-		return errors.Wrap(err, "error converting mount string to pid")
-	}
-	return mountProc.Kill()
-}
@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/version"
 
 	"github.com/pkg/errors"
@@ -163,3 +164,11 @@ func TestKubectlDownloadMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestPromptUserForAcceptNotInteractive(t *testing.T) {
+	defer console.SetInteractive(console.Interactive())
+	console.SetInteractive(false)
+	if PromptUserForAccept(strings.NewReader("y\n")) {
+		t.Error("Expected a non-interactive prompt not to accept")
+	}
+}
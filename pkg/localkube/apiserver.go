@@ -21,6 +21,7 @@ import (
 	"path"
 	"strconv"
 
+	"github.com/golang/glog"
 	apiserveroptions "k8s.io/apiserver/pkg/server/options"
 	"k8s.io/apiserver/pkg/storage/storagebackend"
 
@@ -70,6 +71,12 @@ func StartAPIServer(lk LocalkubeServer) func() error {
 		RuntimeConfig: lk.RuntimeConfig,
 	}
 
+	config.Audit.Path = lk.AuditLogPath
+	if lk.AuditPolicyFile != "" {
+		// This apiserver only has the legacy audit log, which has no policy.
+		glog.Warningf("The apiserver logs every request to %s, the rules of the audit policy %s apply from Kubernetes v1.7.0", lk.AuditLogPath, lk.AuditPolicyFile)
+	}
+
 	lk.SetExtraConfigForComponent("apiserver", &config)
 
 	return func() error {
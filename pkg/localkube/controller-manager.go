@@ -40,6 +40,8 @@ func StartControllerManagerServer(lk LocalkubeServer) func() error {
 	config.VolumeConfiguration.EnableDynamicProvisioning = true
 	config.ServiceAccountKeyFile = lk.GetPrivateKeyCertPath()
 	config.RootCAFile = lk.GetCAPublicKeyCertPath()
+	config.ClusterCIDR = lk.PodCIDR.String()
+	config.ServiceCIDR = lk.ServiceClusterIPRange.String()
 
 	lk.SetExtraConfigForComponent("controller-manager", &config)
 
@@ -41,7 +41,7 @@ func StartKubeletServer(lk LocalkubeServer) func() error {
 	config.ClusterDomain = lk.DNSDomain
 	config.ClusterDNS = []string{lk.DNSIP.String()}
 	// For kubenet plugin.
-	config.PodCIDR = "10.180.1.0/24"
+	config.PodCIDR = lk.PodCIDR.String()
 
 	config.NodeIP = lk.NodeIP.String()
 
@@ -53,6 +53,14 @@ func StartKubeletServer(lk LocalkubeServer) func() error {
 	if lk.ContainerRuntime != "" {
 		config.ContainerRuntime = lk.ContainerRuntime
 	}
+	// The remote runtimes serve their images on the same socket.
+	if lk.ContainerRuntimeEndpoint != "" {
+		config.RemoteRuntimeEndpoint = lk.ContainerRuntimeEndpoint
+		config.RemoteImageEndpoint = lk.ContainerRuntimeEndpoint
+	}
+	// The kubelet sets the feature gates of its config when it runs, so they are the ones
+	// of all the components, merged with their FeatureGates extra config by SetupServer.
+	config.FeatureGates = lk.FeatureGates
 	lk.SetExtraConfigForComponent("kubelet", &config)
 
 	// Use the host's resolver config
@@ -34,6 +34,13 @@ import (
 
 const serverInterval = 200
 
+// featureGatesKey is the key of the extra config setting the feature gates of a component.
+const featureGatesKey = "FeatureGates"
+
+// featureGateComponents are the components taking feature gates, in the order their extra
+// config is applied in.
+var featureGateComponents = []string{"apiserver", "controller-manager", "scheduler", "kubelet"}
+
 // LocalkubeServer provides a fully functional Kubernetes cluster running entirely through goroutines
 type LocalkubeServer struct {
 	// Inherits Servers
@@ -46,6 +53,7 @@ type LocalkubeServer struct {
 	DNSIP                    net.IP
 	LocalkubeDirectory       string
 	ServiceClusterIPRange    net.IPNet
+	PodCIDR                  net.IPNet
 	APIServerAddress         net.IP
 	APIServerPort            int
 	APIServerInsecureAddress net.IP
@@ -57,8 +65,12 @@ type LocalkubeServer struct {
 	RuntimeConfig            flag.ConfigurationMap
 	NodeIP                   net.IP
 	ContainerRuntime         string
+	ContainerRuntimeEndpoint string
 	NetworkPlugin            string
 	FeatureGates             string
+	AuditLogPath             string
+	AuditPolicyFile          string
+	StorageProvisioner       bool
 	ExtraConfig              util.ExtraOptionSlice
 }
 
@@ -113,9 +125,43 @@ func (lk LocalkubeServer) getExtraConfigForComponent(component string) []util.Ex
 	return e
 }
 
+// GetFeatureGates returns the feature gates of the components, which share them as they run in
+// the one process: the --feature-gates ones, with those the FeatureGates extra config of any of
+// the components sets taking precedence, each gate once.
+func (lk LocalkubeServer) GetFeatureGates() (string, error) {
+	gates, err := util.ParseFeatureGates(lk.FeatureGates)
+	if err != nil {
+		return "", err
+	}
+	set := map[string]string{}
+	for _, component := range featureGateComponents {
+		for _, e := range lk.getExtraConfigForComponent(component) {
+			if e.Key != featureGatesKey {
+				continue
+			}
+			extra, err := util.ParseFeatureGates(e.Value)
+			if err != nil {
+				return "", fmt.Errorf("Invalid %s.%s: %s", component, featureGatesKey, err)
+			}
+			for k, v := range extra {
+				if other, ok := set[k]; ok && gates[k] != v {
+					glog.Warningf("Feature gate %s is set by both %s and %s, setting it to %t", k, other, component, v)
+				}
+				gates[k] = v
+				set[k] = component
+			}
+		}
+	}
+	return util.FormatFeatureGates(gates), nil
+}
+
 func (lk LocalkubeServer) SetExtraConfigForComponent(component string, config interface{}) {
 	extra := lk.getExtraConfigForComponent(component)
 	for _, e := range extra {
+		// The feature gates of every component are the ones of GetFeatureGates.
+		if e.Key == featureGatesKey {
+			continue
+		}
 		glog.Infof("Setting %s to %s on %s.\n", e.Key, e.Value, component)
 		if err := util.FindAndSet(e.Key, config, e.Value); err != nil {
 			glog.Warningf("Unable to set %s to %s. Error: %s", e.Key, e.Value, err)
@@ -180,7 +226,11 @@ func (lk LocalkubeServer) shouldGenerateCACerts() bool {
 }
 
 func (lk LocalkubeServer) getAllIPs() ([]net.IP, error) {
-	ips := []net.IP{net.ParseIP(util.DefaultServiceClusterIP)}
+	serviceIP, err := util.ServiceIP(&lk.ServiceClusterIPRange)
+	if err != nil {
+		return nil, err
+	}
+	ips := []net.IP{serviceIP}
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
 		return nil, err
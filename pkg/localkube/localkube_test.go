@@ -21,9 +21,12 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"k8s.io/kubernetes/cmd/kubelet/app/options"
 	"k8s.io/minikube/pkg/minikube/tests"
+	"k8s.io/minikube/pkg/util"
 )
 
 var testIPs = []net.IP{net.ParseIP("1.2.3.4")}
@@ -121,3 +124,74 @@ func TestShouldNotGenerateCerts(t *testing.T) {
 		t.Fatalf("IPs match, we should not generate.")
 	}
 }
+
+func TestGetFeatureGates(t *testing.T) {
+	var tests = []struct {
+		description string
+		extraConfig util.ExtraOptionSlice
+		expected    string
+	}{
+		{
+			description: "feature gates only",
+			expected:    "Accelerators=true,AllAlpha=true",
+		},
+		{
+			description: "apiserver extra config",
+			extraConfig: util.ExtraOptionSlice{{Component: "apiserver", Key: "FeatureGates", Value: "AllAlpha=false"}},
+			expected:    "Accelerators=true,AllAlpha=false",
+		},
+		{
+			description: "controller-manager extra config",
+			extraConfig: util.ExtraOptionSlice{{Component: "controller-manager", Key: "FeatureGates", Value: "AllAlpha=false,TaintNodesByCondition=true"}},
+			expected:    "Accelerators=true,AllAlpha=false,TaintNodesByCondition=true",
+		},
+		{
+			description: "scheduler extra config",
+			extraConfig: util.ExtraOptionSlice{{Component: "scheduler", Key: "FeatureGates", Value: "Accelerators=false"}},
+			expected:    "Accelerators=false,AllAlpha=true",
+		},
+		{
+			description: "kubelet extra config",
+			extraConfig: util.ExtraOptionSlice{{Component: "kubelet", Key: "FeatureGates", Value: "Accelerators=false"}},
+			expected:    "Accelerators=false,AllAlpha=true",
+		},
+		{
+			description: "other extra config",
+			extraConfig: util.ExtraOptionSlice{{Component: "apiserver", Key: "Authorization.Mode", Value: "RBAC"}},
+			expected:    "Accelerators=true,AllAlpha=true",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			lk := LocalkubeServer{FeatureGates: "AllAlpha=true,Accelerators=true", ExtraConfig: test.extraConfig}
+			gates, err := lk.GetFeatureGates()
+			if err != nil {
+				t.Fatalf("Error getting feature gates: %s", err)
+			}
+			if gates != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, gates)
+			}
+			for _, gate := range []string{"AllAlpha=", "Accelerators="} {
+				if n := strings.Count(gates, gate); n != 1 {
+					t.Errorf("Expected %s once, found it %d times in %s", gate, n, gates)
+				}
+			}
+		})
+	}
+
+	lk := LocalkubeServer{ExtraConfig: util.ExtraOptionSlice{{Component: "scheduler", Key: "FeatureGates", Value: "AllAlpha"}}}
+	if _, err := lk.GetFeatureGates(); err == nil {
+		t.Errorf("Expected an error for invalid extra config feature gates")
+	}
+}
+
+func TestSetExtraConfigSkipsFeatureGates(t *testing.T) {
+	lk := LocalkubeServer{ExtraConfig: util.ExtraOptionSlice{{Component: "kubelet", Key: "FeatureGates", Value: "AllAlpha=false"}}}
+	config := options.NewKubeletServer()
+	config.FeatureGates = "AllAlpha=false,Accelerators=true"
+	lk.SetExtraConfigForComponent("kubelet", &config)
+	if config.FeatureGates != "AllAlpha=false,Accelerators=true" {
+		t.Errorf("Expected the merged feature gates kept, got %s", config.FeatureGates)
+	}
+}
@@ -40,6 +40,8 @@ func StartProxyServer(lk LocalkubeServer) func() error {
 	config.Master = lk.GetAPIServerInsecureURL()
 
 	config.Mode = componentconfig.ProxyModeIPTables
+	// Traffic to service IPs from outside the pod range is masqueraded.
+	config.ClusterCIDR = lk.PodCIDR.String()
 
 	// defaults
 	config.OOMScoreAdj = &OOMScoreAdj
@@ -17,136 +17,25 @@ limitations under the License.
 package localkube
 
 import (
-	"errors"
-	"fmt"
-	"os"
-	"path"
-	"time"
-
 	"github.com/golang/glog"
-	"github.com/r2d4/external-storage/lib/controller"
-	"github.com/r2d4/external-storage/lib/leaderelection"
-	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/uuid"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/pkg/api/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/storage"
 )
 
-const (
-	resyncPeriod              = 15 * time.Second
-	provisionerName           = "k8s.io/minikube-hostpath"
-	exponentialBackOffOnError = false
-	failedRetryThreshold      = 5
-	leasePeriod               = leaderelection.DefaultLeaseDuration
-	retryPeriod               = leaderelection.DefaultRetryPeriod
-	renewDeadline             = leaderelection.DefaultRenewDeadline
-	termLimit                 = leaderelection.DefaultTermLimit
-)
-
-type hostPathProvisioner struct {
-	// The directory to create PV-backing directories in
-	pvDir string
-
-	// Identity of this hostPathProvisioner, generated. Used to identify "this"
-	// provisioner's PVs.
-	identity types.UID
-}
-
-func NewHostPathProvisioner() controller.Provisioner {
-	return &hostPathProvisioner{
-		pvDir:    "/tmp/hostpath-provisioner",
-		identity: uuid.NewUUID(),
-	}
-}
-
-var _ controller.Provisioner = &hostPathProvisioner{}
-
-// Provision creates a storage asset and returns a PV object representing it.
-func (p *hostPathProvisioner) Provision(options controller.VolumeOptions) (*v1.PersistentVolume, error) {
-	path := path.Join(p.pvDir, options.PVName)
-
-	if err := os.MkdirAll(path, 0777); err != nil {
-		return nil, err
-	}
-
-	pv := &v1.PersistentVolume{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name: options.PVName,
-			Annotations: map[string]string{
-				"hostPathProvisionerIdentity": string(p.identity),
-			},
-		},
-		Spec: v1.PersistentVolumeSpec{
-			PersistentVolumeReclaimPolicy: options.PersistentVolumeReclaimPolicy,
-			AccessModes:                   options.PVC.Spec.AccessModes,
-			Capacity: v1.ResourceList{
-				v1.ResourceName(v1.ResourceStorage): options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)],
-			},
-			PersistentVolumeSource: v1.PersistentVolumeSource{
-				HostPath: &v1.HostPathVolumeSource{
-					Path: path,
-				},
-			},
-		},
-	}
-
-	return pv, nil
-}
-
-// Delete removes the storage asset that was created by Provision represented
-// by the given PV.
-func (p *hostPathProvisioner) Delete(volume *v1.PersistentVolume) error {
-	ann, ok := volume.Annotations["hostPathProvisionerIdentity"]
-	if !ok {
-		return errors.New("identity annotation not found on PV")
-	}
-	if ann != string(p.identity) {
-		return &controller.IgnoredError{"identity annotation on PV does not match ours"}
-	}
-
-	path := path.Join(p.pvDir, volume.Name)
-	if err := os.RemoveAll(path); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (lk LocalkubeServer) NewStorageProvisionerServer() Server {
 	return NewSimpleServer("storage-provisioner", serverInterval, StartStorageProvisioner(lk), noop)
 }
 
 func StartStorageProvisioner(lk LocalkubeServer) func() error {
-
 	// Create an InClusterConfig and use it to create a client for the controller
 	// to use to communicate with Kubernetes
 	config := rest.Config{Host: "http://localhost:8080"}
 	return func() error {
-
-		clientset, err := kubernetes.NewForConfig(&config)
-		if err != nil {
-			glog.Fatalf("Failed to create client: %v", err)
-		}
-
-		// The controller needs to know what the server version is because out-of-tree
-		// provisioners aren't officially supported until 1.5
-		serverVersion, err := clientset.Discovery().ServerVersion()
-		if err != nil {
-			return fmt.Errorf("Error getting server version: %v", err)
+		if err := storage.StartStorageProvisioner(&config, constants.DefaultStorageProvisionerDir); err != nil {
+			glog.Errorf("Error running the storage provisioner: %s", err)
+			return err
 		}
-
-		// Create the provisioner: it implements the Provisioner interface expected by
-		// the controller
-		hostPathProvisioner := NewHostPathProvisioner()
-
-		// Start the provision controller which will dynamically provision hostPath
-		// PVs
-		pc := controller.NewProvisionController(clientset, resyncPeriod, provisionerName, hostPathProvisioner, serverVersion.GitVersion, exponentialBackOffOnError, failedRetryThreshold, leasePeriod, renewDeadline, retryPeriod, termLimit)
-
-		pc.Run(wait.NeverStop)
 		return nil
 	}
 }
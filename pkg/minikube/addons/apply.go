@@ -0,0 +1,335 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	rbacv1beta1 "k8s.io/client-go/kubernetes/typed/rbac/v1beta1"
+	storagev1 "k8s.io/client-go/kubernetes/typed/storage/v1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	rbac "k8s.io/client-go/pkg/apis/rbac/v1beta1"
+	storage "k8s.io/client-go/pkg/apis/storage/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// The actions taken on the objects of the addons.
+const (
+	Created    = "created"
+	Configured = "configured"
+	Deleted    = "deleted"
+	NotFound   = "not found"
+	NotDefault = "no longer default"
+)
+
+// Result is the outcome of applying or deleting an object of an addon.
+type Result struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Action    string
+	Err       error
+}
+
+func (r Result) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%s %q failed: %s", r.Kind, name, r.Err)
+	}
+	return fmt.Sprintf("%s %q %s", r.Kind, name, r.Action)
+}
+
+// Failed returns whether any of the results is an error.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Clients are the clients of the kinds of objects the addons are made of.
+type Clients struct {
+	Core        corev1.CoreV1Interface
+	Deployments extensionsv1beta1.DeploymentsGetter
+	DaemonSets  extensionsv1beta1.DaemonSetsGetter
+	Storage     storagev1.StorageClassesGetter
+	RBAC        rbacv1beta1.RbacV1beta1Interface
+}
+
+// NewClients returns the clients of the current profile's cluster, through its kubeconfig context.
+func NewClients() (*Clients, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.GetMachineName()}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating kubeConfig")
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating new client from kubeConfig.ClientConfig()")
+	}
+	return &Clients{
+		Core:        client.CoreV1(),
+		Deployments: client.ExtensionsV1beta1(),
+		DaemonSets:  client.ExtensionsV1beta1(),
+		Storage:     client.StorageV1(),
+		RBAC:        client.RbacV1beta1(),
+	}, nil
+}
+
+// Apply creates the objects which don't exist and updates those which do, returning the result for each.
+func (c *Clients) Apply(objs []runtime.Object) []Result {
+	var results []Result
+	for _, obj := range objs {
+		r := c.object(obj)
+		if r.Err == nil {
+			r.Action, r.Err = r.apply()
+		}
+		results = append(results, r.Result)
+	}
+	return results
+}
+
+// Delete deletes the objects, in the reverse order of their creation, along with the pods of the
+// controllers among them. Objects which don't exist are reported as not found.
+func (c *Clients) Delete(objs []runtime.Object) []Result {
+	var results []Result
+	policy := meta_v1.DeletePropagationBackground
+	options := &meta_v1.DeleteOptions{PropagationPolicy: &policy}
+	for i := len(objs) - 1; i >= 0; i-- {
+		r := c.object(objs[i])
+		if r.Err == nil {
+			r.Action = Deleted
+			r.Err = r.delete(options)
+			if apierrors.IsNotFound(r.Err) {
+				r.Action, r.Err = NotFound, nil
+			}
+		}
+		results = append(results, r.Result)
+	}
+	return results
+}
+
+// object is an object of an addon, along with the calls applying and deleting it.
+type object struct {
+	Result
+	get    func() (meta_v1.Object, error)
+	create func() error
+	update func(existing meta_v1.Object) error
+	delete func(*meta_v1.DeleteOptions) error
+}
+
+// apply creates the object, or updates it if it exists.
+func (o *object) apply() (string, error) {
+	existing, err := o.get()
+	if apierrors.IsNotFound(err) {
+		return Created, o.create()
+	}
+	if err != nil {
+		return "", err
+	}
+	return Configured, o.update(existing)
+}
+
+// object returns the calls of the kind of obj, or a result with the error if it isn't
+// supported. Namespaced objects which don't specify their namespace are in the default one.
+func (c *Clients) object(obj runtime.Object) *object {
+	opts := meta_v1.GetOptions{}
+	switch o := obj.(type) {
+	case *v1.ConfigMap:
+		i := c.Core.ConfigMaps(namespace(&o.ObjectMeta))
+		return newObject("configmap", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1.Secret:
+		i := c.Core.Secrets(namespace(&o.ObjectMeta))
+		return newObject("secret", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1.Service:
+		i := c.Core.Services(namespace(&o.ObjectMeta))
+		return newObject("service", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete).keeping(func(existing meta_v1.Object) error {
+			keepAllocated(o, existing.(*v1.Service))
+			return nil
+		})
+	case *v1.ReplicationController:
+		i := c.Core.ReplicationControllers(namespace(&o.ObjectMeta))
+		return newObject("replicationcontroller", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1beta1.Deployment:
+		i := c.Deployments.Deployments(namespace(&o.ObjectMeta))
+		return newObject("deployment", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1beta1.DaemonSet:
+		i := c.DaemonSets.DaemonSets(namespace(&o.ObjectMeta))
+		return newObject("daemonset", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1.PersistentVolumeClaim:
+		i := c.Core.PersistentVolumeClaims(namespace(&o.ObjectMeta))
+		return newObject("persistentvolumeclaim", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete).keeping(func(existing meta_v1.Object) error {
+			// The spec of a claim can't change once it is bound to a volume, so a claim of
+			// another size has to be deleted, along with its data, to be created again.
+			spec := existing.(*v1.PersistentVolumeClaim).Spec
+			size, requested := spec.Resources.Requests[v1.ResourceStorage], o.Spec.Resources.Requests[v1.ResourceStorage]
+			if size.Cmp(requested) != 0 {
+				return errors.Errorf("the claim of %s can't be resized to %s, delete it to create it again, which deletes its data", size.String(), requested.String())
+			}
+			o.Spec = spec
+			return nil
+		})
+	case *storage.StorageClass:
+		i := c.Storage.StorageClasses()
+		return newObject("storageclass", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *v1.ServiceAccount:
+		i := c.Core.ServiceAccounts(namespace(&o.ObjectMeta))
+		return newObject("serviceaccount", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete).keeping(func(existing meta_v1.Object) error {
+			// The token secrets of the account are the ones the token controller added.
+			o.Secrets = existing.(*v1.ServiceAccount).Secrets
+			return nil
+		})
+	case *rbac.ClusterRole:
+		i := c.RBAC.ClusterRoles()
+		return newObject("clusterrole", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *rbac.ClusterRoleBinding:
+		i := c.RBAC.ClusterRoleBindings()
+		return newObject("clusterrolebinding", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *rbac.Role:
+		i := c.RBAC.Roles(namespace(&o.ObjectMeta))
+		return newObject("role", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	case *rbac.RoleBinding:
+		i := c.RBAC.RoleBindings(namespace(&o.ObjectMeta))
+		return newObject("rolebinding", &o.ObjectMeta,
+			func() (meta_v1.Object, error) { return i.Get(o.Name, opts) },
+			func() error { _, err := i.Create(o); return err },
+			func() error { _, err := i.Update(o); return err },
+			i.Delete)
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	return &object{Result: Result{Kind: kind, Err: errors.Errorf("Unsupported kind %s", kind)}}
+}
+
+// newObject returns the calls of an object of kind through the typed calls of its client. The
+// update keeps the resource version of the existing object, which the apiserver checks.
+func newObject(kind string, m *meta_v1.ObjectMeta, get func() (meta_v1.Object, error), create, update func() error,
+	delete func(name string, options *meta_v1.DeleteOptions) error) *object {
+	return &object{
+		Result: Result{Kind: kind, Namespace: m.Namespace, Name: m.Name},
+		get:    get,
+		create: create,
+		update: func(existing meta_v1.Object) error {
+			m.ResourceVersion = existing.GetResourceVersion()
+			return update()
+		},
+		delete: func(options *meta_v1.DeleteOptions) error { return delete(m.Name, options) },
+	}
+}
+
+// keeping returns the object with its update first calling keep, which keeps the fields of the
+// existing object updates can't change, or fails when the object can't be updated.
+func (o *object) keeping(keep func(existing meta_v1.Object) error) *object {
+	update := o.update
+	o.update = func(existing meta_v1.Object) error {
+		if err := keep(existing); err != nil {
+			return err
+		}
+		return update(existing)
+	}
+	return o
+}
+
+func namespace(m *meta_v1.ObjectMeta) string {
+	if m.Namespace == "" {
+		m.Namespace = meta_v1.NamespaceDefault
+	}
+	return m.Namespace
+}
+
+// keepAllocated keeps the cluster IP and node ports the apiserver allocated to the existing
+// service, which updates can't change or drop. The node ports are left out when the service is
+// changed to a cluster IP one, which has none.
+func keepAllocated(svc, existing *v1.Service) {
+	svc.ResourceVersion = existing.ResourceVersion
+	svc.Spec.ClusterIP = existing.Spec.ClusterIP
+	if svc.Spec.Type == "" || svc.Spec.Type == v1.ServiceTypeClusterIP {
+		return
+	}
+	for i, p := range svc.Spec.Ports {
+		if p.NodePort != 0 {
+			continue
+		}
+		for _, e := range existing.Spec.Ports {
+			if e.Port == p.Port && (p.Protocol == "" || e.Protocol == p.Protocol) {
+				svc.Spec.Ports[i].NodePort = e.NodePort
+			}
+		}
+	}
+}
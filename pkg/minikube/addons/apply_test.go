@@ -0,0 +1,571 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	rbacv1beta1 "k8s.io/client-go/kubernetes/typed/rbac/v1beta1"
+	storagev1 "k8s.io/client-go/kubernetes/typed/storage/v1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	rbac "k8s.io/client-go/pkg/apis/rbac/v1beta1"
+	storage "k8s.io/client-go/pkg/apis/storage/v1"
+	core "k8s.io/client-go/testing"
+	"k8s.io/minikube/pkg/minikube/assets"
+)
+
+// fakeCluster stores the objects created through its clients by resource, namespace and name,
+// bumping their resource version on each write, as the apiserver does.
+type fakeCluster struct {
+	core.Fake
+	objects map[string]runtime.Object
+	version int
+	// failures fails the calls of the resources of its keys with the errors.
+	failures map[string]error
+}
+
+func newFakeCluster() *fakeCluster {
+	c := &fakeCluster{objects: map[string]runtime.Object{}, failures: map[string]error{}}
+	c.AddReactor("*", "*", c.react)
+	return c
+}
+
+func (c *fakeCluster) clients() *Clients {
+	return &Clients{
+		Core:        &fake.FakeCoreV1{Fake: &c.Fake},
+		Deployments: &fakeDeploymentsGetter{fake: &c.Fake},
+		DaemonSets:  &fakeDaemonSetsGetter{fake: &c.Fake},
+		Storage:     &fakeStorageClassesGetter{fake: &c.Fake},
+		RBAC:        &fakeRBAC{fake: &c.Fake},
+	}
+}
+
+func key(resource, namespace, name string) string {
+	return resource + "/" + namespace + "/" + name
+}
+
+func (c *fakeCluster) react(action core.Action) (bool, runtime.Object, error) {
+	resource := action.GetResource().Resource
+	if err := c.failures[resource]; err != nil {
+		return true, nil, err
+	}
+	switch action.GetVerb() {
+	case "get":
+		a := action.(core.GetAction)
+		obj, ok := c.objects[key(resource, a.GetNamespace(), a.GetName())]
+		if !ok {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, a.GetName())
+		}
+		return true, obj, nil
+	case "create", "update":
+		obj := action.(core.CreateAction).GetObject()
+		m := obj.(meta_v1.Object)
+		k := key(resource, action.GetNamespace(), m.GetName())
+		existing, exists := c.objects[k]
+		if action.GetVerb() == "create" && exists {
+			return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: resource}, m.GetName())
+		}
+		if action.GetVerb() == "update" {
+			if !exists {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, m.GetName())
+			}
+			if m.GetResourceVersion() != existing.(meta_v1.Object).GetResourceVersion() {
+				return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: resource}, m.GetName(), errors.New("resource version changed"))
+			}
+		}
+		copied, err := scheme.Scheme.DeepCopy(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		stored := copied.(runtime.Object)
+		c.version++
+		stored.(meta_v1.Object).SetResourceVersion(strconv.Itoa(c.version))
+		c.objects[k] = stored
+		return true, stored, nil
+	case "list":
+		// Only the storage classes are listed.
+		list := &storage.StorageClassList{}
+		prefix := key(resource, action.GetNamespace(), "")
+		for k, obj := range c.objects {
+			if strings.HasPrefix(k, prefix) {
+				list.Items = append(list.Items, *obj.(*storage.StorageClass))
+			}
+		}
+		sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+		return true, list, nil
+	case "delete":
+		a := action.(core.DeleteAction)
+		k := key(resource, a.GetNamespace(), a.GetName())
+		if _, ok := c.objects[k]; !ok {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, a.GetName())
+		}
+		delete(c.objects, k)
+		return true, nil, nil
+	}
+	return false, nil, nil
+}
+
+var (
+	deploymentsResource         = schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "deployments"}
+	daemonSetsResource          = schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "daemonsets"}
+	storageClassesResource      = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	clusterRolesResource        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterroles"}
+	clusterRoleBindingsResource = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterrolebindings"}
+	rolesResource               = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "roles"}
+	roleBindingsResource        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "rolebindings"}
+)
+
+// fakeDeploymentsGetter makes the calls of the deployments through the fake, as the fakes of
+// the core clients do.
+type fakeDeploymentsGetter struct {
+	fake *core.Fake
+}
+
+func (g *fakeDeploymentsGetter) Deployments(namespace string) extensionsv1beta1.DeploymentInterface {
+	return &fakeDeployments{fake: g.fake, ns: namespace}
+}
+
+type fakeDeployments struct {
+	extensionsv1beta1.DeploymentInterface
+	fake *core.Fake
+	ns   string
+}
+
+func (d *fakeDeployments) Get(name string, _ meta_v1.GetOptions) (*v1beta1.Deployment, error) {
+	obj, err := d.fake.Invokes(core.NewGetAction(deploymentsResource, d.ns, name), &v1beta1.Deployment{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Deployment), err
+}
+
+func (d *fakeDeployments) Create(deployment *v1beta1.Deployment) (*v1beta1.Deployment, error) {
+	obj, err := d.fake.Invokes(core.NewCreateAction(deploymentsResource, d.ns, deployment), &v1beta1.Deployment{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Deployment), err
+}
+
+func (d *fakeDeployments) Update(deployment *v1beta1.Deployment) (*v1beta1.Deployment, error) {
+	obj, err := d.fake.Invokes(core.NewUpdateAction(deploymentsResource, d.ns, deployment), &v1beta1.Deployment{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Deployment), err
+}
+
+func (d *fakeDeployments) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := d.fake.Invokes(core.NewDeleteAction(deploymentsResource, d.ns, name), &v1beta1.Deployment{})
+	return err
+}
+
+type fakeDaemonSetsGetter struct {
+	fake *core.Fake
+}
+
+func (g *fakeDaemonSetsGetter) DaemonSets(namespace string) extensionsv1beta1.DaemonSetInterface {
+	return &fakeDaemonSets{fake: g.fake, ns: namespace}
+}
+
+type fakeDaemonSets struct {
+	extensionsv1beta1.DaemonSetInterface
+	fake *core.Fake
+	ns   string
+}
+
+func (d *fakeDaemonSets) Get(name string, _ meta_v1.GetOptions) (*v1beta1.DaemonSet, error) {
+	obj, err := d.fake.Invokes(core.NewGetAction(daemonSetsResource, d.ns, name), &v1beta1.DaemonSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.DaemonSet), err
+}
+
+func (d *fakeDaemonSets) Create(daemonSet *v1beta1.DaemonSet) (*v1beta1.DaemonSet, error) {
+	obj, err := d.fake.Invokes(core.NewCreateAction(daemonSetsResource, d.ns, daemonSet), &v1beta1.DaemonSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.DaemonSet), err
+}
+
+func (d *fakeDaemonSets) Update(daemonSet *v1beta1.DaemonSet) (*v1beta1.DaemonSet, error) {
+	obj, err := d.fake.Invokes(core.NewUpdateAction(daemonSetsResource, d.ns, daemonSet), &v1beta1.DaemonSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.DaemonSet), err
+}
+
+func (d *fakeDaemonSets) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := d.fake.Invokes(core.NewDeleteAction(daemonSetsResource, d.ns, name), &v1beta1.DaemonSet{})
+	return err
+}
+
+type fakeStorageClassesGetter struct {
+	fake *core.Fake
+}
+
+func (g *fakeStorageClassesGetter) StorageClasses() storagev1.StorageClassInterface {
+	return &fakeStorageClasses{fake: g.fake}
+}
+
+type fakeStorageClasses struct {
+	storagev1.StorageClassInterface
+	fake *core.Fake
+}
+
+func (s *fakeStorageClasses) Get(name string, _ meta_v1.GetOptions) (*storage.StorageClass, error) {
+	obj, err := s.fake.Invokes(core.NewRootGetAction(storageClassesResource, name), &storage.StorageClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storage.StorageClass), err
+}
+
+func (s *fakeStorageClasses) Create(class *storage.StorageClass) (*storage.StorageClass, error) {
+	obj, err := s.fake.Invokes(core.NewRootCreateAction(storageClassesResource, class), &storage.StorageClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storage.StorageClass), err
+}
+
+func (s *fakeStorageClasses) Update(class *storage.StorageClass) (*storage.StorageClass, error) {
+	obj, err := s.fake.Invokes(core.NewRootUpdateAction(storageClassesResource, class), &storage.StorageClass{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storage.StorageClass), err
+}
+
+func (s *fakeStorageClasses) List(opts meta_v1.ListOptions) (*storage.StorageClassList, error) {
+	obj, err := s.fake.Invokes(core.NewRootListAction(storageClassesResource, opts), &storage.StorageClassList{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storage.StorageClassList), err
+}
+
+func (s *fakeStorageClasses) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := s.fake.Invokes(core.NewRootDeleteAction(storageClassesResource, name), &storage.StorageClass{})
+	return err
+}
+
+type fakeRBAC struct {
+	rbacv1beta1.RbacV1beta1Interface
+	fake *core.Fake
+}
+
+func (r *fakeRBAC) ClusterRoles() rbacv1beta1.ClusterRoleInterface {
+	return &fakeClusterRoles{fake: r.fake}
+}
+
+func (r *fakeRBAC) ClusterRoleBindings() rbacv1beta1.ClusterRoleBindingInterface {
+	return &fakeClusterRoleBindings{fake: r.fake}
+}
+
+func (r *fakeRBAC) Roles(namespace string) rbacv1beta1.RoleInterface {
+	return &fakeRoles{fake: r.fake, ns: namespace}
+}
+
+func (r *fakeRBAC) RoleBindings(namespace string) rbacv1beta1.RoleBindingInterface {
+	return &fakeRoleBindings{fake: r.fake, ns: namespace}
+}
+
+type fakeClusterRoles struct {
+	rbacv1beta1.ClusterRoleInterface
+	fake *core.Fake
+}
+
+func (r *fakeClusterRoles) Get(name string, _ meta_v1.GetOptions) (*rbac.ClusterRole, error) {
+	obj, err := r.fake.Invokes(core.NewRootGetAction(clusterRolesResource, name), &rbac.ClusterRole{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRole), err
+}
+
+func (r *fakeClusterRoles) Create(role *rbac.ClusterRole) (*rbac.ClusterRole, error) {
+	obj, err := r.fake.Invokes(core.NewRootCreateAction(clusterRolesResource, role), &rbac.ClusterRole{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRole), err
+}
+
+func (r *fakeClusterRoles) Update(role *rbac.ClusterRole) (*rbac.ClusterRole, error) {
+	obj, err := r.fake.Invokes(core.NewRootUpdateAction(clusterRolesResource, role), &rbac.ClusterRole{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRole), err
+}
+
+func (r *fakeClusterRoles) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := r.fake.Invokes(core.NewRootDeleteAction(clusterRolesResource, name), &rbac.ClusterRole{})
+	return err
+}
+
+type fakeClusterRoleBindings struct {
+	rbacv1beta1.ClusterRoleBindingInterface
+	fake *core.Fake
+}
+
+func (r *fakeClusterRoleBindings) Get(name string, _ meta_v1.GetOptions) (*rbac.ClusterRoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewRootGetAction(clusterRoleBindingsResource, name), &rbac.ClusterRoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRoleBinding), err
+}
+
+func (r *fakeClusterRoleBindings) Create(binding *rbac.ClusterRoleBinding) (*rbac.ClusterRoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewRootCreateAction(clusterRoleBindingsResource, binding), &rbac.ClusterRoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRoleBinding), err
+}
+
+func (r *fakeClusterRoleBindings) Update(binding *rbac.ClusterRoleBinding) (*rbac.ClusterRoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewRootUpdateAction(clusterRoleBindingsResource, binding), &rbac.ClusterRoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.ClusterRoleBinding), err
+}
+
+func (r *fakeClusterRoleBindings) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := r.fake.Invokes(core.NewRootDeleteAction(clusterRoleBindingsResource, name), &rbac.ClusterRoleBinding{})
+	return err
+}
+
+type fakeRoles struct {
+	rbacv1beta1.RoleInterface
+	fake *core.Fake
+	ns   string
+}
+
+func (r *fakeRoles) Get(name string, _ meta_v1.GetOptions) (*rbac.Role, error) {
+	obj, err := r.fake.Invokes(core.NewGetAction(rolesResource, r.ns, name), &rbac.Role{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.Role), err
+}
+
+func (r *fakeRoles) Create(role *rbac.Role) (*rbac.Role, error) {
+	obj, err := r.fake.Invokes(core.NewCreateAction(rolesResource, r.ns, role), &rbac.Role{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.Role), err
+}
+
+func (r *fakeRoles) Update(role *rbac.Role) (*rbac.Role, error) {
+	obj, err := r.fake.Invokes(core.NewUpdateAction(rolesResource, r.ns, role), &rbac.Role{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.Role), err
+}
+
+func (r *fakeRoles) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := r.fake.Invokes(core.NewDeleteAction(rolesResource, r.ns, name), &rbac.Role{})
+	return err
+}
+
+type fakeRoleBindings struct {
+	rbacv1beta1.RoleBindingInterface
+	fake *core.Fake
+	ns   string
+}
+
+func (r *fakeRoleBindings) Get(name string, _ meta_v1.GetOptions) (*rbac.RoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewGetAction(roleBindingsResource, r.ns, name), &rbac.RoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.RoleBinding), err
+}
+
+func (r *fakeRoleBindings) Create(binding *rbac.RoleBinding) (*rbac.RoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewCreateAction(roleBindingsResource, r.ns, binding), &rbac.RoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.RoleBinding), err
+}
+
+func (r *fakeRoleBindings) Update(binding *rbac.RoleBinding) (*rbac.RoleBinding, error) {
+	obj, err := r.fake.Invokes(core.NewUpdateAction(roleBindingsResource, r.ns, binding), &rbac.RoleBinding{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*rbac.RoleBinding), err
+}
+
+func (r *fakeRoleBindings) Delete(name string, _ *meta_v1.DeleteOptions) error {
+	_, err := r.fake.Invokes(core.NewDeleteAction(roleBindingsResource, r.ns, name), &rbac.RoleBinding{})
+	return err
+}
+
+// configured returns the addon with the values of its configuration, the defaults for those they don't set.
+func configured(t *testing.T, addon string, values map[string]string) *assets.Addon {
+	a, err := assets.Addons[addon].WithConfigValues(values)
+	if err != nil {
+		t.Fatalf("Error configuring %s: %s", addon, err)
+	}
+	return a
+}
+
+func objects(t *testing.T, addon string) []runtime.Object {
+	objs, err := Objects(configured(t, addon, nil))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of %s: %s", addon, err)
+	}
+	return objs
+}
+
+func actions(results []Result) []string {
+	var actions []string
+	for _, r := range results {
+		if r.Err != nil {
+			actions = append(actions, r.Kind+" "+r.Name+" failed")
+			continue
+		}
+		actions = append(actions, r.Kind+" "+r.Name+" "+r.Action)
+	}
+	return actions
+}
+
+func TestApplyAndDelete(t *testing.T) {
+	c := newFakeCluster()
+	clients := c.clients()
+
+	expected := []string{"deployment kube-dns created", "configmap kube-dns created", "service kube-dns created"}
+	if got := actions(clients.Apply(objects(t, "kube-dns"))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v applying kube-dns, got %v", expected, got)
+	}
+	if len(c.objects) != 3 {
+		t.Errorf("Expected 3 objects, got %v", c.objects)
+	}
+	if _, ok := c.objects[key("deployments", "kube-system", "kube-dns")]; !ok {
+		t.Errorf("Expected the deployment in kube-system, got %v", c.objects)
+	}
+
+	// The apiserver allocated an IP to the service, which applying again keeps.
+	svc := c.objects[key("services", "kube-system", "kube-dns")].(*v1.Service)
+	svc.Spec.ClusterIP = "10.0.0.10"
+	expected = []string{"deployment kube-dns configured", "configmap kube-dns configured", "service kube-dns configured"}
+	if got := actions(clients.Apply(objects(t, "kube-dns"))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v applying kube-dns again, got %v", expected, got)
+	}
+	if ip := c.objects[key("services", "kube-system", "kube-dns")].(*v1.Service).Spec.ClusterIP; ip != "10.0.0.10" {
+		t.Errorf("Expected the cluster IP to be kept, got %q", ip)
+	}
+
+	delete(c.objects, key("configmaps", "kube-system", "kube-dns"))
+	results := clients.Delete(objects(t, "kube-dns"))
+	expected = []string{"service kube-dns deleted", "configmap kube-dns not found", "deployment kube-dns deleted"}
+	if got := actions(results); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v deleting kube-dns, got %v", expected, got)
+	}
+	if Failed(results) || len(c.objects) != 0 {
+		t.Errorf("Expected all the objects deleted, got %v", c.objects)
+	}
+}
+
+func TestApplyStorageClass(t *testing.T) {
+	c := newFakeCluster()
+	clients := c.clients()
+	expected := []string{"storageclass standard created"}
+	if got := actions(clients.Apply(objects(t, "default-storageclass"))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if _, ok := c.objects[key("storageclasses", "", "standard")]; !ok {
+		t.Errorf("Expected the storage class, cluster scoped, got %v", c.objects)
+	}
+	expected = []string{"storageclass standard deleted"}
+	if got := actions(clients.Delete(objects(t, "default-storageclass"))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestApplyFailures(t *testing.T) {
+	c := newFakeCluster()
+	c.failures["replicationcontrollers"] = errors.New("connection refused")
+	results := c.clients().Apply(objects(t, "dashboard"))
+	expected := []string{"replicationcontroller kubernetes-dashboard failed", "service kubernetes-dashboard created"}
+	if got := actions(results); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if !Failed(results) {
+		t.Error("Expected the results to have failed")
+	}
+	if s := results[0].String(); s != `replicationcontroller "kube-system/kubernetes-dashboard" failed: connection refused` {
+		t.Errorf("Unexpected result %s", s)
+	}
+	if s := results[1].String(); s != `service "kube-system/kubernetes-dashboard" created` {
+		t.Errorf("Unexpected result %s", s)
+	}
+
+	unsupported := &v1.Pod{TypeMeta: meta_v1.TypeMeta{Kind: "Pod"}}
+	if results := c.clients().Apply([]runtime.Object{unsupported}); !Failed(results) {
+		t.Errorf("Expected an unsupported kind to fail, got %v", results)
+	}
+}
+
+func TestKeepAllocated(t *testing.T) {
+	svc := &v1.Service{Spec: v1.ServiceSpec{Type: v1.ServiceTypeNodePort, Ports: []v1.ServicePort{{Port: 80}, {Port: 443, NodePort: 30443}}}}
+	existing := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{ResourceVersion: "7"},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.20",
+			Ports:     []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP, NodePort: 30080}, {Port: 443, Protocol: v1.ProtocolTCP, NodePort: 31443}},
+		},
+	}
+	keepAllocated(svc, existing)
+	if svc.ResourceVersion != "7" || svc.Spec.ClusterIP != "10.0.0.20" {
+		t.Errorf("Expected the resource version and cluster IP kept, got %+v", svc)
+	}
+	if svc.Spec.Ports[0].NodePort != 30080 || svc.Spec.Ports[1].NodePort != 30443 {
+		t.Errorf("Expected the allocated node port kept and the requested one set, got %+v", svc.Spec.Ports)
+	}
+
+	clusterIP := &v1.Service{Spec: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, Ports: []v1.ServicePort{{Port: 80}}}}
+	keepAllocated(clusterIP, existing)
+	if clusterIP.Spec.ClusterIP != "10.0.0.20" || clusterIP.Spec.Ports[0].NodePort != 0 {
+		t.Errorf("Expected the cluster IP kept and no node port, got %+v", clusterIP.Spec)
+	}
+}
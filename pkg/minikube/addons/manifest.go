@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// Objects decodes the objects of the manifests of the addon which are in the addons directory.
+// The other manifests, such as the addon manager's pod, are run by the kubelet instead.
+func Objects(addon *assets.Addon) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	for _, a := range addon.Assets {
+		if a.GetTargetDir() != constants.AddonsPath {
+			continue
+		}
+		o, err := decodeManifest(a.Bytes())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding %s", a.GetTargetName())
+		}
+		objs = append(objs, o...)
+	}
+	return objs, nil
+}
+
+// decodeManifest decodes the objects of the YAML documents of a manifest.
+func decodeManifest(data []byte) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	r := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if empty(doc) {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// empty returns whether a YAML document holds nothing but comments.
+func empty(doc []byte) bool {
+	for _, line := range strings.Split(string(doc), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") && line != "---" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,363 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	rbac "k8s.io/client-go/pkg/apis/rbac/v1beta1"
+	storage "k8s.io/client-go/pkg/apis/storage/v1"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestObjects(t *testing.T) {
+	for name := range assets.Addons {
+		t.Run(name, func(t *testing.T) {
+			objs, err := Objects(configured(t, name, nil))
+			if err != nil {
+				t.Fatalf("Error decoding the objects of %s: %s", name, err)
+			}
+			if name == "addon-manager" && len(objs) != 0 {
+				t.Errorf("Expected the pod of the addon manager to be left to the kubelet, got %v", objs)
+			}
+			for _, r := range newFakeCluster().clients().Apply(objs) {
+				if r.Err != nil {
+					t.Errorf("Unexpected error applying %s: %s", name, r)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectsKinds(t *testing.T) {
+	var tests = []struct {
+		addon    string
+		expected []string
+	}{
+		{addon: "dashboard", expected: []string{"*v1.ReplicationController", "*v1.Service"}},
+		{addon: "ingress", expected: []string{"*v1.ServiceAccount", "*v1beta1.ClusterRole", "*v1beta1.Role", "*v1beta1.RoleBinding",
+			"*v1beta1.ClusterRoleBinding", "*v1.ConfigMap", "*v1beta1.Deployment", "*v1beta1.Deployment", "*v1.Service"}},
+		{addon: "kube-dns", expected: []string{"*v1beta1.Deployment", "*v1.ConfigMap", "*v1.Service"}},
+		{addon: "default-storageclass", expected: []string{"*v1.StorageClass"}},
+		{addon: "registry-creds", expected: []string{"*v1.Secret", "*v1.Secret", "*v1.Secret", "*v1.ReplicationController"}},
+		{addon: "registry", expected: []string{"*v1.PersistentVolumeClaim", "*v1.ReplicationController", "*v1.Service", "*v1beta1.DaemonSet"}},
+		{addon: "storage-provisioner", expected: []string{"*v1.ServiceAccount", "*v1beta1.ClusterRoleBinding", "*v1beta1.Deployment", "*v1.StorageClass"}},
+		{addon: "metrics", expected: []string{"*v1.ServiceAccount", "*v1beta1.ClusterRoleBinding", "*v1beta1.Deployment", "*v1.Service",
+			"*v1beta1.Deployment", "*v1.Service", "*v1beta1.Deployment", "*v1.Service"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addon, func(t *testing.T) {
+			objs, err := Objects(configured(t, test.addon, nil))
+			if err != nil {
+				t.Fatalf("Error decoding the objects of %s: %s", test.addon, err)
+			}
+			kinds := []string{}
+			for _, obj := range objs {
+				kinds = append(kinds, reflect.TypeOf(obj).String())
+			}
+			if !reflect.DeepEqual(kinds, test.expected) {
+				t.Errorf("Expected objects %v, got %v", test.expected, kinds)
+			}
+		})
+	}
+}
+
+// TestIngress checks the objects of the ingress addon refer to each other.
+func TestIngress(t *testing.T) {
+	objs, err := Objects(cluster.AddonWithImageRepository(configured(t, "ingress", nil), "registry.example.com/google_containers"))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of ingress: %s", err)
+	}
+	names := map[string]bool{}
+	for _, obj := range objs {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatalf("Error accessing the metadata of %T: %s", obj, err)
+		}
+		if m.GetName() == "" || m.GetLabels()["addonmanager.kubernetes.io/mode"] != "Reconcile" {
+			t.Errorf("Expected %T %q named and reconciled by the addon manager", obj, m.GetName())
+		}
+		names[fmt.Sprintf("%T %s", obj, m.GetName())] = true
+	}
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *rbac.RoleBinding:
+			if !names["*v1beta1.Role "+o.RoleRef.Name] {
+				t.Errorf("Expected the role %s of %s", o.RoleRef.Name, o.Name)
+			}
+			for _, s := range o.Subjects {
+				if !names["*v1.ServiceAccount "+s.Name] {
+					t.Errorf("Expected the service account %s of %s", s.Name, o.Name)
+				}
+			}
+		case *rbac.ClusterRoleBinding:
+			if !names["*v1beta1.ClusterRole "+o.RoleRef.Name] {
+				t.Errorf("Expected the cluster role %s of %s", o.RoleRef.Name, o.Name)
+			}
+			for _, s := range o.Subjects {
+				if !names["*v1.ServiceAccount "+s.Name] {
+					t.Errorf("Expected the service account %s of %s", s.Name, o.Name)
+				}
+			}
+		case *v1beta1.Deployment:
+			spec := o.Spec.Template.Spec
+			if account := spec.ServiceAccountName; account != "" && !names["*v1.ServiceAccount "+account] {
+				t.Errorf("Expected the service account %s of %s", account, o.Name)
+			}
+			selector, err := meta_v1.LabelSelectorAsSelector(o.Spec.Selector)
+			if err != nil || !selector.Matches(labels.Set(o.Spec.Template.Labels)) {
+				t.Errorf("Expected the selector of %s to match its pods", o.Name)
+			}
+			for _, c := range spec.Containers {
+				if !strings.HasPrefix(c.Image, "registry.example.com/google_containers/") {
+					t.Errorf("Expected the image of %s from the image repository, got %s", c.Name, c.Image)
+				}
+				for _, arg := range c.Args {
+					if ref := strings.TrimPrefix(arg, "--default-backend-service=$(POD_NAMESPACE)/"); ref != arg && !names["*v1.Service "+ref] {
+						t.Errorf("Expected the default backend service %s", ref)
+					}
+					if ref := strings.TrimPrefix(arg, "--configmap=$(POD_NAMESPACE)/"); ref != arg && !names["*v1.ConfigMap "+ref] {
+						t.Errorf("Expected the configmap %s", ref)
+					}
+				}
+			}
+		}
+	}
+
+	controller := objs[7].(*v1beta1.Deployment)
+	hostPorts := map[int32]bool{}
+	for _, p := range controller.Spec.Template.Spec.Containers[0].Ports {
+		hostPorts[p.HostPort] = true
+	}
+	if !hostPorts[80] || !hostPorts[443] {
+		t.Errorf("Expected the controller on the ports 80 and 443 of the VM, got %v", hostPorts)
+	}
+}
+
+// TestRegistry checks the registry addon is reachable on its node port from the host, and on
+// localhost:5000 of the VM through the proxy, and stores its images in a volume of the configured size.
+func TestRegistry(t *testing.T) {
+	addon := cluster.AddonWithDNSDomain(configured(t, "registry", nil), cluster.KubernetesConfig{DNSDomain: "example.test"})
+	objs, err := Objects(addon)
+	if err != nil {
+		t.Fatalf("Error decoding the objects of registry: %s", err)
+	}
+	claim, rc, svc, proxy := objs[0].(*v1.PersistentVolumeClaim), objs[1].(*v1.ReplicationController), objs[2].(*v1.Service), objs[3].(*v1beta1.DaemonSet)
+
+	if storage := claim.Spec.Resources.Requests[v1.ResourceStorage]; storage.String() != constants.DefaultRegistryStorageSize {
+		t.Errorf("Expected a claim of %s, got %s", constants.DefaultRegistryStorageSize, storage.String())
+	}
+	volumes := rc.Spec.Template.Spec.Volumes
+	if len(volumes) != 1 || volumes[0].PersistentVolumeClaim == nil || volumes[0].PersistentVolumeClaim.ClaimName != claim.Name {
+		t.Errorf("Expected the registry to store its images in the claim %s, got %+v", claim.Name, volumes)
+	}
+	if !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(rc.Spec.Template.Labels)) {
+		t.Errorf("Expected the selector of the service to match the pods of the registry")
+	}
+	if len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].NodePort != constants.RegistryNodePort {
+		t.Errorf("Expected the registry on node port %d, got %+v", constants.RegistryNodePort, svc.Spec.Ports)
+	}
+
+	c := proxy.Spec.Template.Spec.Containers[0]
+	if c.Ports[0].HostPort != 5000 {
+		t.Errorf("Expected the proxy on port 5000 of the VM, got %+v", c.Ports)
+	}
+	env := map[string]string{}
+	for _, e := range c.Env {
+		env[e.Name] = e.Value
+	}
+	if expected := fmt.Sprintf("%s.%s.svc.example.test.", svc.Name, svc.Namespace); env["REGISTRY_HOST"] != expected {
+		t.Errorf("Expected the proxy to the service %s, got %s", expected, env["REGISTRY_HOST"])
+	}
+	if expected := fmt.Sprint(svc.Spec.Ports[0].Port); env["REGISTRY_PORT"] != expected {
+		t.Errorf("Expected the proxy to port %s of the service, got %s", expected, env["REGISTRY_PORT"])
+	}
+
+	// The size of an existing claim is kept, as it can't change once bound, and asking for
+	// another size fails rather than being dropped.
+	fake := newFakeCluster()
+	fake.clients().Apply(objs)
+	for _, r := range fake.clients().Apply(objs) {
+		if r.Err != nil || r.Action != Configured {
+			t.Errorf("Expected %s configured, got %s", r.Name, r)
+		}
+	}
+	resized, err := Objects(configured(t, "registry", map[string]string{"storage-size": "20Gi"}))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of registry: %s", err)
+	}
+	if storage := resized[0].(*v1.PersistentVolumeClaim).Spec.Resources.Requests[v1.ResourceStorage]; storage.String() != "20Gi" {
+		t.Errorf("Expected a claim of 20Gi, got %s", storage.String())
+	}
+	for _, r := range fake.clients().Apply(resized) {
+		if r.Kind == "persistentvolumeclaim" {
+			if r.Err == nil || !strings.Contains(r.Err.Error(), "can't be resized to 20Gi") {
+				t.Errorf("Expected an error resizing %s, got %s", r.Name, r)
+			}
+		} else if r.Err != nil || r.Action != Configured {
+			t.Errorf("Expected %s configured, got %s", r.Name, r)
+		}
+	}
+	stored := fake.objects[key("persistentvolumeclaims", "kube-system", claim.Name)].(*v1.PersistentVolumeClaim)
+	if storage := stored.Spec.Resources.Requests[v1.ResourceStorage]; storage.String() != constants.DefaultRegistryStorageSize {
+		t.Errorf("Expected the claim of %s kept, got %s", constants.DefaultRegistryStorageSize, storage.String())
+	}
+}
+
+func TestStorageProvisioner(t *testing.T) {
+	objs, err := Objects(configured(t, "storage-provisioner", map[string]string{"directory": "/data/volumes"}))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of storage-provisioner: %s", err)
+	}
+	account, binding, dp, class := objs[0].(*v1.ServiceAccount), objs[1].(*rbac.ClusterRoleBinding), objs[2].(*v1beta1.Deployment), objs[3].(*storage.StorageClass)
+
+	if s := binding.Subjects; len(s) != 1 || s[0].Name != account.Name || s[0].Namespace != account.Namespace {
+		t.Errorf("Expected the binding for the account %s, got %+v", account.Name, s)
+	}
+	pod := dp.Spec.Template.Spec
+	if pod.ServiceAccountName != account.Name {
+		t.Errorf("Expected the provisioner to run as %s, got %s", account.Name, pod.ServiceAccountName)
+	}
+	if pod.Volumes[0].HostPath == nil || pod.Volumes[0].HostPath.Path != "/data/volumes" {
+		t.Errorf("Expected the provisioner to mount /data/volumes of the VM, got %+v", pod.Volumes)
+	}
+	c := pod.Containers[0]
+	if c.VolumeMounts[0].MountPath != "/data/volumes" || !reflect.DeepEqual(c.Args, []string{"--pv-dir=/data/volumes"}) {
+		t.Errorf("Expected the volumes provisioned at the path of the VM, got mounts %+v and args %v", c.VolumeMounts, c.Args)
+	}
+
+	defaults, err := Objects(configured(t, "default-storageclass", nil))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of default-storageclass: %s", err)
+	}
+	standard := defaults[0].(*storage.StorageClass)
+	if class.Name != standard.Name || class.Provisioner != standard.Provisioner || !IsDefaultClass(class) {
+		t.Errorf("Expected the default class %s of %s, got %+v", standard.Name, standard.Provisioner, class)
+	}
+}
+
+// TestMetrics checks the services of the metrics addon select its pods, the dashboard finding the
+// collector as the heapster service and the host reaching grafana on a node port.
+func TestMetrics(t *testing.T) {
+	addon := cluster.AddonWithImageRepository(configured(t, "metrics", map[string]string{"retention": "24h"}), "registry.example.com/google_containers")
+	objs, err := Objects(addon)
+	if err != nil {
+		t.Fatalf("Error decoding the objects of metrics: %s", err)
+	}
+	account, binding := objs[0].(*v1.ServiceAccount), objs[1].(*rbac.ClusterRoleBinding)
+	if s := binding.Subjects; len(s) != 1 || s[0].Name != account.Name || s[0].Namespace != account.Namespace {
+		t.Errorf("Expected the binding for the account %s, got %+v", account.Name, s)
+	}
+
+	services := map[string]*v1.Service{}
+	for i := 2; i < len(objs); i += 2 {
+		dp, svc := objs[i].(*v1beta1.Deployment), objs[i+1].(*v1.Service)
+		services[svc.Name] = svc
+		if svc.Name != dp.Name && svc.Name != "heapster" {
+			t.Errorf("Expected the service of %s named after it, got %s", dp.Name, svc.Name)
+		}
+		if !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(dp.Spec.Template.Labels)) {
+			t.Errorf("Expected the selector of the service %s to match the pods of %s", svc.Name, dp.Name)
+		}
+		for _, c := range dp.Spec.Template.Spec.Containers {
+			if !strings.HasPrefix(c.Image, "registry.example.com/google_containers/") {
+				t.Errorf("Expected the image of %s from the image repository, got %s", c.Name, c.Image)
+			}
+		}
+	}
+
+	collector := objs[2].(*v1beta1.Deployment).Spec.Template.Spec
+	if collector.ServiceAccountName != account.Name {
+		t.Errorf("Expected the collector to run as %s, got %s", account.Name, collector.ServiceAccountName)
+	}
+	args := strings.Join(collector.Containers[0].Command, " ")
+	for _, arg := range []string{"--source=kubernetes.summary_api:", "--sink=influxdb:http://monitoring-influxdb:8086?retention=24h"} {
+		if !strings.Contains(args, arg) {
+			t.Errorf("Expected %s in the command of the collector: %s", arg, args)
+		}
+	}
+	if _, ok := services["heapster"]; !ok {
+		t.Errorf("Expected the heapster service the dashboard gets the metrics from, got %v", services)
+	}
+	grafana, ok := services["monitoring-grafana"]
+	if !ok || grafana.Spec.Type != v1.ServiceTypeNodePort || grafana.Labels["kubernetes.io/minikube-addons-endpoint"] != "metrics" {
+		t.Errorf("Expected grafana on a node port, opened by minikube addons open metrics, got %+v", grafana)
+	}
+}
+
+func TestObjectsImageRepository(t *testing.T) {
+	objs, err := Objects(cluster.AddonWithImageRepository(assets.Addons["kube-dns"], "registry.example.com/google_containers"))
+	if err != nil {
+		t.Fatalf("Error decoding the objects of kube-dns: %s", err)
+	}
+	d, ok := objs[0].(*v1beta1.Deployment)
+	if !ok {
+		t.Fatalf("Expected a deployment, got %T", objs[0])
+	}
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if !strings.HasPrefix(c.Image, "registry.example.com/google_containers/") {
+			t.Errorf("Expected the image of container %s from the repository, got %s", c.Name, c.Image)
+		}
+	}
+}
+
+func TestDecodeManifest(t *testing.T) {
+	manifest := `# A license header.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+# Nothing but a comment.
+---
+kind: StorageClass
+apiVersion: storage.k8s.io/v1
+metadata:
+  name: second
+provisioner: k8s.io/minikube-hostpath
+`
+	objs, err := decodeManifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("Error decoding the manifest: %s", err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(objs))
+	}
+	if cm, ok := objs[0].(*v1.ConfigMap); !ok || cm.Name != "first" {
+		t.Errorf("Expected configmap first, got %+v", objs[0])
+	}
+	if sc, ok := objs[1].(*storage.StorageClass); !ok || sc.Name != "second" {
+		t.Errorf("Expected storageclass second, got %+v", objs[1])
+	}
+
+	if _, err := decodeManifest([]byte("kind: Unknown\napiVersion: v1\n")); err == nil {
+		t.Error("Expected an error decoding an unknown kind")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// The readiness of the workloads of an addon.
+const (
+	Ready    = "ready"
+	NotReady = "not ready"
+	Unknown  = "unknown"
+)
+
+// Workload is how many of the pods of a deployment, daemon set or replication controller of an addon are ready.
+type Workload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Ready     int32  `json:"ready"`
+	Desired   int32  `json:"desired"`
+}
+
+// IsReady returns whether the pods of the workload are all ready.
+func (w Workload) IsReady() bool {
+	return w.Desired > 0 && w.Ready >= w.Desired
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s %q %d/%d ready", w.Kind, w.Namespace+"/"+w.Name, w.Ready, w.Desired)
+}
+
+// Workloads returns the readiness of the deployments, daemon sets and replication controllers among
+// the objects. Those which don't exist have none of the pods of their manifest ready.
+func (c *Clients) Workloads(objs []runtime.Object) ([]Workload, error) {
+	var workloads []Workload
+	for _, obj := range objs {
+		var w Workload
+		var err error
+		switch o := obj.(type) {
+		case *v1beta1.Deployment:
+			w = Workload{Kind: "deployment", Namespace: namespace(&o.ObjectMeta), Name: o.Name, Desired: replicas(o.Spec.Replicas)}
+			var d *v1beta1.Deployment
+			if d, err = c.Deployments.Deployments(o.Namespace).Get(o.Name, meta_v1.GetOptions{}); err == nil {
+				// The available pods of an earlier revision don't count.
+				w.Desired, w.Ready = replicas(d.Spec.Replicas), d.Status.AvailableReplicas
+				if d.Status.UpdatedReplicas < w.Ready || d.Status.ObservedGeneration < d.Generation {
+					w.Ready = d.Status.UpdatedReplicas
+				}
+			}
+		case *v1beta1.DaemonSet:
+			w = Workload{Kind: "daemonset", Namespace: namespace(&o.ObjectMeta), Name: o.Name, Desired: 1}
+			var d *v1beta1.DaemonSet
+			if d, err = c.DaemonSets.DaemonSets(o.Namespace).Get(o.Name, meta_v1.GetOptions{}); err == nil {
+				w.Desired, w.Ready = d.Status.DesiredNumberScheduled, d.Status.NumberReady
+			}
+		case *v1.ReplicationController:
+			w = Workload{Kind: "replicationcontroller", Namespace: namespace(&o.ObjectMeta), Name: o.Name, Desired: replicas(o.Spec.Replicas)}
+			var rc *v1.ReplicationController
+			if rc, err = c.Core.ReplicationControllers(o.Namespace).Get(o.Name, meta_v1.GetOptions{}); err == nil {
+				w.Desired, w.Ready = replicas(rc.Spec.Replicas), rc.Status.ReadyReplicas
+			}
+		default:
+			continue
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, nil
+}
+
+// Readiness returns Ready if all the workloads are, NotReady if any isn't, and "" without workloads.
+func Readiness(workloads []Workload) string {
+	if len(workloads) == 0 {
+		return ""
+	}
+	for _, w := range workloads {
+		if !w.IsReady() {
+			return NotReady
+		}
+	}
+	return Ready
+}
+
+// replicas returns the number of replicas of a spec, which defaults to 1.
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
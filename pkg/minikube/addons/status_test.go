@@ -0,0 +1,149 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestWorkloads(t *testing.T) {
+	meta := func(name string) meta_v1.ObjectMeta {
+		return meta_v1.ObjectMeta{Name: name, Namespace: "kube-system"}
+	}
+	manifests := []runtime.Object{
+		&v1.ConfigMap{ObjectMeta: meta("config")},
+		&v1beta1.Deployment{ObjectMeta: meta("deployment"), Spec: v1beta1.DeploymentSpec{Replicas: int32Ptr(2)}},
+		&v1beta1.DaemonSet{ObjectMeta: meta("daemonset")},
+		&v1.ReplicationController{ObjectMeta: meta("rc")},
+		&v1.ReplicationController{ObjectMeta: meta("missing"), Spec: v1.ReplicationControllerSpec{Replicas: int32Ptr(3)}},
+	}
+
+	var tests = []struct {
+		description string
+		live        map[string]runtime.Object
+		expected    []int32
+		readiness   string
+	}{
+		{
+			description: "ready",
+			live: map[string]runtime.Object{
+				"deployments": &v1beta1.Deployment{
+					ObjectMeta: meta_v1.ObjectMeta{Generation: 2},
+					Spec:       v1beta1.DeploymentSpec{Replicas: int32Ptr(2)},
+					Status:     v1beta1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+				},
+				"daemonsets":             &v1beta1.DaemonSet{Status: v1beta1.DaemonSetStatus{DesiredNumberScheduled: 1, NumberReady: 1}},
+				"replicationcontrollers": &v1.ReplicationController{Status: v1.ReplicationControllerStatus{ReadyReplicas: 1}},
+			},
+			expected:  []int32{2, 2, 1, 1, 1, 1},
+			readiness: Ready,
+		},
+		{
+			description: "rolling out",
+			live: map[string]runtime.Object{
+				"deployments": &v1beta1.Deployment{
+					Spec:   v1beta1.DeploymentSpec{Replicas: int32Ptr(2)},
+					Status: v1beta1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 2},
+				},
+				"daemonsets":             &v1beta1.DaemonSet{Status: v1beta1.DaemonSetStatus{DesiredNumberScheduled: 1, NumberReady: 1}},
+				"replicationcontrollers": &v1.ReplicationController{Status: v1.ReplicationControllerStatus{ReadyReplicas: 1}},
+			},
+			expected:  []int32{1, 2, 1, 1, 1, 1},
+			readiness: NotReady,
+		},
+		{
+			description: "daemon set not scheduled",
+			live: map[string]runtime.Object{
+				"deployments":            &v1beta1.Deployment{Status: v1beta1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 1}},
+				"daemonsets":             &v1beta1.DaemonSet{},
+				"replicationcontrollers": &v1.ReplicationController{Status: v1.ReplicationControllerStatus{ReadyReplicas: 1}},
+			},
+			expected:  []int32{1, 1, 0, 0, 1, 1},
+			readiness: NotReady,
+		},
+		{
+			description: "none created",
+			expected:    []int32{0, 2, 0, 1, 0, 1},
+			readiness:   NotReady,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			fake := newFakeCluster()
+			for resource, obj := range test.live {
+				name := map[string]string{"deployments": "deployment", "daemonsets": "daemonset", "replicationcontrollers": "rc"}[resource]
+				fake.objects[key(resource, "kube-system", name)] = obj
+			}
+			workloads, err := fake.clients().Workloads(manifests)
+			if err != nil {
+				t.Fatalf("Unexpected error getting the workloads: %s", err)
+			}
+			// The missing replication controller keeps the replicas of its manifest.
+			expected := append(test.expected, 0, 3)
+			var got []int32
+			for _, w := range workloads {
+				got = append(got, w.Ready, w.Desired)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected ready and desired pods %v, got %v: %v", expected, got, workloads)
+			}
+			if readiness := Readiness(workloads); readiness != NotReady {
+				t.Errorf("Expected %s with a missing workload, got %s", NotReady, readiness)
+			}
+			if readiness := Readiness(workloads[:3]); readiness != test.readiness {
+				t.Errorf("Expected %s, got %s", test.readiness, readiness)
+			}
+		})
+	}
+
+	if readiness := Readiness(nil); readiness != "" {
+		t.Errorf("Expected no readiness without workloads, got %s", readiness)
+	}
+	fake := newFakeCluster()
+	fake.failures["daemonsets"] = errors.New("connection refused")
+	if _, err := fake.clients().Workloads(manifests); err == nil {
+		t.Error("Expected an error getting the workloads")
+	}
+}
+
+func TestWorkloadsOfAddons(t *testing.T) {
+	fake := newFakeCluster()
+	objs := objects(t, "ingress")
+	fake.clients().Apply(objs)
+	workloads, err := fake.clients().Workloads(objs)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the workloads: %s", err)
+	}
+	if len(workloads) != 2 || workloads[0].Name != "default-http-backend" || workloads[1].Name != "nginx-ingress-controller" {
+		t.Errorf("Expected the deployments of ingress, got %v", workloads)
+	}
+	if readiness := Readiness(workloads); readiness != NotReady {
+		t.Errorf("Expected the deployments without pods not ready, got %s", readiness)
+	}
+}
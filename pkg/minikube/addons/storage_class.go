@@ -0,0 +1,71 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	storage "k8s.io/client-go/pkg/apis/storage/v1"
+)
+
+// The annotations marking a storage class as the default one, which the DefaultStorageClass
+// admission plugin gives the claims without a class. It reads either.
+const (
+	defaultClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// IsDefaultClass returns whether the storage class is marked as the default one.
+func IsDefaultClass(class *storage.StorageClass) bool {
+	return class.Annotations[defaultClassAnnotation] == "true" || class.Annotations[betaDefaultClassAnnotation] == "true"
+}
+
+// UnsetOtherDefaultClasses marks the storage classes of the cluster which are default as not
+// default anymore, when objs have a default one of another name. The admission plugin rejects
+// the claims without a class while several are default.
+func (c *Clients) UnsetOtherDefaultClasses(objs []runtime.Object) ([]Result, error) {
+	defaults := map[string]bool{}
+	for _, obj := range objs {
+		if class, ok := obj.(*storage.StorageClass); ok && IsDefaultClass(class) {
+			defaults[class.Name] = true
+		}
+	}
+	if len(defaults) == 0 {
+		return nil, nil
+	}
+	i := c.Storage.StorageClasses()
+	list, err := i.List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing the storage classes")
+	}
+	var results []Result
+	for _, class := range list.Items {
+		if defaults[class.Name] || !IsDefaultClass(&class) {
+			continue
+		}
+		class := class
+		for _, a := range []string{defaultClassAnnotation, betaDefaultClassAnnotation} {
+			if _, ok := class.Annotations[a]; ok {
+				class.Annotations[a] = "false"
+			}
+		}
+		_, err := i.Update(&class)
+		results = append(results, Result{Kind: "storageclass", Name: class.Name, Action: NotDefault, Err: err})
+	}
+	return results, nil
+}
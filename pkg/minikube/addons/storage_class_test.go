@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	storage "k8s.io/client-go/pkg/apis/storage/v1"
+)
+
+func TestIsDefaultClass(t *testing.T) {
+	var tests = []struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		{annotations: nil},
+		{annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}, expected: true},
+		{annotations: map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "true"}, expected: true},
+		{annotations: map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "false"}},
+	}
+	for _, test := range tests {
+		class := &storage.StorageClass{ObjectMeta: meta_v1.ObjectMeta{Annotations: test.annotations}}
+		if got := IsDefaultClass(class); got != test.expected {
+			t.Errorf("Expected %t for the annotations %v, got %t", test.expected, test.annotations, got)
+		}
+	}
+}
+
+func TestUnsetOtherDefaultClasses(t *testing.T) {
+	class := func(name string, annotations map[string]string) *storage.StorageClass {
+		return &storage.StorageClass{ObjectMeta: meta_v1.ObjectMeta{Name: name, Annotations: annotations}, Provisioner: "example.com/" + name}
+	}
+	c := newFakeCluster()
+	clients := c.clients()
+	existing := []runtime.Object{
+		class("fast", map[string]string{"storageclass.kubernetes.io/is-default-class": "true", "owner": "me"}),
+		class("slow", map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "false"}),
+		class("old", map[string]string{"storageclass.beta.kubernetes.io/is-default-class": "true"}),
+	}
+	if Failed(clients.Apply(existing)) {
+		t.Fatal("Error creating the existing classes")
+	}
+	objs := objects(t, "storage-provisioner")
+	if Failed(clients.Apply(objs)) {
+		t.Fatal("Error applying storage-provisioner")
+	}
+
+	results, err := clients.UnsetOtherDefaultClasses(objs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []string{"storageclass fast no longer default", "storageclass old no longer default"}
+	if got := actions(results); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	defaults := []string{}
+	for _, name := range []string{"fast", "old", "slow", "standard"} {
+		stored := c.objects[key("storageclasses", "", name)].(*storage.StorageClass)
+		if IsDefaultClass(stored) {
+			defaults = append(defaults, name)
+		}
+	}
+	if !reflect.DeepEqual(defaults, []string{"standard"}) {
+		t.Errorf("Expected standard to be the only default class, got %v", defaults)
+	}
+	if owner := c.objects[key("storageclasses", "", "fast")].(*storage.StorageClass).Annotations["owner"]; owner != "me" {
+		t.Errorf("Expected the other annotations kept, got owner %q", owner)
+	}
+
+	// Addons without a default class leave the classes alone.
+	if results, err := clients.UnsetOtherDefaultClasses(objects(t, "dashboard")); err != nil || len(results) != 0 {
+		t.Errorf("Expected nothing done for dashboard, got %v, %v", results, err)
+	}
+
+	c.failures["storageclasses"] = errors.New("connection refused")
+	if _, err := clients.UnsetOtherDefaultClasses(objs); err == nil {
+		t.Error("Expected an error listing the classes")
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// ConfigKind is the kind of value a field of the configuration of an addon takes.
+type ConfigKind int
+
+const (
+	ConfigString ConfigKind = iota
+	// ConfigSize is the size of a volume, as in 10Gi.
+	ConfigSize
+	// ConfigDuration is a duration, as in 24h.
+	ConfigDuration
+	// ConfigFile is the path of a file on the host, whose contents are used.
+	ConfigFile
+	// ConfigPersistedDir is the path of a directory of the VM on its persisted disk.
+	ConfigPersistedDir
+)
+
+// ConfigField is a value of the configuration of an addon, set with minikube addons configure and kept
+// in the config as <addon>.<name>. The manifests of the addon get its value with {{ config "<name>" }}.
+type ConfigField struct {
+	Name        string
+	Description string
+	Kind        ConfigKind
+	// Default is the value of the field when it isn't configured, which for a file is its contents.
+	Default string
+	// Secret is the name of the secret, in kube-system, the value is kept in under Key, if any.
+	// The secret is created along with the addon, unless none of its values are set.
+	Secret string
+	Key    string
+}
+
+// Validate checks that value is a value the field takes. An empty value unsets the field.
+func (f ConfigField) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+	switch f.Kind {
+	case ConfigSize:
+		if q, err := resource.ParseQuantity(value); err != nil || q.Sign() <= 0 {
+			return fmt.Errorf("Not valid %s %q, a size such as 10Gi was expected", f.Name, value)
+		}
+	case ConfigDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("Not valid %s %q, a duration such as 24h was expected", f.Name, value)
+		}
+	case ConfigFile:
+		if _, err := ioutil.ReadFile(value); err != nil {
+			return fmt.Errorf("Not valid %s %q, the file can't be read: %s", f.Name, value, err)
+		}
+	case ConfigPersistedDir:
+		if !persisted(value) {
+			return fmt.Errorf("Not valid %s %q, a directory of the VM's persisted disk, under one of %s, was expected",
+				f.Name, value, strings.Join(constants.PersistedVMDirs, ", "))
+		}
+	}
+	return nil
+}
+
+// Parse validates value and returns it as it's kept in the config. The path of a file is kept absolute,
+// as the file is read again each time the addon is applied, from whichever directory minikube is run in.
+func (f ConfigField) Parse(value string) (string, error) {
+	if err := f.Validate(value); err != nil {
+		return "", err
+	}
+	if f.Kind != ConfigFile || value == "" {
+		return value, nil
+	}
+	abs, err := filepath.Abs(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting the absolute path of %s", value)
+	}
+	return abs, nil
+}
+
+// persisted returns whether the directory of the VM is on its persisted disk.
+func persisted(dir string) bool {
+	if !path.IsAbs(dir) {
+		return false
+	}
+	dir = path.Clean(dir)
+	for _, p := range constants.PersistedVMDirs {
+		if dir == p || strings.HasPrefix(dir, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigKey returns the key of the config the field of the addon is kept under.
+func ConfigKey(addon, field string) string {
+	return addon + "." + field
+}
+
+// withConfig sets the fields of the configuration of the addon.
+func (a *Addon) withConfig(fields ...ConfigField) *Addon {
+	a.Config = fields
+	return a
+}
+
+// Name returns the name of the addon.
+func (a *Addon) Name() string {
+	return a.addonName
+}
+
+// ConfigField returns the field of the configuration of the addon with the name.
+func (a *Addon) ConfigField(name string) (ConfigField, bool) {
+	for _, f := range a.Config {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return ConfigField{}, false
+}
+
+// ConfigValues returns the values of the fields of the addon kept in m, by name. Fields which aren't are left out.
+func (a *Addon) ConfigValues(m config.MinikubeConfig) map[string]string {
+	values := map[string]string{}
+	for _, f := range a.Config {
+		if v, ok := m[ConfigKey(a.addonName, f.Name)]; ok {
+			values[f.Name] = fmt.Sprintf("%v", v)
+		}
+	}
+	return values
+}
+
+// WithConfigValues returns a copy of the addon with the values of its configuration, by name, injected
+// in its manifests, and a manifest for each of the secrets its values are kept in ahead of them.
+// The fields the values don't set take their defaults.
+func (a *Addon) WithConfigValues(values map[string]string) (*Addon, error) {
+	resolved := map[string]string{}
+	for _, f := range a.Config {
+		v, ok := values[f.Name]
+		if !ok || v == "" {
+			resolved[f.Name] = f.Default
+			continue
+		}
+		if err := f.Validate(v); err != nil {
+			return nil, err
+		}
+		if f.Kind == ConfigFile {
+			data, err := ioutil.ReadFile(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error reading %s of addon %s", f.Name, a.addonName)
+			}
+			v = string(data)
+		}
+		resolved[f.Name] = v
+	}
+
+	secrets, err := a.secretAssets(resolved)
+	if err != nil {
+		return nil, err
+	}
+	funcs := template.FuncMap{
+		"config": func(name string) (string, error) {
+			v, ok := resolved[name]
+			if !ok {
+				return "", fmt.Errorf("Addon %s has no config field %s", a.addonName, name)
+			}
+			return v, nil
+		},
+	}
+	assets := secrets
+	for _, m := range a.Assets {
+		t, err := template.New(m.AssetName).Funcs(funcs).Parse(string(m.data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing the manifest %s", m.AssetName)
+		}
+		var b bytes.Buffer
+		if err := t.Execute(&b, nil); err != nil {
+			return nil, errors.Wrapf(err, "Error injecting the config of addon %s in %s", a.addonName, m.AssetName)
+		}
+		c := &MemoryAsset{BaseAsset: m.BaseAsset}
+		c.setData(b.Bytes())
+		assets = append(assets, c)
+	}
+	return NewAddon(assets, a.enabled, a.addonName).withConfig(a.Config...), nil
+}
+
+// secretAssets returns the manifests of the secrets the values of the fields are kept in, by name of secret.
+func (a *Addon) secretAssets(values map[string]string) ([]*MemoryAsset, error) {
+	data := map[string]map[string][]byte{}
+	for _, f := range a.Config {
+		if f.Secret == "" {
+			continue
+		}
+		if data[f.Secret] == nil {
+			data[f.Secret] = map[string][]byte{}
+		}
+		if v := values[f.Name]; v != "" {
+			data[f.Secret][f.Key] = []byte(v)
+		}
+	}
+	names := []string{}
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assets := []*MemoryAsset{}
+	for _, name := range names {
+		if len(data[name]) == 0 {
+			continue
+		}
+		secret := &v1.Secret{
+			TypeMeta: meta_v1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "kube-system",
+				Labels: map[string]string{
+					"kubernetes.io/minikube-addons":   a.addonName,
+					"addonmanager.kubernetes.io/mode": "Reconcile",
+				},
+			},
+			Data: data[name],
+		}
+		// A JSON manifest is a YAML one too.
+		manifest, err := json.MarshalIndent(secret, "", "  ")
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error generating the secret %s", name)
+		}
+		m := &MemoryAsset{BaseAsset{AssetName: name + "-secret.yaml", TargetDir: constants.AddonsPath, TargetName: name + "-secret.yaml", Permissions: "0640"}}
+		m.setData(manifest)
+		assets = append(assets, m)
+	}
+	return assets, nil
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestConfigFieldValidate(t *testing.T) {
+	file, err := ioutil.TempFile("", "addon-config")
+	if err != nil {
+		t.Fatalf("Error creating temp file: %s", err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	var tests = []struct {
+		kind      ConfigKind
+		value     string
+		shouldErr bool
+	}{
+		{kind: ConfigString, value: "anything"},
+		{kind: ConfigSize, value: "10Gi"},
+		{kind: ConfigSize, value: "500M"},
+		{kind: ConfigSize, value: "", shouldErr: false},
+		{kind: ConfigSize, value: "0", shouldErr: true},
+		{kind: ConfigSize, value: "10gb", shouldErr: true},
+		{kind: ConfigDuration, value: "24h"},
+		{kind: ConfigDuration, value: "7d", shouldErr: true},
+		{kind: ConfigFile, value: file.Name()},
+		{kind: ConfigFile, value: filepath.Join(os.TempDir(), "missing", "cert.pem"), shouldErr: true},
+		{kind: ConfigPersistedDir, value: "/tmp/hostpath-provisioner"},
+		{kind: ConfigPersistedDir, value: "/data/volumes/"},
+		{kind: ConfigPersistedDir, value: "/database", shouldErr: true},
+		{kind: ConfigPersistedDir, value: "/data/../etc", shouldErr: true},
+		{kind: ConfigPersistedDir, value: "data/volumes", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		err := ConfigField{Name: "field", Kind: test.kind}.Validate(test.value)
+		if err != nil && !test.shouldErr {
+			t.Errorf("%q: unexpected error %s", test.value, err)
+		}
+		if err == nil && test.shouldErr {
+			t.Errorf("%q: expected an error", test.value)
+		}
+	}
+}
+
+func TestConfigFieldParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addon-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error getting working directory: %s", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Error changing directory: %s", err)
+	}
+	defer os.Chdir(wd)
+	if err := ioutil.WriteFile("cert.pem", []byte("cert"), 0644); err != nil {
+		t.Fatalf("Error writing file: %s", err)
+	}
+	abs, err := filepath.Abs("cert.pem")
+	if err != nil {
+		t.Fatalf("Error getting absolute path: %s", err)
+	}
+
+	var tests = []struct {
+		kind     ConfigKind
+		value    string
+		expected string
+	}{
+		{kind: ConfigFile, value: "cert.pem", expected: abs},
+		{kind: ConfigFile, value: abs, expected: abs},
+		{kind: ConfigFile, value: "", expected: ""},
+		{kind: ConfigPersistedDir, value: "/data/volumes", expected: "/data/volumes"},
+		{kind: ConfigSize, value: "10Gi", expected: "10Gi"},
+	}
+
+	for _, test := range tests {
+		v, err := ConfigField{Name: "field", Kind: test.kind}.Parse(test.value)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", test.value, err)
+			continue
+		}
+		if v != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.value, test.expected, v)
+		}
+	}
+	if _, err := (ConfigField{Name: "field", Kind: ConfigFile}).Parse("missing.pem"); err == nil {
+		t.Errorf("expected an error parsing a missing file")
+	}
+}
+
+func TestConfigValues(t *testing.T) {
+	m := config.MinikubeConfig{"registry": true, "registry.storage-size": "20Gi", "registry.unknown": "x", "ingress.default-ssl-key": "/key.pem"}
+	values := Addons["registry"].ConfigValues(m)
+	if len(values) != 1 || values["storage-size"] != "20Gi" {
+		t.Errorf("Expected only the storage size of the registry, got %v", values)
+	}
+}
+
+func TestWithConfigValuesTemplates(t *testing.T) {
+	var tests = []struct {
+		description string
+		addon       string
+		values      map[string]string
+		expected    string
+		unexpected  string
+	}{
+		{
+			description: "default",
+			addon:       "registry",
+			expected:    "storage: 5Gi\n",
+		},
+		{
+			description: "set",
+			addon:       "registry",
+			values:      map[string]string{"storage-size": "20Gi"},
+			expected:    "storage: 20Gi\n",
+		},
+		{
+			description: "optional unset",
+			addon:       "heapster",
+			expected:    "--sink=influxdb:http://monitoring-influxdb:8086\n",
+			unexpected:  "retention",
+		},
+		{
+			description: "optional set",
+			addon:       "heapster",
+			values:      map[string]string{"retention": "24h"},
+			expected:    "--sink=influxdb:http://monitoring-influxdb:8086?retention=24h\n",
+		},
+		{
+			description: "directory default",
+			addon:       "storage-provisioner",
+			expected:    "path: /tmp/hostpath-provisioner\n",
+		},
+		{
+			description: "directory set",
+			addon:       "storage-provisioner",
+			values:      map[string]string{"directory": "/data/volumes"},
+			expected:    "--pv-dir=/data/volumes\n",
+		},
+		{
+			description: "without config",
+			addon:       "dashboard",
+			expected:    "kind: ReplicationController",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			a, err := Addons[test.addon].WithConfigValues(test.values)
+			if err != nil {
+				t.Fatalf("Unexpected error configuring %s: %s", test.addon, err)
+			}
+			var manifests []string
+			for _, m := range a.Assets {
+				manifests = append(manifests, string(m.Bytes()))
+			}
+			all := strings.Join(manifests, "---\n")
+			if strings.Contains(all, "{{") {
+				t.Errorf("Expected the templates expanded: %s", all)
+			}
+			if !strings.Contains(all, test.expected) {
+				t.Errorf("Expected %q in the manifests: %s", test.expected, all)
+			}
+			if test.unexpected != "" && strings.Contains(all, test.unexpected) {
+				t.Errorf("Expected no %q in the manifests: %s", test.unexpected, all)
+			}
+		})
+	}
+}
+
+func TestWithConfigValuesErrors(t *testing.T) {
+	if _, err := Addons["registry"].WithConfigValues(map[string]string{"storage-size": "big"}); err == nil {
+		t.Error("Expected an error configuring an invalid size")
+	}
+	a := NewAddon([]*MemoryAsset{{BaseAsset{AssetName: "unknown.yaml"}}}, false, "test")
+	a.Assets[0].setData([]byte(`value: {{ config "unknown" }}`))
+	if _, err := a.WithConfigValues(nil); err == nil {
+		t.Error("Expected an error injecting an unknown field")
+	}
+}
+
+func TestWithConfigValuesSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addon-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	cert, key := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ioutil.WriteFile(cert, []byte("certificate"), 0600)
+	ioutil.WriteFile(key, []byte("private key"), 0600)
+
+	var tests = []struct {
+		description string
+		addon       string
+		values      map[string]string
+		expected    map[string]map[string]string
+	}{
+		{
+			description: "none set",
+			addon:       "ingress",
+			expected:    map[string]map[string]string{},
+		},
+		{
+			description: "files",
+			addon:       "ingress",
+			values:      map[string]string{"default-ssl-certificate": cert, "default-ssl-key": key},
+			expected: map[string]map[string]string{
+				"ingress-default-cert": {"tls.crt": "certificate", "tls.key": "private key"},
+			},
+		},
+		{
+			description: "defaults",
+			addon:       "registry-creds",
+			values:      map[string]string{"aws-region": "eu-west-1", "docker-password": "secret"},
+			expected: map[string]map[string]string{
+				"registry-creds-dpr": {"DOCKER_PRIVATE_REGISTRY_SERVER": "changeme", "DOCKER_PRIVATE_REGISTRY_USER": "changeme", "DOCKER_PRIVATE_REGISTRY_PASSWORD": "secret"},
+				"registry-creds-ecr": {"AWS_ACCESS_KEY_ID": "changeme", "AWS_SECRET_ACCESS_KEY": "changeme", "aws-region": "eu-west-1", "aws-account": "changeme"},
+				"registry-creds-gcr": {"application_default_credentials.json": "changeme"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			a, err := Addons[test.addon].WithConfigValues(test.values)
+			if err != nil {
+				t.Fatalf("Unexpected error configuring %s: %s", test.addon, err)
+			}
+			secrets := map[string]map[string]string{}
+			for _, m := range a.Assets {
+				if !strings.HasSuffix(m.GetTargetName(), "-secret.yaml") {
+					continue
+				}
+				var s v1.Secret
+				if err := json.Unmarshal(m.Bytes(), &s); err != nil {
+					t.Fatalf("Error decoding the secret %s: %s", m.GetTargetName(), err)
+				}
+				if s.Kind != "Secret" || s.Namespace != "kube-system" || s.Labels["kubernetes.io/minikube-addons"] != test.addon {
+					t.Errorf("Expected a secret of %s in kube-system, got %+v", test.addon, s.ObjectMeta)
+				}
+				data := map[string]string{}
+				for k, v := range s.Data {
+					data[k] = string(v)
+				}
+				secrets[s.Name] = data
+			}
+			if len(secrets) != len(test.expected) {
+				t.Fatalf("Expected the secrets %v, got %v", test.expected, secrets)
+			}
+			for name, data := range test.expected {
+				for k, v := range data {
+					if secrets[name][k] != v {
+						t.Errorf("Expected %s of secret %s to be %q, got %q", k, name, v, secrets[name][k])
+					}
+				}
+			}
+			if test.addon == "ingress" {
+				hasArg := strings.Contains(string(a.Assets[len(a.Assets)-2].Bytes()), "--default-ssl-certificate=$(POD_NAMESPACE)/ingress-default-cert")
+				if hasArg != (len(test.expected) > 0) {
+					t.Errorf("Expected the default certificate argument %t, got %t", len(test.expected) > 0, hasArg)
+				}
+			}
+		})
+	}
+}
@@ -28,7 +28,9 @@ import (
 )
 
 type Addon struct {
-	Assets    []*MemoryAsset
+	Assets []*MemoryAsset
+	// Config are the fields of the configuration of the addon, which minikube addons configure sets.
+	Config    []ConfigField
 	enabled   bool
 	addonName string
 }
@@ -54,6 +56,16 @@ func (a *Addon) IsEnabled() (bool, error) {
 	return a.enabled, nil
 }
 
+// WithContents returns a copy of the addon with the contents of each asset replaced by what rewrite returns for them.
+func (a *Addon) WithContents(rewrite func(data []byte) []byte) *Addon {
+	assets := make([]*MemoryAsset, len(a.Assets))
+	for i, m := range a.Assets {
+		assets[i] = &MemoryAsset{BaseAsset: m.BaseAsset}
+		assets[i].setData(rewrite(m.data))
+	}
+	return NewAddon(assets, a.enabled, a.addonName).withConfig(a.Config...)
+}
+
 var Addons = map[string]*Addon{
 	"addon-manager": NewAddon([]*MemoryAsset{
 		NewMemoryAsset(
@@ -81,6 +93,20 @@ var Addons = map[string]*Addon{
 			"storageclass.yaml",
 			"0640"),
 	}, true, "default-storageclass"),
+	"storage-provisioner": NewAddon([]*MemoryAsset{
+		NewMemoryAsset(
+			"deploy/addons/storage-provisioner/storage-provisioner.yaml",
+			constants.AddonsPath,
+			"storage-provisioner.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/storage-provisioner/storageclass.yaml",
+			constants.AddonsPath,
+			"storage-provisioner-class.yaml",
+			"0640"),
+	}, false, "storage-provisioner").withConfig(
+		ConfigField{Name: "directory", Description: "Directory of the VM the volumes are provisioned in, on its persisted disk", Kind: ConfigPersistedDir, Default: constants.DefaultStorageProvisionerDir},
+	),
 	"kube-dns": NewAddon([]*MemoryAsset{
 		NewMemoryAsset(
 			"deploy/addons/kube-dns/kube-dns-controller.yaml",
@@ -124,31 +150,94 @@ var Addons = map[string]*Addon{
 			constants.AddonsPath,
 			"heapster-svc.yaml",
 			"0640"),
-	}, false, "heapster"),
+	}, false, "heapster").withConfig(
+		ConfigField{Name: "retention", Description: "How long InfluxDB keeps the metrics, as in 24h, forever by default", Kind: ConfigDuration},
+	),
+	"metrics": NewAddon([]*MemoryAsset{
+		NewMemoryAsset(
+			"deploy/addons/metrics/metrics-collector.yaml",
+			constants.AddonsPath,
+			"metrics-collector.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/metrics/influxdb.yaml",
+			constants.AddonsPath,
+			"metrics-influxdb.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/metrics/grafana.yaml",
+			constants.AddonsPath,
+			"metrics-grafana.yaml",
+			"0640"),
+	}, false, "metrics").withConfig(
+		ConfigField{Name: "retention", Description: "How long InfluxDB keeps the metrics, as in 24h, forever by default", Kind: ConfigDuration},
+	),
 	"ingress": NewAddon([]*MemoryAsset{
+		NewMemoryAsset(
+			"deploy/addons/ingress/ingress-rbac.yaml",
+			constants.AddonsPath,
+			"ingress-rbac.yaml",
+			"0640"),
 		NewMemoryAsset(
 			"deploy/addons/ingress/ingress-configmap.yaml",
 			constants.AddonsPath,
 			"ingress-configmap.yaml",
 			"0640"),
 		NewMemoryAsset(
-			"deploy/addons/ingress/ingress-rc.yaml",
+			"deploy/addons/ingress/ingress-dp.yaml",
 			constants.AddonsPath,
-			"ingress-rc.yaml",
+			"ingress-dp.yaml",
 			"0640"),
 		NewMemoryAsset(
 			"deploy/addons/ingress/ingress-svc.yaml",
 			constants.AddonsPath,
 			"ingress-svc.yaml",
 			"0640"),
-	}, false, "ingress"),
+	}, false, "ingress").withConfig(
+		ConfigField{Name: "default-ssl-certificate", Description: "Path of the certificate of the hosts without one of their own", Kind: ConfigFile, Secret: "ingress-default-cert", Key: "tls.crt"},
+		ConfigField{Name: "default-ssl-key", Description: "Path of the key of that certificate", Kind: ConfigFile, Secret: "ingress-default-cert", Key: "tls.key"},
+	),
+	"registry": NewAddon([]*MemoryAsset{
+		NewMemoryAsset(
+			"deploy/addons/registry/registry-pvc.yaml",
+			constants.AddonsPath,
+			"registry-pvc.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/registry/registry-rc.yaml",
+			constants.AddonsPath,
+			"registry-rc.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/registry/registry-svc.yaml",
+			constants.AddonsPath,
+			"registry-svc.yaml",
+			"0640"),
+		NewMemoryAsset(
+			"deploy/addons/registry/registry-proxy.yaml",
+			constants.AddonsPath,
+			"registry-proxy.yaml",
+			"0640"),
+	}, false, "registry").withConfig(
+		ConfigField{Name: "storage-size", Description: "Size of the volume the registry stores its images in", Kind: ConfigSize, Default: constants.DefaultRegistryStorageSize},
+	),
 	"registry-creds": NewAddon([]*MemoryAsset{
 		NewMemoryAsset(
 			"deploy/addons/registry-creds/registry-creds-rc.yaml",
 			constants.AddonsPath,
 			"registry-creds-rc.yaml",
 			"0640"),
-	}, false, "registry-creds"),
+	}, false, "registry-creds").withConfig(
+		ConfigField{Name: "aws-access-key-id", Description: "AWS Access Key ID", Default: "changeme", Secret: "registry-creds-ecr", Key: "AWS_ACCESS_KEY_ID"},
+		ConfigField{Name: "aws-secret-access-key", Description: "AWS Secret Access Key", Default: "changeme", Secret: "registry-creds-ecr", Key: "AWS_SECRET_ACCESS_KEY"},
+		ConfigField{Name: "aws-region", Description: "AWS Region", Default: "changeme", Secret: "registry-creds-ecr", Key: "aws-region"},
+		ConfigField{Name: "aws-account", Description: "12 digit AWS Account ID", Default: "changeme", Secret: "registry-creds-ecr", Key: "aws-account"},
+		ConfigField{Name: "gcr-credentials", Description: "Path of the Google Container Registry credentials, e.g. /home/user/.config/gcloud/application_default_credentials.json",
+			Kind: ConfigFile, Default: "changeme", Secret: "registry-creds-gcr", Key: "application_default_credentials.json"},
+		ConfigField{Name: "docker-server", Description: "Docker registry server url", Default: "changeme", Secret: "registry-creds-dpr", Key: "DOCKER_PRIVATE_REGISTRY_SERVER"},
+		ConfigField{Name: "docker-user", Description: "Docker registry username", Default: "changeme", Secret: "registry-creds-dpr", Key: "DOCKER_PRIVATE_REGISTRY_USER"},
+		ConfigField{Name: "docker-password", Description: "Docker registry password", Default: "changeme", Secret: "registry-creds-dpr", Key: "DOCKER_PRIVATE_REGISTRY_PASSWORD"},
+	),
 }
 
 func AddMinikubeAddonsDirToAssets(assetList *[]CopyableFile) {
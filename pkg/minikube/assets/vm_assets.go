@@ -125,12 +125,21 @@ func (m *MemoryAsset) loadData() error {
 	if err != nil {
 		return err
 	}
-	m.data = contents
-	m.Length = len(contents)
-	m.reader = bytes.NewReader(m.data)
+	m.setData(contents)
 	return nil
 }
 
+func (m *MemoryAsset) setData(data []byte) {
+	m.data = data
+	m.Length = len(data)
+	m.reader = bytes.NewReader(m.data)
+}
+
+// Bytes returns the contents of the asset.
+func (m *MemoryAsset) Bytes() []byte {
+	return m.data
+}
+
 func (m *MemoryAsset) GetLength() int {
 	return m.Length
 }
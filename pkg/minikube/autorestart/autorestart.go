@@ -0,0 +1,195 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package autorestart installs a user-level unit, in each platform's own service manager,
+// which starts a minikube VM again when the user logs in after the host rebooted.
+package autorestart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Unit starts the cluster of a profile at login, with the minikube binary it was installed with.
+type Unit struct {
+	Profile string
+	Binary  string
+}
+
+// NewUnit returns the unit of the given profile, which runs the minikube binary being run.
+func NewUnit(profile string) (Unit, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return Unit{}, errors.Wrap(err, "Error getting the path of the minikube binary")
+	}
+	return Unit{Profile: profile, Binary: binary}, nil
+}
+
+// Name is the name of the unit, which is unique to its profile.
+func (u Unit) Name() string {
+	return "minikube-" + u.Profile
+}
+
+// Args are the arguments the unit runs the minikube binary with. Only cached files are used,
+// as the network may not be up yet at login.
+func (u Unit) Args() []string {
+	return []string{"start", "--profile", u.Profile, "--offline"}
+}
+
+// Install installs the unit, replacing any unit of the same profile.
+func Install(u Unit) error {
+	if err := install(u); err != nil {
+		return errors.Wrapf(err, "Error installing %s", u.Name())
+	}
+	return nil
+}
+
+// Uninstall removes the unit of the named profile. It does nothing if there is none.
+func Uninstall(profile string) error {
+	u := Unit{Profile: profile}
+	if err := uninstall(u); err != nil {
+		return errors.Wrapf(err, "Error removing %s", u.Name())
+	}
+	return nil
+}
+
+const systemdTmpl = `[Unit]
+Description=Starts the minikube VM of profile {{.Profile}}
+
+[Service]
+Type=oneshot
+ExecStart={{systemdQuote .Binary}}{{range .Args}} {{systemdQuote .}}{{end}}
+
+[Install]
+WantedBy=default.target
+`
+
+const launchAgentTmpl = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Binary}}</string>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`
+
+const scheduledTaskTmpl = `<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>Starts the minikube VM of profile {{xml .Profile}}</Description>
+  </RegistrationInfo>
+  <Triggers>
+    <LogonTrigger>
+      <Enabled>true</Enabled>
+    </LogonTrigger>
+  </Triggers>
+  <Settings>
+    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+    <ExecutionTimeLimit>PT1H</ExecutionTimeLimit>
+  </Settings>
+  <Actions Context="Author">
+    <Exec>
+      <Command>{{xml .Binary}}</Command>
+      <Arguments>{{xml .Arguments}}</Arguments>
+    </Exec>
+  </Actions>
+</Task>
+`
+
+var templateFuncs = template.FuncMap{
+	"xml":          escapeXML,
+	"systemdQuote": systemdQuote,
+}
+
+func escapeXML(s string) (string, error) {
+	var b bytes.Buffer
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// systemdQuote quotes an argument of a systemd command line, in which % starts a specifier.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(s)
+	return `"` + s + `"`
+}
+
+// windowsQuote quotes an argument of a Windows command line.
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+// launchAgentLabel is the label of the unit's LaunchAgent, and the name of its plist.
+func launchAgentLabel(u Unit) string {
+	return "io.k8s.minikube." + u.Profile
+}
+
+// systemdUnit generates the systemd user service of the unit.
+func systemdUnit(u Unit) (string, error) {
+	return execTemplate("systemd", systemdTmpl, u)
+}
+
+// launchAgent generates the LaunchAgent plist of the unit.
+func launchAgent(u Unit) (string, error) {
+	return execTemplate("launchAgent", launchAgentTmpl, struct {
+		Unit
+		Label string
+	}{u, launchAgentLabel(u)})
+}
+
+// scheduledTask generates the Task Scheduler definition of the unit.
+func scheduledTask(u Unit) (string, error) {
+	var args []string
+	for _, a := range u.Args() {
+		args = append(args, windowsQuote(a))
+	}
+	return execTemplate("scheduledTask", scheduledTaskTmpl, struct {
+		Unit
+		Arguments string
+	}{u, strings.Join(args, " ")})
+}
+
+func execTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error parsing %s template", name)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errors.Wrapf(err, "Error executing %s template", name)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autorestart
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/util/homedir"
+)
+
+func unitPath(u Unit) string {
+	return filepath.Join(homedir.HomeDir(), "Library", "LaunchAgents", launchAgentLabel(u)+".plist")
+}
+
+// install writes the unit's LaunchAgent, which launchd loads at login. It isn't
+// loaded now, as that would start the VM while minikube start is starting it.
+func install(u Unit) error {
+	plist, err := launchAgent(u)
+	if err != nil {
+		return err
+	}
+	path := unitPath(u)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(plist), 0644)
+}
+
+func uninstall(u Unit) error {
+	path := unitPath(u)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	// The agent is only loaded since the last login.
+	if out, err := exec.Command("launchctl", "unload", path).CombinedOutput(); err != nil {
+		glog.Infof("Not unloading %s: %s: %s", path, err, out)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autorestart
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+)
+
+func unitPath(u Unit) string {
+	return filepath.Join(homedir.HomeDir(), ".config", "systemd", "user", u.Name()+".service")
+}
+
+func systemctl(args ...string) error {
+	args = append([]string{"--user"}, args...)
+	if out, err := exec.Command("systemctl", args...).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "Error running systemctl %v: %s", args, out)
+	}
+	return nil
+}
+
+// install writes and enables the unit's systemd user service, which systemd starts at login.
+func install(u Unit) error {
+	unit, err := systemdUnit(u)
+	if err != nil {
+		return err
+	}
+	path := unitPath(u)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, []byte(unit), 0644); err != nil {
+		return err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", u.Name()+".service")
+}
+
+func uninstall(u Unit) error {
+	path := unitPath(u)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if err := systemctl("disable", u.Name()+".service"); err != nil {
+		glog.Warningf("Removing %s anyway: %s", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autorestart
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testUnit = Unit{Profile: "dev", Binary: `/opt/tools & "more"/100%/minikube`}
+
+// parseXML checks that s is well-formed XML, whatever encoding it declares.
+func parseXML(t *testing.T, s string) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Error parsing generated XML: %s\n%s", err, s)
+		}
+	}
+}
+
+func TestUnitGeneration(t *testing.T) {
+	var tests = []struct {
+		description string
+		generate    func(Unit) (string, error)
+		isXML       bool
+		expected    []string
+	}{
+		{
+			description: "systemd user service",
+			generate:    systemdUnit,
+			expected: []string{
+				`ExecStart="/opt/tools & \"more\"/100%%/minikube" "start" "--profile" "dev" "--offline"`,
+				"WantedBy=default.target",
+			},
+		},
+		{
+			description: "launch agent",
+			generate:    launchAgent,
+			isXML:       true,
+			expected: []string{
+				"<string>io.k8s.minikube.dev</string>",
+				"<string>/opt/tools &amp; &#34;more&#34;/100%/minikube</string>",
+				"<string>--profile</string>\n\t\t<string>dev</string>\n\t\t<string>--offline</string>",
+				"<key>RunAtLoad</key>\n\t<true/>",
+			},
+		},
+		{
+			description: "scheduled task",
+			generate:    scheduledTask,
+			isXML:       true,
+			expected: []string{
+				"<LogonTrigger>",
+				"<Command>/opt/tools &amp; &#34;more&#34;/100%/minikube</Command>",
+				"<Arguments>start --profile dev --offline</Arguments>",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			out, err := test.generate(testUnit)
+			if err != nil {
+				t.Fatalf("Error generating unit: %s", err)
+			}
+			if test.isXML {
+				parseXML(t, out)
+			}
+			for _, e := range test.expected {
+				if !strings.Contains(out, e) {
+					t.Errorf("Expected the unit to contain %q, got:\n%s", e, out)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowsQuote(t *testing.T) {
+	var tests = []struct {
+		arg      string
+		expected string
+	}{
+		{arg: "--offline", expected: "--offline"},
+		{arg: "my profile", expected: `"my profile"`},
+		{arg: `say "hi"`, expected: `"say \"hi\""`},
+		{arg: "", expected: `""`},
+	}
+
+	for _, test := range tests {
+		if got := windowsQuote(test.arg); got != test.expected {
+			t.Errorf("Expected %s to be quoted as %s, got %s", test.arg, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autorestart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+func schtasks(args ...string) (string, error) {
+	out, err := exec.Command("schtasks", args...).CombinedOutput()
+	if err != nil {
+		return string(out), errors.Wrapf(err, "Error running schtasks %v: %s", args, out)
+	}
+	return string(out), nil
+}
+
+// install creates the unit's scheduled task, which runs at logon.
+func install(u Unit) error {
+	task, err := scheduledTask(u)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile("", "minikube-task")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	// The definition declares itself UTF-16, which schtasks insists on.
+	_, err = f.Write(encodeUTF16(task))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	_, err = schtasks("/Create", "/TN", u.Name(), "/XML", f.Name(), "/F")
+	return err
+}
+
+func uninstall(u Unit) error {
+	if _, err := schtasks("/Query", "/TN", u.Name()); err != nil {
+		// There is no such task.
+		return nil
+	}
+	_, err := schtasks("/Delete", "/TN", u.Name(), "/F")
+	return err
+}
+
+// encodeUTF16 encodes s as little-endian UTF-16, with a byte order mark.
+func encodeUTF16(s string) []byte {
+	var b bytes.Buffer
+	s = strings.Replace(s, "\n", "\r\n", -1)
+	binary.Write(&b, binary.LittleEndian, utf16.Encode([]rune("\ufeff"+s)))
+	return b.Bytes()
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+
+	"github.com/golang/glog"
+)
+
+// APIServerSANs are the names and IPs added to the apiserver certificate, for
+// reaching the apiserver through other addresses than the VM's IP.
+type APIServerSANs struct {
+	Names []string `json:",omitempty"`
+	IPs   []net.IP `json:",omitempty"`
+}
+
+// ParseAPIServerSANs checks the names and IPs of the flags of start.
+func ParseAPIServerSANs(names, ips []string) (APIServerSANs, error) {
+	sans := APIServerSANs{}
+	for _, name := range names {
+		if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			return sans, fmt.Errorf("Invalid apiserver name %q, names can't be empty or contain spaces", name)
+		}
+		if net.ParseIP(name) != nil {
+			return sans, fmt.Errorf("Invalid apiserver name %q, pass IPs with --apiserver-ips", name)
+		}
+		sans.Names = append(sans.Names, name)
+	}
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return sans, fmt.Errorf("Invalid apiserver IP %q", s)
+		}
+		sans.IPs = append(sans.IPs, ip)
+	}
+	return sans, nil
+}
+
+// StoredAPIServerSANs returns the extra names and IPs the named machine's
+// apiserver certificate was last generated with, which starts keep unless they
+// are given others.
+func StoredAPIServerSANs(name string) APIServerSANs {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not using the apiserver names and IPs of %s: %s", name, err)
+	}
+	return s.APIServerSANs
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestParseAPIServerSANs(t *testing.T) {
+	var cases = []struct {
+		description string
+		names       []string
+		ips         []string
+		expected    APIServerSANs
+		shouldErr   bool
+	}{
+		{
+			description: "none",
+		},
+		{
+			description: "names and IPs",
+			names:       []string{"bastion.example.com", "*.dev.example.com"},
+			ips:         []string{"10.0.0.5", "fd00::5"},
+			expected: APIServerSANs{
+				Names: []string{"bastion.example.com", "*.dev.example.com"},
+				IPs:   []net.IP{net.ParseIP("10.0.0.5"), net.ParseIP("fd00::5")},
+			},
+		},
+		{
+			description: "name with a space",
+			names:       []string{"bastion example.com"},
+			shouldErr:   true,
+		},
+		{
+			description: "empty name",
+			names:       []string{""},
+			shouldErr:   true,
+		},
+		{
+			description: "IP as a name",
+			names:       []string{"10.0.0.5"},
+			shouldErr:   true,
+		},
+		{
+			description: "malformed IP",
+			ips:         []string{"10.0.0.256"},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			sans, err := ParseAPIServerSANs(test.names, test.ips)
+			if test.shouldErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", sans)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(sans, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, sans)
+			}
+		})
+	}
+}
+
+func TestGenerateCertsAPIServerSANs(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sans, err := ParseAPIServerSANs([]string{"bastion.example.com"}, []string{"10.0.0.5"})
+	if err != nil {
+		t.Fatalf("Error parsing SANs: %s", err)
+	}
+	cert := filepath.Join(tempDir, "apiserver.crt")
+	err = GenerateCerts(filepath.Join(tempDir, "ca.crt"), filepath.Join(tempDir, "ca.key"), cert, filepath.Join(tempDir, "apiserver.key"),
+		net.ParseIP("192.168.99.100"), constants.APIServerName, sans)
+	if err != nil {
+		t.Fatalf("Error generating certs: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(cert)
+	if err != nil {
+		t.Fatalf("Error reading certificate: %s", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("No PEM certificate in %s", data)
+	}
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %s", err)
+	}
+	for _, name := range []string{"bastion.example.com", "kubernetes.default"} {
+		if err := c.VerifyHostname(name); err != nil {
+			t.Errorf("Expected the certificate to be valid for %s: %s", name, err)
+		}
+	}
+	for _, ip := range []string{"10.0.0.5", "192.168.99.100", "127.0.0.1"} {
+		if err := c.VerifyHostname(ip); err != nil {
+			t.Errorf("Expected the certificate to be valid for %s: %s", ip, err)
+		}
+	}
+}
+
+func TestStoredAPIServerSANs(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+
+	if sans := StoredAPIServerSANs(name); len(sans.Names) != 0 || len(sans.IPs) != 0 {
+		t.Errorf("Expected no SANs before the first start, got %+v", sans)
+	}
+	sans := APIServerSANs{Names: []string{"bastion.example.com"}, IPs: []net.IP{net.ParseIP("10.0.0.5")}}
+	recordKubernetesConfig(name, KubernetesConfig{APIServerSANs: sans})
+	// A later start which fails keeps them.
+	recordStartState(name, PhaseHostRunning, os.ErrNotExist)
+
+	if stored := StoredAPIServerSANs(name); !reflect.DeepEqual(stored, sans) {
+		t.Errorf("Expected the SANs %+v to be kept, got %+v", sans, stored)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// auditLogExistsCommand fails unless the apiserver audits requests.
+var auditLogExistsCommand = "sudo test -f " + constants.RemoteAuditLogPath
+
+// auditPolicyAsset returns the audit policy at the host path, to copy into the VM where localkube reads it.
+func auditPolicyAsset(policy string) (assets.CopyableFile, error) {
+	asset, err := assets.NewFileAsset(policy, path.Dir(constants.RemoteAuditPolicyPath), path.Base(constants.RemoteAuditPolicyPath), "0644")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading the audit policy %s", policy)
+	}
+	return asset, nil
+}
+
+// auditPolicyChecksum returns the checksum of the audit policy at the host path, if there is one.
+func auditPolicyChecksum(policy string) string {
+	if policy == "" {
+		return ""
+	}
+	data, err := ioutil.ReadFile(policy)
+	if err != nil {
+		glog.Warningf("Error reading the audit policy %s: %s", policy, err)
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// GetAuditLogsCommand returns the command printing the apiserver's audit log, and following it.
+func GetAuditLogsCommand(follow bool) string {
+	if follow {
+		return "sudo tail -F " + constants.RemoteAuditLogPath
+	}
+	return "sudo tail -n +1 " + constants.RemoteAuditLogPath
+}
+
+// GetAuditLogs returns the requests the apiserver audited, or follows them.
+func GetAuditLogs(api libmachine.API, follow bool) (string, error) {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return "", errors.Wrap(err, "Error checking that api exists and loading it")
+	}
+	if _, err := RunCommand(h, auditLogExistsCommand, true); err != nil {
+		return "", errors.Errorf("There is no audit log at %s, start the cluster with --audit-policy to audit the requests to the apiserver", constants.RemoteAuditLogPath)
+	}
+	return runLogsCommand(h, GetAuditLogsCommand(follow), follow)
+}
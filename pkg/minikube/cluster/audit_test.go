@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKubernetesConfigFingerprintAuditPolicy(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	policy := filepath.Join(tempDir, "policy.yaml")
+	writePolicy := func(level string) {
+		data := "apiVersion: audit.k8s.io/v1alpha1\nkind: Policy\nrules:\n- level: " + level + "\n"
+		if err := ioutil.WriteFile(policy, []byte(data), 0644); err != nil {
+			t.Fatalf("Error writing the audit policy: %s", err)
+		}
+	}
+
+	k := KubernetesConfig{KubernetesVersion: "v1.6.4", AuditPolicy: policy}
+	writePolicy("Metadata")
+	before := kubernetesConfigFingerprint(k)
+	if again := kubernetesConfigFingerprint(k); again != before {
+		t.Errorf("Expected the same fingerprint for the same policy, got %s and %s", before, again)
+	}
+	writePolicy("RequestResponse")
+	if after := kubernetesConfigFingerprint(k); after == before {
+		t.Errorf("Expected editing the audit policy to change the fingerprint %s", before)
+	}
+	if without := kubernetesConfigFingerprint(KubernetesConfig{KubernetesVersion: "v1.6.4"}); without == before {
+		t.Errorf("Expected the audit policy to change the fingerprint %s", before)
+	}
+}
+
+func TestGetAuditLogsCommand(t *testing.T) {
+	if cmd := GetAuditLogsCommand(false); cmd != "sudo tail -n +1 /var/lib/localkube/audit/audit.log" {
+		t.Errorf("Unexpected command printing the audit log: %s", cmd)
+	}
+	if cmd := GetAuditLogsCommand(true); cmd != "sudo tail -F /var/lib/localkube/audit/audit.log" {
+		t.Errorf("Unexpected command following the audit log: %s", cmd)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+)
+
+// suppliedCAMarker records that the CA was supplied with --ca-cert and --ca-key,
+// so minikube never replaces it with one it generates.
+const suppliedCAMarker = "ca.supplied"
+
+// CertPath returns the path of the named certificate or key of the current profile's cluster.
+func CertPath(name string) string {
+	return constants.MakeProfilePath(cfg.GetMachineName(), name)
+}
+
+// ValidateCA checks that the certificate at certPath can sign other certificates,
+// and that the key at keyPath is its private key.
+func ValidateCA(certPath, keyPath string) error {
+	cert, err := loadCert(certPath)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the CA certificate")
+	}
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return fmt.Errorf("The certificate %s is not a CA: it lacks the CA basic constraint", certPath)
+	}
+	key, err := util.ReadPrivateKey(keyPath)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the CA key")
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(key.N) != 0 || pub.E != key.E {
+		return fmt.Errorf("The key %s doesn't match the CA certificate %s", keyPath, certPath)
+	}
+	return nil
+}
+
+// InstallCA validates the CA at certPath and keyPath, and copies it to where the
+// cluster's certificates are generated, replacing the CA there. Setting up the
+// certs then signs the apiserver certificate with it. It returns whether the CA
+// there was a different one.
+func InstallCA(certPath, keyPath string) (bool, error) {
+	if err := ValidateCA(certPath, keyPath); err != nil {
+		return false, err
+	}
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error reading %s", certPath)
+	}
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error reading %s", keyPath)
+	}
+	old, _ := ioutil.ReadFile(CertPath("ca.crt"))
+	changed := !bytes.Equal(old, cert)
+	if err := os.MkdirAll(filepath.Dir(CertPath("ca.crt")), 0755); err != nil {
+		return false, errors.Wrap(err, "Error creating the certificates directory")
+	}
+	if err := ioutil.WriteFile(CertPath("ca.crt"), cert, 0644); err != nil {
+		return false, errors.Wrap(err, "Error writing the CA certificate")
+	}
+	if err := ioutil.WriteFile(CertPath("ca.key"), key, 0600); err != nil {
+		return false, errors.Wrap(err, "Error writing the CA key")
+	}
+	if err := ioutil.WriteFile(CertPath(suppliedCAMarker), nil, 0644); err != nil {
+		return false, errors.Wrap(err, "Error recording the supplied CA")
+	}
+	if changed {
+		glog.Infof("Using the CA %s to sign the cluster's certificates", certPath)
+	}
+	return changed, nil
+}
+
+// caSupplied returns whether the cluster's CA was supplied with --ca-cert and --ca-key.
+func caSupplied() bool {
+	_, err := os.Stat(CertPath(suppliedCAMarker))
+	return err == nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+)
+
+// writePKCS8Key writes the PKCS #1 key at keyPath to path in PKCS #8 form.
+func writePKCS8Key(t *testing.T, keyPath, path string) {
+	key, err := util.ReadPrivateKey(keyPath)
+	if err != nil {
+		t.Fatalf("Error reading key: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error encoding key: %s", err)
+	}
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Error writing key: %s", err)
+	}
+}
+
+func TestValidateCA(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	cert := writeCert(t, filepath.Join(tempDir, "a"), "192.168.99.100")
+	writeCert(t, filepath.Join(tempDir, "b"), "192.168.99.100")
+	writePKCS8Key(t, filepath.Join(tempDir, "a", "ca.key"), filepath.Join(tempDir, "a", "ca.pkcs8.key"))
+
+	var cases = []struct {
+		description string
+		cert, key   string
+		shouldErr   bool
+	}{
+		{
+			description: "CA and its key",
+			cert:        filepath.Join(tempDir, "a", "ca.crt"),
+			key:         filepath.Join(tempDir, "a", "ca.key"),
+		},
+		{
+			description: "PKCS #8 key",
+			cert:        filepath.Join(tempDir, "a", "ca.crt"),
+			key:         filepath.Join(tempDir, "a", "ca.pkcs8.key"),
+		},
+		{
+			description: "another CA's key",
+			cert:        filepath.Join(tempDir, "a", "ca.crt"),
+			key:         filepath.Join(tempDir, "b", "ca.key"),
+			shouldErr:   true,
+		},
+		{
+			description: "not a CA",
+			cert:        cert,
+			key:         filepath.Join(tempDir, "a", "apiserver.key"),
+			shouldErr:   true,
+		},
+		{
+			description: "missing key",
+			cert:        filepath.Join(tempDir, "a", "ca.crt"),
+			key:         filepath.Join(tempDir, "missing.key"),
+			shouldErr:   true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateCA(test.cert, test.key)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+// verifyChain checks that the certificate at certPath is signed by the CA at caPath.
+func verifyChain(t *testing.T, certPath, caPath string) {
+	cert, err := loadCert(certPath)
+	if err != nil {
+		t.Fatalf("Error loading certificate: %s", err)
+	}
+	ca, err := loadCert(caPath)
+	if err != nil {
+		t.Fatalf("Error loading CA: %s", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Errorf("Expected %s to chain to %s: %s", certPath, caPath, err)
+	}
+}
+
+func TestInstallCA(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	caDir := filepath.Join(tempDir, "internal")
+	writeCert(t, caDir, "10.0.0.1")
+	suppliedCert, suppliedKey := filepath.Join(caDir, "ca.crt"), filepath.Join(caDir, "ca.key")
+
+	// A CA minikube generated is replaced.
+	caCert, caKey := constants.MakeMiniPath("ca.crt"), constants.MakeMiniPath("ca.key")
+	if err := util.GenerateCACert(caCert, caKey, constants.APIServerName); err != nil {
+		t.Fatalf("Error generating CA: %s", err)
+	}
+	changed, err := InstallCA(suppliedCert, suppliedKey)
+	if err != nil {
+		t.Fatalf("Error installing CA: %s", err)
+	}
+	if !changed {
+		t.Errorf("Expected replacing the generated CA to change it")
+	}
+	if changed, err := InstallCA(suppliedCert, suppliedKey); err != nil || changed {
+		t.Errorf("Expected installing the same CA again not to change it, got %v, %v", changed, err)
+	}
+
+	cert, key := constants.MakeMiniPath("apiserver.crt"), constants.MakeMiniPath("apiserver.key")
+	for _, ip := range []string{"192.168.99.100", "192.168.99.101"} {
+		if err := GenerateCerts(caCert, caKey, cert, key, net.ParseIP(ip), constants.APIServerName, APIServerSANs{}); err != nil {
+			t.Fatalf("Error generating certs for %s: %s", ip, err)
+		}
+		verifyChain(t, cert, suppliedCert)
+	}
+	installed, _ := ioutil.ReadFile(caCert)
+	supplied, _ := ioutil.ReadFile(suppliedCert)
+	if !bytes.Equal(installed, supplied) {
+		t.Errorf("Expected regenerating the certs to keep the supplied CA")
+	}
+
+	// An expired supplied CA isn't replaced by a generated one.
+	writeShortLivedCert(t, caCert, caKey, time.Now().Add(-time.Hour))
+	if _, err := RenewExpiredCerts(time.Now()); err == nil {
+		t.Errorf("Expected an error for an expired supplied CA")
+	}
+	if _, err := os.Stat(caCert); err != nil {
+		t.Errorf("Expected the expired supplied CA to be kept: %s", err)
+	}
+}
+
+func TestInstallCAInvalid(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	cert := writeCert(t, filepath.Join(tempDir, "internal"), "10.0.0.1")
+
+	if _, err := InstallCA(cert, filepath.Join(tempDir, "internal", "apiserver.key")); err == nil {
+		t.Errorf("Expected an error installing a certificate which is not a CA")
+	}
+	if _, err := os.Stat(constants.MakeMiniPath("ca.crt")); !os.IsNotExist(err) {
+		t.Errorf("Expected an invalid CA not to be installed: %v", err)
+	}
+	if caSupplied() {
+		t.Errorf("Expected an invalid CA not to be recorded as supplied")
+	}
+}
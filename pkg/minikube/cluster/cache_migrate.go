@@ -0,0 +1,122 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// MigrateSummary lists what migrating the cache moved, and what it left in place as the
+// new cache dir holds it already.
+type MigrateSummary struct {
+	Moved   []string
+	Skipped []string
+}
+
+// MigrateCache moves the ISOs, images and localkube binaries cached in from into the cache
+// dir to, which may be on another filesystem. Partial downloads are left behind, and so
+// are the files to holds already; the directories emptied are removed.
+func MigrateCache(from, to string) (MigrateSummary, error) {
+	var summary MigrateSummary
+	if filepath.Clean(from) == filepath.Clean(to) {
+		return summary, errors.Errorf("The cache is in %s already", to)
+	}
+	for _, sub := range constants.CacheSubdirs {
+		src := filepath.Join(from, sub)
+		var dirs []string
+		err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) && path == src {
+				return filepath.SkipDir
+			}
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				dirs = append(dirs, path)
+				return nil
+			}
+			if !info.Mode().IsRegular() || strings.HasSuffix(path, ".tmp") {
+				return nil
+			}
+			rel, err := filepath.Rel(from, path)
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(to, rel)
+			if _, err := os.Stat(dst); err == nil {
+				summary.Skipped = append(summary.Skipped, rel)
+				return nil
+			}
+			glog.Infof("Moving %s to %s", path, dst)
+			if err := moveFile(path, dst, info.Mode()); err != nil {
+				return errors.Wrapf(err, "Error moving %s to %s", path, dst)
+			}
+			summary.Moved = append(summary.Moved, rel)
+			return nil
+		})
+		if err != nil {
+			return summary, err
+		}
+		// The deepest first, so that a parent is empty by the time it's removed.
+		sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+		for _, dir := range dirs {
+			os.Remove(dir)
+		}
+	}
+	return summary, nil
+}
+
+// moveFile moves src to dst, copying it when they are on different filesystems.
+func moveFile(src, dst string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
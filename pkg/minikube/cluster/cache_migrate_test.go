@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateCache(t *testing.T) {
+	from, err := ioutil.TempDir("", "minikube-cache-from")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(from)
+	to, err := ioutil.TempDir("", "minikube-cache-to")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(to)
+
+	files := map[string]string{
+		filepath.Join("iso", "minikube-v0.19.0.iso"):                    "iso",
+		filepath.Join("iso", "minikube-v0.19.0.iso.sha256"):             "sum",
+		filepath.Join("iso", "minikube-v0.20.0.iso.tmp"):                "partial",
+		filepath.Join("images", "gcr.io", "google_containers", "pause"): "image",
+		filepath.Join("localkube", "localkube-v1.6.4"):                  "localkube",
+		filepath.Join("unrelated", "file"):                              "unrelated",
+	}
+	for name, contents := range files {
+		path := filepath.Join(from, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Error creating dir: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Error writing %s: %s", name, err)
+		}
+	}
+	existing := filepath.Join(to, "localkube", "localkube-v1.6.4")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatalf("Error creating dir: %s", err)
+	}
+	if err := ioutil.WriteFile(existing, []byte("newer"), 0644); err != nil {
+		t.Fatalf("Error writing %s: %s", existing, err)
+	}
+
+	summary, err := MigrateCache(from, to)
+	if err != nil {
+		t.Fatalf("Error migrating the cache: %s", err)
+	}
+	moved := []string{
+		filepath.Join("iso", "minikube-v0.19.0.iso"),
+		filepath.Join("iso", "minikube-v0.19.0.iso.sha256"),
+		filepath.Join("images", "gcr.io", "google_containers", "pause"),
+	}
+	expected := MigrateSummary{Moved: moved, Skipped: []string{filepath.Join("localkube", "localkube-v1.6.4")}}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected the summary %+v, got %+v", expected, summary)
+	}
+	for _, name := range moved {
+		data, err := ioutil.ReadFile(filepath.Join(to, name))
+		if err != nil || string(data) != files[name] {
+			t.Errorf("Expected %s to be moved, got %q: %v", name, data, err)
+		}
+		if _, err := os.Stat(filepath.Join(from, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed from the old cache, got: %v", name, err)
+		}
+	}
+	if data, err := ioutil.ReadFile(existing); err != nil || string(data) != "newer" {
+		t.Errorf("Expected %s to be kept, got %q: %v", existing, data, err)
+	}
+	for _, name := range []string{filepath.Join("iso", "minikube-v0.20.0.iso.tmp"), filepath.Join("localkube", "localkube-v1.6.4"), filepath.Join("unrelated", "file")} {
+		if _, err := os.Stat(filepath.Join(from, name)); err != nil {
+			t.Errorf("Expected %s to be left behind, got: %s", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(from, "images")); !os.IsNotExist(err) {
+		t.Errorf("Expected the emptied images dir to be removed, got: %v", err)
+	}
+
+	if _, err := MigrateCache(to, to+string(filepath.Separator)); err == nil {
+		t.Errorf("Expected an error migrating the cache into itself")
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+)
+
+// CachedArtifact is an ISO or a localkube binary in the cache.
+type CachedArtifact struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// PruneSummary lists the artifacts pruning the cache removed.
+type PruneSummary struct {
+	Removed   []CachedArtifact
+	Reclaimed int64
+}
+
+// byModTime sorts artifacts from the most recently modified.
+type byModTime []CachedArtifact
+
+func (b byModTime) Len() int           { return len(b) }
+func (b byModTime) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byModTime) Less(i, j int) bool { return b[i].ModTime.After(b[j].ModTime) }
+
+// isoCacheDir is the directory ISOs are cached in.
+func isoCacheDir() string {
+	return constants.MakeCachePath("iso")
+}
+
+// listCached returns the artifacts cached in dir. Partial downloads are left out, as
+// they may still be written to, and so are the checksums recorded next to artifacts.
+func listCached(dir string) ([]CachedArtifact, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error listing %s", dir)
+	}
+	artifacts := []CachedArtifact{}
+	for _, f := range files {
+		if !f.Mode().IsRegular() || strings.HasSuffix(f.Name(), ".tmp") || strings.HasSuffix(f.Name(), constants.ShaSuffix) {
+			continue
+		}
+		artifacts = append(artifacts, CachedArtifact{Path: filepath.Join(dir, f.Name()), Size: f.Size(), ModTime: f.ModTime()})
+	}
+	return artifacts, nil
+}
+
+// CacheSize returns the size of the ISOs and localkube binaries in the cache.
+func CacheSize() (int64, error) {
+	var size int64
+	for _, dir := range []string{isoCacheDir(), localkubeCacheDir()} {
+		artifacts, err := listCached(dir)
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range artifacts {
+			size += a.Size
+		}
+	}
+	return size, nil
+}
+
+// storedMachineConfig holds the field of a machine's stored config which references the cache.
+type storedMachineConfig struct {
+	Driver struct {
+		Boot2DockerURL string
+	}
+}
+
+// cacheReferences returns the cached artifacts which the machines or the given Kubernetes
+// version and ISO URL use. A machine config which can't be read fails it, rather than
+// risking the removal of what it references.
+func cacheReferences(kubernetesVersion, isoURL string) (map[string]bool, error) {
+	refs := map[string]bool{}
+	addVersion := func(version string) {
+		if version != "" {
+			l := localkubeCacher{KubernetesConfig{KubernetesVersion: version}}
+			refs[filepath.Clean(l.getLocalkubeCacheFilepath())] = true
+		}
+	}
+	addISO := func(fileURL string) {
+		if fileURL != "" {
+			refs[filepath.Clean(localPath(fileURL))] = true
+		}
+	}
+	addVersion(kubernetesVersion)
+	if isoURL != "" {
+		addISO(util.DefaultDownloader{}.GetISOFileURI(isoURL))
+	}
+
+	machinesDir := filepath.Join(constants.GetMinipath(), "machines")
+	machines, err := ioutil.ReadDir(machinesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Error listing machines")
+	}
+	for _, m := range machines {
+		if !m.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(machinesDir, m.Name(), "config.json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading config of machine %s", m.Name())
+		}
+		var c storedMachineConfig
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, errors.Wrapf(err, "Error parsing config of machine %s", m.Name())
+		}
+		addISO(c.Driver.Boot2DockerURL)
+		s, err := LoadStartState(m.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading start state of machine %s", m.Name())
+		}
+		addVersion(s.KubernetesVersion)
+	}
+	return refs, nil
+}
+
+// PruneCache removes the cached ISOs and localkube binaries which neither a machine nor
+// the given Kubernetes version and ISO URL use, except for the keep most recently
+// modified ISOs and localkube binaries out of those.
+func PruneCache(kubernetesVersion, isoURL string, keep int) (PruneSummary, error) {
+	var summary PruneSummary
+	refs, err := cacheReferences(kubernetesVersion, isoURL)
+	if err != nil {
+		return summary, err
+	}
+	for _, dir := range []string{isoCacheDir(), localkubeCacheDir()} {
+		artifacts, err := listCached(dir)
+		if err != nil {
+			return summary, err
+		}
+		sort.Sort(byModTime(artifacts))
+		kept := 0
+		for _, a := range artifacts {
+			if refs[filepath.Clean(a.Path)] {
+				continue
+			}
+			if kept < keep {
+				kept++
+				continue
+			}
+			glog.Infof("Removing %s from the cache", a.Path)
+			if err := os.Remove(a.Path); err != nil {
+				return summary, errors.Wrapf(err, "Error removing %s", a.Path)
+			}
+			if err := os.Remove(a.Path + constants.ShaSuffix); err != nil && !os.IsNotExist(err) {
+				return summary, errors.Wrapf(err, "Error removing the checksum of %s", a.Path)
+			}
+			summary.Removed = append(summary.Removed, a)
+			summary.Reclaimed += a.Size
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// fakeCache creates cached artifacts of the given sizes, each modified an hour before the next one.
+func fakeCache(t *testing.T, dir string, sizes map[string]int) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Error creating %s: %s", dir, err)
+	}
+	names := []string{}
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	modTime := time.Now().Add(-24 * time.Hour)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, make([]byte, sizes[name]), 0644); err != nil {
+			t.Fatalf("Error writing %s: %s", path, err)
+		}
+		modTime = modTime.Add(time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Error setting the times of %s: %s", path, err)
+		}
+	}
+}
+
+func writeMachineFile(t *testing.T, machine, name, contents string) {
+	dir := filepath.Join(constants.GetMinipath(), "machines", machine)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Error creating %s: %s", dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+		t.Fatalf("Error writing %s: %s", name, err)
+	}
+}
+
+func listDir(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Error listing %s: %s", dir, err)
+	}
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+func TestPruneCache(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "minipath")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv(constants.MinikubeHome, tempDir)
+	defer os.Unsetenv(constants.MinikubeHome)
+
+	// Sorted by name, the last ones are the most recently modified.
+	fakeCache(t, isoCacheDir(), map[string]int{
+		"minikube-v0.17.0.iso":     10,
+		"minikube-v0.18.0.iso":     20,
+		"minikube-v0.19.0.iso":     30,
+		"minikube-v0.19.1.iso":     40,
+		"minikube-v0.20.0.iso":     50,
+		"minikube-v0.21.0.iso.tmp": 60,
+	})
+	fakeCache(t, localkubeCacheDir(), map[string]int{
+		"localkube-v1.5.0": 1,
+		"localkube-v1.5.3": 2,
+		"localkube-v1.6.0": 3,
+		"localkube-v1.6.4": 4,
+		"localkube-v1.7.0": 5,
+	})
+
+	// One machine uses an old ISO, and the other an old localkube.
+	writeMachineFile(t, "minikube", "config.json", `{"Driver": {"Boot2DockerURL": "file://`+filepath.ToSlash(filepath.Join(isoCacheDir(), "minikube-v0.18.0.iso"))+`"}}`)
+	writeMachineFile(t, "none", "config.json", `{"Driver": {"IPAddress": "127.0.0.1"}}`)
+	writeMachineFile(t, "none", startStateFile, `{"Phase": "auth-configured", "KubernetesVersion": "v1.5.3"}`)
+
+	// The current versions aren't the most recent ones.
+	summary, err := PruneCache("v1.6.0", "https://storage.googleapis.com/minikube/iso/minikube-v0.19.0.iso", 1)
+	if err != nil {
+		t.Fatalf("Error pruning cache: %s", err)
+	}
+
+	removed := []string{}
+	for _, a := range summary.Removed {
+		removed = append(removed, filepath.Base(a.Path))
+	}
+	sort.Strings(removed)
+	expected := []string{"localkube-v1.5.0", "localkube-v1.6.4", "minikube-v0.17.0.iso", "minikube-v0.19.1.iso"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("Expected %v to be removed, got %v", expected, removed)
+	}
+	if summary.Reclaimed != 1+4+10+40 {
+		t.Errorf("Expected %d bytes to be reclaimed, got %d", 1+4+10+40, summary.Reclaimed)
+	}
+
+	if isos := listDir(t, isoCacheDir()); !reflect.DeepEqual(isos, []string{"minikube-v0.18.0.iso", "minikube-v0.19.0.iso", "minikube-v0.20.0.iso", "minikube-v0.21.0.iso.tmp"}) {
+		t.Errorf("Unexpected ISOs left in the cache: %v", isos)
+	}
+	if binaries := listDir(t, localkubeCacheDir()); !reflect.DeepEqual(binaries, []string{"localkube-v1.5.3", "localkube-v1.6.0", "localkube-v1.7.0"}) {
+		t.Errorf("Unexpected localkube binaries left in the cache: %v", binaries)
+	}
+
+	size, err := CacheSize()
+	if err != nil {
+		t.Fatalf("Error getting cache size: %s", err)
+	}
+	if size != 20+30+50+2+3+5 {
+		t.Errorf("Expected a cache size of %d, got %d", 20+30+50+2+3+5, size)
+	}
+}
+
+func TestPruneCacheUnreadableMachine(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "minipath")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	os.Setenv(constants.MinikubeHome, tempDir)
+	defer os.Unsetenv(constants.MinikubeHome)
+
+	fakeCache(t, isoCacheDir(), map[string]int{"minikube-v0.17.0.iso": 10, "minikube-v0.18.0.iso": 20})
+	writeMachineFile(t, "minikube", "config.json", `{"Driver": `)
+
+	if _, err := PruneCache("v1.6.0", "", 0); err == nil {
+		t.Fatal("Expected an error for the machine config which can't be parsed")
+	}
+	if isos := listDir(t, isoCacheDir()); len(isos) != 2 {
+		t.Errorf("Expected nothing to be removed, got %v", isos)
+	}
+}
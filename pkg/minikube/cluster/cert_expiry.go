@@ -0,0 +1,121 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// certExpiryWarning is how long before a certificate expires minikube starts warning about it.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// clusterCerts are the certificates minikube generates for the cluster, and
+// their keys. The CA comes first, as the others are signed by it.
+var clusterCerts = []struct {
+	cert, key string
+}{
+	{"ca.crt", "ca.key"},
+	{"apiserver.crt", "apiserver.key"},
+}
+
+// CertInfo describes one of the cluster's certificates.
+type CertInfo struct {
+	Name     string
+	Path     string
+	NotAfter time.Time
+
+	keyPath string
+}
+
+// Expired returns whether the certificate is no longer valid at now.
+func (c CertInfo) Expired(now time.Time) bool {
+	return !now.Before(c.NotAfter)
+}
+
+// Expiring returns whether the certificate expires within 30 days of now.
+func (c CertInfo) Expiring(now time.Time) bool {
+	return now.Add(certExpiryWarning).After(c.NotAfter)
+}
+
+// CertsInfo returns the cluster's certificates which have been generated, the CA first.
+func CertsInfo() ([]CertInfo, error) {
+	var infos []CertInfo
+	for _, c := range clusterCerts {
+		path := CertPath(c.cert)
+		cert, err := loadCert(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, CertInfo{Name: c.cert, Path: path, NotAfter: cert.NotAfter, keyPath: CertPath(c.key)})
+	}
+	return infos, nil
+}
+
+// CertExpiryWarnings returns a warning for each of the certificates which
+// expires within 30 days of now, or has already expired.
+func CertExpiryWarnings(infos []CertInfo, now time.Time) []string {
+	var warnings []string
+	for _, c := range infos {
+		switch {
+		case c.Expired(now):
+			warnings = append(warnings, fmt.Sprintf("The certificate %s expired on %s, run minikube start to regenerate it.", c.Path, c.NotAfter.Format(time.RFC1123)))
+		case c.Expiring(now):
+			warnings = append(warnings, fmt.Sprintf("The certificate %s expires on %s.", c.Path, c.NotAfter.Format(time.RFC1123)))
+		}
+	}
+	return warnings
+}
+
+// RenewExpiredCerts removes the certificates which have expired by now, and their keys,
+// so that setting up the certs generates them again. When the CA has expired, the
+// certificates it signed are removed too, unless it was supplied with --ca-cert,
+// which is an error. It returns the certificates it removed.
+func RenewExpiredCerts(now time.Time) ([]CertInfo, error) {
+	infos, err := CertsInfo()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading certificates")
+	}
+	var renewed []CertInfo
+	caExpired := false
+	for _, c := range infos {
+		if !c.Expired(now) && !caExpired {
+			continue
+		}
+		if c.Name == clusterCerts[0].cert {
+			if caSupplied() {
+				return nil, fmt.Errorf("The CA %s supplied with --ca-cert expired on %s, pass a valid one with --ca-cert and --ca-key", c.Path, c.NotAfter.Format(time.RFC1123))
+			}
+			caExpired = true
+		}
+		glog.Infof("Removing the expired certificate %s", c.Path)
+		for _, path := range []string{c.Path, c.keyPath} {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "Error removing %s", path)
+			}
+		}
+		renewed = append(renewed, c)
+	}
+	return renewed, nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// writeShortLivedCert writes a self-signed certificate expiring at notAfter, and its key.
+func writeShortLivedCert(t *testing.T, certPath, keyPath string, notAfter time.Time) {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "minikubeCA"},
+		NotBefore:             notAfter.Add(-2 * time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %s", err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := ioutil.WriteFile(certPath, cert, 0644); err != nil {
+		t.Fatalf("Error writing certificate: %s", err)
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+	if err := ioutil.WriteFile(keyPath, key, 0600); err != nil {
+		t.Fatalf("Error writing key: %s", err)
+	}
+}
+
+func TestCertExpiryWarnings(t *testing.T) {
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	var cases = []struct {
+		description string
+		notAfter    time.Time
+		expected    string
+	}{
+		{
+			description: "valid",
+			notAfter:    now.Add(365 * 24 * time.Hour),
+		},
+		{
+			description: "expiring",
+			notAfter:    now.Add(29 * 24 * time.Hour),
+			expected:    "expires on",
+		},
+		{
+			description: "expired",
+			notAfter:    now.Add(-time.Hour),
+			expected:    "expired on",
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			warnings := CertExpiryWarnings([]CertInfo{{Name: "ca.crt", Path: "ca.crt", NotAfter: test.notAfter}}, now)
+			if test.expected == "" {
+				if len(warnings) != 0 {
+					t.Errorf("Expected no warnings, got %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], test.expected) {
+				t.Errorf("Expected a warning containing %q, got %v", test.expected, warnings)
+			}
+		})
+	}
+}
+
+func TestRenewExpiredCerts(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	now := time.Now()
+	valid, expired := now.Add(24*time.Hour), now.Add(-time.Hour)
+
+	var cases = []struct {
+		description   string
+		caNotAfter    time.Time
+		certNotAfter  time.Time
+		expectRenewed []string
+	}{
+		{
+			description:  "valid",
+			caNotAfter:   valid,
+			certNotAfter: valid,
+		},
+		{
+			description:   "apiserver expired",
+			caNotAfter:    valid,
+			certNotAfter:  expired,
+			expectRenewed: []string{"apiserver.crt"},
+		},
+		{
+			description:   "CA expired",
+			caNotAfter:    expired,
+			certNotAfter:  valid,
+			expectRenewed: []string{"ca.crt", "apiserver.crt"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			writeShortLivedCert(t, constants.MakeMiniPath("ca.crt"), constants.MakeMiniPath("ca.key"), test.caNotAfter)
+			writeShortLivedCert(t, constants.MakeMiniPath("apiserver.crt"), constants.MakeMiniPath("apiserver.key"), test.certNotAfter)
+
+			renewed, err := RenewExpiredCerts(now)
+			if err != nil {
+				t.Fatalf("Error renewing certs: %s", err)
+			}
+			var names []string
+			for _, c := range renewed {
+				names = append(names, c.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(test.expectRenewed, ",") {
+				t.Fatalf("Expected %v to be renewed, got %v", test.expectRenewed, names)
+			}
+			for _, c := range clusterCerts {
+				_, err := os.Stat(constants.MakeMiniPath(c.cert))
+				removed := os.IsNotExist(err)
+				if shouldRemove := strings.Contains(strings.Join(names, ","), c.cert); removed != shouldRemove {
+					t.Errorf("Expected %s to be removed: %v, got %v", c.cert, shouldRemove, removed)
+				}
+			}
+
+			// Setting up the certs generates the removed ones again.
+			err = GenerateCerts(constants.MakeMiniPath("ca.crt"), constants.MakeMiniPath("ca.key"),
+				constants.MakeMiniPath("apiserver.crt"), constants.MakeMiniPath("apiserver.key"),
+				net.ParseIP("192.168.99.100"), constants.APIServerName, APIServerSANs{})
+			if err != nil {
+				t.Fatalf("Error generating certs: %s", err)
+			}
+			infos, err := CertsInfo()
+			if err != nil {
+				t.Fatalf("Error reading certs: %s", err)
+			}
+			if len(infos) != len(clusterCerts) {
+				t.Fatalf("Expected %d certs, got %+v", len(clusterCerts), infos)
+			}
+			for _, c := range infos {
+				if c.Expired(now) {
+					t.Errorf("Expected %s to be valid after generating the certs, it expires on %s", c.Name, c.NotAfter)
+				}
+			}
+		})
+	}
+}
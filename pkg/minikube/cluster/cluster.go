@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -28,7 +29,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/engine"
@@ -36,11 +36,17 @@ import (
 	"github.com/docker/machine/libmachine/state"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
 	"k8s.io/minikube/pkg/minikube/assets"
 	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/none"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
 	"k8s.io/minikube/pkg/minikube/sshutil"
+	"k8s.io/minikube/pkg/provision"
 	"k8s.io/minikube/pkg/util"
 )
 
@@ -57,88 +63,330 @@ func init() {
 	flag.Set("logtostderr", "false")
 }
 
-// StartHost starts a host VM.
-func StartHost(api libmachine.API, config MachineConfig) (*host.Host, error) {
-	exists, err := api.Exists(cfg.GetMachineName())
+// cleanupTimeout bounds removing the VM of a start which was given up on, as
+// the driver which wedged the start may not remove it either.
+const cleanupTimeout = 2 * time.Minute
+
+// StartHost starts a host VM, giving up once ctx is done, and returns whether it
+// created the host rather than starting an existing one. When a start which was
+// creating the VM is given up on, the half created VM is removed. The machine is
+// locked throughout, so that another process doesn't create or change it meanwhile.
+func StartHost(ctx context.Context, api libmachine.API, config MachineConfig) (*host.Host, bool, error) {
+	name := cfg.GetMachineName()
+	unlock, err := machine.LockMachine(api, name)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Error locking machine: %s", name)
+	}
+	defer unlock()
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Error checking if host exists: %s", name)
+	}
+	var h *host.Host
+	err = machine.RunWithContext(ctx, api, func() error {
+		var err error
+		h, err = startHost(api, config, exists)
+		return err
+	})
+	if err != nil && err == ctx.Err() {
+		if !exists && !config.KeepFailed {
+			config.Steps.Println(fmt.Sprintf("Removing machine %s, which was not created in time...", name))
+			removeHalfCreatedHost(api, config)
+		}
+		return nil, !exists, errors.Wrapf(err, "Error starting host %s in time", name)
+	}
+	return h, !exists, err
+}
+
+// StartHostWithRetries starts the host as StartHost does, making up to attempts
+// attempts, backoff apart, while it fails with retriable errors. It returns whether
+// any attempt created the host, as a retry resumes the host an earlier one created.
+// When the start is given up on because ctx is done, such a host is removed as
+// RemoveFailedHost does, as StartHost only removes a host it is creating itself.
+func StartHostWithRetries(ctx context.Context, api libmachine.API, config MachineConfig, attempts int, backoff time.Duration) (*host.Host, bool, error) {
+	return startHostWithRetries(ctx, api, config, attempts, backoff, StartHost)
+}
+
+func startHostWithRetries(ctx context.Context, api libmachine.API, config MachineConfig, attempts int, backoff time.Duration,
+	start func(context.Context, libmachine.API, MachineConfig) (*host.Host, bool, error)) (*host.Host, bool, error) {
+	var created bool
+	for attempt := 1; ; attempt++ {
+		h, c, err := start(ctx, api, config)
+		created = created || c
+		if err == nil {
+			return h, created, nil
+		}
+		if ctx.Err() != nil {
+			if created && !c {
+				RemoveFailedHost(api, config, true)
+			}
+			return nil, created, err
+		}
+		if _, ok := err.(*util.RetriableError); !ok || attempt >= attempts {
+			return nil, created, err
+		}
+		glog.Errorf("Error starting host: %s.\n\n Retrying.\n", err)
+		time.Sleep(backoff)
+	}
+}
+
+// RemoveFailedHost stops and removes the machine of a start which failed before
+// its cluster was bootstrapped, if that start created it, so that its VM doesn't
+// keep using the host's memory. A machine which existed before is never removed,
+// nor is one kept with config.KeepFailed. It returns whether the machine was removed.
+func RemoveFailedHost(api libmachine.API, config MachineConfig, created bool) bool {
+	if !created || config.KeepFailed {
+		return false
+	}
+	name := cfg.GetMachineName()
+	unlock, err := machine.LockMachine(api, name)
+	if err != nil {
+		glog.Warningf("Error removing machine %s: %s", name, err)
+		return false
+	}
+	defer unlock()
+	config.Steps.Println(fmt.Sprintf("Removing machine %s, which failed to start. Pass --keep-failed to keep it.", name))
+	removeHalfCreatedHost(api, config)
+	return true
+}
+
+// removeHalfCreatedHost makes a best effort to stop and remove the VM and the
+// stored config of a machine whose creation was given up on.
+func removeHalfCreatedHost(api libmachine.API, config MachineConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	name := cfg.GetMachineName()
+	err := machine.RunWithContext(ctx, api, func() error {
+		// The VM is killed rather than shut down, as it is being thrown away.
+		if h, err := api.Load(name); err == nil {
+			if err := h.Driver.Kill(); err != nil {
+				glog.Infof("Unable to kill VM of %s, it may not be running: %s", name, err)
+			}
+		}
+		removeVM(api, config)
+		return api.Remove(name)
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "Error checking if host exists: %s", cfg.GetMachineName())
+		glog.Warningf("Error removing machine %s: %s", name, err)
 	}
+}
+
+func startHost(api libmachine.API, config MachineConfig, exists bool) (*host.Host, error) {
+	name := cfg.GetMachineName()
 	if !exists {
-		return createHost(api, config)
+		console.Phase("Creating machine %s with the %s driver", name, config.VMDriver)
+		h, err := createHost(api, config)
+		if err != nil {
+			return nil, err
+		}
+		// Creating the VM provisioned it, only its ports and files are left.
+		config.Steps.Start(util.StepProvisioning)
+		if err := forwardPorts(h, config.NatForwards); err != nil {
+			return nil, errors.Wrap(err, "Error forwarding ports")
+		}
+		restartDocker, err := syncFiles(h)
+		if err != nil {
+			return nil, err
+		}
+		if restartDocker {
+			if _, err := RunCommand(h, restartDockerCommand, false); err != nil {
+				return nil, errors.Wrap(err, "Error restarting Docker")
+			}
+		}
+		config.Steps.Complete(util.StepProvisioning)
+		return h, nil
 	}
 
-	glog.Infoln("Machine exists!")
-	h, err := api.Load(cfg.GetMachineName())
+	console.Phase("Starting the existing machine %s", name)
+	h, err := api.Load(name)
 	if err != nil {
+		if _, ok := errors.Cause(err).(machine.ErrCorruptConfig); ok {
+			if config.ForceRecreate {
+				return recreateHost(api, config, "a corrupt config")
+			}
+			return nil, err
+		}
 		return nil, errors.Wrap(err, "Error loading existing host. Please try running [minikube delete], then run [minikube start] again.")
 	}
 
-	s, err := h.Driver.GetState()
+	changes, err := configChanges(h, config)
+	if err != nil {
+		glog.Warningf("Not checking the config of %s for changes: %s", name, err)
+	}
+	if len(changes) > 0 {
+		if config.RecreateOnConfigChange {
+			return recreateHost(api, config, "the changed config")
+		}
+		printConfigChanges(console.ErrWriter(), h, changes)
+	}
+
+	// If the host was saved but creating it failed, resume from where it failed
+	// rather than starting over.
+	last, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	if last.LastStop == StopKill {
+		glog.Infof("Machine %s was killed when it was last stopped", name)
+	}
+	phase := PhaseHostCreated
+	if last.Failed() && last.Phase < PhaseHostCreated {
+		config.Steps.Println(fmt.Sprintf("Resuming start of machine %s, which %s", name, last))
+		phase = last.Phase
+	}
+
+	config.Steps.Start(util.StepCreatingVM)
+	s, err := CheckDriverConsistency(h)
 	glog.Infoln("Machine state: ", s)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error getting state for host")
+		if _, ok := err.(ErrMachineMissing); ok && (config.ForceRecreate || phase < PhaseHostCreated) {
+			return recreateHost(api, config, "a missing VM")
+		}
+		recordStartState(name, phase, err)
+		return nil, err
 	}
 
 	if s != state.Running {
-		if err := h.Driver.Start(); err != nil {
+		if err := retryDriverOp(h.Driver.DriverName(), "start", config.RetryPolicy, h.Driver.Start); err != nil {
+			recordStartState(name, phase, err)
 			return nil, errors.Wrap(err, "Error starting stopped host")
 		}
 		if err := api.Save(h); err != nil {
+			recordStartState(name, phase, err)
 			return nil, errors.Wrap(err, "Error saving started host")
 		}
 	}
+	phase = PhaseHostRunning
+	config.Steps.Complete(util.StepCreatingVM)
+
+	config.Steps.Start(util.StepProvisioning)
+	if err := forwardPorts(h, config.NatForwards); err != nil {
+		recordStartState(name, phase, err)
+		return nil, errors.Wrap(err, "Error forwarding ports")
+	}
 
+	// Configuring auth provisions the host again, which also completes an
+	// interrupted provisioning. It restarts Docker, after the files are copied.
 	if h.Driver.DriverName() != "none" {
+		if _, err := syncFiles(h); err != nil {
+			recordStartState(name, phase, err)
+			return nil, err
+		}
+		// The engine options passed now are applied along.
+		optionsChanged := setEngineOptions(h, config)
 		if err := h.ConfigureAuth(); err != nil {
+			recordStartState(name, phase, err)
 			return nil, &util.RetriableError{Err: errors.Wrap(err, "Error configuring auth on host")}
 		}
+		if optionsChanged {
+			if err := api.Save(h); err != nil {
+				recordStartState(name, phase, err)
+				return nil, errors.Wrap(err, "Error saving host")
+			}
+		}
+		// Mounts don't survive the VM restarting, unlike the shared folder.
+		if err := provision.MountSharedFolder(h.Driver); err != nil {
+			recordStartState(name, phase, err)
+			return nil, err
+		}
 	}
+	recordStartState(name, PhaseAuthConfigured, nil)
+	config.Steps.Complete(util.StepProvisioning)
 	return h, nil
 }
 
-// StopHost stops the host VM.
-func StopHost(api libmachine.API) error {
-	host, err := api.Load(cfg.GetMachineName())
+// StopHost stops the host VM as opts say, retrying driver operations as the policy
+// says if they fail, and returns how it was stopped. It gives up once ctx is done.
+func StopHost(ctx context.Context, api libmachine.API, policy RetryPolicy, opts StopOptions) (StopMethod, error) {
+	unlock, err := machine.LockMachine(api, cfg.GetMachineName())
 	if err != nil {
-		return errors.Wrapf(err, "Error loading host: %s", cfg.GetMachineName())
-	}
-	if err := host.Stop(); err != nil {
-		return errors.Wrapf(err, "Error stopping host: %s", cfg.GetMachineName())
+		return "", errors.Wrapf(err, "Error locking machine: %s", cfg.GetMachineName())
 	}
-	return nil
+	defer unlock()
+	var method StopMethod
+	err = machine.RunWithContext(ctx, api, func() error {
+		var err error
+		method, err = stopHost(api, policy, opts)
+		return err
+	})
+	return method, err
 }
 
-// DeleteHost deletes the host VM.
-func DeleteHost(api libmachine.API) error {
+func stopHost(api libmachine.API, policy RetryPolicy, opts StopOptions) (StopMethod, error) {
+	s, err := machine.GetState(api, cfg.GetMachineName())
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting state for host: %s", cfg.GetMachineName())
+	}
+	if s == state.None {
+		return "", errors.Errorf("Machine does not exist: %s", cfg.GetMachineName())
+	}
+	if s == state.Stopped {
+		glog.Infof("Machine %s is already stopped", cfg.GetMachineName())
+		return "", nil
+	}
 	host, err := api.Load(cfg.GetMachineName())
 	if err != nil {
-		return errors.Wrapf(err, "Error deleting host: %s", cfg.GetMachineName())
+		return "", errors.Wrapf(err, "Error loading host: %s", cfg.GetMachineName())
 	}
-	m := util.MultiError{}
-	m.Collect(host.Driver.Remove())
-	m.Collect(api.Remove(cfg.GetMachineName()))
-	return m.ToError()
+	var method StopMethod
+	if opts.Force {
+		method, err = forceStop(host, policy, opts.Timeout)
+	} else {
+		method, err = StopDriver, stopWithin(host.Driver, opts.Timeout, func() error {
+			return retryDriverOp(host.Driver.DriverName(), "stop", policy, host.Stop)
+		})
+		if _, ok := err.(errStopTimedOut); ok {
+			err = errors.Wrap(err, "Run minikube stop --force to kill the VM")
+		}
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "Error stopping host: %s", cfg.GetMachineName())
+	}
+	recordStopMethod(host.Name, method)
+	return method, nil
 }
 
-// GetHostStatus gets the status of the host VM.
-func GetHostStatus(api libmachine.API) (string, error) {
-	exists, err := api.Exists(cfg.GetMachineName())
+// DeleteHost deletes the host VM, giving up once ctx is done.
+func DeleteHost(ctx context.Context, api libmachine.API) error {
+	unlock, err := machine.LockMachine(api, cfg.GetMachineName())
 	if err != nil {
-		return "", errors.Wrapf(err, "Error checking that api exists for: %s", cfg.GetMachineName())
-	}
-	if !exists {
-		return state.None.String(), nil
+		return errors.Wrapf(err, "Error locking machine: %s", cfg.GetMachineName())
 	}
+	defer unlock()
+	return machine.RunWithContext(ctx, api, func() error {
+		return deleteHost(api, cfg.GetMachineName())
+	})
+}
 
-	host, err := api.Load(cfg.GetMachineName())
+// deleteHost removes the named host's VM and files. A VM which is already gone is
+// skipped, so that the files of the host are still removed.
+func deleteHost(api libmachine.API, name string) error {
+	host, err := api.Load(name)
 	if err != nil {
-		return "", errors.Wrapf(err, "Error loading api for: %s", cfg.GetMachineName())
+		return errors.Wrapf(err, "Error deleting host: %s", name)
+	}
+	m := util.MultiError{}
+	if err := host.Driver.Remove(); err != nil {
+		if machine.IsMachineNotFound(host.DriverName, err) {
+			glog.Infof("VM of %s is already gone: %s", name, err)
+		} else {
+			m.Collect(err)
+		}
 	}
+	m.Collect(api.Remove(name))
+	return m.ToError()
+}
 
-	s, err := host.Driver.GetState()
+// GetHostStatus gets the status of the host VM.
+// If the host does not exist, constants.MachineDoesNotExist is returned.
+func GetHostStatus(api libmachine.API) (string, error) {
+	s, err := machine.GetState(api, cfg.GetMachineName())
 	if err != nil {
 		return "", errors.Wrap(err, "Error getting host state")
 	}
+	if s == state.None {
+		return constants.MachineDoesNotExist, nil
+	}
 	return s.String(), nil
 }
 
@@ -160,6 +408,8 @@ func GetLocalkubeStatus(api libmachine.API) (string, error) {
 		return state.Running.String(), nil
 	} else if state.Stopped.String() == s {
 		return state.Stopped.String(), nil
+	} else if state.Paused.String() == s {
+		return state.Paused.String(), nil
 	} else {
 		return "", fmt.Errorf("Error: Unrecognize output from GetLocalkubeStatus: %s", s)
 	}
@@ -182,18 +432,20 @@ func StartCluster(api libmachine.API, kubernetesConfig KubernetesConfig) error {
 	if err != nil {
 		return errors.Wrapf(err, "Error running ssh command: %s", startCommand)
 	}
+	recordKubernetesConfig(cfg.GetMachineName(), kubernetesConfig)
 	return nil
 }
 
 func UpdateCluster(d drivers.Driver, config KubernetesConfig) error {
 	copyableFiles := []assets.CopyableFile{}
 	var localkubeFile assets.CopyableFile
+	var localkubeChecksum string
 	var err error
 
 	//add url/file/bundled localkube to file list
 	if localkubeURIWasSpecified(config) {
 		lCacher := localkubeCacher{config}
-		localkubeFile, err = lCacher.fetchLocalkubeFromURI()
+		localkubeFile, localkubeChecksum, err = lCacher.fetchLocalkubeFromURI()
 		if err != nil {
 			return errors.Wrap(err, "Error updating localkube from uri")
 		}
@@ -206,9 +458,29 @@ func UpdateCluster(d drivers.Driver, config KubernetesConfig) error {
 	// add addons to file list
 	// custom addons
 	assets.AddMinikubeAddonsDirToAssets(&copyableFiles)
-	// bundled addons
+	if config.AuditPolicy != "" {
+		policy, err := auditPolicyAsset(config.AuditPolicy)
+		if err != nil {
+			return err
+		}
+		copyableFiles = append(copyableFiles, policy)
+	}
+
+	// bundled addons, with their configuration
+	m, err := cfg.ReadConfig()
+	if err != nil {
+		return errors.Wrap(err, "Error reading the configuration of the addons")
+	}
 	for _, addonBundle := range assets.Addons {
 		if isEnabled, err := addonBundle.IsEnabled(); err == nil && isEnabled {
+			addonBundle, err = addonBundle.WithConfigValues(addonBundle.ConfigValues(m))
+			if err != nil {
+				return err
+			}
+			addonBundle, err = AddonWithDNSIP(AddonWithDNSDomain(AddonWithImageRepository(addonBundle, config.ImageRepository), config), config)
+			if err != nil {
+				return err
+			}
 			for _, addon := range addonBundle.Assets {
 				copyableFiles = append(copyableFiles, addon)
 			}
@@ -224,6 +496,7 @@ func UpdateCluster(d drivers.Driver, config KubernetesConfig) error {
 				return err
 			}
 		}
+		recordKubernetesVersion(cfg.GetMachineName(), config.KubernetesVersion, config.KubernetesChannel)
 		return nil
 	}
 
@@ -234,10 +507,16 @@ func UpdateCluster(d drivers.Driver, config KubernetesConfig) error {
 	}
 
 	for _, f := range copyableFiles {
-		if err := sshutil.TransferFile(f, client); err != nil {
+		if f == localkubeFile && localkubeChecksum != "" {
+			err = transferVerified(f, localkubeChecksum, client)
+		} else {
+			err = sshutil.TransferFile(f, client)
+		}
+		if err != nil {
 			return err
 		}
 	}
+	recordKubernetesVersion(cfg.GetMachineName(), config.KubernetesVersion, config.KubernetesChannel)
 	return nil
 }
 
@@ -247,26 +526,33 @@ func localkubeURIWasSpecified(config KubernetesConfig) bool {
 }
 
 // SetupCerts gets the generated credentials required to talk to the APIServer.
-func SetupCerts(d drivers.Driver, apiServerName string) error {
-	localPath := constants.GetMinipath()
+func SetupCerts(d drivers.Driver, k KubernetesConfig) error {
+	localPath := CertPath("")
 	ipStr, err := d.GetIP()
 	if err != nil {
 		return errors.Wrap(err, "Error getting ip from driver")
 	}
-	glog.Infoln("Setting up certificates for IP: %s", ipStr)
-
 	ip := net.ParseIP(ipStr)
 	caCert := filepath.Join(localPath, "ca.crt")
 	caKey := filepath.Join(localPath, "ca.key")
 	publicPath := filepath.Join(localPath, "apiserver.crt")
 	privatePath := filepath.Join(localPath, "apiserver.key")
-	if err := GenerateCerts(caCert, caKey, publicPath, privatePath, ip, apiServerName); err != nil {
+	sans, err := k.certSANs()
+	if err != nil {
+		return err
+	}
+	console.Phase("Setting up the apiserver certificate for %s, with the extra names %v and IPs %v", ipStr, sans.Names, sans.IPs)
+	if err := GenerateCerts(caCert, caKey, publicPath, privatePath, ip, k.APIServerName, sans); err != nil {
 		return errors.Wrap(err, "Error generating certs")
 	}
 
 	copyableFiles := []assets.CopyableFile{}
 
 	for _, cert := range certs {
+		// localkube doesn't generate certs, so a supplied CA's key stays on the host.
+		if cert == "ca.key" && caSupplied() {
+			continue
+		}
 		p := filepath.Join(localPath, cert)
 		perms := "0644"
 		if strings.HasSuffix(cert, ".key") {
@@ -306,16 +592,21 @@ func SetupCerts(d drivers.Driver, apiServerName string) error {
 func engineOptions(config MachineConfig) *engine.Options {
 
 	o := engine.Options{
-		Env:              config.DockerEnv,
+		Env:              mergeDockerEnv(config.DockerEnv, config.Proxy),
 		InsecureRegistry: config.InsecureRegistry,
 		RegistryMirror:   config.RegistryMirror,
-		ArbitraryFlags:   config.DockerOpt,
+		ArbitraryFlags:   dockerDaemonFlags(config.DockerOpt),
 	}
 	return &o
 }
 
 func createVirtualboxHost(config MachineConfig) drivers.Driver {
 	d := virtualbox.NewDriver(cfg.GetMachineName(), constants.GetMinipath())
+	d.ExtraDisks = config.ExtraDisks
+	d.ExtraDiskSize = config.ExtraDiskSize
+	d.VBoxManage = DetectVBoxManageCmd()
+	d.MountHostPath = config.SharedFolder.HostPath
+	d.MountGuestPath = config.SharedFolder.GuestPath
 	d.Boot2DockerURL = config.Downloader.GetISOFileURI(config.MinikubeISO)
 	d.Memory = config.Memory
 	d.CPU = config.CPUs
@@ -325,14 +616,71 @@ func createVirtualboxHost(config MachineConfig) drivers.Driver {
 }
 
 func createHost(api libmachine.API, config MachineConfig) (*host.Host, error) {
-	var driver interface{}
-
 	if config.VMDriver != "none" {
 		if err := config.Downloader.CacheMinikubeISOFromURL(config.MinikubeISO); err != nil {
 			return nil, errors.Wrap(err, "Error attempting to cache minikube ISO from URL")
 		}
 	}
 
+	if config.VMDriver == "hyperv" && config.HypervVirtualSwitch == "" && config.HypervUseExternalSwitch {
+		vswitch, err := chooseHypervSwitch(powerShell)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error choosing an external virtual switch")
+		}
+		config.HypervVirtualSwitch = vswitch
+	}
+
+	h, err := newHost(api, config)
+	if err != nil {
+		return nil, err
+	}
+
+	h.HostOptions.AuthOptions.CertDir = constants.GetMinipath()
+	h.HostOptions.AuthOptions.StorePath = constants.GetMinipath()
+	h.HostOptions.EngineOptions = engineOptions(config)
+
+	config.Steps.Start(util.StepCreatingVM)
+	attempts := 0
+	create := func() error {
+		attempts++
+		if attempts > 1 {
+			// Remove what the failed attempt created, so the VM can be created again.
+			if err := h.Driver.Remove(); err != nil {
+				glog.Warningf("Error removing partially created host: %s", err)
+			}
+		}
+		return api.Create(h)
+	}
+	if err := retryDriverOp(config.VMDriver, "create", config.RetryPolicy, create); err != nil {
+		// The host may have been saved before creating it failed.
+		recordStartState(h.Name, PhaseISOCached, err)
+		// Wait for all the logs to reach the client
+		time.Sleep(2 * time.Second)
+		return nil, errors.Wrap(err, "Error creating host")
+	}
+
+	if err := api.Save(h); err != nil {
+		recordStartState(h.Name, PhaseISOCached, err)
+		return nil, errors.Wrap(err, "Error attempting to save")
+	}
+	recordStartState(h.Name, PhaseHostCreated, nil)
+	recordMachineID(h.Name)
+	config.Steps.Complete(util.StepCreatingVM)
+	return h, nil
+}
+
+// HostConfig is the configuration a new host is created with, resolved from a MachineConfig.
+type HostConfig struct {
+	MachineConfig MachineConfig
+	Driver        interface{}
+	EngineOptions *engine.Options
+}
+
+// NewHostConfig builds the driver and engine configuration for a new host from
+// the given MachineConfig, without creating anything.
+func NewHostConfig(config MachineConfig) (*HostConfig, error) {
+	var driver interface{}
+
 	switch config.VMDriver {
 	case "virtualbox":
 		driver = createVirtualboxHost(config)
@@ -340,17 +688,35 @@ func createHost(api libmachine.API, config MachineConfig) (*host.Host, error) {
 		driver = createVMwareFusionHost(config)
 	case "kvm":
 		driver = createKVMHost(config)
+	case "kvm2":
+		driver = createKVM2Host(config)
 	case "xhyve":
 		driver = createXhyveHost(config)
+	case "hyperkit":
+		driver = createHyperkitHost(config)
 	case "hyperv":
 		driver = createHypervHost(config)
 	case "none":
 		driver = createNoneHost(config)
 	default:
-		glog.Exitf("Unsupported driver: %s\n", config.VMDriver)
+		return nil, fmt.Errorf("Unsupported driver: %s", config.VMDriver)
 	}
 
-	data, err := json.Marshal(driver)
+	return &HostConfig{
+		MachineConfig: config,
+		Driver:        driver,
+		EngineOptions: engineOptions(config),
+	}, nil
+}
+
+// newHost builds, but does not create, the host described by config.
+func newHost(api libmachine.API, config MachineConfig) (*host.Host, error) {
+	hc, err := NewHostConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(hc.Driver)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error marshalling json")
 	}
@@ -359,21 +725,53 @@ func createHost(api libmachine.API, config MachineConfig) (*host.Host, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating new host")
 	}
+	return h, nil
+}
 
-	h.HostOptions.AuthOptions.CertDir = constants.GetMinipath()
-	h.HostOptions.AuthOptions.StorePath = constants.GetMinipath()
-	h.HostOptions.EngineOptions = engineOptions(config)
+// ErrMachineMissing is returned when the VM of a stored machine was deleted outside of minikube.
+type ErrMachineMissing struct {
+	Name       string
+	DriverName string
+	Err        error
+}
 
-	if err := api.Create(h); err != nil {
-		// Wait for all the logs to reach the client
-		time.Sleep(2 * time.Second)
-		return nil, errors.Wrap(err, "Error creating host")
+func (e ErrMachineMissing) Error() string {
+	return fmt.Sprintf(`The %s VM %q no longer exists, it may have been deleted outside of minikube. Run "minikube delete" to clean it up, or "minikube start --force-recreate" to create it again.`, e.DriverName, e.Name)
+}
+
+// CheckDriverConsistency gets the state of the host's VM, and returns
+// ErrMachineMissing if the driver can no longer find it.
+func CheckDriverConsistency(h *host.Host) (state.State, error) {
+	s, err := h.Driver.GetState()
+	if err != nil {
+		if machine.IsMachineNotFound(h.DriverName, err) {
+			return s, ErrMachineMissing{Name: h.Name, DriverName: h.DriverName, Err: err}
+		}
+		return s, errors.Wrap(err, "Error getting state for host")
 	}
+	return s, nil
+}
 
-	if err := api.Save(h); err != nil {
-		return nil, errors.Wrap(err, "Error attempting to save")
+// recreateHost deletes a machine whose config is corrupt or whose VM is missing, and creates it again.
+// The VM may still exist even though its config is unusable, so removing it is
+// attempted through a freshly configured driver first to avoid leaking it.
+func recreateHost(api libmachine.API, config MachineConfig, reason string) (*host.Host, error) {
+	config.Steps.Println(fmt.Sprintf("Recreating machine %s with %s...", cfg.GetMachineName(), reason))
+	removeVM(api, config)
+	if err := api.Remove(cfg.GetMachineName()); err != nil {
+		return nil, errors.Wrapf(err, "Error removing machine: %s", cfg.GetMachineName())
+	}
+	return createHost(api, config)
+}
+
+// removeVM removes the machine's VM through a freshly configured driver, as the
+// stored one may be unusable or not saved yet. The VM may not exist, so errors are only logged.
+func removeVM(api libmachine.API, config MachineConfig) {
+	if h, err := newHost(api, config); err != nil {
+		glog.Infof("Unable to build driver to remove existing VM: %s", err)
+	} else if err := h.Driver.Remove(); err != nil {
+		glog.Infof("Unable to remove existing VM, it may not exist: %s", err)
 	}
-	return h, nil
 }
 
 // GetHostDockerEnv gets the necessary docker env variables to allow the use of docker through minikube's vm
@@ -386,39 +784,34 @@ func GetHostDockerEnv(api libmachine.API) (map[string]string, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting ip from host")
 	}
-
-	tcpPrefix := "tcp://"
-	port := "2376"
+	// DHCP may have given the VM another IP since its certificates were generated.
+	if err := ensureDockerCertsIP(host, ip); err != nil {
+		return nil, err
+	}
 
 	envMap := map[string]string{
 		"DOCKER_TLS_VERIFY": "1",
-		"DOCKER_HOST":       tcpPrefix + net.JoinHostPort(ip, port),
+		"DOCKER_HOST":       dockerHost(ip),
 		"DOCKER_CERT_PATH":  constants.MakeMiniPath("certs"),
+		DockerEnvIDVar:      dockerEnvID(host.Name, ensureMachineID(host.Name)),
 	}
 	return envMap, nil
 }
 
-// GetHostLogs gets the localkube logs of the host VM.
-// If follow is specified, it will tail the logs
-func GetHostLogs(api libmachine.API, follow bool) (string, error) {
-	h, err := CheckIfApiExistsAndLoad(api)
-	if err != nil {
-		return "", errors.Wrap(err, "Error checking that api exists and loading it")
-	}
-	logsCommand, err := GetLogsCommand(follow)
-	if err != nil {
-		return "", errors.Wrap(err, "Error getting logs command")
-	}
+// runLogsCommand runs a command printing logs on the host. A command following them
+// runs until it is interrupted, with its output going to the terminal.
+func runLogsCommand(h *host.Host, logsCommand string, follow bool) (string, error) {
 	if follow {
-		c, err := h.CreateSSHClient()
+		// The command is run again if the connection drops, for instance while the host sleeps.
+		session, err := sshutil.NewSession(h.Driver)
 		if err != nil {
-			return "", errors.Wrap(err, "Error creating ssh client")
+			return "", errors.Wrap(err, "Error creating ssh session")
 		}
-		err = c.Shell(logsCommand)
-		if err != nil {
-			return "", errors.Wrap(err, "error ssh shell")
+		defer session.Close()
+		if err := session.Stream(logsCommand, console.OutWriter(), console.ErrWriter()); err != nil {
+			return "", errors.Wrap(err, "Error following logs")
 		}
-		return "", err
+		return "", nil
 	}
 	s, err := RunCommand(h, logsCommand, false)
 
@@ -428,28 +821,38 @@ func GetHostLogs(api libmachine.API, follow bool) (string, error) {
 	return s, nil
 }
 
-// MountHost runs the mount command from the 9p client on the VM to the 9p server on the host
-func MountHost(api libmachine.API, path string, ip net.IP, port string) error {
+// MountHost runs the mount command from the 9p client on the VM to the 9p server on the host,
+// with the options of config. It runs it again whenever the returned session re-dials the VM, for instance after the host
+// slept, until the session is closed.
+func MountHost(api libmachine.API, path string, ip net.IP, port string, config MountConfig) (*sshutil.Session, error) {
 	host, err := CheckIfApiExistsAndLoad(api)
 	if err != nil {
-		return errors.Wrap(err, "Error checking that api exists and loading it")
+		return nil, errors.Wrap(err, "Error checking that api exists and loading it")
 	}
 	if ip == nil {
 		ip, err = GetVMHostIP(host)
 		if err != nil {
-			return errors.Wrap(err, "Error getting the host IP address to use from within the VM")
+			return nil, errors.Wrap(err, "Error getting the host IP address to use from within the VM")
 		}
 	}
-	host.RunSSHCommand(GetMountCleanupCommand(path))
-	mountCmd, err := GetMountCommand(ip, path, port)
+	mountCmd, err := GetMountCommand(ip, path, port, config)
 	if err != nil {
-		return errors.Wrap(err, "Error getting mount command")
+		return nil, errors.Wrap(err, "Error getting mount command")
 	}
-	_, err = host.RunSSHCommand(mountCmd)
+	session, err := sshutil.NewSession(host.Driver)
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "Error creating ssh session")
 	}
-	return nil
+	err = session.OnConnect(func(c *ssh.Client) error {
+		// A mount left by a dropped connection is stale.
+		sshutil.RunCommand(c, GetMountCleanupCommand(path))
+		return errors.Wrap(sshutil.RunCommand(c, mountCmd), "Error running mount command")
+	})
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	return session, nil
 }
 
 // GetVMHostIP gets the ip address to be used for mapping host -> VM and VM -> host
@@ -457,6 +860,9 @@ func GetVMHostIP(host *host.Host) (net.IP, error) {
 	switch host.DriverName {
 	case "kvm":
 		return net.ParseIP("192.168.42.1"), nil
+	case "kvm2":
+		// The gateway of kvm2PrivateCIDR, the driver's private network.
+		return net.ParseIP("192.168.39.1"), nil
 	case "hyperv":
 		re := regexp.MustCompile(`"VSwitch": "(.*?)",`)
 		// TODO(aprindle) Change this to deserialize the driver instead
@@ -467,7 +873,10 @@ func GetVMHostIP(host *host.Host) (net.IP, error) {
 		}
 		return ip, nil
 	case "virtualbox":
-		out, err := exec.Command(detectVBoxManageCmd(), "showvminfo", "minikube", "--machinereadable").Output()
+		cmd := exec.Command(DetectVBoxManageCmd(), "showvminfo", host.Name, "--machinereadable")
+		r := console.StartCommand("the host", strings.Join(cmd.Args, " "))
+		out, err := cmd.Output()
+		r.Done(out, err)
 		if err != nil {
 			return []byte{}, errors.Wrap(err, "Error running vboxmanage command")
 		}
@@ -478,7 +887,7 @@ func GetVMHostIP(host *host.Host) (net.IP, error) {
 			return []byte{}, errors.Wrap(err, "Error getting VM/Host IP address")
 		}
 		return ip, nil
-	case "xhyve":
+	case "xhyve", "hyperkit":
 		return net.ParseIP("192.168.64.1"), nil
 	default:
 		return []byte{}, errors.New("Error, attempted to get host ip address for unsupported driver")
@@ -546,7 +955,7 @@ func EnsureMinikubeRunningOrExit(api libmachine.API, exitStatus int) {
 		os.Exit(1)
 	}
 	if s != state.Running.String() {
-		fmt.Fprintln(os.Stderr, "minikube is not currently running so the service cannot be accessed")
+		console.ErrLn("minikube is not currently running so the service cannot be accessed")
 		os.Exit(exitStatus)
 	}
 }
@@ -554,15 +963,10 @@ func EnsureMinikubeRunningOrExit(api libmachine.API, exitStatus int) {
 // RunCommand executes commands for both the local and driver implementations
 func RunCommand(h *host.Host, command string, sudo bool) (string, error) {
 	if h.Driver.DriverName() == "none" {
-		cmd := exec.Command("/bin/sh", "-c", command)
-		if sudo {
-			cmd = exec.Command("sudo", "/bin/sh", "-c", command)
-		}
-		out, err := cmd.CombinedOutput()
-		if err != nil {
-			return "", err
-		}
-		return string(out), nil
+		return none.RunCommand(command, sudo)
 	}
-	return h.RunSSHCommand(command)
+	r := console.StartCommand("the VM", command)
+	out, err := h.RunSSHCommand(command)
+	r.Done([]byte(out), err)
+	return out, err
 }
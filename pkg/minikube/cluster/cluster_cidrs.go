@@ -0,0 +1,182 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/util"
+)
+
+// kvm2PrivateCIDR is the range of the kvm2 driver's private network, which the host reaches the VM on.
+const kvm2PrivateCIDR = "192.168.39.0/24"
+
+// resetClusterDataCommand stops localkube and removes its etcd data, which holds every object of the cluster.
+var resetClusterDataCommand = fmt.Sprintf("sudo systemctl stop localkube.service || true; sudo rm -rf %s", path.Join(util.DefaultLocalkubeDirectory, "etcd"))
+
+// defaultDNSClusterIP matches the service IP of kube-dns in the bundled manifests.
+var defaultDNSClusterIP = regexp.MustCompile(`(?m)^([ \t]*clusterIP:[ \t]*)` + regexp.QuoteMeta(util.DefaultDNSIP) + `[ \t]*$`)
+
+func (k KubernetesConfig) serviceCIDR() string {
+	if k.ServiceCIDR == "" {
+		return util.DefaultServiceCIDR
+	}
+	return k.ServiceCIDR
+}
+
+func (k KubernetesConfig) podCIDR() string {
+	if k.PodCIDR == "" {
+		return util.DefaultPodCIDR
+	}
+	return k.PodCIDR
+}
+
+// serviceIP returns the IP of the kubernetes service, which the apiserver certificate has to be valid for.
+func (k KubernetesConfig) serviceIP() (net.IP, error) {
+	serviceNet, err := util.ParseClusterCIDR(k.serviceCIDR())
+	if err != nil {
+		return nil, err
+	}
+	return util.ServiceIP(serviceNet)
+}
+
+// dnsIP returns the IP of the kube-dns service, which the kubelet points pods to.
+func (k KubernetesConfig) dnsIP() (net.IP, error) {
+	serviceNet, err := util.ParseClusterCIDR(k.serviceCIDR())
+	if err != nil {
+		return nil, err
+	}
+	return util.DNSIP(serviceNet)
+}
+
+// hostNetworkCIDR returns the range of the network the host reaches the VM on, if the driver's is known.
+func hostNetworkCIDR(config MachineConfig) string {
+	switch config.VMDriver {
+	case "virtualbox":
+		return config.HostOnlyCIDR
+	case "kvm2":
+		return kvm2PrivateCIDR
+	}
+	return ""
+}
+
+// ValidateClusterCIDRs checks the service and pod ranges of k. They can't overlap each other, nor
+// the network the host reaches the VM on, as the VM would route the host's addresses into the cluster.
+func ValidateClusterCIDRs(k KubernetesConfig, config MachineConfig) error {
+	serviceNet, err := util.ParseClusterCIDR(k.serviceCIDR())
+	if err != nil {
+		return errors.Wrap(err, "Invalid --service-cluster-ip-range")
+	}
+	podNet, err := util.ParseClusterCIDR(k.podCIDR())
+	if err != nil {
+		return errors.Wrap(err, "Invalid --pod-network-cidr")
+	}
+	if util.CIDRsOverlap(serviceNet, podNet) {
+		return fmt.Errorf("The service cluster IP range %s overlaps the pod network CIDR %s, they must not share any address", serviceNet, podNet)
+	}
+
+	cidr := hostNetworkCIDR(config)
+	if cidr == "" {
+		return nil
+	}
+	_, hostNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return errors.Wrapf(err, "Error parsing the %s host network %s", config.VMDriver, cidr)
+	}
+	for _, r := range []struct {
+		name  string
+		ipNet *net.IPNet
+	}{
+		{"service cluster IP range", serviceNet},
+		{"pod network CIDR", podNet},
+	} {
+		if util.CIDRsOverlap(r.ipNet, hostNet) {
+			return fmt.Errorf("The %s %s overlaps %s, the network the host reaches the %s VM on. Pick a range outside of it", r.name, r.ipNet, hostNet, config.VMDriver)
+		}
+	}
+	return nil
+}
+
+// StoredClusterCIDRs returns the service and pod ranges the named machine's cluster
+// was last started with, which starts keep unless they are given others.
+func StoredClusterCIDRs(name string) (string, string) {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not using the cluster ranges of %s: %s", name, err)
+	}
+	return s.ServiceCIDR, s.PodCIDR
+}
+
+// ClusterCIDRsChanged returns the ranges of k which differ from those the named machine's
+// cluster was last started with. Services and pods keep the IPs they were given, so
+// moving them to other ranges requires removing the cluster's data.
+func ClusterCIDRsChanged(name string, k KubernetesConfig) []ConfigChange {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not checking the cluster ranges of %s: %s", name, err)
+	}
+	if s.KubernetesConfig == "" {
+		// The cluster was never started.
+		return nil
+	}
+	// Clusters started before the ranges were recorded have the default ones.
+	last := KubernetesConfig{ServiceCIDR: s.ServiceCIDR, PodCIDR: s.PodCIDR}
+	changes := []ConfigChange{}
+	if last.serviceCIDR() != k.serviceCIDR() {
+		changes = append(changes, ConfigChange{Setting: "service-cluster-ip-range", Existing: last.serviceCIDR(), Requested: k.serviceCIDR()})
+	}
+	if last.podCIDR() != k.podCIDR() {
+		changes = append(changes, ConfigChange{Setting: "pod-network-cidr", Existing: last.podCIDR(), Requested: k.podCIDR()})
+	}
+	return changes
+}
+
+// ResetClusterData stops localkube and removes the cluster's data, so that it
+// starts again without any of the objects created in it.
+func ResetClusterData(api libmachine.API) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error checking that api exists and loading it")
+	}
+	output, err := RunCommand(h, resetClusterDataCommand, true)
+	glog.Infoln(output)
+	if err != nil {
+		return errors.Wrap(err, "Error removing the cluster's data")
+	}
+	return nil
+}
+
+// AddonWithDNSIP returns the addon with the kube-dns service of its manifests given the DNS IP of k.
+func AddonWithDNSIP(a *assets.Addon, k KubernetesConfig) (*assets.Addon, error) {
+	ip, err := k.dnsIP()
+	if err != nil {
+		return nil, err
+	}
+	if ip.String() == util.DefaultDNSIP {
+		return a, nil
+	}
+	return a.WithContents(func(data []byte) []byte {
+		return defaultDNSClusterIP.ReplaceAll(data, []byte("${1}"+ip.String()))
+	}), nil
+}
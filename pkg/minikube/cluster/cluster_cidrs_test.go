@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestValidateClusterCIDRs(t *testing.T) {
+	var cases = []struct {
+		description string
+		k           KubernetesConfig
+		config      MachineConfig
+		err         string
+	}{
+		{
+			description: "defaults",
+			config:      MachineConfig{VMDriver: "virtualbox", HostOnlyCIDR: "192.168.99.1/24"},
+		},
+		{
+			description: "other ranges",
+			k:           KubernetesConfig{ServiceCIDR: "172.30.0.0/16", PodCIDR: "172.31.0.0/16"},
+			config:      MachineConfig{VMDriver: "kvm2"},
+		},
+		{
+			description: "service range holding the pod range",
+			k:           KubernetesConfig{ServiceCIDR: "10.0.0.0/8"},
+			err:         "overlaps the pod network CIDR 10.180.1.0/24",
+		},
+		{
+			description: "service range overlapping the host-only network",
+			k:           KubernetesConfig{ServiceCIDR: "192.168.0.0/16"},
+			config:      MachineConfig{VMDriver: "virtualbox", HostOnlyCIDR: "192.168.99.1/24"},
+			err:         "service cluster IP range 192.168.0.0/16 overlaps 192.168.99.0/24",
+		},
+		{
+			description: "pod range overlapping the kvm2 private network",
+			k:           KubernetesConfig{PodCIDR: "192.168.39.128/25"},
+			config:      MachineConfig{VMDriver: "kvm2"},
+			err:         "pod network CIDR 192.168.39.128/25 overlaps 192.168.39.0/24",
+		},
+		{
+			description: "unknown host network",
+			k:           KubernetesConfig{ServiceCIDR: "192.168.64.0/24"},
+			config:      MachineConfig{VMDriver: "xhyve"},
+		},
+		{
+			description: "invalid range",
+			k:           KubernetesConfig{ServiceCIDR: "10.96.0.0/30"},
+			err:         "Invalid --service-cluster-ip-range",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateClusterCIDRs(test.k, test.config)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("Expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestClusterCIDRsChanged(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+	moved := KubernetesConfig{ServiceCIDR: "172.30.0.0/16"}
+
+	if changes := ClusterCIDRsChanged(name, moved); len(changes) != 0 {
+		t.Errorf("Expected no changes before the first start, got %+v", changes)
+	}
+	recordKubernetesConfig(name, KubernetesConfig{})
+	if changes := ClusterCIDRsChanged(name, KubernetesConfig{}); len(changes) != 0 {
+		t.Errorf("Expected no changes for the same ranges, got %+v", changes)
+	}
+	expected := []ConfigChange{{Setting: "service-cluster-ip-range", Existing: "10.0.0.0/24", Requested: "172.30.0.0/16"}}
+	if changes := ClusterCIDRsChanged(name, moved); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+
+	recordKubernetesConfig(name, moved)
+	if service, pod := StoredClusterCIDRs(name); service != "172.30.0.0/16" || pod != "10.180.1.0/24" {
+		t.Errorf("Expected the ranges of the last start to be stored, got %s and %s", service, pod)
+	}
+	if changes := ClusterCIDRsChanged(name, moved); len(changes) != 0 {
+		t.Errorf("Expected no changes once started with the ranges, got %+v", changes)
+	}
+}
+
+func TestAddonWithDNSIP(t *testing.T) {
+	dns := assets.Addons["kube-dns"]
+	original := string(dns.Assets[2].Bytes())
+
+	moved, err := AddonWithDNSIP(dns, KubernetesConfig{ServiceCIDR: "172.30.0.0/16"})
+	if err != nil {
+		t.Fatalf("Error rewriting the addon: %s", err)
+	}
+	svc := string(moved.Assets[2].Bytes())
+	if !strings.Contains(svc, "\n  clusterIP: 172.30.0.10\n") || strings.Contains(svc, "10.0.0.10") {
+		t.Errorf("Expected the kube-dns service IP to be moved into the range:\n%s", svc)
+	}
+	if string(dns.Assets[2].Bytes()) != original {
+		t.Error("The bundled addon was modified")
+	}
+	kept, err := AddonWithDNSIP(dns, KubernetesConfig{})
+	if err != nil || kept != dns {
+		t.Errorf("Expected the addon to be left alone with the default range, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// ClusterConfigVersion is the version of the format of the cluster config file.
+const ClusterConfigVersion = 1
+
+// clusterConfigFile is the name of the file, in the machine directory, the cluster config is kept in.
+const clusterConfigFile = "cluster-config.json"
+
+// ClusterConfig is the complete config a profile's cluster was last started with, which the
+// next starts are based on.
+type ClusterConfig struct {
+	Version          int
+	MachineConfig    MachineConfig
+	KubernetesConfig KubernetesConfig
+}
+
+func clusterConfigPath(name string) string {
+	return filepath.Join(constants.GetMinipath(), "machines", name, clusterConfigFile)
+}
+
+// SaveClusterConfig records the config the named machine's cluster was started with. Like
+// the start state, it is only written once the machine directory exists, and removed along with it.
+func SaveClusterConfig(name string, m MachineConfig, k KubernetesConfig) error {
+	if _, err := os.Stat(filepath.Dir(clusterConfigPath(name))); err != nil {
+		glog.Infof("Not recording the cluster config of %s, machine directory does not exist", name)
+		return nil
+	}
+	data, err := json.MarshalIndent(ClusterConfig{Version: ClusterConfigVersion, MachineConfig: m, KubernetesConfig: k}, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling cluster config")
+	}
+	return errors.Wrap(ioutil.WriteFile(clusterConfigPath(name), data, 0600), "Error writing cluster config")
+}
+
+// LoadClusterConfig returns the config the named machine's cluster was last started with. For
+// clusters started by versions which didn't record it, it is gathered from the stored driver
+// config and the start state. nil is returned if the machine has never been started.
+func LoadClusterConfig(api libmachine.API, name string) (*ClusterConfig, error) {
+	data, err := ioutil.ReadFile(clusterConfigPath(name))
+	if os.IsNotExist(err) {
+		return migrateClusterConfig(api, name)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error reading cluster config")
+	}
+	var c ClusterConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling cluster config")
+	}
+	if c.Version > ClusterConfigVersion {
+		return nil, fmt.Errorf("The cluster config of %s has version %d, which is newer than this minikube reads (%d)", name, c.Version, ClusterConfigVersion)
+	}
+	return &c, nil
+}
+
+// migrateClusterConfig gathers the config of the named machine's cluster from the driver
+// config it was created with and its start state, which is what older versions stored.
+func migrateClusterConfig(api libmachine.API, name string) (*ClusterConfig, error) {
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error checking that machine exists: %s", name)
+	}
+	if !exists {
+		return nil, nil
+	}
+	h, err := api.Load(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading machine: %s", name)
+	}
+	c := &ClusterConfig{Version: ClusterConfigVersion}
+	c.MachineConfig.VMDriver = h.DriverName
+	if h.DriverName != "none" {
+		var d storedDriverConfig
+		if err := decodeDriverConfig(h, &d); err != nil {
+			return nil, errors.Wrap(err, "Error decoding driver config")
+		}
+		c.MachineConfig.Memory = d.Memory
+		if c.MachineConfig.Memory == 0 {
+			c.MachineConfig.Memory = d.MemSize
+		}
+		c.MachineConfig.CPUs = d.CPU
+		c.MachineConfig.DiskSize = d.DiskSize
+		c.MachineConfig.ExtraDisks = d.ExtraDisks
+		c.MachineConfig.ExtraDiskSize = d.ExtraDiskSize
+		// The driver config only has the ISO's path in the cache, not the URL it was downloaded from.
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		return nil, err
+	}
+	k := &c.KubernetesConfig
+	k.KubernetesVersion = s.RunningKubernetesVersion
+	if k.KubernetesVersion == "" {
+		k.KubernetesVersion = s.KubernetesVersion
+	}
+	k.KubernetesChannel = s.KubernetesChannel
+	k.ContainerRuntime = s.ContainerRuntime
+	k.APIServerSANs = s.APIServerSANs
+	k.ServiceCIDR = s.ServiceCIDR
+	k.PodCIDR = s.PodCIDR
+	return c, nil
+}
+
+// Where the value of a setting comes from, in the order they take precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceProfile = "profile"
+	SourceConfig  = "config"
+	SourceDefault = "default"
+)
+
+// A StoredSetting is a setting of start kept in the cluster config, which later starts use
+// unless they are given another value.
+type StoredSetting struct {
+	// Name is the name of the flag of start, and of the minikube config setting.
+	Name    string
+	Default string
+	stored  func(c ClusterConfig) string
+}
+
+func storedInt(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+// StoredSettings are the settings kept in the cluster config, the driver first, as the
+// driver-specific config values of the others depend on it.
+var StoredSettings = []StoredSetting{
+	{"vm-driver", constants.DefaultVMDriver, func(c ClusterConfig) string { return c.MachineConfig.VMDriver }},
+	{"iso-url", constants.DefaultIsoUrl, func(c ClusterConfig) string { return c.MachineConfig.MinikubeISO }},
+	{"memory", strconv.Itoa(constants.DefaultMemory), func(c ClusterConfig) string { return storedInt(c.MachineConfig.Memory) }},
+	{"cpus", strconv.Itoa(constants.DefaultCPUS), func(c ClusterConfig) string { return storedInt(c.MachineConfig.CPUs) }},
+	{"disk-size", constants.DefaultDiskSize, func(c ClusterConfig) string {
+		if c.MachineConfig.DiskSize == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%dMB", c.MachineConfig.DiskSize)
+	}},
+	{"xhyve-disk-driver", constants.DefaultXhyveDisk, func(c ClusterConfig) string { return c.MachineConfig.XhyveDiskDriver }},
+	{"host-only-cidr", constants.DefaultHostOnlyCIDR, func(c ClusterConfig) string { return c.MachineConfig.HostOnlyCIDR }},
+	{"hyperv-virtual-switch", "", func(c ClusterConfig) string { return c.MachineConfig.HypervVirtualSwitch }},
+	{"kvm-network", constants.DefaultKvmNetwork, func(c ClusterConfig) string { return c.MachineConfig.KvmNetwork }},
+	// A version resolved from a channel is kept by the channel, which resolves to it again.
+	{"kubernetes-version", constants.DefaultKubernetesVersion, func(c ClusterConfig) string {
+		if c.KubernetesConfig.KubernetesChannel != "" {
+			return c.KubernetesConfig.KubernetesChannel
+		}
+		return c.KubernetesConfig.KubernetesVersion
+	}},
+	{"apiserver-name", constants.APIServerName, func(c ClusterConfig) string { return c.KubernetesConfig.APIServerName }},
+	{"container-runtime", "", func(c ClusterConfig) string { return c.KubernetesConfig.ContainerRuntime }},
+	{"network-plugin", "", func(c ClusterConfig) string { return c.KubernetesConfig.NetworkPlugin }},
+	{"feature-gates", "", func(c ClusterConfig) string { return c.KubernetesConfig.FeatureGates }},
+	{"dns-domain", "", func(c ClusterConfig) string { return c.KubernetesConfig.DNSDomain }},
+	{config.ImageRepository, "", func(c ClusterConfig) string { return c.KubernetesConfig.ImageRepository }},
+}
+
+// IsStoredSetting returns whether the named setting is kept in the cluster config.
+func IsStoredSetting(name string) bool {
+	for _, s := range StoredSettings {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveSetting is the value a stored setting resolves to, and where it comes from.
+type EffectiveSetting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// SettingSources are where the values of the stored settings come from.
+type SettingSources struct {
+	// Flags are the values of the flags given, by name.
+	Flags map[string]string
+	// Env are the values of the MINIKUBE_ environment variables of the settings, by name.
+	Env map[string]string
+	// Cluster is the config the cluster was last started with, if it was.
+	Cluster *ClusterConfig
+	// Config is the minikube config of the profile.
+	Config config.MinikubeConfig
+}
+
+// SettingsEnv returns the values of the MINIKUBE_ environment variables of the stored
+// settings which getenv has, by setting name.
+func SettingsEnv(getenv func(string) string) map[string]string {
+	var names []string
+	for _, s := range StoredSettings {
+		names = append(names, s.Name)
+	}
+	return config.EnvValues(names, getenv)
+}
+
+// Resolve returns the value of each stored setting, from the first source which has it of:
+// the flags, the environment, the cluster config and the minikube config, else its default.
+// Like start, a driver-specific minikube config value, such as memory.virtualbox, is taken
+// over the cluster config's.
+func (s SettingSources) Resolve() []EffectiveSetting {
+	var settings []EffectiveSetting
+	driver := constants.DefaultVMDriver
+	for _, setting := range StoredSettings {
+		e := s.resolve(setting, driver)
+		if setting.Name == "vm-driver" {
+			driver = e.Value
+		}
+		settings = append(settings, e)
+	}
+	return settings
+}
+
+func (s SettingSources) resolve(setting StoredSetting, driver string) EffectiveSetting {
+	e := EffectiveSetting{Name: setting.Name}
+	if v, ok := s.Flags[setting.Name]; ok {
+		e.Value, e.Source = v, SourceFlag
+		return e
+	}
+	if v, ok := s.Env[setting.Name]; ok {
+		e.Value, e.Source = v, SourceEnv
+		return e
+	}
+	if v, ok := s.Config[config.DriverKey(setting.Name, driver)]; ok && isDriverSetting(setting.Name) {
+		e.Value, e.Source = fmt.Sprintf("%v", v), SourceConfig
+		return e
+	}
+	if s.Cluster != nil {
+		if v := setting.stored(*s.Cluster); v != "" {
+			e.Value, e.Source = v, SourceProfile
+			return e
+		}
+	}
+	if v, ok := s.Config[setting.Name]; ok {
+		e.Value, e.Source = fmt.Sprintf("%v", v), SourceConfig
+		return e
+	}
+	e.Value, e.Source = setting.Default, SourceDefault
+	return e
+}
+
+func isDriverSetting(name string) bool {
+	for _, s := range config.DriverSettings {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
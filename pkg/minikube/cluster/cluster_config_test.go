@@ -0,0 +1,158 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestSaveClusterConfig(t *testing.T) {
+	defer os.RemoveAll(makeMachineDir(t))
+	api := tests.NewMockAPI()
+
+	m := MachineConfig{MinikubeISO: "https://mirror.lan/minikube.iso", Memory: 4096, CPUs: 4, VMDriver: "kvm2", InsecureRegistry: []string{"10.0.0.0/24"}}
+	k := KubernetesConfig{KubernetesVersion: "v1.7.0", KubernetesChannel: "stable", ContainerRuntime: "cri-o"}
+	if err := SaveClusterConfig("minikube", m, k); err != nil {
+		t.Fatalf("Error saving cluster config: %s", err)
+	}
+	c, err := LoadClusterConfig(api, "minikube")
+	if err != nil {
+		t.Fatalf("Error loading cluster config: %s", err)
+	}
+	expected := &ClusterConfig{Version: ClusterConfigVersion, MachineConfig: m, KubernetesConfig: k}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("Expected cluster config %+v, got %+v", expected, c)
+	}
+
+	if err := ioutil.WriteFile(clusterConfigPath("minikube"), []byte(`{"Version": 2}`), 0600); err != nil {
+		t.Fatalf("Error writing cluster config: %s", err)
+	}
+	if _, err := LoadClusterConfig(api, "minikube"); err == nil {
+		t.Error("Expected an error loading a cluster config of a newer version")
+	}
+}
+
+func TestMigrateClusterConfig(t *testing.T) {
+	defer os.RemoveAll(makeMachineDir(t))
+	api := tests.NewMockAPI()
+
+	if c, err := LoadClusterConfig(api, "minikube"); err != nil || c != nil {
+		t.Fatalf("Expected no cluster config without a machine, got %+v, %v", c, err)
+	}
+
+	api.Hosts["minikube"] = &host.Host{Name: "minikube", DriverName: "virtualbox", RawDriver: []byte(tests.VBoxConfig)}
+	writeStartState("minikube", StartState{Phase: PhaseAuthConfigured, KubernetesVersion: "v1.7.0", RunningKubernetesVersion: "v1.6.4",
+		ServiceCIDR: "10.96.0.0/12", ContainerRuntime: "containerd"})
+	c, err := LoadClusterConfig(api, "minikube")
+	if err != nil {
+		t.Fatalf("Error loading cluster config: %s", err)
+	}
+	expected := &ClusterConfig{
+		Version:          ClusterConfigVersion,
+		MachineConfig:    MachineConfig{VMDriver: "virtualbox", Memory: 16384, CPUs: 4, DiskSize: 20000},
+		KubernetesConfig: KubernetesConfig{KubernetesVersion: "v1.6.4", ServiceCIDR: "10.96.0.0/12", ContainerRuntime: "containerd"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("Expected cluster config %+v, got %+v", expected, c)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	stored := &ClusterConfig{
+		MachineConfig:    MachineConfig{VMDriver: "kvm2", Memory: 4096, DiskSize: 30000},
+		KubernetesConfig: KubernetesConfig{KubernetesVersion: "v1.7.0", KubernetesChannel: "stable", ContainerRuntime: "cri-o"},
+	}
+	var cases = []struct {
+		description string
+		sources     SettingSources
+		expected    map[string]EffectiveSetting
+	}{
+		{
+			description: "defaults",
+			expected: map[string]EffectiveSetting{
+				"vm-driver": {Name: "vm-driver", Value: "virtualbox", Source: SourceDefault},
+				"memory":    {Name: "memory", Value: "2048", Source: SourceDefault},
+			},
+		},
+		{
+			description: "profile over config",
+			sources:     SettingSources{Cluster: stored, Config: config.MinikubeConfig{"memory": 8192.0, "cpus": 3.0}},
+			expected: map[string]EffectiveSetting{
+				"vm-driver":          {Name: "vm-driver", Value: "kvm2", Source: SourceProfile},
+				"memory":             {Name: "memory", Value: "4096", Source: SourceProfile},
+				"cpus":               {Name: "cpus", Value: "3", Source: SourceConfig},
+				"disk-size":          {Name: "disk-size", Value: "30000MB", Source: SourceProfile},
+				"kubernetes-version": {Name: "kubernetes-version", Value: "stable", Source: SourceProfile},
+				"container-runtime":  {Name: "container-runtime", Value: "cri-o", Source: SourceProfile},
+				"network-plugin":     {Name: "network-plugin", Value: "", Source: SourceDefault},
+			},
+		},
+		{
+			description: "driver-specific config over profile",
+			sources:     SettingSources{Cluster: stored, Config: config.MinikubeConfig{"memory.kvm2": 6144.0, "memory.virtualbox": 1024.0}},
+			expected: map[string]EffectiveSetting{
+				"memory": {Name: "memory", Value: "6144", Source: SourceConfig},
+			},
+		},
+		{
+			description: "env over profile",
+			sources:     SettingSources{Env: map[string]string{"memory": "3072"}, Cluster: stored},
+			expected: map[string]EffectiveSetting{
+				"memory": {Name: "memory", Value: "3072", Source: SourceEnv},
+			},
+		},
+		{
+			description: "flags over everything",
+			sources: SettingSources{Flags: map[string]string{"memory": "1024", "vm-driver": "xhyve"}, Env: map[string]string{"memory": "3072"},
+				Cluster: stored, Config: config.MinikubeConfig{"memory.xhyve": 6144.0}},
+			expected: map[string]EffectiveSetting{
+				"vm-driver":          {Name: "vm-driver", Value: "xhyve", Source: SourceFlag},
+				"memory":             {Name: "memory", Value: "1024", Source: SourceFlag},
+				"kubernetes-version": {Name: "kubernetes-version", Value: "stable", Source: SourceProfile},
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			settings := test.sources.Resolve()
+			if len(settings) != len(StoredSettings) {
+				t.Fatalf("Expected %d settings, got %d", len(StoredSettings), len(settings))
+			}
+			for _, s := range settings {
+				if expected, ok := test.expected[s.Name]; ok && s != expected {
+					t.Errorf("Expected %+v, got %+v", expected, s)
+				}
+			}
+		})
+	}
+}
+
+func TestSettingsEnv(t *testing.T) {
+	env := map[string]string{"MINIKUBE_MEMORY": "4096", "MINIKUBE_KUBERNETES_VERSION": "v1.7.0", "MINIKUBE_UNKNOWN": "1"}
+	expected := map[string]string{"memory": "4096", "kubernetes-version": "v1.7.0"}
+	if got := SettingsEnv(func(key string) string { return env[key] }); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the settings %v, got %v", expected, got)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/docker/machine/libmachine/drivers"
 	cfg "k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/hyperkit"
 )
 
 func createVMwareFusionHost(config MachineConfig) drivers.Driver {
@@ -75,7 +76,18 @@ func createXhyveHost(config MachineConfig) *xhyveDriver {
 	}
 }
 
-func detectVBoxManageCmd() string {
+func createHyperkitHost(config MachineConfig) drivers.Driver {
+	d := hyperkit.NewDriver(cfg.GetMachineName(), constants.GetMinipath())
+	d.Boot2DockerURL = config.Downloader.GetISOFileURI(config.MinikubeISO)
+	d.Memory = config.Memory
+	d.CPU = config.CPUs
+	d.DiskSize = config.DiskSize
+	d.Cmdline = "loglevel=3 user=docker console=ttyS0 console=tty0 noembed nomodeset norestore waitusb=10 base host=" + cfg.GetMachineName()
+	return d
+}
+
+// DetectVBoxManageCmd returns the path of VBoxManage, or its name if it isn't found.
+func DetectVBoxManageCmd() string {
 	cmd := "VBoxManage"
 	if path, err := exec.LookPath(cmd); err == nil {
 		return path
@@ -24,6 +24,7 @@ import (
 	"github.com/docker/machine/libmachine/drivers"
 	cfg "k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/kvm2"
 	"k8s.io/minikube/pkg/minikube/machine/drivers/none"
 )
 
@@ -61,7 +62,25 @@ func createKVMHost(config MachineConfig) *kvmDriver {
 	}
 }
 
-func detectVBoxManageCmd() string {
+func createKVM2Host(config MachineConfig) *kvm2.Driver {
+	d := kvm2.NewDriver(cfg.GetMachineName(), constants.GetMinipath())
+	d.Memory = config.Memory
+	d.CPU = config.CPUs
+	d.DiskSize = config.DiskSize
+	d.Network = config.KvmNetwork
+	d.Boot2DockerURL = config.Downloader.GetISOFileURI(config.MinikubeISO)
+	d.ISO = filepath.Join(constants.GetMinipath(), "machines", cfg.GetMachineName(), "boot2docker.iso")
+	d.DiskPath = filepath.Join(constants.GetMinipath(), "machines", cfg.GetMachineName(), fmt.Sprintf("%s.rawdisk", cfg.GetMachineName()))
+	d.ExtraDisks = config.ExtraDisks
+	d.ExtraDiskSize = config.ExtraDiskSize
+	d.GPU = config.GPU
+	d.MountHostPath = config.SharedFolder.HostPath
+	d.MountGuestPath = config.SharedFolder.GuestPath
+	return d
+}
+
+// DetectVBoxManageCmd returns the path of VBoxManage, or its name if it isn't found.
+func DetectVBoxManageCmd() string {
 	cmd := "VBoxManage"
 	if path, err := exec.LookPath(cmd); err == nil {
 		return path
@@ -18,15 +18,21 @@ package cluster
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/provision"
@@ -34,7 +40,10 @@ import (
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
 	"k8s.io/minikube/pkg/minikube/tests"
+	"k8s.io/minikube/pkg/util"
 )
 
 type MockDownloader struct{}
@@ -48,6 +57,51 @@ var defaultMachineConfig = MachineConfig{
 	Downloader:  MockDownloader{},
 }
 
+func TestNewHostConfig(t *testing.T) {
+	config := MachineConfig{
+		VMDriver:         "virtualbox",
+		MinikubeISO:      "https://storage.googleapis.com/minikube/iso/minikube-v0.18.0.iso",
+		Memory:           4096,
+		CPUs:             4,
+		DiskSize:         30000,
+		HostOnlyCIDR:     "192.168.100.1/24",
+		DockerEnv:        []string{"FOO=BAR"},
+		InsecureRegistry: []string{"example.com:5000"},
+		Downloader:       util.DefaultDownloader{},
+		ExtraDisks:       2,
+		ExtraDiskSize:    10000,
+	}
+
+	hc, err := NewHostConfig(config)
+	if err != nil {
+		t.Fatalf("Error building host config: %s", err)
+	}
+	d, ok := hc.Driver.(*virtualbox.Driver)
+	if !ok {
+		t.Fatalf("Expected a virtualbox driver, got: %T", hc.Driver)
+	}
+	if d.Memory != 4096 || d.CPU != 4 || d.DiskSize != 30000 {
+		t.Errorf("Driver resources did not match config, got: memory %d, cpus %d, disk %d", d.Memory, d.CPU, d.DiskSize)
+	}
+	if d.HostOnlyCIDR != config.HostOnlyCIDR {
+		t.Errorf("Expected host-only CIDR %s, got: %s", config.HostOnlyCIDR, d.HostOnlyCIDR)
+	}
+	if d.ExtraDisks != 2 || d.ExtraDiskSize != 10000 {
+		t.Errorf("Extra disks did not match config, got: %d disks of %d MB", d.ExtraDisks, d.ExtraDiskSize)
+	}
+	if d.Boot2DockerURL != config.Downloader.GetISOFileURI(config.MinikubeISO) {
+		t.Errorf("Expected ISO URL %s, got: %s", config.Downloader.GetISOFileURI(config.MinikubeISO), d.Boot2DockerURL)
+	}
+	if !reflect.DeepEqual(hc.EngineOptions.Env, config.DockerEnv) || !reflect.DeepEqual(hc.EngineOptions.InsecureRegistry, config.InsecureRegistry) {
+		t.Errorf("Engine options did not match config, got: %+v", hc.EngineOptions)
+	}
+
+	config.VMDriver = "foo"
+	if _, err := NewHostConfig(config); err == nil {
+		t.Error("Expected an error building a host config for an unknown driver")
+	}
+}
+
 func TestCreateHost(t *testing.T) {
 	api := tests.NewMockAPI()
 
@@ -175,7 +229,7 @@ func TestStartHostExists(t *testing.T) {
 	provision.SetDetector(md)
 
 	// This should pass without calling Create because the host exists already.
-	h, err := StartHost(api, defaultMachineConfig)
+	h, _, err := StartHost(context.Background(), api, defaultMachineConfig)
 	if err != nil {
 		t.Fatal("Error starting host.")
 	}
@@ -190,6 +244,111 @@ func TestStartHostExists(t *testing.T) {
 	}
 }
 
+func TestStartHostCorruptConfig(t *testing.T) {
+	api := tests.NewMockAPI()
+	if _, err := createHost(api, defaultMachineConfig); err != nil {
+		t.Fatalf("Error creating host: %v", err)
+	}
+	api.LoadError = machine.ErrCorruptConfig{Name: config.GetMachineName(), Err: errors.New("unexpected end of JSON input")}
+
+	md := &tests.MockDetector{Provisioner: &tests.MockProvisioner{}}
+	provision.SetDetector(md)
+
+	if _, _, err := StartHost(context.Background(), api, defaultMachineConfig); err == nil {
+		t.Fatal("Expected an error starting a host with a corrupt config.")
+	}
+
+	api.SaveCalled = false
+	c := defaultMachineConfig
+	c.ForceRecreate = true
+	h, _, err := StartHost(context.Background(), api, c)
+	if err != nil {
+		t.Fatalf("Error recreating host: %v", err)
+	}
+	if !api.SaveCalled {
+		t.Fatal("Recreated host was not saved.")
+	}
+	if s, _ := h.Driver.GetState(); s != state.Running {
+		t.Fatalf("Recreated machine is not running. Currently in state: %s", s)
+	}
+}
+
+func TestStartHostMissingVM(t *testing.T) {
+	var missingTests = []struct {
+		description string
+		driverName  string
+		stateErr    error
+		missing     bool
+	}{
+		{
+			description: "virtualbox vm deleted",
+			driverName:  "virtualbox",
+			stateErr:    errors.New("machine does not exist"),
+			missing:     true,
+		},
+		{
+			description: "virtualbox vm unregistered",
+			driverName:  "virtualbox",
+			stateErr:    errors.New("VBoxManage: error: Could not find a registered machine named 'minikube'"),
+			missing:     true,
+		},
+		{
+			description: "kvm domain deleted",
+			driverName:  "kvm",
+			stateErr:    errors.New("virError(Code=42, Domain=10, Message='Domain not found: no domain with matching name 'minikube'')"),
+			missing:     true,
+		},
+		{
+			description: "xhyve vm deleted",
+			driverName:  "xhyve",
+			stateErr:    errors.New("open /Users/minikube/.minikube/machines/minikube/hyperkit.pid: no such file or directory"),
+			missing:     true,
+		},
+		{
+			description: "other state error",
+			driverName:  "virtualbox",
+			stateErr:    errors.New("VBoxManage not found"),
+		},
+	}
+
+	md := &tests.MockDetector{Provisioner: &tests.MockProvisioner{}}
+	provision.SetDetector(md)
+
+	for _, test := range missingTests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			api := tests.NewMockAPI()
+			h, err := createHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error creating host: %v", err)
+			}
+			h.DriverName = test.driverName
+			h.Driver = &tests.MockDriver{StateError: test.stateErr}
+
+			_, _, err = StartHost(context.Background(), api, defaultMachineConfig)
+			if err == nil {
+				t.Fatal("Expected an error starting a host whose state can't be read.")
+			}
+			if _, ok := err.(ErrMachineMissing); ok != test.missing {
+				t.Fatalf("Expected missing machine to be %t, got: %v", test.missing, err)
+			}
+			if !test.missing {
+				return
+			}
+
+			c := defaultMachineConfig
+			c.ForceRecreate = true
+			h, _, err = StartHost(context.Background(), api, c)
+			if err != nil {
+				t.Fatalf("Error recreating host: %v", err)
+			}
+			if s, _ := h.Driver.GetState(); s != state.Running {
+				t.Fatalf("Recreated machine is not running. Currently in state: %s", s)
+			}
+		})
+	}
+}
+
 func TestStartStoppedHost(t *testing.T) {
 	api := tests.NewMockAPI()
 	// Create an initial host.
@@ -203,7 +362,7 @@ func TestStartStoppedHost(t *testing.T) {
 
 	md := &tests.MockDetector{Provisioner: &tests.MockProvisioner{}}
 	provision.SetDetector(md)
-	h, err = StartHost(api, defaultMachineConfig)
+	h, _, err = StartHost(context.Background(), api, defaultMachineConfig)
 	if err != nil {
 		t.Fatal("Error starting host.")
 	}
@@ -230,7 +389,7 @@ func TestStartHost(t *testing.T) {
 	md := &tests.MockDetector{Provisioner: &tests.MockProvisioner{}}
 	provision.SetDetector(md)
 
-	h, err := StartHost(api, defaultMachineConfig)
+	h, _, err := StartHost(context.Background(), api, defaultMachineConfig)
 	if err != nil {
 		t.Fatal("Error starting host.")
 	}
@@ -251,6 +410,37 @@ func TestStartHost(t *testing.T) {
 	}
 }
 
+func TestStartHostSteps(t *testing.T) {
+	api := tests.NewMockAPI()
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+
+	var events bytes.Buffer
+	config := defaultMachineConfig
+	config.Steps = util.NewJSONStepReporter(&events, ioutil.Discard)
+	// Creating the host, then starting the existing one.
+	for i := 0; i < 2; i++ {
+		if _, _, err := StartHost(context.Background(), api, config); err != nil {
+			t.Fatalf("Error starting host: %s", err)
+		}
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(events.String()), "\n") {
+		var e util.StepEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("Error parsing event %q: %s", line, err)
+		}
+		got = append(got, e.Type+":"+e.Step)
+	}
+	expected := []string{
+		"started:creating-vm", "completed:creating-vm", "started:provisioning", "completed:provisioning",
+		"started:creating-vm", "completed:creating-vm", "started:provisioning", "completed:provisioning",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected events %q, got %q", expected, got)
+	}
+}
+
 func TestStartHostConfig(t *testing.T) {
 	api := tests.NewMockAPI()
 
@@ -264,7 +454,7 @@ func TestStartHostConfig(t *testing.T) {
 		Downloader: MockDownloader{},
 	}
 
-	h, err := StartHost(api, config)
+	h, _, err := StartHost(context.Background(), api, config)
 	if err != nil {
 		t.Fatal("Error starting host.")
 	}
@@ -275,17 +465,229 @@ func TestStartHostConfig(t *testing.T) {
 		}
 	}
 
-	for i := range h.HostOptions.EngineOptions.ArbitraryFlags {
-		if h.HostOptions.EngineOptions.ArbitraryFlags[i] != config.DockerOpt[i] {
-			t.Fatal("Docker flags were not set!")
+	// The default log rotation options follow the flags passed.
+	expectedFlags := append(append([]string{}, config.DockerOpt...), defaultLogFlags...)
+	if !reflect.DeepEqual(h.HostOptions.EngineOptions.ArbitraryFlags, expectedFlags) {
+		t.Fatalf("Docker flags were not set! Expected %v, got %v", expectedFlags, h.HostOptions.EngineOptions.ArbitraryFlags)
+	}
+
+}
+
+// hangingAPI is a MockAPI whose new hosts have a driver which hangs.
+type hangingAPI struct {
+	*tests.MockAPI
+	driver *tests.HangingDriver
+}
+
+func (api hangingAPI) NewHost(driverName string, rawDriver []byte) (*host.Host, error) {
+	h, err := api.MockAPI.NewHost(driverName, rawDriver)
+	if err != nil {
+		return nil, err
+	}
+	h.Driver = api.driver
+	return h, nil
+}
+
+func TestStartHostTimeoutRemovesNewVM(t *testing.T) {
+	d := tests.NewHangingDriver()
+	api := hangingAPI{MockAPI: tests.NewMockAPI(), driver: d}
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err := StartHost(ctx, api, defaultMachineConfig)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("Expected the start to time out, got: %v", err)
+	}
+	if !d.Removed() {
+		t.Error("Expected the half created VM to be removed")
+	}
+	if exists, _ := api.Exists(config.GetMachineName()); exists {
+		t.Error("Expected the half created machine to be removed from the store")
+	}
+}
+
+func TestStartHostTimeoutKeepsExistingVM(t *testing.T) {
+	d := tests.NewHangingDriver()
+	defer d.Release()
+	api := tests.NewMockAPI()
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %v", err)
+	}
+	d.CurrentState = state.Stopped
+	h.Driver = d
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = StartHost(ctx, api, defaultMachineConfig)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("Expected the start to time out, got: %v", err)
+	}
+	if d.Removed() {
+		t.Error("Did not expect the existing VM to be removed")
+	}
+}
+
+func TestStartHostWithRetriesTimeoutRemovesEarlierVM(t *testing.T) {
+	d := tests.NewHangingDriver()
+	d.Release()
+	api := hangingAPI{MockAPI: tests.NewMockAPI(), driver: d}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	// The first attempt creates the host and fails, the retry starts it and times out.
+	start := func(ctx context.Context, api libmachine.API, config MachineConfig) (*host.Host, bool, error) {
+		attempts++
+		if attempts == 1 {
+			h, err := newHost(api, config)
+			if err != nil {
+				t.Fatalf("Error building host: %v", err)
+			}
+			if err := api.Save(h); err != nil {
+				t.Fatalf("Error saving host: %v", err)
+			}
+			return nil, true, &util.RetriableError{Err: errors.New("Error configuring auth on host")}
 		}
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+	_, created, err := startHostWithRetries(ctx, api, defaultMachineConfig, 5, 0, start)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected the start to time out, got: %v", err)
+	}
+	if attempts != 2 || !created {
+		t.Errorf("Expected 2 attempts creating the host, got %d, created %t", attempts, created)
+	}
+	if !d.Removed() {
+		t.Error("Expected the VM created by the first attempt to be removed")
+	}
+	if exists, _ := api.Exists(config.GetMachineName()); exists {
+		t.Error("Expected the machine created by the first attempt to be removed from the store")
+	}
+}
+
+func TestStartHostWithRetriesTimeoutKeepsExistingVM(t *testing.T) {
+	d := tests.NewHangingDriver()
+	d.Release()
+	api := hangingAPI{MockAPI: tests.NewMockAPI(), driver: d}
+	h, err := newHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error building host: %v", err)
+	}
+	if err := api.Save(h); err != nil {
+		t.Fatalf("Error saving host: %v", err)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := func(ctx context.Context, api libmachine.API, config MachineConfig) (*host.Host, bool, error) {
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+	if _, created, err := startHostWithRetries(ctx, api, defaultMachineConfig, 5, 0, start); err == nil || created {
+		t.Fatalf("Expected the existing host to time out, got created %t, error %v", created, err)
+	}
+	if d.Removed() {
+		t.Error("Did not expect the existing VM to be removed")
+	}
+}
+
+func TestStartHostCreated(t *testing.T) {
+	api := tests.NewMockAPI()
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+
+	if _, created, err := StartHost(context.Background(), api, defaultMachineConfig); err != nil || !created {
+		t.Fatalf("Expected the host to be created, got created %t, error %v", created, err)
+	}
+	if _, created, err := StartHost(context.Background(), api, defaultMachineConfig); err != nil || created {
+		t.Fatalf("Expected the existing host to be started, got created %t, error %v", created, err)
+	}
+}
+
+func TestRemoveFailedHost(t *testing.T) {
+	var cases = []struct {
+		description string
+		created     bool
+		keepFailed  bool
+		removed     bool
+	}{
+		{
+			description: "created by the failed start",
+			created:     true,
+			removed:     true,
+		},
+		{
+			description: "existed before the failed start",
+		},
+		{
+			description: "created and kept",
+			created:     true,
+			keepFailed:  true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			d := tests.NewHangingDriver()
+			d.Release()
+			api := hangingAPI{MockAPI: tests.NewMockAPI(), driver: d}
+			h, err := newHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error building host: %v", err)
+			}
+			if err := api.Save(h); err != nil {
+				t.Fatalf("Error saving host: %v", err)
+			}
+			d.CurrentState = state.Running
+
+			c := defaultMachineConfig
+			c.KeepFailed = test.keepFailed
+			if removed := RemoveFailedHost(api, c, test.created); removed != test.removed {
+				t.Errorf("Expected removed %t, got %t", test.removed, removed)
+			}
+			if d.Removed() != test.removed {
+				t.Errorf("Expected the VM to be removed: %t, got %t", test.removed, d.Removed())
+			}
+			if s, _ := d.GetState(); test.removed && s != state.Stopped {
+				t.Errorf("Expected the removed VM to be stopped, got %s", s)
+			}
+			if exists, _ := api.Exists(config.GetMachineName()); exists == test.removed {
+				t.Errorf("Expected the machine to be kept in the store: %t, got %t", !test.removed, exists)
+			}
+		})
+	}
+}
+
+func TestGetVMHostIP(t *testing.T) {
+	var cases = []struct {
+		driver    string
+		expected  string
+		shouldErr bool
+	}{
+		{driver: "kvm", expected: "192.168.42.1"},
+		{driver: "kvm2", expected: "192.168.39.1"},
+		{driver: "hyperkit", expected: "192.168.64.1"},
+		{driver: "none", shouldErr: true},
+	}
+	for _, test := range cases {
+		ip, err := GetVMHostIP(&host.Host{Name: "minikube", DriverName: test.driver})
+		if err != nil && !test.shouldErr {
+			t.Errorf("Unexpected error for driver %s: %v", test.driver, err)
+		}
+		if err == nil && test.shouldErr {
+			t.Errorf("Expected an error for driver %s", test.driver)
+		}
+		if err == nil && ip.String() != test.expected {
+			t.Errorf("Expected the host IP %s for driver %s, got %s", test.expected, test.driver, ip)
+		}
+	}
 }
 
 func TestStopHostError(t *testing.T) {
 	api := tests.NewMockAPI()
-	if err := StopHost(api); err == nil {
+	if _, err := StopHost(context.Background(), api, RetryPolicy{}, StopOptions{}); err == nil {
 		t.Fatal("An error should be thrown when stopping non-existing machine.")
 	}
 }
@@ -293,7 +695,7 @@ func TestStopHostError(t *testing.T) {
 func TestStopHost(t *testing.T) {
 	api := tests.NewMockAPI()
 	h, _ := createHost(api, defaultMachineConfig)
-	if err := StopHost(api); err != nil {
+	if _, err := StopHost(context.Background(), api, RetryPolicy{}, StopOptions{}); err != nil {
 		t.Fatal("An error should be thrown when stopping non-existing machine.")
 	}
 	if s, _ := h.Driver.GetState(); s != state.Stopped {
@@ -305,7 +707,7 @@ func TestDeleteHost(t *testing.T) {
 	api := tests.NewMockAPI()
 	createHost(api, defaultMachineConfig)
 
-	if err := DeleteHost(api); err != nil {
+	if err := DeleteHost(context.Background(), api); err != nil {
 		t.Fatalf("Unexpected error deleting host: %s", err)
 	}
 }
@@ -318,7 +720,7 @@ func TestDeleteHostErrorDeletingVM(t *testing.T) {
 
 	h.Driver = d
 
-	if err := DeleteHost(api); err == nil {
+	if err := DeleteHost(context.Background(), api); err == nil {
 		t.Fatal("Expected error deleting host.")
 	}
 }
@@ -328,7 +730,7 @@ func TestDeleteHostErrorDeletingFiles(t *testing.T) {
 	api.RemoveError = true
 	createHost(api, defaultMachineConfig)
 
-	if err := DeleteHost(api); err == nil {
+	if err := DeleteHost(context.Background(), api); err == nil {
 		t.Fatal("Expected error deleting host.")
 	}
 }
@@ -342,7 +744,7 @@ func TestDeleteHostMultipleErrors(t *testing.T) {
 
 	h.Driver = d
 
-	err := DeleteHost(api)
+	err := DeleteHost(context.Background(), api)
 
 	if err == nil {
 		t.Fatal("Expected error deleting host, didn't get one.")
@@ -369,12 +771,12 @@ func TestGetHostStatus(t *testing.T) {
 		}
 	}
 
-	checkState(state.None.String())
+	checkState(constants.MachineDoesNotExist)
 
 	createHost(api, defaultMachineConfig)
 	checkState(state.Running.String())
 
-	StopHost(api)
+	StopHost(context.Background(), api, RetryPolicy{}, StopOptions{})
 	checkState(state.Stopped.String())
 }
 
@@ -436,7 +838,7 @@ func TestSetupCerts(t *testing.T) {
 	tempDir := tests.MakeTempDir()
 	defer os.RemoveAll(tempDir)
 
-	if err := SetupCerts(d, constants.APIServerName); err != nil {
+	if err := SetupCerts(d, KubernetesConfig{APIServerName: constants.APIServerName}); err != nil {
 		t.Fatalf("Error starting cluster: %s", err)
 	}
 
@@ -520,7 +922,8 @@ func TestHostGetLogs(t *testing.T) {
 	}
 
 	d := &tests.MockDriver{
-		Port: port,
+		Port:         port,
+		CurrentState: state.Running,
 		BaseDriver: drivers.BaseDriver{
 			IPAddress:  "127.0.0.1",
 			SSHKeyPath: "",
@@ -530,29 +933,30 @@ func TestHostGetLogs(t *testing.T) {
 
 	tests := []struct {
 		description string
-		follow      bool
+		opts        LogsOptions
+		expected    []string
 	}{
 		{
 			description: "logs",
-			follow:      false,
+			opts:        LogsOptions{Length: 20},
+			expected:    []string{"sudo journalctl --no-pager -u localkube -n 20", "sudo journalctl --no-pager -u docker -n 20", "sudo dmesg | tail -n 20"},
 		},
 		{
 			description: "logs -f",
-			follow:      true,
+			opts:        LogsOptions{Follow: true, Length: 20},
+			expected:    []string{"sudo journalctl --no-pager -u localkube -n 20 -f", "sudo journalctl --no-pager -k -n 20 -f"},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
-			cmd, err := GetLogsCommand(test.follow)
-			if err != nil {
-				t.Errorf("Error getting the logs command: %s", err)
-			}
-			if _, err = GetHostLogs(api, test.follow); err != nil {
+			if err := WriteLogs(api, ioutil.Discard, test.opts); err != nil {
 				t.Errorf("Error getting host logs: %s", err)
 			}
-			if _, ok := s.Commands[cmd]; !ok {
-				t.Errorf("Expected command to run but did not: %s", cmd)
+			for _, cmd := range test.expected {
+				if _, ok := s.Commands[cmd]; !ok {
+					t.Errorf("Expected command to run but did not: %s", cmd)
+				}
 			}
 		})
 	}
@@ -633,6 +1037,9 @@ func TestUpdateDefault(t *testing.T) {
 
 var testLocalkubeBin = "hello"
 
+// testLocalkubeChecksum is the SHA256 checksum of testLocalkubeBin.
+const testLocalkubeChecksum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
 type K8sVersionHandlerCorrect struct{}
 
 func (h *K8sVersionHandlerCorrect) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -658,6 +1065,9 @@ func TestUpdateKubernetesVersion(t *testing.T) {
 	}
 	handler := &K8sVersionHandlerCorrect{}
 	server := httptest.NewServer(handler)
+	s.SetCommandToOutput(map[string]string{
+		sha256sumCommand("/usr/local/bin/localkube"): testLocalkubeChecksum + "  /usr/local/bin/localkube\n",
+	})
 
 	kubernetesConfig := KubernetesConfig{
 		KubernetesVersion: server.URL,
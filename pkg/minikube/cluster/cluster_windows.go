@@ -42,7 +42,8 @@ func createHypervHost(config MachineConfig) drivers.Driver {
 	return d
 }
 
-func detectVBoxManageCmd() string {
+// DetectVBoxManageCmd returns the path of VBoxManage, or its name if it isn't found.
+func DetectVBoxManageCmd() string {
 	cmd := "VBoxManage"
 	if p := os.Getenv("VBOX_INSTALL_PATH"); p != "" {
 		if path, err := exec.LookPath(filepath.Join(p, cmd)); err == nil {
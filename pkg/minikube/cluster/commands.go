@@ -21,10 +21,14 @@ import (
 	gflag "flag"
 	"fmt"
 	"net"
+	"os"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/util"
 )
 
 // Kill any running instances.
@@ -103,7 +107,11 @@ func GenLocalkubeStartCmd(kubernetesConfig KubernetesConfig) (string, error) {
 	}
 
 	if kubernetesConfig.ContainerRuntime != "" {
-		flagVals = append(flagVals, "--container-runtime="+kubernetesConfig.ContainerRuntime)
+		rt, err := cruntime.Lookup(kubernetesConfig.ContainerRuntime)
+		if err != nil {
+			return "", err
+		}
+		flagVals = append(flagVals, rt.KubeletFlags()...)
 	}
 
 	if kubernetesConfig.NetworkPlugin != "" {
@@ -122,11 +130,41 @@ func GenLocalkubeStartCmd(kubernetesConfig KubernetesConfig) (string, error) {
 		flagVals = append(flagVals, "--dns-domain="+kubernetesConfig.DNSDomain)
 	}
 
+	if cidr := kubernetesConfig.serviceCIDR(); cidr != util.DefaultServiceCIDR {
+		dnsIP, err := kubernetesConfig.dnsIP()
+		if err != nil {
+			return "", err
+		}
+		flagVals = append(flagVals, "--service-cluster-ip-range="+cidr, "--dns-ip="+dnsIP.String())
+	}
+
+	if cidr := kubernetesConfig.podCIDR(); cidr != util.DefaultPodCIDR {
+		flagVals = append(flagVals, "--pod-network-cidr="+cidr)
+	}
+
+	if kubernetesConfig.AuditPolicy != "" {
+		flagVals = append(flagVals, "--audit-policy-file="+constants.RemoteAuditPolicyPath, "--audit-log-path="+constants.RemoteAuditLogPath)
+	}
+
 	if kubernetesConfig.NodeIP != "127.0.0.1" {
 		flagVals = append(flagVals, "--node-ip="+kubernetesConfig.NodeIP)
 	}
 
-	for _, e := range kubernetesConfig.ExtraOptions {
+	if f := pauseImageFlag(kubernetesConfig); f != "" {
+		flagVals = append(flagVals, f)
+	}
+
+	extraOptions := kubernetesConfig.ExtraOptions
+	if kubernetesConfig.StorageProvisionerAddon {
+		// The claims without a class only get the default one of the addon with the admission plugin.
+		flagVals = append(flagVals, "--storage-provisioner=false")
+		extraOptions = withAdmissionPlugin(extraOptions, "DefaultStorageClass")
+	}
+	if kubernetesConfig.MetricsAddon {
+		extraOptions = withSummaryAPI(extraOptions)
+	}
+
+	for _, e := range extraOptions {
 		flagVals = append(flagVals, fmt.Sprintf("--extra-config=%s", e.String()))
 	}
 	flags := strings.Join(flagVals, " ")
@@ -145,56 +183,95 @@ func GenLocalkubeStartCmd(kubernetesConfig KubernetesConfig) (string, error) {
 	return buf.String(), nil
 }
 
-const logsTemplate = "sudo journalctl {{.Flags}} -u localkube"
+// admissionControlKey is the field of the apiserver's options listing its admission plugins,
+// which localkube enables DefaultStorageClass in unless extra config sets it.
+const admissionControlKey = "GenericServerRunOptions.AdmissionControl"
 
-func GetLogsCommand(follow bool) (string, error) {
-	t, err := template.New("logsTemplate").Parse(logsTemplate)
-	if err != nil {
-		return "", err
-	}
-	var flags []string
-	if follow {
-		flags = append(flags, "-f")
+// withAdmissionPlugin returns the options with plugin added to the admission plugins of the
+// apiserver, when they set them without it.
+func withAdmissionPlugin(opts util.ExtraOptionSlice, plugin string) util.ExtraOptionSlice {
+	result := append(util.ExtraOptionSlice{}, opts...)
+	for i, e := range result {
+		if e.Component != "apiserver" || e.Key != admissionControlKey {
+			continue
+		}
+		plugins := strings.Split(e.Value, ",")
+		found := false
+		for _, p := range plugins {
+			found = found || p == plugin
+		}
+		if !found {
+			result[i].Value = strings.Join(append(plugins, plugin), ",")
+		}
 	}
+	return result
+}
 
-	buf := bytes.Buffer{}
-	data := struct {
-		RemoteLocalkubeErrPath string
-		RemoteLocalkubeOutPath string
-		Flags                  string
-	}{
-		RemoteLocalkubeErrPath: constants.RemoteLocalKubeErrPath,
-		RemoteLocalkubeOutPath: constants.RemoteLocalKubeOutPath,
-		Flags: strings.Join(flags, " "),
-	}
-	if err := t.Execute(&buf, data); err != nil {
-		return "", err
+// withSummaryAPI returns the options with the kubelet serving its summary API on the read-only
+// port the metrics addon collects from, when they turn it off. The kubelet serves it by default.
+func withSummaryAPI(opts util.ExtraOptionSlice) util.ExtraOptionSlice {
+	result := append(util.ExtraOptionSlice{}, opts...)
+	for i, e := range result {
+		if e.Component != "kubelet" {
+			continue
+		}
+		switch e.Key {
+		case "EnableServer":
+			if on, err := strconv.ParseBool(e.Value); err != nil || !on {
+				result[i].Value = "true"
+			}
+		case "ReadOnlyPort":
+			result[i].Value = strconv.Itoa(constants.KubeletReadOnlyPort)
+		}
 	}
-	return buf.String(), nil
+	return result
 }
 
-var localkubeStatusCommand = `sudo systemctl is-active localkube 2>&1 1>/dev/null && echo "Running" || echo "Stopped"`
+var localkubeStatusCommand = `if ! sudo systemctl is-active localkube >/dev/null 2>&1; then echo "Stopped"; ` +
+	`elif grep -q '^State:.*stopped' /proc/$(systemctl show -p MainPID localkube | cut -d= -f2)/status; then echo "Paused"; ` +
+	`else echo "Running"; fi`
+
+// Pausing stops localkube's process before the cluster's containers, so that the kubelet
+// doesn't react to them being paused, and unpausing continues it first.
+var (
+	pauseLocalkubeCommand    = "sudo systemctl kill --signal=SIGSTOP localkube"
+	unpauseLocalkubeCommand  = "sudo systemctl kill --signal=SIGCONT localkube"
+	pauseContainersCommand   = `ids=$(docker ps -q --filter name=k8s_ --filter status=running); [ -z "$ids" ] || docker pause $ids`
+	unpauseContainersCommand = `ids=$(docker ps -q --filter name=k8s_ --filter status=paused); [ -z "$ids" ] || docker unpause $ids`
+	apiserverHealthCommand   = "curl -fs http://localhost:8080/healthz"
+)
 
 func GetMountCleanupCommand(path string) string {
-	return fmt.Sprintf("sudo umount %s;", path)
+	return fmt.Sprintf("sudo umount %s;", util.ShellQuote(path))
 }
 
 var mountTemplate = `
 sudo mkdir -p {{.Path}} || true;
-sudo mount -t 9p -o trans=tcp -o port={{.Port}} -o uid=1001 -o gid=1001 {{.IP}} {{.Path}};
-sudo chmod 775 {{.Path}};`
+sudo mount -t 9p -o trans=tcp -o port={{.Port}} -o dfltuid={{.UID}} -o dfltgid={{.GID}} -o msize={{.Msize}} {{.IP}} {{.Path}};
+{{if not .DirMode}}sudo chmod 775 {{.Path}};{{end}}`
 
-func GetMountCommand(ip net.IP, path string, port string) (string, error) {
+// GetMountCommand returns the command mounting the 9p server at ip:port into path with the
+// owner and msize of config. With a DirMode, the server sets the permissions of path itself.
+// path is quoted, as the mount string of a host folder with spaces in it names one more often.
+func GetMountCommand(ip net.IP, path string, port string, config MountConfig) (string, error) {
 	t := template.Must(template.New("mountCommand").Parse(mountTemplate))
 	buf := bytes.Buffer{}
 	data := struct {
-		IP   string
-		Path string
-		Port string
+		IP      string
+		Path    string
+		Port    string
+		UID     int
+		GID     int
+		Msize   int
+		DirMode os.FileMode
 	}{
-		IP:   ip.String(),
-		Path: path,
-		Port: port,
+		IP:      ip.String(),
+		Path:    util.ShellQuote(path),
+		Port:    port,
+		UID:     config.UID,
+		GID:     config.GID,
+		Msize:   config.Msize,
+		DirMode: config.DirMode,
 	}
 	if err := t.Execute(&buf, data); err != nil {
 		return "", err
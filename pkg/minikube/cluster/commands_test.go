@@ -63,6 +63,263 @@ func TestGetStartCommandExtraOptions(t *testing.T) {
 	}
 }
 
+func TestGetStartCommandFeatureGates(t *testing.T) {
+	var cases = []struct {
+		description  string
+		extraOptions util.ExtraOptionSlice
+	}{
+		{
+			description: "feature gates only",
+		},
+		{
+			description: "extra config sets the kubelet's feature gates",
+			extraOptions: util.ExtraOptionSlice{
+				util.ExtraOption{Component: "kubelet", Key: "FeatureGates", Value: "AllAlpha=false"},
+			},
+		},
+		{
+			description: "extra config sets the feature gates of the control plane",
+			extraOptions: util.ExtraOptionSlice{
+				util.ExtraOption{Component: "apiserver", Key: "FeatureGates", Value: "AllAlpha=false"},
+				util.ExtraOption{Component: "controller-manager", Key: "FeatureGates", Value: "Accelerators=false"},
+				util.ExtraOption{Component: "scheduler", Key: "FeatureGates", Value: "AllAlpha=false"},
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			k := KubernetesConfig{
+				FeatureGates: "AllAlpha=true,Accelerators=true",
+				ExtraOptions: test.extraOptions,
+			}
+			cmd, err := GenLocalkubeStartCmd(k)
+			if err != nil {
+				t.Fatalf("Error generating start command: %s", err)
+			}
+			if n := strings.Count(cmd, "--feature-gates="); n != 1 {
+				t.Fatalf("Expected --feature-gates once, found it %d times: %s", n, cmd)
+			}
+			if !strings.Contains(cmd, "--feature-gates=AllAlpha=true,Accelerators=true") {
+				t.Fatalf("Expected the feature gates in the start command: %s", cmd)
+			}
+			for _, e := range test.extraOptions {
+				if n := strings.Count(cmd, "--extra-config="+e.String()); n != 1 {
+					t.Fatalf("Expected --extra-config=%s once, found it %d times: %s", e.String(), n, cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStartCommandClusterCIDRs(t *testing.T) {
+	cmd, err := GenLocalkubeStartCmd(KubernetesConfig{ServiceCIDR: "172.30.0.0/16", PodCIDR: "172.31.0.0/16"})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	for _, arg := range []string{"--service-cluster-ip-range=172.30.0.0/16", "--dns-ip=172.30.0.10", "--pod-network-cidr=172.31.0.0/16"} {
+		if !strings.Contains(cmd, arg) {
+			t.Errorf("Expected %s in the start command: %s", arg, cmd)
+		}
+	}
+
+	cmd, err = GenLocalkubeStartCmd(KubernetesConfig{})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	for _, arg := range []string{"--service-cluster-ip-range", "--dns-ip", "--pod-network-cidr"} {
+		if strings.Contains(cmd, arg) {
+			t.Errorf("Expected no %s with the default ranges: %s", arg, cmd)
+		}
+	}
+}
+
+func TestGetStartCommandAuditPolicy(t *testing.T) {
+	cmd, err := GenLocalkubeStartCmd(KubernetesConfig{AuditPolicy: "/home/user/policy.yaml"})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	for _, arg := range []string{"--audit-policy-file=/var/lib/localkube/audit/policy.yaml", "--audit-log-path=/var/lib/localkube/audit/audit.log"} {
+		if !strings.Contains(cmd, arg) {
+			t.Errorf("Expected %s in the start command: %s", arg, cmd)
+		}
+	}
+
+	cmd, err = GenLocalkubeStartCmd(KubernetesConfig{})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	if strings.Contains(cmd, "--audit-") {
+		t.Errorf("Expected no audit flags without a policy: %s", cmd)
+	}
+}
+
+func TestGetStartCommandContainerRuntime(t *testing.T) {
+	var cases = []struct {
+		description string
+		runtime     string
+		expected    string
+	}{
+		{
+			description: "default",
+			expected:    "",
+		},
+		{
+			description: "docker",
+			runtime:     "docker",
+			expected:    "--container-runtime=docker ",
+		},
+		{
+			description: "containerd",
+			runtime:     "containerd",
+			expected:    "--container-runtime=remote --container-runtime-endpoint=unix:///run/containerd/containerd.sock ",
+		},
+		{
+			description: "cri-o",
+			runtime:     "cri-o",
+			expected:    "--container-runtime=remote --container-runtime-endpoint=unix:///var/run/crio/crio.sock ",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			cmd, err := GenLocalkubeStartCmd(KubernetesConfig{ContainerRuntime: test.runtime, NodeIP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("Error generating start command: %s", err)
+			}
+			if test.expected == "" && strings.Contains(cmd, "--container-runtime") {
+				t.Errorf("Expected no runtime flags by default: %s", cmd)
+			}
+			if !strings.Contains(cmd, test.expected) {
+				t.Errorf("Expected %q in the start command: %s", test.expected, cmd)
+			}
+		})
+	}
+
+	if _, err := GenLocalkubeStartCmd(KubernetesConfig{ContainerRuntime: "lxc"}); err == nil {
+		t.Error("Expected an error generating the start command with an unknown runtime")
+	}
+}
+
+func TestGetStartCommandMergedExtraOptions(t *testing.T) {
+	stored := util.ExtraOptionSlice{{Component: "kubelet", Key: "MaxPods", Value: "5"}, {Component: "apiserver", Key: "Authorization.Mode", Value: "AlwaysAllow"}}
+	passed := util.ExtraOptionSlice{{Component: "apiserver", Key: "Authorization.Mode", Value: "RBAC"}}
+	startCommand, err := GetStartCommand(KubernetesConfig{ExtraOptions: stored.Merge(passed)})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	for _, arg := range []string{"--extra-config=kubelet.MaxPods=5", "--extra-config=apiserver.Authorization.Mode=RBAC"} {
+		if strings.Count(startCommand, arg) != 1 {
+			t.Errorf("Expected to find argument %s once. Got: %s", arg, startCommand)
+		}
+	}
+	if strings.Contains(startCommand, "AlwaysAllow") {
+		t.Errorf("Expected the stored value to be replaced. Got: %s", startCommand)
+	}
+}
+
+func TestGetStartCommandStorageProvisionerAddon(t *testing.T) {
+	var cases = []struct {
+		description  string
+		extraOptions util.ExtraOptionSlice
+		expected     []string
+	}{
+		{
+			description: "default admission plugins",
+			expected:    []string{"--storage-provisioner=false"},
+		},
+		{
+			description:  "admission plugins without DefaultStorageClass",
+			extraOptions: util.ExtraOptionSlice{{Component: "apiserver", Key: "GenericServerRunOptions.AdmissionControl", Value: "NamespaceLifecycle,ServiceAccount"}},
+			expected:     []string{"--storage-provisioner=false", "--extra-config=apiserver.GenericServerRunOptions.AdmissionControl=NamespaceLifecycle,ServiceAccount,DefaultStorageClass "},
+		},
+		{
+			description:  "admission plugins with DefaultStorageClass",
+			extraOptions: util.ExtraOptionSlice{{Component: "apiserver", Key: "GenericServerRunOptions.AdmissionControl", Value: "DefaultStorageClass,ServiceAccount"}},
+			expected:     []string{"--extra-config=apiserver.GenericServerRunOptions.AdmissionControl=DefaultStorageClass,ServiceAccount "},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			cmd, err := GenLocalkubeStartCmd(KubernetesConfig{StorageProvisionerAddon: true, ExtraOptions: test.extraOptions, NodeIP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("Error generating start command: %s", err)
+			}
+			for _, arg := range test.expected {
+				if !strings.Contains(cmd, arg) {
+					t.Errorf("Expected %q in the start command: %s", arg, cmd)
+				}
+			}
+			if n := strings.Count(cmd, "DefaultStorageClass"); len(test.extraOptions) > 0 && n != 1 {
+				t.Errorf("Expected DefaultStorageClass once, found it %d times: %s", n, cmd)
+			}
+		})
+	}
+
+	extraOptions := util.ExtraOptionSlice{{Component: "apiserver", Key: "GenericServerRunOptions.AdmissionControl", Value: "ServiceAccount"}}
+	cmd, err := GenLocalkubeStartCmd(KubernetesConfig{ExtraOptions: extraOptions, NodeIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	if strings.Contains(cmd, "--storage-provisioner") || strings.Contains(cmd, "DefaultStorageClass") {
+		t.Errorf("Expected localkube to provision the volumes, with the admission plugins as set, without the addon: %s", cmd)
+	}
+	if extraOptions[0].Value != "ServiceAccount" {
+		t.Errorf("Expected the options of the config left alone, got %v", extraOptions)
+	}
+}
+
+func TestGetStartCommandMetricsAddon(t *testing.T) {
+	var cases = []struct {
+		description  string
+		extraOptions util.ExtraOptionSlice
+		expected     []string
+	}{
+		{
+			description: "summary API served by default",
+		},
+		{
+			description:  "read-only port turned off",
+			extraOptions: util.ExtraOptionSlice{{Component: "kubelet", Key: "ReadOnlyPort", Value: "0"}, {Component: "kubelet", Key: "MaxPods", Value: "5"}},
+			expected:     []string{"--extra-config=kubelet.ReadOnlyPort=10255", "--extra-config=kubelet.MaxPods=5"},
+		},
+		{
+			description:  "server turned off",
+			extraOptions: util.ExtraOptionSlice{{Component: "kubelet", Key: "EnableServer", Value: "false"}},
+			expected:     []string{"--extra-config=kubelet.EnableServer=true"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			cmd, err := GenLocalkubeStartCmd(KubernetesConfig{MetricsAddon: true, ExtraOptions: test.extraOptions, NodeIP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("Error generating start command: %s", err)
+			}
+			if n := strings.Count(cmd, "--extra-config="); n != len(test.extraOptions) {
+				t.Errorf("Expected %d extra config flags, got %d: %s", len(test.extraOptions), n, cmd)
+			}
+			for _, arg := range test.expected {
+				if !strings.Contains(cmd, arg) {
+					t.Errorf("Expected %q in the start command: %s", arg, cmd)
+				}
+			}
+		})
+	}
+
+	extraOptions := util.ExtraOptionSlice{{Component: "kubelet", Key: "ReadOnlyPort", Value: "0"}}
+	cmd, err := GenLocalkubeStartCmd(KubernetesConfig{ExtraOptions: extraOptions, NodeIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	if !strings.Contains(cmd, "--extra-config=kubelet.ReadOnlyPort=0") {
+		t.Errorf("Expected the read-only port left off without the addon: %s", cmd)
+	}
+	if extraOptions[0].Value != "0" {
+		t.Errorf("Expected the options of the config left alone, got %v", extraOptions)
+	}
+}
+
 func flagMapToSetFlags(flagMap map[string]string) {
 	for flag, val := range flagMap {
 		gflag.Set(flag, val)
@@ -0,0 +1,166 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+)
+
+// ConfigChange is a setting of an existing host which differs from the one start was asked for.
+type ConfigChange struct {
+	Setting   string
+	Existing  string
+	Requested string
+}
+
+// storedDriverConfig holds the fields of the stored driver configs the settings are compared with.
+type storedDriverConfig struct {
+	Memory         int
+	MemSize        int // The hyperv driver's memory
+	CPU            int
+	DiskSize       int
+	Boot2DockerURL string
+	ExtraDisks     int
+	ExtraDiskSize  int
+	MountHostPath  string
+	MountGuestPath string
+}
+
+// decodeDriverConfig decodes fields of the host's stored driver config into v.
+func decodeDriverConfig(h *host.Host, v interface{}) error {
+	data := h.RawDriver
+	if len(data) == 0 {
+		var err error
+		if data, err = json.Marshal(h.Driver); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// configChanges compares the config start was asked for with the stored driver config of the
+// existing host, which the VM was created with. Settings missing from the driver config are skipped,
+// and so are the ones left to their defaults, which don't ask for the host to change.
+func configChanges(h *host.Host, config MachineConfig) ([]ConfigChange, error) {
+	if h.DriverName == "none" {
+		return nil, nil
+	}
+	var c storedDriverConfig
+	if err := decodeDriverConfig(h, &c); err != nil {
+		return nil, err
+	}
+	if c.Memory == 0 {
+		c.Memory = c.MemSize
+	}
+
+	changes := []ConfigChange{}
+	requested := func(setting string) bool {
+		return config.RequestedSettings == nil || config.RequestedSettings[setting]
+	}
+	compare := func(setting string, existing, value int) {
+		if requested(setting) && existing != 0 && existing != value {
+			changes = append(changes, ConfigChange{Setting: setting, Existing: strconv.Itoa(existing), Requested: strconv.Itoa(value)})
+		}
+	}
+	compare("memory", c.Memory, config.Memory)
+	compare("cpus", c.CPU, config.CPUs)
+	compare("disk-size", c.DiskSize, config.DiskSize)
+	if requested("iso-url") && config.Downloader != nil && c.Boot2DockerURL != "" {
+		if iso := config.Downloader.GetISOFileURI(config.MinikubeISO); iso != c.Boot2DockerURL {
+			changes = append(changes, ConfigChange{Setting: "iso-url", Existing: c.Boot2DockerURL, Requested: iso})
+		}
+	}
+	// Extra disks and the shared folder are only compared when asked for, as they are usually left out.
+	if config.ExtraDisks > 0 && (c.ExtraDisks != config.ExtraDisks || c.ExtraDiskSize != config.ExtraDiskSize) {
+		changes = append(changes, ConfigChange{
+			Setting:   "extra-disks",
+			Existing:  fmt.Sprintf("%d of %d MB", c.ExtraDisks, c.ExtraDiskSize),
+			Requested: fmt.Sprintf("%d of %d MB", config.ExtraDisks, config.ExtraDiskSize),
+		})
+	}
+	if f := config.SharedFolder; f.HostPath != "" && (c.MountHostPath != f.HostPath || c.MountGuestPath != f.GuestPath) {
+		existing := "none"
+		if c.MountHostPath != "" {
+			existing = c.MountHostPath + ":" + c.MountGuestPath
+		}
+		changes = append(changes, ConfigChange{Setting: "mount-string", Existing: existing, Requested: f.HostPath + ":" + f.GuestPath})
+	}
+	return changes, nil
+}
+
+// StoredHostConfig is the configuration an existing host was created with, which starting it keeps.
+type StoredHostConfig struct {
+	Name       string
+	DriverName string
+	Driver     map[string]interface{}
+	// Changes are the settings start was asked for which differ from the stored ones.
+	Changes []ConfigChange
+}
+
+// LoadStoredHostConfig returns the stored configuration of the named host compared with config,
+// or nil if the host doesn't exist.
+func LoadStoredHostConfig(api libmachine.API, name string, config MachineConfig) (*StoredHostConfig, error) {
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error checking if host %s exists", name)
+	}
+	if !exists {
+		return nil, nil
+	}
+	h, err := api.Load(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading host %s", name)
+	}
+	stored := &StoredHostConfig{Name: h.Name, DriverName: h.DriverName}
+	if err := decodeDriverConfig(h, &stored.Driver); err != nil {
+		return nil, errors.Wrapf(err, "Error decoding the driver config of host %s", name)
+	}
+	if stored.Changes, err = configChanges(h, config); err != nil {
+		return nil, errors.Wrapf(err, "Error comparing the config of host %s", name)
+	}
+	return stored, nil
+}
+
+// printConfigChanges warns that the changed settings are ignored, and how to apply them.
+func printConfigChanges(w io.Writer, h *host.Host, changes []ConfigChange) {
+	fmt.Fprintf(w, "WARNING: The existing VM %s was created with different settings, which are ignored:\n", h.Name)
+	resizable := []string{}
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s: %s (requested %s)\n", c.Setting, c.Existing, c.Requested)
+		if h.DriverName != "virtualbox" && h.DriverName != "kvm2" {
+			continue
+		}
+		switch c.Setting {
+		case "memory":
+			resizable = append(resizable, "--memory "+c.Requested)
+		case "cpus":
+			resizable = append(resizable, "--cpus "+c.Requested)
+		}
+	}
+	fmt.Fprintln(w, "Run \"minikube delete\" and start again, or pass --recreate-on-config-change, to recreate the VM with them.")
+	if len(resizable) > 0 {
+		fmt.Fprintf(w, "The memory and cpus can instead be changed without recreating the VM by running \"minikube resize %s\".\n", strings.Join(resizable, " "))
+	}
+}
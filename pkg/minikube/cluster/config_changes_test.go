@@ -0,0 +1,205 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+const vboxConfigISO = "file:///home/sundarp/.minikube/cache/iso/minikube-v1.0.6.iso"
+
+// isoDownloader caches every ISO as the one in the vboxConfig fixture.
+type isoDownloader struct {
+	MockDownloader
+	uri string
+}
+
+func (d isoDownloader) GetISOFileURI(isoURL string) string { return d.uri }
+
+func vboxHost() *host.Host {
+	return &host.Host{Name: "minikube", DriverName: "virtualbox", RawDriver: []byte(tests.VBoxConfig)}
+}
+
+func TestConfigChanges(t *testing.T) {
+	// The settings the vboxConfig fixture was created with.
+	unchanged := MachineConfig{
+		Memory:     16384,
+		CPUs:       4,
+		DiskSize:   20000,
+		Downloader: isoDownloader{uri: vboxConfigISO},
+	}
+	var cases = []struct {
+		description string
+		change      func(*MachineConfig)
+		expected    []ConfigChange
+	}{
+		{
+			description: "unchanged",
+			change:      func(*MachineConfig) {},
+			expected:    []ConfigChange{},
+		},
+		{
+			description: "memory",
+			change:      func(c *MachineConfig) { c.Memory = 8192 },
+			expected:    []ConfigChange{{Setting: "memory", Existing: "16384", Requested: "8192"}},
+		},
+		{
+			description: "cpus",
+			change:      func(c *MachineConfig) { c.CPUs = 2 },
+			expected:    []ConfigChange{{Setting: "cpus", Existing: "4", Requested: "2"}},
+		},
+		{
+			description: "disk size",
+			change:      func(c *MachineConfig) { c.DiskSize = 40000 },
+			expected:    []ConfigChange{{Setting: "disk-size", Existing: "20000", Requested: "40000"}},
+		},
+		{
+			description: "iso url",
+			change:      func(c *MachineConfig) { c.Downloader = isoDownloader{uri: "file:///home/sundarp/.minikube/cache/iso/minikube-v0.20.0.iso"} },
+			expected: []ConfigChange{
+				{Setting: "iso-url", Existing: vboxConfigISO, Requested: "file:///home/sundarp/.minikube/cache/iso/minikube-v0.20.0.iso"},
+			},
+		},
+		{
+			description: "extra disks",
+			change: func(c *MachineConfig) {
+				c.ExtraDisks = 1
+				c.ExtraDiskSize = 10000
+			},
+			expected: []ConfigChange{{Setting: "extra-disks", Existing: "0 of 0 MB", Requested: "1 of 10000 MB"}},
+		},
+		{
+			description: "memory and cpus",
+			change: func(c *MachineConfig) {
+				c.Memory = 8192
+				c.CPUs = 2
+			},
+			expected: []ConfigChange{
+				{Setting: "memory", Existing: "16384", Requested: "8192"},
+				{Setting: "cpus", Existing: "4", Requested: "2"},
+			},
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			config := unchanged
+			test.change(&config)
+			changes, err := configChanges(vboxHost(), config)
+			if err != nil {
+				t.Fatalf("Error comparing config: %s", err)
+			}
+			if !reflect.DeepEqual(changes, test.expected) {
+				t.Errorf("Expected changes %+v, got %+v", test.expected, changes)
+			}
+		})
+	}
+}
+
+func TestConfigChangesDefaults(t *testing.T) {
+	// The defaults of start, which the customized vboxConfig fixture wasn't created with.
+	defaults := MachineConfig{
+		Memory:     2048,
+		CPUs:       2,
+		DiskSize:   20000,
+		Downloader: isoDownloader{uri: "file:///home/sundarp/.minikube/cache/iso/minikube-v0.20.0.iso"},
+	}
+	var cases = []struct {
+		description string
+		requested   map[string]bool
+		expected    []ConfigChange
+	}{
+		{
+			description: "nothing given",
+			requested:   map[string]bool{},
+			expected:    []ConfigChange{},
+		},
+		{
+			description: "memory given",
+			requested:   map[string]bool{"memory": true},
+			expected:    []ConfigChange{{Setting: "memory", Existing: "16384", Requested: "2048"}},
+		},
+		{
+			description: "cpus and iso url given",
+			requested:   map[string]bool{"cpus": true, "iso-url": true},
+			expected: []ConfigChange{
+				{Setting: "cpus", Existing: "4", Requested: "2"},
+				{Setting: "iso-url", Existing: vboxConfigISO, Requested: "file:///home/sundarp/.minikube/cache/iso/minikube-v0.20.0.iso"},
+			},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			config := defaults
+			config.RequestedSettings = test.requested
+			changes, err := configChanges(vboxHost(), config)
+			if err != nil {
+				t.Fatalf("Error comparing config: %s", err)
+			}
+			if !reflect.DeepEqual(changes, test.expected) {
+				t.Errorf("Expected changes %+v, got %+v", test.expected, changes)
+			}
+		})
+	}
+}
+
+func TestPrintConfigChanges(t *testing.T) {
+	var b bytes.Buffer
+	printConfigChanges(&b, vboxHost(), []ConfigChange{
+		{Setting: "memory", Existing: "16384", Requested: "8192"},
+		{Setting: "disk-size", Existing: "20000", Requested: "40000"},
+	})
+	out := b.String()
+	for _, expected := range []string{"memory: 16384 (requested 8192)", "disk-size: 20000 (requested 40000)", "minikube delete", "minikube resize --memory 8192\""} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected the warning to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestLoadStoredHostConfig(t *testing.T) {
+	api := tests.NewMockAPI()
+	stored, err := LoadStoredHostConfig(api, "minikube", MachineConfig{})
+	if err != nil || stored != nil {
+		t.Fatalf("Expected no stored config of a missing host, got %v, %v", stored, err)
+	}
+
+	api.Hosts["minikube"] = vboxHost()
+	config := MachineConfig{
+		Memory:            8192,
+		CPUs:              2,
+		RequestedSettings: map[string]bool{"memory": true},
+	}
+	stored, err = LoadStoredHostConfig(api, "minikube", config)
+	if err != nil {
+		t.Fatalf("Error loading the stored config: %s", err)
+	}
+	if stored.DriverName != "virtualbox" || stored.Driver["Memory"] != float64(16384) {
+		t.Errorf("Expected the stored virtualbox config of 16384 MB, got %s with %v", stored.DriverName, stored.Driver["Memory"])
+	}
+	expected := []ConfigChange{{Setting: "memory", Existing: "16384", Requested: "8192"}}
+	if !reflect.DeepEqual(stored.Changes, expected) {
+		t.Errorf("Expected the changes %v, got %v", expected, stored.Changes)
+	}
+}
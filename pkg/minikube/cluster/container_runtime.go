@@ -0,0 +1,85 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/images"
+)
+
+// ConfigureContainerRuntime writes the config of the cluster's container runtime in the VM
+// and starts it, so that the kubelet can reach it. The VM's /etc is not kept when it
+// restarts, so it is done on every start. Docker and rkt are already set up by the ISO.
+func ConfigureContainerRuntime(h *host.Host, k KubernetesConfig) error {
+	rt, err := cruntime.Lookup(k.ContainerRuntime)
+	if err != nil {
+		return err
+	}
+	if rt.ConfigPath == "" {
+		return nil
+	}
+	if h.DriverName == "none" {
+		glog.Infof("Not configuring %s, which is set up on the host with the none driver", rt.Name)
+		return nil
+	}
+	if _, err := RunCommand(h, "command -v "+rt.Binary, false); err != nil {
+		return fmt.Errorf("The VM's ISO doesn't have %s, which --container-runtime=%s needs. Pass the --iso-url of an ISO which has it", rt.Binary, rt.Name)
+	}
+	glog.Infof("Configuring container runtime %s", rt.Name)
+	out, err := RunCommand(h, containerRuntimeConfigCommand(rt, images.WithRepository(constants.PauseImage, k.ImageRepository)), false)
+	if err != nil {
+		return errors.Wrapf(err, "Error configuring container runtime %s: %s", rt.Name, out)
+	}
+	return nil
+}
+
+// containerRuntimeConfigCommand writes the runtime's config, only restarting the runtime if it changed, and starts it.
+func containerRuntimeConfigCommand(rt cruntime.Runtime, sandboxImage string) string {
+	tmp := rt.ConfigPath + ".new"
+	return fmt.Sprintf("sudo mkdir -p %s && printf %%s '%s' | sudo tee %s >/dev/null && "+
+		"if sudo cmp -s %s %s; then sudo rm -f %s; else sudo mv %s %s && sudo systemctl restart %s; fi && sudo systemctl start %s",
+		path.Dir(rt.ConfigPath), rt.Config(sandboxImage), tmp,
+		tmp, rt.ConfigPath, tmp, tmp, rt.ConfigPath, rt.Service, rt.Service)
+}
+
+// ContainerRuntimeChanged returns the change of the named machine's container runtime start was asked
+// for, if any. The containers and images of a runtime are not moved to another, so it can't be changed.
+func ContainerRuntimeChanged(name string, k KubernetesConfig) *ConfigChange {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not checking the container runtime of %s: %s", name, err)
+	}
+	if s.ContainerRuntime == "" {
+		// The cluster was never started, or before the runtime was recorded.
+		return nil
+	}
+	requested := k.ContainerRuntime
+	if requested == "" {
+		requested = cruntime.Docker
+	}
+	if s.ContainerRuntime == requested {
+		return nil
+	}
+	return &ConfigChange{Setting: "container-runtime", Existing: s.ContainerRuntime, Requested: requested}
+}
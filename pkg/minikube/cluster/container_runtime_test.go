@@ -0,0 +1,71 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+func TestContainerRuntimeConfigCommand(t *testing.T) {
+	rt, err := cruntime.Lookup(cruntime.CRIO)
+	if err != nil {
+		t.Fatalf("Unexpected error looking up runtime: %s", err)
+	}
+	cmd := containerRuntimeConfigCommand(rt, "registry.example.com/pause-amd64:3.0")
+	for _, part := range []string{
+		"sudo mkdir -p /etc/crio && ",
+		`pause_image = "registry.example.com/pause-amd64:3.0"`,
+		"| sudo tee /etc/crio/crio.conf.new >/dev/null",
+		"if sudo cmp -s /etc/crio/crio.conf.new /etc/crio/crio.conf; then sudo rm -f /etc/crio/crio.conf.new; ",
+		"else sudo mv /etc/crio/crio.conf.new /etc/crio/crio.conf && sudo systemctl restart crio; fi",
+		"&& sudo systemctl start crio",
+	} {
+		if !strings.Contains(cmd, part) {
+			t.Errorf("Expected %q in the command: %s", part, cmd)
+		}
+	}
+}
+
+func TestContainerRuntimeChanged(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+	containerd := KubernetesConfig{ContainerRuntime: cruntime.Containerd}
+
+	if change := ContainerRuntimeChanged(name, containerd); change != nil {
+		t.Errorf("Expected no change before the first start, got %+v", change)
+	}
+	recordKubernetesConfig(name, KubernetesConfig{})
+	if change := ContainerRuntimeChanged(name, KubernetesConfig{ContainerRuntime: cruntime.Docker}); change != nil {
+		t.Errorf("Expected no change for docker, the default runtime, got %+v", change)
+	}
+	expected := &ConfigChange{Setting: "container-runtime", Existing: "docker", Requested: "containerd"}
+	if change := ContainerRuntimeChanged(name, containerd); !reflect.DeepEqual(change, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, change)
+	}
+
+	recordKubernetesConfig(name, containerd)
+	if change := ContainerRuntimeChanged(name, containerd); change != nil {
+		t.Errorf("Expected no change once started with the runtime, got %+v", change)
+	}
+}
@@ -28,16 +28,40 @@ var (
 	internalIP = net.ParseIP(util.DefaultServiceClusterIP)
 )
 
-func GenerateCerts(caCert, caKey, pub, priv string, ip net.IP, name string) error {
-	if !(util.CanReadFile(caCert) && util.CanReadFile(caKey)) {
-		if err := util.GenerateCACert(caCert, caKey, name); err != nil {
-			return errors.Wrap(err, "Error generating certificate")
+// GenerateCerts generates the CA, if there is none, and the apiserver certificate
+// signed by it for ip, with the extra names and IPs of sans. An existing CA is
+// kept, whether minikube generated it or it was put there to be used instead.
+func GenerateCerts(caCert, caKey, pub, priv string, ip net.IP, name string, sans APIServerSANs) error {
+	if util.CanReadFile(caCert) && util.CanReadFile(caKey) {
+		if err := ValidateCA(caCert, caKey); err != nil {
+			return err
 		}
+	} else if err := util.GenerateCACert(caCert, caKey, name); err != nil {
+		return errors.Wrap(err, "Error generating certificate")
 	}
 
-	ips := []net.IP{ip, internalIP}
-	if err := util.GenerateSignedCert(pub, priv, ips, util.GetAlternateDNS(util.DefaultDNSDomain), caCert, caKey); err != nil {
+	// The loopback address lets the apiserver be reached through a NAT port forward.
+	ips := append([]net.IP{ip, internalIP, net.ParseIP("127.0.0.1")}, sans.IPs...)
+	names := append(util.GetAlternateDNS(util.DefaultDNSDomain), sans.Names...)
+	if err := util.GenerateSignedCert(pub, priv, ips, names, caCert, caKey); err != nil {
 		return errors.Wrap(err, "Error generating signed cert")
 	}
 	return nil
 }
+
+// certSANs returns the extra names and IPs of the apiserver certificate. It is always valid for
+// localkube's default service IP and domain, and is also made valid for those of k if they differ.
+func (k KubernetesConfig) certSANs() (APIServerSANs, error) {
+	sans := k.APIServerSANs
+	serviceIP, err := k.serviceIP()
+	if err != nil {
+		return sans, err
+	}
+	if !serviceIP.Equal(internalIP) {
+		sans.IPs = append(append([]net.IP{}, sans.IPs...), serviceIP)
+	}
+	if domain := k.dnsDomain(); domain != util.DefaultDNSDomain {
+		sans.Names = append(append([]string{}, sans.Names...), "kubernetes.default.svc."+domain)
+	}
+	return sans, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"os"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/util"
+)
+
+// DeleteResult is the outcome of deleting one of the hosts.
+type DeleteResult struct {
+	Name string
+	// Err is why deleting the host failed, if it did.
+	Err error
+}
+
+// DeleteAllHosts deletes every host, of every profile, carrying on when deleting one of
+// them fails. Each host is given up on once ctx is done.
+func DeleteAllHosts(ctx context.Context, api libmachine.API) ([]DeleteResult, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing hosts")
+	}
+	var results []DeleteResult
+	for _, name := range names {
+		name := name
+		err := machine.RunWithContext(ctx, api, func() error {
+			return deleteHost(api, name)
+		})
+		results = append(results, DeleteResult{Name: name, Err: err})
+	}
+	return results, nil
+}
+
+// PurgeFiles removes the files minikube keeps outside of its machines: the cache,
+// the certs libmachine uses, and the certs of each profile's cluster. Of a cache dir
+// of its own, only what minikube caches is removed, as it may hold other files.
+func PurgeFiles() error {
+	m := util.MultiError{}
+	for _, dir := range []string{"cache", "certs", "profiles"} {
+		m.Collect(os.RemoveAll(constants.MakeMiniPath(dir)))
+	}
+	for _, dir := range constants.CacheSubdirs {
+		m.Collect(os.RemoveAll(constants.MakeCachePath(dir)))
+	}
+	for _, cert := range append(certs, suppliedCAMarker) {
+		if err := os.Remove(constants.MakeMiniPath(cert)); err != nil && !os.IsNotExist(err) {
+			m.Collect(err)
+		}
+	}
+	return m.ToError()
+}
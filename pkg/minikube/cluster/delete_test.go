@@ -0,0 +1,107 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestDeleteAllHosts(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	profiles := map[string]*tests.MockDriver{
+		"minikube": {},
+		"gone":     {RemoveErr: errors.New("machine does not exist")},
+		"broken":   {RemoveError: true},
+	}
+	for name, d := range profiles {
+		api.Hosts[name] = &host.Host{Name: name, DriverName: "virtualbox", Driver: d}
+	}
+
+	results, err := DeleteAllHosts(context.Background(), api)
+	if err != nil {
+		t.Fatalf("Error deleting hosts: %s", err)
+	}
+	if len(results) != len(profiles) {
+		t.Fatalf("Expected a result for each of the %d hosts, got %v", len(profiles), results)
+	}
+	for _, r := range results {
+		if failed := r.Err != nil; failed != (r.Name == "broken") {
+			t.Errorf("Unexpected result deleting %s: %v", r.Name, r.Err)
+		}
+	}
+	if len(api.Hosts) != 0 {
+		t.Errorf("Expected the files of every host to be removed, got %v", api.Hosts)
+	}
+}
+
+func TestPurgeFiles(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	purged := []string{
+		filepath.Join("cache", "iso", "minikube-v0.19.0.iso"),
+		filepath.Join("certs", "ca.pem"),
+		"ca.crt",
+		"apiserver.key",
+		filepath.Join("profiles", "project", "apiserver.key"),
+	}
+	kept := []string{
+		filepath.Join("config", "config.json"),
+		filepath.Join("config", "profiles", "project", "config.json"),
+		filepath.Join("machines", "minikube", "config.json"),
+	}
+	for _, f := range append(purged, kept...) {
+		path := constants.MakeMiniPath(f)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatalf("Error making dir: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Error writing %s: %s", f, err)
+		}
+	}
+
+	if err := PurgeFiles(); err != nil {
+		t.Fatalf("Error purging files: %s", err)
+	}
+	for _, f := range purged {
+		if _, err := os.Stat(constants.MakeMiniPath(f)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got: %v", f, err)
+		}
+	}
+	for _, dir := range []string{"cache", "certs"} {
+		if _, err := os.Stat(constants.MakeMiniPath(dir)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got: %v", dir, err)
+		}
+	}
+	for _, f := range kept {
+		if _, err := os.Stat(constants.MakeMiniPath(f)); err != nil {
+			t.Errorf("Expected %s to be kept, got: %s", f, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"regexp"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/util"
+)
+
+// defaultDNSDomainRef matches where the bundled kube-dns manifests name the default domain:
+// the domain kube-dns serves, and the names its health probes look up in it.
+var defaultDNSDomainRef = regexp.MustCompile(`(--domain=|\.svc\.)` + regexp.QuoteMeta(util.DefaultDNSDomain) + `\.`)
+
+func (k KubernetesConfig) dnsDomain() string {
+	if k.DNSDomain == "" {
+		return util.DefaultDNSDomain
+	}
+	return k.DNSDomain
+}
+
+// AddonWithDNSDomain returns the addon with the kube-dns of its manifests serving the DNS domain of k.
+func AddonWithDNSDomain(a *assets.Addon, k KubernetesConfig) *assets.Addon {
+	domain := k.dnsDomain()
+	if domain == util.DefaultDNSDomain {
+		return a
+	}
+	return a.WithContents(func(data []byte) []byte {
+		return defaultDNSDomainRef.ReplaceAll(data, []byte("${1}"+domain+"."))
+	})
+}
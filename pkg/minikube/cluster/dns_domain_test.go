@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+)
+
+func TestDNSDomainConsistency(t *testing.T) {
+	k := KubernetesConfig{DNSDomain: "dev.example.com"}
+
+	// Every bundled addon is rendered, as any of them naming the default domain would disagree with the cluster.
+	for name, addon := range assets.Addons {
+		for _, a := range AddonWithDNSDomain(addon, k).Assets {
+			if m := string(a.Bytes()); strings.Contains(m, "cluster.local") {
+				t.Errorf("Expected the %s addon's %s to only name the domain dev.example.com:\n%s", name, a.GetTargetName(), m)
+			}
+		}
+	}
+	controller := string(AddonWithDNSDomain(assets.Addons["kube-dns"], k).Assets[0].Bytes())
+	for _, arg := range []string{
+		"--domain=dev.example.com.",
+		"--probe=kubedns,127.0.0.1:10053,kubernetes.default.svc.dev.example.com.,5,A",
+		"--probe=dnsmasq,127.0.0.1:53,kubernetes.default.svc.dev.example.com.,5,A",
+	} {
+		if !strings.Contains(controller, arg) {
+			t.Errorf("Expected %s in the kube-dns controller:\n%s", arg, controller)
+		}
+	}
+
+	cmd, err := GenLocalkubeStartCmd(k)
+	if err != nil {
+		t.Fatalf("Error generating start command: %s", err)
+	}
+	if !strings.Contains(cmd, "--dns-domain=dev.example.com") {
+		t.Errorf("Expected the kubelet to be given the domain: %s", cmd)
+	}
+
+	sans, err := k.certSANs()
+	if err != nil {
+		t.Fatalf("Error getting the certificate SANs: %s", err)
+	}
+	if strings.Join(sans.Names, ",") != "kubernetes.default.svc.dev.example.com" {
+		t.Errorf("Expected the certificate to be valid for the kubernetes service in the domain, got %v", sans.Names)
+	}
+}
+
+func TestAddonWithDefaultDNSDomain(t *testing.T) {
+	dns := assets.Addons["kube-dns"]
+	if AddonWithDNSDomain(dns, KubernetesConfig{}) != dns || AddonWithDNSDomain(dns, KubernetesConfig{DNSDomain: "cluster.local"}) != dns {
+		t.Error("Expected the addon to be left alone with the default domain")
+	}
+	sans, err := KubernetesConfig{}.certSANs()
+	if err != nil || len(sans.Names) != 0 || len(sans.IPs) != 0 {
+		t.Errorf("Expected no extra SANs with the default domain and range, got %+v, %v", sans, err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// DockerEnvIDVar is the variable minikube docker-env sets to the machine the docker variables point
+// at: its name and the ID it was given when it was created, as in minikube/<ID>.
+const DockerEnvIDVar = "DOCKER_MINIKUBE_ID"
+
+// dockerDaemonPort is the port the docker daemon of the VM listens on, with TLS.
+const dockerDaemonPort = "2376"
+
+// dockerHost returns the DOCKER_HOST of the docker daemon of the VM at ip.
+func dockerHost(ip string) string {
+	return "tcp://" + net.JoinHostPort(ip, dockerDaemonPort)
+}
+
+// dockerEnvID returns the value of DockerEnvIDVar for the named machine of the ID, "" if it has none.
+func dockerEnvID(name, machineID string) string {
+	if machineID == "" {
+		return ""
+	}
+	return name + "/" + machineID
+}
+
+// CheckDockerEnv checks that the docker variables getenv has, if minikube docker-env set them for
+// the named machine, still point at it: that it wasn't deleted since, even if it was created again
+// with the same name, and that its IP didn't change. machineID is the ID of the machine, "" if it
+// doesn't exist, and dockerHost its DOCKER_HOST, "" if it isn't known, as while it is stopped.
+// Variables set for another machine are left alone.
+func CheckDockerEnv(getenv func(string) string, name, machineID, dockerHost string) error {
+	id := strings.SplitN(getenv(DockerEnvIDVar), "/", 2)
+	if len(id) != 2 || id[0] != name {
+		return nil
+	}
+	if id[1] != machineID {
+		return errors.Errorf("Your shell is configured for a deleted %s machine, re-run minikube docker-env to point docker at the current one", name)
+	}
+	if current := getenv("DOCKER_HOST"); dockerHost != "" && current != dockerHost {
+		return errors.Errorf("Your shell is configured for the docker daemon of %s at %s, but it is at %s now, re-run minikube docker-env", name, current, dockerHost)
+	}
+	return nil
+}
+
+// CheckShellDockerEnv checks the docker variables of minikube's environment against the machine
+// of the current profile, as CheckDockerEnv does.
+func CheckShellDockerEnv(api libmachine.API) error {
+	if os.Getenv(DockerEnvIDVar) == "" {
+		return nil
+	}
+	name := cfg.GetMachineName()
+	machineID, host, err := liveDockerEnv(api, name)
+	if err != nil {
+		glog.Warningf("Not checking the docker variables of the environment: %s", err)
+		return nil
+	}
+	return CheckDockerEnv(os.Getenv, name, machineID, host)
+}
+
+// liveDockerEnv returns the ID of the named machine, "" if it doesn't exist, and the DOCKER_HOST
+// of its docker daemon, "" unless it runs.
+func liveDockerEnv(api libmachine.API, name string) (machineID, host string, err error) {
+	exists, err := api.Exists(name)
+	if err != nil || !exists {
+		return "", "", err
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		return "", "", err
+	}
+	h, err := api.Load(name)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Error loading machine %s", name)
+	}
+	if st, err := h.Driver.GetState(); err != nil || st != state.Running {
+		return s.MachineID, "", nil
+	}
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return s.MachineID, "", nil
+	}
+	return s.MachineID, dockerHost(ip), nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestCheckDockerEnv(t *testing.T) {
+	var cases = []struct {
+		description string
+		env         map[string]string
+		machineID   string
+		dockerHost  string
+		expectedErr string
+	}{
+		{
+			description: "not set",
+			env:         map[string]string{"DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			machineID:   "1234",
+			dockerHost:  "tcp://192.168.99.101:2376",
+		},
+		{
+			description: "current",
+			env:         map[string]string{DockerEnvIDVar: "minikube/1234", "DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			machineID:   "1234",
+			dockerHost:  "tcp://192.168.99.100:2376",
+		},
+		{
+			description: "recreated",
+			env:         map[string]string{DockerEnvIDVar: "minikube/1234", "DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			machineID:   "5678",
+			dockerHost:  "tcp://192.168.99.100:2376",
+			expectedErr: "Your shell is configured for a deleted minikube machine",
+		},
+		{
+			description: "deleted",
+			env:         map[string]string{DockerEnvIDVar: "minikube/1234", "DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			expectedErr: "Your shell is configured for a deleted minikube machine",
+		},
+		{
+			description: "ip changed",
+			env:         map[string]string{DockerEnvIDVar: "minikube/1234", "DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			machineID:   "1234",
+			dockerHost:  "tcp://192.168.99.101:2376",
+			expectedErr: "but it is at tcp://192.168.99.101:2376 now",
+		},
+		{
+			description: "stopped",
+			env:         map[string]string{DockerEnvIDVar: "minikube/1234", "DOCKER_HOST": "tcp://192.168.99.100:2376"},
+			machineID:   "1234",
+		},
+		{
+			description: "other profile",
+			env:         map[string]string{DockerEnvIDVar: "project/1234", "DOCKER_HOST": "tcp://192.168.99.102:2376"},
+			machineID:   "5678",
+			dockerHost:  "tcp://192.168.99.100:2376",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			getenv := func(name string) string { return test.env[name] }
+			err := CheckDockerEnv(getenv, "minikube", test.machineID, test.dockerHost)
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("Expected an error containing %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+// checkDockerEnv checks env against the named machine of api, as CheckShellDockerEnv does.
+func checkDockerEnv(t *testing.T, api *tests.MockAPI, env map[string]string) error {
+	name := config.GetMachineName()
+	machineID, dockerHost, err := liveDockerEnv(api, name)
+	if err != nil {
+		t.Fatalf("Error getting the machine's docker env: %s", err)
+	}
+	return CheckDockerEnv(func(k string) string { return env[k] }, name, machineID, dockerHost)
+}
+
+func createDockerEnvHost(t *testing.T, api *tests.MockAPI, ip string) *host.Host {
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %v", err)
+	}
+	h.Driver.(*tests.MockDriver).IPAddress = ip
+	return h
+}
+
+func TestDockerEnvRecreatedMachine(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	createDockerEnvHost(t, api, "192.168.99.100")
+	env, err := GetHostDockerEnv(api)
+	if err != nil {
+		t.Fatalf("Unexpected error getting env: %s", err)
+	}
+	if !strings.HasPrefix(env[DockerEnvIDVar], config.GetMachineName()+"/") {
+		t.Fatalf("Expected %s to name the machine, got %q", DockerEnvIDVar, env[DockerEnvIDVar])
+	}
+	if err := checkDockerEnv(t, api, env); err != nil {
+		t.Fatalf("Unexpected error for the current env: %s", err)
+	}
+
+	// minikube delete removes the host and its machine directory.
+	delete(api.Hosts, config.GetMachineName())
+	machineDir := filepath.Join(constants.GetMinipath(), "machines", config.GetMachineName())
+	if err := os.RemoveAll(machineDir); err != nil {
+		t.Fatalf("Error removing machine dir: %s", err)
+	}
+	if err := checkDockerEnv(t, api, env); err == nil || !strings.Contains(err.Error(), "deleted") {
+		t.Errorf("Expected an error for the deleted machine, got %v", err)
+	}
+
+	// Created again, at the same IP.
+	if err := os.MkdirAll(machineDir, 0700); err != nil {
+		t.Fatalf("Error creating machine dir: %s", err)
+	}
+	createDockerEnvHost(t, api, "192.168.99.100")
+	if err := checkDockerEnv(t, api, env); err == nil || !strings.Contains(err.Error(), "deleted") {
+		t.Errorf("Expected an error for the recreated machine, got %v", err)
+	}
+	newEnv, err := GetHostDockerEnv(api)
+	if err != nil {
+		t.Fatalf("Unexpected error getting env: %s", err)
+	}
+	if newEnv[DockerEnvIDVar] == env[DockerEnvIDVar] {
+		t.Errorf("Expected the recreated machine to get another ID than %s", env[DockerEnvIDVar])
+	}
+	if err := checkDockerEnv(t, api, newEnv); err != nil {
+		t.Errorf("Unexpected error for the env of the recreated machine: %s", err)
+	}
+}
+
+func TestDockerEnvIPChange(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	h := createDockerEnvHost(t, api, "192.168.99.100")
+	env, err := GetHostDockerEnv(api)
+	if err != nil {
+		t.Fatalf("Unexpected error getting env: %s", err)
+	}
+
+	d := h.Driver.(*tests.MockDriver)
+	d.CurrentState = state.Stopped
+	if err := checkDockerEnv(t, api, env); err != nil {
+		t.Errorf("Unexpected error while the machine is stopped: %s", err)
+	}
+
+	// DHCP hands the VM another IP when it starts again.
+	d.CurrentState = state.Running
+	d.IPAddress = "192.168.99.101"
+	if err := checkDockerEnv(t, api, env); err == nil || !strings.Contains(err.Error(), "tcp://192.168.99.101:2376") {
+		t.Errorf("Expected an error for the new IP, got %v", err)
+	}
+}
+
+func TestEnsureMachineID(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	// A machine created before IDs were gets one, which is kept.
+	name := config.GetMachineName()
+	id := ensureMachineID(name)
+	if id == "" {
+		t.Fatal("Expected an ID to be recorded")
+	}
+	recordStartState(name, PhaseHostRunning, nil)
+	if again := ensureMachineID(name); again != id {
+		t.Errorf("Expected the ID %s to be kept, got %s", id, again)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/util"
+)
+
+// defaultDockerLogOpts rotate the logs of the containers, which the json-file log driver otherwise lets grow without bound.
+var defaultDockerLogOpts = map[string]string{
+	"max-size": "log-opt=max-size=10m",
+	"max-file": "log-opt=max-file=3",
+}
+
+// dockerDaemonFlags returns the flags the docker daemon is started with, the --docker-opt ones
+// and the default log rotation options they don't override. With another log driver than
+// json-file, the default options are left out, as they are json-file's.
+func dockerDaemonFlags(opts []string) []string {
+	flags := append([]string{}, opts...)
+	set := map[string]bool{}
+	for _, opt := range opts {
+		kv := strings.SplitN(strings.TrimLeft(opt, "-"), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "log-driver":
+			if kv[1] != "json-file" {
+				return flags
+			}
+		case "log-opt":
+			set[strings.SplitN(kv[1], "=", 2)[0]] = true
+		}
+	}
+	for _, name := range []string{"max-size", "max-file"} {
+		if !set[name] {
+			flags = append(flags, defaultDockerLogOpts[name])
+		}
+	}
+	return flags
+}
+
+// sameStrings returns whether a and b hold the same strings, in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setEngineOptions sets the insecure registries, registry mirrors and flags the host's docker
+// daemon is provisioned with to the ones of config, returning whether they changed. Their
+// order doesn't matter to the daemon.
+func setEngineOptions(h *host.Host, config MachineConfig) bool {
+	if h.DriverName == "none" || h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return false
+	}
+	o := h.HostOptions.EngineOptions
+	requested := engineOptions(config)
+	if sameStrings(o.InsecureRegistry, requested.InsecureRegistry) && sameStrings(o.RegistryMirror, requested.RegistryMirror) &&
+		sameStrings(o.ArbitraryFlags, requested.ArbitraryFlags) {
+		return false
+	}
+	glog.Infof("Changing the docker daemon's insecure registries from %v to %v, registry mirrors from %v to %v and flags from %v to %v",
+		o.InsecureRegistry, requested.InsecureRegistry, o.RegistryMirror, requested.RegistryMirror, o.ArbitraryFlags, requested.ArbitraryFlags)
+	o.InsecureRegistry = requested.InsecureRegistry
+	o.RegistryMirror = requested.RegistryMirror
+	o.ArbitraryFlags = requested.ArbitraryFlags
+	return true
+}
+
+// UpdateEngineOptions provisions the docker daemon of the running host again if it was
+// provisioned with other insecure registries, registry mirrors or flags, which restarts it.
+func UpdateEngineOptions(api libmachine.API, h *host.Host, config MachineConfig) error {
+	if !setEngineOptions(h, config) {
+		return nil
+	}
+	if err := h.ConfigureAuth(); err != nil {
+		return errors.Wrap(err, "Error provisioning the docker daemon with its engine options")
+	}
+	return errors.Wrap(api.Save(h), "Error saving host")
+}
+
+// addInsecureRegistry adds registry to the insecure registries the host's docker daemon is
+// provisioned with, unless they cover it already, returning whether they changed.
+func addInsecureRegistry(h *host.Host, registry string) bool {
+	if h.DriverName == "none" || h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return false
+	}
+	o := h.HostOptions.EngineOptions
+	if util.InsecureRegistryCovered(o.InsecureRegistry, registry) {
+		return false
+	}
+	glog.Infof("Adding %s to the docker daemon's insecure registries %v", registry, o.InsecureRegistry)
+	o.InsecureRegistry = util.MergeInsecureRegistries(o.InsecureRegistry, []string{registry})
+	return true
+}
+
+// AddInsecureRegistry provisions the docker daemon of the running host again with registry among
+// its insecure registries, unless they cover it already, which restarts it. It returns whether it did.
+func AddInsecureRegistry(api libmachine.API, h *host.Host, registry string) (bool, error) {
+	if !addInsecureRegistry(h, registry) {
+		return false, nil
+	}
+	if err := h.ConfigureAuth(); err != nil {
+		return false, errors.Wrap(err, "Error provisioning the docker daemon with its insecure registries")
+	}
+	return true, errors.Wrap(api.Save(h), "Error saving host")
+}
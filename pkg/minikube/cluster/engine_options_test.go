@@ -0,0 +1,211 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
+)
+
+var defaultLogFlags = []string{"log-opt=max-size=10m", "log-opt=max-file=3"}
+
+func TestEngineOptions(t *testing.T) {
+	var cases = []struct {
+		description string
+		config      MachineConfig
+		expected    engine.Options
+	}{
+		{
+			description: "defaults",
+			expected:    engine.Options{ArbitraryFlags: defaultLogFlags},
+		},
+		{
+			description: "registries and flags",
+			config: MachineConfig{
+				InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"},
+				RegistryMirror:   []string{"https://mirror.lan"},
+				DockerOpt:        []string{"storage-driver=overlay2"},
+			},
+			expected: engine.Options{
+				InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"},
+				RegistryMirror:   []string{"https://mirror.lan"},
+				ArbitraryFlags:   []string{"storage-driver=overlay2", "log-opt=max-size=10m", "log-opt=max-file=3"},
+			},
+		},
+		{
+			description: "log size overridden",
+			config:      MachineConfig{DockerOpt: []string{"log-opt=max-size=100m"}},
+			expected:    engine.Options{ArbitraryFlags: []string{"log-opt=max-size=100m", "log-opt=max-file=3"}},
+		},
+		{
+			description: "both log options overridden",
+			config:      MachineConfig{DockerOpt: []string{"log-opt=max-file=10", "log-opt=max-size=1g"}},
+			expected:    engine.Options{ArbitraryFlags: []string{"log-opt=max-file=10", "log-opt=max-size=1g"}},
+		},
+		{
+			description: "json-file log driver",
+			config:      MachineConfig{DockerOpt: []string{"log-driver=json-file"}},
+			expected:    engine.Options{ArbitraryFlags: []string{"log-driver=json-file", "log-opt=max-size=10m", "log-opt=max-file=3"}},
+		},
+		{
+			description: "other log driver",
+			config:      MachineConfig{DockerOpt: []string{"log-driver=journald"}},
+			expected:    engine.Options{ArbitraryFlags: []string{"log-driver=journald"}},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			o := engineOptions(test.config)
+			o.Env = nil
+			if !reflect.DeepEqual(*o, test.expected) {
+				t.Errorf("Expected engine options %+v, got %+v", test.expected, *o)
+			}
+		})
+	}
+}
+
+func TestSetEngineOptions(t *testing.T) {
+	var cases = []struct {
+		description string
+		driver      string
+		existing    engine.Options
+		config      MachineConfig
+		changed     bool
+	}{
+		{
+			description: "same",
+			driver:      "virtualbox",
+			existing:    engine.Options{InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"}, ArbitraryFlags: defaultLogFlags},
+			config:      MachineConfig{InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"}},
+		},
+		{
+			description: "other order",
+			driver:      "virtualbox",
+			existing:    engine.Options{InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"}, ArbitraryFlags: defaultLogFlags},
+			config:      MachineConfig{InsecureRegistry: []string{"registry.lan:5000", "10.0.0.0/24"}},
+		},
+		{
+			description: "insecure registry added",
+			driver:      "virtualbox",
+			existing:    engine.Options{InsecureRegistry: []string{"10.0.0.0/24"}, ArbitraryFlags: defaultLogFlags},
+			config:      MachineConfig{InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"}},
+			changed:     true,
+		},
+		{
+			description: "insecure registry replaced",
+			driver:      "kvm2",
+			existing:    engine.Options{InsecureRegistry: []string{"10.0.0.0/24", "registry.lan:5000"}, ArbitraryFlags: defaultLogFlags},
+			config:      MachineConfig{InsecureRegistry: []string{"10.0.0.0/24", "192.168.1.0/24"}},
+			changed:     true,
+		},
+		{
+			description: "registry mirror added",
+			driver:      "virtualbox",
+			existing:    engine.Options{ArbitraryFlags: defaultLogFlags},
+			config:      MachineConfig{RegistryMirror: []string{"https://mirror.lan"}},
+			changed:     true,
+		},
+		{
+			description: "created without log rotation",
+			driver:      "virtualbox",
+			config:      MachineConfig{},
+			changed:     true,
+		},
+		{
+			description: "docker opt changed",
+			driver:      "virtualbox",
+			existing:    engine.Options{ArbitraryFlags: append([]string{"storage-driver=overlay"}, defaultLogFlags...)},
+			config:      MachineConfig{DockerOpt: []string{"storage-driver=overlay2"}},
+			changed:     true,
+		},
+		{
+			description: "none driver",
+			driver:      "none",
+			config:      MachineConfig{InsecureRegistry: []string{"registry.lan:5000"}},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			existing := test.existing
+			h := &host.Host{DriverName: test.driver, HostOptions: &host.Options{EngineOptions: &existing}}
+			if changed := setEngineOptions(h, test.config); changed != test.changed {
+				t.Errorf("Expected changed %t, got %t", test.changed, changed)
+			}
+			expected := test.existing
+			if test.changed {
+				expected = *engineOptions(test.config)
+				expected.Env = nil
+			}
+			if got := *h.HostOptions.EngineOptions; !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected engine options %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestAddInsecureRegistry(t *testing.T) {
+	var cases = []struct {
+		description string
+		driver      string
+		existing    []string
+		expected    []string
+	}{
+		{
+			description: "added",
+			driver:      "virtualbox",
+			existing:    []string{"10.0.0.0/24"},
+			expected:    []string{"10.0.0.0/24", "192.168.99.100:30500"},
+		},
+		{
+			description: "listed already",
+			driver:      "virtualbox",
+			existing:    []string{"10.0.0.0/24", "192.168.99.100:30500"},
+		},
+		{
+			description: "in a cidr",
+			driver:      "kvm2",
+			existing:    []string{"192.168.99.0/24"},
+		},
+		{
+			description: "none driver",
+			driver:      "none",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			existing := engine.Options{InsecureRegistry: test.existing, ArbitraryFlags: defaultLogFlags}
+			h := &host.Host{DriverName: test.driver, HostOptions: &host.Options{EngineOptions: &existing}}
+			changed := addInsecureRegistry(h, "192.168.99.100:30500")
+			if changed != (test.expected != nil) {
+				t.Errorf("Expected changed %t, got %t", test.expected != nil, changed)
+			}
+			expected := test.existing
+			if test.expected != nil {
+				expected = test.expected
+			}
+			if got := h.HostOptions.EngineOptions.InsecureRegistry; !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected insecure registries %v, got %v", expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import "fmt"
+
+// ExtraDiskDevices returns the device names the extra disks attached by the driver show up as in the VM.
+func ExtraDiskDevices(driver string, n int) []string {
+	prefix := ""
+	switch driver {
+	case "virtualbox":
+		prefix = "/dev/sd"
+	case "kvm2":
+		prefix = "/dev/vd"
+	default:
+		return nil
+	}
+	devices := []string{}
+	for i := 0; i < n; i++ {
+		// The VM's own disk is the first device.
+		devices = append(devices, fmt.Sprintf("%s%c", prefix, 'b'+i))
+	}
+	return devices
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtraDiskDevices(t *testing.T) {
+	var cases = []struct {
+		description string
+		driver      string
+		disks       int
+		expected    []string
+	}{
+		{
+			description: "virtualbox",
+			driver:      "virtualbox",
+			disks:       2,
+			expected:    []string{"/dev/sdb", "/dev/sdc"},
+		},
+		{
+			description: "kvm2",
+			driver:      "kvm2",
+			disks:       1,
+			expected:    []string{"/dev/vdb"},
+		},
+		{
+			description: "no extra disks",
+			driver:      "kvm2",
+			expected:    []string{},
+		},
+		{
+			description: "unsupported driver",
+			driver:      "xhyve",
+			disks:       2,
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := ExtraDiskDevices(test.driver, test.disks); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
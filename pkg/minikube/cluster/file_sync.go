@@ -0,0 +1,155 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+const (
+	// modeFileSuffix marks the sidecar file holding the permissions of the file it is named after.
+	modeFileSuffix = ".mode"
+	// defaultFileMode is the permissions of a synced file without a sidecar file.
+	defaultFileMode = "0644"
+)
+
+var fileMode = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// syncedFile is a file under the files dir, and where it is copied to in the VM.
+type syncedFile struct {
+	Source string
+	Target string
+	Mode   string
+}
+
+// FilesDir is the tree which is mirrored into the VM's root on each start.
+func FilesDir() string {
+	return constants.MakeMiniPath("files")
+}
+
+// vmPath returns where the file at rel, relative to the files dir, goes in the VM. rel
+// may use either path separator, so the mapping doesn't depend on the host's OS.
+func vmPath(rel string) string {
+	return path.Join("/", strings.Replace(rel, `\`, "/", -1))
+}
+
+// readFileMode returns the permissions in the sidecar file of source, or the default.
+func readFileMode(source string) (string, error) {
+	data, err := ioutil.ReadFile(source + modeFileSuffix)
+	if os.IsNotExist(err) {
+		return defaultFileMode, nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "Error reading the mode of %s", source)
+	}
+	mode := strings.TrimSpace(string(data))
+	if !fileMode.MatchString(mode) {
+		return "", fmt.Errorf("The mode %q of %s is not octal permissions, such as 0600", mode, source)
+	}
+	if len(mode) == 3 {
+		mode = "0" + mode
+	}
+	return mode, nil
+}
+
+// localFiles returns the files under dir to copy into the VM. The sidecar files are
+// not copied, nor are the registry CAs and static pod manifests, which are put in place on their own.
+func localFiles(dir string) ([]syncedFile, error) {
+	var files []syncedFile
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if p == dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel == "certs" || rel == staticManifestsFilesDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, modeFileSuffix) {
+			return nil
+		}
+		mode, err := readFileMode(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, syncedFile{Source: p, Target: vmPath(rel), Mode: mode})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing the files to copy into the VM")
+	}
+	return files, nil
+}
+
+// needsDockerRestart returns whether Docker has to restart to pick up any of files.
+func needsDockerRestart(files []syncedFile) bool {
+	for _, f := range files {
+		if strings.HasPrefix(f.Target, "/etc/docker/") {
+			return true
+		}
+	}
+	return false
+}
+
+// syncFiles copies the tree under the files dir into the VM, overwriting the files
+// there. It returns whether any of them configure Docker.
+func syncFiles(h *host.Host) (bool, error) {
+	if h.Driver.DriverName() == "none" {
+		return false, nil
+	}
+	files, err := localFiles(FilesDir())
+	if err != nil || len(files) == 0 {
+		return false, err
+	}
+	client, err := sshutil.NewSSHClient(h.Driver)
+	if err != nil {
+		return false, errors.Wrap(err, "Error creating new ssh client")
+	}
+	defer client.Close()
+	for _, f := range files {
+		glog.Infof("Copying %s to %s in the VM", f.Source, f.Target)
+		asset, err := assets.NewFileAsset(f.Source, path.Dir(f.Target), path.Base(f.Target), f.Mode)
+		if err != nil {
+			return false, err
+		}
+		if err := sshutil.TransferFile(asset, client); err != nil {
+			return false, errors.Wrapf(err, "Error copying %s into the VM", f.Source)
+		}
+	}
+	return needsDockerRestart(files), nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVMPath(t *testing.T) {
+	var cases = []struct {
+		rel      string
+		expected string
+	}{
+		{rel: "etc/docker/daemon.json", expected: "/etc/docker/daemon.json"},
+		{rel: `etc\docker\daemon.json`, expected: "/etc/docker/daemon.json"},
+		{rel: `etc\kubernetes/audit-policy.yaml`, expected: "/etc/kubernetes/audit-policy.yaml"},
+		{rel: "motd", expected: "/motd"},
+	}
+	for _, test := range cases {
+		if got := vmPath(test.rel); got != test.expected {
+			t.Errorf("Expected %s to go to %s, got %s", test.rel, test.expected, got)
+		}
+	}
+}
+
+// writeFiles writes the files, relative to dir, creating their directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Error creating dir: %s", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Error writing %s: %s", name, err)
+		}
+	}
+}
+
+func TestLocalFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "files")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"etc/docker/daemon.json":             `{"log-driver": "journald"}`,
+		"etc/docker/daemon.json.mode":        "0600\n",
+		"etc/kubernetes/audit-policy.yaml":   "apiVersion: audit.k8s.io/v1beta1",
+		"etc/kubernetes/audit-policy.yaml.X": "not a sidecar",
+		"certs/registry.example.com.crt":     "a registry CA",
+		"etc/kubernetes/manifests/pod.yaml":  "a static pod",
+	})
+
+	files, err := localFiles(dir)
+	if err != nil {
+		t.Fatalf("Error listing files: %s", err)
+	}
+	expected := []syncedFile{
+		{Source: filepath.Join(dir, "etc", "docker", "daemon.json"), Target: "/etc/docker/daemon.json", Mode: "0600"},
+		{Source: filepath.Join(dir, "etc", "kubernetes", "audit-policy.yaml"), Target: "/etc/kubernetes/audit-policy.yaml", Mode: defaultFileMode},
+		{Source: filepath.Join(dir, "etc", "kubernetes", "audit-policy.yaml.X"), Target: "/etc/kubernetes/audit-policy.yaml.X", Mode: defaultFileMode},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, files)
+	}
+	if !needsDockerRestart(files) {
+		t.Errorf("Expected a new daemon.json to need Docker to restart")
+	}
+	if needsDockerRestart(files[1:]) {
+		t.Errorf("Expected files outside /etc/docker not to need Docker to restart")
+	}
+
+	writeFiles(t, dir, map[string]string{"etc/hosts.mode": "rw-r--r--", "etc/hosts": ""})
+	if _, err := localFiles(dir); err == nil {
+		t.Errorf("Expected an error for a mode which is not octal")
+	}
+
+	if files, err := localFiles(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Errorf("Expected no files in a missing dir, got %+v, %v", files, err)
+	}
+}
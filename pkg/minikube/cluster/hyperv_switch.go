@@ -0,0 +1,156 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+// externalSwitchName is the name of the external virtual switch minikube creates when there is none.
+const externalSwitchName = "minikube-external"
+
+// powerShellRunner runs PowerShell commands, returning their output.
+type powerShellRunner interface {
+	Run(command string) (string, error)
+}
+
+type execPowerShell struct{}
+
+func (execPowerShell) Run(command string) (string, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", command)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	r := console.StartCommand("the host", "PowerShell: "+command)
+	err := cmd.Run()
+	r.Done(append(stdout.Bytes(), stderr.Bytes()...), err)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error running PowerShell command %q: %s", command, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// powerShell is the runner used to set up Hyper-V, replaced in tests.
+var powerShell powerShellRunner = execPowerShell{}
+
+type vmSwitch struct {
+	Name       string
+	SwitchType string
+}
+
+type netAdapter struct {
+	Name           string
+	InterfaceIndex int
+}
+
+const (
+	listSwitchesCmd = `ConvertTo-Json -InputObject @(Get-VMSwitch | Select-Object Name, @{Name='SwitchType'; Expression={$_.SwitchType.ToString()}})`
+	listAdaptersCmd = `ConvertTo-Json -InputObject @(Get-NetAdapter -Physical | Where-Object Status -eq 'Up' | Select-Object Name, InterfaceIndex)`
+	listRoutesCmd   = `ConvertTo-Json -InputObject @(Get-NetRoute -DestinationPrefix '0.0.0.0/0' | Select-Object -ExpandProperty InterfaceIndex)`
+	newSwitchCmd    = `New-VMSwitch -Name %s -NetAdapterName %s -AllowManagementOS $true | Out-Null`
+)
+
+// powerShellQuotes are the characters PowerShell takes as single quotes, the typographic ones included.
+var powerShellQuotes = []string{"'", "\u2018", "\u2019", "\u201a", "\u201b"}
+
+// powerShellQuote quotes s as a verbatim PowerShell string, doubling the quotes it holds.
+func powerShellQuote(s string) string {
+	for _, q := range powerShellQuotes {
+		s = strings.Replace(s, q, q+q, -1)
+	}
+	return "'" + s + "'"
+}
+
+// newSwitchCommand returns the command creating the external switch name on the network adapter.
+func newSwitchCommand(name, adapter string) string {
+	return fmt.Sprintf(newSwitchCmd, powerShellQuote(name), powerShellQuote(adapter))
+}
+
+func runPowerShellJSON(ps powerShellRunner, command string, v interface{}) error {
+	out, err := ps.Run(command)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "" {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal([]byte(out), v), "Error parsing PowerShell output: %s", out)
+}
+
+// chooseHypervSwitch returns the name of an external virtual switch for the VM: an
+// existing one if there is one, otherwise a new one bound to the active network adapter.
+func chooseHypervSwitch(ps powerShellRunner) (string, error) {
+	var switches []vmSwitch
+	if err := runPowerShellJSON(ps, listSwitchesCmd, &switches); err != nil {
+		return "", errors.Wrap(err, "Error listing Hyper-V virtual switches")
+	}
+	for _, s := range switches {
+		if s.SwitchType == "External" {
+			glog.Infof("Using external virtual switch %q", s.Name)
+			return s.Name, nil
+		}
+	}
+
+	adapter, err := chooseNetAdapter(ps)
+	if err != nil {
+		return "", err
+	}
+	console.Out("Creating external virtual switch %q on network adapter %q...\n", externalSwitchName, adapter.Name)
+	if _, err := ps.Run(newSwitchCommand(externalSwitchName, adapter.Name)); err != nil {
+		return "", errors.Wrap(err, "Error creating external virtual switch")
+	}
+	return externalSwitchName, nil
+}
+
+// chooseNetAdapter returns the physical network adapter to bind a new external switch to.
+// When there are several, the one with the default route is picked.
+func chooseNetAdapter(ps powerShellRunner) (netAdapter, error) {
+	var adapters []netAdapter
+	if err := runPowerShellJSON(ps, listAdaptersCmd, &adapters); err != nil {
+		return netAdapter{}, errors.Wrap(err, "Error listing network adapters")
+	}
+	switch len(adapters) {
+	case 0:
+		return netAdapter{}, errors.New("No connected physical network adapter found to create an external virtual switch on")
+	case 1:
+		return adapters[0], nil
+	}
+
+	var routes []int
+	if err := runPowerShellJSON(ps, listRoutesCmd, &routes); err != nil {
+		return netAdapter{}, errors.Wrap(err, "Error listing default routes")
+	}
+	names := []string{}
+	for _, a := range adapters {
+		for _, index := range routes {
+			if a.InterfaceIndex == index {
+				glog.Infof("Picked network adapter %q, which has the default route, out of %d adapters", a.Name, len(adapters))
+				return a, nil
+			}
+		}
+		names = append(names, a.Name)
+	}
+	return netAdapter{}, fmt.Errorf("None of the network adapters %s has a default route, pass --hyperv-virtual-switch to pick a switch", strings.Join(names, ", "))
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"testing"
+)
+
+// mockPowerShell returns canned output for each command, and records the commands run.
+type mockPowerShell struct {
+	outputs map[string]string
+	ran     []string
+}
+
+func (m *mockPowerShell) Run(command string) (string, error) {
+	m.ran = append(m.ran, command)
+	out, ok := m.outputs[command]
+	if !ok {
+		return "", fmt.Errorf("unexpected command: %s", command)
+	}
+	return out, nil
+}
+
+func (m *mockPowerShell) didRun(command string) bool {
+	for _, c := range m.ran {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+func TestChooseHypervSwitch(t *testing.T) {
+	var tests = []struct {
+		description string
+		outputs     map[string]string
+		expected    string
+		created     string
+		err         bool
+	}{
+		{
+			description: "existing external switch",
+			outputs: map[string]string{
+				listSwitchesCmd: `[{"Name": "Default Switch", "SwitchType": "Internal"}, {"Name": "External", "SwitchType": "External"}]`,
+			},
+			expected: "External",
+		},
+		{
+			description: "no switch, one adapter",
+			outputs: map[string]string{
+				listSwitchesCmd: `[]`,
+				listAdaptersCmd: `[{"Name": "Ethernet", "InterfaceIndex": 4}]`,
+				newSwitchCommand(externalSwitchName, "Ethernet"): "",
+			},
+			expected: externalSwitchName,
+			created:  "Ethernet",
+		},
+		{
+			description: "internal switch, several adapters",
+			outputs: map[string]string{
+				listSwitchesCmd: `[{"Name": "Default Switch", "SwitchType": "Internal"}]`,
+				listAdaptersCmd: `[{"Name": "Ethernet", "InterfaceIndex": 4}, {"Name": "Wi-Fi", "InterfaceIndex": 12}]`,
+				listRoutesCmd:   `[12]`,
+				newSwitchCommand(externalSwitchName, "Wi-Fi"): "",
+			},
+			expected: externalSwitchName,
+			created:  "Wi-Fi",
+		},
+		{
+			description: "adapter with a quote in its name",
+			outputs: map[string]string{
+				listSwitchesCmd: `[]`,
+				listAdaptersCmd: `[{"Name": "Bob's Ethernet", "InterfaceIndex": 4}]`,
+				`New-VMSwitch -Name 'minikube-external' -NetAdapterName 'Bob''s Ethernet' -AllowManagementOS $true | Out-Null`: "",
+			},
+			expected: externalSwitchName,
+			created:  "Bob's Ethernet",
+		},
+		{
+			description: "several adapters, no default route",
+			outputs: map[string]string{
+				listSwitchesCmd: `[]`,
+				listAdaptersCmd: `[{"Name": "Ethernet", "InterfaceIndex": 4}, {"Name": "Wi-Fi", "InterfaceIndex": 12}]`,
+				listRoutesCmd:   `[]`,
+			},
+			err: true,
+		},
+		{
+			description: "no adapters",
+			outputs: map[string]string{
+				listSwitchesCmd: ``,
+				listAdaptersCmd: ``,
+			},
+			err: true,
+		},
+		{
+			description: "bad output",
+			outputs: map[string]string{
+				listSwitchesCmd: `Get-VMSwitch : The term 'Get-VMSwitch' is not recognized`,
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			ps := &mockPowerShell{outputs: test.outputs}
+			actual, err := chooseHypervSwitch(ps)
+			if err != nil && !test.err {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Errorf("No error returned, but expected err")
+			}
+			if actual != test.expected {
+				t.Errorf("Expected switch %q, got: %q", test.expected, actual)
+			}
+			if test.created != "" && !ps.didRun(newSwitchCommand(externalSwitchName, test.created)) {
+				t.Errorf("Expected a switch to be created on %s, ran: %v", test.created, ps.ran)
+			}
+		})
+	}
+}
+
+func TestPowerShellQuote(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected string
+	}{
+		{s: "Ethernet", expected: "'Ethernet'"},
+		{s: "Bob's Ethernet", expected: "'Bob''s Ethernet'"},
+		{s: "Bob\u2019s Wi-Fi", expected: "'Bob\u2019\u2019s Wi-Fi'"},
+		{s: "$(Remove-Item C:\\)", expected: "'$(Remove-Item C:\\)'"},
+		{s: "'; Stop-Computer; '", expected: "'''; Stop-Computer; '''"},
+	}
+
+	for _, test := range tests {
+		if got := powerShellQuote(test.s); got != test.expected {
+			t.Errorf("Expected %s quoted as %s, got %s", test.s, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/images"
+)
+
+// pauseImageOption is the kubelet setting of the pause image.
+const pauseImageOption = "PodInfraContainerImage"
+
+// AddonWithImageRepository returns the addon with the images of its manifests pulled from the repository.
+func AddonWithImageRepository(a *assets.Addon, repository string) *assets.Addon {
+	if repository == "" {
+		return a
+	}
+	return a.WithContents(func(data []byte) []byte {
+		return images.ManifestWithRepository(data, repository)
+	})
+}
+
+// ImageRewrite is an image of the cluster which is pulled from an image repository instead.
+type ImageRewrite struct {
+	From string
+	To   string
+}
+
+// ImageRepositoryRewrites returns the images pulled from the repository instead: the pause image,
+// the DNS check's and those of the bundled addons, whether they are enabled or not.
+func ImageRepositoryRewrites(repository string) []ImageRewrite {
+	refs := map[string]bool{constants.PauseImage: true, constants.DNSCheckImage: true}
+	for _, a := range assets.Addons {
+		for _, m := range a.Assets {
+			for _, ref := range images.ManifestImages(m.Bytes()) {
+				refs[ref] = true
+			}
+		}
+	}
+	sorted := []string{}
+	for ref := range refs {
+		sorted = append(sorted, ref)
+	}
+	sort.Strings(sorted)
+
+	rewrites := []ImageRewrite{}
+	for _, ref := range sorted {
+		if to := images.WithRepository(ref, repository); to != ref {
+			rewrites = append(rewrites, ImageRewrite{From: ref, To: to})
+		}
+	}
+	return rewrites
+}
+
+// pauseImageFlag returns the extra config pulling the pause image from the repository,
+// unless the extra options set the pause image already.
+func pauseImageFlag(config KubernetesConfig) string {
+	if config.ImageRepository == "" {
+		return ""
+	}
+	for _, e := range config.ExtraOptions {
+		if e.Component == "kubelet" && e.Key == pauseImageOption {
+			return ""
+		}
+	}
+	return "--extra-config=kubelet." + pauseImageOption + "=" + images.WithRepository(constants.PauseImage, config.ImageRepository)
+}
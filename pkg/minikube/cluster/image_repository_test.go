@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/util"
+)
+
+func TestAddonWithImageRepository(t *testing.T) {
+	dashboard := assets.Addons["dashboard"]
+	original := string(dashboard.Assets[0].Bytes())
+	mirrored := AddonWithImageRepository(dashboard, "registry.example.com:5000/k8s")
+
+	rc := string(mirrored.Assets[0].Bytes())
+	if !strings.Contains(rc, "image: registry.example.com:5000/k8s/kubernetes-dashboard-amd64:") || strings.Contains(rc, "gcr.io") {
+		t.Errorf("Expected the dashboard image to be pulled from the repository:\n%s", rc)
+	}
+	if mirrored.Assets[0].GetLength() != len(rc) {
+		t.Errorf("Expected the length %d of the rewritten manifest, got %d", len(rc), mirrored.Assets[0].GetLength())
+	}
+	if string(dashboard.Assets[0].Bytes()) != original {
+		t.Error("The bundled addon was modified")
+	}
+	if AddonWithImageRepository(dashboard, "") != dashboard {
+		t.Error("Expected the addon to be left alone without a repository")
+	}
+}
+
+func TestImageRepositoryRewrites(t *testing.T) {
+	rewrites := map[string]string{}
+	for _, r := range ImageRepositoryRewrites("registry.example.com/k8s") {
+		rewrites[r.From] = r.To
+	}
+	if to := rewrites["gcr.io/google_containers/pause-amd64:3.0"]; to != "registry.example.com/k8s/pause-amd64:3.0" {
+		t.Errorf("Expected the pause image to be rewritten, got %q", to)
+	}
+	if to := rewrites["gcr.io/google-containers/kube-addon-manager:v6.4-beta.1"]; to != "registry.example.com/k8s/kube-addon-manager:v6.4-beta.1" {
+		t.Errorf("Expected the addon manager image to be rewritten, got %q", to)
+	}
+	for from := range rewrites {
+		if !strings.HasPrefix(from, "gcr.io/") {
+			t.Errorf("Image %s isn't in gcr.io but is rewritten", from)
+		}
+	}
+}
+
+func TestPauseImageFlag(t *testing.T) {
+	var cases = []struct {
+		description string
+		config      KubernetesConfig
+		expected    string
+	}{
+		{
+			description: "no repository",
+		},
+		{
+			description: "repository",
+			config:      KubernetesConfig{ImageRepository: "registry.example.com:5000/k8s"},
+			expected:    "--extra-config=kubelet.PodInfraContainerImage=registry.example.com:5000/k8s/pause-amd64:3.0",
+		},
+		{
+			description: "set by extra config",
+			config: KubernetesConfig{
+				ImageRepository: "registry.example.com:5000/k8s",
+				ExtraOptions:    util.ExtraOptionSlice{{Component: "kubelet", Key: "PodInfraContainerImage", Value: "pause:3.0"}},
+			},
+		},
+	}
+
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if got := pauseImageFlag(test.config); got != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
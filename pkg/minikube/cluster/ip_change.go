@@ -0,0 +1,143 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// loadCert parses the PEM certificate at path.
+func loadCert(path string) (*x509.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("No PEM certificate in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing certificate %s", path)
+	}
+	return cert, nil
+}
+
+// certIPs returns the IP addresses the PEM certificate at path is valid for.
+func certIPs(path string) ([]net.IP, error) {
+	cert, err := loadCert(path)
+	if err != nil {
+		return nil, err
+	}
+	return cert.IPAddresses, nil
+}
+
+// staleCertIP returns the IP the certificate at path was generated for, if it
+// isn't valid for ip. The loopback and cluster service IPs it is also valid for
+// don't identify the VM, so they are skipped. A missing certificate is not stale,
+// as there is no IP for it to be out of date with.
+func staleCertIP(path string, ip net.IP) (string, bool, error) {
+	ips, err := certIPs(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	old := ""
+	for _, certIP := range ips {
+		if certIP.Equal(ip) {
+			return "", false, nil
+		}
+		if old == "" && !certIP.IsLoopback() && !certIP.Equal(internalIP) {
+			old = certIP.String()
+		}
+	}
+	return old, true, nil
+}
+
+// APIServerIPChanged returns the IP the apiserver certificate was last generated
+// for, if the VM's IP is no longer it. Starting the cluster generates the
+// certificate and the kubeconfig entry for the new IP.
+func APIServerIPChanged(ip string) (string, bool) {
+	old, stale, err := staleCertIP(CertPath("apiserver.crt"), net.ParseIP(ip))
+	if err != nil {
+		glog.Warningf("Not checking the apiserver certificate for an IP change: %s", err)
+		return "", false
+	}
+	return old, stale
+}
+
+// ensureDockerCertsIP regenerates the Docker daemon certificates of the host
+// if they were generated for another IP than the VM's, so that clients of the
+// daemon can still verify it.
+func ensureDockerCertsIP(h *host.Host, ip string) error {
+	if h.HostOptions == nil || h.HostOptions.AuthOptions == nil || h.HostOptions.AuthOptions.ServerCertPath == "" {
+		return nil
+	}
+	old, stale, err := staleCertIP(h.HostOptions.AuthOptions.ServerCertPath, net.ParseIP(ip))
+	if err != nil {
+		return errors.Wrap(err, "Error checking Docker certificates")
+	}
+	if !stale {
+		return nil
+	}
+	glog.Infof("The IP of %s changed from %s to %s, regenerating its Docker certificates", h.Name, old, ip)
+	if err := h.ConfigureAuth(); err != nil {
+		return errors.Wrap(err, "Error regenerating Docker certificates")
+	}
+	return nil
+}
+
+// ipPollInterval is how often an IP is asked for while waiting for one.
+const ipPollInterval = time.Second
+
+// WaitForHostIP returns the IP of the host VM, waiting up to timeout for its
+// driver to report one, such as while the VM is still booting.
+func WaitForHostIP(api libmachine.API, timeout time.Duration) (string, error) {
+	h, err := api.Load(cfg.GetMachineName())
+	if err != nil {
+		return "", errors.Wrap(err, "Error loading host")
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ip, err := h.Driver.GetIP()
+		if err == nil && ip != "" {
+			return ip, nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = errors.New("The driver reported no IP")
+			}
+			return "", errors.Wrapf(err, "Error getting IP after waiting %s", timeout)
+		}
+		glog.Infof("Waiting for the IP of %s: %v", h.Name, err)
+		time.Sleep(ipPollInterval)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/provision"
+	"k8s.io/minikube/pkg/minikube/tests"
+	"k8s.io/minikube/pkg/util"
+)
+
+// writeCert writes a certificate for ip, and the CA signing it, to dir, returning its path.
+func writeCert(t *testing.T, dir, ip string) string {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Error creating cert dir: %s", err)
+	}
+	caCert, caKey := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	if err := util.GenerateCACert(caCert, caKey, "minikubeCA"); err != nil {
+		t.Fatalf("Error generating CA: %s", err)
+	}
+	cert := filepath.Join(dir, "apiserver.crt")
+	ips := []net.IP{net.ParseIP(ip), internalIP, net.ParseIP("127.0.0.1")}
+	if err := util.GenerateSignedCert(cert, filepath.Join(dir, "apiserver.key"), ips, nil, caCert, caKey); err != nil {
+		t.Fatalf("Error generating cert: %s", err)
+	}
+	return cert
+}
+
+func TestStaleCertIP(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	cert := writeCert(t, tempDir, "192.168.99.100")
+
+	var cases = []struct {
+		description string
+		path        string
+		ip          string
+		stale       bool
+		old         string
+	}{
+		{
+			description: "same IP",
+			path:        cert,
+			ip:          "192.168.99.100",
+		},
+		{
+			description: "changed IP",
+			path:        cert,
+			ip:          "192.168.99.101",
+			stale:       true,
+			old:         "192.168.99.100",
+		},
+		{
+			description: "no certificate",
+			path:        filepath.Join(tempDir, "missing.crt"),
+			ip:          "192.168.99.101",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			old, stale, err := staleCertIP(test.path, net.ParseIP(test.ip))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if stale != test.stale || old != test.old {
+				t.Errorf("Expected stale %t with old IP %q, got %t with %q", test.stale, test.old, stale, old)
+			}
+		})
+	}
+}
+
+func TestGetHostDockerEnvIPChange(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	p := &tests.MockProvisioner{}
+	provision.SetDetector(&tests.MockDetector{Provisioner: p})
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %v", err)
+	}
+	h.HostOptions.AuthOptions = &auth.Options{ServerCertPath: writeCert(t, filepath.Join(tempDir, "server"), "192.168.99.100")}
+	d := &tests.MockDriver{BaseDriver: drivers.BaseDriver{IPAddress: "192.168.99.100"}}
+	h.Driver = d
+
+	if _, err := GetHostDockerEnv(api); err != nil {
+		t.Fatalf("Unexpected error getting env: %s", err)
+	}
+	if p.Provisioned {
+		t.Fatal("Did not expect the Docker certificates to be regenerated for the same IP")
+	}
+
+	// DHCP hands the VM another IP.
+	d.IPAddress = "192.168.99.101"
+	envMap, err := GetHostDockerEnv(api)
+	if err != nil {
+		t.Fatalf("Unexpected error getting env: %s", err)
+	}
+	if !p.Provisioned {
+		t.Error("Expected the Docker certificates to be regenerated for the new IP")
+	}
+	if expected := "tcp://192.168.99.101:2376"; envMap["DOCKER_HOST"] != expected {
+		t.Errorf("Expected DOCKER_HOST %s, got %s", expected, envMap["DOCKER_HOST"])
+	}
+}
+
+// bootingDriver is a MockDriver which has no IP for its first calls to GetIP.
+type bootingDriver struct {
+	tests.MockDriver
+	noIPCalls int
+}
+
+func (d *bootingDriver) GetIP() (string, error) {
+	if d.noIPCalls > 0 {
+		d.noIPCalls--
+		return "", drivers.ErrHostIsNotRunning
+	}
+	return d.MockDriver.GetIP()
+}
+
+func TestWaitForHostIP(t *testing.T) {
+	var cases = []struct {
+		description string
+		noIPCalls   int
+		timeout     time.Duration
+		shouldErr   bool
+	}{
+		{
+			description: "IP right away",
+		},
+		{
+			description: "IP after booting",
+			noIPCalls:   1,
+			timeout:     time.Minute,
+		},
+		{
+			description: "no IP in time",
+			noIPCalls:   1,
+			shouldErr:   true,
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			api := tests.NewMockAPI()
+			h, err := createHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error creating host: %v", err)
+			}
+			h.Driver = &bootingDriver{MockDriver: tests.MockDriver{BaseDriver: drivers.BaseDriver{IPAddress: "192.168.99.100"}}, noIPCalls: test.noIPCalls}
+
+			ip, err := WaitForHostIP(api, test.timeout)
+			if test.shouldErr {
+				if err == nil || !strings.Contains(err.Error(), drivers.ErrHostIsNotRunning.Error()) {
+					t.Errorf("Expected an error waiting for the IP, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error waiting for the IP: %s", err)
+			}
+			if ip != "192.168.99.100" {
+				t.Errorf("Expected IP 192.168.99.100, got %s", ip)
+			}
+		})
+	}
+}
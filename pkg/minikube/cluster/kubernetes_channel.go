@@ -0,0 +1,51 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/kubernetes_versions"
+)
+
+// ResolveKubernetesVersion returns the Kubernetes version to start the named machine with.
+// A release channel, such as stable, is resolved against channelURL. The version a machine's
+// cluster was resolved to from the same channel before is kept, so that starting it again
+// doesn't upgrade it, and offline, there is nothing else to go on.
+func ResolveKubernetesVersion(name, requested, channelURL string, offline bool) (string, error) {
+	if !kubernetes_versions.IsChannel(requested) {
+		return requested, nil
+	}
+	last, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	if last.KubernetesChannel == requested && last.KubernetesVersion != "" {
+		glog.Infof("Using Kubernetes %s, which %s resolved to when %s was last started", last.KubernetesVersion, requested, name)
+		return last.KubernetesVersion, nil
+	}
+	if offline {
+		return "", fmt.Errorf("The %s Kubernetes version can't be resolved offline, %s has never been started with it", requested, name)
+	}
+	version, err := kubernetes_versions.ResolveChannel(requested, channelURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error resolving the %s Kubernetes version", requested)
+	}
+	return version, nil
+}
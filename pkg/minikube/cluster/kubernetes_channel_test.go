@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/kubernetes_versions"
+)
+
+// channelServer serves a stable channel file holding version.
+func channelServer(version string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stable.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, version)
+	}))
+}
+
+func TestResolveKubernetesVersion(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+	stable := kubernetes_versions.ChannelStable
+
+	server := channelServer("v1.7.0")
+	defer server.Close()
+
+	if v, err := ResolveKubernetesVersion(name, "v1.6.4", server.URL, true); err != nil || v != "v1.6.4" {
+		t.Errorf("Expected an explicit version to be used as it is, got %s, %v", v, err)
+	}
+	if _, err := ResolveKubernetesVersion(name, stable, server.URL, true); err == nil {
+		t.Error("Expected an error resolving a channel offline before the machine was started with it")
+	}
+	v, err := ResolveKubernetesVersion(name, stable, server.URL, false)
+	if err != nil || v != "v1.7.0" {
+		t.Fatalf("Expected stable to resolve to v1.7.0, got %s, %v", v, err)
+	}
+	recordKubernetesVersion(name, v, stable)
+
+	// A newer stable release doesn't upgrade the cluster, online or offline.
+	newer := channelServer("v1.8.0")
+	defer newer.Close()
+	for _, offline := range []bool{false, true} {
+		if v, err := ResolveKubernetesVersion(name, stable, newer.URL, offline); err != nil || v != "v1.7.0" {
+			t.Errorf("Expected the cluster to keep v1.7.0 with offline %t, got %s, %v", offline, v, err)
+		}
+	}
+
+	// Once started with an explicit version, the channel is resolved again.
+	recordKubernetesVersion(name, "v1.6.4", "")
+	if v, err := ResolveKubernetesVersion(name, stable, newer.URL, false); err != nil || v != "v1.8.0" {
+		t.Errorf("Expected stable to resolve to v1.8.0, got %s, %v", v, err)
+	}
+}
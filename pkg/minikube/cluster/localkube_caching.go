@@ -18,16 +18,23 @@ package cluster
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
+	"github.com/golang/glog"
 	download "github.com/jimmidyson/go-download"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 	"k8s.io/minikube/pkg/util"
 )
 
@@ -36,9 +43,17 @@ type localkubeCacher struct {
 	k8sConf KubernetesConfig
 }
 
+// localkubeCacheDir is the directory localkube binaries are cached in.
+func localkubeCacheDir() string {
+	return constants.MakeCachePath("localkube")
+}
+
 func (l *localkubeCacher) getLocalkubeCacheFilepath() string {
-	return filepath.Join(constants.GetMinipath(), "cache", "localkube",
-		filepath.Base(url.QueryEscape("localkube-"+l.k8sConf.KubernetesVersion)))
+	return filepath.Join(localkubeCacheDir(), filepath.Base(url.QueryEscape("localkube-"+l.k8sConf.KubernetesVersion)))
+}
+
+func (l *localkubeCacher) getLocalkubeChecksumFilepath() string {
+	return l.getLocalkubeCacheFilepath() + constants.ShaSuffix
 }
 
 func (l *localkubeCacher) isLocalkubeCached() bool {
@@ -48,28 +63,102 @@ func (l *localkubeCacher) isLocalkubeCached() bool {
 	return true
 }
 
-func (l *localkubeCacher) downloadAndCacheLocalkube() error {
-	err := errors.New("")
+// downloadAndCacheLocalkube downloads localkube into the cache, showing its progress on w,
+// and records its SHA256 checksum next to it.
+func (l *localkubeCacher) downloadAndCacheLocalkube(w io.Writer) (string, error) {
 	url, err := util.GetLocalkubeDownloadURL(l.k8sConf.KubernetesVersion, constants.LocalkubeLinuxFilename)
 	if err != nil {
-		return errors.Wrap(err, "Error getting localkube download url")
+		return "", errors.Wrap(err, "Error getting localkube download url")
 	}
 	opts := download.FileOptions{
 		Mkdirs: download.MkdirAll,
 		Options: download.Options{
 			ProgressBars: &download.ProgressBarOptions{
+				Writer:   w,
 				MaxWidth: 80,
 			},
 		},
 	}
-	fmt.Println("Downloading localkube binary")
-	return download.ToFile(url, l.getLocalkubeCacheFilepath(), opts)
+	console.Phase("Downloading localkube %s from %s", l.k8sConf.KubernetesVersion, url)
+	fmt.Fprintln(w, "Downloading localkube binary")
+	if err := download.ToFile(url, l.getLocalkubeCacheFilepath(), opts); err != nil {
+		return "", err
+	}
+	return l.recordChecksum()
+}
+
+// recordChecksum writes the checksum of the cached localkube next to it, in the format of sha256sum.
+func (l *localkubeCacher) recordChecksum() (string, error) {
+	path := l.getLocalkubeCacheFilepath()
+	checksum, err := util.FileChecksum(path)
+	if err != nil {
+		return "", errors.Wrap(err, "Error computing localkube checksum")
+	}
+	line := fmt.Sprintf("%s  %s\n", checksum, filepath.Base(path))
+	if err := ioutil.WriteFile(l.getLocalkubeChecksumFilepath(), []byte(line), 0644); err != nil {
+		return "", errors.Wrap(err, "Error writing localkube checksum")
+	}
+	return checksum, nil
+}
+
+// verifyCachedLocalkube checks the cached localkube against the checksum recorded when it
+// was downloaded, and returns the checksum. A localkube cached before checksums were
+// recorded gets its checksum recorded now.
+func (l *localkubeCacher) verifyCachedLocalkube() (string, error) {
+	path := l.getLocalkubeCacheFilepath()
+	expected, err := util.ReadChecksumFile(l.getLocalkubeChecksumFilepath())
+	if os.IsNotExist(err) {
+		glog.Infof("No checksum recorded for %s, recording it", path)
+		return l.recordChecksum()
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "Error reading localkube checksum")
+	}
+	checksum, err := util.FileChecksum(path)
+	if err != nil {
+		return "", errors.Wrap(err, "Error computing localkube checksum")
+	}
+	if checksum != expected {
+		return "", fmt.Errorf("Checksum of %s is %s, expected %s", path, checksum, expected)
+	}
+	return checksum, nil
+}
+
+// ensureLocalkubeCached makes sure the cache holds an intact localkube, downloading it when
+// it is missing or fails verification, and returns its checksum. Offline, a missing or
+// corrupt localkube is an error.
+func (l *localkubeCacher) ensureLocalkubeCached(w io.Writer) (string, error) {
+	path := l.getLocalkubeCacheFilepath()
+	if l.isLocalkubeCached() {
+		checksum, err := l.verifyCachedLocalkube()
+		if err == nil {
+			console.Phase("Using localkube %s cached at %s", l.k8sConf.KubernetesVersion, path)
+			return checksum, nil
+		}
+		if l.k8sConf.Offline {
+			return "", errors.Wrapf(err, "localkube %s cached at %s is corrupt and can't be downloaded again offline", l.k8sConf.KubernetesVersion, path)
+		}
+		fmt.Fprintf(w, "Cached localkube %s is corrupt, downloading it again: %s\n", l.k8sConf.KubernetesVersion, err)
+		for _, p := range []string{path, l.getLocalkubeChecksumFilepath()} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return "", errors.Wrap(err, "Error removing corrupt localkube")
+			}
+		}
+	} else if l.k8sConf.Offline {
+		return "", fmt.Errorf("localkube %s is not cached at %s and can't be downloaded offline", l.k8sConf.KubernetesVersion, path)
+	}
+	checksum, err := l.downloadAndCacheLocalkube(w)
+	if err != nil {
+		return "", errors.Wrap(err, "Error attempting to download and cache localkube")
+	}
+	return checksum, nil
 }
 
-func (l *localkubeCacher) fetchLocalkubeFromURI() (assets.CopyableFile, error) {
+// fetchLocalkubeFromURI returns the localkube to copy into the VM along with its checksum.
+func (l *localkubeCacher) fetchLocalkubeFromURI() (assets.CopyableFile, string, error) {
 	urlObj, err := url.Parse(l.k8sConf.KubernetesVersion)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error parsing --kubernetes-version url")
+		return nil, "", errors.Wrap(err, "Error parsing --kubernetes-version url")
 	}
 	if urlObj.Scheme == fileScheme {
 		return l.genLocalkubeFileFromFile()
@@ -77,25 +166,84 @@ func (l *localkubeCacher) fetchLocalkubeFromURI() (assets.CopyableFile, error) {
 	return l.genLocalkubeFileFromURL()
 }
 
-func (l *localkubeCacher) genLocalkubeFileFromURL() (assets.CopyableFile, error) {
-	if !l.isLocalkubeCached() {
-		if err := l.downloadAndCacheLocalkube(); err != nil {
-			return nil, errors.Wrap(err, "Error attempting to download and cache localkube")
-		}
+func (l *localkubeCacher) genLocalkubeFileFromURL() (assets.CopyableFile, string, error) {
+	checksum, err := l.ensureLocalkubeCached(util.TerminalWriter(console.OutWriter()))
+	if err != nil {
+		return nil, "", err
 	}
 	localkubeFile, err := assets.NewFileAsset(l.getLocalkubeCacheFilepath(), "/usr/local/bin", "localkube", "0777")
 	if err != nil {
-		return nil, errors.Wrap(err, "Error creating localkube asset from url")
+		return nil, "", errors.Wrap(err, "Error creating localkube asset from url")
 	}
-	return localkubeFile, nil
+	return localkubeFile, checksum, nil
 }
 
-func (l *localkubeCacher) genLocalkubeFileFromFile() (assets.CopyableFile, error) {
+func (l *localkubeCacher) genLocalkubeFileFromFile() (assets.CopyableFile, string, error) {
 	path := strings.TrimPrefix(l.k8sConf.KubernetesVersion, "file://")
 	path = filepath.FromSlash(path)
+	checksum, err := util.FileChecksum(path)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error computing localkube checksum")
+	}
 	localkubeFile, err := assets.NewFileAsset(path, "/usr/local/bin", "localkube", "0777")
 	if err != nil {
-		return nil, errors.Wrap(err, "Error creating localkube asset from file")
+		return nil, "", errors.Wrap(err, "Error creating localkube asset from file")
+	}
+	return localkubeFile, checksum, nil
+}
+
+// transferVerified copies the file into the VM and compares the checksum of the
+// sha256sum run inside the VM on the copy with checksum, copying it once more
+// when they don't match.
+func transferVerified(f assets.CopyableFile, checksum string, client *ssh.Client) error {
+	target := path.Join(f.GetTargetDir(), f.GetTargetName())
+	for attempt := 1; ; attempt++ {
+		if err := sshutil.TransferFile(f, client); err != nil {
+			return err
+		}
+		err := verifyTransfer(target, checksum, client)
+		if err == nil {
+			return nil
+		}
+		if attempt == 2 {
+			return err
+		}
+		glog.Infof("Copying %s again: %s", target, err)
+		if f, err = assets.NewFileAsset(f.GetAssetName(), f.GetTargetDir(), f.GetTargetName(), f.GetPermissions()); err != nil {
+			return err
+		}
+	}
+}
+
+func sha256sumCommand(target string) string {
+	return fmt.Sprintf("sha256sum %s", target)
+}
+
+// verifyTransfer checks the file at target in the VM against checksum.
+func verifyTransfer(target, checksum string, client *ssh.Client) error {
+	cmd := sha256sumCommand(target)
+	out, err := sshutil.RunCommandOutput(client, cmd)
+	if err != nil {
+		return errors.Wrapf(err, "Error running command: %s", cmd)
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return fmt.Errorf("No checksum in the output of %s: %q", cmd, out)
+	}
+	if fields[0] != checksum {
+		return fmt.Errorf("Checksum of %s in the VM is %s, expected %s", target, fields[0], checksum)
+	}
+	return nil
+}
+
+// CacheLocalkube downloads the localkube of the Kubernetes version into the cache ahead
+// of UpdateCluster, showing its progress on w. Nothing is downloaded when an intact one
+// is cached already or the version is a file URL.
+func CacheLocalkube(k8sConf KubernetesConfig, w io.Writer) error {
+	if strings.HasPrefix(k8sConf.KubernetesVersion, "file://") {
+		return nil
 	}
-	return localkubeFile, nil
+	l := localkubeCacher{k8sConf}
+	_, err := l.ensureLocalkubeCached(w)
+	return err
 }
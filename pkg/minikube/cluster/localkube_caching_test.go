@@ -0,0 +1,140 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+// countingLocalkubeHandler serves testLocalkubeBin, counting the downloads.
+type countingLocalkubeHandler struct {
+	downloads int32
+}
+
+func (h *countingLocalkubeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&h.downloads, 1)
+	io.WriteString(w, testLocalkubeBin)
+}
+
+func newLocalkubeTestVM(t *testing.T, vmChecksum string) (*tests.SSHServer, *tests.MockDriver) {
+	s, _ := tests.NewSSHServer()
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	s.SetCommandToOutput(map[string]string{
+		sha256sumCommand("/usr/local/bin/localkube"): vmChecksum + "  /usr/local/bin/localkube\n",
+	})
+	return s, &tests.MockDriver{
+		Port: port,
+		BaseDriver: drivers.BaseDriver{
+			IPAddress:  "127.0.0.1",
+			SSHKeyPath: "",
+		},
+	}
+}
+
+func TestUpdateCorruptCachedLocalkube(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	handler := &countingLocalkubeHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	kubernetesConfig := KubernetesConfig{
+		KubernetesVersion: server.URL,
+	}
+	l := localkubeCacher{kubernetesConfig}
+
+	_, d := newLocalkubeTestVM(t, testLocalkubeChecksum)
+	if err := UpdateCluster(d, kubernetesConfig); err != nil {
+		t.Fatalf("Error updating cluster: %s", err)
+	}
+	checksum, err := ioutil.ReadFile(l.getLocalkubeChecksumFilepath())
+	if err != nil {
+		t.Fatalf("Error reading the recorded checksum: %s", err)
+	}
+	if !strings.HasPrefix(string(checksum), testLocalkubeChecksum) {
+		t.Fatalf("Expected the recorded checksum to be %s, got %q", testLocalkubeChecksum, checksum)
+	}
+
+	if err := ioutil.WriteFile(l.getLocalkubeCacheFilepath(), []byte("hellp"), 0644); err != nil {
+		t.Fatalf("Error corrupting the cached localkube: %s", err)
+	}
+
+	offline := kubernetesConfig
+	offline.Offline = true
+	_, d = newLocalkubeTestVM(t, testLocalkubeChecksum)
+	if err := UpdateCluster(d, offline); err == nil {
+		t.Fatal("Expected an error updating offline with a corrupt cached localkube")
+	}
+	if n := atomic.LoadInt32(&handler.downloads); n != 1 {
+		t.Fatalf("Expected localkube to be downloaded once, it was downloaded %d times", n)
+	}
+
+	s, d := newLocalkubeTestVM(t, testLocalkubeChecksum)
+	if err := UpdateCluster(d, kubernetesConfig); err != nil {
+		t.Fatalf("Error updating cluster: %s", err)
+	}
+	if n := atomic.LoadInt32(&handler.downloads); n != 2 {
+		t.Fatalf("Expected the corrupt localkube to be downloaded again, it was downloaded %d times", n)
+	}
+	cached, err := ioutil.ReadFile(l.getLocalkubeCacheFilepath())
+	if err != nil {
+		t.Fatalf("Error reading the cached localkube: %s", err)
+	}
+	if string(cached) != testLocalkubeBin {
+		t.Fatalf("Expected the cached localkube to be %q, got %q", testLocalkubeBin, cached)
+	}
+	if !bytes.Contains(s.Transfers.Bytes(), []byte(testLocalkubeBin)) {
+		t.Fatalf("File not copied. Expected transfers to contain: %s. It was: %s", testLocalkubeBin, s.Transfers.Bytes())
+	}
+	if _, ok := s.Commands[sha256sumCommand("/usr/local/bin/localkube")]; !ok {
+		t.Fatalf("Expected the copy of localkube to be verified in the VM, commands run: %v", s.Commands)
+	}
+}
+
+func TestUpdateLocalkubeChecksumMismatchInVM(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	server := httptest.NewServer(&countingLocalkubeHandler{})
+	defer server.Close()
+	kubernetesConfig := KubernetesConfig{
+		KubernetesVersion: server.URL,
+	}
+
+	s, d := newLocalkubeTestVM(t, strings.Repeat("0", len(testLocalkubeChecksum)))
+	if err := UpdateCluster(d, kubernetesConfig); err == nil {
+		t.Fatal("Expected an error when the checksum of localkube in the VM doesn't match")
+	}
+	// The copy is retried once before giving up.
+	if n := bytes.Count(s.Transfers.Bytes(), []byte(testLocalkubeBin)); n != 2 {
+		t.Fatalf("Expected localkube to be copied twice, it was copied %d times", n)
+	}
+}
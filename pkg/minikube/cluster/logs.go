@@ -0,0 +1,184 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/logs"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/none"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+// LogsOptions are the options of minikube logs.
+type LogsOptions struct {
+	// Follow prints the lines appended to the logs after their last ones, until interrupted.
+	Follow bool
+	// Problems prints only the lines pointing out problems.
+	Problems bool
+	// Length is how many of the last lines of each log are printed.
+	Length int
+}
+
+// logsRunner runs the commands printing the logs in the VM over an SSH connection, and those
+// following them over a session which re-dials the VM when the connection drops. With the none
+// driver, they are run on the host.
+type logsRunner struct {
+	h       *host.Host
+	client  *ssh.Client
+	mu      sync.Mutex
+	session *sshutil.Session
+}
+
+func newLogsRunner(h *host.Host) (*logsRunner, error) {
+	r := &logsRunner{h: h}
+	if h.Driver.DriverName() == "none" {
+		return r, nil
+	}
+	c, err := sshutil.NewSSHClient(h.Driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating ssh client")
+	}
+	r.client = c
+	return r, nil
+}
+
+func (r *logsRunner) Run(cmd string) (string, error) {
+	if r.client == nil {
+		return none.RunCommand(cmd, false)
+	}
+	s, err := r.client.NewSession()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating new session for ssh client")
+	}
+	defer s.Close()
+	out, err := s.CombinedOutput(cmd)
+	return string(out), err
+}
+
+func (r *logsRunner) Stream(cmd string, w io.Writer) error {
+	if r.client == nil {
+		c := exec.Command("/bin/sh", "-c", cmd)
+		c.Stdout, c.Stderr = w, w
+		return c.Run()
+	}
+	r.mu.Lock()
+	if r.session == nil {
+		session, err := sshutil.NewSession(r.h.Driver)
+		if err != nil {
+			r.mu.Unlock()
+			return errors.Wrap(err, "Error creating ssh session")
+		}
+		r.session = session
+	}
+	r.mu.Unlock()
+	return r.session.Stream(cmd, w, w)
+}
+
+func (r *logsRunner) Close() {
+	if r.client != nil {
+		r.client.Close()
+	}
+	if r.session != nil {
+		r.session.Close()
+	}
+}
+
+// WriteLogs writes the logs of the VM to w: those of localkube, which runs the kubelet, of the
+// container runtime, the kernel's and those of the containers of kube-system, each line prefixed
+// with its log. When the VM is unreachable, the logs kept on the host are written instead.
+func WriteLogs(api libmachine.API, w io.Writer, opts LogsOptions) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error checking that api exists and loading it")
+	}
+	if s, err := h.Driver.GetState(); err != nil || s != state.Running {
+		glog.Infof("Not getting the logs of the VM, in state %s: %v", s, err)
+		return writeHostLogs(w, h, opts)
+	}
+	r, err := newLogsRunner(h)
+	if err != nil {
+		glog.Infof("Not getting the logs of the VM, it is unreachable: %s", err)
+		return writeHostLogs(w, h, opts)
+	}
+	defer r.Close()
+
+	last, err := LoadStartState(cfg.GetMachineName())
+	if err != nil {
+		glog.Warningf("Ignoring the start state: %s", err)
+	}
+	rt, err := cruntime.Lookup(last.ContainerRuntime)
+	if err != nil {
+		return err
+	}
+	sources := logs.Sources(r, rt, opts.Length)
+	if opts.Follow {
+		return logs.Follow(r, sources, w, opts.Problems)
+	}
+	// The kubelet's lines about the static pods it couldn't run are pointed out among the rest.
+	if len(last.StaticManifests) > 0 {
+		sources = append(sources, logs.Source{Name: "static pods", Command: staticPodLogsCommand})
+	}
+	return logs.Output(r, sources, w, opts.Problems)
+}
+
+// HostLogPaths returns the logs the driver of the host keeps on the host.
+func HostLogPaths(h *host.Host) []string {
+	dir := filepath.Join(constants.GetMinipath(), "machines", h.Name)
+	switch h.DriverName {
+	case "virtualbox":
+		return []string{filepath.Join(dir, h.Name, "Logs", "VBox.log")}
+	case "hyperkit":
+		return []string{filepath.Join(dir, "console-ring")}
+	case "kvm", "kvm2":
+		return []string{filepath.Join("/var/log/libvirt/qemu", h.Name+".log")}
+	}
+	return nil
+}
+
+// writeHostLogs writes how the last start of the host went, and the last lines of the logs
+// its driver keeps on the host, for when the VM is unreachable.
+func writeHostLogs(w io.Writer, h *host.Host, opts LogsOptions) error {
+	console.ErrLn("The VM is unreachable, showing the logs kept on the host.")
+	last, err := LoadStartState(h.Name)
+	if err != nil {
+		return err
+	}
+	if !last.Time.IsZero() && (!opts.Problems || last.Failed()) {
+		fmt.Fprintf(w, "[start] The last start, at %s, %s\n", last.Time.Format("2006-01-02 15:04:05"), last)
+	}
+	for _, p := range HostLogPaths(h) {
+		if err := logs.WriteFile(w, filepath.Base(p), p, opts.Length, opts.Problems); err != nil {
+			glog.Infof("Not showing %s: %s", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMountUID and DefaultMountGID own the mounted files in the VM, as the docker user.
+	DefaultMountUID = "1000"
+	DefaultMountGID = "1000"
+	// DefaultMountMsize is the maximum size of the 9P messages of a mount.
+	DefaultMountMsize = 262144
+	// minMountMsize is the smallest msize the VM's 9p client accepts.
+	minMountMsize = 4096
+)
+
+// mountIDName matches the names of users and groups.
+var mountIDName = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+
+// MountConfig are the options of a 9p mount of a host folder into the VM.
+type MountConfig struct {
+	// UID and GID own the mounted files in the VM.
+	UID int
+	GID int
+	// FileMode and DirMode, when not 0, replace the permissions of the mounted files and directories.
+	FileMode os.FileMode
+	DirMode  os.FileMode
+	Msize    int
+}
+
+// NewMountConfig returns the config of a mount. uid and gid are ids, or the names of a user and
+// a group of the VM, which are looked up by running commands with run. The modes are octal,
+// the host's permissions being kept when they are empty.
+func NewMountConfig(uid, gid, fileMode, dirMode string, msize int, run func(cmd string) (string, error)) (MountConfig, error) {
+	var c MountConfig
+	var err error
+	if c.UID, err = resolveMountID(uid, false, run); err != nil {
+		return c, err
+	}
+	if c.GID, err = resolveMountID(gid, true, run); err != nil {
+		return c, err
+	}
+	if c.FileMode, err = parseMountMode(fileMode); err != nil {
+		return c, err
+	}
+	if c.DirMode, err = parseMountMode(dirMode); err != nil {
+		return c, err
+	}
+	if msize < minMountMsize {
+		return c, errors.Errorf("The msize %d is smaller than the minimum of %d", msize, minMountMsize)
+	}
+	c.Msize = msize
+	return c, nil
+}
+
+// resolveMountID returns the numeric id of the user, or group, id in the VM.
+func resolveMountID(id string, group bool, run func(cmd string) (string, error)) (int, error) {
+	kind, cmd := "user", fmt.Sprintf("id -u %s", id)
+	if group {
+		kind, cmd = "group", fmt.Sprintf("getent group %s | cut -d: -f3", id)
+	}
+	if n, err := strconv.Atoi(id); err == nil {
+		if n < 0 {
+			return 0, errors.Errorf("The %s id %d is negative", kind, n)
+		}
+		return n, nil
+	}
+	if !mountIDName.MatchString(id) {
+		return 0, errors.Errorf("%q is neither a numeric %s id nor a %s name", id, kind, kind)
+	}
+	out, err := run(cmd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Error looking up %s %s in the VM", kind, id)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, errors.Errorf("Unknown %s %s in the VM", kind, id)
+	}
+	return n, nil
+}
+
+func parseMountMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, errors.Errorf("Invalid mode %q, expected octal permissions such as 0755", s)
+	}
+	return os.FileMode(mode), nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewMountConfig(t *testing.T) {
+	// The VM has the docker user and group, and the staff group.
+	run := func(cmd string) (string, error) {
+		switch cmd {
+		case "id -u docker":
+			return "1000\n", nil
+		case "getent group docker | cut -d: -f3":
+			return "1000\n", nil
+		case "getent group staff | cut -d: -f3":
+			return "50\n", nil
+		case "id -u nobody", "getent group nobody | cut -d: -f3":
+			return "", nil
+		}
+		return "", fmt.Errorf("unexpected command %q", cmd)
+	}
+
+	var cases = []struct {
+		description string
+		uid, gid    string
+		fileMode    string
+		dirMode     string
+		msize       int
+		expected    MountConfig
+		shouldErr   bool
+	}{
+		{
+			description: "defaults",
+			uid:         DefaultMountUID,
+			gid:         DefaultMountGID,
+			msize:       DefaultMountMsize,
+			expected:    MountConfig{UID: 1000, GID: 1000, Msize: DefaultMountMsize},
+		},
+		{
+			description: "names and modes",
+			uid:         "docker",
+			gid:         "staff",
+			fileMode:    "0644",
+			dirMode:     "755",
+			msize:       65536,
+			expected:    MountConfig{UID: 1000, GID: 50, FileMode: 0644, DirMode: 0755, Msize: 65536},
+		},
+		{
+			description: "unknown user",
+			uid:         "nobody",
+			gid:         "1000",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "unknown group",
+			uid:         "1000",
+			gid:         "nobody",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "invalid name",
+			uid:         "docker; reboot",
+			gid:         "1000",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "negative id",
+			uid:         "1000",
+			gid:         "-1",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "mode not octal",
+			uid:         "1000",
+			gid:         "1000",
+			fileMode:    "rw-r--r--",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "mode out of range",
+			uid:         "1000",
+			gid:         "1000",
+			dirMode:     "1777",
+			msize:       DefaultMountMsize,
+			shouldErr:   true,
+		},
+		{
+			description: "msize too small",
+			uid:         "1000",
+			gid:         "1000",
+			msize:       1024,
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			c, err := NewMountConfig(test.uid, test.gid, test.fileMode, test.dirMode, test.msize, run)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error, got %+v", c)
+			}
+			if !test.shouldErr && c != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, c)
+			}
+		})
+	}
+}
+
+func TestGetMountCommand(t *testing.T) {
+	var cases = []struct {
+		description string
+		path        string
+		config      MountConfig
+		expected    []string
+		unexpected  []string
+	}{
+		{
+			description: "defaults",
+			config:      MountConfig{UID: 1000, GID: 1000, Msize: DefaultMountMsize},
+			expected: []string{
+				"sudo mkdir -p /mount-9p || true;",
+				"sudo mount -t 9p -o trans=tcp -o port=5005 -o dfltuid=1000 -o dfltgid=1000 -o msize=262144 192.168.99.1 /mount-9p;",
+				"sudo chmod 775 /mount-9p;",
+			},
+		},
+		{
+			description: "owner and modes",
+			config:      MountConfig{UID: 0, GID: 50, FileMode: 0644, DirMode: os.FileMode(0755), Msize: 65536},
+			expected:    []string{"-o dfltuid=0 -o dfltgid=50 -o msize=65536 192.168.99.1 /mount-9p;"},
+			unexpected:  []string{"chmod"},
+		},
+		{
+			description: "path with spaces",
+			path:        "/home/First Last",
+			config:      MountConfig{UID: 1000, GID: 1000, Msize: DefaultMountMsize},
+			expected: []string{
+				"sudo mkdir -p '/home/First Last' || true;",
+				"192.168.99.1 '/home/First Last';",
+				"sudo chmod 775 '/home/First Last';",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			path := test.path
+			if path == "" {
+				path = "/mount-9p"
+			}
+			cmd, err := GetMountCommand(net.ParseIP("192.168.99.1"), path, "5005", test.config)
+			if err != nil {
+				t.Fatalf("Error generating mount command: %s", err)
+			}
+			for _, part := range test.expected {
+				if !strings.Contains(cmd, part) {
+					t.Errorf("Expected %q in the mount command: %s", part, cmd)
+				}
+			}
+			for _, part := range test.unexpected {
+				if strings.Contains(cmd, part) {
+					t.Errorf("Expected no %q in the mount command: %s", part, cmd)
+				}
+			}
+		})
+	}
+}
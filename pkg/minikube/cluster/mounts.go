@@ -0,0 +1,154 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// mountsFile is the name of the file, in the machine directory, the background mounts are recorded in.
+const mountsFile = "mounts.json"
+
+// AllMounts selects every background mount of a machine in KillMounts.
+const AllMounts = "all"
+
+// Mount is a `minikube mount` process running in the background.
+type Mount struct {
+	// ID identifies the mount among the machine's.
+	ID       int
+	PID      int
+	HostPath string
+	VMPath   string
+	Started  time.Time
+}
+
+func mountsPath(name string) string {
+	return filepath.Join(constants.GetMinipath(), "machines", name, mountsFile)
+}
+
+// LoadMounts returns the background mounts of the named machine whose processes are still running.
+func LoadMounts(name string) ([]Mount, error) {
+	data, err := ioutil.ReadFile(mountsPath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading mounts")
+	}
+	var recorded, mounts []Mount
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling mounts")
+	}
+	for _, m := range recorded {
+		if ProcessAlive(m.PID) {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts, nil
+}
+
+func writeMounts(name string, mounts []Mount) error {
+	if len(mounts) == 0 {
+		if err := os.Remove(mountsPath(name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "Error removing mounts")
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(mounts, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling mounts")
+	}
+	return errors.Wrap(ioutil.WriteFile(mountsPath(name), data, 0600), "Error writing mounts")
+}
+
+// RecordMount records the background mount m of the named machine, giving it the next free ID.
+func RecordMount(name string, m Mount) (Mount, error) {
+	mounts, err := LoadMounts(name)
+	if err != nil {
+		return m, err
+	}
+	m.ID = 1
+	for _, existing := range mounts {
+		if existing.ID >= m.ID {
+			m.ID = existing.ID + 1
+		}
+	}
+	return m, writeMounts(name, append(mounts, m))
+}
+
+// KillMounts kills the background mount of the named machine with the given ID, or all of
+// them for AllMounts, and returns the mounts it killed.
+func KillMounts(name, id string) ([]Mount, error) {
+	if id != AllMounts {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, errors.Errorf("Invalid mount %q, expected the ID of a mount or %q", id, AllMounts)
+		}
+	}
+	killed, err := killMounts(name, func(m Mount) bool {
+		return id == AllMounts || strconv.Itoa(m.ID) == id
+	})
+	if id != AllMounts && len(killed) == 0 && err == nil {
+		return nil, errors.Errorf("No background mount %s", id)
+	}
+	return killed, err
+}
+
+// KillMountsInto kills the background mounts of the named machine into vmPath, which a new
+// mount of that path replaces, and returns the mounts it killed.
+func KillMountsInto(name, vmPath string) ([]Mount, error) {
+	return killMounts(name, func(m Mount) bool {
+		return path.Clean(m.VMPath) == path.Clean(vmPath)
+	})
+}
+
+func killMounts(name string, selected func(Mount) bool) ([]Mount, error) {
+	mounts, err := LoadMounts(name)
+	if err != nil {
+		return nil, err
+	}
+	var kept, killed []Mount
+	var killErr error
+	for _, m := range mounts {
+		if !selected(m) {
+			kept = append(kept, m)
+			continue
+		}
+		p, err := os.FindProcess(m.PID)
+		if err == nil {
+			err = p.Kill()
+		}
+		if err != nil {
+			killErr = errors.Wrapf(err, "Error killing mount %d", m.ID)
+			kept = append(kept, m)
+			continue
+		}
+		killed = append(killed, m)
+	}
+	if err := writeMounts(name, kept); err != nil {
+		return killed, err
+	}
+	return killed, killErr
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// startMountProcess starts a process standing in for a background mount, and records it.
+func startMountProcess(t *testing.T, name string) (Mount, *exec.Cmd) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Error starting process: %s", err)
+	}
+	m, err := RecordMount(name, Mount{PID: cmd.Process.Pid, HostPath: "/home/user/src", VMPath: "/src", Started: time.Now()})
+	if err != nil {
+		t.Fatalf("Error recording mount: %s", err)
+	}
+	return m, cmd
+}
+
+func TestRecordMount(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("Error running process: %s", err)
+	}
+	if _, err := RecordMount(name, Mount{PID: exited.Process.Pid}); err != nil {
+		t.Fatalf("Error recording mount: %s", err)
+	}
+	first, cmd := startMountProcess(t, name)
+	defer cmd.Process.Kill()
+	second, cmd := startMountProcess(t, name)
+	defer cmd.Process.Kill()
+
+	// The exited process's mount is dropped, and its ID given to the next one.
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("Expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+	}
+	mounts, err := LoadMounts(name)
+	if err != nil {
+		t.Fatalf("Error loading mounts: %s", err)
+	}
+	if len(mounts) != 2 || mounts[0].PID != first.PID || mounts[1].PID != second.PID || mounts[1].VMPath != "/src" {
+		t.Errorf("Expected mounts %+v and %+v, got %+v", first, second, mounts)
+	}
+}
+
+func TestKillMounts(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+
+	first, firstCmd := startMountProcess(t, name)
+	defer firstCmd.Process.Kill()
+	second, secondCmd := startMountProcess(t, name)
+	defer secondCmd.Process.Kill()
+
+	for _, id := range []string{"3", "first"} {
+		if _, err := KillMounts(name, id); err == nil {
+			t.Errorf("Expected an error killing mount %q", id)
+		}
+	}
+
+	killed, err := KillMounts(name, "1")
+	if err != nil {
+		t.Fatalf("Error killing mount: %s", err)
+	}
+	if len(killed) != 1 || killed[0].ID != first.ID {
+		t.Errorf("Expected mount 1 killed, got %+v", killed)
+	}
+	if err := firstCmd.Wait(); err == nil {
+		t.Error("Expected the mount's process to be killed")
+	}
+	mounts, err := LoadMounts(name)
+	if err != nil {
+		t.Fatalf("Error loading mounts: %s", err)
+	}
+	if len(mounts) != 1 || mounts[0].ID != second.ID {
+		t.Errorf("Expected mount 2 left, got %+v", mounts)
+	}
+
+	if killed, err := KillMounts(name, AllMounts); err != nil || len(killed) != 1 {
+		t.Fatalf("Expected mount 2 killed, got %+v, %v", killed, err)
+	}
+	secondCmd.Wait()
+	if _, err := os.Stat(mountsPath(name)); !os.IsNotExist(err) {
+		t.Errorf("Expected the mounts file removed once no mount is left, got %v", err)
+	}
+	if killed, err := KillMounts(name, AllMounts); err != nil || len(killed) != 0 {
+		t.Errorf("Expected nothing to kill, got %+v, %v", killed, err)
+	}
+}
+
+func TestKillMountsInto(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+
+	_, firstCmd := startMountProcess(t, name)
+	defer firstCmd.Process.Kill()
+	_, secondCmd := startMountProcess(t, name)
+	defer secondCmd.Process.Kill()
+
+	if killed, err := KillMountsInto(name, "/other"); err != nil || len(killed) != 0 {
+		t.Errorf("Expected no mount into /other killed, got %+v, %v", killed, err)
+	}
+	if killed, err := KillMountsInto(name, "/src/"); err != nil || len(killed) != 2 {
+		t.Errorf("Expected both mounts into /src killed, got %+v, %v", killed, err)
+	}
+	firstCmd.Wait()
+	secondCmd.Wait()
+}
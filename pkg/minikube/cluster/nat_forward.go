@@ -0,0 +1,220 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+// natRulePrefix starts the names of the NAT rules minikube manages, which
+// leaves the rules added by docker-machine, such as ssh, alone.
+const natRulePrefix = "minikube-"
+
+// PortForward forwards a port on the host's loopback interface to a port of the VM through its NAT network.
+type PortForward struct {
+	HostPort  int
+	GuestPort int
+	Protocol  string
+}
+
+// ParsePortForward parses a port forward of the form <host port>:<guest port>[/<tcp|udp>].
+func ParsePortForward(s string) (PortForward, error) {
+	p := PortForward{Protocol: "tcp"}
+	ports := s
+	if i := strings.Index(s, "/"); i != -1 {
+		ports, p.Protocol = s[:i], s[i+1:]
+	}
+	if p.Protocol != "tcp" && p.Protocol != "udp" {
+		return p, fmt.Errorf("Invalid protocol %q in port forward %q, must be tcp or udp", p.Protocol, s)
+	}
+	parts := strings.Split(ports, ":")
+	if len(parts) != 2 {
+		return p, fmt.Errorf("Invalid port forward %q, must be <host port>:<guest port>[/<tcp|udp>]", s)
+	}
+	var err error
+	if p.HostPort, err = parsePort(parts[0]); err != nil {
+		return p, errors.Wrapf(err, "Invalid host port in port forward %q", s)
+	}
+	if p.GuestPort, err = parsePort(parts[1]); err != nil {
+		return p, errors.Wrapf(err, "Invalid guest port in port forward %q", s)
+	}
+	return p, nil
+}
+
+// ParsePortForwards parses the port forwards, each of which must use a different host port.
+func ParsePortForwards(specs []string) ([]PortForward, error) {
+	forwards := []PortForward{}
+	seen := map[string]bool{}
+	for _, s := range specs {
+		p, err := ParsePortForward(s)
+		if err != nil {
+			return nil, err
+		}
+		if seen[p.ruleName()] {
+			return nil, fmt.Errorf("Host port %d/%s is forwarded more than once", p.HostPort, p.Protocol)
+		}
+		seen[p.ruleName()] = true
+		forwards = append(forwards, p)
+	}
+	return forwards, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%d is out of range", port)
+	}
+	return port, nil
+}
+
+func (p PortForward) String() string {
+	return fmt.Sprintf("%d:%d/%s", p.HostPort, p.GuestPort, p.Protocol)
+}
+
+func (p PortForward) ruleName() string {
+	return fmt.Sprintf("%s%s-%d", natRulePrefix, p.Protocol, p.HostPort)
+}
+
+// rule is the port forward as VBoxManage natpf takes and lists it.
+func (p PortForward) rule() string {
+	return fmt.Sprintf("%s,%s,127.0.0.1,%d,,%d", p.ruleName(), p.Protocol, p.HostPort, p.GuestPort)
+}
+
+// ForwardedPort returns the host port the guest's TCP port is forwarded to.
+func ForwardedPort(forwards []PortForward, guestPort int) (int, bool) {
+	for _, p := range forwards {
+		if p.Protocol == "tcp" && p.GuestPort == guestPort {
+			return p.HostPort, true
+		}
+	}
+	return 0, false
+}
+
+// vboxManageRunner runs VBoxManage commands, returning their output.
+type vboxManageRunner interface {
+	Run(args ...string) (string, error)
+}
+
+type execVBoxManage struct{}
+
+func (execVBoxManage) Run(args ...string) (string, error) {
+	cmd := exec.Command(DetectVBoxManageCmd(), args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	line := "VBoxManage " + strings.Join(args, " ")
+	r := console.StartCommand("the host", line)
+	err := cmd.Run()
+	r.Done(append(stdout.Bytes(), stderr.Bytes()...), err)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error running %s: %s", line, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// vboxManage is the runner the NAT rules are set up with, replaced in tests.
+var vboxManage vboxManageRunner = execVBoxManage{}
+
+// parseNATRules returns the minikube NAT rules of the VM's first network adapter
+// in the output of VBoxManage showvminfo --machinereadable, by name.
+func parseNATRules(out string) map[string]string {
+	rules := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		// Forwarding(0)="minikube-tcp-8443,tcp,127.0.0.1,8443,,8443"
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "Forwarding(") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i == -1 {
+			continue
+		}
+		rule := strings.Trim(line[i+1:], `"`)
+		name := strings.SplitN(rule, ",", 2)[0]
+		if strings.HasPrefix(name, natRulePrefix) {
+			rules[name] = rule
+		}
+	}
+	return rules
+}
+
+// forwardPorts makes the NAT rules of a running virtualbox VM match the port forwards,
+// leaving rules which are already set up alone so starting again doesn't duplicate them.
+// Without port forwards the rules are left as they are. They are part of the VM, so
+// they are removed when it is deleted.
+func forwardPorts(h *host.Host, forwards []PortForward) error {
+	if len(forwards) == 0 || h.Driver.DriverName() != "virtualbox" {
+		return nil
+	}
+	return configureNATRules(vboxManage, h.Name, forwards)
+}
+
+func configureNATRules(vbm vboxManageRunner, machineName string, forwards []PortForward) error {
+	out, err := vbm.Run("showvminfo", machineName, "--machinereadable")
+	if err != nil {
+		return errors.Wrap(err, "Error getting NAT rules")
+	}
+	existing := parseNATRules(out)
+
+	wanted := map[string]string{}
+	for _, p := range forwards {
+		wanted[p.ruleName()] = p.rule()
+	}
+
+	// Sorting keeps the order of the commands run stable.
+	names := []string{}
+	for name := range existing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if wanted[name] == existing[name] {
+			continue
+		}
+		if _, err := vbm.Run("controlvm", machineName, "natpf1", "delete", name); err != nil {
+			return errors.Wrapf(err, "Error removing NAT rule %s", name)
+		}
+		delete(existing, name)
+	}
+
+	for _, p := range forwards {
+		if _, ok := existing[p.ruleName()]; ok {
+			continue
+		}
+		glog.Infof("Forwarding 127.0.0.1:%d on the host to port %d of the VM", p.HostPort, p.GuestPort)
+		if _, err := vbm.Run("controlvm", machineName, "natpf1", p.rule()); err != nil {
+			return errors.Wrapf(err, "Error adding NAT rule for port forward %s", p)
+		}
+		existing[p.ruleName()] = p.rule()
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePortForward(t *testing.T) {
+	var cases = []struct {
+		description string
+		spec        string
+		expected    PortForward
+		err         bool
+	}{
+		{
+			description: "tcp by default",
+			spec:        "8443:8443",
+			expected:    PortForward{HostPort: 8443, GuestPort: 8443, Protocol: "tcp"},
+		},
+		{
+			description: "udp",
+			spec:        "5353:53/udp",
+			expected:    PortForward{HostPort: 5353, GuestPort: 53, Protocol: "udp"},
+		},
+		{
+			description: "invalid protocol",
+			spec:        "8443:8443/sctp",
+			err:         true,
+		},
+		{
+			description: "missing guest port",
+			spec:        "8443",
+			err:         true,
+		},
+		{
+			description: "port out of range",
+			spec:        "8443:70000",
+			err:         true,
+		},
+		{
+			description: "port not a number",
+			spec:        "http:80",
+			err:         true,
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			actual, err := ParsePortForward(test.spec)
+			if err != nil && !test.err {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Fatalf("Expected an error parsing %q, got: %+v", test.spec, actual)
+			}
+			if !test.err && actual != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParsePortForwardsDuplicate(t *testing.T) {
+	if _, err := ParsePortForwards([]string{"8443:8443", "8443:443/tcp"}); err == nil {
+		t.Error("Expected an error forwarding the same host port twice")
+	}
+	forwards, err := ParsePortForwards([]string{"8443:8443", "8443:8443/udp"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if port, ok := ForwardedPort(forwards, 8443); !ok || port != 8443 {
+		t.Errorf("Expected the apiserver port to be forwarded from 8443, got: %d, %v", port, ok)
+	}
+	if _, ok := ForwardedPort(forwards, 53); ok {
+		t.Error("Expected port 53 not to be forwarded")
+	}
+}
+
+// mockVBoxManage returns canned output for showvminfo, and records the commands run.
+type mockVBoxManage struct {
+	vmInfo string
+	ran    []string
+}
+
+func (m *mockVBoxManage) Run(args ...string) (string, error) {
+	command := strings.Join(args, " ")
+	if args[0] == "showvminfo" {
+		return m.vmInfo, nil
+	}
+	if args[0] != "controlvm" {
+		return "", fmt.Errorf("unexpected command: %s", command)
+	}
+	m.ran = append(m.ran, command)
+	return "", nil
+}
+
+const vmInfoWithRules = `name="minikube"
+nic1="nat"
+Forwarding(0)="ssh,tcp,127.0.0.1,41234,,22"
+Forwarding(1)="minikube-tcp-8443,tcp,127.0.0.1,8443,,8443"
+Forwarding(2)="minikube-tcp-30000,tcp,127.0.0.1,30000,,31000"
+`
+
+func TestParseNATRules(t *testing.T) {
+	expected := map[string]string{
+		"minikube-tcp-8443":  "minikube-tcp-8443,tcp,127.0.0.1,8443,,8443",
+		"minikube-tcp-30000": "minikube-tcp-30000,tcp,127.0.0.1,30000,,31000",
+	}
+	if actual := parseNATRules(vmInfoWithRules); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestConfigureNATRules(t *testing.T) {
+	var cases = []struct {
+		description string
+		vmInfo      string
+		forwards    []PortForward
+		expected    []string
+	}{
+		{
+			description: "new rules",
+			vmInfo:      `Forwarding(0)="ssh,tcp,127.0.0.1,41234,,22"`,
+			forwards:    []PortForward{{HostPort: 8443, GuestPort: 8443, Protocol: "tcp"}},
+			expected:    []string{"controlvm minikube natpf1 minikube-tcp-8443,tcp,127.0.0.1,8443,,8443"},
+		},
+		{
+			description: "rules already set up",
+			vmInfo:      vmInfoWithRules,
+			forwards: []PortForward{
+				{HostPort: 8443, GuestPort: 8443, Protocol: "tcp"},
+				{HostPort: 30000, GuestPort: 31000, Protocol: "tcp"},
+			},
+		},
+		{
+			description: "changed and removed rules",
+			vmInfo:      vmInfoWithRules,
+			forwards:    []PortForward{{HostPort: 8443, GuestPort: 443, Protocol: "tcp"}},
+			expected: []string{
+				"controlvm minikube natpf1 delete minikube-tcp-30000",
+				"controlvm minikube natpf1 delete minikube-tcp-8443",
+				"controlvm minikube natpf1 minikube-tcp-8443,tcp,127.0.0.1,8443,,443",
+			},
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			vbm := &mockVBoxManage{vmInfo: test.vmInfo}
+			if err := configureNATRules(vbm, "minikube", test.forwards); err != nil {
+				t.Fatalf("Error configuring NAT rules: %s", err)
+			}
+			if !reflect.DeepEqual(vbm.ran, test.expected) {
+				t.Errorf("Expected commands %v, got %v", test.expected, vbm.ran)
+			}
+		})
+	}
+}
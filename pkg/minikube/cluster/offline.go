@@ -0,0 +1,85 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// ErrMissingArtifacts is returned when starting offline needs files which aren't cached.
+type ErrMissingArtifacts struct {
+	Artifacts []string
+}
+
+func (e ErrMissingArtifacts) Error() string {
+	return fmt.Sprintf("Unable to start offline, these files are missing:\n\t%s", strings.Join(e.Artifacts, "\n\t"))
+}
+
+// CheckOffline checks that everything starting the machine downloads is
+// available locally, returning ErrMissingArtifacts listing each of the files
+// which are missing. It doesn't use the network itself.
+func CheckOffline(api libmachine.API, config MachineConfig, kubernetesVersion string) error {
+	exists, err := api.Exists(cfg.GetMachineName())
+	if err != nil {
+		return errors.Wrapf(err, "Error checking if host exists: %s", cfg.GetMachineName())
+	}
+
+	var missing []string
+	// The ISO is only used to create the VM.
+	if !exists && config.VMDriver != "none" {
+		path := localPath(config.Downloader.GetISOFileURI(config.MinikubeISO))
+		if !fileExists(path) {
+			missing = append(missing, fmt.Sprintf("Minikube ISO %s, at %s", config.MinikubeISO, path))
+		}
+	}
+
+	k8sConfig := KubernetesConfig{KubernetesVersion: kubernetesVersion}
+	if localkubeURIWasSpecified(k8sConfig) {
+		l := localkubeCacher{k8sConfig}
+		path := l.getLocalkubeCacheFilepath()
+		if u, err := url.Parse(kubernetesVersion); err == nil && u.Scheme == fileScheme {
+			path = localPath(kubernetesVersion)
+		}
+		if !fileExists(path) {
+			missing = append(missing, fmt.Sprintf("localkube %s, at %s", kubernetesVersion, path))
+		}
+	}
+
+	if len(missing) > 0 {
+		return ErrMissingArtifacts{Artifacts: missing}
+	}
+	return nil
+}
+
+// localPath returns the path of a file URL.
+func localPath(fileURL string) string {
+	return filepath.FromSlash(strings.TrimPrefix(fileURL, "file://"))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
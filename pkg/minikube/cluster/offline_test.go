@@ -0,0 +1,169 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+	"k8s.io/minikube/pkg/util"
+)
+
+// failingTransport fails the test when an HTTP request is made.
+type failingTransport struct {
+	t *testing.T
+}
+
+func (f failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.t.Errorf("Unexpected HTTP request offline: %s %s", req.Method, req.URL)
+	return nil, http.ErrNotSupported
+}
+
+func TestCheckOffline(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+	defer func(rt http.RoundTripper) { http.DefaultTransport = rt }(http.DefaultTransport)
+	http.DefaultTransport = failingTransport{t}
+
+	isoURL := "https://storage.googleapis.com/minikube/iso/minikube-v0.18.0.iso"
+	isoPath := filepath.Join(tempDir, "cache", "iso", "minikube-v0.18.0.iso")
+	localkubeURL := "https://storage.googleapis.com/minikube/k8sReleases/v1.6.0/localkube-linux-amd64"
+	l := localkubeCacher{KubernetesConfig{KubernetesVersion: localkubeURL}}
+	localkubePath := l.getLocalkubeCacheFilepath()
+	localISO := filepath.Join(tempDir, "local.iso")
+
+	var cases = []struct {
+		description string
+		driver      string
+		iso         string
+		version     string
+		cached      []string
+		exists      bool
+		missing     []string
+	}{
+		{
+			description: "nothing cached",
+			iso:         isoURL,
+			version:     localkubeURL,
+			missing: []string{
+				"Minikube ISO " + isoURL + ", at " + isoPath,
+				"localkube " + localkubeURL + ", at " + localkubePath,
+			},
+		},
+		{
+			description: "everything cached",
+			iso:         isoURL,
+			version:     localkubeURL,
+			cached:      []string{isoPath, localkubePath},
+		},
+		{
+			description: "bundled localkube",
+			iso:         isoURL,
+			version:     constants.DefaultKubernetesVersion,
+			cached:      []string{isoPath},
+		},
+		{
+			description: "existing machine doesn't need the ISO",
+			iso:         isoURL,
+			version:     constants.DefaultKubernetesVersion,
+			exists:      true,
+		},
+		{
+			description: "none driver doesn't need the ISO",
+			driver:      "none",
+			iso:         isoURL,
+			version:     constants.DefaultKubernetesVersion,
+		},
+		{
+			description: "missing local files",
+			iso:         "file://" + filepath.ToSlash(localISO),
+			version:     "file://" + filepath.ToSlash(filepath.Join(tempDir, "localkube")),
+			missing: []string{
+				"Minikube ISO file://" + filepath.ToSlash(localISO) + ", at " + localISO,
+				"localkube file://" + filepath.ToSlash(filepath.Join(tempDir, "localkube")) + ", at " + filepath.Join(tempDir, "localkube"),
+			},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			for _, path := range test.cached {
+				if err := ioutil.WriteFile(path, []byte("cached"), 0644); err != nil {
+					t.Fatalf("Error writing %s: %s", path, err)
+				}
+				defer os.Remove(path)
+			}
+			api := tests.NewMockAPI()
+			if test.exists {
+				api.Hosts[config.GetMachineName()] = &host.Host{Name: config.GetMachineName()}
+			}
+			driver := test.driver
+			if driver == "" {
+				driver = "virtualbox"
+			}
+			c := MachineConfig{
+				VMDriver:    driver,
+				MinikubeISO: test.iso,
+				Downloader:  util.DefaultDownloader{Offline: true},
+				Offline:     true,
+			}
+
+			err := CheckOffline(api, c, test.version)
+			if len(test.missing) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error checking offline: %s", err)
+				}
+				return
+			}
+			e, ok := err.(ErrMissingArtifacts)
+			if !ok {
+				t.Fatalf("Expected ErrMissingArtifacts, got %v", err)
+			}
+			if !reflect.DeepEqual(e.Artifacts, test.missing) {
+				t.Errorf("Expected missing artifacts %q, got %q", test.missing, e.Artifacts)
+			}
+		})
+	}
+}
+
+func TestOfflineDownloads(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+	defer func(rt http.RoundTripper) { http.DefaultTransport = rt }(http.DefaultTransport)
+	http.DefaultTransport = failingTransport{t}
+
+	isoURL := "https://storage.googleapis.com/minikube/iso/minikube-v0.18.0.iso"
+	if err := (util.DefaultDownloader{Offline: true}).CacheMinikubeISOFromURL(isoURL); err == nil {
+		t.Error("Expected an error caching the ISO offline")
+	}
+
+	l := localkubeCacher{KubernetesConfig{
+		KubernetesVersion: "https://storage.googleapis.com/minikube/k8sReleases/v1.6.0/localkube-linux-amd64",
+		Offline:           true,
+	}}
+	if _, _, err := l.fetchLocalkubeFromURI(); err == nil {
+		t.Error("Expected an error fetching localkube offline")
+	}
+}
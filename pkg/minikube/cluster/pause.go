@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/util"
+)
+
+// How long unpausing waits for the apiserver to respond.
+const (
+	apiserverHealthAttempts = 30
+	apiserverHealthInterval = 2 * time.Second
+)
+
+// commandRunner runs a command on the host, returning its output.
+type commandRunner func(command string) (string, error)
+
+// PauseCluster freezes localkube and the cluster's containers, leaving the VM running.
+// Pausing a paused cluster does nothing.
+func PauseCluster(api libmachine.API) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return err
+	}
+	return pauseCluster(func(command string) (string, error) {
+		return RunCommand(h, command, false)
+	})
+}
+
+// UnpauseCluster continues localkube and then the cluster's containers, and waits for
+// the apiserver to respond. Unpausing a running cluster does nothing.
+func UnpauseCluster(api libmachine.API) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return err
+	}
+	return unpauseCluster(func(command string) (string, error) {
+		return RunCommand(h, command, false)
+	}, apiserverHealthInterval)
+}
+
+func localkubeState(run commandRunner) (string, error) {
+	out, err := run(localkubeStatusCommand)
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting localkube status")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func pauseCluster(run commandRunner) error {
+	s, err := localkubeState(run)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case state.Paused.String():
+		glog.Infoln("Cluster is already paused")
+		return nil
+	case state.Stopped.String():
+		return errors.New("Localkube is not running, there is no cluster to pause")
+	}
+	if _, err := run(pauseLocalkubeCommand); err != nil {
+		return errors.Wrap(err, "Error pausing localkube")
+	}
+	if _, err := run(pauseContainersCommand); err != nil {
+		return errors.Wrap(err, "Error pausing containers")
+	}
+	return nil
+}
+
+func unpauseCluster(run commandRunner, interval time.Duration) error {
+	s, err := localkubeState(run)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case state.Running.String():
+		glog.Infoln("Cluster is not paused")
+		return nil
+	case state.Stopped.String():
+		return errors.New("Localkube is not running, there is no cluster to unpause")
+	}
+	if _, err := run(unpauseLocalkubeCommand); err != nil {
+		return errors.Wrap(err, "Error unpausing localkube")
+	}
+	if _, err := run(unpauseContainersCommand); err != nil {
+		return errors.Wrap(err, "Error unpausing containers")
+	}
+	return util.RetryAfter(apiserverHealthAttempts, func() error {
+		out, err := run(apiserverHealthCommand)
+		if err != nil {
+			return &util.RetriableError{Err: errors.Wrap(err, "Error checking apiserver health")}
+		}
+		if strings.TrimSpace(out) != "ok" {
+			return &util.RetriableError{Err: errors.Errorf("Apiserver is not healthy yet: %s", out)}
+		}
+		return nil
+	}, interval)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeRunner answers commands with the given outputs, and records the commands run.
+type fakeRunner struct {
+	outputs map[string][]string
+	ran     []string
+}
+
+func (f *fakeRunner) run(command string) (string, error) {
+	f.ran = append(f.ran, command)
+	outs := f.outputs[command]
+	if len(outs) == 0 {
+		return "", nil
+	}
+	out := outs[0]
+	if len(outs) > 1 {
+		f.outputs[command] = outs[1:]
+	}
+	if out == "error" {
+		return "", errors.New("exit status 7")
+	}
+	return out, nil
+}
+
+func TestPauseCluster(t *testing.T) {
+	var cases = []struct {
+		description string
+		status      string
+		ran         []string
+		shouldErr   bool
+	}{
+		{
+			description: "running",
+			status:      "Running\n",
+			ran:         []string{localkubeStatusCommand, pauseLocalkubeCommand, pauseContainersCommand},
+		},
+		{
+			description: "already paused",
+			status:      "Paused\n",
+			ran:         []string{localkubeStatusCommand},
+		},
+		{
+			description: "stopped",
+			status:      "Stopped\n",
+			ran:         []string{localkubeStatusCommand},
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			f := &fakeRunner{outputs: map[string][]string{localkubeStatusCommand: {test.status}}}
+			err := pauseCluster(f.run)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error pausing cluster: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Error("Expected an error pausing cluster")
+			}
+			if !reflect.DeepEqual(f.ran, test.ran) {
+				t.Errorf("Expected commands %v, got %v", test.ran, f.ran)
+			}
+		})
+	}
+}
+
+func TestUnpauseCluster(t *testing.T) {
+	var cases = []struct {
+		description string
+		status      string
+		health      []string
+		ran         []string
+		shouldErr   bool
+	}{
+		{
+			description: "paused",
+			status:      "Paused\n",
+			health:      []string{"error", "error", "ok"},
+			ran: []string{localkubeStatusCommand, unpauseLocalkubeCommand, unpauseContainersCommand,
+				apiserverHealthCommand, apiserverHealthCommand, apiserverHealthCommand},
+		},
+		{
+			description: "not paused",
+			status:      "Running\n",
+			ran:         []string{localkubeStatusCommand},
+		},
+		{
+			description: "stopped",
+			status:      "Stopped\n",
+			ran:         []string{localkubeStatusCommand},
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			f := &fakeRunner{outputs: map[string][]string{
+				localkubeStatusCommand: {test.status},
+				apiserverHealthCommand: test.health,
+			}}
+			err := unpauseCluster(f.run, 0)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error unpausing cluster: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Error("Expected an error unpausing cluster")
+			}
+			if !reflect.DeepEqual(f.ran, test.ran) {
+				t.Errorf("Expected commands %v, got %v", test.ran, f.ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+)
+
+// ProxyConfig is the HTTP proxy the docker daemon in the VM pulls images through.
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// ProxyFromEnv reads the proxy settings from the environment, where programs
+// use either the upper or the lower case variables.
+func ProxyFromEnv(getenv func(string) string) ProxyConfig {
+	lookup := func(name string) string {
+		if v := getenv(name); v != "" {
+			return v
+		}
+		return getenv(strings.ToLower(name))
+	}
+	return ProxyConfig{
+		HTTPProxy:  lookup("HTTP_PROXY"),
+		HTTPSProxy: lookup("HTTPS_PROXY"),
+		NoProxy:    lookup("NO_PROXY"),
+	}
+}
+
+// IsSet returns whether a proxy is configured.
+func (p ProxyConfig) IsSet() bool {
+	return p.HTTPProxy != "" || p.HTTPSProxy != ""
+}
+
+// Bypasses returns whether host is listed in NoProxy.
+func (p ProxyConfig) Bypasses(host string) bool {
+	for _, h := range strings.Split(p.NoProxy, ",") {
+		if strings.TrimSpace(h) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// WithClusterNoProxy returns the config with the range of the service IPs added
+// to NoProxy, as they are never reached through the proxy. An empty range is localkube's default.
+func (p ProxyConfig) WithClusterNoProxy(serviceCIDR string) ProxyConfig {
+	if p.IsSet() {
+		p.NoProxy = AddNoProxy(p.NoProxy, KubernetesConfig{ServiceCIDR: serviceCIDR}.serviceCIDR())
+	}
+	return p
+}
+
+// AddNoProxy returns the NO_PROXY list with the hosts appended to it, leaving out those already in it.
+func AddNoProxy(noProxy string, hosts ...string) string {
+	entries := []string{}
+	seen := map[string]bool{}
+	for _, h := range append(strings.Split(noProxy, ","), hosts...) {
+		h = strings.TrimSpace(h)
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		entries = append(entries, h)
+	}
+	return strings.Join(entries, ",")
+}
+
+// DockerEnv returns the proxy settings as environment variables of the docker daemon.
+func (p ProxyConfig) DockerEnv() []string {
+	env := []string{}
+	for _, v := range []struct{ name, value string }{
+		{"HTTP_PROXY", p.HTTPProxy},
+		{"HTTPS_PROXY", p.HTTPSProxy},
+		{"NO_PROXY", p.NoProxy},
+	} {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
+}
+
+// LoopbackProxies returns the proxies which are on the host's loopback
+// interface, where the VM can't reach them.
+func (p ProxyConfig) LoopbackProxies() []string {
+	proxies := []string{}
+	for _, proxy := range []string{p.HTTPProxy, p.HTTPSProxy} {
+		if proxy != "" && isLoopbackProxy(proxy) {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+func isLoopbackProxy(proxy string) bool {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		// Proxies are often set without a scheme.
+		if u, err = url.Parse("http://" + proxy); err != nil {
+			return false
+		}
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// mergeDockerEnv appends the proxy variables to the docker env, unless it sets them already.
+func mergeDockerEnv(dockerEnv []string, proxy ProxyConfig) []string {
+	set := map[string]bool{}
+	for _, e := range dockerEnv {
+		set[strings.ToUpper(strings.SplitN(e, "=", 2)[0])] = true
+	}
+	env := append([]string{}, dockerEnv...)
+	for _, e := range proxy.DockerEnv() {
+		if !set[strings.SplitN(e, "=", 2)[0]] {
+			env = append(env, e)
+		}
+	}
+	return env
+}
+
+// addNoProxyToEnv adds the hosts to the NO_PROXY of the docker env, returning whether
+// it changed. An env without a proxy is left as it is.
+func addNoProxyToEnv(env []string, hosts ...string) ([]string, bool) {
+	proxied := false
+	noProxy := -1
+	for i, e := range env {
+		kv := strings.SplitN(e, "=", 2)
+		switch strings.ToUpper(kv[0]) {
+		case "HTTP_PROXY", "HTTPS_PROXY":
+			proxied = proxied || (len(kv) == 2 && kv[1] != "")
+		case "NO_PROXY":
+			noProxy = i
+		}
+	}
+	if !proxied {
+		return env, false
+	}
+	if noProxy < 0 {
+		return append(env, "NO_PROXY="+AddNoProxy("", hosts...)), true
+	}
+	kv := strings.SplitN(env[noProxy], "=", 2)
+	old := ""
+	if len(kv) == 2 {
+		old = kv[1]
+	}
+	updated := AddNoProxy(old, hosts...)
+	if updated == old {
+		return env, false
+	}
+	env = append([]string{}, env...)
+	env[noProxy] = kv[0] + "=" + updated
+	return env, true
+}
+
+// AddVMToNoProxy adds the IP of the started VM to the NO_PROXY of its docker daemon,
+// which can only be done once the VM has its IP. The daemon is provisioned again to apply it.
+func AddVMToNoProxy(api libmachine.API, h *host.Host, ip string) error {
+	if h.DriverName == "none" || h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return nil
+	}
+	env, changed := addNoProxyToEnv(h.HostOptions.EngineOptions.Env, ip)
+	if !changed {
+		return nil
+	}
+	h.HostOptions.EngineOptions.Env = env
+	if err := h.ConfigureAuth(); err != nil {
+		return errors.Wrap(err, "Error provisioning the docker daemon with the VM's NO_PROXY")
+	}
+	return errors.Wrap(api.Save(h), "Error saving host")
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// RegistryAddress returns the host:port the host pushes images to the registry addon at, its node port on the VM.
+func RegistryAddress(h *host.Host) (string, error) {
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting the IP of the VM")
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(constants.RegistryNodePort)), nil
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// The VM's Docker daemon trusts the CA of a registry at /etc/docker/certs.d/<registry>/ca.crt.
+// The marker lists the registries minikube put a CA there for, and the hashes of the
+// CAs, so an unchanged set isn't copied again and Docker isn't restarted for it.
+const (
+	dockerCertsDir         = "/etc/docker/certs.d"
+	systemCertsDir         = "/etc/ssl/certs"
+	registryCertsMarker    = dockerCertsDir + "/.minikube-registry-certs"
+	restartDockerCommand   = "sudo systemctl restart docker"
+	readRegistryCertsState = "cat " + registryCertsMarker + " 2>/dev/null || true"
+	// c_rehash links the certificates by hash for OpenSSL. Docker only needs certs.d,
+	// so the ISO lacking it isn't an error.
+	rehashSystemCertsCommand = "(command -v c_rehash >/dev/null && sudo c_rehash " + systemCertsDir + " >/dev/null) || true"
+)
+
+// registryName matches a registry host, with an optional port.
+var registryName = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?(:[0-9]+)?$`)
+
+// registryCertExtensions are the extensions of the files in the registry certs dir which are CAs.
+var registryCertExtensions = []string{".crt", ".pem"}
+
+// RegistryCert is the CA of a registry, which the VM's Docker daemon is made to trust.
+type RegistryCert struct {
+	Registry string
+	// PEM is the CA's certificates, without any text around them.
+	PEM []byte
+}
+
+// RegistryCertsDir is where the CAs of registries are kept, as <registry>.crt or <registry>.pem,
+// with the port of the registry, if any, after an underscore or a colon.
+func RegistryCertsDir() string {
+	return constants.MakeMiniPath("files", "certs")
+}
+
+// readRegistryCert reads the CA of registry from path, checking it holds PEM certificates.
+func readRegistryCert(registry, path string) (RegistryCert, error) {
+	if !registryName.MatchString(registry) {
+		return RegistryCert{}, fmt.Errorf("%q is not a registry host, such as registry.example.com:5000", registry)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return RegistryCert{}, errors.Wrapf(err, "Error reading the CA of %s", registry)
+	}
+	// Only the certificates are kept, leaving out any text around them.
+	var certs []byte
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, pem.EncodeToMemory(block)...)
+		}
+	}
+	if len(certs) == 0 {
+		return RegistryCert{}, fmt.Errorf("The CA of %s, %s, is not a PEM certificate", registry, path)
+	}
+	return RegistryCert{Registry: registry, PEM: certs}, nil
+}
+
+// LoadRegistryCerts reads the CAs in dir, sorted by registry. Files with other extensions are skipped.
+func LoadRegistryCerts(dir string) ([]RegistryCert, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing registry CAs")
+	}
+	var certs []RegistryCert
+	seen := map[string]string{}
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || !isRegistryCertExtension(ext) {
+			glog.Infof("Skipping %s in %s, which is not a .crt or .pem file", f.Name(), dir)
+			continue
+		}
+		registry := registryFromFileName(strings.TrimSuffix(f.Name(), ext))
+		if other, ok := seen[registry]; ok {
+			return nil, fmt.Errorf("Both %s and %s are CAs of %s in %s, remove one of them", other, f.Name(), registry, dir)
+		}
+		seen[registry] = f.Name()
+		cert, err := readRegistryCert(registry, filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	sort.Slice(certs, func(i, j int) bool { return certs[i].Registry < certs[j].Registry })
+	return certs, nil
+}
+
+// registryFileName is the name the CA of registry is kept under. Windows doesn't allow
+// colons in file names, so its port follows an underscore instead.
+func registryFileName(registry string) string {
+	return strings.Replace(registry, ":", "_", -1)
+}
+
+// registryFromFileName returns the registry of a CA kept under name.
+func registryFromFileName(name string) string {
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		return name[:i] + ":" + name[i+1:]
+	}
+	return name
+}
+
+func isRegistryCertExtension(ext string) bool {
+	for _, e := range registryCertExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRegistryCerts copies the CAs given as <registry>=<path> to the registry certs dir,
+// so later starts keep making the VM trust them.
+func AddRegistryCerts(flags []string) error {
+	for _, f := range flags {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("%q is not <registry>=<CA file>", f)
+		}
+		cert, err := readRegistryCert(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(RegistryCertsDir(), 0755); err != nil {
+			return errors.Wrap(err, "Error creating the registry CAs dir")
+		}
+		if err := ioutil.WriteFile(filepath.Join(RegistryCertsDir(), registryFileName(cert.Registry)+".crt"), cert.PEM, 0644); err != nil {
+			return errors.Wrapf(err, "Error saving the CA of %s", cert.Registry)
+		}
+	}
+	return nil
+}
+
+// registryCertTargets returns where the CA of registry is put in the VM: where Docker
+// looks for it, and the system trust store.
+func registryCertTargets(registry string) []string {
+	return []string{
+		path.Join(dockerCertsDir, registry, "ca.crt"),
+		fmt.Sprintf("%s/minikube-%s.pem", systemCertsDir, registryFileName(registry)),
+	}
+}
+
+// registryCertsState lists the registries and the hashes of their CAs, one per line.
+func registryCertsState(certs []RegistryCert) string {
+	var lines []string
+	for _, c := range certs {
+		lines = append(lines, fmt.Sprintf("%s %x\n", c.Registry, sha256.Sum256(c.PEM)))
+	}
+	return strings.Join(lines, "")
+}
+
+// writeFileCommand writes data, which mustn't contain single quotes, to the paths in the VM.
+func writeFileCommand(data string, paths ...string) string {
+	return fmt.Sprintf("printf '%%s' '%s' | sudo tee %s >/dev/null", data, strings.Join(paths, " "))
+}
+
+// syncRegistryCerts makes the VM trust the CAs of certs, and no longer trust the ones of
+// registries which were removed since the last sync. Docker is restarted to pick them up.
+// It returns whether the CAs changed; when they didn't, nothing is copied.
+func syncRegistryCerts(run commandRunner, certs []RegistryCert) (bool, error) {
+	old, err := run(readRegistryCertsState)
+	if err != nil {
+		return false, errors.Wrap(err, "Error reading the registry CAs in the VM")
+	}
+	state := registryCertsState(certs)
+	if old == state {
+		return false, nil
+	}
+
+	current := map[string]bool{}
+	for _, c := range certs {
+		current[c.Registry] = true
+	}
+	var commands []string
+	for _, line := range strings.Split(old, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || current[fields[0]] || !registryName.MatchString(fields[0]) {
+			continue
+		}
+		targets := registryCertTargets(fields[0])
+		commands = append(commands, fmt.Sprintf("sudo rm -rf %s %s", path.Dir(targets[0]), targets[1]))
+	}
+	for _, c := range certs {
+		targets := registryCertTargets(c.Registry)
+		commands = append(commands,
+			fmt.Sprintf("sudo mkdir -p %s", path.Dir(targets[0])),
+			writeFileCommand(string(c.PEM), targets...))
+	}
+	commands = append(commands, rehashSystemCertsCommand, writeFileCommand(state, registryCertsMarker), restartDockerCommand)
+
+	for _, cmd := range commands {
+		if _, err := run(cmd); err != nil {
+			return false, errors.Wrap(err, "Error copying the registry CAs into the VM")
+		}
+	}
+	return true, nil
+}
+
+// SyncRegistryCerts makes the VM's Docker daemon trust the CAs in the registry certs dir,
+// restarting it when they changed. It returns the registries when they changed.
+func SyncRegistryCerts(h *host.Host) ([]string, bool, error) {
+	certs, err := LoadRegistryCerts(RegistryCertsDir())
+	if err != nil {
+		return nil, false, err
+	}
+	changed, err := syncRegistryCerts(func(command string) (string, error) {
+		return RunCommand(h, command, false)
+	}, certs)
+	if err != nil {
+		return nil, false, err
+	}
+	var registries []string
+	for _, c := range certs {
+		registries = append(registries, c.Registry)
+	}
+	return registries, changed, nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/util"
+)
+
+// writeRegistryCA writes a CA to path, with text around it like openssl leaves.
+func writeRegistryCA(t *testing.T, path string) {
+	dir, err := ioutil.TempDir("", "registryca")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := util.GenerateCACert(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"), "Corp CA"); err != nil {
+		t.Fatalf("Error generating CA: %s", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatalf("Error reading CA: %s", err)
+	}
+	data = append([]byte("subject=/CN=Corp CA's root\n"), data...)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Error writing CA: %s", err)
+	}
+}
+
+func TestLoadRegistryCerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	writeRegistryCA(t, filepath.Join(dir, "registry.corp.example.com:5000.pem"))
+	writeRegistryCA(t, filepath.Join(dir, "docker.corp.example.com_443.crt"))
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("CAs of our registries"), 0644); err != nil {
+		t.Fatalf("Error writing README: %s", err)
+	}
+
+	certs, err := LoadRegistryCerts(dir)
+	if err != nil {
+		t.Fatalf("Error loading registry CAs: %s", err)
+	}
+	var registries []string
+	for _, c := range certs {
+		registries = append(registries, c.Registry)
+		if strings.Contains(string(c.PEM), "subject=") {
+			t.Errorf("Expected the text around the CA of %s to be left out, got %s", c.Registry, c.PEM)
+		}
+	}
+	if expected := []string{"docker.corp.example.com:443", "registry.corp.example.com:5000"}; !reflect.DeepEqual(registries, expected) {
+		t.Errorf("Expected the CAs of %v, got %v", expected, registries)
+	}
+
+	if certs, err := LoadRegistryCerts(filepath.Join(dir, "missing")); err != nil || len(certs) != 0 {
+		t.Errorf("Expected no CAs in a missing dir, got %v, %v", certs, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "bad.example.com.crt"), []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Error writing file: %s", err)
+	}
+	if _, err := LoadRegistryCerts(dir); err == nil {
+		t.Errorf("Expected an error for a file which is not a PEM certificate")
+	}
+}
+
+func TestAddRegistryCerts(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	ca := filepath.Join(tempDir, "corp-ca.pem")
+	writeRegistryCA(t, ca)
+
+	var cases = []struct {
+		description string
+		flag        string
+		shouldErr   bool
+	}{
+		{
+			description: "registry and CA",
+			flag:        "registry.corp.example.com:5000=" + ca,
+		},
+		{
+			description: "no registry",
+			flag:        ca,
+			shouldErr:   true,
+		},
+		{
+			description: "invalid registry",
+			flag:        "https://registry.corp.example.com=" + ca,
+			shouldErr:   true,
+		},
+		{
+			description: "missing CA",
+			flag:        "registry.corp.example.com=" + filepath.Join(tempDir, "missing.pem"),
+			shouldErr:   true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			err := AddRegistryCerts([]string{test.flag})
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	certs, err := LoadRegistryCerts(RegistryCertsDir())
+	if err != nil {
+		t.Fatalf("Error loading registry CAs: %s", err)
+	}
+	if len(certs) != 1 || certs[0].Registry != "registry.corp.example.com:5000" {
+		t.Errorf("Expected the CA of registry.corp.example.com:5000 to be kept, got %+v", certs)
+	}
+}
+
+func TestRegistryCertTargets(t *testing.T) {
+	expected := []string{
+		"/etc/docker/certs.d/registry.corp.example.com:5000/ca.crt",
+		"/etc/ssl/certs/minikube-registry.corp.example.com_5000.pem",
+	}
+	if targets := registryCertTargets("registry.corp.example.com:5000"); !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected %v, got %v", expected, targets)
+	}
+}
+
+func TestSyncRegistryCerts(t *testing.T) {
+	corp := RegistryCert{Registry: "registry.corp.example.com", PEM: []byte("-----BEGIN CERTIFICATE-----\nY29ycA==\n-----END CERTIFICATE-----\n")}
+	other := RegistryCert{Registry: "other.example.com:5000", PEM: []byte("-----BEGIN CERTIFICATE-----\nb3RoZXI=\n-----END CERTIFICATE-----\n")}
+	both := []RegistryCert{other, corp}
+
+	var cases = []struct {
+		description string
+		vmState     string
+		certs       []RegistryCert
+		changed     bool
+		removed     string
+	}{
+		{
+			description: "new VM",
+			certs:       both,
+			changed:     true,
+		},
+		{
+			description: "unchanged",
+			vmState:     registryCertsState(both),
+			certs:       both,
+		},
+		{
+			description: "CA replaced",
+			vmState:     registryCertsState([]RegistryCert{other, {Registry: corp.Registry, PEM: []byte("old")}}),
+			certs:       both,
+			changed:     true,
+		},
+		{
+			description: "registry removed",
+			vmState:     registryCertsState(both),
+			certs:       []RegistryCert{corp},
+			changed:     true,
+			removed:     "sudo rm -rf /etc/docker/certs.d/other.example.com:5000 /etc/ssl/certs/minikube-other.example.com_5000.pem",
+		},
+		{
+			description: "none",
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			f := &fakeRunner{outputs: map[string][]string{readRegistryCertsState: {test.vmState}}}
+			changed, err := syncRegistryCerts(f.run, test.certs)
+			if err != nil {
+				t.Fatalf("Error syncing registry CAs: %s", err)
+			}
+			if changed != test.changed {
+				t.Errorf("Expected changed to be %v, got %v", test.changed, changed)
+			}
+			if !test.changed {
+				if len(f.ran) != 1 {
+					t.Errorf("Expected only the registry CAs in the VM to be read, ran %v", f.ran)
+				}
+				return
+			}
+
+			ran := strings.Join(f.ran, "\n")
+			for _, c := range test.certs {
+				if expected := writeFileCommand(string(c.PEM), registryCertTargets(c.Registry)...); !strings.Contains(ran, expected) {
+					t.Errorf("Expected the CA of %s to be written with %q, ran:\n%s", c.Registry, expected, ran)
+				}
+			}
+			if test.removed != "" && !strings.Contains(ran, test.removed) {
+				t.Errorf("Expected %q to be run, ran:\n%s", test.removed, ran)
+			}
+			if test.removed == "" && strings.Contains(ran, "sudo rm -rf") {
+				t.Errorf("Expected no CAs to be removed, ran:\n%s", ran)
+			}
+			if expected := writeFileCommand(registryCertsState(test.certs), registryCertsMarker); !strings.Contains(ran, expected) {
+				t.Errorf("Expected the registry CAs to be recorded in the VM, ran:\n%s", ran)
+			}
+			if last := f.ran[len(f.ran)-1]; last != restartDockerCommand {
+				t.Errorf("Expected Docker to be restarted last, ran %q", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+// ErrResizeUnsupported is returned when resizing a VM whose driver can't change its resources.
+type ErrResizeUnsupported struct {
+	DriverName string
+}
+
+func (e ErrResizeUnsupported) Error() string {
+	return fmt.Sprintf(`The %s driver can't resize its VM. Run "minikube delete" and start again with the new --memory and --cpus instead.`, e.DriverName)
+}
+
+// ResizeHost changes the memory, in MB, and the number of CPUs of the host VM, stopping it
+// while they are changed and starting it again if it was running. Values of 0 are left as they are.
+// It returns whether the VM was restarted.
+func ResizeHost(api libmachine.API, memoryMB, cpus int, policy RetryPolicy) (bool, error) {
+	name := cfg.GetMachineName()
+	unlock, err := machine.LockMachine(api, name)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error locking machine: %s", name)
+	}
+	defer unlock()
+	h, err := api.Load(name)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error loading host: %s", name)
+	}
+	// Loaded through an RPC client, the driver is a client of its plugin, which does the resizing.
+	d, ok, err := machine.AsMutableDriver(h.Driver)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error resizing host: %s", name)
+	}
+	if !ok {
+		return false, ErrResizeUnsupported{DriverName: h.DriverName}
+	}
+
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return false, errors.Wrapf(err, "Error getting state for host: %s", name)
+	}
+	running := s == state.Running
+	if running {
+		glog.Infof("Stopping %s to resize it", name)
+		if err := retryDriverOp(h.Driver.DriverName(), "stop", policy, h.Stop); err != nil {
+			return false, errors.Wrapf(err, "Error stopping host: %s", name)
+		}
+	}
+
+	if err := d.Resize(memoryMB, cpus); err != nil {
+		return false, errors.Wrapf(err, "Error resizing host: %s", name)
+	}
+	// Saving stores the driver's new resources in the machine's config.json.
+	if err := api.Save(h); err != nil {
+		return false, errors.Wrapf(err, "Error saving resized host: %s", name)
+	}
+
+	if !running {
+		return false, nil
+	}
+	if err := retryDriverOp(h.Driver.DriverName(), "start", policy, h.Driver.Start); err != nil {
+		return false, errors.Wrapf(err, "Error starting resized host: %s", name)
+	}
+	return true, errors.Wrapf(api.Save(h), "Error saving started host: %s", name)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+// fileStoreAPI is a MockAPI which also saves hosts to a filestore, the way libmachine does.
+type fileStoreAPI struct {
+	*tests.MockAPI
+	store *persist.Filestore
+}
+
+func (api fileStoreAPI) Save(h *host.Host) error {
+	if err := api.store.Save(h); err != nil {
+		return err
+	}
+	return api.MockAPI.Save(h)
+}
+
+func resizableHost(api *tests.MockAPI, s state.State) *tests.ResizableDriver {
+	d := &tests.ResizableDriver{MockDriver: tests.MockDriver{CurrentState: s}, Memory: 2048, CPU: 2}
+	api.Hosts[config.GetMachineName()] = &host.Host{Name: config.GetMachineName(), DriverName: "virtualbox", Driver: d}
+	return d
+}
+
+func TestResizeHost(t *testing.T) {
+	var cases = []struct {
+		description string
+		state       state.State
+		restarted   bool
+	}{
+		{
+			description: "running",
+			state:       state.Running,
+			restarted:   true,
+		},
+		{
+			description: "stopped",
+			state:       state.Stopped,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			api := tests.NewMockAPI()
+			d := resizableHost(api, test.state)
+			restarted, err := ResizeHost(api, 4096, 4, RetryPolicy{})
+			if err != nil {
+				t.Fatalf("Error resizing host: %s", err)
+			}
+			if restarted != test.restarted {
+				t.Errorf("Expected restarted: %v, got %v", test.restarted, restarted)
+			}
+			if d.Memory != 4096 || d.CPU != 4 {
+				t.Errorf("Expected 4096 MB and 4 CPUs, got %d MB and %d CPUs", d.Memory, d.CPU)
+			}
+			if d.CurrentState != test.state {
+				t.Errorf("Expected the machine to be %s again, got %s", test.state, d.CurrentState)
+			}
+			if !api.SaveCalled {
+				t.Error("Expected the resized host to be saved")
+			}
+		})
+	}
+}
+
+func TestResizeHostUnsupported(t *testing.T) {
+	api := tests.NewMockAPI()
+	createHost(api, defaultMachineConfig)
+	if _, err := ResizeHost(api, 4096, 0, RetryPolicy{}); err == nil {
+		t.Fatal("Expected an error resizing a host whose driver can't be resized")
+	} else if _, ok := err.(ErrResizeUnsupported); !ok {
+		t.Fatalf("Expected ErrResizeUnsupported, got: %v", err)
+	}
+}
+
+func TestResizeHostConfigFile(t *testing.T) {
+	dir := tests.MakeTempDir()
+	defer os.RemoveAll(dir)
+
+	api := fileStoreAPI{MockAPI: tests.NewMockAPI(), store: persist.NewFilestore(dir, "", "")}
+	resizableHost(api.MockAPI, state.Running)
+	if _, err := ResizeHost(api, 8192, 0, RetryPolicy{}); err != nil {
+		t.Fatalf("Error resizing host: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "machines", config.GetMachineName(), "config.json"))
+	if err != nil {
+		t.Fatalf("Error reading config.json: %s", err)
+	}
+	var saved struct {
+		Driver struct {
+			Memory int
+			CPU    int
+		}
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Error decoding config.json: %s", err)
+	}
+	if saved.Driver.Memory != 8192 || saved.Driver.CPU != 2 {
+		t.Errorf("Expected config.json to have 8192 MB and 2 CPUs, got %d MB and %d CPUs", saved.Driver.Memory, saved.Driver.CPU)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+// RetryPolicy is how driver operations which fail with transient errors are retried.
+type RetryPolicy struct {
+	// Retries is how many times a failed operation is retried.
+	Retries int
+	// Backoff is the wait before the first retry, which doubles for each following one.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy retries failed driver operations a couple of times.
+var DefaultRetryPolicy = RetryPolicy{Retries: constants.DefaultMachineOpRetries, Backoff: constants.DefaultMachineOpBackoff}
+
+// retryDriverOp runs op, an operation of the named driver, retrying it while
+// it fails with errors the driver reports for transient failures.
+func retryDriverOp(driverName, op string, policy RetryPolicy, fn func() error) error {
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		glog.Infof("Running %s %s, attempt %d of %d", driverName, op, attempt, policy.Retries+1)
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt > policy.Retries || !machine.IsTransient(driverName, err) {
+			return err
+		}
+		glog.Warningf("%s %s failed, retrying in %s: %s", driverName, op, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
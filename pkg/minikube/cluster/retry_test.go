@@ -0,0 +1,164 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+var (
+	transientErr = errors.New("VBoxManage: error: The machine 'minikube' is already locked for a session (or being unlocked)")
+	vtxErr       = errors.New("VBoxManage: error: VT-x is not available (VERR_VMX_NO_VMX)\nVBoxManage: error: Details: code E_FAIL (0x80004005)")
+)
+
+func TestRetryStartHost(t *testing.T) {
+	var retryTests = []struct {
+		description string
+		failures    int
+		err         error
+		shouldErr   bool
+		attempts    int
+	}{
+		{
+			description: "no failure",
+			attempts:    1,
+		},
+		{
+			description: "transient failures",
+			failures:    2,
+			err:         transientErr,
+			attempts:    3,
+		},
+		{
+			description: "too many transient failures",
+			failures:    3,
+			err:         transientErr,
+			shouldErr:   true,
+			attempts:    3,
+		},
+		{
+			description: "permanent failure",
+			failures:    1,
+			err:         vtxErr,
+			shouldErr:   true,
+			attempts:    1,
+		},
+	}
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+	for _, test := range retryTests {
+		t.Run(test.description, func(t *testing.T) {
+			api := tests.NewMockAPI()
+			h, err := createHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error creating host: %s", err)
+			}
+			d := &tests.FlakyDriver{Name: "virtualbox", Failures: test.failures, Err: test.err}
+			d.CurrentState = state.Stopped
+			h.Driver = d
+
+			config := defaultMachineConfig
+			config.RetryPolicy = RetryPolicy{Retries: 2}
+			_, _, err = StartHost(context.Background(), api, config)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error starting host: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error starting host")
+			}
+			if d.Attempts != test.attempts {
+				t.Errorf("Expected %d attempts, got %d", test.attempts, d.Attempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopHost(t *testing.T) {
+	api := tests.NewMockAPI()
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %s", err)
+	}
+	d := &tests.FlakyDriver{Name: "virtualbox", Failures: 1, Err: transientErr}
+	d.CurrentState = state.Running
+	h.Driver = d
+
+	if _, err := StopHost(context.Background(), api, RetryPolicy{Retries: 1}, StopOptions{}); err != nil {
+		t.Fatalf("Unexpected error stopping host: %s", err)
+	}
+	if d.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", d.Attempts)
+	}
+	if s, _ := d.GetState(); s != state.Stopped {
+		t.Errorf("Machine not stopped. Currently in state: %s", s)
+	}
+}
+
+func TestRetryDriverOpBackoff(t *testing.T) {
+	var retryTests = []struct {
+		description string
+		policy      RetryPolicy
+		failures    int
+		minWait     time.Duration
+	}{
+		{
+			description: "no retry",
+			policy:      RetryPolicy{Retries: 2, Backoff: time.Hour},
+		},
+		{
+			description: "one retry",
+			policy:      RetryPolicy{Retries: 2, Backoff: 20 * time.Millisecond},
+			failures:    1,
+			minWait:     20 * time.Millisecond,
+		},
+		{
+			description: "doubled backoff",
+			policy:      RetryPolicy{Retries: 2, Backoff: 20 * time.Millisecond},
+			failures:    2,
+			minWait:     60 * time.Millisecond,
+		},
+	}
+	for _, test := range retryTests {
+		t.Run(test.description, func(t *testing.T) {
+			attempts := 0
+			start := time.Now()
+			err := retryDriverOp("virtualbox", "start", test.policy, func() error {
+				attempts++
+				if attempts <= test.failures {
+					return transientErr
+				}
+				return nil
+			})
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if attempts != test.failures+1 {
+				t.Errorf("Expected %d attempts, got %d", test.failures+1, attempts)
+			}
+			if elapsed < test.minWait || elapsed > test.minWait+time.Second {
+				t.Errorf("Expected the retries to wait %s, they took %s", test.minWait, elapsed)
+			}
+		})
+	}
+}
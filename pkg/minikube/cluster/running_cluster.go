@@ -0,0 +1,164 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	cfg "k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+)
+
+// apiserverHealthTimeout is how long the apiserver has to answer a health probe.
+const apiserverHealthTimeout = 2 * time.Second
+
+// ClusterIsCurrent returns whether the host is running a healthy cluster, started with
+// the given configs, so that starting it again would change nothing. Otherwise, it
+// returns why the cluster has to be started.
+func ClusterIsCurrent(api libmachine.API, config MachineConfig, k KubernetesConfig) (bool, string) {
+	name := cfg.GetMachineName()
+	s, err := machine.GetState(api, name)
+	if err != nil {
+		return false, fmt.Sprintf("the host state can't be read: %s", err)
+	}
+	if s != state.Running {
+		return false, fmt.Sprintf("the host is %s", s)
+	}
+	h, err := api.Load(name)
+	if err != nil {
+		return false, fmt.Sprintf("the host can't be loaded: %s", err)
+	}
+	changes, err := configChanges(h, config)
+	if err != nil {
+		return false, fmt.Sprintf("the host config can't be read: %s", err)
+	}
+	if len(changes) > 0 {
+		return false, "the host config changed"
+	}
+	last, err := LoadStartState(name)
+	if err != nil {
+		return false, fmt.Sprintf("the start state can't be read: %s", err)
+	}
+	if last.Failed() || last.Phase != PhaseAuthConfigured {
+		return false, fmt.Sprintf("the last start %s", last)
+	}
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return false, fmt.Sprintf("the host IP can't be read: %s", err)
+	}
+	if old, changed := APIServerIPChanged(ip); changed {
+		return false, fmt.Sprintf("the IP changed from %s to %s", old, ip)
+	}
+	k.NodeIP = ip
+	if fingerprint := kubernetesConfigFingerprint(k); fingerprint != last.KubernetesConfig {
+		return false, "the Kubernetes config changed"
+	}
+	if err := apiserverHealthz(h); err != nil {
+		return false, fmt.Sprintf("the apiserver is not healthy: %s", err)
+	}
+	return true, ""
+}
+
+// kubernetesConfigFingerprint identifies the configs localkube is started with the same way.
+func kubernetesConfigFingerprint(k KubernetesConfig) string {
+	// Neither changes what runs in the VM.
+	k.Offline = false
+	k.KubernetesChannel = ""
+	// The audit policy is copied into the VM, so editing it changes what runs there too.
+	data, err := json.Marshal(struct {
+		KubernetesConfig
+		AuditPolicyChecksum string `json:",omitempty"`
+	}{k, auditPolicyChecksum(k.AuditPolicy)})
+	if err != nil {
+		glog.Warningf("Error marshalling Kubernetes config: %s", err)
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// apiserverHealthz probes the health of the host's apiserver, replaced in tests.
+var apiserverHealthz = func(h *host.Host) error {
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return err
+	}
+	client, err := newAPIServerClient(apiserverHealthTimeout)
+	if err != nil {
+		return err
+	}
+	return probeHealthz(client, fmt.Sprintf("https://%s/healthz", net.JoinHostPort(ip, strconv.Itoa(constants.APIServerPort))))
+}
+
+// APIServerHealthy returns why the host's apiserver is not healthy, nil if it is.
+func APIServerHealthy(h *host.Host) error {
+	return apiserverHealthz(h)
+}
+
+// newAPIServerClient returns a client authenticating to the apiserver with the certs kubeconfig uses.
+func newAPIServerClient(timeout time.Duration) (*http.Client, error) {
+	cert, err := tls.LoadX509KeyPair(CertPath("apiserver.crt"), CertPath("apiserver.key"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading apiserver client cert")
+	}
+	ca, err := ioutil.ReadFile(CertPath("ca.crt"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading CA cert")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("Error parsing CA cert")
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool},
+		},
+	}, nil
+}
+
+// probeHealthz returns an error unless url answers ok.
+func probeHealthz(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "ok" {
+		return fmt.Errorf("%s answered %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestProbeHealthz(t *testing.T) {
+	var cases = []struct {
+		description string
+		handler     http.HandlerFunc
+		shouldErr   bool
+	}{
+		{
+			description: "healthy",
+			handler:     func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "ok") },
+		},
+		{
+			description: "unhealthy",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "[-]poststarthook/bootstrap-controller failed", http.StatusInternalServerError)
+			},
+			shouldErr: true,
+		},
+		{
+			description: "not answering",
+			handler:     func(w http.ResponseWriter, r *http.Request) { time.Sleep(300 * time.Millisecond) },
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+			client := &http.Client{Timeout: 100 * time.Millisecond}
+
+			err := probeHealthz(client, server.URL+"/healthz")
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error probing healthz: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Error("Expected an error probing healthz")
+			}
+		})
+	}
+}
+
+func TestClusterIsCurrent(t *testing.T) {
+	defer func(p func(*host.Host) error) { apiserverHealthz = p }(apiserverHealthz)
+	started := KubernetesConfig{KubernetesVersion: "v1.6.4", NodeIP: "127.0.0.1"}
+
+	var cases = []struct {
+		description string
+		state       state.State
+		ip          string
+		certIP      string
+		health      error
+		requested   KubernetesConfig
+		current     bool
+		reason      string
+	}{
+		{
+			description: "running and healthy",
+			state:       state.Running,
+			requested:   KubernetesConfig{KubernetesVersion: "v1.6.4", Offline: true},
+			current:     true,
+		},
+		{
+			description: "running and unhealthy",
+			state:       state.Running,
+			health:      errors.New("connection refused"),
+			requested:   KubernetesConfig{KubernetesVersion: "v1.6.4"},
+			reason:      "apiserver is not healthy",
+		},
+		{
+			description: "running another version",
+			state:       state.Running,
+			requested:   KubernetesConfig{KubernetesVersion: "v1.7.0"},
+			reason:      "Kubernetes config changed",
+		},
+		{
+			description: "running with other feature gates",
+			state:       state.Running,
+			requested:   KubernetesConfig{KubernetesVersion: "v1.6.4", FeatureGates: "AllAlpha=true"},
+			reason:      "Kubernetes config changed",
+		},
+		{
+			description: "running on another IP",
+			state:       state.Running,
+			ip:          "192.168.99.101",
+			certIP:      "192.168.99.100",
+			requested:   KubernetesConfig{KubernetesVersion: "v1.6.4"},
+			reason:      "IP changed from 192.168.99.100 to 192.168.99.101",
+		},
+		{
+			description: "stopped",
+			state:       state.Stopped,
+			requested:   KubernetesConfig{KubernetesVersion: "v1.6.4"},
+			reason:      "host is Stopped",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir := makeMachineDir(t)
+			defer os.RemoveAll(tempDir)
+			api := tests.NewMockAPI()
+			h, err := createHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error creating host: %s", err)
+			}
+			h.Driver = &tests.MockDriver{CurrentState: test.state, BaseDriver: drivers.BaseDriver{IPAddress: test.ip}}
+			if test.certIP != "" {
+				writeCert(t, constants.GetMinipath(), test.certIP)
+			}
+			recordStartState(config.GetMachineName(), PhaseAuthConfigured, nil)
+			recordKubernetesConfig(config.GetMachineName(), started)
+			apiserverHealthz = func(*host.Host) error { return test.health }
+
+			current, reason := ClusterIsCurrent(api, defaultMachineConfig, test.requested)
+			if current != test.current {
+				t.Errorf("Expected the cluster to be current: %t, got %t: %s", test.current, current, reason)
+			}
+			if !strings.Contains(reason, test.reason) {
+				t.Errorf("Expected the reason to contain %q, got %q", test.reason, reason)
+			}
+		})
+	}
+}
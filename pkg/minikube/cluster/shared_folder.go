@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SharedFolder is a host folder shared natively with the VM, and where it is mounted in the VM.
+type SharedFolder struct {
+	HostPath  string
+	GuestPath string
+}
+
+// ParseMountString parses a mount string of the form <host path>:<VM path>. The
+// host path may be a Windows path starting with a drive letter, such as C:\Users.
+func ParseMountString(s string) (SharedFolder, error) {
+	idx := strings.LastIndex(s, ":")
+	// A lone drive letter before the colon is part of a host path without a VM path.
+	if idx == -1 || idx == 1 {
+		return SharedFolder{}, fmt.Errorf("Mount string %q must be in the form <host path>:<VM path>", s)
+	}
+	f := SharedFolder{HostPath: s[:idx], GuestPath: s[idx+1:]}
+	if f.HostPath == "" {
+		return SharedFolder{}, fmt.Errorf("Mount string %q is missing the host path", s)
+	}
+	if !strings.HasPrefix(f.GuestPath, "/") {
+		return SharedFolder{}, fmt.Errorf("The VM path %q of mount string %q must be an absolute path", f.GuestPath, s)
+	}
+	return f, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import "testing"
+
+func TestParseMountString(t *testing.T) {
+	var cases = []struct {
+		description string
+		mountString string
+		expected    SharedFolder
+		err         bool
+	}{
+		{
+			description: "unix path",
+			mountString: "/home/minikube/src:/src",
+			expected:    SharedFolder{HostPath: "/home/minikube/src", GuestPath: "/src"},
+		},
+		{
+			description: "windows path",
+			mountString: `C:\Users\minikube:/minikube-host`,
+			expected:    SharedFolder{HostPath: `C:\Users\minikube`, GuestPath: "/minikube-host"},
+		},
+		{
+			description: "windows path with forward slashes",
+			mountString: "D:/src:/src",
+			expected:    SharedFolder{HostPath: "D:/src", GuestPath: "/src"},
+		},
+		{
+			description: "windows path without VM path",
+			mountString: `C:\Users\minikube`,
+			err:         true,
+		},
+		{
+			description: "windows drive without VM path",
+			mountString: "C:/src",
+			err:         true,
+		},
+		{
+			description: "no colon",
+			mountString: "/home/minikube/src",
+			err:         true,
+		},
+		{
+			description: "relative VM path",
+			mountString: "/home/minikube/src:src",
+			err:         true,
+		},
+		{
+			description: "missing host path",
+			mountString: ":/src",
+			err:         true,
+		},
+	}
+	for _, test := range cases {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			actual, err := ParseMountString(test.mountString)
+			if err != nil && !test.err {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Fatalf("Expected an error parsing %q, got: %+v", test.mountString, actual)
+			}
+			if actual != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
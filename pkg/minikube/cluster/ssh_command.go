@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	cryptossh "golang.org/x/crypto/ssh"
+
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// RunSSHCommand runs command on the running host without a PTY, streaming its standard output
+// and standard error to stdout and stderr, and returns its exit code.
+func RunSSHCommand(api libmachine.API, command string, stdout, stderr io.Writer) (int, error) {
+	host, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error checking if api exist and loading it")
+	}
+	currentState, err := host.Driver.GetState()
+	if err != nil {
+		return 0, errors.Wrap(err, "Error getting state of host")
+	}
+	if currentState != state.Running {
+		return 0, errors.Errorf("Error: Cannot run ssh command: Host %q is not running", cfg.GetMachineName())
+	}
+	client, err := host.CreateSSHClient()
+	if err != nil {
+		return 0, errors.Wrap(err, "Error creating ssh client")
+	}
+	return runSSHCommand(client, command, stdout, stderr)
+}
+
+func runSSHCommand(client ssh.Client, command string, stdout, stderr io.Writer) (int, error) {
+	outPipe, errPipe, err := client.Start(command)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error starting ssh command")
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(stdout, outPipe)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(stderr, errPipe)
+	}()
+	wg.Wait()
+
+	err = client.Wait()
+	if err == nil {
+		return 0, nil
+	}
+	if code, ok := exitCode(err); ok {
+		return code, nil
+	}
+	return 0, errors.Wrap(err, "Error running ssh command")
+}
+
+// exitCode returns the exit code of the remote command err reports, from the native client's
+// session or the external ssh binary, which exits with the remote command's code.
+func exitCode(err error) (int, bool) {
+	switch e := err.(type) {
+	case *cryptossh.ExitError:
+		return e.ExitStatus(), true
+	case *exec.ExitError:
+		if status, ok := e.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestRunSSHCommand(t *testing.T) {
+	ssh.SetDefaultClient(ssh.Native)
+	defer ssh.SetDefaultClient(ssh.External)
+
+	s, err := tests.NewSSHServer()
+	if err != nil {
+		t.Fatalf("Error creating ssh server: %s", err)
+	}
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	s.SetCommandToOutput(map[string]string{"docker ps -q": "4f3b2a1c\n"})
+	s.SetCommandToStderr(map[string]string{"ls /missing": "ls: /missing: No such file or directory\n"})
+	s.SetCommandToExitStatus(map[string]int{"ls /missing": 2})
+
+	api := tests.NewMockAPI()
+	d := &tests.MockDriver{
+		Port:         port,
+		CurrentState: state.Running,
+		BaseDriver:   drivers.BaseDriver{IPAddress: "127.0.0.1"},
+	}
+	api.Hosts[config.GetMachineName()] = &host.Host{Driver: d}
+
+	var cases = []struct {
+		description string
+		command     string
+		stdout      string
+		stderr      string
+		code        int
+	}{
+		{
+			description: "success",
+			command:     "docker ps -q",
+			stdout:      "4f3b2a1c\n",
+		},
+		{
+			description: "failure",
+			command:     "ls /missing",
+			stderr:      "ls: /missing: No such file or directory\n",
+			code:        2,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code, err := RunSSHCommand(api, test.command, &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("Error running ssh command: %s", err)
+			}
+			if code != test.code {
+				t.Errorf("Expected exit code %d, got %d", test.code, code)
+			}
+			if stdout.String() != test.stdout {
+				t.Errorf("Expected stdout %q, got %q", test.stdout, stdout.String())
+			}
+			if stderr.String() != test.stderr {
+				t.Errorf("Expected stderr %q, got %q", test.stderr, stderr.String())
+			}
+		})
+	}
+
+	d.CurrentState = state.Stopped
+	if _, err := RunSSHCommand(api, "docker ps -q", &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected an error running a command on a stopped host")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+
+	cfg "k8s.io/minikube/pkg/minikube/config"
+)
+
+// ErrMachineNotExist is returned for a machine which hasn't been created.
+var ErrMachineNotExist = errors.New("Machine does not exist")
+
+// SSHInfo holds what tools need to log into a machine with SSH.
+type SSHInfo struct {
+	User    string
+	Host    string
+	Port    int
+	KeyPath string
+}
+
+// String returns the user@host:port of the machine's SSH server.
+func (s SSHInfo) String() string {
+	return s.User + "@" + net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// GetSSHInfo returns the SSH coordinates of the machine, from its stored driver config,
+// or ErrMachineNotExist if it hasn't been created.
+func GetSSHInfo(api libmachine.API) (SSHInfo, error) {
+	h, err := loadSSHHost(api)
+	if err != nil {
+		return SSHInfo{}, err
+	}
+	ip, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return SSHInfo{}, errors.Wrap(err, "Error getting ssh host name")
+	}
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return SSHInfo{}, errors.Wrap(err, "Error getting ssh port")
+	}
+	return SSHInfo{User: h.Driver.GetSSHUsername(), Host: ip, Port: port, KeyPath: h.Driver.GetSSHKeyPath()}, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH identity key of the machine, or ErrMachineNotExist
+// if it hasn't been created. Unlike its host name, the key path is known while the VM is stopped.
+func GetSSHKeyPath(api libmachine.API) (string, error) {
+	h, err := loadSSHHost(api)
+	if err != nil {
+		return "", err
+	}
+	return h.Driver.GetSSHKeyPath(), nil
+}
+
+// loadSSHHost loads the machine, or returns ErrMachineNotExist if it hasn't been created.
+func loadSSHHost(api libmachine.API) (*host.Host, error) {
+	exists, err := api.Exists(cfg.GetMachineName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error checking that api exists for: %s", cfg.GetMachineName())
+	}
+	if !exists {
+		return nil, ErrMachineNotExist
+	}
+	h, err := api.Load(cfg.GetMachineName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading api for: %s", cfg.GetMachineName())
+	}
+	return h, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func TestGetSSHInfo(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	api, err := machine.NewAPIClient(machine.ClientTypeLocal)
+	if err != nil {
+		t.Fatalf("Error creating api client: %s", err)
+	}
+	defer api.Close()
+
+	// The machine's directory is created along with the machine.
+	dir := filepath.Join(constants.GetMinipath(), "machines", config.GetMachineName())
+	os.RemoveAll(dir)
+	if _, err := GetSSHInfo(api); err != ErrMachineNotExist {
+		t.Fatalf("Expected ErrMachineNotExist before the machine is created, got %v", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Error creating machine dir: %s", err)
+	}
+	hostConfig := fmt.Sprintf(`{"ConfigVersion": 3, "Name": %q, "DriverName": "virtualbox", "Driver": %s}`, config.GetMachineName(), tests.VBoxConfig)
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(hostConfig), 0600); err != nil {
+		t.Fatalf("Error writing machine config: %s", err)
+	}
+
+	info, err := GetSSHInfo(api)
+	if err != nil {
+		t.Fatalf("Error getting ssh info: %s", err)
+	}
+	expected := SSHInfo{User: "docker", Host: "127.0.0.1", Port: 33627, KeyPath: "/home/sundarp/.minikube/machines/minikube/id_rsa"}
+	if info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+	if s := info.String(); s != "docker@127.0.0.1:33627" {
+		t.Errorf("Expected docker@127.0.0.1:33627, got %s", s)
+	}
+}
+
+func TestGetSSHKeyPathStopped(t *testing.T) {
+	api := tests.NewMockAPI()
+	if _, err := GetSSHKeyPath(api); err != ErrMachineNotExist {
+		t.Fatalf("Expected ErrMachineNotExist before the machine is created, got %v", err)
+	}
+
+	// The host name of a stopped VM can't be resolved, while its key path is known.
+	d := &tests.MockDriver{CurrentState: state.Stopped, HostError: true}
+	d.SSHKeyPath = "/home/sundarp/.minikube/machines/minikube/id_rsa"
+	api.Hosts[config.GetMachineName()] = &host.Host{Name: config.GetMachineName(), DriverName: "virtualbox", Driver: d}
+	if _, err := GetSSHInfo(api); err == nil {
+		t.Fatalf("Expected an error getting the ssh info of a stopped VM")
+	}
+	path, err := GetSSHKeyPath(api)
+	if err != nil {
+		t.Fatalf("Error getting ssh key path: %s", err)
+	}
+	if path != d.SSHKeyPath {
+		t.Errorf("Expected %s, got %s", d.SSHKeyPath, path)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// StartPhase is a step of starting a host, in the order they are completed.
+type StartPhase int
+
+const (
+	PhaseNone StartPhase = iota
+	PhaseISOCached
+	PhaseHostCreated
+	PhaseHostRunning
+	PhaseAuthConfigured
+)
+
+var startPhaseNames = map[StartPhase]string{
+	PhaseNone:           "none",
+	PhaseISOCached:      "iso-cached",
+	PhaseHostCreated:    "host-created",
+	PhaseHostRunning:    "host-running",
+	PhaseAuthConfigured: "auth-configured",
+}
+
+func (p StartPhase) String() string {
+	if name, ok := startPhaseNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("StartPhase(%d)", int(p))
+}
+
+func (p StartPhase) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *StartPhase) UnmarshalText(text []byte) error {
+	for phase, name := range startPhaseNames {
+		if name == string(text) {
+			*p = phase
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown start phase: %s", text)
+}
+
+// startStateFile is the name of the file, in the machine directory, the start state is kept in.
+const startStateFile = "start-state.json"
+
+// StartState records how far the last start of a host got, and why it stopped.
+type StartState struct {
+	// Phase is the last phase that was completed.
+	Phase StartPhase
+	// Error is the error the following phase failed with, if any.
+	Error string `json:",omitempty"`
+	Time  time.Time
+	// KubernetesVersion is the version the cluster was last updated to, which keeps its localkube in the cache.
+	KubernetesVersion string `json:",omitempty"`
+	// KubernetesChannel is the release channel KubernetesVersion was resolved from, if any.
+	KubernetesChannel string `json:",omitempty"`
+	// RunningKubernetesVersion is the version the cluster was last started with.
+	RunningKubernetesVersion string `json:",omitempty"`
+	// KubernetesConfig is the fingerprint of the config localkube was last started with.
+	KubernetesConfig string `json:",omitempty"`
+	// APIServerSANs are the extra names and IPs the apiserver certificate was last generated with.
+	APIServerSANs APIServerSANs
+	// ServiceCIDR and PodCIDR are the ranges of the service and pod IPs the cluster was last started with.
+	ServiceCIDR string `json:",omitempty"`
+	PodCIDR     string `json:",omitempty"`
+	// ContainerRuntime is the container runtime the cluster was last started with.
+	ContainerRuntime string `json:",omitempty"`
+	// StaticManifests are the checksums of the static pod manifests synced into the VM, by name.
+	StaticManifests map[string]string `json:",omitempty"`
+	// LastStop is how the host was stopped, if it was since it was last started.
+	LastStop StopMethod `json:",omitempty"`
+	// MachineID is generated when the host is created, telling it apart from the hosts of the same name before it.
+	MachineID string `json:",omitempty"`
+}
+
+// Failed returns whether the last start of the host failed.
+func (s StartState) Failed() bool {
+	return s.Error != ""
+}
+
+func (s StartState) String() string {
+	if !s.Failed() {
+		return fmt.Sprintf("completed %s", s.Phase)
+	}
+	return fmt.Sprintf("failed after %s: %s", s.Phase, s.Error)
+}
+
+func startStatePath(name string) string {
+	return filepath.Join(constants.GetMinipath(), "machines", name, startStateFile)
+}
+
+// LoadStartState reads the start state of the named machine.
+// A zero StartState is returned if the machine has never been started.
+func LoadStartState(name string) (StartState, error) {
+	var s StartState
+	data, err := ioutil.ReadFile(startStatePath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, errors.Wrap(err, "Error reading start state")
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, errors.Wrap(err, "Error unmarshalling start state")
+	}
+	return s, nil
+}
+
+// recordStartState writes the start state of the named machine. It is only
+// written once the machine directory exists, so that it is never mistaken for
+// a machine, and removed along with it. Failing to record it doesn't fail the start.
+func recordStartState(name string, phase StartPhase, startErr error) {
+	dir := filepath.Dir(startStatePath(name))
+	if _, err := os.Stat(dir); err != nil {
+		glog.Infof("Not recording start state for %s, machine directory does not exist", name)
+		return
+	}
+	last, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s := StartState{Phase: phase, Time: time.Now(), KubernetesVersion: last.KubernetesVersion, KubernetesChannel: last.KubernetesChannel,
+		RunningKubernetesVersion: last.RunningKubernetesVersion, KubernetesConfig: last.KubernetesConfig,
+		APIServerSANs: last.APIServerSANs, ServiceCIDR: last.ServiceCIDR, PodCIDR: last.PodCIDR,
+		ContainerRuntime: last.ContainerRuntime, StaticManifests: last.StaticManifests, MachineID: last.MachineID}
+	if startErr != nil {
+		s.Error = startErr.Error()
+	}
+	writeStartState(name, s)
+}
+
+// recordKubernetesVersion records the Kubernetes version the named machine's cluster was
+// updated to, and the channel it was resolved from.
+func recordKubernetesVersion(name, version, channel string) {
+	if _, err := os.Stat(filepath.Dir(startStatePath(name))); err != nil {
+		glog.Infof("Not recording Kubernetes version for %s, machine directory does not exist", name)
+		return
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s.KubernetesVersion = version
+	s.KubernetesChannel = channel
+	writeStartState(name, s)
+}
+
+// recordStopMethod records how the named machine was stopped.
+func recordStopMethod(name string, method StopMethod) {
+	if _, err := os.Stat(filepath.Dir(startStatePath(name))); err != nil {
+		glog.Infof("Not recording how %s was stopped, machine directory does not exist", name)
+		return
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s.LastStop = method
+	writeStartState(name, s)
+}
+
+// recordStaticManifests records the static pod manifests synced into the named machine's VM.
+func recordStaticManifests(name string, manifests map[string]string) {
+	if _, err := os.Stat(filepath.Dir(startStatePath(name))); err != nil {
+		glog.Infof("Not recording the static manifests of %s, machine directory does not exist", name)
+		return
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s.StaticManifests = manifests
+	writeStartState(name, s)
+}
+
+// recordKubernetesConfig records the config the named machine's localkube was started with.
+func recordKubernetesConfig(name string, k KubernetesConfig) {
+	if _, err := os.Stat(filepath.Dir(startStatePath(name))); err != nil {
+		glog.Infof("Not recording Kubernetes config for %s, machine directory does not exist", name)
+		return
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s.KubernetesConfig = kubernetesConfigFingerprint(k)
+	s.RunningKubernetesVersion = k.KubernetesVersion
+	s.APIServerSANs = k.APIServerSANs
+	s.ServiceCIDR = k.serviceCIDR()
+	s.PodCIDR = k.podCIDR()
+	s.ContainerRuntime = k.ContainerRuntime
+	if s.ContainerRuntime == "" {
+		s.ContainerRuntime = cruntime.Docker
+	}
+	writeStartState(name, s)
+}
+
+// recordMachineID records a new ID for the named machine, which was just created, and returns it.
+// "" is returned if the machine directory doesn't exist.
+func recordMachineID(name string) string {
+	if _, err := os.Stat(filepath.Dir(startStatePath(name))); err != nil {
+		glog.Infof("Not recording the ID of %s, machine directory does not exist", name)
+		return ""
+	}
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	s.MachineID = uuid.New()
+	writeStartState(name, s)
+	return s.MachineID
+}
+
+// ensureMachineID returns the ID of the named machine, recording one for a machine created
+// before they were.
+func ensureMachineID(name string) string {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Ignoring start state of %s: %s", name, err)
+	}
+	if s.MachineID != "" {
+		return s.MachineID
+	}
+	return recordMachineID(name)
+}
+
+func writeStartState(name string, s StartState) {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		glog.Warningf("Error marshalling start state: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(startStatePath(name), data, 0600); err != nil {
+		glog.Warningf("Error writing start state: %s", err)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+// makeMachineDir points the minikube home at a new temp dir, and creates the machine
+// directory in it, as libmachine does when it saves a host.
+func makeMachineDir(t *testing.T) string {
+	tempDir, err := ioutil.TempDir("", "minipath")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	os.Setenv(constants.MinikubeHome, tempDir)
+	if err := os.MkdirAll(filepath.Join(constants.GetMinipath(), "machines", config.GetMachineName()), 0700); err != nil {
+		t.Fatalf("Error creating machine dir: %s", err)
+	}
+	return tempDir
+}
+
+// savedHost returns a host as it is saved before its VM is created.
+func savedHost(t *testing.T, api *tests.MockAPI, d *tests.MockDriver) *host.Host {
+	h, err := newHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %v", err)
+	}
+	h.HostOptions.EngineOptions = engineOptions(defaultMachineConfig)
+	h.Driver = d
+	api.Hosts[h.Name] = h
+	return h
+}
+
+func TestStartHostRecordsFailedPhase(t *testing.T) {
+	var phaseTests = []struct {
+		description string
+		setup       func(t *testing.T, api *tests.MockAPI, p *tests.MockProvisioner)
+		phase       StartPhase
+	}{
+		{
+			description: "creating host fails",
+			setup: func(t *testing.T, api *tests.MockAPI, p *tests.MockProvisioner) {
+				api.CreateError = true
+			},
+			phase: PhaseISOCached,
+		},
+		{
+			description: "starting host fails",
+			setup: func(t *testing.T, api *tests.MockAPI, p *tests.MockProvisioner) {
+				savedHost(t, api, &tests.MockDriver{CurrentState: state.Stopped, StartError: errors.New("VBoxManage: error: The machine is locked")})
+			},
+			phase: PhaseHostCreated,
+		},
+		{
+			description: "getting host state fails",
+			setup: func(t *testing.T, api *tests.MockAPI, p *tests.MockProvisioner) {
+				savedHost(t, api, &tests.MockDriver{StateError: errors.New("VBoxManage not found")})
+			},
+			phase: PhaseHostCreated,
+		},
+		{
+			description: "configuring auth fails",
+			setup: func(t *testing.T, api *tests.MockAPI, p *tests.MockProvisioner) {
+				savedHost(t, api, &tests.MockDriver{CurrentState: state.Running})
+				p.ProvisionError = errors.New("ssh: handshake failed")
+			},
+			phase: PhaseHostRunning,
+		},
+	}
+
+	for _, test := range phaseTests {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir := makeMachineDir(t)
+			defer os.RemoveAll(tempDir)
+
+			api := tests.NewMockAPI()
+			p := &tests.MockProvisioner{}
+			provision.SetDetector(&tests.MockDetector{Provisioner: p})
+			test.setup(t, api, p)
+
+			if _, _, err := StartHost(context.Background(), api, defaultMachineConfig); err == nil {
+				t.Fatal("Expected an error starting host")
+			}
+			s, err := LoadStartState(config.GetMachineName())
+			if err != nil {
+				t.Fatalf("Error loading start state: %s", err)
+			}
+			if !s.Failed() {
+				t.Fatalf("Expected start state to record the failure, got: %s", s)
+			}
+			if s.Phase != test.phase {
+				t.Errorf("Expected last completed phase %s, got: %s", test.phase, s.Phase)
+			}
+		})
+	}
+}
+
+func TestStartHostResumesFailedCreate(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	p := &tests.MockProvisioner{}
+	provision.SetDetector(&tests.MockDetector{Provisioner: p})
+
+	api.CreateError = true
+	if _, _, err := StartHost(context.Background(), api, defaultMachineConfig); err == nil {
+		t.Fatal("Expected an error creating host")
+	}
+
+	// libmachine saves the host before creating its VM, so it's there on the next start.
+	api.CreateError = false
+	saved := savedHost(t, api, &tests.MockDriver{CurrentState: state.Stopped})
+	h, _, err := StartHost(context.Background(), api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error resuming host start: %s", err)
+	}
+	if h != saved {
+		t.Error("Expected the saved host to be resumed, not recreated")
+	}
+	if s, _ := h.Driver.GetState(); s != state.Running {
+		t.Errorf("Resumed machine is not running. Currently in state: %s", s)
+	}
+	if !p.Provisioned {
+		t.Error("Expected the resumed host to be provisioned")
+	}
+
+	s, err := LoadStartState(config.GetMachineName())
+	if err != nil {
+		t.Fatalf("Error loading start state: %s", err)
+	}
+	if s.Failed() || s.Phase != PhaseAuthConfigured {
+		t.Errorf("Expected start state to record a completed start, got: %s", s)
+	}
+}
+
+func TestStartHostRecreatesMissingFailedCreate(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+
+	api.CreateError = true
+	if _, _, err := StartHost(context.Background(), api, defaultMachineConfig); err == nil {
+		t.Fatal("Expected an error creating host")
+	}
+
+	// The VM was never created, so resuming can't find it.
+	api.CreateError = false
+	saved := savedHost(t, api, &tests.MockDriver{StateError: errors.New("machine does not exist")})
+	h, _, err := StartHost(context.Background(), api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error recreating host: %s", err)
+	}
+	if h == saved {
+		t.Error("Expected the host to be recreated")
+	}
+	if s, _ := h.Driver.GetState(); s != state.Running {
+		t.Errorf("Recreated machine is not running. Currently in state: %s", s)
+	}
+}
+
+func TestLoadStartStateMissing(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	s, err := LoadStartState("nonexistent")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if s.Failed() || s.Phase != PhaseNone {
+		t.Errorf("Expected an empty start state, got: %s", s)
+	}
+}
+
+func TestStartStateKeepsKubernetesVersion(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	name := config.GetMachineName()
+	recordKubernetesVersion(name, "v1.6.4", "stable")
+	recordStartState(name, PhaseHostRunning, errors.New("SSH timed out"))
+	s, err := LoadStartState(name)
+	if err != nil {
+		t.Fatalf("Error loading start state: %s", err)
+	}
+	if s.KubernetesVersion != "v1.6.4" || s.KubernetesChannel != "stable" || s.Phase != PhaseHostRunning || !s.Failed() {
+		t.Errorf("Expected the failed start to keep the Kubernetes version, got %+v", s)
+	}
+}
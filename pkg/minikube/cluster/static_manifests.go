@@ -0,0 +1,233 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+// staticManifestsPath is where the kubelet in localkube reads the manifests of its static pods from.
+const staticManifestsPath = "/etc/kubernetes/manifests"
+
+// staticManifestsFilesDir is where static pod manifests go under the files dir, which are synced
+// as static manifests rather than as files.
+var staticManifestsFilesDir = filepath.Join("etc", "kubernetes", "manifests")
+
+// staticPodLogs matches the lines the kubelet logs about the static pods it couldn't read or admit.
+const staticPodLogs = `manifest|static pod|mirror pod|admit|admission`
+
+// staticManifest is a static pod manifest on the host, and the checksum of its contents.
+type staticManifest struct {
+	Source   string
+	Name     string
+	Checksum string
+}
+
+// StaticManifestsDirs returns the directories holding the static pod manifests to sync into
+// the VM: the one in the files dir, and dir, if it is set.
+func StaticManifestsDirs(dir string) []string {
+	dirs := []string{filepath.Join(FilesDir(), staticManifestsFilesDir)}
+	if dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ValidateStaticManifest checks that data is the manifest of a single pod, which the kubelet can run as a static pod.
+func ValidateStaticManifest(data []byte) error {
+	var pod v1.Pod
+	if err := yaml.Unmarshal(data, &pod); err != nil {
+		return errors.Wrap(err, "Error parsing the manifest")
+	}
+	if pod.Kind == "" {
+		return errors.New("The manifest has no kind, static manifests must each be a Pod")
+	}
+	if pod.Kind != "Pod" {
+		return fmt.Errorf("The manifest is a %q, static manifests must each be a Pod", pod.Kind)
+	}
+	if pod.APIVersion != "v1" {
+		return fmt.Errorf("The pod has apiVersion %q, it must be v1", pod.APIVersion)
+	}
+	if pod.Name == "" {
+		return errors.New("The pod has no metadata.name")
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return fmt.Errorf("The pod %s has no containers", pod.Name)
+	}
+	return nil
+}
+
+// readStaticManifests returns the static pod manifests in dirs, checking each of them. The kubelet
+// skips files whose names start with a dot, and so are they. Manifests of several dirs can't
+// have the same name, as they are synced into the same dir of the VM.
+func readStaticManifests(dirs []string) ([]staticManifest, error) {
+	var manifests []staticManifest
+	names := map[string]string{}
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "Error reading the static manifests")
+		}
+		for _, f := range files {
+			if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+				continue
+			}
+			source := filepath.Join(dir, f.Name())
+			if other, ok := names[f.Name()]; ok {
+				return nil, fmt.Errorf("The static manifests %s and %s have the same name", other, source)
+			}
+			names[f.Name()] = source
+			data, err := ioutil.ReadFile(source)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error reading the static manifest")
+			}
+			if err := ValidateStaticManifest(data); err != nil {
+				return nil, errors.Wrapf(err, "Invalid static manifest %s", source)
+			}
+			manifests = append(manifests, staticManifest{Source: source, Name: f.Name(), Checksum: fmt.Sprintf("%x", sha256.Sum256(data))})
+		}
+	}
+	return manifests, nil
+}
+
+// ValidateStaticManifests checks the static pod manifests in dirs.
+func ValidateStaticManifests(dirs []string) error {
+	_, err := readStaticManifests(dirs)
+	return err
+}
+
+// parseChecksums parses the output of sha256sum into the checksums of the files, by name.
+func parseChecksums(out string) map[string]string {
+	checksums := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			checksums[fields[1]] = fields[0]
+		}
+	}
+	return checksums
+}
+
+// staticManifestChanges returns the manifests which differ from those in the VM, by their checksums,
+// and the names of the synced ones which were removed since, but are still in the VM. The others
+// in the VM, such as the addon manager's, aren't minikube's to remove.
+func staticManifestChanges(manifests []staticManifest, inVM, synced map[string]string) ([]staticManifest, []string) {
+	var changed []staticManifest
+	current := map[string]bool{}
+	for _, m := range manifests {
+		current[m.Name] = true
+		if inVM[m.Name] != m.Checksum {
+			changed = append(changed, m)
+		}
+	}
+	var removed []string
+	for name := range synced {
+		if _, ok := inVM[name]; ok && !current[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// SyncStaticManifests copies the static pod manifests in dirs which differ from those in the VM into
+// it, where the kubelet runs them, and removes those synced before which were removed from dirs
+// since. The VM's /etc doesn't survive it restarting, so they are compared with the VM's copies.
+// It returns whether it changed any. The none driver doesn't sync any.
+func SyncStaticManifests(h *host.Host, dirs []string) (bool, error) {
+	if h.Driver.DriverName() == "none" {
+		return false, nil
+	}
+	manifests, err := readStaticManifests(dirs)
+	if err != nil {
+		return false, err
+	}
+	name := config.GetMachineName()
+	last, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not removing the static manifests synced into %s before: %s", name, err)
+	}
+	out, err := RunCommand(h, vmManifestChecksumsCommand, false)
+	if err != nil {
+		return false, errors.Wrap(err, "Error getting the checksums of the static manifests in the VM")
+	}
+	changed, removed := staticManifestChanges(manifests, parseChecksums(out), last.StaticManifests)
+
+	synced := map[string]string{}
+	for _, m := range manifests {
+		synced[m.Name] = m.Checksum
+	}
+	for _, r := range removed {
+		synced[r] = last.StaticManifests[r]
+	}
+	// The ones which failed to be removed are kept, for the next start to remove them.
+	defer recordStaticManifests(name, synced)
+	if len(changed) == 0 && len(removed) == 0 {
+		return false, nil
+	}
+
+	if len(changed) > 0 {
+		client, err := sshutil.NewSSHClient(h.Driver)
+		if err != nil {
+			return false, errors.Wrap(err, "Error creating new ssh client")
+		}
+		defer client.Close()
+		for _, m := range changed {
+			glog.Infof("Copying the static manifest %s into the VM", m.Source)
+			asset, err := assets.NewFileAsset(m.Source, staticManifestsPath, m.Name, "0644")
+			if err != nil {
+				return false, err
+			}
+			if err := sshutil.TransferFile(asset, client); err != nil {
+				return false, errors.Wrapf(err, "Error copying the static manifest %s into the VM", m.Source)
+			}
+		}
+	}
+	for _, r := range removed {
+		glog.Infof("Removing the static manifest %s from the VM", r)
+		if _, err := RunCommand(h, "sudo rm -f "+path.Join(staticManifestsPath, r), false); err != nil {
+			return false, errors.Wrapf(err, "Error removing the static manifest %s from the VM", r)
+		}
+		delete(synced, r)
+	}
+	return true, nil
+}
+
+// vmManifestChecksumsCommand prints the checksums of the static manifests in the VM.
+var vmManifestChecksumsCommand = fmt.Sprintf("cd %s 2>/dev/null && sudo sha256sum -- * 2>/dev/null || true", staticManifestsPath)
+
+// staticPodLogsCommand prints the kubelet's lines about the static pods it couldn't read or admit.
+var staticPodLogsCommand = fmt.Sprintf("sudo journalctl --no-pager -u localkube | grep -iE '%s' || true", staticPodLogs)
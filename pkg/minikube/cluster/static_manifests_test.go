@@ -0,0 +1,237 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const staticPodManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: etcd-exporter
+  namespace: kube-system
+spec:
+  containers:
+  - name: exporter
+    image: example.com/etcd-exporter:v1
+`
+
+func TestValidateStaticManifest(t *testing.T) {
+	var cases = []struct {
+		description string
+		manifest    string
+		shouldErr   bool
+		errorMsg    string
+	}{
+		{
+			description: "pod",
+			manifest:    staticPodManifest,
+		},
+		{
+			description: "json pod",
+			manifest:    `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web"}, "spec": {"containers": [{"name": "web", "image": "nginx"}]}}`,
+		},
+		{
+			description: "deployment",
+			manifest:    strings.Replace(strings.Replace(staticPodManifest, "kind: Pod", "kind: Deployment", 1), "apiVersion: v1", "apiVersion: extensions/v1beta1", 1),
+			shouldErr:   true,
+			errorMsg:    `"Deployment"`,
+		},
+		{
+			description: "service",
+			manifest:    "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+			shouldErr:   true,
+			errorMsg:    `"Service"`,
+		},
+		{
+			description: "pod list",
+			manifest:    "apiVersion: v1\nkind: PodList\nitems: []\n",
+			shouldErr:   true,
+			errorMsg:    `"PodList"`,
+		},
+		{
+			description: "no kind",
+			manifest:    strings.Replace(staticPodManifest, "kind: Pod\n", "", 1),
+			shouldErr:   true,
+			errorMsg:    "must each be a Pod",
+		},
+		{
+			description: "other api version",
+			manifest:    strings.Replace(staticPodManifest, "apiVersion: v1", "apiVersion: v2", 1),
+			shouldErr:   true,
+			errorMsg:    "apiVersion",
+		},
+		{
+			description: "no name",
+			manifest:    strings.Replace(staticPodManifest, "  name: etcd-exporter\n", "", 1),
+			shouldErr:   true,
+			errorMsg:    "metadata.name",
+		},
+		{
+			description: "no containers",
+			manifest:    "apiVersion: v1\nkind: Pod\nmetadata:\n  name: empty\nspec: {}\n",
+			shouldErr:   true,
+			errorMsg:    "no containers",
+		},
+		{
+			description: "not yaml",
+			manifest:    "kind: [Pod",
+			shouldErr:   true,
+			errorMsg:    "Error parsing",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateStaticManifest([]byte(test.manifest))
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Expected error: %t, got %v", test.shouldErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), test.errorMsg) {
+				t.Errorf("Expected the error to contain %q, got %q", test.errorMsg, err)
+			}
+		})
+	}
+}
+
+func writeManifests(t *testing.T, dir string, manifests map[string]string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Error creating %s: %s", dir, err)
+	}
+	for name, data := range manifests {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Error writing %s: %s", name, err)
+		}
+	}
+}
+
+func TestReadStaticManifests(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+	files := filepath.Join(tempDir, "files")
+	flag := filepath.Join(tempDir, "flag")
+	writeManifests(t, files, map[string]string{"exporter.yaml": staticPodManifest, ".exporter.yaml.swp": "not a manifest"})
+	writeManifests(t, flag, map[string]string{"web.json": strings.Replace(staticPodManifest, "etcd-exporter", "web", 1)})
+
+	manifests, err := readStaticManifests([]string{files, flag, filepath.Join(tempDir, "missing")})
+	if err != nil {
+		t.Fatalf("Error reading the static manifests: %s", err)
+	}
+	expected := []staticManifest{
+		{Source: filepath.Join(files, "exporter.yaml"), Name: "exporter.yaml", Checksum: fmt.Sprintf("%x", sha256.Sum256([]byte(staticPodManifest)))},
+		{Source: filepath.Join(flag, "web.json"), Name: "web.json", Checksum: fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Replace(staticPodManifest, "etcd-exporter", "web", 1))))},
+	}
+	if !reflect.DeepEqual(manifests, expected) {
+		t.Errorf("Expected the static manifests %+v, got %+v", expected, manifests)
+	}
+
+	writeManifests(t, flag, map[string]string{"exporter.yaml": staticPodManifest})
+	if _, err := readStaticManifests([]string{files, flag}); err == nil || !strings.Contains(err.Error(), "same name") {
+		t.Errorf("Expected an error for manifests with the same name, got %v", err)
+	}
+
+	writeManifests(t, files, map[string]string{"service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"})
+	if _, err := readStaticManifests([]string{files}); err == nil || !strings.Contains(err.Error(), "service.yaml") {
+		t.Errorf("Expected an error naming the invalid manifest, got %v", err)
+	}
+}
+
+func TestStaticManifestChanges(t *testing.T) {
+	exporter := staticManifest{Name: "exporter.yaml", Checksum: "aaa"}
+	web := staticManifest{Name: "web.yaml", Checksum: "bbb"}
+
+	var cases = []struct {
+		description string
+		manifests   []staticManifest
+		inVM        map[string]string
+		synced      map[string]string
+		changed     []staticManifest
+		removed     []string
+	}{
+		{
+			description: "first sync",
+			manifests:   []staticManifest{exporter, web},
+			inVM:        map[string]string{"addon-manager.yaml": "ccc"},
+			changed:     []staticManifest{exporter, web},
+		},
+		{
+			description: "unchanged",
+			manifests:   []staticManifest{exporter, web},
+			inVM:        map[string]string{"addon-manager.yaml": "ccc", "exporter.yaml": "aaa", "web.yaml": "bbb"},
+			synced:      map[string]string{"exporter.yaml": "aaa", "web.yaml": "bbb"},
+		},
+		{
+			description: "edited",
+			manifests:   []staticManifest{exporter, web},
+			inVM:        map[string]string{"exporter.yaml": "aaa", "web.yaml": "old"},
+			synced:      map[string]string{"exporter.yaml": "aaa", "web.yaml": "old"},
+			changed:     []staticManifest{web},
+		},
+		{
+			description: "lost when the VM restarted",
+			manifests:   []staticManifest{exporter},
+			inVM:        map[string]string{"addon-manager.yaml": "ccc"},
+			synced:      map[string]string{"exporter.yaml": "aaa"},
+			changed:     []staticManifest{exporter},
+		},
+		{
+			description: "removed",
+			manifests:   []staticManifest{exporter},
+			inVM:        map[string]string{"addon-manager.yaml": "ccc", "exporter.yaml": "aaa", "web.yaml": "bbb"},
+			synced:      map[string]string{"exporter.yaml": "aaa", "web.yaml": "bbb"},
+			removed:     []string{"web.yaml"},
+		},
+		{
+			description: "removed and already gone from the VM",
+			inVM:        map[string]string{"addon-manager.yaml": "ccc"},
+			synced:      map[string]string{"web.yaml": "bbb"},
+		},
+		{
+			description: "other manifests in the VM are left",
+			inVM:        map[string]string{"addon-manager.yaml": "ccc", "mine.yaml": "ddd"},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			changed, removed := staticManifestChanges(test.manifests, test.inVM, test.synced)
+			if !reflect.DeepEqual(changed, test.changed) {
+				t.Errorf("Expected the changed manifests %+v, got %+v", test.changed, changed)
+			}
+			if !reflect.DeepEqual(removed, test.removed) {
+				t.Errorf("Expected the removed manifests %v, got %v", test.removed, removed)
+			}
+		})
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	out := "e3b0c44298fc1c14  addon-manager.yaml\n9f86d081884c7d65  exporter.yaml\n\n"
+	expected := map[string]string{"addon-manager.yaml": "e3b0c44298fc1c14", "exporter.yaml": "9f86d081884c7d65"}
+	if checksums := parseChecksums(out); !reflect.DeepEqual(checksums, expected) {
+		t.Errorf("Expected the checksums %v, got %v", expected, checksums)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+// StopOptions are how StopHost stops a host.
+type StopOptions struct {
+	// Force escalates to killing the VM when it doesn't shut down in time.
+	Force bool
+	// Timeout is how long the VM has to shut down. Without Force, stopping fails once it
+	// elapses, and a zero Timeout waits indefinitely. With Force, the VM is then killed,
+	// straight away for a zero Timeout.
+	Timeout time.Duration
+}
+
+// StopMethod is how a host was stopped.
+type StopMethod string
+
+const (
+	// StopPoweroff is a shutdown from inside the guest.
+	StopPoweroff StopMethod = "poweroff"
+	// StopDriver is a shutdown by the driver, usually over ACPI.
+	StopDriver StopMethod = "stop"
+	// StopKill is the driver cutting the VM's power.
+	StopKill StopMethod = "kill"
+)
+
+const poweroffCommand = "sudo poweroff"
+
+// killTimeout is how long a killed VM has to stop.
+const killTimeout = time.Minute
+
+// errStopTimedOut is returned when a VM doesn't stop within its timeout.
+type errStopTimedOut struct {
+	timeout time.Duration
+}
+
+func (e errStopTimedOut) Error() string {
+	return fmt.Sprintf("Timed out after %s waiting for the VM to stop", e.timeout)
+}
+
+// poweroffGuest shuts the VM down from inside the guest, replaced in tests.
+var poweroffGuest = func(d drivers.Driver) error {
+	client, err := sshutil.NewSSHClient(d)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = sshutil.RunCommandOutput(client, poweroffCommand)
+	if _, ok := err.(*ssh.ExitMissingError); ok {
+		// The guest went down before reporting the command's exit status.
+		return nil
+	}
+	return err
+}
+
+// forceStop shuts the host down from inside the guest, then with the driver, and kills
+// it once timeout elapses. The guest gets the first half of timeout to shut itself down.
+func forceStop(h *host.Host, policy RetryPolicy, timeout time.Duration) (StopMethod, error) {
+	deadline := time.Now().Add(timeout)
+	if timeout > 0 && h.Driver.DriverName() != "none" {
+		glog.Infof("Shutting down %s from inside the guest", h.Name)
+		err := stopWithin(h.Driver, timeout/2, func() error { return poweroffGuest(h.Driver) })
+		if err == nil {
+			return StopPoweroff, nil
+		}
+		glog.Warningf("Shutting down %s from inside the guest failed: %s", h.Name, err)
+	}
+	if remaining := time.Until(deadline); remaining > 0 {
+		err := stopWithin(h.Driver, remaining, func() error {
+			return retryDriverOp(h.Driver.DriverName(), "stop", policy, h.Driver.Stop)
+		})
+		if err == nil {
+			return StopDriver, nil
+		}
+		glog.Warningf("Stopping %s failed: %s", h.Name, err)
+	}
+	glog.Warningf("%s did not stop within %s, killing it", h.Name, timeout)
+	err := stopWithin(h.Driver, killTimeout, func() error {
+		return retryDriverOp(h.Driver.DriverName(), "kill", policy, h.Driver.Kill)
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Error killing host")
+	}
+	return StopKill, nil
+}
+
+// stopWithin runs stop and waits for the VM to be stopped, giving up if stop fails or
+// once timeout elapses. A zero timeout waits indefinitely. When it gives up, stop
+// is left running, as drivers can't cancel their operations.
+func stopWithin(d drivers.Driver, timeout time.Duration, stop func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- stop()
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	ticker := time.NewTicker(stopPollInterval(timeout))
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return err
+			}
+			// Some drivers return before the VM is stopped.
+			done = nil
+		case <-ticker.C:
+		case <-expired:
+			return errStopTimedOut{timeout}
+		}
+		if s, err := d.GetState(); err == nil && s == state.Stopped {
+			return nil
+		}
+	}
+}
+
+// stopPollInterval is how often the state of a stopping VM is checked, often
+// enough to notice it stopped well within timeout.
+func stopPollInterval(timeout time.Duration) time.Duration {
+	if timeout/20 <= 0 || timeout/20 > time.Second {
+		return time.Second
+	}
+	return timeout / 20
+}
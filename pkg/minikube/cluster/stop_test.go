@@ -0,0 +1,175 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func fakePoweroff(d drivers.Driver) error {
+	return d.(*tests.WedgedDriver).Poweroff()
+}
+
+func TestStopHostEscalation(t *testing.T) {
+	defer func(p func(drivers.Driver) error) { poweroffGuest = p }(poweroffGuest)
+	poweroffGuest = fakePoweroff
+
+	var cases = []struct {
+		description     string
+		opts            StopOptions
+		ignoresPoweroff bool
+		stopHangs       bool
+		method          StopMethod
+		calls           []string
+	}{
+		{
+			description: "poweroff in the guest",
+			opts:        StopOptions{Force: true, Timeout: time.Second},
+			method:      StopPoweroff,
+			calls:       []string{"poweroff"},
+		},
+		{
+			description:     "driver stop after poweroff is ignored",
+			opts:            StopOptions{Force: true, Timeout: time.Second},
+			ignoresPoweroff: true,
+			method:          StopDriver,
+			calls:           []string{"poweroff", "stop"},
+		},
+		{
+			description:     "kill after stop hangs",
+			opts:            StopOptions{Force: true, Timeout: 200 * time.Millisecond},
+			ignoresPoweroff: true,
+			stopHangs:       true,
+			method:          StopKill,
+			calls:           []string{"poweroff", "stop", "kill"},
+		},
+		{
+			description: "kill straight away without timeout",
+			opts:        StopOptions{Force: true},
+			method:      StopKill,
+			calls:       []string{"kill"},
+		},
+		{
+			description: "driver stop without force",
+			opts:        StopOptions{Timeout: time.Second},
+			method:      StopDriver,
+			calls:       []string{"stop"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			api := tests.NewMockAPI()
+			h, err := createHost(api, defaultMachineConfig)
+			if err != nil {
+				t.Fatalf("Error creating host: %s", err)
+			}
+			d := tests.NewWedgedDriver()
+			d.IgnoresPoweroff = test.ignoresPoweroff
+			d.StopHangs = test.stopHangs
+			h.Driver = d
+
+			start := time.Now()
+			method, err := StopHost(context.Background(), api, RetryPolicy{}, test.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error stopping host: %s", err)
+			}
+			if method != test.method {
+				t.Errorf("Expected the host to be stopped with %s, got %s", test.method, method)
+			}
+			if calls := d.Calls(); !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("Expected calls %v, got %v", test.calls, calls)
+			}
+			if s, _ := d.GetState(); s != state.Stopped {
+				t.Errorf("Machine not stopped. Currently in state: %s", s)
+			}
+			if method == StopKill && time.Since(start) < test.opts.Timeout {
+				t.Errorf("Expected the host to be killed once %s elapsed, it was after %s", test.opts.Timeout, time.Since(start))
+			}
+		})
+	}
+}
+
+func TestStopHostTimeoutWithoutForce(t *testing.T) {
+	api := tests.NewMockAPI()
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %s", err)
+	}
+	d := tests.NewWedgedDriver()
+	d.StopHangs = true
+	h.Driver = d
+
+	_, err = StopHost(context.Background(), api, RetryPolicy{}, StopOptions{Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected stopping to time out and suggest --force, got: %v", err)
+	}
+	if calls := d.Calls(); !reflect.DeepEqual(calls, []string{"stop"}) {
+		t.Errorf("Expected the host not to be killed, got calls %v", calls)
+	}
+}
+
+func TestStartHostAfterKill(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	api := tests.NewMockAPI()
+	provision.SetDetector(&tests.MockDetector{Provisioner: &tests.MockProvisioner{}})
+	h, err := createHost(api, defaultMachineConfig)
+	if err != nil {
+		t.Fatalf("Error creating host: %s", err)
+	}
+	d := tests.NewWedgedDriver()
+	d.StopHangs = true
+	h.Driver = d
+
+	if _, err := StopHost(context.Background(), api, RetryPolicy{}, StopOptions{Force: true}); err != nil {
+		t.Fatalf("Unexpected error stopping host: %s", err)
+	}
+	s, err := LoadStartState(config.GetMachineName())
+	if err != nil {
+		t.Fatalf("Error loading start state: %s", err)
+	}
+	if s.LastStop != StopKill {
+		t.Errorf("Expected the kill to be recorded, got: %q", s.LastStop)
+	}
+
+	if _, _, err := StartHost(context.Background(), api, defaultMachineConfig); err != nil {
+		t.Fatalf("Error starting killed host: %s", err)
+	}
+	if s, _ := d.GetState(); s != state.Running {
+		t.Errorf("Machine not running. Currently in state: %s", s)
+	}
+	s, err = LoadStartState(config.GetMachineName())
+	if err != nil {
+		t.Fatalf("Error loading start state: %s", err)
+	}
+	if s.LastStop != "" {
+		t.Errorf("Expected starting the host to clear how it was stopped, got: %q", s.LastStop)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// timingsFile is the name of the file, in the machine directory, the timings are kept in.
+const timingsFile = "timings.json"
+
+// maxTimings is how many timings are kept, the oldest ones are dropped first.
+const maxTimings = 20
+
+// The commands a Timing is recorded for.
+const (
+	TimingStart = "start"
+	TimingStop  = "stop"
+)
+
+// Timing records how long a start or a stop of a host took.
+type Timing struct {
+	// Command is TimingStart or TimingStop.
+	Command string
+	// Time is when the command began.
+	Time     time.Time
+	Duration time.Duration
+	// Durations are how long each step of the command took, by step name.
+	Durations map[string]time.Duration `json:",omitempty"`
+	// Error is the error the command failed with, if any.
+	Error string `json:",omitempty"`
+}
+
+// Failed returns whether the command failed.
+func (t Timing) Failed() bool {
+	return t.Error != ""
+}
+
+func timingsPath(name string) string {
+	return filepath.Join(constants.GetMinipath(), "machines", name, timingsFile)
+}
+
+// LoadTimings reads the timings of the named machine, oldest first.
+// None are returned if the machine has never been started or stopped.
+func LoadTimings(name string) ([]Timing, error) {
+	data, err := ioutil.ReadFile(timingsPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Error reading timings")
+	}
+	var timings []Timing
+	if err := json.Unmarshal(data, &timings); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling timings")
+	}
+	return timings, nil
+}
+
+// LastTiming returns the most recent of timings of command, or a zero Timing if there is none.
+func LastTiming(timings []Timing, command string) Timing {
+	for i := len(timings) - 1; i >= 0; i-- {
+		if timings[i].Command == command {
+			return timings[i]
+		}
+	}
+	return Timing{}
+}
+
+// RecordTiming adds t to the timings of the named machine, dropping the oldest
+// past maxTimings. Like the start state, they are only written once the machine
+// directory exists, and failing to record them doesn't fail the command.
+func RecordTiming(name string, t Timing) {
+	if _, err := os.Stat(filepath.Dir(timingsPath(name))); err != nil {
+		glog.Infof("Not recording %s timing for %s, machine directory does not exist", t.Command, name)
+		return
+	}
+	timings, err := LoadTimings(name)
+	if err != nil {
+		glog.Warningf("Dropping timings of %s: %s", name, err)
+	}
+	timings = append(timings, t)
+	if len(timings) > maxTimings {
+		timings = timings[len(timings)-maxTimings:]
+	}
+	data, err := json.MarshalIndent(timings, "", "    ")
+	if err != nil {
+		glog.Warningf("Error marshalling timings: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(timingsPath(name), data, 0600); err != nil {
+		glog.Warningf("Error writing timings: %s", err)
+	}
+}
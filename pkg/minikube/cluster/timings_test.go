@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestRecordTimingRotates(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+	name := config.GetMachineName()
+
+	began := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < maxTimings+5; i++ {
+		RecordTiming(name, Timing{
+			Command:   TimingStart,
+			Time:      began.Add(time.Duration(i) * time.Hour),
+			Duration:  time.Duration(i) * time.Second,
+			Durations: map[string]time.Duration{"creating-vm": time.Duration(i) * time.Second},
+		})
+	}
+	RecordTiming(name, Timing{Command: TimingStop, Time: began.Add(48 * time.Hour), Duration: time.Second, Error: "timed out"})
+
+	data, err := ioutil.ReadFile(timingsPath(name))
+	if err != nil {
+		t.Fatalf("Error reading timings file: %s", err)
+	}
+	var timings []Timing
+	if err := json.Unmarshal(data, &timings); err != nil {
+		t.Fatalf("Timings file is not a JSON list of timings: %s", err)
+	}
+	if len(timings) != maxTimings {
+		t.Fatalf("Expected %d timings to be kept, got %d", maxTimings, len(timings))
+	}
+	// The oldest 6 starts were dropped.
+	if first := timings[0]; first.Duration != 6*time.Second || first.Durations["creating-vm"] != 6*time.Second {
+		t.Errorf("Expected the oldest kept timing to be the 7th start, got %+v", first)
+	}
+
+	loaded, err := LoadTimings(name)
+	if err != nil {
+		t.Fatalf("Error loading timings: %s", err)
+	}
+	if !reflect.DeepEqual(loaded, timings) {
+		t.Errorf("Expected the loaded timings to be the recorded ones")
+	}
+	start := LastTiming(loaded, TimingStart)
+	if start.Duration != time.Duration(maxTimings+4)*time.Second {
+		t.Errorf("Expected the last start to have taken %ds, got %s", maxTimings+4, start.Duration)
+	}
+	if stop := LastTiming(loaded, TimingStop); !stop.Failed() {
+		t.Errorf("Expected the last stop to have failed, got %+v", stop)
+	}
+}
+
+func TestRecordTimingWithoutMachine(t *testing.T) {
+	tempDir := makeMachineDir(t)
+	defer os.RemoveAll(tempDir)
+
+	RecordTiming("nonexistent", Timing{Command: TimingStart})
+	timings, err := LoadTimings("nonexistent")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(timings) != 0 {
+		t.Errorf("Expected no timings for a machine without a directory, got %v", timings)
+	}
+	if last := LastTiming(timings, TimingStart); !reflect.DeepEqual(last, Timing{}) {
+		t.Errorf("Expected a zero timing, got %+v", last)
+	}
+}
@@ -20,25 +20,42 @@ import "k8s.io/minikube/pkg/util"
 
 // MachineConfig contains the parameters used to start a cluster.
 type MachineConfig struct {
-	MinikubeISO         string
-	Memory              int
-	CPUs                int
-	DiskSize            int
-	VMDriver            string
-	XhyveDiskDriver     string   // Only used by the xhyve driver
-	DockerEnv           []string // Each entry is formatted as KEY=VALUE.
-	InsecureRegistry    []string
-	RegistryMirror      []string
-	HostOnlyCIDR        string // Only used by the virtualbox driver
-	HypervVirtualSwitch string
-	KvmNetwork          string // Only used by the KVM driver
-	Downloader          util.ISODownloader
-	DockerOpt           []string // Each entry is formatted as KEY=VALUE.
+	MinikubeISO             string
+	Memory                  int
+	CPUs                    int
+	DiskSize                int
+	VMDriver                string
+	XhyveDiskDriver         string   // Only used by the xhyve driver
+	DockerEnv               []string // Each entry is formatted as KEY=VALUE.
+	InsecureRegistry        []string
+	RegistryMirror          []string
+	HostOnlyCIDR            string // Only used by the virtualbox driver
+	HypervVirtualSwitch     string
+	HypervUseExternalSwitch bool               // Pick or create an external switch when HypervVirtualSwitch isn't set
+	KvmNetwork              string             // Only used by the KVM driver
+	Downloader              util.ISODownloader `json:"-"`
+	DockerOpt               []string           // Each entry is formatted as KEY=VALUE.
+	ForceRecreate           bool               // Recreate the host if its stored config is corrupt or its VM is missing.
+	RecreateOnConfigChange  bool               // Recreate the host if the config differs from the one it was created with.
+	KeepFailed              bool               `json:"-"` // Keep a host this start created even if starting it fails.
+	RetryPolicy             RetryPolicy        `json:"-"` // How driver operations failing with transient errors are retried.
+	ExtraDisks              int                // Only used by the virtualbox and kvm2 drivers
+	ExtraDiskSize           int                // The size of each extra disk, in MB.
+	NatForwards             []PortForward      // Only used by the virtualbox driver
+	GPU                     bool               // Only used by the kvm2 driver
+	SharedFolder            SharedFolder       // Only used by the virtualbox and kvm2 drivers
+	Offline                 bool               `json:"-"` // Only use cached artifacts, never download them.
+	Proxy                   ProxyConfig        // Passed to the Docker daemon, unless DockerEnv sets the same variables.
+	Steps                   *util.StepReporter `json:"-"` // Where the phases of starting the host are reported.
+	// RequestedSettings are the settings given as flags, in the environment or in the config. Only
+	// they are compared with the existing host's, the defaults are not. All are compared if it's nil.
+	RequestedSettings map[string]bool `json:"-"`
 }
 
 // KubernetesConfig contains the parameters used to configure the VM Kubernetes.
 type KubernetesConfig struct {
 	KubernetesVersion string
+	KubernetesChannel string // The release channel KubernetesVersion was resolved from, if any.
 	NodeIP            string
 	APIServerName     string
 	DNSDomain         string
@@ -46,4 +63,14 @@ type KubernetesConfig struct {
 	NetworkPlugin     string
 	FeatureGates      string
 	ExtraOptions      util.ExtraOptionSlice
+	Offline           bool          // Only use a cached localkube, never download it.
+	ImageRepository   string        // Pull the images of gcr.io/google_containers from this repository instead.
+	APIServerSANs     APIServerSANs // Extra names and IPs of the apiserver certificate.
+	ServiceCIDR       string        // The range of the service IPs, localkube's default if empty.
+	PodCIDR           string        // The range of the pod IPs, localkube's default if empty.
+	AuditPolicy       string        // The path of the apiserver's audit policy on the host, if it audits requests.
+	// StorageProvisionerAddon is whether the storage-provisioner addon provisions the volumes, rather than localkube.
+	StorageProvisionerAddon bool
+	// MetricsAddon is whether the metrics addon collects the metrics of the kubelet, which serves them to it.
+	MetricsAddon bool
 }
@@ -0,0 +1,168 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/version"
+)
+
+// VersionChange is how the Kubernetes version a cluster is started with compares to the one it runs.
+type VersionChange int
+
+const (
+	// VersionUnchanged is the version the cluster runs.
+	VersionUnchanged VersionChange = iota
+	// VersionFirstStart is the version of a cluster which was never started.
+	VersionFirstStart
+	VersionUpgrade
+	VersionDowngrade
+	// VersionIncomparable is a localkube URI, or compared to one, which has no version to compare.
+	VersionIncomparable
+)
+
+// stopClusterCommand stops localkube, which runs all of the cluster's components.
+const stopClusterCommand = "sudo systemctl stop localkube.service"
+
+// KubernetesVersionChange is a change of the Kubernetes version a cluster runs.
+type KubernetesVersionChange struct {
+	From, To string
+	Change   VersionChange
+}
+
+// Migration upgrades the cluster's data to a Kubernetes version. It runs once localkube is
+// stopped and replaced, before the new version starts.
+type Migration struct {
+	// Version is the version the migration upgrades to. It runs when a cluster is upgraded
+	// from a version before it to it, or to a version after it.
+	Version     semver.Version
+	Description string
+	Run         func(h *host.Host) error
+}
+
+// migrations are the migrations of every version so far. localkube has kept its etcd data
+// in the same storage version since v1.3, so none was needed yet.
+var migrations []Migration
+
+func parseKubernetesVersion(v string) (semver.Version, bool) {
+	parsed, err := semver.Make(strings.TrimPrefix(v, version.VersionPrefix))
+	return parsed, err == nil
+}
+
+// CompareKubernetesVersions returns how requested compares to running, the version a cluster
+// runs, which is empty if it was never started.
+func CompareKubernetesVersions(running, requested string) VersionChange {
+	if running == "" {
+		return VersionFirstStart
+	}
+	if running == requested {
+		return VersionUnchanged
+	}
+	from, ok := parseKubernetesVersion(running)
+	if !ok {
+		return VersionIncomparable
+	}
+	to, ok := parseKubernetesVersion(requested)
+	if !ok {
+		return VersionIncomparable
+	}
+	switch {
+	case to.GT(from):
+		return VersionUpgrade
+	case to.LT(from):
+		return VersionDowngrade
+	}
+	return VersionUnchanged
+}
+
+// RunningKubernetesVersion returns the Kubernetes version the named machine's cluster was last
+// started with, or "" if it was never started.
+func RunningKubernetesVersion(name string) string {
+	s, err := LoadStartState(name)
+	if err != nil {
+		glog.Warningf("Not checking the Kubernetes version of %s: %s", name, err)
+	}
+	if s.RunningKubernetesVersion == "" && s.KubernetesConfig != "" {
+		// Clusters started before the running version was recorded run the version they were updated to.
+		return s.KubernetesVersion
+	}
+	return s.RunningKubernetesVersion
+}
+
+// CheckKubernetesVersionChange returns how requested changes the Kubernetes version of the named machine's cluster.
+func CheckKubernetesVersionChange(name, requested string) KubernetesVersionChange {
+	running := RunningKubernetesVersion(name)
+	return KubernetesVersionChange{From: running, To: requested, Change: CompareKubernetesVersions(running, requested)}
+}
+
+// StopClusterComponents stops the cluster's components, so that they can be replaced.
+func StopClusterComponents(api libmachine.API) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error checking that api exists and loading it")
+	}
+	output, err := RunCommand(h, stopClusterCommand, true)
+	glog.Infoln(output)
+	if err != nil {
+		return errors.Wrap(err, "Error stopping the cluster's components")
+	}
+	return nil
+}
+
+// RunMigrations runs the migrations of the versions the cluster is upgraded through, in order.
+func RunMigrations(api libmachine.API, c KubernetesVersionChange, out io.Writer) error {
+	h, err := CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error checking that api exists and loading it")
+	}
+	return runMigrations(h, c, migrations, out)
+}
+
+func runMigrations(h *host.Host, c KubernetesVersionChange, all []Migration, out io.Writer) error {
+	from, ok := parseKubernetesVersion(c.From)
+	if !ok {
+		return fmt.Errorf("Can't migrate from Kubernetes version %s", c.From)
+	}
+	to, ok := parseKubernetesVersion(c.To)
+	if !ok {
+		return fmt.Errorf("Can't migrate to Kubernetes version %s", c.To)
+	}
+	var pending []Migration
+	for _, m := range all {
+		if m.Version.GT(from) && m.Version.LTE(to) {
+			pending = append(pending, m)
+		}
+	}
+	// Migrations of the same version run in the order they were registered in.
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Version.LT(pending[j].Version) })
+	for _, m := range pending {
+		fmt.Fprintf(out, "Migrating the cluster to %s%s: %s...\n", version.VersionPrefix, m.Version, m.Description)
+		if err := m.Run(h); err != nil {
+			return errors.Wrapf(err, "Error migrating the cluster to %s%s", version.VersionPrefix, m.Version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/docker/machine/libmachine/host"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestCompareKubernetesVersions(t *testing.T) {
+	var cases = []struct {
+		description string
+		running     string
+		requested   string
+		change      VersionChange
+	}{
+		{"never started", "", "v1.6.4", VersionFirstStart},
+		{"same version", "v1.6.4", "v1.6.4", VersionUnchanged},
+		{"patch upgrade", "v1.6.4", "v1.6.10", VersionUpgrade},
+		{"minor upgrade", "v1.6.4", "v1.7.0", VersionUpgrade},
+		{"prerelease upgrade", "v1.7.0-alpha.2", "v1.7.0", VersionUpgrade},
+		{"patch downgrade", "v1.6.10", "v1.6.4", VersionDowngrade},
+		{"minor downgrade", "v1.7.0", "v1.6.4", VersionDowngrade},
+		{"to a localkube URI", "v1.6.4", "https://example.com/localkube", VersionIncomparable},
+		{"from a localkube URI", "file:///tmp/localkube", "v1.6.4", VersionIncomparable},
+		{"same localkube URI", "file:///tmp/localkube", "file:///tmp/localkube", VersionUnchanged},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			if change := CompareKubernetesVersions(test.running, test.requested); change != test.change {
+				t.Errorf("Expected %s to %s to be %d, got %d", test.running, test.requested, test.change, change)
+			}
+		})
+	}
+}
+
+func TestCheckKubernetesVersionChange(t *testing.T) {
+	var cases = []struct {
+		description string
+		state       StartState
+		requested   string
+		expected    KubernetesVersionChange
+	}{
+		{
+			description: "never started",
+			requested:   "v1.6.4",
+			expected:    KubernetesVersionChange{To: "v1.6.4", Change: VersionFirstStart},
+		},
+		{
+			description: "updated but never started",
+			state:       StartState{KubernetesVersion: "v1.6.4"},
+			requested:   "v1.7.0",
+			expected:    KubernetesVersionChange{To: "v1.7.0", Change: VersionFirstStart},
+		},
+		{
+			description: "started",
+			state:       StartState{KubernetesVersion: "v1.7.0", RunningKubernetesVersion: "v1.6.4", KubernetesConfig: "fingerprint"},
+			requested:   "v1.7.0",
+			expected:    KubernetesVersionChange{From: "v1.6.4", To: "v1.7.0", Change: VersionUpgrade},
+		},
+		{
+			description: "started before the running version was recorded",
+			state:       StartState{KubernetesVersion: "v1.7.0", KubernetesConfig: "fingerprint"},
+			requested:   "v1.6.4",
+			expected:    KubernetesVersionChange{From: "v1.7.0", To: "v1.6.4", Change: VersionDowngrade},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir := makeMachineDir(t)
+			defer os.RemoveAll(tempDir)
+			writeStartState(config.GetMachineName(), test.state)
+
+			if c := CheckKubernetesVersionChange(config.GetMachineName(), test.requested); c != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, c)
+			}
+		})
+	}
+}
+
+func TestRunMigrations(t *testing.T) {
+	var ran []string
+	migration := func(v, description string, err error) Migration {
+		return Migration{
+			Version:     semver.MustParse(v),
+			Description: description,
+			Run: func(*host.Host) error {
+				ran = append(ran, description)
+				return err
+			},
+		}
+	}
+	// Registered out of order.
+	all := []Migration{
+		migration("1.8.0", "b", nil),
+		migration("1.7.0", "a", nil),
+		migration("1.9.0", "d", nil),
+		migration("1.8.0", "c", nil),
+		migration("1.6.0", "old", nil),
+	}
+
+	var cases = []struct {
+		description string
+		migrations  []Migration
+		change      KubernetesVersionChange
+		ran         []string
+		shouldErr   bool
+	}{
+		{
+			description: "versions in between, in order",
+			migrations:  all,
+			change:      KubernetesVersionChange{From: "v1.6.4", To: "v1.9.0"},
+			ran:         []string{"a", "b", "c", "d"},
+		},
+		{
+			description: "the version upgraded from is left out",
+			migrations:  all,
+			change:      KubernetesVersionChange{From: "v1.7.0", To: "v1.8.1"},
+			ran:         []string{"b", "c"},
+		},
+		{
+			description: "none in between",
+			migrations:  all,
+			change:      KubernetesVersionChange{From: "v1.6.4", To: "v1.6.7"},
+		},
+		{
+			description: "a failed migration stops the others",
+			migrations:  []Migration{migration("1.7.0", "a", errors.New("failed")), migration("1.8.0", "b", nil)},
+			change:      KubernetesVersionChange{From: "v1.6.4", To: "v1.8.0"},
+			ran:         []string{"a"},
+			shouldErr:   true,
+		},
+		{
+			description: "localkube URI",
+			migrations:  all,
+			change:      KubernetesVersionChange{From: "v1.6.4", To: "file:///tmp/localkube"},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			ran = nil
+			var out bytes.Buffer
+			err := runMigrations(&host.Host{}, test.change, test.migrations, &out)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Expected error: %t, got %v", test.shouldErr, err)
+			}
+			if !reflect.DeepEqual(ran, test.ran) {
+				t.Errorf("Expected the migrations %v to run, got %v", test.ran, ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/minikube/assets"
+)
+
+// LocalkubeBootstrapper is the bootstrapper starting the cluster's components in the VM.
+const LocalkubeBootstrapper = "localkube"
+
+const (
+	componentNamespace  = "kube-system"
+	clusterWaitInterval = 2 * time.Second
+)
+
+// ClusterComponent is a component running in kube-system pods, which a started cluster is waited for.
+type ClusterComponent struct {
+	Name string
+	// Selector is the label selector of its pods.
+	Selector string
+	// Addon is the addon deploying it. The component isn't waited for when the addon is disabled.
+	Addon string
+}
+
+// bootstrapperComponents are the components each bootstrapper runs in pods. localkube runs
+// the apiserver, controller manager, scheduler and kube-proxy in its own process, which is
+// waited for through the apiserver's health.
+var bootstrapperComponents = map[string][]ClusterComponent{
+	LocalkubeBootstrapper: {
+		{Name: "addon-manager", Selector: "component=kube-addon-manager", Addon: "addon-manager"},
+		{Name: "kube-dns", Selector: "k8s-app=kube-dns", Addon: "kube-dns"},
+	},
+}
+
+// DashboardComponent is the kubernetes dashboard, which minikube dashboard waits for.
+var DashboardComponent = ClusterComponent{Name: "dashboard", Selector: "app=kubernetes-dashboard", Addon: "dashboard"}
+
+// ClusterComponents returns the components of the bootstrapper which the enabled addons deploy.
+func ClusterComponents(bootstrapper string) ([]ClusterComponent, error) {
+	all, ok := bootstrapperComponents[bootstrapper]
+	if !ok {
+		return nil, fmt.Errorf("Unknown bootstrapper %s", bootstrapper)
+	}
+	var components []ClusterComponent
+	for _, c := range all {
+		if addon, ok := assets.Addons[c.Addon]; ok {
+			enabled, err := addon.IsEnabled()
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error checking whether the %s addon is enabled", c.Addon)
+			}
+			if !enabled {
+				continue
+			}
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// WaitForCluster waits for the host's apiserver to be healthy, and then for the pods of each
+// component to be ready, writing which one it waits for to out. When one isn't ready within
+// timeout, the events of its pod are written to out too.
+func WaitForCluster(h *host.Host, client corev1.CoreV1Interface, components []ClusterComponent, timeout time.Duration, out io.Writer) error {
+	return waitForCluster(h, client, components, timeout, clusterWaitInterval, out)
+}
+
+func waitForCluster(h *host.Host, client corev1.CoreV1Interface, components []ClusterComponent, timeout, interval time.Duration, out io.Writer) error {
+	deadline := time.Now().Add(timeout)
+	fmt.Fprintln(out, "Waiting for the apiserver...")
+	for {
+		err := apiserverHealthz(h)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "The apiserver was not healthy within %s", timeout)
+		}
+		glog.Infof("Waiting for the apiserver: %s", err)
+		time.Sleep(interval)
+	}
+
+	for _, c := range components {
+		fmt.Fprintf(out, "Waiting for %s...\n", c.Name)
+		notReady, err := waitForComponent(client.Pods(componentNamespace), c, deadline, timeout, interval)
+		if notReady != nil {
+			writePodEvents(client, notReady, out)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForComponent waits for the pods of the component to be ready, for up to timeout. When one
+// isn't, the error ends with its events.
+func WaitForComponent(client corev1.CoreV1Interface, c ClusterComponent, timeout time.Duration) error {
+	return waitForComponentEvents(client, c, timeout, clusterWaitInterval)
+}
+
+func waitForComponentEvents(client corev1.CoreV1Interface, c ClusterComponent, timeout, interval time.Duration) error {
+	notReady, err := waitForComponent(client.Pods(componentNamespace), c, time.Now().Add(timeout), timeout, interval)
+	if notReady == nil {
+		return err
+	}
+	var events bytes.Buffer
+	writePodEvents(client, notReady, &events)
+	return fmt.Errorf("%s\n%s", err, strings.TrimSuffix(events.String(), "\n"))
+}
+
+// waitForComponent waits for the pods of the component to be ready until the deadline, timeout
+// after the wait started. When one isn't by then, it is returned along with the error.
+func waitForComponent(pods corev1.PodInterface, c ClusterComponent, deadline time.Time, timeout, interval time.Duration) (*v1.Pod, error) {
+	for {
+		ready, notReady, err := componentReady(pods, c)
+		if err != nil {
+			return nil, err
+		}
+		if ready {
+			return nil, nil
+		}
+		if time.Now().After(deadline) {
+			if notReady == nil {
+				return nil, fmt.Errorf("No %s pod was created within %s", c.Name, timeout)
+			}
+			return notReady, fmt.Errorf("The %s pod %s was not ready within %s, it is %s", c.Name, notReady.Name, timeout, notReady.Status.Phase)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// componentReady returns whether the component has pods and all of them are ready. When they
+// aren't, it also returns the first pod which isn't, if there is one.
+func componentReady(pods corev1.PodInterface, c ClusterComponent) (bool, *v1.Pod, error) {
+	list, err := pods.List(meta_v1.ListOptions{LabelSelector: c.Selector})
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "Error listing the %s pods", c.Name)
+	}
+	found := false
+	for i := range list.Items {
+		pod := &list.Items[i]
+		// Pods being replaced are left out.
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if !podReady(pod) {
+			return false, pod, nil
+		}
+		found = true
+	}
+	return found, nil, nil
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// writePodEvents writes the events of the pod to out, to show why it isn't ready.
+func writePodEvents(client corev1.CoreV1Interface, pod *v1.Pod, out io.Writer) {
+	events, err := client.Events(componentNamespace).List(meta_v1.ListOptions{FieldSelector: "involvedObject.name=" + pod.Name})
+	if err != nil {
+		glog.Warningf("Error listing the events of pod %s: %s", pod.Name, err)
+		return
+	}
+	if len(events.Items) == 0 {
+		fmt.Fprintf(out, "The pod %s has no events.\n", pod.Name)
+		return
+	}
+	fmt.Fprintf(out, "Events of the pod %s:\n", pod.Name)
+	for _, e := range events.Items {
+		fmt.Fprintf(out, "  %s\t%s\t%s\t%s\n", e.LastTimestamp.Format(time.RFC3339), e.Type, e.Reason, e.Message)
+	}
+}
+
+// DescribePods writes the phase, conditions and containers of each kube-system pod to out,
+// along with its events, to show what runs in the cluster and why what doesn't fails to.
+func DescribePods(client corev1.CoreV1Interface, out io.Writer) error {
+	list, err := client.Pods(componentNamespace).List(meta_v1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Error listing the %s pods", componentNamespace)
+	}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		fmt.Fprintf(out, "Pod %s on node %s is %s", pod.Name, pod.Spec.NodeName, pod.Status.Phase)
+		if pod.Status.Reason != "" {
+			fmt.Fprintf(out, ": %s %s", pod.Status.Reason, pod.Status.Message)
+		}
+		fmt.Fprintln(out)
+		for _, c := range pod.Status.Conditions {
+			fmt.Fprintf(out, "  %s\t%s\t%s\n", c.Type, c.Status, c.Message)
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			fmt.Fprintf(out, "  Container %s\t%s\tready: %t\trestarts: %d\t%s\n", c.Name, c.Image, c.Ready, c.RestartCount, containerState(c.State))
+			if t := c.LastTerminationState.Terminated; t != nil {
+				fmt.Fprintf(out, "    last terminated: %s, exit code %d %s\n", t.Reason, t.ExitCode, t.Message)
+			}
+		}
+		writePodEvents(client, pod, out)
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func containerState(s v1.ContainerState) string {
+	switch {
+	case s.Running != nil:
+		return "running since " + s.Running.StartedAt.Format(time.RFC3339)
+	case s.Waiting != nil:
+		return fmt.Sprintf("waiting: %s %s", s.Waiting.Reason, s.Waiting.Message)
+	case s.Terminated != nil:
+		return fmt.Sprintf("terminated: %s, exit code %d", s.Terminated.Reason, s.Terminated.ExitCode)
+	}
+	return "unknown"
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	"k8s.io/client-go/pkg/api/v1"
+	core "k8s.io/client-go/testing"
+)
+
+func componentPod(name, app string, ready bool) v1.Pod {
+	status := v1.ConditionFalse
+	phase := v1.PodPending
+	if ready {
+		status = v1.ConditionTrue
+		phase = v1.PodRunning
+	}
+	return v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: componentNamespace, Labels: map[string]string{"k8s-app": app}},
+		Status: v1.PodStatus{
+			Phase:      phase,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestWaitForCluster(t *testing.T) {
+	defer func(p func(*host.Host) error) { apiserverHealthz = p }(apiserverHealthz)
+	components := []ClusterComponent{
+		{Name: "kube-dns", Selector: "k8s-app=kube-dns"},
+		{Name: "dashboard", Selector: "k8s-app=kubernetes-dashboard"},
+	}
+	dashboard := componentPod("kubernetes-dashboard-1", "kubernetes-dashboard", true)
+
+	var cases = []struct {
+		description string
+		// health is what each probe of the apiserver returns, the last one from then on.
+		health []error
+		// lists are the pods each list returns, the last ones from then on.
+		lists     [][]v1.Pod
+		shouldErr bool
+		errorMsg  string
+		output    []string
+	}{
+		{
+			description: "ready",
+			health:      []error{nil},
+			lists:       [][]v1.Pod{{componentPod("kube-dns-1", "kube-dns", true), dashboard}},
+			output:      []string{"Waiting for the apiserver...", "Waiting for kube-dns...", "Waiting for dashboard..."},
+		},
+		{
+			description: "apiserver becomes healthy",
+			health:      []error{errors.New("connection refused"), errors.New("connection refused"), nil},
+			lists:       [][]v1.Pod{{componentPod("kube-dns-1", "kube-dns", true), dashboard}},
+		},
+		{
+			description: "pod becomes ready",
+			health:      []error{nil},
+			lists: [][]v1.Pod{
+				{dashboard},
+				{componentPod("kube-dns-1", "kube-dns", false), dashboard},
+				{componentPod("kube-dns-1", "kube-dns", true), dashboard},
+			},
+		},
+		{
+			description: "replaced pod is left out",
+			health:      []error{nil},
+			lists: [][]v1.Pod{{
+				func() v1.Pod {
+					p := componentPod("kube-dns-0", "kube-dns", false)
+					p.DeletionTimestamp = &meta_v1.Time{Time: time.Now()}
+					return p
+				}(),
+				componentPod("kube-dns-1", "kube-dns", true),
+				dashboard,
+			}},
+		},
+		{
+			description: "apiserver never healthy",
+			health:      []error{errors.New("connection refused")},
+			shouldErr:   true,
+			errorMsg:    "apiserver was not healthy",
+		},
+		{
+			description: "pod never ready",
+			health:      []error{nil},
+			lists:       [][]v1.Pod{{componentPod("kube-dns-1", "kube-dns", false), dashboard}},
+			shouldErr:   true,
+			errorMsg:    "kube-dns pod kube-dns-1 was not ready",
+			output:      []string{"Events of the pod kube-dns-1:", "Unhealthy\tReadiness probe failed"},
+		},
+		{
+			description: "pod never created",
+			health:      []error{nil},
+			lists:       [][]v1.Pod{{dashboard}},
+			shouldErr:   true,
+			errorMsg:    "No kube-dns pod was created",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			probes := 0
+			apiserverHealthz = func(*host.Host) error {
+				err := test.health[probes]
+				if probes < len(test.health)-1 {
+					probes++
+				}
+				return err
+			}
+			client := &fake.FakeCoreV1{Fake: &core.Fake{}}
+			lists := 0
+			client.AddReactor("list", "pods", func(core.Action) (bool, runtime.Object, error) {
+				pods := test.lists[lists]
+				if lists < len(test.lists)-1 {
+					lists++
+				}
+				return true, &v1.PodList{Items: pods}, nil
+			})
+			client.AddReactor("list", "events", func(action core.Action) (bool, runtime.Object, error) {
+				if fields := action.(core.ListAction).GetListRestrictions().Fields.String(); fields != "involvedObject.name=kube-dns-1" {
+					t.Errorf("Expected the events of kube-dns-1 to be listed, got %q", fields)
+				}
+				return true, &v1.EventList{Items: []v1.Event{{Type: "Warning", Reason: "Unhealthy", Message: "Readiness probe failed"}}}, nil
+			})
+
+			var out bytes.Buffer
+			err := waitForCluster(&host.Host{}, client, components, 50*time.Millisecond, time.Millisecond, &out)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Expected error: %t, got %v", test.shouldErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), test.errorMsg) {
+				t.Errorf("Expected the error to contain %q, got %q", test.errorMsg, err)
+			}
+			for _, o := range test.output {
+				if !strings.Contains(out.String(), o) {
+					t.Errorf("Expected the output to contain %q, got %q", o, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForComponent(t *testing.T) {
+	dashboard := func(ready bool) v1.Pod {
+		p := componentPod("kubernetes-dashboard-1", "", ready)
+		p.Labels = map[string]string{"app": "kubernetes-dashboard"}
+		return p
+	}
+
+	var cases = []struct {
+		description string
+		lists       [][]v1.Pod
+		shouldErr   bool
+		errorMsg    []string
+	}{
+		{
+			description: "ready",
+			lists:       [][]v1.Pod{{dashboard(true)}},
+		},
+		{
+			description: "becomes ready",
+			lists:       [][]v1.Pod{{}, {dashboard(false)}, {dashboard(true)}},
+		},
+		{
+			description: "never ready",
+			lists:       [][]v1.Pod{{dashboard(false)}},
+			shouldErr:   true,
+			errorMsg: []string{
+				"The dashboard pod kubernetes-dashboard-1 was not ready within 50ms, it is Pending",
+				"Events of the pod kubernetes-dashboard-1:",
+				"Warning\tFailed\tFailed to pull image",
+			},
+		},
+		{
+			description: "never created",
+			lists:       [][]v1.Pod{{}},
+			shouldErr:   true,
+			errorMsg:    []string{"No dashboard pod was created within 50ms"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			client := &fake.FakeCoreV1{Fake: &core.Fake{}}
+			lists := 0
+			client.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if labels := action.(core.ListAction).GetListRestrictions().Labels.String(); labels != DashboardComponent.Selector {
+					t.Errorf("Expected the pods matching %q to be listed, got %q", DashboardComponent.Selector, labels)
+				}
+				pods := test.lists[lists]
+				if lists < len(test.lists)-1 {
+					lists++
+				}
+				return true, &v1.PodList{Items: pods}, nil
+			})
+			client.AddReactor("list", "events", func(core.Action) (bool, runtime.Object, error) {
+				return true, &v1.EventList{Items: []v1.Event{{Type: "Warning", Reason: "Failed", Message: "Failed to pull image"}}}, nil
+			})
+
+			err := waitForComponentEvents(client, DashboardComponent, 50*time.Millisecond, time.Millisecond)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Expected error: %t, got %v", test.shouldErr, err)
+			}
+			for _, msg := range test.errorMsg {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("Expected the error to contain %q, got %q", msg, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribePods(t *testing.T) {
+	pod := componentPod("kube-dns-1", "kube-dns", false)
+	pod.Spec.NodeName = "minikube"
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:                 "kubedns",
+		Image:                "gcr.io/google_containers/k8s-dns-kube-dns-amd64:1.14.4",
+		RestartCount:         3,
+		State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 255}},
+	}}
+	client := &fake.FakeCoreV1{Fake: &core.Fake{}}
+	client.AddReactor("list", "pods", func(core.Action) (bool, runtime.Object, error) {
+		return true, &v1.PodList{Items: []v1.Pod{pod}}, nil
+	})
+	client.AddReactor("list", "events", func(core.Action) (bool, runtime.Object, error) {
+		return true, &v1.EventList{Items: []v1.Event{{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container"}}}, nil
+	})
+
+	var out bytes.Buffer
+	if err := DescribePods(client, &out); err != nil {
+		t.Fatalf("Error describing the pods: %s", err)
+	}
+	for _, o := range []string{
+		"Pod kube-dns-1 on node minikube is Pending",
+		"  Ready\tFalse",
+		"  Container kubedns\tgcr.io/google_containers/k8s-dns-kube-dns-amd64:1.14.4\tready: false\trestarts: 3\twaiting: CrashLoopBackOff",
+		"    last terminated: Error, exit code 255",
+		"Events of the pod kube-dns-1:",
+		"Warning\tBackOff\tBack-off restarting failed container",
+	} {
+		if !strings.Contains(out.String(), o) {
+			t.Errorf("Expected the output to contain %q, got %q", o, out.String())
+		}
+	}
+}
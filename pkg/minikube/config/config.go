@@ -21,24 +21,78 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/golang/glog"
 	"github.com/spf13/viper"
 	"k8s.io/minikube/pkg/minikube/constants"
 )
 
 const (
-	WantUpdateNotification    = "WantUpdateNotification"
-	ReminderWaitPeriodInHours = "ReminderWaitPeriodInHours"
-	WantReportError           = "WantReportError"
-	WantReportErrorPrompt     = "WantReportErrorPrompt"
-	WantKubectlDownloadMsg    = "WantKubectlDownloadMsg"
-	MachineProfile            = "profile"
+	WantUpdateNotification     = "WantUpdateNotification"
+	ReminderWaitPeriodInHours  = "ReminderWaitPeriodInHours"
+	WantBetaUpdateNotification = "WantBetaUpdateNotification"
+	ReleasesURL                = "releases-url"
+	WantReportError            = "WantReportError"
+	WantReportErrorPrompt      = "WantReportErrorPrompt"
+	WantKubectlDownloadMsg     = "WantKubectlDownloadMsg"
+	MachineProfile             = "profile"
+	RemoteHost                 = "remote-host"
+	RemoteUser                 = "remote-user"
+	RemoteSSHKey               = "remote-ssh-key"
+	RemoteHostKey              = "remote-host-key"
+	RemoteStorePath            = "remote-store-path"
+	ImageRepository            = "image-repository"
+	ISOBaseURL                 = "iso-base-url"
+	CacheMaxSize               = "cache.max-size"
+	AutoRestart                = "auto-restart"
+	EmbedCerts                 = "embed-certs"
+	ExtraConfig                = "extra-config"
+	DNSDomain                  = "dns-domain"
+	ContainerRuntime           = "container-runtime"
+	InsecureRegistry           = "insecure-registry"
+	DockerOpt                  = "docker-opt"
+	RegistryMirror             = "registry-mirror"
 )
 
+// DriverSettings are the settings which can be overridden for a single driver,
+// with keys of the form <setting>.<driver>, e.g. memory.virtualbox.
+var DriverSettings = []string{"memory", "cpus", "disk-size"}
+
 type MinikubeConfig map[string]interface{}
 
+// DriverKey returns the key overriding the named setting for the driver.
+func DriverKey(name, driver string) string {
+	return name + "." + driver
+}
+
+// SplitDriverKey splits a key overriding a setting for a driver into the
+// setting and driver names. ok is false if key is not such a key.
+func SplitDriverKey(key string) (name, driver string, ok bool) {
+	i := strings.LastIndex(key, ".")
+	if i < 0 || i == len(key)-1 {
+		return "", "", false
+	}
+	name, driver = key[:i], key[i+1:]
+	for _, s := range DriverSettings {
+		if s == name {
+			return name, driver, true
+		}
+	}
+	return "", "", false
+}
+
+// Get returns the value of the named setting: the one of its environment variable, if it is
+// set, else the one of the minikube config.
 func Get(name string) (string, error) {
+	if v := os.Getenv(EnvName(name)); v != "" {
+		return v, nil
+	}
 	m, err := ReadConfig()
 	if err != nil {
 		return "", err
@@ -48,29 +102,203 @@ func Get(name string) (string, error) {
 
 func get(name string, config MinikubeConfig) (string, error) {
 	if val, ok := config[name]; ok {
+		// Lists are shown the way the flags take them.
+		if _, ok := val.([]interface{}); ok {
+			return strings.Join(StoredList(config, name), ","), nil
+		}
 		return fmt.Sprintf("%v", val), nil
 	} else {
 		return "", errors.New("specified key could not be found in config")
 	}
 }
 
-// ReadConfig reads in the JSON minikube config
+// EnvName returns the environment variable overriding the named setting: MINIKUBE_ and the
+// name in upper case, with its - and . replaced with _, e.g. MINIKUBE_KUBERNETES_VERSION.
+func EnvName(name string) string {
+	return constants.MinikubeEnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(name))
+}
+
+var envKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// EnvValues returns the values of the environment variables of the named settings which getenv has, by name.
+func EnvValues(names []string, getenv func(string) string) map[string]string {
+	env := map[string]string{}
+	for _, name := range names {
+		if v := getenv(EnvName(name)); v != "" {
+			env[name] = v
+		}
+	}
+	return env
+}
+
+// StoredExtraConfig returns the extra config of the components kept in config,
+// in the form the --extra-config flag takes.
+func StoredExtraConfig(config MinikubeConfig) []string {
+	return StoredList(config, ExtraConfig)
+}
+
+// StoredInsecureRegistries returns the insecure registries kept in config.
+func StoredInsecureRegistries(config MinikubeConfig) []string {
+	return StoredList(config, InsecureRegistry)
+}
+
+// StoredList returns the list kept in config under key, which is decoded from JSON as a list of interfaces.
+func StoredList(config MinikubeConfig, key string) []string {
+	var values []string
+	switch v := config[key].(type) {
+	case []string:
+		values = v
+	case []interface{}:
+		for _, e := range v {
+			values = append(values, fmt.Sprintf("%v", e))
+		}
+	}
+	return values
+}
+
+// GlobalSettings are the settings every profile shares, which are kept in the global config file.
+var GlobalSettings = []string{
+	MachineProfile, WantUpdateNotification, ReminderWaitPeriodInHours, WantBetaUpdateNotification, ReleasesURL,
+	WantReportError, WantReportErrorPrompt, WantKubectlDownloadMsg, CacheMaxSize, "v", "log_dir",
+}
+
+// IsGlobalSetting returns whether every profile shares the setting.
+func IsGlobalSetting(name string) bool {
+	for _, s := range GlobalSettings {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// profileNameRegexp matches the names of hosts, which the VMs of the profiles are named after.
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// ValidateProfileName checks that name is a valid host name, as the profile's VM is named after it.
+func ValidateProfileName(name string) error {
+	if !profileNameRegexp.MatchString(name) {
+		return fmt.Errorf("Invalid profile name %q: it has to be a valid host name, of up to 63 letters, digits and hyphens, starting and ending with a letter or digit", name)
+	}
+	return nil
+}
+
+// ReadConfig reads in the JSON minikube config of the current profile
 func ReadConfig() (MinikubeConfig, error) {
-	f, err := os.Open(constants.ConfigFile)
+	return ReadProfileConfig(GetMachineName())
+}
+
+// ReadProfileConfig reads in the config of the named profile: its own settings, along with
+// the global settings of the global config file.
+func ReadProfileConfig(profile string) (MinikubeConfig, error) {
+	global, err := ReadConfigFile(constants.ConfigFile)
+	if err != nil || profile == constants.DefaultMachineName {
+		return global, err
+	}
+	m, err := ReadConfigFile(constants.ProfileConfigFile(profile))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range global {
+		if IsGlobalSetting(k) {
+			m[k] = v
+		}
+	}
+	return m, nil
+}
+
+// ReadConfigFile reads in a JSON minikube config file, which is empty if it doesn't exist.
+func ReadConfigFile(path string) (MinikubeConfig, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return make(map[string]interface{}), nil
 		}
-		return nil, fmt.Errorf("Could not open file %s: %s", constants.ConfigFile, err)
+		return nil, fmt.Errorf("Could not open file %s: %s", path, err)
 	}
+	defer f.Close()
 	m, err := decode(f)
 	if err != nil {
-		return nil, fmt.Errorf("Could not decode config %s: %s", constants.ConfigFile, err)
+		return nil, fmt.Errorf("Could not decode config %s: %s", path, err)
+	}
+	if m == nil {
+		m = make(map[string]interface{})
 	}
-
 	return m, nil
 }
 
+// ListProfiles returns the default profile and the profiles which have a config directory, sorted.
+func ListProfiles() ([]string, error) {
+	profiles := []string{constants.DefaultMachineName}
+	dirs, err := ioutil.ReadDir(filepath.Join(filepath.Dir(constants.ConfigFile), "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, d := range dirs {
+		if d.IsDir() && d.Name() != constants.DefaultMachineName {
+			profiles = append(profiles, d.Name())
+		}
+	}
+	sort.Strings(profiles[1:])
+	return profiles, nil
+}
+
+// GetActiveProfile returns the profile minikube profile recorded as the active one, or "" if none is.
+func GetActiveProfile() (string, error) {
+	data, err := ioutil.ReadFile(constants.ActiveProfileFile())
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetActiveProfile records profile as the active one.
+func SetActiveProfile(profile string) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	path := constants.ActiveProfileFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(profile+"\n"), 0644)
+}
+
+// ResetActiveProfile makes the default profile the active one again if the named one is,
+// as when it is deleted. It returns whether it did.
+func ResetActiveProfile(profile string) (bool, error) {
+	active, err := GetActiveProfile()
+	if err != nil || active != profile || profile == constants.DefaultMachineName {
+		return false, err
+	}
+	return true, SetActiveProfile(constants.DefaultMachineName)
+}
+
+// ResolveProfile returns the profile the commands act on: the one of $MINIKUBE_PROFILE, else
+// the one of the --profile flag if it was given, else the active one, else the one set with
+// minikube config set profile, else the default one.
+func ResolveProfile(flag string, flagChanged bool) string {
+	if profile := os.Getenv(constants.MinikubeEnvPrefix + "_PROFILE"); profile != "" {
+		return profile
+	}
+	if flagChanged {
+		return flag
+	}
+	if profile, err := GetActiveProfile(); err != nil {
+		glog.Warningf("Error reading the active profile: %s", err)
+	} else if profile != "" {
+		return profile
+	}
+	if m, err := ReadConfigFile(constants.ConfigFile); err == nil {
+		if profile, ok := m[MachineProfile].(string); ok && profile != "" {
+			return profile
+		}
+	}
+	return constants.DefaultMachineName
+}
+
 func decode(r io.Reader) (MinikubeConfig, error) {
 	var data MinikubeConfig
 	err := json.NewDecoder(r).Decode(&data)
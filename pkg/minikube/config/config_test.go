@@ -18,8 +18,15 @@ package config
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/tests"
 )
 
 type configTestCase struct {
@@ -70,7 +77,8 @@ func TestReadConfig(t *testing.T) {
 
 func TestGet(t *testing.T) {
 	cfg := `{
-		"key": "val"
+		"key": "val",
+		"list": ["a:5000", "10.0.0.0/24"]
 	}`
 
 	config, err := decode(bytes.NewBufferString(cfg))
@@ -84,6 +92,7 @@ func TestGet(t *testing.T) {
 		err bool
 	}{
 		{"key", "val", false},
+		{"list", "a:5000,10.0.0.0/24", false},
 		{"badkey", "", true},
 	}
 
@@ -99,3 +108,242 @@ func TestGet(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitDriverKey(t *testing.T) {
+	var tests = []struct {
+		key    string
+		name   string
+		driver string
+		ok     bool
+	}{
+		{key: "memory.virtualbox", name: "memory", driver: "virtualbox", ok: true},
+		{key: "disk-size.hyperkit", name: "disk-size", driver: "hyperkit", ok: true},
+		{key: "cpus.none", name: "cpus", driver: "none", ok: true},
+		{key: "memory"},
+		{key: "memory."},
+		{key: "iso-url.virtualbox"},
+	}
+	for _, test := range tests {
+		name, driver, ok := SplitDriverKey(test.key)
+		if name != test.name || driver != test.driver || ok != test.ok {
+			t.Errorf("SplitDriverKey(%q) = %q, %q, %v, expected %q, %q, %v", test.key, name, driver, ok, test.name, test.driver, test.ok)
+		}
+	}
+}
+
+// useTempMinikubeHome points MINIKUBE_HOME and the global config file at a temp dir,
+// returning a function to remove it and restore them.
+func useTempMinikubeHome(t *testing.T) (string, func()) {
+	dir := tests.MakeTempDir()
+	configFile := constants.ConfigFile
+	constants.ConfigFile = filepath.Join(dir, "config", "config.json")
+	return dir, func() {
+		constants.ConfigFile = configFile
+		os.Unsetenv(constants.MinikubeHome)
+		os.RemoveAll(filepath.Dir(dir))
+	}
+}
+
+func TestProfilePaths(t *testing.T) {
+	dir, cleanup := useTempMinikubeHome(t)
+	defer cleanup()
+
+	var tests = []struct {
+		profile    string
+		configFile string
+		cert       string
+	}{
+		{
+			profile:    "minikube",
+			configFile: filepath.Join(dir, "config", "config.json"),
+			cert:       filepath.Join(dir, "apiserver.crt"),
+		},
+		{
+			profile:    "project",
+			configFile: filepath.Join(dir, "config", "profiles", "project", "config.json"),
+			cert:       filepath.Join(dir, "profiles", "project", "apiserver.crt"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.profile, func(t *testing.T) {
+			if got := constants.ProfileConfigFile(test.profile); got != test.configFile {
+				t.Errorf("Expected the config file %s, got %s", test.configFile, got)
+			}
+			if got := constants.MakeProfilePath(test.profile, "apiserver.crt"); got != test.cert {
+				t.Errorf("Expected the certificate %s, got %s", test.cert, got)
+			}
+		})
+	}
+}
+
+func writeConfigFile(t *testing.T, path, data string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Error creating dir: %s", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Error writing %s: %s", path, err)
+	}
+}
+
+func TestReadProfileConfig(t *testing.T) {
+	_, cleanup := useTempMinikubeHome(t)
+	defer cleanup()
+	writeConfigFile(t, constants.ConfigFile, `{"memory": 4096, "WantUpdateNotification": false, "profile": "project"}`)
+	writeConfigFile(t, constants.ProfileConfigFile("project"), `{"cpus": 4, "ingress": true}`)
+
+	var tests = []struct {
+		profile  string
+		expected MinikubeConfig
+	}{
+		{
+			profile:  "minikube",
+			expected: MinikubeConfig{"memory": 4096.0, "WantUpdateNotification": false, "profile": "project"},
+		},
+		{
+			// The default profile's own settings don't leak into the other profiles.
+			profile:  "project",
+			expected: MinikubeConfig{"cpus": 4.0, "ingress": true, "WantUpdateNotification": false, "profile": "project"},
+		},
+		{
+			profile:  "other",
+			expected: MinikubeConfig{"WantUpdateNotification": false, "profile": "project"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.profile, func(t *testing.T) {
+			m, err := ReadProfileConfig(test.profile)
+			if err != nil {
+				t.Fatalf("Error reading config: %s", err)
+			}
+			if !reflect.DeepEqual(m, test.expected) {
+				t.Errorf("Expected config %v, got %v", test.expected, m)
+			}
+		})
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	_, cleanup := useTempMinikubeHome(t)
+	defer cleanup()
+	for _, p := range []string{"web", "api"} {
+		writeConfigFile(t, constants.ProfileConfigFile(p), `{}`)
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("Error listing profiles: %s", err)
+	}
+	if expected := []string{"minikube", "api", "web"}; !reflect.DeepEqual(profiles, expected) {
+		t.Errorf("Expected profiles %v, got %v", expected, profiles)
+	}
+}
+
+func TestGetMachineName(t *testing.T) {
+	defer viper.Reset()
+	if name := GetMachineName(); name != constants.DefaultMachineName {
+		t.Errorf("Expected the default machine name, got %s", name)
+	}
+	viper.Set(MachineProfile, "project")
+	if name := GetMachineName(); name != "project" {
+		t.Errorf("Expected machine name project, got %s", name)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	_, cleanup := useTempMinikubeHome(t)
+	defer cleanup()
+
+	if profile, err := GetActiveProfile(); err != nil || profile != "" {
+		t.Fatalf("Expected no active profile, got %q, %v", profile, err)
+	}
+	if err := SetActiveProfile("-project"); err == nil {
+		t.Error("Expected an error recording an invalid profile")
+	}
+	if err := SetActiveProfile("project"); err != nil {
+		t.Fatalf("Error recording the active profile: %s", err)
+	}
+	if profile, err := GetActiveProfile(); err != nil || profile != "project" {
+		t.Errorf("Expected the active profile project, got %q, %v", profile, err)
+	}
+
+	if reset, err := ResetActiveProfile("other"); err != nil || reset {
+		t.Errorf("Expected the active profile to be kept deleting another one, got %t, %v", reset, err)
+	}
+	if reset, err := ResetActiveProfile("project"); err != nil || !reset {
+		t.Errorf("Expected the active profile to be reset, got %t, %v", reset, err)
+	}
+	if profile, err := GetActiveProfile(); err != nil || profile != constants.DefaultMachineName {
+		t.Errorf("Expected the default active profile, got %q, %v", profile, err)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	_, cleanup := useTempMinikubeHome(t)
+	defer cleanup()
+	defer os.Unsetenv("MINIKUBE_PROFILE")
+
+	var tests = []struct {
+		description string
+		env         string
+		flag        string
+		active      string
+		config      string
+		expected    string
+	}{
+		{
+			description: "default",
+			expected:    "minikube",
+		},
+		{
+			description: "config",
+			config:      `{"profile": "configured"}`,
+			expected:    "configured",
+		},
+		{
+			description: "active",
+			active:      "active",
+			config:      `{"profile": "configured"}`,
+			expected:    "active",
+		},
+		{
+			description: "flag",
+			flag:        "flag",
+			active:      "active",
+			expected:    "flag",
+		},
+		{
+			description: "env",
+			env:         "env",
+			flag:        "flag",
+			active:      "active",
+			expected:    "env",
+		},
+		{
+			description: "default flag",
+			flag:        "minikube",
+			active:      "active",
+			expected:    "minikube",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			os.Setenv("MINIKUBE_PROFILE", test.env)
+			os.Remove(constants.ActiveProfileFile())
+			os.Remove(constants.ConfigFile)
+			if test.active != "" {
+				if err := SetActiveProfile(test.active); err != nil {
+					t.Fatalf("Error recording the active profile: %s", err)
+				}
+			}
+			if test.config != "" {
+				writeConfigFile(t, constants.ConfigFile, test.config)
+			}
+			if profile := ResolveProfile(test.flag, test.flag != ""); profile != test.expected {
+				t.Errorf("Expected profile %s, got %s", test.expected, profile)
+			}
+		})
+	}
+}
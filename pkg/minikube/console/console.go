@@ -0,0 +1,193 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package console separates what minikube tells its user from its diagnostic logs. The user
+// output goes to stdout, and the warnings and errors meant for the user to stderr, whatever
+// the verbosity. The diagnostic logs go to glog, at the verbosity levels below, which
+// --alsologtostderr also writes to stderr. --interactive sets whether minikube may prompt,
+// and --quiet whether it writes its progress output.
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// The verbosity levels of the diagnostic logs, set with -v.
+const (
+	// LevelPhase logs the decisions of each phase, such as which ISO URL and apiserver cert SANs are used.
+	LevelPhase glog.Level = 1
+	// LevelCommand logs the command lines of the external commands run on the host and in the VM,
+	// and libmachine's logs.
+	LevelCommand glog.Level = 3
+	// LevelTranscript logs the output of those commands, making up full SSH transcripts, and
+	// libmachine's debug logs.
+	LevelTranscript glog.Level = 7
+)
+
+var (
+	mu          sync.Mutex
+	outWriter   io.Writer = os.Stdout
+	errWriter   io.Writer = os.Stderr
+	interactive           = IsTerminal(os.Stdout)
+	quiet       bool
+)
+
+// SetOutWriter sets where the user output is written, stdout by default.
+func SetOutWriter(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	outWriter = w
+}
+
+// SetErrWriter sets where the warnings and errors meant for the user are written, stderr by default.
+func SetErrWriter(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	errWriter = w
+}
+
+// OutWriter returns where the user output is written, for the functions writing to an io.Writer.
+func OutWriter() io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	return outWriter
+}
+
+// ErrWriter returns where the warnings and errors meant for the user are written.
+func ErrWriter() io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	return errWriter
+}
+
+// IsTerminal returns whether w is a terminal, the only writer progress bars and spinners may
+// write control characters to.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// SetInteractive sets whether minikube may prompt its user, by default only when stdout is a terminal.
+func SetInteractive(i bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	interactive = i
+}
+
+// Interactive returns whether minikube may prompt its user. When it may not, each prompt
+// takes its default answer, or fails if it has none.
+func Interactive() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return interactive
+}
+
+// SetQuiet sets whether the progress output is suppressed, leaving the result and the errors.
+func SetQuiet(q bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	quiet = q
+}
+
+// Quiet returns whether the progress output is suppressed.
+func Quiet() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return quiet
+}
+
+// ProgressWriter returns where the progress output is written, which is the user output
+// unless it is quiet.
+func ProgressWriter() io.Writer {
+	if Quiet() {
+		return ioutil.Discard
+	}
+	return OutWriter()
+}
+
+// Progress writes a line of progress output, such as the phase a command is in, as
+// fmt.Println does, unless it is quiet.
+func Progress(a ...interface{}) {
+	fmt.Fprintln(ProgressWriter(), a...)
+}
+
+// Out writes the user output, formatted as fmt.Printf does.
+func Out(format string, a ...interface{}) {
+	fmt.Fprintf(OutWriter(), format, a...)
+}
+
+// OutLn writes a line of user output, as fmt.Println does.
+func OutLn(a ...interface{}) {
+	fmt.Fprintln(OutWriter(), a...)
+}
+
+// Err writes a warning or error meant for the user, formatted as fmt.Printf does.
+func Err(format string, a ...interface{}) {
+	fmt.Fprintf(ErrWriter(), format, a...)
+}
+
+// ErrLn writes a line of warning or error meant for the user, as fmt.Println does.
+func ErrLn(a ...interface{}) {
+	fmt.Fprintln(ErrWriter(), a...)
+}
+
+// Phase logs a decision of a phase, such as which ISO URL is used, at LevelPhase.
+func Phase(format string, a ...interface{}) {
+	if glog.V(LevelPhase) {
+		glog.InfoDepth(1, fmt.Sprintf(format, a...))
+	}
+}
+
+// logWriter logs each line written to it at its level.
+type logWriter struct {
+	level glog.Level
+	mu    sync.Mutex
+	buf   []byte
+}
+
+// LogWriter returns a writer logging each line written to it at level, for the libraries
+// which log to an io.Writer, such as libmachine.
+func LogWriter(level glog.Level) io.Writer {
+	return &logWriter{level: level}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	// The last line isn't logged until its newline is written.
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buf[:i]), "\r")
+		w.buf = w.buf[i+1:]
+		if glog.V(w.level) {
+			glog.InfoDepth(1, line)
+		}
+	}
+	return len(p), nil
+}
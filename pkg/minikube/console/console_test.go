@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package console
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/glog"
+)
+
+var logDir string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	dir, err := ioutil.TempDir("", "console")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logDir = dir
+	flag.Set("log_dir", dir)
+	// The log file is created by the first line logged.
+	glog.Info("Testing the console")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// TestHelperProcess is the external command the tests run, writing to its stdout and stderr.
+func TestHelperProcess(t *testing.T) {
+	switch os.Getenv("GO_WANT_HELPER_PROCESS") {
+	case "1":
+	case "fail":
+		fmt.Fprintln(os.Stderr, "helper failed", os.Args[len(os.Args)-1])
+		os.Exit(3)
+	case "interactive":
+		fmt.Fprintf(os.Stdout, "interactive=%t terminal=%t\n", Interactive(), IsTerminal(OutWriter()))
+		os.Exit(0)
+	default:
+		return
+	}
+	fmt.Fprintln(os.Stdout, "helper stdout", os.Args[len(os.Args)-1])
+	fmt.Fprintln(os.Stderr, "helper stderr", os.Args[len(os.Args)-1])
+	os.Exit(0)
+}
+
+func helperCommand(marker string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", marker)
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	return cmd
+}
+
+func readLog(t *testing.T) string {
+	glog.Flush()
+	data, err := ioutil.ReadFile(filepath.Join(logDir, filepath.Base(os.Args[0])+".INFO"))
+	if err != nil {
+		t.Fatalf("Error reading the log: %s", err)
+	}
+	return string(data)
+}
+
+func TestOutputStableAcrossVerbosity(t *testing.T) {
+	defer SetOutWriter(os.Stdout)
+	defer SetErrWriter(os.Stderr)
+	defer flag.Set("v", "0")
+
+	var tests = []struct {
+		verbosity string
+		// logged are the markers expected in the log, the others are expected not to be.
+		logged []string
+	}{
+		{verbosity: "0"},
+		{verbosity: "1", logged: []string{"phase"}},
+		{verbosity: "3", logged: []string{"phase", "command", "libmachine"}},
+		{verbosity: "7", logged: []string{"phase", "command", "libmachine", "transcript"}},
+	}
+
+	for _, test := range tests {
+		t.Run("v="+test.verbosity, func(t *testing.T) {
+			flag.Set("v", test.verbosity)
+			var out, errOut bytes.Buffer
+			SetOutWriter(&out)
+			SetErrWriter(&errOut)
+			marker := "v" + test.verbosity
+
+			OutLn("Starting local Kubernetes cluster...")
+			Phase("phase-%s: using the ISO of the default URL", marker)
+			cmd := helperCommand(marker)
+			cmdOut, err := Run(cmd)
+			if err != nil {
+				t.Fatalf("Error running the helper: %s", err)
+			}
+			LogWriter(LevelCommand).Write([]byte("libmachine-" + marker + " (minikube) Starting the VM\npartial"))
+			Err("Warning: %s\n", "the proxy is on localhost")
+			Out("Machine %s.\n", "started")
+
+			if expected := "Starting local Kubernetes cluster...\nMachine started.\n"; out.String() != expected {
+				t.Errorf("Expected the output %q, got %q", expected, out.String())
+			}
+			if expected := "Warning: the proxy is on localhost\n"; errOut.String() != expected {
+				t.Errorf("Expected the errors %q, got %q", expected, errOut.String())
+			}
+			if !strings.Contains(string(cmdOut), "helper stdout "+marker) {
+				t.Errorf("Expected the command's output to be returned, got %q", cmdOut)
+			}
+
+			log := readLog(t)
+			expected := map[string]string{
+				"phase":      "phase-" + marker,
+				"command":    "Running on the host: " + strings.Join(cmd.Args, " "),
+				"libmachine": "libmachine-" + marker,
+				"transcript": "helper stderr " + marker,
+			}
+			logged := map[string]bool{}
+			for _, l := range test.logged {
+				logged[l] = true
+			}
+			for name, line := range expected {
+				if found := strings.Contains(log, line); found != logged[name] {
+					t.Errorf("Expected %s logged: %t, got %t", name, logged[name], found)
+				}
+			}
+			if strings.Contains(log, "partial") {
+				t.Error("Expected the incomplete line not to be logged")
+			}
+		})
+	}
+}
+
+func TestNotInteractiveWhenPiped(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=interactive")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Error running the helper: %s", err)
+	}
+	if expected := "interactive=false terminal=false\n"; string(out) != expected {
+		t.Errorf("Expected %q with a piped stdout, got %q", expected, out)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	defer SetOutWriter(os.Stdout)
+	defer SetQuiet(false)
+
+	var tests = []struct {
+		quiet    bool
+		expected string
+	}{
+		{expected: "Stopping local Kubernetes cluster...\nMachine stopped.\n"},
+		{quiet: true, expected: "Machine stopped.\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("quiet=%t", test.quiet), func(t *testing.T) {
+			var out bytes.Buffer
+			SetOutWriter(&out)
+			SetQuiet(test.quiet)
+			Progress("Stopping local Kubernetes cluster...")
+			OutLn("Machine stopped.")
+			if out.String() != test.expected {
+				t.Errorf("Expected the output %q, got %q", test.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/report"
+)
+
+// maxTraceOutput is how much of the output of a command its trace keeps, from the end, where the errors are.
+const maxTraceOutput = 16 * 1024
+
+// Trace is the record of an external command run on the host or in the VM, a line of JSON of the
+// trace file. Its command line, output and error are redacted.
+type Trace struct {
+	Time            time.Time `json:"time"`
+	Where           string    `json:"where"`
+	Command         string    `json:"command"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	// ExitCode is -1 when the command failed without one, as when it couldn't be started.
+	ExitCode        int    `json:"exitCode"`
+	Error           string `json:"error,omitempty"`
+	Output          string `json:"output,omitempty"`
+	OutputTruncated bool   `json:"outputTruncated,omitempty"`
+}
+
+var (
+	traceMu sync.Mutex
+	// traceOut is where the traces are appended, nowhere unless SetTraceFile is called.
+	traceOut io.Writer
+	// redactor replaces the secrets of the command lines and outputs, as the private keys and
+	// registry passwords the provisioning writes, before they are logged or traced.
+	redactor = report.NewRedactor()
+)
+
+// SetTraceFile has the trace of every command appended to the file at path, as JSON lines.
+func SetTraceFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error opening the trace file")
+	}
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	traceOut = f
+	return nil
+}
+
+func redact(s string) string {
+	return string(redactor.Redact([]byte(s)))
+}
+
+// CommandRun is an external command being run, which Done records once it ended.
+type CommandRun struct {
+	where   string
+	command string
+	start   time.Time
+}
+
+// StartCommand logs the command line of an external command run where, the host or the VM, at
+// LevelCommand, and returns its run.
+func StartCommand(where, command string) *CommandRun {
+	r := &CommandRun{where: where, command: redact(command), start: time.Now()}
+	if glog.V(LevelCommand) {
+		glog.InfoDepth(1, fmt.Sprintf("Running on %s: %s", where, r.command))
+	}
+	return r
+}
+
+// Done logs the output of the command and how it ended at LevelTranscript, and its failure at
+// LevelCommand, and appends its trace to the trace file.
+func (r *CommandRun) Done(out []byte, err error) {
+	out = redactor.Redact(out)
+	var errText string
+	if err != nil {
+		errText = redact(err.Error())
+		if glog.V(LevelCommand) {
+			glog.InfoDepth(1, fmt.Sprintf("%s failed after %s: %s", r.command, time.Since(r.start), errText))
+		}
+	}
+	if glog.V(LevelTranscript) && len(strings.TrimSpace(string(out))) > 0 {
+		glog.InfoDepth(1, fmt.Sprintf("Output of %s:\n%s", r.command, strings.TrimRight(string(out), "\n")))
+	}
+
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if traceOut == nil {
+		return
+	}
+	t := Trace{
+		Time:            r.start.UTC(),
+		Where:           r.where,
+		Command:         r.command,
+		DurationSeconds: time.Since(r.start).Seconds(),
+		ExitCode:        exitCode(err),
+		Error:           errText,
+	}
+	if len(out) > maxTraceOutput {
+		out = out[len(out)-maxTraceOutput:]
+		t.OutputTruncated = true
+	}
+	t.Output = string(out)
+	data, err := json.Marshal(t)
+	if err != nil {
+		glog.Errorf("Error encoding the trace of %s: %s", r.command, err)
+		return
+	}
+	if _, err := traceOut.Write(append(data, '\n')); err != nil {
+		glog.Errorf("Error writing the trace of %s: %s", r.command, err)
+	}
+}
+
+// exitCode returns the exit code of the command which ended with err, 0 without an error, and -1
+// if err has none.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	// As the SSH errors, and the errors of commands wrapping them.
+	if e, ok := errors.Cause(err).(interface {
+		ExitStatus() int
+	}); ok {
+		return e.ExitStatus()
+	}
+	if e, ok := errors.Cause(err).(*exec.ExitError); ok {
+		if status, ok := e.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus()
+		}
+	}
+	return -1
+}
+
+// Run runs the command on the host and returns its combined output, which is logged rather than
+// written to the terminal.
+func Run(cmd *exec.Cmd) ([]byte, error) {
+	r := StartCommand("the host", strings.Join(cmd.Args, " "))
+	out, err := cmd.CombinedOutput()
+	r.Done(out, err)
+	return out, err
+}
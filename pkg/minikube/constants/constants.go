@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
 
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
@@ -35,15 +38,115 @@ const (
 
 const MinikubeHome = "MINIKUBE_HOME"
 
+// MinikubeCacheDir relocates the cache, the ISOs, images and localkube binaries minikube
+// downloads, out of the minikube dir.
+const MinikubeCacheDir = "MINIKUBE_CACHE_DIR"
+
+// The XDG base directories, which are honored on Linux when MINIKUBE_HOME is unset.
+const (
+	xdgDataHome  = "XDG_DATA_HOME"
+	xdgCacheHome = "XDG_CACHE_HOME"
+)
+
+// CacheSubdirs are the directories of the cache, each holding one kind of artifact.
+var CacheSubdirs = []string{"iso", "images", "localkube"}
+
 // Minipath is the path to the user's minikube dir
 func GetMinipath() string {
-	if os.Getenv(MinikubeHome) == "" {
+	return resolveLocalDirs(os.Getenv, runtime.GOOS, defaultMinipathExists()).home
+}
+
+// GetCacheDir returns the directory the cache is kept in: MINIKUBE_CACHE_DIR if it is set,
+// and the cache dir of the minikube dir otherwise.
+func GetCacheDir() string {
+	return resolveLocalDirs(os.Getenv, runtime.GOOS, defaultMinipathExists()).cache
+}
+
+// MakeCachePath returns the path of a file of the cache, as MakeMiniPath does for the minikube dir.
+func MakeCachePath(fileName ...string) string {
+	return filepath.Join(append([]string{GetCacheDir()}, fileName...)...)
+}
+
+// localDirs are the directories minikube keeps its state and its cache in.
+type localDirs struct {
+	home  string
+	cache string
+}
+
+// resolveLocalDirs resolves the minikube dir and the cache dir from the environment. On Linux,
+// with MINIKUBE_HOME unset, their XDG base directories are used when they are set, unless
+// ~/.minikube exists already, so that an existing install keeps its layout until it's moved.
+func resolveLocalDirs(getenv func(string) string, goos string, defaultExists bool) localDirs {
+	xdg := func(env string) string {
+		if goos != "linux" || getenv(MinikubeHome) != "" || defaultExists || getenv(env) == "" {
+			return ""
+		}
+		return filepath.Join(filepath.Clean(getenv(env)), "minikube")
+	}
+	dirs := localDirs{home: minipath(getenv(MinikubeHome))}
+	if home := xdg(xdgDataHome); home != "" {
+		dirs.home = home
+	}
+	switch {
+	case getenv(MinikubeCacheDir) != "":
+		dirs.cache = filepath.Clean(getenv(MinikubeCacheDir))
+	case xdg(xdgCacheHome) != "":
+		dirs.cache = xdg(xdgCacheHome)
+	default:
+		dirs.cache = filepath.Join(dirs.home, "cache")
+	}
+	return dirs
+}
+
+// defaultMinipath records whether ~/.minikube exists, which is checked once per process.
+var defaultMinipath struct {
+	once   sync.Once
+	exists bool
+}
+
+// defaultMinipathExists returns whether ~/.minikube exists. It is checked once, so that the dirs
+// stay the same for the whole process, even after it creates ~/.minikube, and so it warns once
+// when the XDG base directories are ignored although minikube has a dir there as well.
+func defaultMinipathExists() bool {
+	defaultMinipath.once.Do(func() {
+		_, err := os.Stat(DefaultMinipath)
+		defaultMinipath.exists = err == nil
+		exists := func(dir string) bool {
+			_, err := os.Stat(dir)
+			return err == nil
+		}
+		if ignored := ignoredXDGHome(os.Getenv, runtime.GOOS, defaultMinipath.exists, exists); ignored != "" {
+			fmt.Fprintf(os.Stderr, "Warning: Both %s and %s exist, %s is used. Remove one of them, or set %s to the one to use.\n",
+				DefaultMinipath, ignored, DefaultMinipath, MinikubeHome)
+		}
+	})
+	return defaultMinipath.exists
+}
+
+// ignoredXDGHome returns the minikube dir under the XDG base directories, if it exists and is
+// ignored, as ~/.minikube exists too.
+func ignoredXDGHome(getenv func(string) string, goos string, defaultExists bool, exists func(string) bool) string {
+	if !defaultExists {
+		return ""
+	}
+	used, ignored := resolveLocalDirs(getenv, goos, true).home, resolveLocalDirs(getenv, goos, false).home
+	if ignored == used || !exists(ignored) {
+		return ""
+	}
+	return ignored
+}
+
+// minipath returns the minikube dir of the MINIKUBE_HOME home. It is cleaned, so that the paths
+// made from it don't carry a trailing separator, or on Windows forward slashes, along.
+func minipath(home string) string {
+	if home == "" {
 		return DefaultMinipath
 	}
-	if filepath.Base(os.Getenv(MinikubeHome)) == ".minikube" {
-		return os.Getenv(MinikubeHome)
+	home = filepath.Clean(home)
+	if filepath.Base(home) == ".minikube" {
+		return home
 	}
-	return filepath.Join(os.Getenv(MinikubeHome), ".minikube")
+	return filepath.Join(home, ".minikube")
 }
 
 var DefaultMinipath = filepath.Join(homedir.HomeDir(), ".minikube")
@@ -63,6 +166,9 @@ const MinikubeEnvPrefix = "MINIKUBE"
 // DefaultMachineName is the default name for the VM
 const DefaultMachineName = "minikube"
 
+// MachineDoesNotExist is the status reported for a machine that has not been created
+const MachineDoesNotExist = "Does Not Exist"
+
 // The name of the default storage class provisioner
 const DefaultStorageClassProvisioner = "standard"
 
@@ -73,8 +179,6 @@ func MakeMiniPath(fileName ...string) string {
 	return filepath.Join(args...)
 }
 
-var MountProcessFileName = ".mount-process"
-
 // Only pass along these flags to localkube.
 var LogFlags = [...]string{
 	"v",
@@ -88,23 +192,77 @@ const (
 	DefaultCPUS         = 2
 	DefaultDiskSize     = "20g"
 	MinimumDiskSizeMB   = 2000
+	MinimumMemoryMB     = 512
 	DefaultVMDriver     = "virtualbox"
+	DefaultHostOnlyCIDR = "192.168.99.1/24"
+	DefaultKvmNetwork   = "default"
+	DefaultXhyveDisk    = "ahci-hd"
 	DefaultStatusFormat = "minikube: {{.MinikubeStatus}}\n" +
-		"localkube: {{.LocalkubeStatus}}\n"
+		"localkube: {{.LocalkubeStatus}}\n" +
+		"apiserver: {{.APIServer}}\n" +
+		"kubeconfig: {{.Kubeconfig}}\n" +
+		"{{if .LastStartError}}last start: {{.LastStartError}}\n{{end}}"
 	DefaultAddonListFormat    = "- {{.AddonName}}: {{.AddonStatus}}\n"
-	DefaultConfigViewFormat   = "- {{.ConfigKey}}: {{.ConfigValue}}\n"
+	DefaultConfigViewFormat   = "- {{.ConfigKey}}: {{.ConfigValue}} ({{.Source}})\n"
 	GithubMinikubeReleasesURL = "https://storage.googleapis.com/minikube/releases.json"
 	KubernetesVersionGCSURL   = "https://storage.googleapis.com/minikube/k8s_releases.json"
 )
 
+// DefaultMachineOpRetries is how many times a driver operation failing with a transient error is retried.
+const DefaultMachineOpRetries = 2
+
+// DefaultMachineOpBackoff is the wait before the first retry of a driver operation, which doubles for each following one.
+const DefaultMachineOpBackoff = 2 * time.Second
+
+// Extra disks can be attached to the VM with the virtualbox and kvm2 drivers.
+const (
+	DefaultExtraDiskSize = "10g"
+	MaximumExtraDisks    = 8
+)
+
+// DefaultWaitTimeout is how long minikube start waits for the VM before giving up on it.
+const DefaultWaitTimeout = 10 * time.Minute
+
+// DefaultStopTimeout is how long minikube stop waits for the VM to shut down.
+const DefaultStopTimeout = 2 * time.Minute
+
 var DefaultIsoUrl = fmt.Sprintf("https://storage.googleapis.com/%s/minikube-%s.iso", minikubeVersion.GetIsoPath(), minikubeVersion.GetIsoVersion())
 var DefaultIsoShaUrl = DefaultIsoUrl + ShaSuffix
 
 var DefaultKubernetesVersion = version.Get().GitVersion
 
+// KubernetesReleaseChannelURL holds the files the stable and latest Kubernetes versions are resolved from.
+var KubernetesReleaseChannelURL = "https://dl.k8s.io/release"
+
 var ConfigFilePath = MakeMiniPath("config")
 var ConfigFile = MakeMiniPath("config", "config.json")
 
+// ProfileConfigFile returns the config file of the named profile. The default profile keeps
+// its settings in ConfigFile, along with the settings every profile shares, while the other
+// profiles each have their own under config/profiles.
+func ProfileConfigFile(profile string) string {
+	if profile == DefaultMachineName {
+		return ConfigFile
+	}
+	return filepath.Join(filepath.Dir(ConfigFile), "profiles", profile, "config.json")
+}
+
+// ActiveProfileFile returns the file minikube profile records the active profile in, the one
+// the commands act on when no profile is given.
+func ActiveProfileFile() string {
+	return MakeMiniPath("active_profile")
+}
+
+// MakeProfilePath returns the path of a file of the named profile's cluster, such as its
+// certificates: in the minikube directory for the default profile, and in profiles/<name>
+// for the others.
+func MakeProfilePath(profile string, fileName ...string) string {
+	if profile == DefaultMachineName {
+		return MakeMiniPath(fileName...)
+	}
+	return MakeMiniPath(append([]string{"profiles", profile}, fileName...)...)
+}
+
 var LocalkubeDownloadURLPrefix = "https://storage.googleapis.com/minikube/k8sReleases/"
 var LocalkubeLinuxFilename = "localkube-linux-amd64"
 
@@ -115,12 +273,35 @@ const ReportingURL = "https://clouderrorreporting.googleapis.com/v1beta1/project
 
 const AddonsPath = "/etc/kubernetes/addons"
 
+const (
+	// RegistryNodePort is the node port of the registry addon, which the host pushes images to.
+	RegistryNodePort = 30500
+	// DefaultRegistryStorageSize is the size of the volume the registry addon stores its images in.
+	DefaultRegistryStorageSize = "5Gi"
+	// KubeletReadOnlyPort is the port the kubelet serves its summary API on, which the metrics addon collects from.
+	KubeletReadOnlyPort = 10255
+	// MetricsDashboardPath is the path of the grafana dashboard of the cluster's metrics, which minikube addons open metrics opens.
+	MetricsDashboardPath = "/dashboard/db/cluster"
+)
+
+// DefaultStorageProvisionerDir is the directory of the VM the volumes of the standard storage class are provisioned in by default.
+const DefaultStorageProvisionerDir = "/tmp/hostpath-provisioner"
+
+// PersistedVMDirs are the directories of the VM on its persisted disk, which the files in are kept across restarts.
+var PersistedVMDirs = []string{"/data", "/tmp/hostpath_pv", "/tmp/hostpath-provisioner"}
+
 const (
 	RemoteLocalKubeErrPath = "/var/lib/localkube/localkube.err"
 	RemoteLocalKubeOutPath = "/var/lib/localkube/localkube.out"
 	LocalkubePIDPath       = "/var/run/localkube.pid"
 )
 
+// The audit policy of a cluster started with --audit-policy, and the log the apiserver audits requests to.
+const (
+	RemoteAuditPolicyPath = "/var/lib/localkube/audit/policy.yaml"
+	RemoteAuditLogPath    = "/var/lib/localkube/audit/audit.log"
+)
+
 const (
 	LocalkubeServicePath = "/usr/lib/systemd/system/localkube.service"
 	LocalkubeRunning     = "active"
@@ -133,4 +314,13 @@ const (
 	DefaultMountEndpoint = "/minikube-host"
 )
 
+// SharedFolderName is the name of the host folder shared natively with the VM, which it is mounted by.
+const SharedFolderName = "minikube-share"
+
 const IsMinikubeChildProcess = "IS_MINIKUBE_CHILD_PROCESS"
+
+// PauseImage is the image of the containers holding the pods' namespaces, which the kubelet in localkube defaults to.
+const PauseImage = "gcr.io/google_containers/pause-amd64:3.0"
+
+// DNSCheckImage is the image of the pod start looks up the cluster's DNS domain in.
+const DNSCheckImage = "gcr.io/google_containers/busybox:1.24"
@@ -22,6 +22,8 @@ var SupportedVMDrivers = [...]string{
 	"virtualbox",
 	"vmwarefusion",
 	"kvm",
+	"kvm2",
 	"xhyve",
+	"hyperkit",
 	"hyperv",
 }
@@ -0,0 +1,33 @@
+// +build !windows
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package constants
+
+// platformMinipathTests are the MINIKUBE_HOME values only the other platforms than Windows have.
+var platformMinipathTests = []minipathTest{
+	{
+		description: "absolute",
+		home:        "/home/alice//minikube/",
+		expected:    "/home/alice/minikube/.minikube",
+	},
+	{
+		description: "backslashes",
+		home:        `/home/first\last`,
+		expected:    `/home/first\last/.minikube`,
+	},
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package constants
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type minipathTest struct {
+	description string
+	home        string
+	expected    string
+}
+
+func TestMinipath(t *testing.T) {
+	var tests = []minipathTest{
+		{
+			description: "unset",
+			expected:    DefaultMinipath,
+		},
+		{
+			description: "home",
+			home:        filepath.Join("home", "alice"),
+			expected:    filepath.Join("home", "alice", ".minikube"),
+		},
+		{
+			description: "minikube dir",
+			home:        filepath.Join("home", "alice", ".minikube"),
+			expected:    filepath.Join("home", "alice", ".minikube"),
+		},
+		{
+			description: "trailing separator",
+			home:        filepath.Join("home", "alice", ".minikube") + string(filepath.Separator),
+			expected:    filepath.Join("home", "alice", ".minikube"),
+		},
+		{
+			description: "spaces",
+			home:        filepath.Join("home", "First Last"),
+			expected:    filepath.Join("home", "First Last", ".minikube"),
+		},
+		{
+			description: "non-ascii",
+			home:        filepath.Join("home", "Jürgen Müller"),
+			expected:    filepath.Join("home", "Jürgen Müller", ".minikube"),
+		},
+	}
+	tests = append(tests, platformMinipathTests...)
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if got := minipath(test.home); got != test.expected {
+				t.Errorf("Expected the minikube dir %q of %q, got %q", test.expected, test.home, got)
+			}
+		})
+	}
+}
+
+func TestResolveLocalDirs(t *testing.T) {
+	var tests = []struct {
+		description   string
+		env           map[string]string
+		goos          string
+		defaultExists bool
+		expected      localDirs
+	}{
+		{
+			description: "defaults",
+			goos:        "linux",
+			expected:    localDirs{home: DefaultMinipath, cache: filepath.Join(DefaultMinipath, "cache")},
+		},
+		{
+			description: "minikube home",
+			env:         map[string]string{MinikubeHome: filepath.Join("home", "alice")},
+			goos:        "linux",
+			expected:    localDirs{home: filepath.Join("home", "alice", ".minikube"), cache: filepath.Join("home", "alice", ".minikube", "cache")},
+		},
+		{
+			description: "cache dir",
+			env:         map[string]string{MinikubeCacheDir: filepath.Join("scratch", "minikube") + string(filepath.Separator)},
+			goos:        "darwin",
+			expected:    localDirs{home: DefaultMinipath, cache: filepath.Join("scratch", "minikube")},
+		},
+		{
+			description: "minikube home and cache dir",
+			env:         map[string]string{MinikubeHome: filepath.Join("home", "alice"), MinikubeCacheDir: filepath.Join("scratch", "minikube")},
+			goos:        "linux",
+			expected:    localDirs{home: filepath.Join("home", "alice", ".minikube"), cache: filepath.Join("scratch", "minikube")},
+		},
+		{
+			description: "xdg",
+			env:         map[string]string{xdgDataHome: filepath.Join("home", "alice", ".local", "share"), xdgCacheHome: filepath.Join("home", "alice", ".cache")},
+			goos:        "linux",
+			expected:    localDirs{home: filepath.Join("home", "alice", ".local", "share", "minikube"), cache: filepath.Join("home", "alice", ".cache", "minikube")},
+		},
+		{
+			description: "xdg cache only",
+			env:         map[string]string{xdgCacheHome: filepath.Join("home", "alice", ".cache")},
+			goos:        "linux",
+			expected:    localDirs{home: DefaultMinipath, cache: filepath.Join("home", "alice", ".cache", "minikube")},
+		},
+		{
+			description: "xdg and cache dir",
+			env:         map[string]string{xdgDataHome: filepath.Join("home", "alice", ".local", "share"), xdgCacheHome: filepath.Join("home", "alice", ".cache"), MinikubeCacheDir: filepath.Join("scratch", "minikube")},
+			goos:        "linux",
+			expected:    localDirs{home: filepath.Join("home", "alice", ".local", "share", "minikube"), cache: filepath.Join("scratch", "minikube")},
+		},
+		{
+			description:   "xdg with an existing install",
+			env:           map[string]string{xdgDataHome: filepath.Join("home", "alice", ".local", "share"), xdgCacheHome: filepath.Join("home", "alice", ".cache")},
+			goos:          "linux",
+			defaultExists: true,
+			expected:      localDirs{home: DefaultMinipath, cache: filepath.Join(DefaultMinipath, "cache")},
+		},
+		{
+			description: "xdg and minikube home",
+			env:         map[string]string{MinikubeHome: filepath.Join("home", "alice"), xdgDataHome: filepath.Join("home", "alice", ".local", "share"), xdgCacheHome: filepath.Join("home", "alice", ".cache")},
+			goos:        "linux",
+			expected:    localDirs{home: filepath.Join("home", "alice", ".minikube"), cache: filepath.Join("home", "alice", ".minikube", "cache")},
+		},
+		{
+			description: "xdg off linux",
+			env:         map[string]string{xdgDataHome: filepath.Join("home", "alice", ".local", "share"), xdgCacheHome: filepath.Join("home", "alice", ".cache")},
+			goos:        "darwin",
+			expected:    localDirs{home: DefaultMinipath, cache: filepath.Join(DefaultMinipath, "cache")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			getenv := func(name string) string { return test.env[name] }
+			if got := resolveLocalDirs(getenv, test.goos, test.defaultExists); got != test.expected {
+				t.Errorf("Expected the dirs %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestIgnoredXDGHome(t *testing.T) {
+	xdgEnv := map[string]string{xdgDataHome: filepath.Join("home", "alice", ".local", "share")}
+	xdgHome := filepath.Join("home", "alice", ".local", "share", "minikube")
+	var tests = []struct {
+		description   string
+		env           map[string]string
+		defaultExists bool
+		xdgExists     bool
+		expected      string
+	}{
+		{
+			description:   "both exist",
+			env:           xdgEnv,
+			defaultExists: true,
+			xdgExists:     true,
+			expected:      xdgHome,
+		},
+		{
+			description:   "only the existing install",
+			env:           xdgEnv,
+			defaultExists: true,
+		},
+		{
+			description: "only xdg",
+			env:         xdgEnv,
+			xdgExists:   true,
+		},
+		{
+			description:   "minikube home",
+			env:           map[string]string{MinikubeHome: filepath.Join("home", "alice"), xdgDataHome: xdgEnv[xdgDataHome]},
+			defaultExists: true,
+			xdgExists:     true,
+		},
+		{
+			description:   "xdg unset",
+			defaultExists: true,
+			xdgExists:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			getenv := func(name string) string { return test.env[name] }
+			exists := func(dir string) bool { return dir == xdgHome && test.xdgExists }
+			if got := ignoredXDGHome(getenv, "linux", test.defaultExists, exists); got != test.expected {
+				t.Errorf("Expected %q ignored, got %q", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package constants
+
+// platformMinipathTests are the MINIKUBE_HOME values of Windows: drive letters, UNC shares and
+// forward slashes.
+var platformMinipathTests = []minipathTest{
+	{
+		description: "drive letter with spaces",
+		home:        `C:\Users\First Last`,
+		expected:    `C:\Users\First Last\.minikube`,
+	},
+	{
+		description: "non-ascii user",
+		home:        `C:\Users\Jürgen\.minikube`,
+		expected:    `C:\Users\Jürgen\.minikube`,
+	},
+	{
+		description: "forward slashes",
+		home:        `C:/Users/First Last/.minikube/`,
+		expected:    `C:\Users\First Last\.minikube`,
+	},
+	{
+		description: "unc share",
+		home:        `\\fileserver\home\alice`,
+		expected:    `\\fileserver\home\alice\.minikube`,
+	},
+	{
+		description: "unc share root",
+		home:        `\\fileserver\home\`,
+		expected:    `\\fileserver\home\.minikube`,
+	},
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cruntime describes the container runtimes the kubelet can run the cluster's containers with.
+package cruntime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	Docker     = "docker"
+	Containerd = "containerd"
+	CRIO       = "cri-o"
+	Rkt        = "rkt"
+)
+
+// CgroupDriver is the cgroup driver the runtimes are configured with, which is the kubelet's default.
+const CgroupDriver = "cgroupfs"
+
+// Runtime is a container runtime, and how it is set up in the VM.
+type Runtime struct {
+	// Name is the --container-runtime the runtime is picked with.
+	Name string
+	// Service is the systemd unit running the runtime in the VM.
+	Service string
+	// Binary is the runtime's program, which the ISO has to ship.
+	Binary string
+	// Endpoint is the CRI socket the kubelet reaches the runtime on. The
+	// kubelet drives docker and rkt itself, so they have none.
+	Endpoint string
+	// ConfigPath is where the runtime's config is written in the VM, if minikube configures it.
+	ConfigPath string
+	// configTmpl is the runtime's config, formatted with the pods' sandbox image.
+	configTmpl string
+	// loadImageTmpl is the command loading an image tarball, formatted with its path.
+	loadImageTmpl string
+}
+
+var runtimes = map[string]Runtime{
+	Docker: {
+		Name:          Docker,
+		Service:       "docker",
+		Binary:        "docker",
+		loadImageTmpl: "docker load -i %s",
+	},
+	// rkt can't load docker image tarballs, they are loaded into docker as before.
+	Rkt: {
+		Name:          Rkt,
+		Service:       "rkt-api",
+		Binary:        "rkt",
+		loadImageTmpl: "docker load -i %s",
+	},
+	Containerd: {
+		Name:          Containerd,
+		Service:       "containerd",
+		Binary:        "containerd",
+		Endpoint:      "unix:///run/containerd/containerd.sock",
+		ConfigPath:    "/etc/containerd/config.toml",
+		configTmpl:    containerdConfigTmpl,
+		loadImageTmpl: "sudo ctr --namespace=k8s.io images import %s",
+	},
+	CRIO: {
+		Name:          CRIO,
+		Service:       "crio",
+		Binary:        "crio",
+		Endpoint:      "unix:///var/run/crio/crio.sock",
+		ConfigPath:    "/etc/crio/crio.conf",
+		configTmpl:    crioConfigTmpl,
+		loadImageTmpl: "sudo podman load -i %s",
+	},
+}
+
+const containerdConfigTmpl = `root = "/var/lib/containerd"
+state = "/run/containerd"
+
+[grpc]
+  address = "/run/containerd/containerd.sock"
+
+[plugins.cri]
+  sandbox_image = "%s"
+  systemd_cgroup = false
+  [plugins.cri.containerd]
+    snapshotter = "overlayfs"
+  [plugins.cri.cni]
+    bin_dir = "/opt/cni/bin"
+    conf_dir = "/etc/cni/net.d"
+`
+
+const crioConfigTmpl = `[crio]
+root = "/var/lib/containers/storage"
+runroot = "/var/run/containers/storage"
+storage_driver = "overlay"
+
+[crio.api]
+listen = "/var/run/crio/crio.sock"
+
+[crio.runtime]
+cgroup_manager = "cgroupfs"
+
+[crio.image]
+pause_image = "%s"
+
+[crio.network]
+network_dir = "/etc/cni/net.d/"
+plugin_dir = "/opt/cni/bin/"
+`
+
+// Names returns the names of the container runtimes, sorted.
+func Names() []string {
+	names := []string{}
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the named container runtime. An empty name is docker, the default runtime.
+func Lookup(name string) (Runtime, error) {
+	if name == "" {
+		name = Docker
+	}
+	r, ok := runtimes[name]
+	if !ok {
+		return Runtime{}, fmt.Errorf("Unknown container runtime %q, it must be one of %s", name, strings.Join(Names(), ", "))
+	}
+	return r, nil
+}
+
+// IsCRI returns whether the kubelet reaches the runtime over a CRI socket.
+func (r Runtime) IsCRI() bool {
+	return r.Endpoint != ""
+}
+
+// KubeletFlags returns the localkube flags pointing the kubelet at the runtime.
+func (r Runtime) KubeletFlags() []string {
+	if !r.IsCRI() {
+		return []string{"--container-runtime=" + r.Name}
+	}
+	return []string{"--container-runtime=remote", "--container-runtime-endpoint=" + r.Endpoint}
+}
+
+// Config returns the runtime's config, with the pods' sandbox containers running sandboxImage.
+// It is empty for the runtimes minikube doesn't configure.
+func (r Runtime) Config(sandboxImage string) string {
+	if r.configTmpl == "" {
+		return ""
+	}
+	return fmt.Sprintf(r.configTmpl, sandboxImage)
+}
+
+// LoadImageCommand returns the command loading the image tarball at path into the runtime.
+func (r Runtime) LoadImageCommand(path string) string {
+	return fmt.Sprintf(r.loadImageTmpl, path)
+}
+
+// LoadsIntoDocker returns whether images are loaded into docker, which minikube
+// can list the images of, so that the images already loaded are skipped.
+func (r Runtime) LoadsIntoDocker() bool {
+	return !r.IsCRI()
+}
+
+// Container is a container of a pod, whose logs minikube logs prints.
+type Container struct {
+	ID   string
+	Pod  string
+	Name string
+	// LogPath is the file the kubelet links the logs of the container of a CRI runtime at.
+	LogPath string
+}
+
+// criLogDir is where the kubelet links the logs of the containers of CRI runtimes, as
+// <pod>_<namespace>_<container>-<id>.log.
+const criLogDir = "/var/log/containers"
+
+// ListContainersCommand returns the command listing the containers of the pods of the namespace,
+// which ParseContainers reads. It is empty for rkt, whose containers log to the journal.
+func (r Runtime) ListContainersCommand(namespace string) string {
+	switch {
+	case r.Name == Docker:
+		return fmt.Sprintf(`docker ps -a --filter=label=io.kubernetes.pod.namespace=%s `+
+			`--format='{{.ID}} {{.Label "io.kubernetes.pod.name"}} {{.Label "io.kubernetes.container.name"}}'`, namespace)
+	case r.IsCRI():
+		return fmt.Sprintf("sudo find %s -name '*_%s_*.log' -printf '%%f\\n'", criLogDir, namespace)
+	}
+	return ""
+}
+
+// ParseContainers returns the containers the output of ListContainersCommand lists, leaving
+// out the sandboxes of the pods.
+func (r Runtime) ParseContainers(out string) []Container {
+	containers := []Container{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !r.IsCRI() {
+			fields := strings.Fields(line)
+			if len(fields) == 3 && fields[2] != "POD" {
+				containers = append(containers, Container{ID: fields[0], Pod: fields[1], Name: fields[2]})
+			}
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(line, ".log"), "_", 3)
+		dash := strings.LastIndex(parts[len(parts)-1], "-")
+		if len(parts) != 3 || dash < 0 {
+			continue
+		}
+		containers = append(containers, Container{ID: parts[2][dash+1:], Pod: parts[0], Name: parts[2][:dash], LogPath: criLogDir + "/" + line})
+	}
+	return containers
+}
+
+// ContainerLogsCommand returns the command printing the last lines of the logs of the container,
+// and then those it logs after with follow.
+func (r Runtime) ContainerLogsCommand(c Container, lines int, follow bool) string {
+	if !r.IsCRI() {
+		flags := fmt.Sprintf("--tail %d", lines)
+		if follow {
+			flags += " -f"
+		}
+		return fmt.Sprintf("docker logs %s %s 2>&1", flags, c.ID)
+	}
+	flags := fmt.Sprintf("-n %d", lines)
+	if follow {
+		flags += " -F"
+	}
+	return fmt.Sprintf("sudo tail %s %s", flags, c.LogPath)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuntimes(t *testing.T) {
+	var cases = []struct {
+		name          string
+		expectedName  string
+		kubeletFlags  []string
+		loadImage     string
+		configContent string
+	}{
+		{
+			name:         "",
+			expectedName: Docker,
+			kubeletFlags: []string{"--container-runtime=docker"},
+			loadImage:    "docker load -i /tmp/image.tar",
+		},
+		{
+			name:         Rkt,
+			expectedName: Rkt,
+			kubeletFlags: []string{"--container-runtime=rkt"},
+			loadImage:    "docker load -i /tmp/image.tar",
+		},
+		{
+			name:          Containerd,
+			expectedName:  Containerd,
+			kubeletFlags:  []string{"--container-runtime=remote", "--container-runtime-endpoint=unix:///run/containerd/containerd.sock"},
+			loadImage:     "sudo ctr --namespace=k8s.io images import /tmp/image.tar",
+			configContent: `sandbox_image = "pause:3.0"`,
+		},
+		{
+			name:          CRIO,
+			expectedName:  CRIO,
+			kubeletFlags:  []string{"--container-runtime=remote", "--container-runtime-endpoint=unix:///var/run/crio/crio.sock"},
+			loadImage:     "sudo podman load -i /tmp/image.tar",
+			configContent: `pause_image = "pause:3.0"`,
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.expectedName, func(t *testing.T) {
+			r, err := Lookup(test.name)
+			if err != nil {
+				t.Fatalf("Unexpected error looking up %q: %s", test.name, err)
+			}
+			if r.Name != test.expectedName {
+				t.Errorf("Expected runtime %s, got %s", test.expectedName, r.Name)
+			}
+			if flags := r.KubeletFlags(); !reflect.DeepEqual(flags, test.kubeletFlags) {
+				t.Errorf("Expected kubelet flags %v, got %v", test.kubeletFlags, flags)
+			}
+			if cmd := r.LoadImageCommand("/tmp/image.tar"); cmd != test.loadImage {
+				t.Errorf("Expected load command %q, got %q", test.loadImage, cmd)
+			}
+			config := r.Config("pause:3.0")
+			if test.configContent == "" && config != "" {
+				t.Errorf("Expected no config, got:\n%s", config)
+			}
+			if !strings.Contains(config, test.configContent) {
+				t.Errorf("Expected the config to contain %q, got:\n%s", test.configContent, config)
+			}
+		})
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("lxc"); err == nil {
+		t.Fatal("Expected an error looking up an unknown runtime")
+	}
+}
+
+func TestParseContainers(t *testing.T) {
+	var cases = []struct {
+		name     string
+		out      string
+		expected []Container
+	}{
+		{
+			name: Docker,
+			out: `0123456789ab kube-dns-910330662-7vcv5 kubedns
+123456789abc kube-dns-910330662-7vcv5 POD
+23456789abcd kube-addon-manager-minikube kube-addon-manager
+`,
+			expected: []Container{
+				{ID: "0123456789ab", Pod: "kube-dns-910330662-7vcv5", Name: "kubedns"},
+				{ID: "23456789abcd", Pod: "kube-addon-manager-minikube", Name: "kube-addon-manager"},
+			},
+		},
+		{
+			name: Containerd,
+			out: `kube-dns-910330662-7vcv5_kube-system_dnsmasq-metrics-0a1b2c.log
+kube-addon-manager-minikube_kube-system_kube-addon-manager-3d4e5f.log
+unparsable.log
+`,
+			expected: []Container{
+				{ID: "0a1b2c", Pod: "kube-dns-910330662-7vcv5", Name: "dnsmasq-metrics", LogPath: "/var/log/containers/kube-dns-910330662-7vcv5_kube-system_dnsmasq-metrics-0a1b2c.log"},
+				{ID: "3d4e5f", Pod: "kube-addon-manager-minikube", Name: "kube-addon-manager", LogPath: "/var/log/containers/kube-addon-manager-minikube_kube-system_kube-addon-manager-3d4e5f.log"},
+			},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := Lookup(test.name)
+			if err != nil {
+				t.Fatalf("Unexpected error looking up %q: %s", test.name, err)
+			}
+			if containers := r.ParseContainers(test.out); !reflect.DeepEqual(containers, test.expected) {
+				t.Errorf("Expected containers %+v, got %+v", test.expected, containers)
+			}
+			cmd := r.ContainerLogsCommand(test.expected[0], 20, true)
+			if !strings.Contains(cmd, "20") || !strings.Contains(cmd, test.expected[0].ID) {
+				t.Errorf("Expected the command to follow the last 20 lines of %s, got %q", test.expected[0].ID, cmd)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// ResolverInstructions returns how to make the resolver of the host, whose OS is goos, send the
+// queries for the names of the services to the server listening on host:port.
+func ResolverInstructions(goos, host string, port int) string {
+	switch goos {
+	case "darwin":
+		return fmt.Sprintf(`To resolve the names of the services, add a resolver for the minikube domain:
+	sudo mkdir -p /etc/resolver
+	printf 'nameserver %s\nport %d\n' | sudo tee /etc/resolver/minikube
+`, host, port)
+	case "linux":
+		return fmt.Sprintf(`To resolve the names of the services with systemd-resolved, route the minikube domain to the server:
+	sudo mkdir -p /etc/systemd/resolved.conf.d
+	printf '[Resolve]\nDNS=%s\nDomains=~minikube\n' | sudo tee /etc/systemd/resolved.conf.d/minikube.conf
+	sudo systemctl restart systemd-resolved
+`, net.JoinHostPort(host, strconv.Itoa(port)))
+	default:
+		return fmt.Sprintf("To resolve the names of the services, send the queries for the minikube domain to %s.\n", net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+}
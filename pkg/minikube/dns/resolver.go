@@ -0,0 +1,121 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// Domain is the domain of the names of the services, <service>.<namespace>.minikube.
+const Domain = "minikube."
+
+// DefaultTTL is how long lookups are cached, and answers cached by clients, so that new
+// services resolve soon enough.
+const DefaultTTL = 5 * time.Second
+
+// maxCacheEntries bounds the cache, which drops its expired entries once it holds as many.
+const maxCacheEntries = 1024
+
+// Resolver resolves the names of the services to the IP of the VM, their node ports being
+// reachable there.
+type Resolver struct {
+	services corev1.ServicesGetter
+	ip       func() (string, error)
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// cacheEntry is a lookup of a name, whose ip is nil when there is no such service.
+type cacheEntry struct {
+	ip      net.IP
+	expires time.Time
+}
+
+// NewResolver returns a resolver looking the services up through services and the IP of the
+// VM through ip, caching them for ttl.
+func NewResolver(services corev1.ServicesGetter, ip func() (string, error), ttl time.Duration) *Resolver {
+	return &Resolver{services: services, ip: ip, ttl: ttl, now: time.Now, cache: map[string]cacheEntry{}}
+}
+
+// inDomain returns whether name is in the domain of the services.
+func inDomain(name string) bool {
+	return name == Domain || strings.HasSuffix(name, "."+Domain)
+}
+
+// lookup returns the IP name resolves to, or nil if it isn't the name of a service. Errors
+// looking it up aren't cached.
+func (r *Resolver) lookup(name string) (net.IP, error) {
+	r.mu.Lock()
+	e, ok := r.cache[name]
+	r.mu.Unlock()
+	if ok && r.now().Before(e.expires) {
+		return e.ip, nil
+	}
+
+	ip, err := r.lookupService(name)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= maxCacheEntries {
+		for n, e := range r.cache {
+			if !r.now().Before(e.expires) {
+				delete(r.cache, n)
+			}
+		}
+	}
+	if len(r.cache) < maxCacheEntries {
+		r.cache[name] = cacheEntry{ip: ip, expires: r.now().Add(r.ttl)}
+	}
+	return ip, nil
+}
+
+func (r *Resolver) lookupService(name string) (net.IP, error) {
+	parts := strings.Split(strings.TrimSuffix(name, "."+Domain), ".")
+	if !inDomain(name) || name == Domain || len(parts) != 2 {
+		return nil, nil
+	}
+	service, namespace := parts[0], parts[1]
+	_, err := r.services.Services(namespace).Get(service, meta_v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error getting service %s/%s", namespace, service)
+	}
+	s, err := r.ip()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the VM IP")
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, errors.Errorf("Invalid VM IP %q", s)
+	}
+	return ip, nil
+}
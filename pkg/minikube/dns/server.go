@@ -0,0 +1,172 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxMessageLen is the largest query read, which is plenty for a single question.
+	maxMessageLen = 512
+	// headerLen is the length of the header of a DNS message.
+	headerLen = 12
+)
+
+// Server answers the DNS queries for the names of the services over UDP.
+type Server struct {
+	resolver *Resolver
+	conn     net.PacketConn
+}
+
+// Listen returns a server listening on address, such as 127.0.0.1:1053.
+func Listen(address string, resolver *Resolver) (*Server, error) {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error listening on %s", address)
+	}
+	return &Server{resolver: resolver, conn: conn}, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Serve answers queries until the server is closed.
+func (s *Server) Serve() error {
+	buf := make([]byte, maxMessageLen)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return errors.Wrap(err, "Error reading a query")
+		}
+		b := append([]byte{}, buf[:n]...)
+		go func() {
+			if reply := s.handle(b); reply != nil {
+				if _, err := s.conn.WriteTo(reply, addr); err != nil {
+					glog.Errorf("Error replying to %s: %s", addr, err)
+				}
+			}
+		}()
+	}
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
+
+// handle returns the reply to the query b, or nil when b can't be replied to. Unknown services
+// are answered with NXDOMAIN, and names of other domains refused, the server answering for its
+// own only.
+func (s *Server) handle(b []byte) []byte {
+	q := new(dns.Msg)
+	if err := q.Unpack(b); err != nil {
+		glog.Infof("Invalid query: %s", err)
+		return formatError(b)
+	}
+	r := s.reply(q)
+	if r == nil {
+		return nil
+	}
+	reply, err := r.Pack()
+	if err != nil {
+		glog.Errorf("Error packing the reply to %s: %s", q.Question[0].Name, err)
+		return nil
+	}
+	return reply
+}
+
+// reply answers q, which the server only does for standard queries of a single question. The
+// additional records of the query, such as its EDNS options, are left out of the reply.
+func (s *Server) reply(q *dns.Msg) *dns.Msg {
+	if q.Response {
+		return nil
+	}
+	r := new(dns.Msg).SetReply(q)
+	r.Authoritative = true
+	if q.Opcode != dns.OpcodeQuery {
+		r.Opcode = q.Opcode
+		r.Question = nil
+		r.Rcode = dns.RcodeNotImplemented
+		return r
+	}
+	if len(q.Question) != 1 {
+		r.Question = nil
+		r.Rcode = dns.RcodeFormatError
+		return r
+	}
+
+	// The reply repeats the case of the question, which some resolvers check.
+	question := q.Question[0]
+	name := strings.ToLower(question.Name)
+	if !inDomain(name) {
+		r.Rcode = dns.RcodeRefused
+		return r
+	}
+	if question.Qclass != dns.ClassINET {
+		r.Rcode = dns.RcodeNotImplemented
+		return r
+	}
+	ip, err := s.resolver.lookup(name)
+	if err != nil {
+		glog.Errorf("Error resolving %s: %s", name, err)
+		r.Rcode = dns.RcodeServerFailure
+		return r
+	}
+	if ip == nil {
+		r.Rcode = dns.RcodeNameError
+		return r
+	}
+	if question.Qtype != dns.TypeA {
+		// The name exists, without records of other types.
+		return r
+	}
+	r.Answer = []dns.RR{&dns.A{
+		Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: uint32(s.resolver.ttl.Seconds())},
+		A:   ip,
+	}}
+	r.Compress = true
+	return r
+}
+
+// formatError returns the FORMERR reply to the query b which couldn't be parsed, or nil when
+// not even its header could be.
+func formatError(b []byte) []byte {
+	if len(b) < headerLen || b[2]&0x80 != 0 {
+		return nil
+	}
+	r := &dns.Msg{MsgHdr: dns.MsgHdr{
+		Id:               binary.BigEndian.Uint16(b),
+		Response:         true,
+		Opcode:           int(b[2]>>3) & 0xf,
+		RecursionDesired: b[2]&1 != 0,
+		Rcode:            dns.RcodeFormatError,
+	}}
+	reply, err := r.Pack()
+	if err != nil {
+		return nil
+	}
+	return reply
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// mockServices lists the services by namespace/name, counting the lookups, which the server
+// makes concurrently.
+type mockServices struct {
+	mu       sync.Mutex
+	services map[string]bool
+	gets     int
+	err      error
+}
+
+func (m *mockServices) Services(namespace string) corev1.ServiceInterface {
+	return &mockServiceInterface{mock: m, namespace: namespace}
+}
+
+type mockServiceInterface struct {
+	fake.FakeServices
+	mock      *mockServices
+	namespace string
+}
+
+func (s *mockServiceInterface) Get(name string, _ meta_v1.GetOptions) (*v1.Service, error) {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+	s.mock.gets++
+	if s.mock.err != nil {
+		return nil, s.mock.err
+	}
+	if !s.mock.services[s.namespace+"/"+name] {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, name)
+	}
+	return &v1.Service{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: s.namespace}}, nil
+}
+
+func vmIP() (string, error) {
+	return "192.168.99.100", nil
+}
+
+// newQuery packs a query of name, with an EDNS record as resolvers add.
+func newQuery(t *testing.T, name string, qtype uint16) []byte {
+	q := new(dns.Msg).SetQuestion(name, qtype).SetEdns0(4096, false)
+	b, err := q.Pack()
+	if err != nil {
+		t.Fatalf("Error packing the query of %s: %s", name, err)
+	}
+	return b
+}
+
+// packet decodes a packet written as groups of hex bytes, as tcpdump -x prints them.
+func packet(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		t.Fatalf("Error decoding packet: %s", err)
+	}
+	return b
+}
+
+func TestHandle(t *testing.T) {
+	var tests = []struct {
+		description string
+		query       []byte
+		rcode       int
+		answer      net.IP
+	}{
+		{
+			description: "service",
+			query:       newQuery(t, "web.default.minikube.", dns.TypeA),
+			answer:      net.ParseIP("192.168.99.100"),
+		},
+		{
+			description: "case insensitive",
+			query:       newQuery(t, "Web.Default.Minikube.", dns.TypeA),
+			answer:      net.ParseIP("192.168.99.100"),
+		},
+		{
+			description: "AAAA of a service",
+			query:       newQuery(t, "web.default.minikube.", dns.TypeAAAA),
+		},
+		{
+			description: "unknown service",
+			query:       newQuery(t, "db.default.minikube.", dns.TypeA),
+			rcode:       dns.RcodeNameError,
+		},
+		{
+			description: "namespace missing",
+			query:       newQuery(t, "web.minikube.", dns.TypeA),
+			rcode:       dns.RcodeNameError,
+		},
+		{
+			description: "other domain",
+			query:       newQuery(t, "kubernetes.io.", dns.TypeA),
+			rcode:       dns.RcodeRefused,
+		},
+	}
+
+	s := &Server{resolver: NewResolver(&mockServices{services: map[string]bool{"default/web": true}}, vmIP, DefaultTTL)}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			reply := new(dns.Msg)
+			if err := reply.Unpack(s.handle(test.query)); err != nil {
+				t.Fatalf("Error unpacking the reply: %s", err)
+			}
+			if id := binary.BigEndian.Uint16(test.query); reply.Id != id {
+				t.Errorf("Expected the id %d of the query, got %d", id, reply.Id)
+			}
+			if !reply.Response || !reply.RecursionDesired {
+				t.Errorf("Expected a response with the recursion desired flag of the query, got %+v", reply.MsgHdr)
+			}
+			if reply.Rcode != test.rcode {
+				t.Errorf("Expected rcode %s, got %s", dns.RcodeToString[test.rcode], dns.RcodeToString[reply.Rcode])
+			}
+			if len(reply.Extra) != 0 {
+				t.Errorf("Expected no additional records, got %v", reply.Extra)
+			}
+			if test.answer == nil {
+				if len(reply.Answer) != 0 {
+					t.Errorf("Expected no answer, got %v", reply.Answer)
+				}
+				return
+			}
+			if len(reply.Answer) != 1 {
+				t.Fatalf("Expected an answer of %s, got %v", test.answer, reply.Answer)
+			}
+			a, ok := reply.Answer[0].(*dns.A)
+			if !ok || !a.A.Equal(test.answer) {
+				t.Errorf("Expected an answer of %s, got %s", test.answer, reply.Answer[0])
+			}
+			if a != nil && a.Hdr.Ttl != 5 {
+				t.Errorf("Expected a TTL of 5 seconds, got %d", a.Hdr.Ttl)
+			}
+		})
+	}
+}
+
+// TestHandlePackets answers queries as the resolvers which query the server send them, and
+// checks the replies against the responses they accept, byte for byte.
+func TestHandlePackets(t *testing.T) {
+	var tests = []struct {
+		description string
+		query       string
+		reply       string
+	}{
+		{
+			description: "dig, with an EDNS cookie",
+			query: `8c3f 0120 0001 0000 0000 0001
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 0001 0001
+				00 0029 1000 00 00 0000 000c 000a 0008 5d3b7c1e2a8f44c1`,
+			reply: `8c3f 8500 0001 0001 0000 0000
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 0001 0001
+				c00c 0001 0001 00000005 0004 c0a86364`,
+		},
+		{
+			description: "glibc, asking for an AAAA record",
+			query: `1d62 0100 0001 0000 0000 0000
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 001c 0001`,
+			reply: `1d62 8500 0001 0000 0000 0000
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 001c 0001`,
+		},
+		{
+			// The reply has to repeat the case of the question, which the resolver checks.
+			description: "systemd-resolved, with randomized case and DNSSEC OK",
+			query: `4a07 0100 0001 0000 0000 0001
+				03 576542 07 44654661756c54 08 4d696e694b756265 00 0001 0001
+				00 0029 05ac 00 00 8000 0000`,
+			reply: `4a07 8500 0001 0001 0000 0000
+				03 576542 07 44654661756c54 08 4d696e694b756265 00 0001 0001
+				c00c 0001 0001 00000005 0004 c0a86364`,
+		},
+		{
+			description: "name error",
+			query: `b5e1 0100 0001 0000 0000 0000
+				02 6462 07 64656661756c74 08 6d696e696b756265 00 0001 0001`,
+			reply: `b5e1 8503 0001 0000 0000 0000
+				02 6462 07 64656661756c74 08 6d696e696b756265 00 0001 0001`,
+		},
+		{
+			description: "notify",
+			query: `07d0 2400 0001 0000 0000 0000
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 0006 0001`,
+			reply: `07d0 a404 0000 0000 0000 0000`,
+		},
+		{
+			description: "two questions",
+			query: `61a8 0100 0002 0000 0000 0000
+				03 776562 07 64656661756c74 08 6d696e696b756265 00 0001 0001
+				c00c 001c 0001`,
+			reply: `61a8 8501 0000 0000 0000 0000`,
+		},
+		{
+			description: "truncated name",
+			query:       `8c3f 0100 0001 0000 0000 0000 03 776562 07 6465`,
+			reply:       `8c3f 8101 0000 0000 0000 0000`,
+		},
+	}
+
+	s := &Server{resolver: NewResolver(&mockServices{services: map[string]bool{"default/web": true}}, vmIP, DefaultTTL)}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if reply, expected := s.handle(packet(t, test.query)), packet(t, test.reply); !bytes.Equal(reply, expected) {
+				t.Errorf("Expected the reply\n%x\ngot\n%x", expected, reply)
+			}
+		})
+	}
+}
+
+func TestHandleUnanswerable(t *testing.T) {
+	var tests = []struct {
+		description string
+		message     string
+	}{
+		{description: "short header", message: `8c3f 0120 0001 0000 0000`},
+		{description: "response", message: `8c3f 8180 0001 0001 0000 0000 03 776562 00 0001 0001`},
+		{description: "truncated response", message: `8c3f 8180 0001 0001 0000 0000 03 776562 07 6465`},
+	}
+
+	s := &Server{resolver: NewResolver(&mockServices{}, vmIP, DefaultTTL)}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if reply := s.handle(packet(t, test.message)); reply != nil {
+				t.Errorf("Expected no reply, got %x", reply)
+			}
+		})
+	}
+}
+
+func TestResolverCache(t *testing.T) {
+	services := &mockServices{services: map[string]bool{"default/web": true}}
+	r := NewResolver(services, vmIP, DefaultTTL)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ip, err := r.lookup("web.default.minikube."); err != nil || ip == nil {
+			t.Fatalf("Expected web to resolve, got %v, %v", ip, err)
+		}
+		if ip, err := r.lookup("db.default.minikube."); err != nil || ip != nil {
+			t.Fatalf("Expected db not to resolve, got %v, %v", ip, err)
+		}
+	}
+	if services.gets != 2 {
+		t.Errorf("Expected the lookups to be cached, got %d", services.gets)
+	}
+
+	services.services["default/db"] = true
+	now = now.Add(DefaultTTL)
+	if ip, err := r.lookup("db.default.minikube."); err != nil || ip == nil {
+		t.Fatalf("Expected db to resolve once the cache expired, got %v, %v", ip, err)
+	}
+
+	services.err = errors.New("connection refused")
+	if _, err := r.lookup("api.default.minikube."); err == nil {
+		t.Fatal("Expected an error when the apiserver can't be reached")
+	}
+	services.err = nil
+	if ip, err := r.lookup("api.default.minikube."); err != nil || ip != nil {
+		t.Fatalf("Expected errors not to be cached, got %v, %v", ip, err)
+	}
+}
+
+// TestServer resolves names through the server with the resolver of the standard library, and
+// with the client of miekg/dns.
+func TestServer(t *testing.T) {
+	s, err := Listen("127.0.0.1:0", NewResolver(&mockServices{services: map[string]bool{"default/web": true}}, vmIP, DefaultTTL))
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	defer s.Close()
+	go s.Serve()
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "udp", s.Addr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := resolver.LookupHost(ctx, "web.default.minikube.")
+	if err != nil {
+		t.Fatalf("Error resolving web: %s", err)
+	}
+	if !reflect.DeepEqual(addrs, []string{"192.168.99.100"}) {
+		t.Errorf("Expected web to resolve to the VM IP, got %v", addrs)
+	}
+
+	_, err = resolver.LookupHost(ctx, "db.default.minikube.")
+	if dnsErr, ok := err.(*net.DNSError); !ok || !dnsErr.IsNotFound {
+		t.Errorf("Expected db not to be found, got %v", err)
+	}
+
+	reply, err := dns.Exchange(new(dns.Msg).SetQuestion("web.default.minikube.", dns.TypeA), s.Addr().String())
+	if err != nil {
+		t.Fatalf("Error querying web: %s", err)
+	}
+	if len(reply.Answer) != 1 || reply.Answer[0].(*dns.A).A.String() != "192.168.99.100" {
+		t.Errorf("Expected web to resolve to the VM IP, got %v", reply.Answer)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// CacheDir is where cached images are saved, as docker save tarballs.
+var CacheDir = constants.MakeCachePath("images")
+
+// CachedImage is an image saved in the cache directory.
+type CachedImage struct {
+	Image
+	// Path is the path of the image's tarball.
+	Path string
+}
+
+// Add pulls the images from their registries and saves them in the cache directory,
+// replacing the ones already there.
+func Add(dir string, refs []string) error {
+	imgs, err := parseImages(refs)
+	if err != nil {
+		return err
+	}
+	c := newRegistryClient()
+	for _, img := range imgs {
+		fmt.Printf("Caching image %s...\n", img)
+		if err := saveImage(c, img, img.cachePath(dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveImage pulls the image into a temporary file, which replaces the one at path once it is complete.
+func saveImage(c *registryClient, img Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "Error creating image cache directory")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "Error creating temporary image file")
+	}
+	defer os.Remove(tmp.Name())
+	id, err := c.pull(img, tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "Error writing image file")
+	}
+	glog.Infof("Cached image %s, with ID %s, at %s", img, id, path)
+	return errors.Wrap(os.Rename(tmp.Name(), path), "Error moving image into the cache")
+}
+
+// Delete removes the images from the cache directory. An image which isn't cached is an error.
+func Delete(dir string, refs []string) error {
+	imgs, err := parseImages(refs)
+	if err != nil {
+		return err
+	}
+	for _, img := range imgs {
+		path := img.cachePath(dir)
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("Image %s is not cached", img)
+			}
+			return errors.Wrapf(err, "Error deleting cached image %s", img)
+		}
+		removeEmptyDirs(dir, filepath.Dir(path))
+	}
+	return nil
+}
+
+// DeleteAll removes every image from the cache directory.
+func DeleteAll(dir string) error {
+	return errors.Wrap(os.RemoveAll(dir), "Error deleting image cache")
+}
+
+// removeEmptyDirs removes path and its parents up to dir, as long as they are empty.
+func removeEmptyDirs(dir, path string) {
+	for path != dir && strings.HasPrefix(path, dir) {
+		if err := os.Remove(path); err != nil {
+			return
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+// List returns the images in the cache directory, sorted by reference.
+func List(dir string) ([]CachedImage, error) {
+	var cached []CachedImage
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".tar" {
+			return nil
+		}
+		img, err := imageFromCachePath(dir, path)
+		if err != nil {
+			glog.Warningf("Ignoring %s", err)
+			return nil
+		}
+		cached = append(cached, CachedImage{Image: img, Path: path})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing cached images")
+	}
+	sort.Sort(byReference(cached))
+	return cached, nil
+}
+
+type byReference []CachedImage
+
+func (b byReference) Len() int           { return len(b) }
+func (b byReference) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byReference) Less(i, j int) bool { return b[i].String() < b[j].String() }
+
+func parseImages(refs []string) ([]Image, error) {
+	var imgs []Image
+	for _, ref := range refs {
+		img, err := ParseImage(ref)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, img)
+	}
+	return imgs, nil
+}
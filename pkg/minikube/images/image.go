@@ -0,0 +1,134 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package images caches container images on the host, so that they can be
+// loaded into the VM's docker daemon without it reaching a registry.
+package images
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRegistry = "docker.io"
+	defaultTag      = "latest"
+	// officialRepoPrefix is the namespace of the Docker Hub images with a single component name.
+	officialRepoPrefix = "library/"
+	// digestPrefix starts the names of the cached files of images referenced by digest,
+	// as a tag can't start with it and a digest's colon isn't allowed in Windows file names.
+	digestPrefix = "@"
+)
+
+// Image is a reference to an image in a registry, normalized the way docker does.
+type Image struct {
+	// Registry is the registry's host, such as docker.io or gcr.io.
+	Registry string
+	// Repository is the image's path in the registry, such as library/busybox.
+	Repository string
+	// Tag is the image's tag, if it isn't referenced by Digest.
+	Tag string
+	// Digest is the digest of the image's manifest, if it is referenced by it.
+	Digest digest.Digest
+}
+
+// ParseImage parses an image reference, such as busybox, gcr.io/google_containers/pause:3.0
+// or busybox@sha256:<hex>. A reference without a tag or digest is to the latest tag.
+func ParseImage(s string) (Image, error) {
+	ref, err := reference.ParseNamed(s)
+	if err != nil {
+		return Image{}, errors.Wrapf(err, "Error parsing image %q", s)
+	}
+	img := Image{Registry: defaultRegistry, Repository: ref.Name()}
+	// Like docker, the first component of the name is only a registry if it looks like a host.
+	if i := strings.Index(img.Repository, "/"); i >= 0 {
+		host := img.Repository[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			img.Registry, img.Repository = host, img.Repository[i+1:]
+		}
+	}
+	if img.Registry == defaultRegistry && !strings.Contains(img.Repository, "/") {
+		img.Repository = officialRepoPrefix + img.Repository
+	}
+	switch r := ref.(type) {
+	case reference.Canonical:
+		if r.Digest().Algorithm() != digest.SHA256 {
+			return Image{}, fmt.Errorf("Unsupported digest algorithm in image %q", s)
+		}
+		img.Digest = r.Digest()
+	case reference.NamedTagged:
+		img.Tag = r.Tag()
+	default:
+		img.Tag = defaultTag
+	}
+	return img, nil
+}
+
+// Name returns the image's name, without the registry and namespace docker leaves out.
+func (i Image) Name() string {
+	if i.Registry != defaultRegistry {
+		return i.Registry + "/" + i.Repository
+	}
+	return strings.TrimPrefix(i.Repository, officialRepoPrefix)
+}
+
+// String returns the image's reference as docker shows it.
+func (i Image) String() string {
+	if i.Digest != "" {
+		return i.Name() + "@" + i.Digest.String()
+	}
+	return i.Name() + ":" + i.Tag
+}
+
+// cachePath returns where the image is saved in the cache directory: under a directory
+// for its repository, in a file named for its tag or digest.
+func (i Image) cachePath(dir string) string {
+	name := i.Tag
+	if i.Digest != "" {
+		name = digestPrefix + string(i.Digest.Algorithm()) + "_" + i.Digest.Hex()
+	}
+	// A registry's port is separated by an underscore, which a host can't contain, as Windows doesn't allow colons.
+	registry := strings.Replace(i.Registry, ":", "_", 1)
+	return filepath.Join(dir, registry, filepath.FromSlash(i.Repository), name+".tar")
+}
+
+// imageFromCachePath returns the image saved at path in the cache directory.
+func imageFromCachePath(dir, path string) (Image, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return Image{}, err
+	}
+	parts := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, ".tar")), "/")
+	if len(parts) < 3 {
+		return Image{}, fmt.Errorf("Unexpected file in image cache: %s", path)
+	}
+	img := Image{Registry: strings.Replace(parts[0], "_", ":", 1), Repository: strings.Join(parts[1:len(parts)-1], "/")}
+	name := parts[len(parts)-1]
+	if strings.HasPrefix(name, digestPrefix) {
+		img.Digest = digest.Digest(strings.Replace(strings.TrimPrefix(name, digestPrefix), "_", ":", 1))
+		if err := img.Digest.Validate(); err != nil {
+			return Image{}, errors.Wrapf(err, "Unexpected file in image cache: %s", path)
+		}
+	} else {
+		img.Tag = name
+	}
+	return img, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const testDigest = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestParseImage(t *testing.T) {
+	var tests = []struct {
+		description string
+		ref         string
+		expected    Image
+		str         string
+		path        string
+		shouldErr   bool
+	}{
+		{
+			description: "official image",
+			ref:         "busybox",
+			expected:    Image{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"},
+			str:         "busybox:latest",
+			path:        "docker.io/library/busybox/latest.tar",
+		},
+		{
+			description: "docker hub user image",
+			ref:         "kubernetes/heapster:v1.2",
+			expected:    Image{Registry: "docker.io", Repository: "kubernetes/heapster", Tag: "v1.2"},
+			str:         "kubernetes/heapster:v1.2",
+			path:        "docker.io/kubernetes/heapster/v1.2.tar",
+		},
+		{
+			description: "other registry",
+			ref:         "gcr.io/google_containers/pause-amd64:3.0",
+			expected:    Image{Registry: "gcr.io", Repository: "google_containers/pause-amd64", Tag: "3.0"},
+			str:         "gcr.io/google_containers/pause-amd64:3.0",
+			path:        "gcr.io/google_containers/pause-amd64/3.0.tar",
+		},
+		{
+			description: "registry with port",
+			ref:         "localhost:5000/app",
+			expected:    Image{Registry: "localhost:5000", Repository: "app", Tag: "latest"},
+			str:         "localhost:5000/app:latest",
+			path:        "localhost_5000/app/latest.tar",
+		},
+		{
+			description: "digest",
+			ref:         "busybox@" + testDigest,
+			expected:    Image{Registry: "docker.io", Repository: "library/busybox", Digest: testDigest},
+			str:         "busybox@" + testDigest,
+			path:        "docker.io/library/busybox/@sha256_2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.tar",
+		},
+		{
+			description: "invalid",
+			ref:         "Busybox",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			img, err := ParseImage(test.ref)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error parsing %s: %s", test.ref, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error parsing %s", test.ref)
+			}
+			if test.shouldErr {
+				return
+			}
+			if img != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, img)
+			}
+			if img.String() != test.str {
+				t.Errorf("Expected %s to be shown as %s, got %s", test.ref, test.str, img.String())
+			}
+
+			dir := filepath.Join("cache", "images")
+			path := img.cachePath(dir)
+			if expected := filepath.Join(dir, filepath.FromSlash(test.path)); path != expected {
+				t.Errorf("Expected %s to be cached at %s, got %s", test.ref, expected, path)
+			}
+			cached, err := imageFromCachePath(dir, path)
+			if err != nil {
+				t.Fatalf("Unexpected error reading image from cache path %s: %s", path, err)
+			}
+			if cached != img {
+				t.Errorf("Expected the image cached at %s to be %+v, got %+v", path, img, cached)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/docker/distribution/digest"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+)
+
+// vmImageDir is where image tarballs are copied to in the VM before they are loaded.
+const vmImageDir = "/tmp/minikube-images"
+
+// CommandRunner runs commands in the VM and copies files into it.
+type CommandRunner interface {
+	// Run runs the command, and returns its output.
+	Run(cmd string) (string, error)
+	// Copy copies the local file at path to dir/name in the VM.
+	Copy(path, dir, name string) error
+}
+
+type sshRunner struct {
+	client *ssh.Client
+}
+
+// NewSSHRunner returns a CommandRunner running commands over the SSH connection.
+func NewSSHRunner(c *ssh.Client) CommandRunner {
+	return &sshRunner{client: c}
+}
+
+func (r *sshRunner) Run(cmd string) (string, error) {
+	s, err := r.client.NewSession()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating new session for ssh client")
+	}
+	defer s.Close()
+	out, err := s.CombinedOutput(cmd)
+	return string(out), err
+}
+
+func (r *sshRunner) Copy(p, dir, name string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return sshutil.Transfer(f, int(info.Size()), dir, name, "0644", r.client)
+}
+
+// loadStep loads an image into the VM's docker daemon, unless it is there
+// already, and tags it with the references it doesn't have yet.
+type loadStep struct {
+	ID digest.Digest
+	// Image is the reference of the image the tarball to load is cached as.
+	Image string
+	// Load is the tarball to load, if the image isn't in the VM.
+	Load string
+	// Tags are the references to tag the image with.
+	Tags []string
+}
+
+type cachedImageID struct {
+	CachedImage
+	ID digest.Digest
+}
+
+// planLoad works out how to get the cached images into the VM. Images which
+// are cached with several references are loaded once and tagged with the
+// others. loaded holds the IDs of the images in the VM, and tags the IDs of
+// the images the cached tags point to in the VM.
+func planLoad(cached []cachedImageID, loaded map[digest.Digest]bool, tags map[string]digest.Digest) []loadStep {
+	var steps []*loadStep
+	byID := map[digest.Digest]*loadStep{}
+	for _, c := range cached {
+		step, ok := byID[c.ID]
+		if !ok {
+			step = &loadStep{ID: c.ID}
+			byID[c.ID] = step
+			steps = append(steps, step)
+			if !loaded[c.ID] {
+				// Loading the tarball tags the image with its reference.
+				step.Image, step.Load = c.String(), c.Path
+				continue
+			}
+		}
+		if c.Tag != "" && tags[c.String()] != c.ID {
+			step.Tags = append(step.Tags, c.String())
+		}
+	}
+	var plan []loadStep
+	for _, s := range steps {
+		if s.Load != "" || len(s.Tags) > 0 {
+			plan = append(plan, *s)
+		}
+	}
+	return plan
+}
+
+// LoadCached loads the images in the cache directory into the VM's container runtime, listing
+// them on w. Images already in docker are skipped, so it can be run again after each start.
+func LoadCached(r CommandRunner, dir string, rt cruntime.Runtime, w io.Writer) error {
+	list, err := List(dir)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	if !rt.LoadsIntoDocker() {
+		// The CRI runtimes don't report docker's image IDs, so every image is loaded again.
+		for _, img := range list {
+			fmt.Fprintf(w, "Loading cached image %s...\n", img)
+			if err := loadTarball(r, rt, img.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var cached []cachedImageID
+	for _, img := range list {
+		id, err := cachedImageIDOf(img.Path)
+		if err != nil {
+			glog.Warningf("Not loading cached image %s: %s", img, err)
+			continue
+		}
+		cached = append(cached, cachedImageID{CachedImage: img, ID: id})
+	}
+
+	out, err := r.Run("docker images -q --no-trunc")
+	if err != nil {
+		return errors.Wrapf(err, "Error listing images in the VM: %s", out)
+	}
+	loaded := map[digest.Digest]bool{}
+	for _, id := range strings.Fields(out) {
+		loaded[digest.Digest(id)] = true
+	}
+	tags := map[string]digest.Digest{}
+	for _, c := range cached {
+		if c.Tag == "" || !loaded[c.ID] {
+			continue
+		}
+		out, err := r.Run("docker images -q --no-trunc " + c.String())
+		if err != nil {
+			return errors.Wrapf(err, "Error getting image %s in the VM: %s", c, out)
+		}
+		tags[c.String()] = digest.Digest(strings.TrimSpace(out))
+	}
+
+	for _, step := range planLoad(cached, loaded, tags) {
+		if step.Load != "" {
+			fmt.Fprintf(w, "Loading cached image %s...\n", step.Image)
+			if err := loadTarball(r, rt, step.Load); err != nil {
+				return err
+			}
+		}
+		for _, tag := range step.Tags {
+			if out, err := r.Run(fmt.Sprintf("docker tag %s %s", step.ID.Hex(), tag)); err != nil {
+				return errors.Wrapf(err, "Error tagging image %s as %s: %s", step.ID, tag, out)
+			}
+		}
+	}
+	return nil
+}
+
+func cachedImageIDOf(p string) (digest.Digest, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return readImageID(f)
+}
+
+// loadTarball copies the image tarball into the VM and loads it into the container runtime.
+func loadTarball(r CommandRunner, rt cruntime.Runtime, p string) error {
+	name := "image.tar"
+	if err := r.Copy(p, vmImageDir, name); err != nil {
+		return errors.Wrapf(err, "Error copying %s into the VM", p)
+	}
+	vmPath := path.Join(vmImageDir, name)
+	cmd := fmt.Sprintf("%s && sudo rm -f %s", rt.LoadImageCommand(vmPath), vmPath)
+	if out, err := r.Run(cmd); err != nil {
+		return errors.Wrapf(err, "Error loading %s: %s", p, out)
+	}
+	return nil
+}
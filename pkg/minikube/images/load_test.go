@@ -0,0 +1,235 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// fakeRunner records the commands run and the files copied, answering
+// commands with the outputs it was given.
+type fakeRunner struct {
+	outputs  map[string]string
+	commands []string
+}
+
+func (r *fakeRunner) Run(cmd string) (string, error) {
+	r.commands = append(r.commands, cmd)
+	return r.outputs[cmd], nil
+}
+
+func (r *fakeRunner) Copy(path, dir, name string) error {
+	r.commands = append(r.commands, fmt.Sprintf("copy %s", filepath.Base(path)))
+	return nil
+}
+
+func testID(c string) digest.Digest {
+	return digest.Digest("sha256:" + strings.Repeat(c, 64))
+}
+
+// writeCachedImage writes a tarball of the image with the given ID, holding only its manifest, into the cache.
+func writeCachedImage(t *testing.T, dir, ref string, id digest.Digest) {
+	img, err := ParseImage(ref)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %s", ref, err)
+	}
+	path := img.cachePath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Error creating cache dir: %s", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Error creating cached image: %s", err)
+	}
+	defer f.Close()
+	b, _ := json.Marshal([]saveManifest{{Config: id.Hex() + ".json"}})
+	tw := tar.NewWriter(f)
+	if err := tw.WriteHeader(&tar.Header{Name: manifestFile, Mode: 0644, Size: int64(len(b))}); err != nil {
+		t.Fatalf("Error writing cached image: %s", err)
+	}
+	tw.Write(b)
+	tw.Close()
+}
+
+func TestPlanLoad(t *testing.T) {
+	cached := func(ref string, id digest.Digest) cachedImageID {
+		img, _ := ParseImage(ref)
+		return cachedImageID{CachedImage: CachedImage{Image: img, Path: ref + ".tar"}, ID: id}
+	}
+	var tests = []struct {
+		description string
+		cached      []cachedImageID
+		loaded      map[digest.Digest]bool
+		tags        map[string]digest.Digest
+		expected    []loadStep
+	}{
+		{
+			description: "nothing loaded",
+			cached:      []cachedImageID{cached("a:1", testID("a")), cached("b:1", testID("b"))},
+			expected: []loadStep{
+				{ID: testID("a"), Image: "a:1", Load: "a:1.tar"},
+				{ID: testID("b"), Image: "b:1", Load: "b:1.tar"},
+			},
+		},
+		{
+			description: "everything loaded",
+			cached:      []cachedImageID{cached("a:1", testID("a")), cached("b@"+testDigest, testID("b"))},
+			loaded:      map[digest.Digest]bool{testID("a"): true, testID("b"): true},
+			tags:        map[string]digest.Digest{"a:1": testID("a")},
+		},
+		{
+			description: "multiple tags loaded once",
+			cached: []cachedImageID{
+				cached("a:1", testID("a")),
+				cached("a:latest", testID("a")),
+				cached("a@"+testDigest, testID("a")),
+			},
+			expected: []loadStep{
+				{ID: testID("a"), Image: "a:1", Load: "a:1.tar", Tags: []string{"a:latest"}},
+			},
+		},
+		{
+			description: "loaded image missing a tag",
+			cached:      []cachedImageID{cached("a:1", testID("a")), cached("a:2", testID("a"))},
+			loaded:      map[digest.Digest]bool{testID("a"): true},
+			tags:        map[string]digest.Digest{"a:1": testID("a")},
+			expected:    []loadStep{{ID: testID("a"), Tags: []string{"a:2"}}},
+		},
+		{
+			description: "tag moved to another image",
+			cached:      []cachedImageID{cached("a:1", testID("a")), cached("b:1", testID("b"))},
+			loaded:      map[digest.Digest]bool{testID("b"): true},
+			tags:        map[string]digest.Digest{"b:1": testID("c")},
+			expected: []loadStep{
+				{ID: testID("a"), Image: "a:1", Load: "a:1.tar"},
+				{ID: testID("b"), Tags: []string{"b:1"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			plan := planLoad(test.cached, test.loaded, test.tags)
+			if !reflect.DeepEqual(plan, test.expected) {
+				t.Errorf("Expected plan %+v, got %+v", test.expected, plan)
+			}
+		})
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	writeCachedImage(t, dir, "busybox:1.26", testID("a"))
+	writeCachedImage(t, dir, "busybox:latest", testID("a"))
+	writeCachedImage(t, dir, "gcr.io/google_containers/pause-amd64:3.0", testID("b"))
+	writeCachedImage(t, dir, "nginx@"+testDigest, testID("c"))
+
+	r := &fakeRunner{outputs: map[string]string{
+		"docker images -q --no-trunc":                                          testID("b").String() + "\n" + testID("d").String() + "\n",
+		"docker images -q --no-trunc gcr.io/google_containers/pause-amd64:3.0": testID("b").String() + "\n",
+	}}
+	if err := LoadCached(r, dir, docker(t), ioutil.Discard); err != nil {
+		t.Fatalf("Unexpected error loading cached images: %s", err)
+	}
+	expected := []string{
+		"docker images -q --no-trunc",
+		"docker images -q --no-trunc gcr.io/google_containers/pause-amd64:3.0",
+		"copy 1.26.tar",
+		"docker load -i /tmp/minikube-images/image.tar && sudo rm -f /tmp/minikube-images/image.tar",
+		fmt.Sprintf("docker tag %s busybox:latest", testID("a").Hex()),
+		"copy @sha256_" + testDigest[len("sha256:"):] + ".tar",
+		"docker load -i /tmp/minikube-images/image.tar && sudo rm -f /tmp/minikube-images/image.tar",
+	}
+	if !reflect.DeepEqual(r.commands, expected) {
+		t.Errorf("Expected commands:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(r.commands, "\n"))
+	}
+}
+
+func TestLoadCachedEmpty(t *testing.T) {
+	r := &fakeRunner{}
+	if err := LoadCached(r, filepath.Join(os.TempDir(), "minikube-no-such-cache"), docker(t), ioutil.Discard); err != nil {
+		t.Fatalf("Unexpected error loading an empty cache: %s", err)
+	}
+	if len(r.commands) != 0 {
+		t.Errorf("Expected no commands to run, got %v", r.commands)
+	}
+}
+
+func TestLoadCachedCRI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	writeCachedImage(t, dir, "busybox:1.26", testID("a"))
+	writeCachedImage(t, dir, "busybox:latest", testID("a"))
+
+	var cases = []struct {
+		runtime string
+		load    string
+	}{
+		{
+			runtime: cruntime.Containerd,
+			load:    "sudo ctr --namespace=k8s.io images import /tmp/minikube-images/image.tar && sudo rm -f /tmp/minikube-images/image.tar",
+		},
+		{
+			runtime: cruntime.CRIO,
+			load:    "sudo podman load -i /tmp/minikube-images/image.tar && sudo rm -f /tmp/minikube-images/image.tar",
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.runtime, func(t *testing.T) {
+			rt, err := cruntime.Lookup(test.runtime)
+			if err != nil {
+				t.Fatalf("Unexpected error looking up runtime: %s", err)
+			}
+			r := &fakeRunner{}
+			if err := LoadCached(r, dir, rt, ioutil.Discard); err != nil {
+				t.Fatalf("Unexpected error loading cached images: %s", err)
+			}
+			// Without docker's image IDs, each tarball is loaded.
+			expected := []string{"copy 1.26.tar", test.load, "copy latest.tar", test.load}
+			if !reflect.DeepEqual(r.commands, expected) {
+				t.Errorf("Expected commands:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(r.commands, "\n"))
+			}
+		})
+	}
+}
+
+func docker(t *testing.T) cruntime.Runtime {
+	rt, err := cruntime.Lookup(cruntime.Docker)
+	if err != nil {
+		t.Fatalf("Unexpected error looking up runtime: %s", err)
+	}
+	return rt
+}
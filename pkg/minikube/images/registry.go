@@ -0,0 +1,328 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker/distribution/digest"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestV2Type   = "application/vnd.docker.distribution.manifest.v2+json"
+	manifestListType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// dockerHubRegistry is the host of the registry API of docker.io.
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+type descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Size      int64         `json:"size"`
+	Digest    digest.Digest `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+// saveManifest is an entry of the manifest.json of a docker save tarball.
+type saveManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// registryClient pulls images from registries with the Docker Registry HTTP API V2,
+// authenticating anonymously when a registry asks for a token.
+type registryClient struct {
+	client *http.Client
+	// tokens are the bearer tokens of each repository pulled from.
+	tokens map[string]string
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{client: http.DefaultClient, tokens: map[string]string{}}
+}
+
+// registryURL returns the base URL of the registry API of the image's registry.
+// Registries on the local host are reached over HTTP, all others over HTTPS.
+func registryURL(img Image) string {
+	host := img.Registry
+	if host == defaultRegistry {
+		host = dockerHubRegistry
+	}
+	scheme := "https"
+	if h := strings.Split(host, ":")[0]; h == "localhost" || h == "127.0.0.1" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, host, img.Repository)
+}
+
+// get requests path in the image's repository, fetching a token when the registry asks for one.
+func (c *registryClient) get(img Image, path string, accept ...string) (*http.Response, error) {
+	u := registryURL(img) + path
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accept {
+			req.Header.Add("Accept", a)
+		}
+		if token, ok := c.tokens[img.Registry+"/"+img.Repository]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return c.client.Do(req)
+	}
+
+	resp, err := do()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := c.fetchToken(challenge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error authenticating to %s", img.Registry)
+		}
+		c.tokens[img.Registry+"/"+img.Repository] = token
+		if resp, err = do(); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Error getting %s: %s", u, resp.Status)
+	}
+	return resp, nil
+}
+
+// fetchToken gets an anonymous pull token as asked for by a WWW-Authenticate Bearer challenge.
+func (c *registryClient) fetchToken(challenge string) (string, error) {
+	params, err := parseBearerChallenge(challenge)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", errors.Wrap(err, "Error parsing token realm")
+	}
+	q := u.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v, ok := params[key]; ok {
+			q.Set(key, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	glog.Infof("Getting registry token from %s", u)
+	resp, err := c.client.Get(u.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error getting token from %s: %s", u, resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "Error decoding token")
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseBearerChallenge parses the parameters of a challenge such as
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io".
+func parseBearerChallenge(challenge string) (map[string]string, error) {
+	const scheme = "Bearer "
+	if !strings.HasPrefix(challenge, scheme) {
+		return nil, fmt.Errorf("Unsupported authentication challenge: %q", challenge)
+	}
+	params := map[string]string{}
+	for _, p := range strings.Split(challenge[len(scheme):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+	if params["realm"] == "" {
+		return nil, fmt.Errorf("Authentication challenge has no realm: %q", challenge)
+	}
+	return params, nil
+}
+
+// getManifest gets the image's manifest for linux/amd64, which a manifest list points to.
+func (c *registryClient) getManifest(img Image) (*manifest, error) {
+	ref := img.Tag
+	if img.Digest != "" {
+		ref = img.Digest.String()
+	}
+	for {
+		resp, err := c.get(img, "/manifests/"+ref, manifestV2Type, manifestListType)
+		if err != nil {
+			return nil, err
+		}
+		var m manifest
+		err = json.NewDecoder(resp.Body).Decode(&m)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding manifest of %s", img)
+		}
+		switch m.MediaType {
+		case manifestV2Type:
+			return &m, nil
+		case manifestListType:
+			ref = ""
+			for _, d := range m.Manifests {
+				if d.Platform != nil && d.Platform.OS == "linux" && d.Platform.Architecture == "amd64" {
+					ref = d.Digest.String()
+				}
+			}
+			if ref == "" {
+				return nil, fmt.Errorf("Image %s has no linux/amd64 manifest", img)
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported manifest type %q of image %s", m.MediaType, img)
+		}
+	}
+}
+
+// copyBlob writes the blob of the descriptor to w, checking its digest and size.
+func (c *registryClient) copyBlob(img Image, d descriptor, w io.Writer) error {
+	resp, err := c.get(img, "/blobs/"+d.Digest.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	verifier, err := digest.NewDigestVerifier(d.Digest)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(io.MultiWriter(w, verifier), io.LimitReader(resp.Body, d.Size))
+	if err != nil {
+		return errors.Wrapf(err, "Error reading blob %s", d.Digest)
+	}
+	if n != d.Size || !verifier.Verified() {
+		return fmt.Errorf("Blob %s of image %s doesn't match its digest", d.Digest, img)
+	}
+	return nil
+}
+
+// pull writes the image to w as a docker save tarball, which docker load reads.
+// It returns the image's ID, the digest of its config.
+func (c *registryClient) pull(img Image, w io.Writer) (digest.Digest, error) {
+	m, err := c.getManifest(img)
+	if err != nil {
+		return "", err
+	}
+
+	tw := tar.NewWriter(w)
+	configName := m.Config.Digest.Hex() + ".json"
+	save := saveManifest{Config: configName}
+	if img.Tag != "" {
+		save.RepoTags = []string{img.String()}
+	}
+	for _, l := range m.Layers {
+		// docker load decompresses the layers itself.
+		save.Layers = append(save.Layers, l.Digest.Hex()+"/layer.tar")
+	}
+	// The manifest comes first, so that the image's ID can be read without going through its layers.
+	b, err := json.Marshal([]saveManifest{save})
+	if err != nil {
+		return "", err
+	}
+	if err := writeTarFile(tw, manifestFile, int64(len(b)), func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	files := append([]descriptor{m.Config}, m.Layers...)
+	names := append([]string{configName}, save.Layers...)
+	for i, d := range files {
+		glog.Infof("Pulling %s of %s", d.Digest, img)
+		if err := writeTarFile(tw, names[i], d.Size, func(w io.Writer) error {
+			return c.copyBlob(img, d, w)
+		}); err != nil {
+			return "", errors.Wrapf(err, "Error pulling %s", img)
+		}
+	}
+	return m.Config.Digest, tw.Close()
+}
+
+const manifestFile = "manifest.json"
+
+func writeTarFile(tw *tar.Writer, name string, size int64, write func(io.Writer) error) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: size, Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	return write(tw)
+}
+
+// readImageID reads the ID of the image in a docker save tarball from its manifest.
+func readImageID(r io.Reader) (digest.Digest, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", errors.New("No manifest.json in image tarball")
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "Error reading image tarball")
+		}
+		if hdr.Name != manifestFile {
+			continue
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return "", err
+		}
+		var save []saveManifest
+		if err := json.Unmarshal(b, &save); err != nil {
+			return "", errors.Wrap(err, "Error decoding manifest.json of image tarball")
+		}
+		if len(save) != 1 {
+			return "", fmt.Errorf("Expected one image in tarball, found %d", len(save))
+		}
+		id := digest.NewDigestFromHex(string(digest.SHA256), strings.TrimSuffix(save[0].Config, ".json"))
+		return id, id.Validate()
+	}
+}
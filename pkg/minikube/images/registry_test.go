@@ -0,0 +1,181 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+)
+
+// fakeRegistry serves the test/app image, as a manifest list pointing to a
+// linux/amd64 manifest, to clients holding the token it hands out.
+type fakeRegistry struct {
+	server *httptest.Server
+	blobs  map[digest.Digest][]byte
+	list   []byte
+	config digest.Digest
+	layer  digest.Digest
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{blobs: map[digest.Digest][]byte{}}
+	config, layer := []byte(`{"architecture":"amd64"}`), []byte("layer contents")
+	r.config, r.layer = digest.FromBytes(config), digest.FromBytes(layer)
+	r.blobs[r.config], r.blobs[r.layer] = config, layer
+
+	m, err := json.Marshal(manifest{
+		MediaType: manifestV2Type,
+		Config:    descriptor{Size: int64(len(config)), Digest: r.config},
+		Layers:    []descriptor{{Size: int64(len(layer)), Digest: r.layer}},
+	})
+	if err != nil {
+		t.Fatalf("Error marshalling manifest: %s", err)
+	}
+	r.blobs[digest.FromBytes(m)] = m
+	r.list = []byte(fmt.Sprintf(`{"mediaType": %q, "manifests": [
+		{"digest": "sha256:%s", "platform": {"architecture": "arm", "os": "linux"}},
+		{"digest": %q, "platform": {"architecture": "amd64", "os": "linux"}}]}`,
+		manifestListType, strings.Repeat("0", 64), digest.FromBytes(m)))
+
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if req.URL.Query().Get("scope") != "repository:test/app:pull" {
+			http.Error(w, "unexpected scope", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"token": "secret"}`)
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:test/app:pull"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	const prefix = "/v2/test/app/"
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	switch {
+	case path == "manifests/v1":
+		w.Write(r.list)
+	case strings.HasPrefix(path, "manifests/"), strings.HasPrefix(path, "blobs/"):
+		b, ok := r.blobs[digest.Digest(path[strings.Index(path, "/")+1:])]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(b)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func TestCacheAddListDelete(t *testing.T) {
+	r := newFakeRegistry(t)
+	defer r.server.Close()
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	host := strings.TrimPrefix(r.server.URL, "http://")
+	ref := host + "/test/app:v1"
+	if err := Add(dir, []string{ref}); err != nil {
+		t.Fatalf("Unexpected error caching %s: %s", ref, err)
+	}
+
+	cached, err := List(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error listing cached images: %s", err)
+	}
+	if len(cached) != 1 || cached[0].String() != ref {
+		t.Fatalf("Expected %s to be cached, got %v", ref, cached)
+	}
+	expectedPath := filepath.Join(dir, strings.Replace(host, ":", "_", 1), "test", "app", "v1.tar")
+	if cached[0].Path != expectedPath {
+		t.Errorf("Expected %s to be cached at %s, got %s", ref, expectedPath, cached[0].Path)
+	}
+
+	f, err := os.Open(cached[0].Path)
+	if err != nil {
+		t.Fatalf("Error opening cached image: %s", err)
+	}
+	defer f.Close()
+	files := map[string]string{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(tr)
+		files[hdr.Name] = string(b)
+	}
+	expectedFiles := map[string]string{
+		"manifest.json":              fmt.Sprintf(`[{"Config":"%s.json","RepoTags":["%s"],"Layers":["%s/layer.tar"]}]`, r.config.Hex(), ref, r.layer.Hex()),
+		r.config.Hex() + ".json":     string(r.blobs[r.config]),
+		r.layer.Hex() + "/layer.tar": string(r.blobs[r.layer]),
+	}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("Expected the image tarball to contain %v, got %v", expectedFiles, files)
+	}
+	if id, err := cachedImageIDOf(cached[0].Path); err != nil || id != r.config {
+		t.Errorf("Expected the cached image's ID to be %s, got %s, %v", r.config, id, err)
+	}
+
+	if err := Delete(dir, []string{host + "/test/app:v2"}); err == nil {
+		t.Error("Expected an error deleting an image which isn't cached")
+	}
+	if err := Delete(dir, []string{ref}); err != nil {
+		t.Fatalf("Unexpected error deleting %s: %s", ref, err)
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the empty directories to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestCacheAddMissingImage(t *testing.T) {
+	r := newFakeRegistry(t)
+	defer r.server.Close()
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ref := strings.TrimPrefix(r.server.URL, "http://") + "/test/app:missing"
+	if err := Add(dir, []string{ref}); err == nil {
+		t.Fatalf("Expected an error caching %s", ref)
+	}
+	if cached, _ := List(dir); len(cached) != 0 {
+		t.Errorf("Expected nothing to be cached, got %v", cached)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"regexp"
+	"strings"
+)
+
+// googleRepositories are the repositories the images of the cluster are pulled from,
+// which an image repository mirrors. The addon manager's has a dash rather than an underscore.
+var googleRepositories = []string{"gcr.io/google_containers", "gcr.io/google-containers"}
+
+// WithRepository returns the image reference pulled from the repository instead, if the
+// image is in one of the Google repositories. Its path in the repository and its tag or
+// digest are kept, so gcr.io/google_containers/pause-amd64:3.0 becomes <repository>/pause-amd64:3.0.
+func WithRepository(ref, repository string) string {
+	repository = strings.TrimSuffix(repository, "/")
+	if repository == "" {
+		return ref
+	}
+	for _, r := range googleRepositories {
+		if strings.HasPrefix(ref, r+"/") {
+			return repository + ref[len(r):]
+		}
+	}
+	return ref
+}
+
+// manifestImage matches the image fields of a YAML manifest, quoted or not.
+var manifestImage = regexp.MustCompile(`(?m)^([ \t]*(?:- )?[ \t]*image:[ \t]*["']?)([^"'\s]+)`)
+
+// ManifestWithRepository rewrites the images of a YAML manifest with WithRepository.
+func ManifestWithRepository(data []byte, repository string) []byte {
+	return manifestImage.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := manifestImage.FindSubmatch(m)
+		return append(append([]byte{}, sub[1]...), WithRepository(string(sub[2]), repository)...)
+	})
+}
+
+// ManifestImages returns the images of a YAML manifest.
+func ManifestImages(data []byte) []string {
+	refs := []string{}
+	for _, sub := range manifestImage.FindAllSubmatch(data, -1) {
+		refs = append(refs, string(sub[2]))
+	}
+	return refs
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithRepository(t *testing.T) {
+	var tests = []struct {
+		description string
+		ref         string
+		repository  string
+		expected    string
+	}{
+		{
+			description: "no repository",
+			ref:         "gcr.io/google_containers/pause-amd64:3.0",
+			expected:    "gcr.io/google_containers/pause-amd64:3.0",
+		},
+		{
+			description: "registry",
+			ref:         "gcr.io/google_containers/pause-amd64:3.0",
+			repository:  "registry.example.com",
+			expected:    "registry.example.com/pause-amd64:3.0",
+		},
+		{
+			description: "registry with port",
+			ref:         "gcr.io/google_containers/kubernetes-dashboard-amd64:v1.6.1",
+			repository:  "localhost:5000",
+			expected:    "localhost:5000/kubernetes-dashboard-amd64:v1.6.1",
+		},
+		{
+			description: "nested path",
+			ref:         "gcr.io/google_containers/heapster:v1.3.0",
+			repository:  "registry.example.com:5000/mirrors/google_containers/",
+			expected:    "registry.example.com:5000/mirrors/google_containers/heapster:v1.3.0",
+		},
+		{
+			description: "repository with a dash",
+			ref:         "gcr.io/google-containers/kube-addon-manager:v6.4-beta.1",
+			repository:  "registry.example.com/k8s",
+			expected:    "registry.example.com/k8s/kube-addon-manager:v6.4-beta.1",
+		},
+		{
+			description: "digest",
+			ref:         "gcr.io/google_containers/pause-amd64@sha256:163ac025575b775d1c0f9bf0bdd0f086883171eb475b5068e7defa4ca9e76516",
+			repository:  "registry.example.com:5000/k8s",
+			expected:    "registry.example.com:5000/k8s/pause-amd64@sha256:163ac025575b775d1c0f9bf0bdd0f086883171eb475b5068e7defa4ca9e76516",
+		},
+		{
+			description: "other registry",
+			ref:         "upmcenterprises/registry-creds:1.7",
+			repository:  "registry.example.com",
+			expected:    "upmcenterprises/registry-creds:1.7",
+		},
+		{
+			description: "other repository",
+			ref:         "gcr.io/google_containers_ext/foo:1.0",
+			repository:  "registry.example.com",
+			expected:    "gcr.io/google_containers_ext/foo:1.0",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if got := WithRepository(test.ref, test.repository); got != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+const testManifest = `spec:
+  containers:
+  - name: kubedns
+    image: gcr.io/google_containers/k8s-dns-kube-dns-amd64:1.14.2
+  - image: "gcr.io/google_containers/nginx-ingress-controller:0.9.0-beta.4"
+    name: nginx
+  - name: creds
+    image: upmcenterprises/registry-creds:1.7
+    imagePullPolicy: IfNotPresent
+`
+
+func TestManifestWithRepository(t *testing.T) {
+	expected := `spec:
+  containers:
+  - name: kubedns
+    image: localhost:5000/k8s/k8s-dns-kube-dns-amd64:1.14.2
+  - image: "localhost:5000/k8s/nginx-ingress-controller:0.9.0-beta.4"
+    name: nginx
+  - name: creds
+    image: upmcenterprises/registry-creds:1.7
+    imagePullPolicy: IfNotPresent
+`
+	if got := string(ManifestWithRepository([]byte(testManifest), "localhost:5000/k8s")); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestManifestImages(t *testing.T) {
+	expected := []string{
+		"gcr.io/google_containers/k8s-dns-kube-dns-amd64:1.14.2",
+		"gcr.io/google_containers/nginx-ingress-controller:0.9.0-beta.4",
+		"upmcenterprises/registry-creds:1.7",
+	}
+	if got := ManifestImages([]byte(testManifest)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
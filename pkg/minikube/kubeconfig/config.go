@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/clientcmd/api/latest"
+	"k8s.io/minikube/pkg/minikube/constants"
 )
 
 type KubeConfigSetup struct {
@@ -48,6 +49,9 @@ type KubeConfigSetup struct {
 	// Should the current context be kept when setting up this one
 	KeepContext bool
 
+	// EmbedCerts inlines the certificates and the key in the config, rather than referencing their files.
+	EmbedCerts bool
+
 	// kubeConfigFile is the path where the kube config is stored
 	// Only access this with atomic ops
 	kubeConfigFile atomic.Value
@@ -73,17 +77,52 @@ func SetupKubeConfig(cfg *KubeConfigSetup) error {
 		return err
 	}
 
+	if err := PopulateKubeConfig(cfg, config); err != nil {
+		return err
+	}
+
+	// write back to disk
+	if err := WriteConfig(config, cfg.GetKubeConfigFile()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PopulateKubeConfig adds, or replaces, the minikube cluster, user and context in config,
+// leaving the others as they are.
+func PopulateKubeConfig(cfg *KubeConfigSetup, config *api.Config) error {
 	clusterName := cfg.ClusterName
 	cluster := api.NewCluster()
 	cluster.Server = cfg.ClusterServerAddress
-	cluster.CertificateAuthority = cfg.CertificateAuthority
+	if cfg.EmbedCerts {
+		data, err := ioutil.ReadFile(cfg.CertificateAuthority)
+		if err != nil {
+			return errors.Wrap(err, "Error reading certificate authority")
+		}
+		cluster.CertificateAuthorityData = data
+	} else {
+		cluster.CertificateAuthority = cfg.CertificateAuthority
+	}
 	config.Clusters[clusterName] = cluster
 
 	// user
 	userName := cfg.ClusterName
 	user := api.NewAuthInfo()
-	user.ClientCertificate = cfg.ClientCertificate
-	user.ClientKey = cfg.ClientKey
+	if cfg.EmbedCerts {
+		cert, err := ioutil.ReadFile(cfg.ClientCertificate)
+		if err != nil {
+			return errors.Wrap(err, "Error reading client certificate")
+		}
+		key, err := ioutil.ReadFile(cfg.ClientKey)
+		if err != nil {
+			return errors.Wrap(err, "Error reading client key")
+		}
+		user.ClientCertificateData = cert
+		user.ClientKeyData = key
+	} else {
+		user.ClientCertificate = cfg.ClientCertificate
+		user.ClientKey = cfg.ClientKey
+	}
 	config.AuthInfos[userName] = user
 
 	// context
@@ -97,12 +136,68 @@ func SetupKubeConfig(cfg *KubeConfigSetup) error {
 	if !cfg.KeepContext {
 		config.CurrentContext = contextName
 	}
+	return nil
+}
 
-	// write back to disk
-	if err := WriteConfig(config, cfg.GetKubeConfigFile()); err != nil {
+// DeleteKubeConfigContext removes the named cluster, user and context, as SetupKubeConfig
+// adds them, from the config in the given file, leaving the rest of it as it is. The
+// current context is unset if it was the named one. A missing file is left missing.
+func DeleteKubeConfigContext(filename, name string) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	config, err := ReadConfigOrNew(filename)
+	if err != nil {
 		return err
 	}
-	return nil
+	delete(config.Clusters, name)
+	delete(config.AuthInfos, name)
+	delete(config.Contexts, name)
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+	return WriteConfig(config, filename)
+}
+
+// ContextServer returns the server address of the cluster the named context of the config
+// in the given file points at, or "" if the file, context or cluster is missing.
+func ContextServer(filename, name string) (string, error) {
+	config, err := ReadConfigOrNew(filename)
+	if err != nil {
+		return "", err
+	}
+	context, ok := config.Contexts[name]
+	if !ok {
+		return "", nil
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok {
+		return "", nil
+	}
+	return cluster.Server, nil
+}
+
+// SetCurrentContext makes the named context the current one of the config in the given file.
+// It fails if the config has no such context.
+func SetCurrentContext(filename, name string) error {
+	config, err := ReadConfigOrNew(filename)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return errors.Errorf("%s has no context %s", filename, name)
+	}
+	config.CurrentContext = name
+	return WriteConfig(config, filename)
+}
+
+// DefaultPath returns the kubeconfig file minikube sets its contexts up in: the first one of
+// $KUBECONFIG, or ~/.kube/config.
+func DefaultPath() string {
+	if env := os.Getenv(constants.KubeconfigEnvVar); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+	return constants.KubeconfigPath
 }
 
 // ReadConfigOrNew retrieves Kubernetes client configuration from a file.
@@ -135,6 +230,20 @@ func ReadConfigOrNew(filename string) (*api.Config, error) {
 	return config, nil
 }
 
+// EncodeKubeConfig returns a config holding only the minikube cluster, user and
+// context, encoded as YAML.
+func EncodeKubeConfig(cfg *KubeConfigSetup) ([]byte, error) {
+	config := api.NewConfig()
+	if err := PopulateKubeConfig(cfg, config); err != nil {
+		return nil, err
+	}
+	data, err := runtime.Encode(latest.Codec, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encoding config")
+	}
+	return data, nil
+}
+
 // WriteConfig encodes the configuration and writes it to the given file.
 // If the file exists, it's contents will be overwritten.
 func WriteConfig(config *api.Config, filename string) error {
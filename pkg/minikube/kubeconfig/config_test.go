@@ -17,12 +17,16 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"encoding/base64"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/minikube/pkg/minikube/constants"
 )
@@ -180,6 +184,345 @@ func TestNewConfig(t *testing.T) {
 
 // tempFile creates a temporary with the provided bytes as its contents.
 // The caller is responsible for deleting file after use.
+func TestDeleteKubeConfigContext(t *testing.T) {
+	var tests = []struct {
+		description    string
+		name           string
+		currentContext string
+	}{
+		{
+			description:    "current context",
+			name:           "la-croix",
+			currentContext: "",
+		},
+		{
+			description:    "other context",
+			name:           "minikube",
+			currentContext: "la-croix",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			tmp := tempFile(t, fakeKubeCfg)
+			defer os.Remove(tmp)
+			if test.name == "minikube" {
+				setup := &KubeConfigSetup{ClusterName: "minikube", KeepContext: true}
+				setup.SetKubeConfigFile(tmp)
+				if err := SetupKubeConfig(setup); err != nil {
+					t.Fatalf("Error setting up kubeconfig: %s", err)
+				}
+			}
+
+			if err := DeleteKubeConfigContext(tmp, test.name); err != nil {
+				t.Fatalf("Error deleting context: %s", err)
+			}
+			config, err := ReadConfigOrNew(tmp)
+			if err != nil {
+				t.Fatalf("Error reading kubeconfig file: %s", err)
+			}
+			if _, ok := config.Contexts[test.name]; ok {
+				t.Errorf("Expected context %s to be deleted", test.name)
+			}
+			if _, ok := config.Clusters[test.name]; ok {
+				t.Errorf("Expected cluster %s to be deleted", test.name)
+			}
+			if _, ok := config.AuthInfos[test.name]; ok {
+				t.Errorf("Expected user %s to be deleted", test.name)
+			}
+			if config.CurrentContext != test.currentContext {
+				t.Errorf("Expected current context %q, got %q", test.currentContext, config.CurrentContext)
+			}
+			if test.name != "la-croix" {
+				if _, ok := config.Contexts["la-croix"]; !ok {
+					t.Error("Expected the other context to be kept")
+				}
+			}
+		})
+	}
+}
+
+func TestContextServer(t *testing.T) {
+	tmp := tempFile(t, fakeKubeCfg)
+	defer os.Remove(tmp)
+
+	var tests = []struct {
+		description string
+		name        string
+		expected    string
+	}{
+		{
+			description: "existing context",
+			name:        "la-croix",
+			expected:    "192.168.1.1:8080",
+		},
+		{
+			description: "missing context",
+			name:        "minikube",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			server, err := ContextServer(tmp, test.name)
+			if err != nil {
+				t.Fatalf("Error getting server: %s", err)
+			}
+			if server != test.expected {
+				t.Errorf("Expected server %q, got %q", test.expected, server)
+			}
+		})
+	}
+}
+
+func TestSetCurrentContext(t *testing.T) {
+	tmp := tempFile(t, fakeKubeCfg)
+	defer os.Remove(tmp)
+	setup := &KubeConfigSetup{ClusterName: "project", KeepContext: true}
+	setup.SetKubeConfigFile(tmp)
+	if err := SetupKubeConfig(setup); err != nil {
+		t.Fatalf("Error setting up kubeconfig: %s", err)
+	}
+
+	if err := SetCurrentContext(tmp, "other"); err == nil {
+		t.Error("Expected an error switching to a missing context")
+	}
+	if err := SetCurrentContext(tmp, "project"); err != nil {
+		t.Fatalf("Error switching context: %s", err)
+	}
+	config, err := ReadConfigOrNew(tmp)
+	if err != nil {
+		t.Fatalf("Error reading kubeconfig file: %s", err)
+	}
+	if config.CurrentContext != "project" {
+		t.Errorf("Expected current context project, got %s", config.CurrentContext)
+	}
+	if _, ok := config.Contexts["la-croix"]; !ok {
+		t.Error("Expected the other contexts to be kept")
+	}
+}
+
+func TestDeleteKubeConfigContextMissingFile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Error making temp directory %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	filename := filepath.Join(tmpDir, "kubeconfig")
+
+	if err := DeleteKubeConfigContext(filename, "minikube"); err != nil {
+		t.Fatalf("Error deleting context: %s", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Expected the kubeconfig not to be created, got: %v", err)
+	}
+}
+
+var otherClustersKubeCfg = []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority: /home/la-croix/apiserver.crt
+    server: 192.168.1.1:8080
+  name: la-croix
+- cluster:
+    certificate-authority-data: Y2VydGlmaWNhdGU=
+    server: https://prod.example.com
+  name: prod
+contexts:
+- context:
+    cluster: la-croix
+    user: la-croix
+  name: la-croix
+- context:
+    cluster: prod
+    namespace: web
+    user: admin
+  name: prod
+current-context: prod
+kind: Config
+preferences: {}
+users:
+- name: la-croix
+  user:
+    client-certificate: /home/la-croix/apiserver.crt
+    client-key: /home/la-croix/apiserver.key
+- name: admin
+  user:
+    token: secret
+`)
+
+func TestSetupKubeConfigMerge(t *testing.T) {
+	var tests = []struct {
+		description string
+		embedCerts  bool
+	}{
+		{
+			description: "referencing certs",
+		},
+		{
+			description: "embedding certs",
+			embedCerts:  true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			tmpDir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatalf("Error making temp directory %s", err)
+			}
+			defer os.RemoveAll(tmpDir)
+			setup := certsSetup(t, tmpDir)
+			setup.EmbedCerts = test.embedCerts
+			setup.KeepContext = true
+			setup.SetKubeConfigFile(filepath.Join(tmpDir, "kubeconfig"))
+			if err := ioutil.WriteFile(setup.GetKubeConfigFile(), otherClustersKubeCfg, 0600); err != nil {
+				t.Fatalf("Error writing kubeconfig: %s", err)
+			}
+			before, err := decode(otherClustersKubeCfg)
+			if err != nil {
+				t.Fatalf("Error decoding kubeconfig: %s", err)
+			}
+
+			if err := SetupKubeConfig(setup); err != nil {
+				t.Fatalf("Error setting up kubeconfig: %s", err)
+			}
+			after, err := ReadConfigOrNew(setup.GetKubeConfigFile())
+			if err != nil {
+				t.Fatalf("Error reading kubeconfig file: %s", err)
+			}
+
+			// Nothing but the minikube entry changes.
+			delete(after.Clusters, "minikube")
+			delete(after.AuthInfos, "minikube")
+			delete(after.Contexts, "minikube")
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("Expected the other entries to be kept as they were.\nBefore: %+v\nAfter: %+v", before, after)
+			}
+		})
+	}
+}
+
+func TestPopulateKubeConfigEmbedCerts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Error making temp directory %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	setup := certsSetup(t, tmpDir)
+	setup.EmbedCerts = true
+
+	data, err := EncodeKubeConfig(setup)
+	if err != nil {
+		t.Fatalf("Error encoding kubeconfig: %s", err)
+	}
+	config, err := decode(data)
+	if err != nil {
+		t.Fatalf("Error decoding kubeconfig %s: %s", data, err)
+	}
+	if len(config.Clusters) != 1 || len(config.AuthInfos) != 1 || len(config.Contexts) != 1 {
+		t.Fatalf("Expected only the minikube entry, got: %s", data)
+	}
+	if config.CurrentContext != "minikube" {
+		t.Errorf("Expected the current context to be minikube, got %q", config.CurrentContext)
+	}
+	cluster, user := config.Clusters["minikube"], config.AuthInfos["minikube"]
+	if cluster.CertificateAuthority != "" || user.ClientCertificate != "" || user.ClientKey != "" {
+		t.Errorf("Expected no cert paths with embedded certs, got: %s", data)
+	}
+	for _, embedded := range []struct {
+		data []byte
+		file string
+	}{
+		{cluster.CertificateAuthorityData, "ca.crt"},
+		{user.ClientCertificateData, "apiserver.crt"},
+		{user.ClientKeyData, "apiserver.key"},
+	} {
+		if string(embedded.data) != "PEM data of "+embedded.file {
+			t.Errorf("Expected %s to be embedded, got %q", embedded.file, embedded.data)
+		}
+	}
+
+	setup.ClientKey = filepath.Join(tmpDir, "missing.key")
+	if _, err := EncodeKubeConfig(setup); err == nil {
+		t.Error("Expected an error embedding a missing key")
+	}
+}
+
+func TestSetupKubeConfigEmbedCertsRotation(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Error making temp directory %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	setup := certsSetup(t, tmpDir)
+	setup.EmbedCerts = true
+	setup.SetKubeConfigFile(filepath.Join(tmpDir, "kubeconfig"))
+
+	for _, generation := range []string{"first", "rotated"} {
+		for _, path := range []string{setup.ClientCertificate, setup.ClientKey, setup.CertificateAuthority} {
+			if err := ioutil.WriteFile(path, []byte(generation+" PEM data of "+filepath.Base(path)), 0600); err != nil {
+				t.Fatalf("Error writing %s: %s", path, err)
+			}
+		}
+		if err := SetupKubeConfig(setup); err != nil {
+			t.Fatalf("Error setting up kubeconfig: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(setup.GetKubeConfigFile())
+		if err != nil {
+			t.Fatalf("Error reading kubeconfig file: %s", err)
+		}
+		for _, field := range []string{"certificate-authority:", "client-certificate:", "client-key:"} {
+			if strings.Contains(string(data), field) {
+				t.Errorf("Expected no %s path with embedded certs, got:\n%s", field, data)
+			}
+		}
+		if ca := base64.StdEncoding.EncodeToString([]byte(generation + " PEM data of ca.crt")); !strings.Contains(string(data), "certificate-authority-data: "+ca) {
+			t.Errorf("Expected the CA to be embedded as base64, got:\n%s", data)
+		}
+
+		// kubectl reads the kubeconfig through clientcmd.
+		config, err := clientcmd.LoadFromFile(setup.GetKubeConfigFile())
+		if err != nil {
+			t.Fatalf("Error loading kubeconfig with clientcmd: %s", err)
+		}
+		restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			t.Fatalf("Error building client config: %s", err)
+		}
+		if expected := generation + " PEM data of apiserver.crt"; string(restConfig.TLSClientConfig.CertData) != expected {
+			t.Errorf("Expected client certificate %q, got %q", expected, restConfig.TLSClientConfig.CertData)
+		}
+		if expected := generation + " PEM data of apiserver.key"; string(restConfig.TLSClientConfig.KeyData) != expected {
+			t.Errorf("Expected client key %q, got %q", expected, restConfig.TLSClientConfig.KeyData)
+		}
+		if expected := generation + " PEM data of ca.crt"; string(restConfig.TLSClientConfig.CAData) != expected {
+			t.Errorf("Expected CA %q, got %q", expected, restConfig.TLSClientConfig.CAData)
+		}
+	}
+}
+
+// certsSetup returns the setup of a minikube entry whose certs and key are files in dir.
+func certsSetup(t *testing.T, dir string) *KubeConfigSetup {
+	setup := &KubeConfigSetup{
+		ClusterName:          "minikube",
+		ClusterServerAddress: "https://192.168.99.100:8443",
+		ClientCertificate:    filepath.Join(dir, "apiserver.crt"),
+		ClientKey:            filepath.Join(dir, "apiserver.key"),
+		CertificateAuthority: filepath.Join(dir, "ca.crt"),
+	}
+	for _, path := range []string{setup.ClientCertificate, setup.ClientKey, setup.CertificateAuthority} {
+		if err := ioutil.WriteFile(path, []byte("PEM data of "+filepath.Base(path)), 0600); err != nil {
+			t.Fatalf("Error writing %s: %s", path, err)
+		}
+	}
+	return setup
+}
+
 func tempFile(t *testing.T, data []byte) string {
 	tmp, err := ioutil.TempFile("", "kubeconfig")
 	if err != nil {
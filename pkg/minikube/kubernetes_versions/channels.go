@@ -0,0 +1,69 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes_versions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// The release channels a Kubernetes version can be given as, which are resolved to the
+// version currently released on them.
+const (
+	ChannelStable = "stable"
+	ChannelLatest = "latest"
+)
+
+// IsChannel returns whether v names a release channel rather than a version.
+func IsChannel(v string) bool {
+	return v == ChannelStable || v == ChannelLatest
+}
+
+// resolvedChannels caches the versions channel files held, so they are only fetched once.
+var resolvedChannels = map[string]string{}
+
+// ResolveChannel returns the version currently released on the channel, which the
+// channel's file under url, such as stable.txt, holds.
+func ResolveChannel(channel, url string) (string, error) {
+	channelURL := fmt.Sprintf("%s/%s.txt", strings.TrimSuffix(url, "/"), channel)
+	if v, ok := resolvedChannels[channelURL]; ok {
+		return v, nil
+	}
+	r, err := http.Get(channelURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting %s", channelURL)
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error getting %s: %s", channelURL, r.Status)
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error reading %s", channelURL)
+	}
+	v := strings.TrimSpace(string(b))
+	if _, err := semver.Make(strings.TrimPrefix(v, "v")); err != nil || !strings.HasPrefix(v, "v") {
+		return "", fmt.Errorf("%s doesn't hold a Kubernetes version: %q", channelURL, v)
+	}
+	resolvedChannels[channelURL] = v
+	return v, nil
+}
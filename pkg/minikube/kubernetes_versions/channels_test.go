@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes_versions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// channelHandler serves channel files, counting the requests.
+type channelHandler struct {
+	files    map[string]string
+	requests int
+}
+
+func (h *channelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests++
+	body, ok := h.files[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, body)
+}
+
+func TestResolveChannel(t *testing.T) {
+	var tests = []struct {
+		description string
+		channel     string
+		files       map[string]string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "stable",
+			channel:     ChannelStable,
+			files:       map[string]string{"/stable.txt": "v1.7.0\n", "/latest.txt": "v1.8.0-alpha.1\n"},
+			expected:    "v1.7.0",
+		},
+		{
+			description: "latest",
+			channel:     ChannelLatest,
+			files:       map[string]string{"/stable.txt": "v1.7.0\n", "/latest.txt": "v1.8.0-alpha.1\n"},
+			expected:    "v1.8.0-alpha.1",
+		},
+		{
+			description: "not a version",
+			channel:     ChannelStable,
+			files:       map[string]string{"/stable.txt": "<html>Moved</html>"},
+			shouldErr:   true,
+		},
+		{
+			description: "missing channel file",
+			channel:     ChannelLatest,
+			files:       map[string]string{"/stable.txt": "v1.7.0\n"},
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			resolvedChannels = map[string]string{}
+
+			server := httptest.NewServer(&channelHandler{files: test.files})
+			defer server.Close()
+			version, err := ResolveChannel(test.channel, server.URL)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Got unexpected error: %v", err)
+				return
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Got no error but expected an error, resolved to %s", version)
+				return
+			}
+			if version != test.expected {
+				t.Errorf("Expected %s to resolve to %s, got %s", test.channel, test.expected, version)
+			}
+		})
+	}
+}
+
+func TestResolveChannelCached(t *testing.T) {
+	resolvedChannels = map[string]string{}
+
+	handler := &channelHandler{files: map[string]string{"/stable.txt": "v1.7.0\n"}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := ResolveChannel(ChannelStable, server.URL); err != nil {
+			t.Fatalf("Error resolving the stable channel: %s", err)
+		}
+	}
+	if handler.requests != 1 {
+		t.Errorf("Expected the stable channel to be fetched once, it was fetched %d times", handler.requests)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs gathers the logs of the VM for minikube logs: those of localkube, of the container
+// runtime, of the kernel and of the containers of the cluster.
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// Source is a log of the VM, printed by its commands.
+type Source struct {
+	Name string
+	// Command prints the last lines of the log.
+	Command string
+	// Follow prints the last lines of the log and then those appended to it, until it is
+	// interrupted. The sources without one are left out when following.
+	Follow string
+}
+
+// Runner runs the commands printing the logs in the VM.
+type Runner interface {
+	// Run runs the command, and returns its output.
+	Run(cmd string) (string, error)
+	// Stream runs the command, writing its output to w as it comes, until it exits.
+	Stream(cmd string, w io.Writer) error
+}
+
+// journalSource is the source of the last lines of the journal of a systemd unit,
+// or of the kernel's messages for the -k flag.
+func journalSource(name, unit string, lines int) Source {
+	return Source{
+		Name:    name,
+		Command: fmt.Sprintf("sudo journalctl --no-pager %s -n %d", unit, lines),
+		Follow:  fmt.Sprintf("sudo journalctl --no-pager %s -n %d -f", unit, lines),
+	}
+}
+
+// Sources returns the logs of the VM, with their last lines: those of localkube, which runs the
+// kubelet, of the container runtime, the kernel's, and those of the containers of the pods
+// of kube-system the runtime lists. Failing to list the containers only leaves them out.
+func Sources(r Runner, rt cruntime.Runtime, lines int) []Source {
+	sources := []Source{
+		journalSource("localkube", "-u localkube", lines),
+		journalSource(rt.Service, "-u "+rt.Service, lines),
+		{Name: "dmesg", Command: fmt.Sprintf("sudo dmesg | tail -n %d", lines), Follow: fmt.Sprintf("sudo journalctl --no-pager -k -n %d -f", lines)},
+	}
+	list := rt.ListContainersCommand("kube-system")
+	if list == "" {
+		return sources
+	}
+	out, err := r.Run(list)
+	if err != nil {
+		glog.Warningf("Error listing the containers of kube-system: %s: %s", err, out)
+		return sources
+	}
+	for _, c := range rt.ParseContainers(out) {
+		sources = append(sources, Source{
+			Name:    c.Pod + "/" + c.Name,
+			Command: rt.ContainerLogsCommand(c, lines, false),
+			Follow:  rt.ContainerLogsCommand(c, lines, true),
+		})
+	}
+	return sources
+}
+
+// problemPatterns match the lines pointing out why the cluster doesn't work.
+var problemPatterns = []*regexp.Regexp{
+	// The errors and fatal errors glog logs, as in "E1014 12:00:00.000000".
+	regexp.MustCompile(`\b[EF]\d{4} \d{2}:\d{2}:\d{2}`),
+	regexp.MustCompile(`(?i)\b(error|failed|failure|fatal|panic)\b`),
+	regexp.MustCompile(`(?i)out of memory|oom[- ]?kill`),
+	regexp.MustCompile(`(?i)no space left on device`),
+	regexp.MustCompile(`(?i)permission denied`),
+	regexp.MustCompile(`(?i)connection refused`),
+	regexp.MustCompile(`(?i)i/o timeout|timed out`),
+	regexp.MustCompile(`(?i)back-off|crashloop`),
+}
+
+// IsProblem returns whether the line of a log points out a problem.
+func IsProblem(line string) bool {
+	for _, p := range problemPatterns {
+		if p.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineWriter writes the lines written to it to w with a prefix, whole, so that the lines of
+// several sources don't mix. With problems, only the lines pointing out problems are written.
+type lineWriter struct {
+	mu       *sync.Mutex
+	w        io.Writer
+	prefix   string
+	problems bool
+	partial  []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(l.partial[:i])
+		l.partial = l.partial[i+1:]
+		if err := l.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes the last line, when it isn't terminated.
+func (l *lineWriter) Flush() error {
+	if len(l.partial) == 0 {
+		return nil
+	}
+	line := string(l.partial)
+	l.partial = nil
+	return l.writeLine(line)
+}
+
+func (l *lineWriter) writeLine(line string) error {
+	line = strings.TrimSuffix(line, "\r")
+	if l.problems && !IsProblem(line) {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := fmt.Fprintf(l.w, "[%s] %s\n", l.prefix, line)
+	return err
+}
+
+// Output writes the last lines of the sources to w, one source after the other, each line prefixed
+// with the name of its source. With problems, only the lines pointing out problems are written.
+// The sources which fail are pointed out along with the others, and only fail Output if all do.
+func Output(r Runner, sources []Source, w io.Writer, problems bool) error {
+	mu := &sync.Mutex{}
+	failed := 0
+	for _, s := range sources {
+		out, err := r.Run(s.Command)
+		lw := &lineWriter{mu: mu, w: w, prefix: s.Name, problems: problems}
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			if err := lw.writeLine(scanner.Text()); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "[%s] Error getting the logs: %s\n", s.Name, err)
+		}
+	}
+	if failed > 0 && failed == len(sources) {
+		return errors.New("Error getting the logs of all the sources")
+	}
+	return nil
+}
+
+// Follow writes the last lines of the sources with a command following them to w, and the lines
+// appended to them after, as they come, each prefixed with the name of its source. It returns
+// once all the commands exit, with the first error they failed with.
+func Follow(r Runner, sources []Source, w io.Writer, problems bool) error {
+	mu := &sync.Mutex{}
+	var wg sync.WaitGroup
+	errc := make(chan error, len(sources))
+	for _, s := range sources {
+		if s.Follow == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(s Source) {
+			defer wg.Done()
+			lw := &lineWriter{mu: mu, w: w, prefix: s.Name, problems: problems}
+			err := r.Stream(s.Follow, lw)
+			lw.Flush()
+			if err != nil {
+				errc <- errors.Wrapf(err, "Error following the logs of %s", s.Name)
+			}
+		}(s)
+	}
+	wg.Wait()
+	close(errc)
+	return <-errc
+}
+
+// WriteFile writes the last lines of the file at path to w, each prefixed with name.
+// With problems, only the lines pointing out problems are written.
+func WriteFile(w io.Writer, name, path string, lines int, problems bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// Only the last lines are kept, as the logs may be large.
+	last := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if lines > 0 && len(last) == lines {
+			last = append(last[1:], scanner.Text())
+		} else {
+			last = append(last, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "Error reading %s", path)
+	}
+	lw := &lineWriter{mu: &sync.Mutex{}, w: w, prefix: name, problems: problems}
+	for _, line := range last {
+		if err := lw.writeLine(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
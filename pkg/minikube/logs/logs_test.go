@@ -0,0 +1,253 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// fakeRunner answers the commands with their outputs, and streams them in chunks
+// which split the lines, along with the other streams.
+type fakeRunner struct {
+	mu      sync.Mutex
+	outputs map[string]string
+	errs    map[string]error
+	ran     []string
+}
+
+func (r *fakeRunner) Run(cmd string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, cmd)
+	return r.outputs[cmd], r.errs[cmd]
+}
+
+func (r *fakeRunner) Stream(cmd string, w io.Writer) error {
+	r.mu.Lock()
+	r.ran = append(r.ran, cmd)
+	out, err := r.outputs[cmd], r.errs[cmd]
+	r.mu.Unlock()
+	for i := 0; i < len(out); i += 3 {
+		end := i + 3
+		if end > len(out) {
+			end = len(out)
+		}
+		if _, err := w.Write([]byte(out[i:end])); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func TestIsProblem(t *testing.T) {
+	var tests = []struct {
+		line     string
+		expected bool
+	}{
+		{line: "Oct 14 12:00:00 minikube localkube[3412]: E1014 12:00:00.123456    3412 kubelet.go:1234] Unable to mount volumes", expected: true},
+		{line: "Oct 14 12:00:00 minikube localkube[3412]: I1014 12:00:00.123456    3412 kubelet.go:1234] Started kubelet", expected: false},
+		{line: "F1014 12:00:00.000000       1 server.go:100] cannot start", expected: true},
+		{line: "Error response from daemon: conflict", expected: true},
+		{line: "Failed to pull image \"busybox\"", expected: true},
+		{line: "panic: runtime error: invalid memory address", expected: true},
+		{line: "[ 512.000000] Out of memory: Kill process 1234 (java)", expected: true},
+		{line: "write /var/lib/docker/tmp: no space left on device", expected: true},
+		{line: "dial tcp 10.0.0.1:443: i/o timeout", expected: true},
+		{line: "Back-off restarting failed container", expected: true},
+		{line: "Successfully assigned busybox to minikube", expected: false},
+		{line: "errors=0 during the sync", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			if problem := IsProblem(test.line); problem != test.expected {
+				t.Errorf("Expected IsProblem %t, got %t", test.expected, problem)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	rt, err := cruntime.Lookup(cruntime.Docker)
+	if err != nil {
+		t.Fatalf("Unexpected error looking up the runtime: %s", err)
+	}
+	r := &fakeRunner{outputs: map[string]string{
+		rt.ListContainersCommand("kube-system"): "0123456789ab kube-dns-910330662-7vcv5 kubedns\n123456789abc kube-dns-910330662-7vcv5 POD\n",
+	}}
+	names := []string{}
+	for _, s := range Sources(r, rt, 10) {
+		names = append(names, s.Name)
+		if !strings.Contains(s.Command, "10") || !strings.Contains(s.Follow, "10") {
+			t.Errorf("Expected the commands of %s to print the last 10 lines, got %q and %q", s.Name, s.Command, s.Follow)
+		}
+	}
+	if expected := []string{"localkube", "docker", "dmesg", "kube-dns-910330662-7vcv5/kubedns"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected sources %v, got %v", expected, names)
+	}
+
+	r.errs = map[string]error{rt.ListContainersCommand("kube-system"): fmt.Errorf("docker is stopped")}
+	if sources := Sources(r, rt, 10); len(sources) != 3 {
+		t.Errorf("Expected the containers left out when they can't be listed, got %+v", sources)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	sources := []Source{{Name: "localkube", Command: "localkube logs"}, {Name: "docker", Command: "docker logs"}, {Name: "dmesg", Command: "dmesg"}}
+	r := &fakeRunner{
+		outputs: map[string]string{
+			"localkube logs": "I1014 12:00:00.000000 started\nE1014 12:00:01.000000 failed to sync\n",
+			"docker logs":    "Started Docker Application Container Engine.",
+		},
+		errs: map[string]error{"dmesg": fmt.Errorf("dmesg: read kernel buffer failed")},
+	}
+
+	var tests = []struct {
+		description string
+		problems    bool
+		expected    string
+	}{
+		{
+			description: "all lines",
+			expected: `[localkube] I1014 12:00:00.000000 started
+[localkube] E1014 12:00:01.000000 failed to sync
+[docker] Started Docker Application Container Engine.
+[dmesg] Error getting the logs: dmesg: read kernel buffer failed
+`,
+		},
+		{
+			description: "problems",
+			problems:    true,
+			expected: `[localkube] E1014 12:00:01.000000 failed to sync
+[dmesg] Error getting the logs: dmesg: read kernel buffer failed
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Output(r, sources, &buf, test.problems); err != nil {
+				t.Fatalf("Unexpected error writing the logs: %s", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("Expected logs:\n%s\ngot:\n%s", test.expected, buf.String())
+			}
+		})
+	}
+
+	if err := Output(r, sources[2:], ioutil.Discard, false); err == nil {
+		t.Error("Expected an error when none of the logs could be written")
+	}
+}
+
+func TestFollow(t *testing.T) {
+	sources := []Source{
+		{Name: "localkube", Command: "localkube logs", Follow: "localkube logs -f"},
+		{Name: "docker", Command: "docker logs", Follow: "docker logs -f"},
+		{Name: "static pods", Command: "static pods"},
+	}
+	r := &fakeRunner{outputs: map[string]string{
+		"localkube logs -f": "I1014 12:00:00.000000 started\nE1014 12:00:01.000000 failed to sync\nI1014 12:00:02.000000 synced",
+		"docker logs -f":    "Started Docker Application Container Engine.\nError response from daemon: conflict\n",
+	}}
+
+	var tests = []struct {
+		description string
+		problems    bool
+		expected    []string
+	}{
+		{
+			description: "all lines",
+			expected: []string{
+				"[docker] Error response from daemon: conflict",
+				"[docker] Started Docker Application Container Engine.",
+				"[localkube] E1014 12:00:01.000000 failed to sync",
+				"[localkube] I1014 12:00:00.000000 started",
+				"[localkube] I1014 12:00:02.000000 synced",
+			},
+		},
+		{
+			description: "problems",
+			problems:    true,
+			expected: []string{
+				"[docker] Error response from daemon: conflict",
+				"[localkube] E1014 12:00:01.000000 failed to sync",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Follow(r, sources, &buf, test.problems); err != nil {
+				t.Fatalf("Unexpected error following the logs: %s", err)
+			}
+			// The lines of the sources interleave in any order, but each stays whole.
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			sort.Strings(lines)
+			if !reflect.DeepEqual(lines, test.expected) {
+				t.Errorf("Expected lines %q, got %q", test.expected, lines)
+			}
+		})
+	}
+	for _, cmd := range r.ran {
+		if cmd == "static pods" {
+			t.Errorf("Expected the sources without a follow command left out")
+		}
+	}
+
+	r.errs = map[string]error{"docker logs -f": fmt.Errorf("connection lost")}
+	if err := Follow(r, sources, ioutil.Discard, false); err == nil || !strings.Contains(err.Error(), "docker") {
+		t.Errorf("Expected the error following the logs of docker, got %v", err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "VBox.log")
+	if err := ioutil.WriteFile(path, []byte("first\nVERR_VMX_NO_VMX error\nthird\nfourth\n"), 0644); err != nil {
+		t.Fatalf("Error writing log: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFile(&buf, "VBox.log", path, 3, false); err != nil {
+		t.Fatalf("Unexpected error writing the file: %s", err)
+	}
+	if expected := "[VBox.log] VERR_VMX_NO_VMX error\n[VBox.log] third\n[VBox.log] fourth\n"; buf.String() != expected {
+		t.Errorf("Expected the last 3 lines:\n%s\ngot:\n%s", expected, buf.String())
+	}
+	if err := WriteFile(ioutil.Discard, "missing.log", filepath.Join(dir, "missing.log"), 3, false); err == nil {
+		t.Error("Expected an error writing a missing file")
+	}
+}
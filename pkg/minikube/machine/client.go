@@ -21,18 +21,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/provision"
 
-	"github.com/docker/machine/drivers/virtualbox"
+	"github.com/docker/machine/drivers/errdriver"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/cert"
-	"github.com/docker/machine/libmachine/check"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
 	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
@@ -47,47 +47,136 @@ import (
 	"github.com/pkg/errors"
 )
 
-type driverGetter func([]byte) (drivers.Driver, error)
-
 type ClientType int
 type clientFactory interface {
-	NewClient(string, string) libmachine.API
+	NewClient(string, string) (API, error)
 }
 
 type localClientFactory struct{}
 
-func (*localClientFactory) NewClient(storePath, certsDir string) libmachine.API {
+func (*localClientFactory) NewClient(storePath, certsDir string) (API, error) {
 	return &LocalClient{
 		certsDir:  certsDir,
 		storePath: storePath,
 		Filestore: persist.NewFilestore(storePath, certsDir, certsDir),
-	}
+	}, nil
 }
 
 type rpcClientFactory struct{}
 
-func (*rpcClientFactory) NewClient(storePath, certsDir string) libmachine.API {
+func (*rpcClientFactory) NewClient(storePath, certsDir string) (API, error) {
 	c := libmachine.NewClient(storePath, certsDir)
 	c.SSHClientType = ssh.Native
-	return c
+	return &rpcClient{
+		Client:        c,
+		certsDir:      certsDir,
+		driverFactory: newPluginDriverFactory(),
+	}, nil
+}
+
+// rpcClient is the libmachine RPC client, extended with lightweight state queries. Its
+// drivers are launched by its own driver factory rather than libmachine's.
+type rpcClient struct {
+	*libmachine.Client
+	certsDir      string
+	driverFactory rpcdriver.RPCClientDriverFactory
+}
+
+// NewHost mirrors libmachine's Client.NewHost, in vendor/github.com/docker/machine/libmachine/libmachine.go,
+// launching the driver with the client's own factory, as the factory of libmachine's Client is unexported.
+func (api *rpcClient) NewHost(driverName string, rawDriver []byte) (*host.Host, error) {
+	driver, err := api.driverFactory.NewRPCClientDriver(driverName, rawDriver)
+	if err != nil {
+		return nil, err
+	}
+	return &host.Host{
+		ConfigVersion: version.ConfigVersion,
+		Name:          driver.GetMachineName(),
+		Driver:        driver,
+		DriverName:    driver.DriverName(),
+		HostOptions: &host.Options{
+			AuthOptions: &auth.Options{
+				CertDir:          api.certsDir,
+				CaCertPath:       filepath.Join(api.certsDir, "ca.pem"),
+				CaPrivateKeyPath: filepath.Join(api.certsDir, "ca-key.pem"),
+				ClientCertPath:   filepath.Join(api.certsDir, "cert.pem"),
+				ClientKeyPath:    filepath.Join(api.certsDir, "key.pem"),
+				ServerCertPath:   filepath.Join(api.GetMachinesDir(), "server.pem"),
+				ServerKeyPath:    filepath.Join(api.GetMachinesDir(), "server-key.pem"),
+			},
+			EngineOptions: &engine.Options{
+				InstallURL:    drivers.DefaultEngineInstallURL,
+				StorageDriver: "aufs",
+				TLSVerify:     true,
+			},
+			SwarmOptions: &swarm.Options{
+				Host:     "tcp://0.0.0.0:3376",
+				Image:    "swarm:latest",
+				Strategy: "spread",
+			},
+		},
+	}, nil
+}
+
+// Load mirrors libmachine's Client.Load, in the same file, launching the driver with the client's own
+// factory once the stored config of the machine is checked.
+func (api *rpcClient) Load(name string) (*host.Host, error) {
+	if err := checkMachineConfig(api, name, nil); err != nil {
+		return nil, err
+	}
+	h, err := api.Filestore.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := api.driverFactory.NewRPCClientDriver(h.DriverName, h.RawDriver)
+	if err != nil {
+		// libmachine loads the hosts whose driver binary is missing with a driver failing each call.
+		if _, ok := err.(localbinary.ErrPluginBinaryNotFound); ok {
+			h.Driver = errdriver.NewDriver(h.DriverName)
+			return h, nil
+		}
+		return nil, err
+	}
+	// As libmachine does, the calls to the VirtualBox driver are serialized.
+	if h.DriverName == "virtualbox" {
+		h.Driver = drivers.NewSerialDriver(d)
+	} else {
+		h.Driver = d
+	}
+	return h, nil
+}
+
+func (api *rpcClient) Save(h *host.Host) error {
+	return saveHost(api.GetMachinesDir(), h)
+}
+
+func (api *rpcClient) Remove(name string) error {
+	return removeHost(api.GetMachinesDir(), name, api.Client.Remove)
+}
+
+func (api *rpcClient) Close() error {
+	api.driverFactory.Close()
+	return api.Client.Close()
 }
 
 var clientFactories = map[ClientType]clientFactory{
 	//	ClientTypeNative: &nativeClientFactory{},
 	ClientTypeLocal: &localClientFactory{},
 	ClientTypeRPC:   &rpcClientFactory{},
+	ClientTypeSSH:   &sshClientFactory{},
 }
 
 const (
 	ClientTypeLocal ClientType = iota
 	ClientTypeRPC
+	ClientTypeSSH
 
 //	ClientTypeNative
 )
 
 // Gets a new client depending on the clientType specified
 // defaults to the libmachine client
-func NewAPIClient(clientType ClientType) (libmachine.API, error) {
+func NewAPIClient(clientType ClientType) (API, error) {
 	storePath := constants.GetMinipath()
 	certsDir := constants.MakeMiniPath("certs")
 	newClientFactory, ok := clientFactories[clientType]
@@ -95,29 +184,51 @@ func NewAPIClient(clientType ClientType) (libmachine.API, error) {
 		return nil, fmt.Errorf("No implementation for API client type %d", clientType)
 	}
 
-	return newClientFactory.NewClient(storePath, certsDir), nil
+	return newClientFactory.NewClient(storePath, certsDir)
+}
+
+// ErrUnknownDriver is returned when a driver is not registered in this binary.
+type ErrUnknownDriver struct {
+	DriverName string
+	Supported  []string
+}
+
+func (e ErrUnknownDriver) Error() string {
+	return fmt.Sprintf(`Unknown driver %q for this platform. The supported drivers are: %s.
+If this machine was created with another driver, run "minikube delete" and then "minikube start" to recreate it.`,
+		e.DriverName, strings.Join(e.Supported, ", "))
+}
+
+// supportedDrivers returns the sorted names of the drivers registered in this binary.
+func supportedDrivers() []string {
+	names := []string{}
+	for name := range driverMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func getDriver(driverName string, rawDriver []byte) (drivers.Driver, error) {
-	driverGetter, ok := driverMap[driverName]
+	newDriver, ok := driverMap[driverName]
 	if !ok {
-		return nil, fmt.Errorf("Unknown driver %s for platform.", driverName)
+		if msg, ok := pluginOnlyDrivers[driverName]; ok {
+			return nil, errors.New(msg)
+		}
+		return nil, ErrUnknownDriver{
+			DriverName: driverName,
+			Supported:  supportedDrivers(),
+		}
 	}
-	driver, err := driverGetter(rawDriver)
+	rawDriver, err := migrateDriverConfig(driverName, rawDriver)
 	if err != nil {
-		return nil, errors.Wrapf(err, "Error getting driver for %s", driverName)
+		return nil, err
 	}
-
-	return driver, nil
-}
-
-func getVirtualboxDriver(rawDriver []byte) (drivers.Driver, error) {
-	var driver drivers.Driver
-	driver = virtualbox.NewDriver("", "")
-	err := json.Unmarshal(rawDriver, driver)
-	if err != nil {
-		return nil, errors.Wrapf(err, "Error unmarshalling virtualbox driver %s", string(rawDriver))
+	driver := newDriver()
+	if err := json.Unmarshal(rawDriver, driver); err != nil {
+		return nil, errors.Wrapf(err, "Error unmarshalling %s driver", driverName)
 	}
+
 	return driver, nil
 }
 
@@ -138,6 +249,9 @@ func (api *LocalClient) NewHost(driverName string, rawDriver []byte) (*host.Host
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting driver")
 	}
+	if err := validateDriverConfig(driverName, rawDriver); err != nil {
+		return nil, err
+	}
 	return &host.Host{
 		ConfigVersion: version.ConfigVersion,
 		Name:          driver.GetMachineName(),
@@ -163,6 +277,9 @@ func (api *LocalClient) NewHost(driverName string, rawDriver []byte) (*host.Host
 }
 
 func (api *LocalClient) Load(name string) (*host.Host, error) {
+	if err := checkMachineConfig(api, name, getDriver); err != nil {
+		return nil, err
+	}
 	h, err := api.Filestore.Load(name)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading host from store")
@@ -176,9 +293,20 @@ func (api *LocalClient) Load(name string) (*host.Host, error) {
 	return h, nil
 }
 
+// Remove removes the host from the store, holding the machine's lock.
+func (api *LocalClient) Remove(name string) error {
+	return removeHost(api.GetMachinesDir(), name, api.Filestore.Remove)
+}
+
 func (api *LocalClient) Close() error { return nil }
 
 func (api *LocalClient) Create(h *host.Host) error {
+	return createHost(api, h)
+}
+
+// createHost runs the steps needed to bring up a new host, saving it
+// through the given API so that both the local and remote clients share it.
+func createHost(api libmachine.API, h *host.Host) error {
 	steps := []struct {
 		name string
 		f    func() error
@@ -231,16 +359,6 @@ func (api *LocalClient) Create(h *host.Host) error {
 	return nil
 }
 
-func StartDriver() {
-	cert.SetCertGenerator(&CertGenerator{})
-	check.DefaultConnChecker = &ConnChecker{}
-	if os.Getenv(localbinary.PluginEnvKey) == localbinary.PluginEnvVal {
-		registerDriver(os.Getenv(localbinary.PluginEnvDriverName))
-	}
-
-	localbinary.CurrentBinaryIsDockerMachine = true
-}
-
 type ConnChecker struct {
 }
 
@@ -17,47 +17,22 @@ limitations under the License.
 package machine
 
 import (
-	"encoding/json"
-
-	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/drivers/vmwarefusion"
 	"github.com/docker/machine/libmachine/drivers"
-	"github.com/docker/machine/libmachine/drivers/plugin"
-	"github.com/golang/glog"
-	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/hyperkit"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
 )
 
-var driverMap = map[string]driverGetter{
-	"vmwarefusion": getVMWareFusionDriver,
-	"xhyve":        getXhyveDriver,
-	"virtualbox":   getVirtualboxDriver,
-}
-
-func getVMWareFusionDriver(rawDriver []byte) (drivers.Driver, error) {
-	var driver drivers.Driver
-	driver = &vmwarefusion.Driver{}
-	if err := json.Unmarshal(rawDriver, &driver); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling vmwarefusion driver")
-	}
-	return driver, nil
+var driverMap = map[string]func() drivers.Driver{
+	"vmwarefusion": func() drivers.Driver { return vmwarefusion.NewDriver("", "") },
+	"virtualbox":   func() drivers.Driver { return virtualbox.NewDriver("", "") },
+	"hyperkit":     func() drivers.Driver { return hyperkit.NewDriver("", "") },
 }
 
-// Xhyve driver not implemented yet for non-RPC access
-func getXhyveDriver(rawDriver []byte) (drivers.Driver, error) {
-	return nil, errors.New(`
+// pluginOnlyDrivers are drivers that are supported, but only through an external plugin.
+var pluginOnlyDrivers = map[string]string{
+	"xhyve": `
 The Xhyve driver is not included in minikube yet.  Please follow the directions at
 https://github.com/kubernetes/minikube/blob/master/DRIVERS.md#xhyve-driver
-`)
-}
-
-// StartDriver starts the desired machine driver if necessary.
-func registerDriver(driverName string) {
-	switch driverName {
-	case "virtualbox":
-		plugin.RegisterDriver(virtualbox.NewDriver("", ""))
-	case "vmwarefusion":
-		plugin.RegisterDriver(vmwarefusion.NewDriver("", ""))
-	default:
-		glog.Exitf("Unsupported driver: %s\n", driverName)
-	}
+`,
 }
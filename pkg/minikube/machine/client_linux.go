@@ -17,46 +17,22 @@ limitations under the License.
 package machine
 
 import (
-	"encoding/json"
-
-	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/libmachine/drivers"
-	"github.com/docker/machine/libmachine/drivers/plugin"
-	"github.com/golang/glog"
-	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/kvm2"
 	"k8s.io/minikube/pkg/minikube/machine/drivers/none"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
 )
 
-var driverMap = map[string]driverGetter{
-	"kvm":        getKVMDriver,
-	"virtualbox": getVirtualboxDriver,
-	"none":       getNoneDriver,
+var driverMap = map[string]func() drivers.Driver{
+	"virtualbox": func() drivers.Driver { return virtualbox.NewDriver("", "") },
+	"none":       func() drivers.Driver { return none.NewDriver("", "") },
+	"kvm2":       func() drivers.Driver { return kvm2.NewDriver("", "") },
 }
 
-func getKVMDriver(rawDriver []byte) (drivers.Driver, error) {
-	return nil, errors.New(`
+// pluginOnlyDrivers are drivers that are supported, but only through an external plugin.
+var pluginOnlyDrivers = map[string]string{
+	"kvm": `
 The KVM driver is not included in minikube yet.  Please follow the direction at
 https://github.com/kubernetes/minikube/blob/master/DRIVERS.md#kvm-driver
-`)
-}
-
-func getNoneDriver(rawDriver []byte) (drivers.Driver, error) {
-	var driver drivers.Driver
-	driver = &none.Driver{}
-	if err := json.Unmarshal(rawDriver, &driver); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling none driver")
-	}
-	return driver, nil
-}
-
-// StartDriver starts the desired machine driver if necessary.
-func registerDriver(driverName string) {
-	switch driverName {
-	case "virtualbox":
-		plugin.RegisterDriver(virtualbox.NewDriver("", ""))
-	case "none":
-		plugin.RegisterDriver(none.NewDriver("", ""))
-	default:
-		glog.Exitf("Unsupported driver: %s\n", driverName)
-	}
+`,
 }
@@ -18,10 +18,9 @@ limitations under the License.
 
 package machine
 
-import "github.com/golang/glog"
+import "github.com/docker/machine/libmachine/drivers"
 
-var driverMap = map[string]driverGetter{}
+var driverMap = map[string]func() drivers.Driver{}
 
-func registerDriver(driverName string) {
-	glog.Errorf("Unsupported platform")
-}
+// pluginOnlyDrivers are drivers that are supported, but only through an external plugin.
+var pluginOnlyDrivers = map[string]string{}
@@ -0,0 +1,481 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/rpc"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
+	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnerror"
+	machinessh "github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/libmachine/version"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/util"
+)
+
+const (
+	defaultRemoteSSHPort   = "22"
+	defaultRemoteStorePath = ".minikube"
+	remotePathExists       = "exists"
+	pluginHeartbeat        = 5 * time.Second
+)
+
+// RemoteConfig contains the parameters used to reach a remote docker-machine host.
+// HostKey is the SHA256 fingerprint of the host key the remote host must present.
+type RemoteConfig struct {
+	Host      string
+	User      string
+	SSHKey    string
+	HostKey   string
+	StorePath string
+}
+
+// GetRemoteConfig reads the remote host parameters from flags, the config file
+// or MINIKUBE_REMOTE_* environment variables.
+func GetRemoteConfig() RemoteConfig {
+	return RemoteConfig{
+		Host:      viper.GetString(config.RemoteHost),
+		User:      viper.GetString(config.RemoteUser),
+		SSHKey:    viper.GetString(config.RemoteSSHKey),
+		HostKey:   viper.GetString(config.RemoteHostKey),
+		StorePath: viper.GetString(config.RemoteStorePath),
+	}
+}
+
+type sshClientFactory struct{}
+
+func (*sshClientFactory) NewClient(storePath, certsDir string) (API, error) {
+	c, err := NewRemoteClient(GetRemoteConfig(), certsDir)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// RemoteClient is an implementation of the libmachine API that keeps the
+// machine store on a remote host and runs the driver plugins there, reaching
+// both over a single SSH connection.
+type RemoteClient struct {
+	certsDir  string
+	storePath string
+	client    *ssh.Client
+
+	mu       sync.Mutex
+	sessions []*ssh.Session
+	done     chan struct{}
+}
+
+// NewRemoteClient dials the remote host described by rc and verifies that its machine store exists.
+func NewRemoteClient(rc RemoteConfig, certsDir string) (*RemoteClient, error) {
+	if rc.Host == "" {
+		return nil, errors.Errorf("No remote host specified, please set --%s or %s", config.RemoteHost, remoteEnvVar(config.RemoteHost))
+	}
+	addr := rc.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultRemoteSSHPort)
+	}
+	auth := &machinessh.Auth{}
+	if rc.SSHKey != "" {
+		auth.Keys = []string{rc.SSHKey}
+	}
+	sshConfig, err := machinessh.NewNativeConfig(rc.User, auth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error creating ssh config for %s", rc.Host)
+	}
+	// The remote host holds the machine store and its certs, so it must be the one
+	// whose key the user checked.
+	sshConfig.HostKeyCallback = verifyHostKey(rc.HostKey)
+	client, err := ssh.Dial("tcp", addr, &sshConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error dialing remote host %s", addr)
+	}
+
+	api := &RemoteClient{
+		certsDir:  certsDir,
+		storePath: rc.StorePath,
+		client:    client,
+		done:      make(chan struct{}),
+	}
+	if api.storePath == "" {
+		api.storePath = defaultRemoteStorePath
+	}
+	exists, err := api.pathExists(api.storePath)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrapf(err, "Error checking remote store path %s", api.storePath)
+	}
+	if !exists {
+		client.Close()
+		return nil, errors.Errorf("Remote machine store %s does not exist on %s. Please create it or set --%s", api.storePath, rc.Host, config.RemoteStorePath)
+	}
+	return api, nil
+}
+
+// verifyHostKey returns a host key callback accepting the key whose SHA256 fingerprint is
+// fingerprint, with or without its SHA256: prefix. Without a fingerprint, no key is accepted,
+// and the error shows the fingerprint of the key, for the user to check and pass.
+func verifyHostKey(fingerprint string) func(string, net.Addr, ssh.PublicKey) error {
+	expected := strings.TrimRight(strings.TrimPrefix(fingerprint, "SHA256:"), "=")
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		actual := hostKeyFingerprint(key)
+		if expected == "" {
+			return errors.Errorf("The host key of %s is unknown, its fingerprint is %s. Check it, with ssh-keygen -lf on that host, and pass it with --%s or %s",
+				hostname, actual, config.RemoteHostKey, remoteEnvVar(config.RemoteHostKey))
+		}
+		if strings.TrimPrefix(actual, "SHA256:") != expected {
+			return errors.Errorf("The host key of %s has the fingerprint %s, not the one passed with --%s. The connection may be intercepted, or the host's key changed",
+				hostname, actual, config.RemoteHostKey)
+		}
+		return nil
+	}
+}
+
+// hostKeyFingerprint returns the SHA256 fingerprint of key, as ssh-keygen -l prints it.
+func hostKeyFingerprint(key ssh.PublicKey) string {
+	sum := sha256.Sum256(key.Marshal())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func remoteEnvVar(name string) string {
+	return "MINIKUBE_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+func (api *RemoteClient) run(cmd string) (string, error) {
+	s, err := api.client.NewSession()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating new ssh session")
+	}
+	defer s.Close()
+	r := console.StartCommand("the remote host", cmd)
+	out, err := s.CombinedOutput(cmd)
+	r.Done(out, err)
+	if err != nil {
+		return string(out), errors.Wrapf(err, "Error running remote command: %s", cmd)
+	}
+	return string(out), nil
+}
+
+func (api *RemoteClient) upload(data []byte, dest string) error {
+	s, err := api.client.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "Error creating new ssh session")
+	}
+	defer s.Close()
+	s.Stdin = bytes.NewReader(data)
+	cmd := fmt.Sprintf("mkdir -p %s && cat > %s && chmod 0600 %s", quoteRemotePath(path.Dir(dest)), quoteRemotePath(dest), quoteRemotePath(dest))
+	if err := s.Run(cmd); err != nil {
+		return errors.Wrapf(err, "Error uploading %s", dest)
+	}
+	return nil
+}
+
+func (api *RemoteClient) pathExists(p string) (bool, error) {
+	out, err := api.run(fmt.Sprintf("if [ -d %s ]; then echo %s; fi", quoteRemotePath(p), remotePathExists))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == remotePathExists, nil
+}
+
+func (api *RemoteClient) hostPath(name string) string {
+	return path.Join(api.GetMachinesDir(), name)
+}
+
+// readHostConfig reads the config.json of the machine name in the remote store.
+func (api *RemoteClient) readHostConfig(name string) (string, error) {
+	return api.run(fmt.Sprintf("cat %s", quoteRemotePath(path.Join(api.hostPath(name), "config.json"))))
+}
+
+// GetMachinesDir returns the machines directory of the remote store.
+func (api *RemoteClient) GetMachinesDir() string {
+	return path.Join(api.storePath, "machines")
+}
+
+func (api *RemoteClient) NewHost(driverName string, rawDriver []byte) (*host.Host, error) {
+	rawDriver, err := setRemoteStorePath(rawDriver, api.storePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error setting remote store path on driver")
+	}
+	driver, err := api.newPluginDriver(driverName, rawDriver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting driver")
+	}
+	return &host.Host{
+		ConfigVersion: version.ConfigVersion,
+		Name:          driver.GetMachineName(),
+		Driver:        driver,
+		DriverName:    driver.DriverName(),
+		HostOptions: &host.Options{
+			AuthOptions: &auth.Options{
+				CertDir:          api.certsDir,
+				CaCertPath:       filepath.Join(api.certsDir, "ca.pem"),
+				CaPrivateKeyPath: filepath.Join(api.certsDir, "ca-key.pem"),
+				ClientCertPath:   filepath.Join(api.certsDir, "cert.pem"),
+				ClientKeyPath:    filepath.Join(api.certsDir, "key.pem"),
+				ServerCertPath:   filepath.Join(api.certsDir, "server.pem"),
+				ServerKeyPath:    filepath.Join(api.certsDir, "server-key.pem"),
+			},
+			EngineOptions: &engine.Options{
+				StorageDriver: "aufs",
+				TLSVerify:     true,
+			},
+			SwarmOptions: &swarm.Options{},
+		},
+	}, nil
+}
+
+// setRemoteStorePath rewrites the StorePath of a serialized driver so the VM
+// files are created inside the remote store rather than at a local path.
+func setRemoteStorePath(rawDriver []byte, storePath string) ([]byte, error) {
+	var d map[string]interface{}
+	if err := json.Unmarshal(rawDriver, &d); err != nil {
+		return nil, err
+	}
+	if _, ok := d["StorePath"]; !ok {
+		return rawDriver, nil
+	}
+	d["StorePath"] = storePath
+	return json.Marshal(d)
+}
+
+func (api *RemoteClient) Create(h *host.Host) error {
+	if err := createHost(api, h); err != nil {
+		return err
+	}
+	return api.uploadCerts(h.AuthOptions())
+}
+
+// uploadCerts mirrors the client certificates into the remote store so the
+// remote docker-machine can talk to the docker daemon in the VM.
+func (api *RemoteClient) uploadCerts(authOptions *auth.Options) error {
+	for _, p := range []string{authOptions.CaCertPath, authOptions.ClientCertPath, authOptions.ClientKeyPath} {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return errors.Wrapf(err, "Error reading cert %s", p)
+		}
+		if err := api.upload(data, path.Join(api.storePath, "certs", filepath.Base(p))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (api *RemoteClient) Exists(name string) (bool, error) {
+	return api.pathExists(api.hostPath(name))
+}
+
+func (api *RemoteClient) List() ([]string, error) {
+	out, err := api.run(fmt.Sprintf("ls -1 %s 2>/dev/null || true", quoteRemotePath(api.GetMachinesDir())))
+	if err != nil {
+		return nil, err
+	}
+	hostNames := []string{}
+	for _, name := range strings.Split(out, "\n") {
+		name = strings.TrimSpace(name)
+		if name != "" && !strings.HasPrefix(name, ".") {
+			hostNames = append(hostNames, name)
+		}
+	}
+	return hostNames, nil
+}
+
+func (api *RemoteClient) Load(name string) (*host.Host, error) {
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error checking if remote host exists")
+	}
+	if !exists {
+		return nil, mcnerror.ErrHostDoesNotExist{
+			Name: name,
+		}
+	}
+	data, err := api.readHostConfig(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading host from remote store")
+	}
+	h, _, err := host.MigrateHost(&host.Host{Name: name}, []byte(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting migrated host")
+	}
+	h.Name = name
+
+	h.Driver, err = api.newPluginDriver(h.DriverName, h.RawDriver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading driver from host")
+	}
+	return h, nil
+}
+
+func (api *RemoteClient) Save(h *host.Host) error {
+	data, err := json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling host")
+	}
+	return api.upload(data, path.Join(api.hostPath(h.Name), "config.json"))
+}
+
+func (api *RemoteClient) Remove(name string) error {
+	_, err := api.run(fmt.Sprintf("rm -rf %s", quoteRemotePath(api.hostPath(name))))
+	return err
+}
+
+func (api *RemoteClient) Close() error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	select {
+	case <-api.done:
+	default:
+		close(api.done)
+	}
+	for _, s := range api.sessions {
+		s.Close()
+	}
+	api.sessions = nil
+	return api.client.Close()
+}
+
+// newPluginDriver launches the driver plugin on the remote host and connects
+// to its RPC server through the SSH connection.
+func (api *RemoteClient) newPluginDriver(driverName string, rawDriver []byte) (drivers.Driver, error) {
+	s, err := api.client.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating new ssh session")
+	}
+	stdout, err := s.StdoutPipe()
+	if err != nil {
+		s.Close()
+		return nil, errors.Wrap(err, "Error getting plugin stdout")
+	}
+	if err := s.Start(remotePluginCommand(driverName)); err != nil {
+		s.Close()
+		return nil, errors.Wrapf(err, "Error starting remote plugin for driver %s", driverName)
+	}
+	api.mu.Lock()
+	api.sessions = append(api.sessions, s)
+	api.mu.Unlock()
+
+	// The plugin writes the address it's listening on as its first line of output.
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading remote plugin address for driver %s", driverName)
+	}
+	conn, err := api.client.Dial("tcp", strings.TrimSpace(addr))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error dialing remote plugin at %s", addr)
+	}
+	rpcClient, err := newHTTPRPCClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Error connecting to remote plugin")
+	}
+	c, err := connectPluginDriver(rpcClient, rawDriver, api.done)
+	return c, errors.Wrap(err, "Error connecting to remote plugin")
+}
+
+// connectPluginDriver returns the driver of the plugin rpcClient is connected to, checking its
+// API version, setting its config, and keeping it alive until done is closed.
+func connectPluginDriver(rpcClient *rpc.Client, rawDriver []byte, done <-chan struct{}) (*rpcdriver.RPCClientDriver, error) {
+	c := &rpcdriver.RPCClientDriver{
+		Client: rpcdriver.NewInternalClient(rpcClient),
+	}
+	var serverVersion int
+	if err := c.Client.Call(rpcdriver.GetVersionMethod, struct{}{}, &serverVersion); err != nil {
+		return nil, errors.Wrap(err, "Error getting plugin version")
+	}
+	if serverVersion != version.APIVersion {
+		return nil, errors.Errorf("Driver binary uses an incompatible API version (%d)", serverVersion)
+	}
+	go heartbeat(c.Client, done)
+
+	if err := c.SetConfigRaw(rawDriver); err != nil {
+		return nil, errors.Wrap(err, "Error setting driver config")
+	}
+	c.Client.MachineName = c.GetMachineName()
+	return c, nil
+}
+
+// heartbeat keeps the plugin server alive until done is closed.
+func heartbeat(c *rpcdriver.InternalClient, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(pluginHeartbeat):
+			if err := c.Call(rpcdriver.HeartbeatMethod, struct{}{}, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func remotePluginCommand(driverName string) string {
+	binary := fmt.Sprintf("docker-machine-driver-%s", driverName)
+	for _, coreDriver := range localbinary.CoreDrivers {
+		if coreDriver == driverName {
+			binary = "docker-machine"
+		}
+	}
+	return fmt.Sprintf("%s=%s %s=%s %s", localbinary.PluginEnvKey, localbinary.PluginEnvVal,
+		localbinary.PluginEnvDriverName, util.ShellQuote(driverName), util.ShellQuote(binary))
+}
+
+// quoteRemotePath quotes the path of the remote store for the shell of the remote host. A
+// leading ~/ is left out of the quotes, for the shell to expand it to the home directory.
+func quoteRemotePath(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		return "~/" + util.ShellQuote(p[2:])
+	}
+	return util.ShellQuote(p)
+}
+
+// newHTTPRPCClient performs the same handshake as rpc.DialHTTP over an existing connection.
+func newHTTPRPCClient(conn net.Conn) (*rpc.Client, error) {
+	io.WriteString(conn, "CONNECT "+rpc.DefaultRPCPath+" HTTP/1.0\n\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "200 Connected to Go RPC" {
+		return nil, errors.Errorf("Unexpected HTTP response: %s", resp.Status)
+	}
+	return rpc.NewClient(conn), nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"testing"
+)
+
+func TestQuoteRemotePath(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected string
+	}{
+		{path: ".minikube/machines", expected: ".minikube/machines"},
+		{path: "/srv/minikube store/machines", expected: "'/srv/minikube store/machines'"},
+		{path: "~/.minikube/machines", expected: "~/.minikube/machines"},
+		{path: "~/minikube's store", expected: `~/'minikube'\''s store'`},
+		{path: "/srv/$(reboot)", expected: "'/srv/$(reboot)'"},
+	}
+
+	for _, test := range tests {
+		if got := quoteRemotePath(test.path); got != test.expected {
+			t.Errorf("Expected %s quoted as %s, got %s", test.path, test.expected, got)
+		}
+	}
+}
+
+func TestRemotePluginCommand(t *testing.T) {
+	var tests = []struct {
+		driver   string
+		expected string
+	}{
+		{driver: "virtualbox", expected: "MACHINE_PLUGIN_TOKEN=42 MACHINE_PLUGIN_DRIVER_NAME=virtualbox docker-machine"},
+		{driver: "kvm2", expected: "MACHINE_PLUGIN_TOKEN=42 MACHINE_PLUGIN_DRIVER_NAME=kvm2 docker-machine-driver-kvm2"},
+		{driver: "kvm2;reboot", expected: "MACHINE_PLUGIN_TOKEN=42 MACHINE_PLUGIN_DRIVER_NAME='kvm2;reboot' 'docker-machine-driver-kvm2;reboot'"},
+	}
+
+	for _, test := range tests {
+		if got := remotePluginCommand(test.driver); got != test.expected {
+			t.Errorf("Expected the command %s, got %s", test.expected, got)
+		}
+	}
+}
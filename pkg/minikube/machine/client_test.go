@@ -18,54 +18,36 @@ package machine
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net"
+	"net/rpc"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 
-	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/libmachine/drivers"
 
 	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
+	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/pkg/errors"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
+	"k8s.io/minikube/pkg/minikube/tests"
 )
 
 var expectedDrivers = map[string]drivers.Driver{
 	vboxConfig: virtualbox.NewDriver("", ""),
 }
 
-const vboxConfig = `
-{
-        "IPAddress": "192.168.99.101",
-        "MachineName": "minikube",
-        "SSHUser": "docker",
-        "SSHPort": 33627,
-        "SSHKeyPath": "/home/sundarp/.minikube/machines/minikube/id_rsa",
-        "StorePath": "/home/sundarp/.minikube",
-        "SwarmMaster": false,
-        "SwarmHost": "",
-        "SwarmDiscovery": "",
-        "VBoxManager": {},
-        "HostInterfaces": {},
-        "CPU": 4,
-        "Memory": 16384,
-        "DiskSize": 20000,
-        "NatNicType": "82540EM",
-        "Boot2DockerURL": "file:///home/sundarp/.minikube/cache/iso/minikube-v1.0.6.iso",
-        "Boot2DockerImportVM": "",
-        "HostDNSResolver": false,
-        "HostOnlyCIDR": "192.168.99.1/24",
-        "HostOnlyNicType": "82540EM",
-        "HostOnlyPromiscMode": "deny",
-        "UIType": "headless",
-        "HostOnlyNoDHCP": false,
-        "NoShare": false,
-        "DNSProxy": true,
-        "NoVTXCheck": false
-}
-`
+const vboxConfig = tests.VBoxConfig
 
 func TestGetDriver(t *testing.T) {
 	var tests = []struct {
@@ -115,15 +97,54 @@ func TestGetDriver(t *testing.T) {
 	}
 }
 
+func TestGetDriverUnknownError(t *testing.T) {
+	var tests = []struct {
+		description string
+		driver      string
+	}{
+		{
+			description: "unknown driver",
+			driver:      "unknown",
+		},
+		{
+			description: "empty driver name",
+			driver:      "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			_, err := getDriver(test.driver, []byte(vboxConfig))
+			if err == nil {
+				t.Fatalf("No error returned, but expected err")
+			}
+			if _, ok := err.(ErrUnknownDriver); !ok {
+				t.Fatalf("Expected ErrUnknownDriver, got: %v", err)
+			}
+			for name := range driverMap {
+				if !strings.Contains(err.Error(), name) {
+					t.Errorf("Error %q does not list supported driver %s", err, name)
+				}
+			}
+			if !strings.Contains(err.Error(), "minikube delete") {
+				t.Errorf("Error %q does not suggest running minikube delete", err)
+			}
+		})
+	}
+}
+
 func TestLocalClientNewHost(t *testing.T) {
 	f := clientFactories[ClientTypeLocal]
-	c := f.NewClient("", "")
+	c, _ := f.NewClient("", "")
 
 	var tests = []struct {
 		description string
 		driver      string
 		rawDriver   []byte
 		err         bool
+		invalid     []string
 	}{
 		{
 			description: "host vbox correct",
@@ -136,6 +157,39 @@ func TestLocalClientNewHost(t *testing.T) {
 			rawDriver:   []byte("?"),
 			err:         true,
 		},
+		{
+			description: "host vbox memory below minimum",
+			driver:      "virtualbox",
+			rawDriver:   []byte(strings.Replace(vboxConfig, `"Memory": 16384`, `"Memory": 256`, 1)),
+			err:         true,
+			invalid:     []string{"Memory"},
+		},
+		{
+			description: "host vbox negative memory",
+			driver:      "virtualbox",
+			rawDriver:   []byte(strings.Replace(vboxConfig, `"Memory": 16384`, `"Memory": -1`, 1)),
+			err:         true,
+			invalid:     []string{"Memory"},
+		},
+		{
+			description: "host vbox zero cpus",
+			driver:      "virtualbox",
+			rawDriver:   []byte(strings.Replace(vboxConfig, `"CPU": 4`, `"CPU": 0`, 1)),
+			err:         true,
+			invalid:     []string{"CPU"},
+		},
+		{
+			description: "host vbox all cpus",
+			driver:      "virtualbox",
+			rawDriver:   []byte(strings.Replace(vboxConfig, `"CPU": 4`, `"CPU": -1`, 1)),
+		},
+		{
+			description: "host vbox missing store path",
+			driver:      "virtualbox",
+			rawDriver:   []byte(strings.Replace(vboxConfig, `"StorePath": "/home/sundarp/.minikube",`, "", 1)),
+			err:         true,
+			invalid:     []string{"StorePath"},
+		},
 	}
 
 	for _, test := range tests {
@@ -158,10 +212,69 @@ func TestLocalClientNewHost(t *testing.T) {
 			if err == nil && test.err {
 				t.Errorf("No error returned, but expected err")
 			}
+			if test.invalid != nil {
+				e, ok := err.(ErrInvalidDriverConfig)
+				if !ok {
+					t.Fatalf("Expected ErrInvalidDriverConfig, got: %v", err)
+				}
+				var fields []string
+				for _, f := range e.Fields {
+					fields = append(fields, f.Name)
+				}
+				if !reflect.DeepEqual(fields, test.invalid) {
+					t.Errorf("Invalid fields did not match, expected: %v, got: %v", test.invalid, fields)
+				}
+			}
 		})
 	}
 }
 
+func TestLocalClientLoadCorruptConfig(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	var tests = []struct {
+		description string
+		config      string
+		missing     bool
+	}{
+		{
+			description: "truncated config",
+			config:      `{"ConfigVersion": 3, "Driver": {"MachineName": "mini`,
+		},
+		{
+			description: "missing driver",
+			config:      `{"ConfigVersion": 3, "DriverName": "virtualbox"}`,
+		},
+		{
+			description: "bad driver field",
+			config:      `{"ConfigVersion": 3, "DriverName": "virtualbox", "Driver": {"Memory": "lots"}}`,
+		},
+		{
+			description: "missing config",
+			missing:     true,
+		},
+	}
+
+	f := clientFactories[ClientTypeLocal]
+	api, _ := f.NewClient(tempDir, tempDir)
+	for i, test := range tests {
+		name := fmt.Sprintf("corrupt%d", i)
+		if test.missing {
+			if err := os.MkdirAll(filepath.Join(tempDir, "machines", name), 0700); err != nil {
+				t.Fatalf("Error creating machine dir: %s", err)
+			}
+		} else {
+			writeMachineConfig(t, tempDir, name, test.config)
+		}
+
+		_, err := api.Load(name)
+		if _, ok := errors.Cause(err).(ErrCorruptConfig); !ok {
+			t.Errorf("%s: expected ErrCorruptConfig, got: %v", test.description, err)
+		}
+	}
+}
+
 func TestNewAPIClient(t *testing.T) {
 	var tests = []struct {
 		description string
@@ -176,6 +289,11 @@ func TestNewAPIClient(t *testing.T) {
 			description: "Client type RPC",
 			clientType:  ClientTypeRPC,
 		},
+		{
+			description: "Client type SSH without remote host",
+			clientType:  ClientTypeSSH,
+			err:         true,
+		},
 		{
 			description: "Incorrect client type",
 			clientType:  -1,
@@ -198,6 +316,122 @@ func TestNewAPIClient(t *testing.T) {
 	}
 }
 
+func TestNewRemoteClient(t *testing.T) {
+	s, err := tests.NewSSHServer()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	s.SetCommandToOutput(map[string]string{
+		"if [ -d /remote/.minikube ]; then echo exists; fi":                   "exists\n",
+		"if [ -d /remote/.minikube/machines/minikube ]; then echo exists; fi": "exists\n",
+		"ls -1 /remote/.minikube/machines 2>/dev/null || true":                "minikube\n.tmp\n",
+	})
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	hostKey := hostKeyFingerprint(s.HostKey)
+
+	var tests = []struct {
+		description string
+		config      RemoteConfig
+		err         string
+	}{
+		{
+			description: "remote store exists",
+			config:      RemoteConfig{Host: addr, HostKey: hostKey, StorePath: "/remote/.minikube"},
+		},
+		{
+			description: "host key without prefix",
+			config:      RemoteConfig{Host: addr, HostKey: strings.TrimPrefix(hostKey, "SHA256:"), StorePath: "/remote/.minikube"},
+		},
+		{
+			description: "unknown host key",
+			config:      RemoteConfig{Host: addr, StorePath: "/remote/.minikube"},
+			err:         "its fingerprint is " + hostKey,
+		},
+		{
+			description: "mismatched host key",
+			config:      RemoteConfig{Host: addr, HostKey: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8", StorePath: "/remote/.minikube"},
+			err:         "has the fingerprint " + hostKey,
+		},
+		{
+			description: "remote store missing",
+			config:      RemoteConfig{Host: addr, HostKey: hostKey, StorePath: "/missing/.minikube"},
+			err:         "does not exist",
+		},
+		{
+			description: "no remote host",
+			config:      RemoteConfig{StorePath: "/remote/.minikube"},
+			err:         "No remote host specified",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			c, err := NewRemoteClient(test.config, "")
+			if err != nil && test.err == "" {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err != "" {
+				t.Errorf("No error returned, but expected err")
+			}
+			if err != nil && !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected the error to contain %q, got: %s", test.err, err)
+			}
+			if c != nil {
+				c.Close()
+			}
+		})
+	}
+}
+
+func TestRemoteClientStore(t *testing.T) {
+	s, err := tests.NewSSHServer()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	s.SetCommandToOutput(map[string]string{
+		"if [ -d /remote/.minikube ]; then echo exists; fi":                   "exists\n",
+		"if [ -d /remote/.minikube/machines/minikube ]; then echo exists; fi": "exists\n",
+		"ls -1 /remote/.minikube/machines 2>/dev/null || true":                "minikube\n.tmp\n",
+	})
+
+	c, err := NewRemoteClient(RemoteConfig{
+		Host:      net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		HostKey:   hostKeyFingerprint(s.HostKey),
+		StorePath: "/remote/.minikube",
+	}, "")
+	if err != nil {
+		t.Fatalf("Error creating remote client: %s", err)
+	}
+	defer c.Close()
+
+	if exists, err := c.Exists("minikube"); err != nil || !exists {
+		t.Errorf("Expected host minikube to exist, got: %t, %v", exists, err)
+	}
+	if exists, err := c.Exists("other"); err != nil || exists {
+		t.Errorf("Expected host other not to exist, got: %t, %v", exists, err)
+	}
+	hosts, err := c.List()
+	if err != nil {
+		t.Fatalf("Error listing hosts: %s", err)
+	}
+	if len(hosts) != 1 || hosts[0] != "minikube" {
+		t.Errorf("Expected hosts [minikube], got: %v", hosts)
+	}
+	if _, err := c.Load("other"); err == nil {
+		t.Errorf("Expected error loading nonexistent host")
+	}
+}
+
 func makeTempDir() string {
 	tempDir, err := ioutil.TempDir("", "minipath")
 	if err != nil {
@@ -211,42 +445,165 @@ func makeTempDir() string {
 func TestRunNotDriver(t *testing.T) {
 	tempDir := makeTempDir()
 	defer os.RemoveAll(tempDir)
-	StartDriver()
+	StartDriverFromEnv()
 	if !localbinary.CurrentBinaryIsDockerMachine {
 		t.Fatal("CurrentBinaryIsDockerMachine not set. This will break driver initialization.")
 	}
 }
 
 func TestRunDriver(t *testing.T) {
-	// This test is a bit complicated. It verifies that when the root command is
-	// called with the proper environment variables, we setup the libmachine driver.
+	// This test verifies that several driver plugins can be served from one
+	// process, each announcing its own port and answering RPC calls.
 
 	tempDir := makeTempDir()
 	defer os.RemoveAll(tempDir)
-	os.Setenv(localbinary.PluginEnvKey, localbinary.PluginEnvVal)
-	os.Setenv(localbinary.PluginEnvDriverName, "virtualbox")
-
-	// Capture stdout and reset it later.
-	old := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-	defer func() {
-		os.Stdout = old
-	}()
-
-	// Run the command asynchronously. It should listen on a port for connections.
-	go StartDriver()
-
-	// The command will write out what port it's listening on over stdout.
-	reader := bufio.NewReader(r)
-	addr, _, err := reader.ReadLine()
+
+	var servers []*DriverServer
+	for i := 0; i < 2; i++ {
+		s, err := RegisterDriver("virtualbox")
+		if err != nil {
+			t.Fatalf("Error registering driver: %s", err)
+		}
+		var announced bytes.Buffer
+		if err := s.Announce(&announced); err != nil {
+			t.Fatalf("Error announcing driver: %s", err)
+		}
+		if expected := s.Addr.String() + "\n"; announced.String() != expected {
+			t.Fatalf("Expected the announcement %q, got %q", expected, announced.String())
+		}
+		servers = append(servers, s)
+	}
+	if servers[0].Addr.String() == servers[1].Addr.String() {
+		t.Fatalf("Expected driver servers to listen on different ports, both on %s", servers[0].Addr)
+	}
+
+	for _, s := range servers {
+		done := make(chan error, 1)
+		go func(s *DriverServer) { done <- s.Serve() }(s)
+
+		c, err := rpc.DialHTTP("tcp", s.Addr.String())
+		if err != nil {
+			t.Fatalf("Driver not listening: %s", err)
+		}
+		var name string
+		if err := c.Call(rpcdriver.RPCServiceNameV1+rpcdriver.DriverNameMethod, struct{}{}, &name); err != nil {
+			t.Fatalf("Error calling driver: %s", err)
+		}
+		if name != "virtualbox" {
+			t.Errorf("Expected driver name virtualbox, got: %s", name)
+		}
+		if err := c.Call(rpcdriver.RPCServiceNameV1+rpcdriver.CloseMethod, struct{}{}, nil); err != nil {
+			t.Fatalf("Error closing driver: %s", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Expected Serve to return cleanly after close, got: %s", err)
+		}
+		c.Close()
+	}
+}
+
+// TestDriverPluginHelper is the driver plugin the handshake tests launch. It prints to its
+// stdout before serving the driver, which would be taken for its address there.
+func TestDriverPluginHelper(t *testing.T) {
+	if os.Getenv(localbinary.PluginEnvKey) != localbinary.PluginEnvVal {
+		return
+	}
+	driverMap[resizableDriverName] = newResizableDriver
+	driverMap[hangingDriverName] = newHangingDriver
+	fmt.Println("(virtualbox) Starting the driver")
+	if err := StartDriver(os.Getenv(localbinary.PluginEnvDriverName), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func driverPluginHelper() *exec.Cmd {
+	return exec.Command(os.Args[0], "-test.run=TestDriverPluginHelper")
+}
+
+func TestPluginDriverFactory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The driver plugins announce their address on stdout on Windows")
+	}
+	f := newPluginDriverFactory()
+	f.command = func() (*exec.Cmd, error) { return driverPluginHelper(), nil }
+
+	d, err := f.NewRPCClientDriver("virtualbox", []byte(`{"MachineName": "minikube"}`))
 	if err != nil {
-		t.Fatal("Failed to read address over stdout.")
+		t.Fatalf("Error launching the driver plugin: %s", err)
+	}
+	if name := d.DriverName(); name != "virtualbox" {
+		t.Errorf("Expected driver name virtualbox, got: %s", name)
+	}
+	if name := d.GetMachineName(); name != "minikube" {
+		t.Errorf("Expected machine name minikube, got: %s", name)
+	}
+
+	plugins := f.plugins
+	if err := f.Close(); err != nil {
+		t.Fatalf("Error closing the driver plugins: %s", err)
 	}
-	os.Stdout = old
+	for _, p := range plugins {
+		if !p.cmd.ProcessState.Exited() {
+			t.Errorf("Expected the driver plugin to exit once closed, got: %s", p.cmd.ProcessState)
+		}
+	}
+}
+
+func TestPluginSocketHandshake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "handshake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plugin.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix sockets are unavailable: %s", err)
+	}
+	defer l.Close()
+
+	cmd := driverPluginHelper()
+	cmd.Env = append(os.Environ(),
+		localbinary.PluginEnvKey+"="+localbinary.PluginEnvVal,
+		localbinary.PluginEnvDriverName+"=virtualbox",
+		PluginSocketEnv+"="+path)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Error launching the driver plugin: %s", err)
+	}
+	defer cmd.Process.Kill()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Error accepting the handshake: %s", err)
+	}
+	addr, err := bufio.NewReader(conn).ReadString('\n')
+	conn.Close()
+	if err != nil {
+		t.Fatalf("Error reading the address: %s", err)
+	}
+	c, err := rpc.DialHTTP("tcp", strings.TrimSpace(addr))
+	if err != nil {
+		t.Fatalf("Driver not listening at %q: %s", addr, err)
+	}
+	defer c.Close()
+	if err := c.Call(rpcdriver.RPCServiceNameV1+rpcdriver.CloseMethod, struct{}{}, nil); err != nil {
+		t.Fatalf("Error closing driver: %s", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("Expected the driver plugin to exit cleanly, got: %s", err)
+	}
+	// The address is still announced on stdout for docker-machine, after what the driver printed.
+	if expected := "(virtualbox) Starting the driver\n" + addr; !strings.HasPrefix(stdout.String(), expected) {
+		t.Errorf("Expected the stdout to start with %q, got %q", expected, stdout.String())
+	}
+}
 
-	// Now that we got the port, make sure we can connect.
-	if _, err := net.Dial("tcp", string(addr)); err != nil {
-		t.Fatal("Driver not listening.")
+func TestRegisterUnknownDriver(t *testing.T) {
+	if _, err := RegisterDriver("foo"); err == nil {
+		t.Fatal("Expected error registering unknown driver")
 	}
 }
@@ -17,38 +17,15 @@ limitations under the License.
 package machine
 
 import (
-	"encoding/json"
-
 	"github.com/docker/machine/drivers/hyperv"
-	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/libmachine/drivers"
-	"github.com/docker/machine/libmachine/drivers/plugin"
-	"github.com/golang/glog"
-	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
 )
 
-var driverMap = map[string]driverGetter{
-	"hyperv":     getHyperVDriver,
-	"virtualbox": getVirtualboxDriver,
-}
-
-func getHyperVDriver(rawDriver []byte) (drivers.Driver, error) {
-	var driver drivers.Driver
-	driver = &hyperv.Driver{}
-	if err := json.Unmarshal(rawDriver, &driver); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling hyperv driver")
-	}
-	return driver, nil
+var driverMap = map[string]func() drivers.Driver{
+	"hyperv":     func() drivers.Driver { return hyperv.NewDriver("", "") },
+	"virtualbox": func() drivers.Driver { return virtualbox.NewDriver("", "") },
 }
 
-// StartDriver starts the desired machine driver if necessary.
-func registerDriver(driverName string) {
-	switch driverName {
-	case "virtualbox":
-		plugin.RegisterDriver(virtualbox.NewDriver("", ""))
-	case "hyperv":
-		plugin.RegisterDriver(hyperv.NewDriver("", ""))
-	default:
-		glog.Exitf("Unsupported driver: %s\n", driverName)
-	}
-}
+// pluginOnlyDrivers are drivers that are supported, but only through an external plugin.
+var pluginOnlyDrivers = map[string]string{}
@@ -0,0 +1,58 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"context"
+
+	"github.com/docker/machine/libmachine"
+)
+
+// aborter is implemented by clients whose calls in flight can be cut short.
+type aborter interface {
+	// abort makes the client's calls in flight fail, so that they don't hang
+	// once their caller has given up on them.
+	abort()
+}
+
+// abort closes the connections to the driver plugins, and the plugins, of the factory
+// NewHost and Load launch them with. The RPC calls have no deadline of their own, so this
+// is what ends those stuck behind a wedged driver. Drivers started afterwards get new
+// connections.
+func (api *rpcClient) abort() {
+	api.driverFactory.Close()
+}
+
+// RunWithContext runs op, a sequence of calls to api, until it returns or ctx is done.
+// When ctx is done first, ctx.Err() is returned and api's calls in flight are
+// aborted if it supports it. Otherwise op carries on in the background, so it must
+// not share unsynchronized state with the caller.
+func RunWithContext(ctx context.Context, api libmachine.API, op func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- op()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if a, ok := api.(aborter); ok {
+			a.abort()
+		}
+		return ctx.Err()
+	}
+}
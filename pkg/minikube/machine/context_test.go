@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+const hangingDriverName = "hanging"
+
+// hangingDriver is the driver the plugin helper serves to test aborting a wedged call.
+type hangingDriver struct {
+	*tests.HangingDriver
+}
+
+func newHangingDriver() drivers.Driver {
+	return &hangingDriver{tests.NewHangingDriver()}
+}
+
+func (d *hangingDriver) DriverName() string {
+	return hangingDriverName
+}
+
+func TestRunWithContextAbortsPluginCall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The driver plugins announce their address on stdout on Windows")
+	}
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+	driverMap[hangingDriverName] = newHangingDriver
+	defer delete(driverMap, hangingDriverName)
+
+	api := newPluginRPCClient(tempDir)
+	defer api.Close()
+	h, err := api.NewHost(hangingDriverName, []byte(`{"MachineName": "minikube"}`))
+	if err != nil {
+		t.Fatalf("Error creating the host: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := make(chan error, 1)
+	err = RunWithContext(ctx, api, func() error {
+		err := h.Driver.Start()
+		started <- err
+		return err
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected the wedged start to time out, got: %v", err)
+	}
+	// The call in flight fails once its plugin is closed, rather than hanging with the driver.
+	select {
+	case err := <-started:
+		if err == nil {
+			t.Errorf("Expected the aborted start to fail")
+		}
+	case <-time.After(pluginStopTimeout + 5*time.Second):
+		t.Fatalf("Expected the wedged start to be cut short")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// DriverDef describes a driver minikube can create its VM with.
+type DriverDef struct {
+	Name string
+	// Flags are the driver-specific start flags the driver uses.
+	Flags []string
+	// OSes are the host operating systems, as GOOS values, the driver runs on.
+	OSes []string
+	// Privileged is whether the driver needs setup as root, such as a setuid binary.
+	Privileged bool
+	// Binary is the program the driver needs in PATH.
+	Binary string
+}
+
+var driverDefs = []DriverDef{
+	{
+		Name:   "virtualbox",
+		Flags:  []string{"host-only-cidr", "extra-disks", "extra-disk-size", "nat-forward", "nat-forward-kubeconfig"},
+		OSes:   []string{"darwin", "linux", "windows"},
+		Binary: "VBoxManage",
+	},
+	{
+		Name:   "vmwarefusion",
+		OSes:   []string{"darwin"},
+		Binary: "vmrun",
+	},
+	{
+		Name:   "kvm",
+		Flags:  []string{"kvm-network"},
+		OSes:   []string{"linux"},
+		Binary: "docker-machine-driver-kvm",
+	},
+	{
+		Name:   "kvm2",
+		Flags:  []string{"kvm-network", "extra-disks", "extra-disk-size", "gpu"},
+		OSes:   []string{"linux"},
+		Binary: "virsh",
+	},
+	{
+		Name:       "xhyve",
+		Flags:      []string{"xhyve-disk-driver"},
+		OSes:       []string{"darwin"},
+		Privileged: true,
+		Binary:     "docker-machine-driver-xhyve",
+	},
+	{
+		Name:       "hyperkit",
+		OSes:       []string{"darwin"},
+		Privileged: true,
+		Binary:     "hyperkit",
+	},
+	{
+		Name:       "hyperv",
+		Flags:      []string{"hyperv-virtual-switch", "hyperv-use-external-switch"},
+		OSes:       []string{"windows"},
+		Privileged: true,
+		Binary:     "powershell",
+	},
+	{
+		Name:       "none",
+		OSes:       []string{"linux"},
+		Privileged: true,
+		Binary:     "systemctl",
+	},
+}
+
+// SupportsOS returns whether the driver runs on the given GOOS.
+func (d DriverDef) SupportsOS(goos string) bool {
+	for _, o := range d.OSes {
+		if o == goos {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsFlag returns whether the driver uses the given driver-specific start flag.
+func (d DriverDef) SupportsFlag(flag string) bool {
+	for _, f := range d.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// BinaryFound returns whether the program the driver needs is in PATH.
+func (d DriverDef) BinaryFound() bool {
+	_, err := exec.LookPath(d.Binary)
+	return err == nil
+}
+
+// Drivers returns the drivers which run on the current OS.
+func Drivers() []DriverDef {
+	var defs []DriverDef
+	for _, d := range driverDefs {
+		if d.SupportsOS(runtime.GOOS) {
+			defs = append(defs, d)
+		}
+	}
+	return defs
+}
+
+// FindDriverDef returns the named driver, if it runs on the current OS.
+func FindDriverDef(name string) (DriverDef, bool) {
+	for _, d := range Drivers() {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return DriverDef{}, false
+}
+
+// UnsupportedFlags returns those of the given flags which are specific
+// to other drivers, and so are ignored by the named driver.
+func UnsupportedFlags(name string, flags []string) []string {
+	def, _ := FindDriverDef(name)
+	var unsupported []string
+	for _, f := range flags {
+		if def.SupportsFlag(f) {
+			continue
+		}
+		for _, d := range driverDefs {
+			if d.SupportsFlag(f) {
+				unsupported = append(unsupported, f)
+				break
+			}
+		}
+	}
+	return unsupported
+}
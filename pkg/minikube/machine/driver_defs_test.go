@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestDriverDefsForSupportedDrivers(t *testing.T) {
+	for _, name := range constants.SupportedVMDrivers {
+		if _, ok := FindDriverDef(name); !ok {
+			t.Errorf("No driver definition for supported driver %s", name)
+		}
+	}
+}
+
+func TestUnsupportedFlags(t *testing.T) {
+	var tests = []struct {
+		description string
+		driver      string
+		flags       []string
+		expected    []string
+	}{
+		{
+			description: "generic flags",
+			driver:      "virtualbox",
+			flags:       []string{"memory", "cpus", "iso-url"},
+		},
+		{
+			description: "driver's own flag",
+			driver:      "virtualbox",
+			flags:       []string{"host-only-cidr"},
+		},
+		{
+			description: "other driver's flags",
+			driver:      "virtualbox",
+			flags:       []string{"memory", "hyperv-virtual-switch", "xhyve-disk-driver"},
+			expected:    []string{"hyperv-virtual-switch", "xhyve-disk-driver"},
+		},
+		{
+			description: "extra disks",
+			driver:      "hyperkit",
+			flags:       []string{"extra-disks", "extra-disk-size"},
+			expected:    []string{"extra-disks", "extra-disk-size"},
+		},
+		{
+			description: "unknown driver",
+			driver:      "notadriver",
+			flags:       []string{"memory", "host-only-cidr"},
+			expected:    []string{"host-only-cidr"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := UnsupportedFlags(test.driver, test.flags); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
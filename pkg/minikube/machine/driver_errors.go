@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"regexp"
+
+	"github.com/docker/machine/drivers/virtualbox"
+	"github.com/pkg/errors"
+)
+
+// machineNotFoundErrors match the errors each driver returns when its VM no longer exists.
+// Plugin drivers report errors over RPC, so they can only be matched on their message.
+var machineNotFoundErrors = map[string]*regexp.Regexp{
+	"virtualbox": regexp.MustCompile(`Could not find a registered machine named|` + regexp.QuoteMeta(virtualbox.ErrMachineNotExist.Error())),
+	"kvm":        regexp.MustCompile(`[Dd]omain not found|no domain with matching name`),
+	"kvm2":       regexp.MustCompile(`[Dd]omain not found|no domain with matching name|failed to get domain`),
+	"xhyve":      regexp.MustCompile(`[Mm]achine does not exist|hyperkit\.pid: no such file or directory`),
+	"hyperkit":   regexp.MustCompile(`[Mm]achine does not exist|hyperkit\.pid: no such file or directory`),
+}
+
+// IsMachineNotFound returns whether err, returned by the named driver,
+// means that the driver's VM has been deleted.
+func IsMachineNotFound(driverName string, err error) bool {
+	if err == nil {
+		return false
+	}
+	err = errors.Cause(err)
+	if err == virtualbox.ErrMachineNotExist {
+		return true
+	}
+	re, ok := machineNotFoundErrors[driverName]
+	if !ok {
+		return false
+	}
+	return re.MatchString(err.Error())
+}
+
+// transientErrors match the errors each driver returns when an operation failed
+// for a passing reason, such as the VM being briefly locked, so retrying it is worth it.
+var transientErrors = map[string]*regexp.Regexp{
+	"virtualbox": regexp.MustCompile(`E_FAIL|E_ACCESSDENIED|VBOX_E_INVALID_OBJECT_STATE|is already locked|[Tt]imed out`),
+	"kvm":        regexp.MustCompile(`cannot acquire state change lock|[Tt]imed out`),
+	"kvm2":       regexp.MustCompile(`cannot acquire state change lock|[Tt]imed out`),
+	"hyperv":     regexp.MustCompile(`cannot be performed while the object is in its current state|[Tt]imed out`),
+	"xhyve":      regexp.MustCompile(`resource temporarily unavailable|[Tt]imed out`),
+	"hyperkit":   regexp.MustCompile(`resource temporarily unavailable|[Tt]imed out`),
+}
+
+// permanentErrors match errors which retrying can't fix. VirtualBox reports
+// missing hardware virtualization as E_FAIL too.
+var permanentErrors = regexp.MustCompile(`VT-x is not available|AMD-V is not available|VERR_VMX_NO_VMX|VERR_VMX_MSR_ALL_VMX_DISABLED|VERR_SVM_DISABLED`)
+
+// IsTransient returns whether err, returned by an operation of the named
+// driver, is likely to go away when the operation is retried.
+func IsTransient(driverName string, err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := errors.Cause(err).Error()
+	if permanentErrors.MatchString(msg) {
+		return false
+	}
+	re, ok := transientErrors[driverName]
+	if !ok {
+		return false
+	}
+	return re.MatchString(msg)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/virtualbox"
+	"github.com/pkg/errors"
+)
+
+func TestIsMachineNotFound(t *testing.T) {
+	var tests = []struct {
+		description string
+		driverName  string
+		err         error
+		expected    bool
+	}{
+		{
+			description: "no error",
+			driverName:  "virtualbox",
+		},
+		{
+			description: "virtualbox driver error",
+			driverName:  "virtualbox",
+			err:         errors.Wrap(virtualbox.ErrMachineNotExist, "Error getting state"),
+			expected:    true,
+		},
+		{
+			description: "virtualbox plugin error",
+			driverName:  "virtualbox",
+			err:         errors.New("machine does not exist"),
+			expected:    true,
+		},
+		{
+			description: "vboxmanage error",
+			driverName:  "virtualbox",
+			err:         errors.New("VBoxManage: error: Could not find a registered machine named 'minikube'"),
+			expected:    true,
+		},
+		{
+			description: "kvm error",
+			driverName:  "kvm",
+			err:         errors.New("virError(Code=42, Domain=10, Message='Domain not found: no domain with matching name 'minikube'')"),
+			expected:    true,
+		},
+		{
+			description: "hyperkit error",
+			driverName:  "hyperkit",
+			err:         errors.New("open /Users/minikube/.minikube/machines/minikube/hyperkit.pid: no such file or directory"),
+			expected:    true,
+		},
+		{
+			description: "message from another driver",
+			driverName:  "kvm",
+			err:         errors.New("Could not find a registered machine named 'minikube'"),
+		},
+		{
+			description: "unrelated error",
+			driverName:  "virtualbox",
+			err:         errors.New("VBoxManage not found. Make sure VirtualBox is installed and VBoxManage is in the path"),
+		},
+		{
+			description: "unknown driver",
+			driverName:  "foo",
+			err:         errors.New("machine does not exist"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := IsMachineNotFound(test.driverName, test.err); actual != test.expected {
+				t.Errorf("Expected %t for %v, got: %t", test.expected, test.err, actual)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	var tests = []struct {
+		description string
+		driverName  string
+		err         error
+		expected    bool
+	}{
+		{
+			description: "no error",
+			driverName:  "virtualbox",
+		},
+		{
+			description: "virtualbox E_FAIL",
+			driverName:  "virtualbox",
+			err:         errors.Wrap(errors.New("VBoxManage: error: The machine 'minikube' is already locked for a session (or being unlocked)\nVBoxManage: error: Details: code VBOX_E_INVALID_OBJECT_STATE (0x80bb0007)"), "Error starting host"),
+			expected:    true,
+		},
+		{
+			description: "virtualbox VT-x not available",
+			driverName:  "virtualbox",
+			err:         errors.New("VBoxManage: error: VT-x is not available (VERR_VMX_NO_VMX)\nVBoxManage: error: Details: code E_FAIL (0x80004005)"),
+		},
+		{
+			description: "kvm lock",
+			driverName:  "kvm2",
+			err:         errors.New("virsh start minikube: exit status 1: error: Timed out during operation: cannot acquire state change lock"),
+			expected:    true,
+		},
+		{
+			description: "permanent error",
+			driverName:  "virtualbox",
+			err:         errors.New("VBoxManage not found. Make sure VirtualBox is installed and VBoxManage is in the path"),
+		},
+		{
+			description: "message from another driver",
+			driverName:  "hyperkit",
+			err:         errors.New("code E_FAIL (0x80004005)"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := IsTransient(test.driverName, test.err); actual != test.expected {
+				t.Errorf("Expected %t for %v, got: %t", test.expected, test.err, actual)
+			}
+		})
+	}
+}
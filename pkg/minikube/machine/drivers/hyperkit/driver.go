@@ -0,0 +1,103 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperkit
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+const (
+	driverName = "hyperkit"
+
+	isoFilename     = "boot2docker.iso"
+	kernelFilename  = "bzimage"
+	initrdFilename  = "initrd"
+	pidFilename     = "hyperkit.pid"
+	ttyFilename     = "tty"
+	consoleFilename = "console-ring"
+	dockerPort      = 2376
+)
+
+// Driver is a hyperkit driver built into minikube. It runs the hyperkit
+// binary directly, with networking through vmnet.
+type Driver struct {
+	*drivers.BaseDriver
+
+	// Memory is the amount of memory for the VM, in MB.
+	Memory int
+	// CPU is the number of virtual CPUs for the VM.
+	CPU int
+	// DiskSize is the size of the VM's disk, in MB.
+	DiskSize int
+	// Boot2DockerURL is where the ISO is copied from when the VM is created.
+	Boot2DockerURL string
+	// Cmdline is the kernel command line the VM boots with.
+	Cmdline string
+	// UUID identifies the VM to vmnet, which derives its MAC address from it.
+	UUID string
+	// MACAddress is the address vmnet gives the VM, used to find its DHCP lease.
+	MACAddress string
+}
+
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: hostName,
+			StorePath:   storePath,
+			SSHUser:     "docker",
+			SSHPort:     22,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return driverName
+}
+
+func (d *Driver) diskPath() string {
+	return d.ResolveStorePath(fmt.Sprintf("%s.rawdisk", d.MachineName))
+}
+
+// hyperkitArgs returns the hyperkit arguments that boot the VM: the minikube ISO's
+// kernel is booted directly, with the ISO attached as a CD and a raw disk for storage.
+func (d *Driver) hyperkitArgs() []string {
+	return []string{
+		"-A", "-u",
+		"-F", d.ResolveStorePath(pidFilename),
+		"-c", strconv.Itoa(d.CPU),
+		"-m", fmt.Sprintf("%dM", d.Memory),
+		"-s", "0:0,hostbridge",
+		"-s", "31,lpc",
+		"-s", "1:0,virtio-net",
+		"-U", d.UUID,
+		"-s", "2:0,virtio-blk," + d.diskPath(),
+		"-s", "3,ahci-cd," + d.ResolveStorePath(isoFilename),
+		"-s", "4,virtio-rnd",
+		"-l", fmt.Sprintf("com1,autopty=%s,log=%s", d.ResolveStorePath(ttyFilename), d.ResolveStorePath(consoleFilename)),
+		"-f", fmt.Sprintf("kexec,%s,%s,%s", d.ResolveStorePath(kernelFilename), d.ResolveStorePath(initrdFilename), d.Cmdline),
+	}
+}
+
+// macAddressArgs returns the hyperkit arguments that print the MAC address
+// vmnet gives the VM's UUID, without booting it.
+func (d *Driver) macAddressArgs() []string {
+	return []string{"-M", "-s", "0:0,hostbridge", "-s", "31,lpc", "-s", "1:0,virtio-net", "-U", d.UUID, "-f", "kexec,/dev/null"}
+}
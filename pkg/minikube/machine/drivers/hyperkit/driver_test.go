@@ -0,0 +1,49 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHyperkitArgs(t *testing.T) {
+	d := NewDriver("minikube", "/store")
+	d.CPU = 2
+	d.Memory = 2048
+	d.UUID = "c3b0e6b2-4f0a-11e7-9a7e-acde48001122"
+	d.Cmdline = "loglevel=3 user=docker console=ttyS0 base host=minikube"
+
+	expected := []string{
+		"-A", "-u",
+		"-F", "/store/machines/minikube/hyperkit.pid",
+		"-c", "2",
+		"-m", "2048M",
+		"-s", "0:0,hostbridge",
+		"-s", "31,lpc",
+		"-s", "1:0,virtio-net",
+		"-U", "c3b0e6b2-4f0a-11e7-9a7e-acde48001122",
+		"-s", "2:0,virtio-blk,/store/machines/minikube/minikube.rawdisk",
+		"-s", "3,ahci-cd,/store/machines/minikube/boot2docker.iso",
+		"-s", "4,virtio-rnd",
+		"-l", "com1,autopty=/store/machines/minikube/tty,log=/store/machines/minikube/console-ring",
+		"-f", "kexec,/store/machines/minikube/bzimage,/store/machines/minikube/initrd,loglevel=3 user=docker console=ttyS0 base host=minikube",
+	}
+	if args := d.hyperkitArgs(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected hyperkit arguments:\n%q\ngot:\n%q", expected, args)
+	}
+}
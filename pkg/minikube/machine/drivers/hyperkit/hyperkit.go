@@ -0,0 +1,280 @@
+// +build darwin
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperkit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return []mcnflag.Flag{}
+}
+
+func (d *Driver) SetConfigFromFlags(opts drivers.DriverOptions) error {
+	return nil
+}
+
+// PreCreateCheck checks that hyperkit is installed and can use vmnet, which needs root.
+func (d *Driver) PreCreateCheck() error {
+	path, err := exec.LookPath("hyperkit")
+	if err != nil {
+		return errors.New("hyperkit is a requirement in order to use the hyperkit driver, please install it or Docker for Mac")
+	}
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); !ok || st.Uid != 0 || fi.Mode()&os.ModeSetuid == 0 {
+		return fmt.Errorf(`hyperkit needs to run as root to use vmnet, please run:
+	sudo chown root:wheel %[1]s && sudo chmod u+s %[1]s`, path)
+	}
+	return nil
+}
+
+func (d *Driver) Create() error {
+	log.Info("Copying ISO to machine directory...")
+	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+		return errors.Wrap(err, "Error copying ISO to machine directory")
+	}
+	if err := d.extractKernel(); err != nil {
+		return err
+	}
+
+	log.Info("Creating SSH key...")
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "Error generating SSH key")
+	}
+
+	log.Info("Creating raw disk image...")
+	if err := createRawDisk(d.diskPath(), d.DiskSize, d.GetSSHKeyPath()+".pub"); err != nil {
+		return errors.Wrap(err, "Error creating disk image")
+	}
+
+	if d.UUID == "" {
+		d.UUID = uuid.NewUUID().String()
+	}
+	out, err := exec.Command("hyperkit", d.macAddressArgs()...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "Error getting the VM's MAC address: %s", strings.TrimSpace(string(out)))
+	}
+	if d.MACAddress, err = parseMACOutput(string(out)); err != nil {
+		return err
+	}
+	log.Debugf("vmnet MAC address for %s: %s", d.UUID, d.MACAddress)
+
+	return d.Start()
+}
+
+// extractKernel extracts the kernel and initrd, which hyperkit boots directly, from the ISO.
+func (d *Driver) extractKernel() error {
+	dir := d.ResolveStorePath("")
+	tmp, err := ioutil.TempDir(dir, "iso")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	// bsdtar reads ISO 9660 images.
+	out, err := exec.Command("tar", "-C", tmp, "-xf", d.ResolveStorePath(isoFilename), "boot/"+kernelFilename, "boot/"+initrdFilename).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "Error extracting kernel from ISO: %s", strings.TrimSpace(string(out)))
+	}
+	for _, f := range []string{kernelFilename, initrdFilename} {
+		if err := os.Rename(filepath.Join(tmp, "boot", f), d.ResolveStorePath(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) GetIP() (string, error) {
+	s, err := d.GetState()
+	if err != nil {
+		return "", err
+	}
+	if s != state.Running {
+		return "", drivers.ErrHostIsNotRunning
+	}
+	return d.IPAddress, nil
+}
+
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tcp://%s:%d", ip, dockerPort), nil
+}
+
+// pid returns the hyperkit process ID, or 0 if it isn't running.
+func (d *Driver) pid() (int, error) {
+	b, err := ioutil.ReadFile(d.ResolveStorePath(pidFilename))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, errors.Wrapf(err, "Error parsing %s", pidFilename)
+	}
+	// Signal 0 only checks that the process exists.
+	if err := syscall.Kill(pid, 0); err == syscall.ESRCH {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	pid, err := d.pid()
+	if err != nil {
+		return state.Error, err
+	}
+	if pid == 0 {
+		return state.Stopped, nil
+	}
+	return state.Running, nil
+}
+
+func (d *Driver) Start() error {
+	log.Info("Starting hyperkit...")
+	console, err := os.Create(d.ResolveStorePath("hyperkit.log"))
+	if err != nil {
+		return err
+	}
+	defer console.Close()
+	cmd := exec.Command("hyperkit", d.hyperkitArgs()...)
+	cmd.Stdout = console
+	cmd.Stderr = console
+	// Keep the VM running when minikube is interrupted.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	log.Debugf("hyperkit %s", strings.Join(cmd.Args[1:], " "))
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "Error starting hyperkit")
+	}
+	if err := cmd.Process.Release(); err != nil {
+		return err
+	}
+
+	log.Info("Waiting to get IP...")
+	return mcnutils.WaitForSpecificOrError(func() (bool, error) {
+		if s, err := d.GetState(); err != nil || s != state.Running {
+			return false, fmt.Errorf("hyperkit exited, see %s", d.ResolveStorePath("hyperkit.log"))
+		}
+		ip, err := d.leaseIP()
+		if err != nil {
+			log.Debugf("Waiting for IP: %s", err)
+			return false, nil
+		}
+		d.IPAddress = ip
+		return true, nil
+	}, 60, 2*time.Second)
+}
+
+func (d *Driver) leaseIP() (string, error) {
+	f, err := os.Open(leasesPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	leases, err := parseLeases(f)
+	if err != nil {
+		return "", err
+	}
+	return findLeaseIP(leases, d.MACAddress)
+}
+
+func (d *Driver) signal(sig syscall.Signal) error {
+	pid, err := d.pid()
+	if err != nil || pid == 0 {
+		return err
+	}
+	return syscall.Kill(pid, sig)
+}
+
+// Stop asks hyperkit to shut the VM down, which it does through ACPI on SIGTERM.
+func (d *Driver) Stop() error {
+	if err := d.signal(syscall.SIGTERM); err != nil {
+		return errors.Wrap(err, "Error stopping hyperkit")
+	}
+	return mcnutils.WaitForSpecificOrError(func() (bool, error) {
+		s, err := d.GetState()
+		return s == state.Stopped, err
+	}, 60, time.Second)
+}
+
+func (d *Driver) Kill() error {
+	return d.signal(syscall.SIGKILL)
+}
+
+func (d *Driver) Restart() error {
+	if err := d.Stop(); err != nil {
+		return err
+	}
+	return d.Start()
+}
+
+// Remove kills the VM. Its files are removed with the machine directory.
+func (d *Driver) Remove() error {
+	return d.Kill()
+}
+
+// createRawDisk creates the raw disk image of the given size in MB. It starts
+// with the boot2docker tar containing the public SSH key, which tells the VM to
+// format the disk and install the key on first boot.
+func createRawDisk(path string, sizeMB int, publicSSHKeyPath string) error {
+	tarBuf, err := mcnutils.MakeDiskImage(publicSSHKeyPath)
+	if err != nil {
+		return errors.Wrap(err, "Error making disk image tar")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(tarBuf.Bytes()); err != nil {
+		return err
+	}
+	return f.Truncate(int64(sizeMB) << 20)
+}
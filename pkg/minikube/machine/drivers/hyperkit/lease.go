@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperkit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// leasesPath is where the macOS DHCP server, which serves vmnet, records its leases.
+const leasesPath = "/var/db/dhcpd_leases"
+
+// dhcpLease is an entry of the DHCP leases file.
+type dhcpLease struct {
+	Name       string
+	IPAddress  string
+	HWAddress  string
+	Identifier string
+	Lease      string
+}
+
+// parseLeases parses the DHCP leases file, whose entries look like:
+//	{
+//		name=minikube
+//		ip_address=192.168.64.2
+//		hw_address=1,a2:b:c3:d4:e5:f6
+//		identifier=1,a2:b:c3:d4:e5:f6
+//		lease=0x5935e4f6
+//	}
+func parseLeases(r io.Reader) ([]dhcpLease, error) {
+	var leases []dhcpLease
+	var lease *dhcpLease
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case line == "{":
+			lease = &dhcpLease{}
+		case line == "}":
+			if lease != nil {
+				leases = append(leases, *lease)
+			}
+			lease = nil
+		case lease != nil:
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("Unexpected line in DHCP leases: %q", line)
+			}
+			switch kv[0] {
+			case "name":
+				lease.Name = kv[1]
+			case "ip_address":
+				lease.IPAddress = kv[1]
+			case "hw_address":
+				// The address is prefixed with its hardware type.
+				lease.HWAddress = kv[1][strings.Index(kv[1], ",")+1:]
+			case "identifier":
+				lease.Identifier = kv[1]
+			case "lease":
+				lease.Lease = kv[1]
+			}
+		}
+	}
+	return leases, errors.Wrap(scanner.Err(), "Error reading DHCP leases")
+}
+
+// normalizeMAC drops the leading zeros of each octet, which the leases file omits.
+func normalizeMAC(mac string) string {
+	octets := strings.Split(strings.ToLower(mac), ":")
+	for i, o := range octets {
+		if o = strings.TrimLeft(o, "0"); o == "" {
+			o = "0"
+		}
+		octets[i] = o
+	}
+	return strings.Join(octets, ":")
+}
+
+// findLeaseIP returns the IP address leased to the given MAC address.
+// The leases file lists the newest leases first.
+func findLeaseIP(leases []dhcpLease, mac string) (string, error) {
+	mac = normalizeMAC(mac)
+	for _, l := range leases {
+		if normalizeMAC(l.HWAddress) == mac {
+			return l.IPAddress, nil
+		}
+	}
+	return "", fmt.Errorf("No IP address leased to %s yet", mac)
+}
+
+var macOutputRegexp = regexp.MustCompile(`MAC: ([0-9a-fA-F]{1,2}(?::[0-9a-fA-F]{1,2}){5})`)
+
+// parseMACOutput finds the MAC address in the output of hyperkit -M.
+func parseMACOutput(out string) (string, error) {
+	m := macOutputRegexp.FindStringSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("No MAC address in hyperkit output: %s", strings.TrimSpace(out))
+	}
+	return m[1], nil
+}
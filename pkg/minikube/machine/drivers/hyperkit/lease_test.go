@@ -0,0 +1,117 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const leasesFile = `{
+	name=minikube
+	ip_address=192.168.64.3
+	hw_address=1,a2:b:c3:4:e5:f6
+	identifier=1,a2:b:c3:4:e5:f6
+	lease=0x5935e4f6
+}
+{
+	name=other
+	ip_address=192.168.64.2
+	hw_address=1,12:34:56:78:9a:bc
+	identifier=1,12:34:56:78:9a:bc
+	lease=0x5935d1a2
+}
+`
+
+func TestParseLeases(t *testing.T) {
+	leases, err := parseLeases(strings.NewReader(leasesFile))
+	if err != nil {
+		t.Fatalf("Error parsing leases: %s", err)
+	}
+	expected := []dhcpLease{
+		{Name: "minikube", IPAddress: "192.168.64.3", HWAddress: "a2:b:c3:4:e5:f6", Identifier: "1,a2:b:c3:4:e5:f6", Lease: "0x5935e4f6"},
+		{Name: "other", IPAddress: "192.168.64.2", HWAddress: "12:34:56:78:9a:bc", Identifier: "1,12:34:56:78:9a:bc", Lease: "0x5935d1a2"},
+	}
+	if !reflect.DeepEqual(leases, expected) {
+		t.Errorf("Expected leases %+v, got %+v", expected, leases)
+	}
+}
+
+func TestParseLeasesInvalid(t *testing.T) {
+	if _, err := parseLeases(strings.NewReader("{\n\tname\n}\n")); err == nil {
+		t.Errorf("Expected an error parsing an invalid lease")
+	}
+}
+
+func TestFindLeaseIP(t *testing.T) {
+	leases, err := parseLeases(strings.NewReader(leasesFile))
+	if err != nil {
+		t.Fatalf("Error parsing leases: %s", err)
+	}
+	var tests = []struct {
+		description string
+		mac         string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "leading zeros",
+			mac:         "a2:0b:c3:04:e5:f6",
+			expected:    "192.168.64.3",
+		},
+		{
+			description: "upper case",
+			mac:         "12:34:56:78:9A:BC",
+			expected:    "192.168.64.2",
+		},
+		{
+			description: "no lease",
+			mac:         "de:ad:be:ef:00:01",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			ip, err := findLeaseIP(leases, test.mac)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error, got IP %s", ip)
+			}
+			if ip != test.expected {
+				t.Errorf("Expected IP %s, got %s", test.expected, ip)
+			}
+		})
+	}
+}
+
+func TestParseMACOutput(t *testing.T) {
+	mac, err := parseMACOutput("MAC: a2:0b:c3:04:e5:f6\n")
+	if err != nil {
+		t.Fatalf("Error parsing MAC address: %s", err)
+	}
+	if mac != "a2:0b:c3:04:e5:f6" {
+		t.Errorf("Expected MAC address a2:0b:c3:04:e5:f6, got %s", mac)
+	}
+	if _, err := parseMACOutput("vmnet: could not start interface\n"); err == nil {
+		t.Errorf("Expected an error parsing output without a MAC address")
+	}
+}
@@ -0,0 +1,155 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+const domainTmpl = `<domain type='kvm'>
+  <name>{{xml .MachineName}}</name>
+  <memory unit='MB'>{{.Memory}}</memory>
+  <vcpu>{{.CPU}}</vcpu>
+  <features>
+    <acpi/>
+    <apic/>
+    <pae/>
+  </features>
+  <cpu mode='host-passthrough'/>
+  <os>
+    <type>hvm</type>
+    <boot dev='cdrom'/>
+    <boot dev='hd'/>
+    <bootmenu enable='no'/>
+  </os>
+  <devices>
+    <disk type='file' device='cdrom'>
+      <source file='{{xml .ISO}}'/>
+      <target dev='hdc' bus='scsi'/>
+      <readonly/>
+    </disk>
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='raw' cache='default' io='threads'/>
+      <source file='{{xml .DiskPath}}'/>
+      <target dev='hda' bus='virtio'/>
+    </disk>
+{{- range .ExtraDiskList}}
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='raw' cache='default' io='threads'/>
+      <source file='{{xml .Path}}'/>
+      <target dev='{{.Dev}}' bus='virtio'/>
+    </disk>
+{{- end}}
+{{- range .GPUs}}
+    <hostdev mode='subsystem' type='pci' managed='yes'>
+      <source>
+        <address domain='0x{{.Domain}}' bus='0x{{.Bus}}' slot='0x{{.Slot}}' function='0x{{.Function}}'/>
+      </source>
+    </hostdev>
+{{- end}}
+{{- if .MountHostPath}}
+    <filesystem type='mount' accessmode='passthrough'>
+      <source dir='{{xml .MountHostPath}}'/>
+      <target dir='{{.SharedFolderName}}'/>
+    </filesystem>
+{{- end}}
+    <interface type='network'>
+      <source network='{{xml .PrivateNetwork}}'/>
+      <model type='virtio'/>
+    </interface>
+    <interface type='network'>
+      <source network='{{xml .Network}}'/>
+      <model type='virtio'/>
+    </interface>
+    <serial type='pty'>
+      <target port='0'/>
+    </serial>
+    <console type='pty'>
+      <target type='serial' port='0'/>
+    </console>
+    <rng model='virtio'>
+      <backend model='random'>/dev/random</backend>
+    </rng>
+  </devices>
+</domain>
+`
+
+const networkTmpl = `<network>
+  <name>{{xml .PrivateNetwork}}</name>
+  <ip address='192.168.39.1' netmask='255.255.255.0'>
+    <dhcp>
+      <range start='192.168.39.2' end='192.168.39.254'/>
+    </dhcp>
+  </ip>
+</network>
+`
+
+var templateFuncs = template.FuncMap{
+	"xml": escapeXML,
+}
+
+func escapeXML(s string) (string, error) {
+	var b bytes.Buffer
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+type extraDisk struct {
+	Path string
+	Dev  string
+}
+
+// getDomainXML generates the libvirt domain definition of the driver's VM.
+// Its extra disks show up in the VM as /dev/vdb onwards.
+func getDomainXML(d *Driver) (string, error) {
+	data := struct {
+		*Driver
+		ExtraDiskList    []extraDisk
+		SharedFolderName string
+	}{Driver: d, SharedFolderName: constants.SharedFolderName}
+	for i := 0; i < d.ExtraDisks; i++ {
+		data.ExtraDiskList = append(data.ExtraDiskList, extraDisk{Path: d.extraDiskPath(i), Dev: fmt.Sprintf("vd%c", 'b'+i)})
+	}
+	return execTemplate("domain", domainTmpl, data)
+}
+
+// getNetworkXML generates the libvirt network definition of the driver's private network.
+func getNetworkXML(d *Driver) (string, error) {
+	return execTemplate("network", networkTmpl, d)
+}
+
+func execTemplate(name, text string, d interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error parsing %s template", name)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, d); err != nil {
+		return "", errors.Wrapf(err, "Error executing %s template", name)
+	}
+	return b.String(), nil
+}
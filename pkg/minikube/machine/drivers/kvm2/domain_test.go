@@ -0,0 +1,119 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func testDriver() *Driver {
+	d := NewDriver("minikube", "/home/minikube/.minikube")
+	d.Memory = 2048
+	d.CPU = 2
+	d.DiskSize = 20000
+	d.ISO = "/home/minikube/.minikube/machines/minikube/boot2docker.iso"
+	d.DiskPath = "/home/minikube/.minikube/machines/minikube/minikube.rawdisk"
+	return d
+}
+
+func TestGetXML(t *testing.T) {
+	escaped := testDriver()
+	escaped.MachineName = "mini&kube"
+	escaped.ISO = "/home/o'brien/.minikube/machines/mini&kube/boot2docker.iso"
+	escaped.DiskPath = "/home/o'brien/.minikube/machines/mini&kube/mini&kube.rawdisk"
+	escaped.PrivateNetwork = "<private>"
+
+	extraDisks := testDriver()
+	extraDisks.ExtraDisks = 2
+	extraDisks.ExtraDiskSize = 10000
+
+	sharedFolder := testDriver()
+	sharedFolder.MountHostPath = "/home/minikube/src"
+	sharedFolder.MountGuestPath = "/src"
+
+	var tests = []struct {
+		description string
+		driver      *Driver
+		generate    func(*Driver) (string, error)
+		fixture     string
+	}{
+		{
+			description: "domain",
+			driver:      testDriver(),
+			generate:    getDomainXML,
+			fixture:     "domain.xml",
+		},
+		{
+			description: "domain with escaped values",
+			driver:      escaped,
+			generate:    getDomainXML,
+			fixture:     "domain_escaped.xml",
+		},
+		{
+			description: "domain with extra disks",
+			driver:      extraDisks,
+			generate:    getDomainXML,
+			fixture:     "domain_extra_disks.xml",
+		},
+		{
+			description: "domain with a GPU",
+			driver:      gpuDriver(t, lspciOneGPU),
+			generate:    getDomainXML,
+			fixture:     "domain_gpu.xml",
+		},
+		{
+			description: "domain with several GPUs",
+			driver:      gpuDriver(t, lspciTwoGPUs),
+			generate:    getDomainXML,
+			fixture:     "domain_gpus.xml",
+		},
+		{
+			description: "domain with a shared folder",
+			driver:      sharedFolder,
+			generate:    getDomainXML,
+			fixture:     "domain_shared_folder.xml",
+		},
+		{
+			description: "network",
+			driver:      testDriver(),
+			generate:    getNetworkXML,
+			fixture:     "network.xml",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", test.fixture))
+			if err != nil {
+				t.Fatalf("Error reading fixture: %s", err)
+			}
+			actual, err := test.generate(test.driver)
+			if err != nil {
+				t.Fatalf("Error generating XML: %s", err)
+			}
+			if actual != string(expected) {
+				t.Errorf("Generated XML did not match %s.\nExpected:\n%s\nActual:\n%s", test.fixture, expected, actual)
+			}
+		})
+	}
+}
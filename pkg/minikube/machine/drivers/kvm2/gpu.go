@@ -0,0 +1,106 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const nvidiaVendorID = "10de"
+
+// PCIDevice is the address of a host PCI device passed through to the VM.
+type PCIDevice struct {
+	Domain   string
+	Bus      string
+	Slot     string
+	Function string
+}
+
+func (p PCIDevice) String() string {
+	return fmt.Sprintf("%s:%s:%s.%s", p.Domain, p.Bus, p.Slot, p.Function)
+}
+
+var pciAddressRegexp = regexp.MustCompile(`^([0-9a-f]{4}):([0-9a-f]{2}):([0-9a-f]{2})\.([0-7])$`)
+
+func parsePCIAddress(s string) (PCIDevice, error) {
+	m := pciAddressRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return PCIDevice{}, fmt.Errorf("Invalid PCI address %q", s)
+	}
+	return PCIDevice{Domain: m[1], Bus: m[2], Slot: m[3], Function: m[4]}, nil
+}
+
+// lspciRegexp matches a line of lspci -Dnn, capturing the address, class and vendor ID, e.g.
+// 0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GP104 [GeForce GTX 1080] [10de:1b80] (rev a1)
+var lspciRegexp = regexp.MustCompile(`^(\S+) .*\[([0-9a-f]{4})\]: .*\[([0-9a-f]{4}):[0-9a-f]{4}\]`)
+
+// parseNVIDIAGPUs finds the NVIDIA GPUs in the output of lspci -Dnn. Along with
+// each display controller it returns the other functions of the device, such as
+// its audio controller, which have to be passed through to the VM with it.
+func parseNVIDIAGPUs(out string) ([]PCIDevice, error) {
+	var functions []PCIDevice
+	gpuSlots := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		m := lspciRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || m[3] != nvidiaVendorID {
+			continue
+		}
+		dev, err := parsePCIAddress(m[1])
+		if err != nil {
+			return nil, err
+		}
+		// Display controllers are class 03xx.
+		if strings.HasPrefix(m[2], "03") {
+			gpuSlots[dev.slot()] = true
+		}
+		functions = append(functions, dev)
+	}
+	devices := []PCIDevice{}
+	for _, dev := range functions {
+		if gpuSlots[dev.slot()] {
+			devices = append(devices, dev)
+		}
+	}
+	return devices, nil
+}
+
+func (p PCIDevice) slot() string {
+	return fmt.Sprintf("%s:%s:%s", p.Domain, p.Bus, p.Slot)
+}
+
+// findNVIDIAGPUs lists the host's NVIDIA GPUs with lspci.
+func findNVIDIAGPUs() ([]PCIDevice, error) {
+	out, err := exec.Command("lspci", "-Dnn").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing PCI devices with lspci, is pciutils installed?")
+	}
+	devices, err := parseNVIDIAGPUs(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, errors.New("No NVIDIA GPU found to pass through to the VM")
+	}
+	return devices, nil
+}
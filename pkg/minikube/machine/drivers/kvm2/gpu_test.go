@@ -0,0 +1,92 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"reflect"
+	"testing"
+)
+
+const lspciOneGPU = `0000:00:00.0 Host bridge [0600]: Intel Corporation Xeon E3-1200 v6/7th Gen Core Processor Host Bridge/DRAM Registers [8086:591f] (rev 05)
+0000:00:02.0 VGA compatible controller [0300]: Intel Corporation HD Graphics 630 [8086:5912] (rev 04)
+0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GP104 [GeForce GTX 1080] [10de:1b80] (rev a1)
+0000:01:00.1 Audio device [0403]: NVIDIA Corporation GP104 High Definition Audio Controller [10de:10f0] (rev a1)
+0000:03:00.0 Ethernet controller [0200]: Intel Corporation I210 Gigabit Network Connection [8086:1533] (rev 03)
+`
+
+const lspciTwoGPUs = `0000:00:00.0 Host bridge [0600]: Intel Corporation Sky Lake-E DMI3 Registers [8086:2020] (rev 04)
+0000:3b:00.0 3D controller [0302]: NVIDIA Corporation GV100GL [Tesla V100 PCIe 16GB] [10de:1db4] (rev a1)
+0000:5e:00.0 3D controller [0302]: NVIDIA Corporation GV100GL [Tesla V100 PCIe 16GB] [10de:1db4] (rev a1)
+0000:af:00.0 USB controller [0c03]: NVIDIA Corporation Device [10de:1ad6] (rev a1)
+`
+
+// gpuDriver returns a test driver passing through the GPUs in the lspci listing.
+func gpuDriver(t *testing.T, lspci string) *Driver {
+	d := testDriver()
+	d.GPU = true
+	gpus, err := parseNVIDIAGPUs(lspci)
+	if err != nil {
+		t.Fatalf("Error parsing lspci output: %s", err)
+	}
+	d.GPUs = gpus
+	return d
+}
+
+func TestParseNVIDIAGPUs(t *testing.T) {
+	var tests = []struct {
+		description string
+		lspci       string
+		expected    []PCIDevice
+	}{
+		{
+			description: "one GPU with its audio controller",
+			lspci:       lspciOneGPU,
+			expected: []PCIDevice{
+				{Domain: "0000", Bus: "01", Slot: "00", Function: "0"},
+				{Domain: "0000", Bus: "01", Slot: "00", Function: "1"},
+			},
+		},
+		{
+			description: "two GPUs",
+			lspci:       lspciTwoGPUs,
+			expected: []PCIDevice{
+				{Domain: "0000", Bus: "3b", Slot: "00", Function: "0"},
+				{Domain: "0000", Bus: "5e", Slot: "00", Function: "0"},
+			},
+		},
+		{
+			description: "no GPU",
+			lspci:       "0000:00:00.0 Host bridge [0600]: Intel Corporation 440FX - 82441FX PMC [Natoma] [8086:1237] (rev 02)\n",
+			expected:    []PCIDevice{},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			actual, err := parseNVIDIAGPUs(test.lspci)
+			if err != nil {
+				t.Fatalf("Error parsing lspci output: %s", err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
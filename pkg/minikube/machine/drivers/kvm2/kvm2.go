@@ -0,0 +1,364 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+const (
+	driverName = "kvm2"
+
+	defaultConnectionURI  = "qemu:///system"
+	defaultNetwork        = "default"
+	defaultPrivateNetwork = "minikube-net"
+	isoFilename           = "boot2docker.iso"
+	dockerPort            = 2376
+)
+
+// Driver is a KVM driver built into minikube, which manages
+// its libvirt domain through virsh.
+type Driver struct {
+	*drivers.BaseDriver
+
+	// Memory is the amount of memory for the VM, in MB.
+	Memory int
+	// CPU is the number of virtual CPUs for the VM.
+	CPU int
+	// DiskSize is the size of the VM's disk, in MB.
+	DiskSize int
+	// Network is the libvirt network for the VM's outbound traffic.
+	Network string
+	// PrivateNetwork is the libvirt network the host reaches the VM on.
+	PrivateNetwork string
+	// Boot2DockerURL is where the ISO is copied from when the VM is created.
+	Boot2DockerURL string
+	// ISO is the path of the ISO the VM boots from.
+	ISO string
+	// DiskPath is the path of the VM's raw disk image.
+	DiskPath string
+	// ConnectionURI is the libvirt connection the domain is managed on.
+	ConnectionURI string
+	// ExtraDisks is the number of extra disks attached to the VM.
+	ExtraDisks int
+	// ExtraDiskSize is the size of each extra disk, in MB.
+	ExtraDiskSize int
+	// GPU is whether the host's NVIDIA GPUs are passed through to the VM.
+	GPU bool
+	// GPUs are the PCI devices passed through to the VM, found when it is created.
+	GPUs []PCIDevice
+	// MountHostPath is the host folder shared with the VM over virtfs, if any.
+	MountHostPath string
+	// MountGuestPath is where the shared folder is mounted in the VM.
+	MountGuestPath string
+}
+
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: hostName,
+			StorePath:   storePath,
+			SSHUser:     "docker",
+		},
+		Network:        defaultNetwork,
+		PrivateNetwork: defaultPrivateNetwork,
+		ConnectionURI:  defaultConnectionURI,
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return driverName
+}
+
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return []mcnflag.Flag{}
+}
+
+func (d *Driver) SetConfigFromFlags(opts drivers.DriverOptions) error {
+	return nil
+}
+
+// PreCreateCheck checks that virsh is installed and KVM is available
+func (d *Driver) PreCreateCheck() error {
+	if _, err := exec.LookPath("virsh"); err != nil {
+		return errors.New("virsh is a requirement in order to use the kvm2 driver, please install libvirt")
+	}
+	if _, err := os.Stat("/dev/kvm"); err != nil {
+		return errors.Wrap(err, "KVM is not available, check that virtualization is enabled")
+	}
+	if _, err := d.virsh("version"); err != nil {
+		return errors.Wrapf(err, "Error connecting to libvirt on %s", d.ConnectionURI)
+	}
+	return nil
+}
+
+func (d *Driver) Create() error {
+	log.Info("Copying ISO to machine directory...")
+	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+		return errors.Wrap(err, "Error copying ISO to machine directory")
+	}
+	if d.ISO == "" {
+		d.ISO = d.ResolveStorePath(isoFilename)
+	}
+	if d.DiskPath == "" {
+		d.DiskPath = d.ResolveStorePath(fmt.Sprintf("%s.rawdisk", d.MachineName))
+	}
+
+	if d.GPU {
+		devices, err := findNVIDIAGPUs()
+		if err != nil {
+			return err
+		}
+		log.Infof("Passing through PCI devices %v...", devices)
+		d.GPUs = devices
+	}
+
+	log.Info("Creating SSH key...")
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "Error generating SSH key")
+	}
+
+	log.Info("Creating raw disk image...")
+	if err := createRawDisk(d.DiskPath, d.DiskSize, d.publicSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "Error creating disk image")
+	}
+
+	for i := 0; i < d.ExtraDisks; i++ {
+		log.Infof("Creating extra disk %s...", d.extraDiskPath(i))
+		if err := createSparseDisk(d.extraDiskPath(i), d.ExtraDiskSize); err != nil {
+			return errors.Wrap(err, "Error creating extra disk")
+		}
+	}
+
+	if err := d.ensurePrivateNetwork(); err != nil {
+		return err
+	}
+
+	log.Info("Defining libvirt domain...")
+	domainXML, err := getDomainXML(d)
+	if err != nil {
+		return err
+	}
+	if err := d.virshDefine("define", domainXML); err != nil {
+		return errors.Wrap(err, "Error defining domain")
+	}
+
+	return d.Start()
+}
+
+func (d *Driver) extraDiskPath(i int) string {
+	return d.ResolveStorePath(fmt.Sprintf("extra-disk-%d.rawdisk", i+1))
+}
+
+func (d *Driver) publicSSHKeyPath() string {
+	return d.GetSSHKeyPath() + ".pub"
+}
+
+// ensurePrivateNetwork defines and starts the private network, if it isn't already.
+func (d *Driver) ensurePrivateNetwork() error {
+	if _, err := d.virsh("net-info", d.PrivateNetwork); err != nil {
+		log.Infof("Creating network %s...", d.PrivateNetwork)
+		networkXML, err := getNetworkXML(d)
+		if err != nil {
+			return err
+		}
+		if err := d.virshDefine("net-define", networkXML); err != nil {
+			return errors.Wrapf(err, "Error defining network %s", d.PrivateNetwork)
+		}
+		if _, err := d.virsh("net-autostart", d.PrivateNetwork); err != nil {
+			return errors.Wrapf(err, "Error setting network %s to autostart", d.PrivateNetwork)
+		}
+	}
+	info, err := d.virsh("net-info", d.PrivateNetwork)
+	if err != nil {
+		return errors.Wrapf(err, "Error getting network %s", d.PrivateNetwork)
+	}
+	if !parseNetworkActive(info) {
+		if _, err := d.virsh("net-start", d.PrivateNetwork); err != nil {
+			return errors.Wrapf(err, "Error starting network %s", d.PrivateNetwork)
+		}
+	}
+	return nil
+}
+
+// virshDefine runs a virsh define command with the given XML, which virsh only reads from a file.
+func (d *Driver) virshDefine(command, xml string) error {
+	f, err := ioutil.TempFile("", "minikube-kvm2")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(xml); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	_, err = d.virsh(command, f.Name())
+	return err
+}
+
+func (d *Driver) GetIP() (string, error) {
+	s, err := d.GetState()
+	if err != nil {
+		return "", err
+	}
+	if s != state.Running {
+		return "", drivers.ErrHostIsNotRunning
+	}
+	ifaces, err := d.virsh("domiflist", d.MachineName)
+	if err != nil {
+		return "", errors.Wrap(err, "Error listing domain interfaces")
+	}
+	mac, err := parseInterfaceMAC(ifaces, d.PrivateNetwork)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := d.virsh("domifaddr", d.MachineName, "--source", "lease")
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting domain addresses")
+	}
+	return parseLeaseIP(addrs, mac)
+}
+
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tcp://%s:%d", ip, dockerPort), nil
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	out, err := d.virsh("domstate", d.MachineName)
+	if err != nil {
+		return state.Error, err
+	}
+	return parseDomainState(out), nil
+}
+
+func (d *Driver) Start() error {
+	log.Info("Starting domain...")
+	if _, err := d.virsh("start", d.MachineName); err != nil {
+		return errors.Wrap(err, "Error starting domain")
+	}
+
+	log.Info("Waiting to get IP...")
+	return mcnutils.WaitForSpecificOrError(func() (bool, error) {
+		ip, err := d.GetIP()
+		if err != nil {
+			log.Debugf("Waiting for IP: %s", err)
+			return false, nil
+		}
+		d.IPAddress = ip
+		return true, nil
+	}, 90, 2*time.Second)
+}
+
+func (d *Driver) Stop() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s == state.Stopped {
+		return nil
+	}
+	if _, err := d.virsh("shutdown", d.MachineName); err != nil {
+		return errors.Wrap(err, "Error shutting down domain")
+	}
+	return mcnutils.WaitForSpecificOrError(func() (bool, error) {
+		s, err := d.GetState()
+		return s == state.Stopped, err
+	}, 60, time.Second)
+}
+
+func (d *Driver) Kill() error {
+	_, err := d.virsh("destroy", d.MachineName)
+	return err
+}
+
+// Resize sets the memory, in MB, and the number of CPUs of the shut off domain. A value of 0 is left as it is.
+func (d *Driver) Resize(memoryMB, cpus int) error {
+	for _, args := range resizeCommands(d.MachineName, memoryMB, cpus) {
+		if _, err := d.virsh(args...); err != nil {
+			return errors.Wrap(err, "Error resizing domain")
+		}
+	}
+	if memoryMB > 0 {
+		d.Memory = memoryMB
+	}
+	if cpus > 0 {
+		d.CPU = cpus
+	}
+	return nil
+}
+
+func (d *Driver) Restart() error {
+	if err := d.Stop(); err != nil {
+		return err
+	}
+	return d.Start()
+}
+
+// Remove removes the domain and its extra disks. Its other files are removed with the machine directory.
+// Failing to reach libvirt fails the removal, so that the domain isn't left behind.
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	switch {
+	case isDomainNotFound(err):
+		log.Debugf("Not removing domain, it is already gone: %s", err)
+	case err != nil:
+		return errors.Wrap(err, "Error getting domain state")
+	default:
+		if s == state.Running || s == state.Paused {
+			if err := d.Kill(); err != nil {
+				return errors.Wrap(err, "Error destroying domain")
+			}
+		}
+		if _, err := d.virsh("undefine", d.MachineName); err != nil {
+			return errors.Wrap(err, "Error undefining domain")
+		}
+	}
+
+	for i := 0; i < d.ExtraDisks; i++ {
+		if err := os.Remove(d.extraDiskPath(i)); err != nil && !os.IsNotExist(err) {
+			log.Warnf("Error removing extra disk: %s", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// virsh runs a virsh command against the driver's libvirt connection.
+// Errors include virsh's stderr, which is how libvirt reports them.
+func (d *Driver) virsh(args ...string) (string, error) {
+	args = append([]string{"--connect", d.ConnectionURI}, args...)
+	cmd := exec.Command("virsh", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	log.Debugf("virsh %s", strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("virsh %s: %s: %s", strings.Join(args[2:], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// isDomainNotFound returns whether err is virsh reporting that the domain doesn't exist,
+// rather than failing to reach libvirt.
+func isDomainNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Domain not found")
+}
+
+// parseDomainState converts the output of virsh domstate to a machine state.
+func parseDomainState(out string) state.State {
+	switch strings.TrimSpace(out) {
+	case "running":
+		return state.Running
+	case "paused", "pmsuspended":
+		return state.Paused
+	case "in shutdown":
+		return state.Stopping
+	case "shut off":
+		return state.Stopped
+	case "crashed":
+		return state.Error
+	}
+	return state.None
+}
+
+// parseNetworkActive returns whether the output of virsh net-info shows an active network.
+func parseNetworkActive(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "Active:" {
+			return fields[1] == "yes"
+		}
+	}
+	return false
+}
+
+// parseInterfaceMAC finds the MAC address of the interface on the given network in the output of virsh domiflist.
+func parseInterfaceMAC(out, network string) (string, error) {
+	// Interface  Type       Source     Model       MAC
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 5 && fields[1] == "network" && fields[2] == network {
+			return fields[4], nil
+		}
+	}
+	return "", fmt.Errorf("No interface on network %s", network)
+}
+
+// parseLeaseIP finds the IPv4 address leased to the given MAC address in the output of virsh domifaddr.
+func parseLeaseIP(out, mac string) (string, error) {
+	// Name       MAC address          Protocol     Address
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 || !strings.EqualFold(fields[1], mac) || fields[2] != "ipv4" {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(fields[3])
+		if err != nil {
+			return "", errors.Wrapf(err, "Error parsing address %s", fields[3])
+		}
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf("No IP address leased to %s yet", mac)
+}
+
+// resizeCommands returns the virsh commands setting the memory and CPUs in the domain's config.
+// The maximums are set first, as the current values can't exceed them.
+func resizeCommands(domain string, memoryMB, cpus int) [][]string {
+	var commands [][]string
+	if memoryMB > 0 {
+		kib := strconv.Itoa(memoryMB * 1024)
+		commands = append(commands,
+			[]string{"setmaxmem", domain, kib, "--config"},
+			[]string{"setmem", domain, kib, "--config"})
+	}
+	if cpus > 0 {
+		n := strconv.Itoa(cpus)
+		commands = append(commands,
+			[]string{"setvcpus", domain, n, "--config", "--maximum"},
+			[]string{"setvcpus", domain, n, "--config"})
+	}
+	return commands
+}
+
+// createRawDisk creates the raw disk image of the given size in MB. It starts
+// with the boot2docker tar containing the public SSH key, which tells the VM to
+// format the disk and install the key on first boot.
+func createRawDisk(path string, sizeMB int, publicSSHKeyPath string) error {
+	tarBuf, err := mcnutils.MakeDiskImage(publicSSHKeyPath)
+	if err != nil {
+		return errors.Wrap(err, "Error making disk image tar")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(tarBuf.Bytes()); err != nil {
+		return err
+	}
+	return f.Truncate(int64(sizeMB) << 20)
+}
+
+// createSparseDisk creates an empty raw disk image of the given size in MB,
+// which only takes up space on the host as the VM writes to it.
+func createSparseDisk(path string, sizeMB int) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Truncate(int64(sizeMB) << 20)
+}
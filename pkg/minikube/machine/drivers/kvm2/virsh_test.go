@@ -0,0 +1,126 @@
+// +build linux
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm2
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+const domiflist = `Interface  Type       Source     Model       MAC
+-------------------------------------------------------
+vnet0      network    minikube-net virtio      52:54:00:a8:a4:1e
+vnet1      network    default    virtio      52:54:00:0b:7f:52
+
+`
+
+const domifaddr = ` Name       MAC address          Protocol     Address
+-------------------------------------------------------------------------------
+ vnet0      52:54:00:a8:a4:1e    ipv4         192.168.39.114/24
+ vnet1      52:54:00:0b:7f:52    ipv4         192.168.122.38/24
+
+`
+
+func TestParseDomainState(t *testing.T) {
+	var tests = []struct {
+		out      string
+		expected state.State
+	}{
+		{"running\n\n", state.Running},
+		{"shut off\n\n", state.Stopped},
+		{"paused\n", state.Paused},
+		{"in shutdown\n", state.Stopping},
+		{"crashed\n", state.Error},
+		{"blocked\n", state.None},
+	}
+	for _, test := range tests {
+		if actual := parseDomainState(test.out); actual != test.expected {
+			t.Errorf("Expected state %s for %q, got: %s", test.expected, test.out, actual)
+		}
+	}
+}
+
+func TestIsDomainNotFound(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("virsh domstate minikube: exit status 1: error: failed to get domain 'minikube'\nerror: Domain not found: no domain with matching name 'minikube'"), true},
+		{errors.New("virsh domstate minikube: exit status 1: error: failed to connect to the hypervisor\nerror: Failed to connect socket to '/var/run/libvirt/libvirt-sock': Permission denied"), false},
+	}
+	for _, test := range tests {
+		if actual := isDomainNotFound(test.err); actual != test.expected {
+			t.Errorf("Expected %t for %v, got: %t", test.expected, test.err, actual)
+		}
+	}
+}
+
+func TestParseNetworkActive(t *testing.T) {
+	active := "Name:           minikube-net\nUUID:           6b5e7c1a\nActive:         yes\nPersistent:     yes\n"
+	if !parseNetworkActive(active) {
+		t.Errorf("Expected network to be active: %s", active)
+	}
+	inactive := "Name:           minikube-net\nActive:         no\n"
+	if parseNetworkActive(inactive) {
+		t.Errorf("Expected network to be inactive: %s", inactive)
+	}
+}
+
+func TestParseIP(t *testing.T) {
+	mac, err := parseInterfaceMAC(domiflist, "minikube-net")
+	if err != nil {
+		t.Fatalf("Error parsing interface MAC: %s", err)
+	}
+	if mac != "52:54:00:a8:a4:1e" {
+		t.Errorf("Expected MAC 52:54:00:a8:a4:1e, got: %s", mac)
+	}
+	ip, err := parseLeaseIP(domifaddr, mac)
+	if err != nil {
+		t.Fatalf("Error parsing IP: %s", err)
+	}
+	if ip != "192.168.39.114" {
+		t.Errorf("Expected IP 192.168.39.114, got: %s", ip)
+	}
+
+	if _, err := parseInterfaceMAC(domiflist, "other"); err == nil {
+		t.Error("Expected an error finding an interface on an unknown network")
+	}
+	if _, err := parseLeaseIP(domifaddr, "52:54:00:00:00:00"); err == nil {
+		t.Error("Expected an error finding the IP of an unknown MAC")
+	}
+}
+
+func TestResizeCommands(t *testing.T) {
+	expected := [][]string{
+		{"setmaxmem", "minikube", "4194304", "--config"},
+		{"setmem", "minikube", "4194304", "--config"},
+		{"setvcpus", "minikube", "4", "--config", "--maximum"},
+		{"setvcpus", "minikube", "4", "--config"},
+	}
+	if commands := resizeCommands("minikube", 4096, 4); !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected commands %q, got %q", expected, commands)
+	}
+	if commands := resizeCommands("minikube", 0, 2); len(commands) != 2 {
+		t.Errorf("Expected only the CPUs to be set, got %q", commands)
+	}
+}
@@ -20,14 +20,17 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
 	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
 )
 
@@ -71,8 +74,41 @@ func (d *Driver) DriverName() string {
 	return driverName
 }
 
+// GetIP returns the primary IP address of the host, which localkube serves on.
 func (d *Driver) GetIP() (string, error) {
-	return "127.0.0.1", nil
+	return primaryIP(), nil
+}
+
+// primaryIP returns the address of the interface with the default route,
+// falling back to the first non-loopback IPv4 address, then to localhost.
+func primaryIP() string {
+	// Dialing UDP sends no packets, it only picks the outbound interface.
+	if conn, err := net.Dial("udp", "8.8.8.8:80"); err == nil {
+		defer conn.Close()
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() {
+			return addr.IP.String()
+		}
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.Debugf("Error listing interface addresses: %s", err)
+		return "127.0.0.1"
+	}
+	return firstIPv4(addrs)
+}
+
+// firstIPv4 returns the first non-loopback IPv4 address of addrs, or localhost if there is none.
+func firstIPv4(addrs []net.Addr) string {
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() {
+			continue
+		}
+		if ip := ipnet.IP.To4(); ip != nil {
+			return ip.String()
+		}
+	}
+	return "127.0.0.1"
 }
 
 func (d *Driver) GetSSHHostname() (string, error) {
@@ -92,7 +128,7 @@ func (d *Driver) GetSSHUsername() string {
 }
 
 func (d *Driver) GetURL() (string, error) {
-	return "127.0.0.1:8080", nil
+	return primaryIP() + ":8080", nil
 }
 
 func (d *Driver) GetState() (state.State, error) {
@@ -117,25 +153,28 @@ func (d *Driver) GetState() (state.State, error) {
 }
 
 func (d *Driver) Kill() error {
-	cmd := exec.Command("sudo", "systemctl", "stop", "localkube.service")
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-	cmd = exec.Command("sudo", "rm", "-rf", "/var/lib/localkube")
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-	return nil
+	return exec.Command("sudo", "systemctl", "kill", "localkube.service").Run()
 }
 
+// Remove stops localkube and removes the systemd unit and data it installed on the host.
 func (d *Driver) Remove() error {
-	cmd := exec.Command("sudo", "systemctl", "stop", "localkube.service")
-	if err := cmd.Start(); err != nil {
-		return err
+	// Stopping and disabling fail when a failed start never installed the unit, which is fine.
+	for _, args := range [][]string{
+		{"systemctl", "stop", "localkube.service"},
+		{"systemctl", "disable", "localkube.service"},
+	} {
+		if out, err := exec.Command("sudo", args...).CombinedOutput(); err != nil {
+			log.Debugf("Error running %s: %s: %s", strings.Join(args, " "), err, out)
+		}
 	}
-	cmd = exec.Command("sudo", "rm", "-rf", "/var/lib/localkube")
-	if err := cmd.Start(); err != nil {
-		return err
+	if err := exec.Command("sudo", "rm", "-f", constants.LocalkubeServicePath).Run(); err != nil {
+		return fmt.Errorf("Error removing %s: %s", constants.LocalkubeServicePath, err)
+	}
+	if err := exec.Command("sudo", "systemctl", "daemon-reload").Run(); err != nil {
+		return fmt.Errorf("Error reloading systemd: %s", err)
+	}
+	if err := exec.Command("sudo", "rm", "-rf", "/var/lib/localkube").Run(); err != nil {
+		return fmt.Errorf("Error removing /var/lib/localkube: %s", err)
 	}
 	return nil
 }
@@ -153,8 +192,8 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 }
 
 func (d *Driver) Start() error {
-	d.IPAddress = "127.0.0.1"
-	d.URL = "127.0.0.1:8080"
+	d.IPAddress = primaryIP()
+	d.URL = d.IPAddress + ":8080"
 	return nil
 }
 
@@ -176,6 +215,20 @@ func (d *Driver) Stop() error {
 	return nil
 }
 
-func (d *Driver) RunSSHCommandFromDriver() error {
-	return fmt.Errorf("driver does not support ssh commands")
+// RunSSHCommandFromDriver runs the command on the host, as there is no VM to SSH into.
+func (d *Driver) RunSSHCommandFromDriver(command string) (string, error) {
+	return RunCommand(command, false)
+}
+
+// RunCommand runs the shell command locally, optionally as root.
+func RunCommand(command string, sudo bool) (string, error) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	if sudo {
+		cmd = exec.Command("sudo", "/bin/sh", "-c", command)
+	}
+	out, err := console.Run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("Error running %q: %s: %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
 }
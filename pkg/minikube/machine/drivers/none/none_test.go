@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package none
+
+import (
+	"net"
+	"testing"
+)
+
+func ipNet(cidr string) net.Addr {
+	ip, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	n.IP = ip
+	return n
+}
+
+func TestFirstIPv4(t *testing.T) {
+	var tests = []struct {
+		description string
+		addrs       []net.Addr
+		expected    string
+	}{
+		{
+			description: "no addresses",
+			expected:    "127.0.0.1",
+		},
+		{
+			description: "only loopback",
+			addrs:       []net.Addr{ipNet("127.0.0.1/8"), ipNet("::1/128")},
+			expected:    "127.0.0.1",
+		},
+		{
+			description: "skips loopback and ipv6",
+			addrs:       []net.Addr{ipNet("127.0.0.1/8"), ipNet("fe80::1/64"), ipNet("192.168.1.10/24"), ipNet("10.0.0.2/8")},
+			expected:    "192.168.1.10",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if ip := firstIPv4(test.addrs); ip != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, ip)
+			}
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	out, err := RunCommand("echo hello", false)
+	if err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	if out != "hello\n" {
+		t.Errorf("Expected output %q, got %q", "hello\n", out)
+	}
+	if _, err := RunCommand("exit 3", false); err == nil {
+		t.Errorf("Expected an error running a failing command")
+	}
+}
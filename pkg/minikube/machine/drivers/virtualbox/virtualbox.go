@@ -0,0 +1,147 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualbox
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/drivers/virtualbox"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// extraDiskPort is the SATA port of the first extra disk. The ISO and the
+// VM's disk are attached to the ports before it.
+const extraDiskPort = 2
+
+// Driver is the VirtualBox driver, which can also give the VM extra disks.
+type Driver struct {
+	*virtualbox.Driver
+
+	// ExtraDisks is the number of extra disks attached to the VM.
+	ExtraDisks int
+	// ExtraDiskSize is the size of each extra disk, in MB.
+	ExtraDiskSize int
+	// VBoxManage is the command the extra disks are created with.
+	VBoxManage string
+	// MountHostPath is the host folder shared with the VM, if any.
+	MountHostPath string
+	// MountGuestPath is where the shared folder is mounted in the VM.
+	MountGuestPath string
+}
+
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		Driver: virtualbox.NewDriver(hostName, storePath),
+	}
+}
+
+// Create creates the VM, attaching the extra disks and the shared folder before it is
+// started. The disks are deleted along with the VM, which unregisters it with its disks,
+// while the shared folder on the host is left alone.
+func (d *Driver) Create() error {
+	if err := d.CreateVM(); err != nil {
+		return err
+	}
+
+	for i := 0; i < d.ExtraDisks; i++ {
+		path := d.extraDiskPath(i)
+		log.Infof("Creating extra disk %s...", path)
+		if err := d.vbm(createDiskArgs(path, d.ExtraDiskSize)...); err != nil {
+			return errors.Wrap(err, "Error creating extra disk")
+		}
+		if err := d.vbm(attachDiskArgs(d.MachineName, i, path)...); err != nil {
+			return errors.Wrap(err, "Error attaching extra disk")
+		}
+	}
+
+	if d.MountHostPath != "" {
+		log.Infof("Sharing %s with the VM...", d.MountHostPath)
+		if err := d.vbm(sharedFolderArgs(d.MachineName, d.MountHostPath)...); err != nil {
+			return errors.Wrap(err, "Error adding shared folder")
+		}
+	}
+
+	log.Info("Starting the VM...")
+	return d.Start()
+}
+
+// Resize sets the memory, in MB, and the number of CPUs of the stopped VM. A value of 0 is left as it is.
+func (d *Driver) Resize(memoryMB, cpus int) error {
+	args := resizeArgs(d.MachineName, memoryMB, cpus)
+	if len(args) == 2 {
+		return nil
+	}
+	if err := d.vbm(args...); err != nil {
+		return errors.Wrap(err, "Error resizing VM")
+	}
+	if memoryMB > 0 {
+		d.Memory = memoryMB
+	}
+	if cpus > 0 {
+		d.CPU = cpus
+	}
+	return nil
+}
+
+// resizeArgs returns the VBoxManage arguments setting the memory and CPUs of the VM.
+func resizeArgs(machineName string, memoryMB, cpus int) []string {
+	args := []string{"modifyvm", machineName}
+	if memoryMB > 0 {
+		args = append(args, "--memory", strconv.Itoa(memoryMB))
+	}
+	if cpus > 0 {
+		args = append(args, "--cpus", strconv.Itoa(cpus))
+	}
+	return args
+}
+
+func (d *Driver) extraDiskPath(i int) string {
+	return d.ResolveStorePath(fmt.Sprintf("extra-disk-%d.vdi", i+1))
+}
+
+func (d *Driver) vbm(args ...string) error {
+	vboxManage := d.VBoxManage
+	if vboxManage == "" {
+		vboxManage = "VBoxManage"
+	}
+	log.Debugf("COMMAND: %s %s", vboxManage, strings.Join(args, " "))
+	out, err := exec.Command(vboxManage, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %s: %s", vboxManage, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// createDiskArgs returns the VBoxManage arguments creating a dynamically allocated disk of sizeMB.
+func createDiskArgs(path string, sizeMB int) []string {
+	return []string{"createmedium", "disk", "--filename", path, "--size", strconv.Itoa(sizeMB), "--format", "VDI", "--variant", "Standard"}
+}
+
+// attachDiskArgs returns the VBoxManage arguments attaching the i-th extra disk to the VM.
+func attachDiskArgs(machineName string, i int, path string) []string {
+	return []string{"storageattach", machineName, "--storagectl", "SATA", "--port", strconv.Itoa(extraDiskPort + i), "--device", "0", "--type", "hdd", "--medium", path}
+}
+
+// sharedFolderArgs returns the VBoxManage arguments sharing the host folder with the VM.
+func sharedFolderArgs(machineName, hostPath string) []string {
+	return []string{"sharedfolder", "add", machineName, "--name", constants.SharedFolderName, "--hostpath", hostPath}
+}
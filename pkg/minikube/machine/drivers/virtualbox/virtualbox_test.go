@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtraDiskArgs(t *testing.T) {
+	d := NewDriver("minikube", "/home/minikube/.minikube")
+	path := d.extraDiskPath(1)
+	if path != "/home/minikube/.minikube/machines/minikube/extra-disk-2.vdi" {
+		t.Errorf("Unexpected extra disk path: %s", path)
+	}
+
+	expected := []string{"createmedium", "disk", "--filename", path, "--size", "10000", "--format", "VDI", "--variant", "Standard"}
+	if args := createDiskArgs(path, 10000); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected create arguments %q, got %q", expected, args)
+	}
+
+	expected = []string{"storageattach", "minikube", "--storagectl", "SATA", "--port", "3", "--device", "0", "--type", "hdd", "--medium", path}
+	if args := attachDiskArgs("minikube", 1, path); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected attach arguments %q, got %q", expected, args)
+	}
+}
+
+func TestSharedFolderArgs(t *testing.T) {
+	expected := []string{"sharedfolder", "add", "minikube", "--name", "minikube-share", "--hostpath", `C:\Users\minikube`}
+	if args := sharedFolderArgs("minikube", `C:\Users\minikube`); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected shared folder arguments %q, got %q", expected, args)
+	}
+}
+
+func TestResizeArgs(t *testing.T) {
+	var tests = []struct {
+		description string
+		memory      int
+		cpus        int
+		expected    []string
+	}{
+		{
+			description: "memory and cpus",
+			memory:      4096,
+			cpus:        4,
+			expected:    []string{"modifyvm", "minikube", "--memory", "4096", "--cpus", "4"},
+		},
+		{
+			description: "memory only",
+			memory:      4096,
+			expected:    []string{"modifyvm", "minikube", "--memory", "4096"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if args := resizeArgs("minikube", test.memory, test.cpus); !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("Expected resize arguments %q, got %q", test.expected, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// driverConfigVersionKey is the key the driver config version is stored under, alongside the driver's own fields.
+// Configs written before versioning was introduced have no version, and are treated as version 0.
+const driverConfigVersionKey = "ConfigVersion"
+
+// driverMigration upgrades a decoded driver config by one version, in place.
+type driverMigration func(config map[string]interface{}) error
+
+// driverMigrations lists the migrations for each driver.
+// The migration at index i upgrades a config from version i to version i+1.
+var driverMigrations = map[string][]driverMigration{
+	"virtualbox": {
+		migrateVirtualboxNicTypes,
+	},
+}
+
+// driverConfigVersion returns the current config version for the named driver.
+func driverConfigVersion(driverName string) int {
+	return len(driverMigrations[driverName])
+}
+
+// migrateDriverConfig runs the migrations needed to bring a stored driver config up to the current version.
+func migrateDriverConfig(driverName string, rawDriver []byte) ([]byte, error) {
+	migrations := driverMigrations[driverName]
+	if len(migrations) == 0 {
+		return rawDriver, nil
+	}
+
+	config, err := decodeDriverConfig(rawDriver)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error decoding %s driver config", driverName)
+	}
+	version, err := configVersion(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading %s driver config version", driverName)
+	}
+	if version >= len(migrations) {
+		return rawDriver, nil
+	}
+
+	for i := version; i < len(migrations); i++ {
+		glog.Infof("Migrating %s driver config from version %d to %d", driverName, i, i+1)
+		if err := migrations[i](config); err != nil {
+			return nil, errors.Wrapf(err, "Error migrating %s driver config to version %d", driverName, i+1)
+		}
+	}
+	config[driverConfigVersionKey] = len(migrations)
+
+	return json.Marshal(config)
+}
+
+// decodeDriverConfig decodes a driver config, keeping numbers as they were written.
+func decodeDriverConfig(rawDriver []byte) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	d := json.NewDecoder(bytes.NewReader(rawDriver))
+	d.UseNumber()
+	if err := d.Decode(&config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func configVersion(config map[string]interface{}) (int, error) {
+	v, ok := config[driverConfigVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s is not a number: %v", driverConfigVersionKey, v)
+	}
+	version, err := n.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%s is not a valid version: %s", driverConfigVersionKey, n)
+	}
+	return int(version), nil
+}
+
+// defaultVirtualboxNicType is the NIC type docker-machine uses when none is configured.
+const defaultVirtualboxNicType = "82540EM"
+
+// obsoleteVirtualboxKeys are fields older VirtualBox driver configs carried that the driver no longer reads.
+var obsoleteVirtualboxKeys = []string{"CaCertPath", "PrivateKeyPath"}
+
+// migrateVirtualboxNicTypes fills in the NIC type and DNS proxy settings that configs
+// written before they were added lack, and drops fields the driver no longer uses.
+func migrateVirtualboxNicTypes(config map[string]interface{}) error {
+	for _, key := range []string{"NatNicType", "HostOnlyNicType"} {
+		if v, ok := config[key]; !ok || v == "" {
+			config[key] = defaultVirtualboxNicType
+		}
+	}
+	if _, ok := config["DNSProxy"]; !ok {
+		config["DNSProxy"] = true
+	}
+	for _, key := range obsoleteVirtualboxKeys {
+		delete(config, key)
+	}
+	return nil
+}
+
+// versionedDriver marshals a driver with its config version, so that the
+// migrations it has already been through are not run again.
+type versionedDriver struct {
+	drivers.Driver
+	version int
+}
+
+func (d versionedDriver) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(d.Driver)
+	if err != nil {
+		return nil, err
+	}
+	config, err := decodeDriverConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	config[driverConfigVersionKey] = d.version
+	return json.Marshal(config)
+}
+
+// Save stores the host, recording the version of its driver config. It is
+// serialized with other minikube processes by the machine's lock.
+func (api *LocalClient) Save(h *host.Host) error {
+	version := driverConfigVersion(h.DriverName)
+	if version == 0 || h.Driver == nil {
+		return saveHost(api.GetMachinesDir(), h)
+	}
+	d := h.Driver
+	h.Driver = versionedDriver{Driver: d, version: version}
+	defer func() { h.Driver = d }()
+	return saveHost(api.GetMachinesDir(), h)
+}
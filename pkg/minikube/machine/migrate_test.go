@@ -0,0 +1,219 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/machine/drivers/virtualbox"
+)
+
+// oldVboxConfig is a driver config as written before NatNicType and DNSProxy were added.
+const oldVboxConfig = `
+{
+        "IPAddress": "192.168.99.101",
+        "MachineName": "old",
+        "SSHUser": "docker",
+        "SSHPort": 33627,
+        "SSHKeyPath": "/home/sundarp/.minikube/machines/old/id_rsa",
+        "StorePath": "/home/sundarp/.minikube",
+        "CaCertPath": "/home/sundarp/.minikube/certs/ca.pem",
+        "PrivateKeyPath": "/home/sundarp/.minikube/certs/ca-key.pem",
+        "SwarmMaster": false,
+        "SwarmHost": "",
+        "SwarmDiscovery": "",
+        "VBoxManager": {},
+        "HostInterfaces": {},
+        "CPU": 2,
+        "Memory": 2048,
+        "DiskSize": 20000,
+        "Boot2DockerURL": "file:///home/sundarp/.minikube/cache/iso/minikube-v0.0.6.iso",
+        "Boot2DockerImportVM": "",
+        "HostDNSResolver": false,
+        "HostOnlyCIDR": "192.168.99.1/24",
+        "HostOnlyNicType": "",
+        "HostOnlyPromiscMode": "deny",
+        "UIType": "headless",
+        "HostOnlyNoDHCP": false,
+        "NoShare": false,
+        "NoVTXCheck": false
+}
+`
+
+func hostConfig(name, driverConfig string) string {
+	return fmt.Sprintf(`{"ConfigVersion": 3, "Name": %q, "DriverName": "virtualbox", "Driver": %s}`, name, driverConfig)
+}
+
+func TestMigrateDriverConfig(t *testing.T) {
+	var tests = []struct {
+		description string
+		rawDriver   string
+		migrated    bool
+		err         bool
+	}{
+		{
+			description: "unversioned config",
+			rawDriver:   oldVboxConfig,
+			migrated:    true,
+		},
+		{
+			description: "current config",
+			rawDriver:   `{"ConfigVersion": 1, "NatNicType": "virtio"}`,
+		},
+		{
+			description: "config from a newer version",
+			rawDriver:   `{"ConfigVersion": 7}`,
+		},
+		{
+			description: "bad version",
+			rawDriver:   `{"ConfigVersion": "one"}`,
+			err:         true,
+		},
+		{
+			description: "bad json",
+			rawDriver:   `{"ConfigVersion": `,
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			data, err := migrateDriverConfig("virtualbox", []byte(test.rawDriver))
+			if err != nil && !test.err {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Errorf("No error returned, but expected err")
+			}
+			if err != nil {
+				return
+			}
+			if migrated := string(data) != test.rawDriver; migrated != test.migrated {
+				t.Errorf("Expected config migrated to be %t, got: %s", test.migrated, data)
+			}
+		})
+	}
+}
+
+func TestLoadMigratesDriverConfig(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	writeMachineConfig(t, tempDir, "old", hostConfig("old", oldVboxConfig))
+	writeMachineConfig(t, tempDir, "current", hostConfig("current", vboxConfig))
+
+	f := clientFactories[ClientTypeLocal]
+	api, _ := f.NewClient(tempDir, tempDir)
+
+	var tests = []struct {
+		machine         string
+		memory          int
+		natNicType      string
+		hostOnlyNicType string
+		dnsProxy        bool
+	}{
+		{
+			machine:         "old",
+			memory:          2048,
+			natNicType:      defaultVirtualboxNicType,
+			hostOnlyNicType: defaultVirtualboxNicType,
+			dnsProxy:        true,
+		},
+		{
+			machine:         "current",
+			memory:          16384,
+			natNicType:      "82540EM",
+			hostOnlyNicType: "82540EM",
+			dnsProxy:        true,
+		},
+	}
+
+	for _, test := range tests {
+		h, err := api.Load(test.machine)
+		if err != nil {
+			t.Fatalf("Error loading host %s: %s", test.machine, err)
+		}
+		d, ok := h.Driver.(*virtualbox.Driver)
+		if !ok {
+			t.Fatalf("Expected a virtualbox driver, got: %T", h.Driver)
+		}
+		if d.Memory != test.memory {
+			t.Errorf("%s: expected memory %d, got: %d", test.machine, test.memory, d.Memory)
+		}
+		if d.NatNicType != test.natNicType {
+			t.Errorf("%s: expected NatNicType %s, got: %s", test.machine, test.natNicType, d.NatNicType)
+		}
+		if d.HostOnlyNicType != test.hostOnlyNicType {
+			t.Errorf("%s: expected HostOnlyNicType %s, got: %s", test.machine, test.hostOnlyNicType, d.HostOnlyNicType)
+		}
+		if d.DNSProxy != test.dnsProxy {
+			t.Errorf("%s: expected DNSProxy %t, got: %t", test.machine, test.dnsProxy, d.DNSProxy)
+		}
+	}
+}
+
+func TestSaveRecordsDriverConfigVersion(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	writeMachineConfig(t, tempDir, "old", hostConfig("old", oldVboxConfig))
+
+	f := clientFactories[ClientTypeLocal]
+	api, _ := f.NewClient(tempDir, tempDir)
+
+	h, err := api.Load("old")
+	if err != nil {
+		t.Fatalf("Error loading host: %s", err)
+	}
+	// Turn off DNSProxy, which the migration would turn back on if it ran again.
+	h.Driver.(*virtualbox.Driver).DNSProxy = false
+	if err := api.Save(h); err != nil {
+		t.Fatalf("Error saving host: %s", err)
+	}
+	if _, ok := h.Driver.(*virtualbox.Driver); !ok {
+		t.Fatalf("Save should not change the host driver, got: %T", h.Driver)
+	}
+
+	_, rawDriver, err := readDriverConfig(api.GetMachinesDir(), "old")
+	if err != nil {
+		t.Fatalf("Error reading saved config: %s", err)
+	}
+	config, err := decodeDriverConfig(rawDriver)
+	if err != nil {
+		t.Fatalf("Error decoding saved driver config: %s", err)
+	}
+	if v, err := configVersion(config); err != nil || v != driverConfigVersion("virtualbox") {
+		t.Errorf("Expected saved config version %d, got: %d (%v)", driverConfigVersion("virtualbox"), v, err)
+	}
+	for _, key := range obsoleteVirtualboxKeys {
+		if _, ok := config[key]; ok {
+			t.Errorf("Expected obsolete key %s to be dropped", key)
+		}
+	}
+
+	h, err = api.Load("old")
+	if err != nil {
+		t.Fatalf("Error reloading host: %s", err)
+	}
+	if h.Driver.(*virtualbox.Driver).DNSProxy {
+		t.Errorf("Expected DNSProxy to stay off once the config is migrated")
+	}
+}
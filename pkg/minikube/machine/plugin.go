@@ -0,0 +1,191 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/check"
+	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
+	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// heartbeatTimeout is how long a driver server waits for a heartbeat
+// from libmachine before giving up on its client.
+var heartbeatTimeout = 10 * time.Second
+
+const (
+	// PluginFDEnv is the environment variable holding the file descriptor, inherited from the
+	// client, a driver plugin announces its address on before its stdout.
+	PluginFDEnv = "MINIKUBE_PLUGIN_FD"
+	// PluginSocketEnv is the environment variable holding the path of the unix socket, which
+	// the client listens on, a driver plugin announces its address on before its stdout.
+	PluginSocketEnv = "MINIKUBE_PLUGIN_SOCKET"
+)
+
+// DriverServer serves a single docker-machine driver plugin over RPC.
+// Each server owns its own listener and RPC handlers, so several can run in one process.
+type DriverServer struct {
+	DriverName string
+	Addr       net.Addr
+	listener   net.Listener
+	rpcd       *rpcdriver.RPCServerDriver
+}
+
+// RegisterDriver starts serving the named driver on a local port, which
+// Announce tells the client. Call Serve on the returned server to wait for
+// the client to finish with it.
+func RegisterDriver(driverName string) (*DriverServer, error) {
+	newDriver, ok := driverMap[driverName]
+	if !ok {
+		return nil, ErrUnknownDriver{
+			DriverName: driverName,
+			Supported:  supportedDrivers(),
+		}
+	}
+
+	rpcd := rpcdriver.NewRPCServerDriver(newDriver())
+	server := rpc.NewServer()
+	if err := server.RegisterName(rpcdriver.RPCServiceNameV0, rpcd); err != nil {
+		return nil, errors.Wrap(err, "Error registering driver RPC service")
+	}
+	if err := server.RegisterName(rpcdriver.RPCServiceNameV1, rpcd); err != nil {
+		return nil, errors.Wrap(err, "Error registering driver RPC service")
+	}
+	if err := server.RegisterName(extensionServiceName, &driverExtensions{rpcd: rpcd}); err != nil {
+		return nil, errors.Wrap(err, "Error registering driver RPC service")
+	}
+	mux := http.NewServeMux()
+	mux.Handle(rpc.DefaultRPCPath, server)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading RPC server")
+	}
+
+	go http.Serve(listener, mux)
+
+	return &DriverServer{
+		DriverName: driverName,
+		Addr:       listener.Addr(),
+		listener:   listener,
+		rpcd:       rpcd,
+	}, nil
+}
+
+// Announce writes the address of the server on w as a line, the way docker-machine
+// expects a plugin to.
+func (s *DriverServer) Announce(w io.Writer) error {
+	_, err := fmt.Fprintln(w, s.Addr)
+	return errors.Wrap(err, "Error announcing driver address")
+}
+
+// Serve blocks until the client closes the driver or stops sending heartbeats.
+// The listener is closed when it returns.
+func (s *DriverServer) Serve() error {
+	defer s.listener.Close()
+	for {
+		select {
+		case <-s.rpcd.CloseCh:
+			glog.Infof("Closing %s driver plugin on server side", s.DriverName)
+			return nil
+		case <-s.rpcd.HeartbeatCh:
+			continue
+		case <-time.After(heartbeatTimeout):
+			return fmt.Errorf("No heartbeat received by %s driver plugin in %s", s.DriverName, heartbeatTimeout)
+		}
+	}
+}
+
+// StartDriver serves the named driver plugin and blocks until the client is
+// done with it. Its address is announced on the handshake channel the
+// environment gives, if any, where nothing else is written, and then on stdout
+// for docker-machine.
+func StartDriver(driverName string, stdout io.Writer) error {
+	s, err := RegisterDriver(driverName)
+	if err != nil {
+		return errors.Wrapf(err, "Error starting %s driver plugin", driverName)
+	}
+	if err := announceHandshake(s); err != nil {
+		s.listener.Close()
+		return errors.Wrapf(err, "Error starting %s driver plugin", driverName)
+	}
+	if err := s.Announce(stdout); err != nil {
+		s.listener.Close()
+		return errors.Wrapf(err, "Error starting %s driver plugin", driverName)
+	}
+	return s.Serve()
+}
+
+// announceHandshake announces the address of the server on the file descriptor
+// of PluginFDEnv, or the unix socket of PluginSocketEnv, and closes it. The
+// variables are unset, so that the commands the driver runs don't see them.
+func announceHandshake(s *DriverServer) error {
+	fd, path := os.Getenv(PluginFDEnv), os.Getenv(PluginSocketEnv)
+	os.Unsetenv(PluginFDEnv)
+	os.Unsetenv(PluginSocketEnv)
+	var w io.WriteCloser
+	switch {
+	case fd != "":
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			return errors.Wrapf(err, "Error parsing %s", PluginFDEnv)
+		}
+		w = os.NewFile(uintptr(n), "handshake")
+	case path != "":
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return errors.Wrap(err, "Error connecting to the handshake socket")
+		}
+		w = conn
+	default:
+		return nil
+	}
+	defer w.Close()
+	return s.Announce(w)
+}
+
+// StartDriverFromEnv is the command-line entrypoint for the driver plugins.
+// When docker-machine invokes this binary as a plugin it serves the driver named
+// in the environment on stdout and exits, otherwise it marks this binary as
+// docker-machine so that drivers are run in-process.
+func StartDriverFromEnv() {
+	cert.SetCertGenerator(&CertGenerator{})
+	check.DefaultConnChecker = &ConnChecker{}
+	if os.Getenv(localbinary.PluginEnvKey) == localbinary.PluginEnvVal {
+		log.SetDebug(true)
+		os.Setenv("MACHINE_DEBUG", "1")
+		if err := StartDriver(os.Getenv(localbinary.PluginEnvDriverName), os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	localbinary.CurrentBinaryIsDockerMachine = true
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bufio"
+	"fmt"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
+	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+// pluginStopTimeout is how long a driver plugin has to exit once it's closed before it is killed.
+var pluginStopTimeout = 5 * time.Second
+
+// pluginDriverFactory runs the drivers of this binary as plugins of this binary, which announce
+// their address on a pipe of their own, inherited as PluginFDEnv, rather than on stdout, where
+// whatever else they print would be taken for it. The other drivers, and all of them on
+// Windows, where processes can't inherit pipes this way, are run by libmachine's factory.
+type pluginDriverFactory struct {
+	// command returns the command of the binary serving the drivers.
+	command  func() (*exec.Cmd, error)
+	fallback rpcdriver.RPCClientDriverFactory
+
+	mu      sync.Mutex
+	plugins []*driverPlugin
+}
+
+// driverPlugin is a driver plugin process the factory launched.
+type driverPlugin struct {
+	cmd    *exec.Cmd
+	client *rpc.Client
+	done   chan struct{}
+	exited chan struct{}
+}
+
+func newPluginDriverFactory() *pluginDriverFactory {
+	return &pluginDriverFactory{
+		command: func() (*exec.Cmd, error) {
+			binary, err := os.Executable()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error finding the minikube binary")
+			}
+			return exec.Command(binary), nil
+		},
+		fallback: rpcdriver.NewRPCClientDriverFactory(),
+	}
+}
+
+func (f *pluginDriverFactory) NewRPCClientDriver(driverName string, rawDriver []byte) (*rpcdriver.RPCClientDriver, error) {
+	if _, ok := driverMap[driverName]; !ok || runtime.GOOS == "windows" {
+		return f.fallback.NewRPCClientDriver(driverName, rawDriver)
+	}
+	p, addr, err := f.launch(driverName)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.plugins = append(f.plugins, p)
+	f.mu.Unlock()
+
+	p.client, err = rpc.DialHTTP("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error connecting to the %s driver plugin at %s", driverName, addr)
+	}
+	c, err := connectPluginDriver(p.client, rawDriver, p.done)
+	return c, errors.Wrapf(err, "Error connecting to the %s driver plugin", driverName)
+}
+
+// launch starts the plugin of the named driver and returns it along with the address it announced.
+func (f *pluginDriverFactory) launch(driverName string) (*driverPlugin, string, error) {
+	cmd, err := f.command()
+	if err != nil {
+		return nil, "", err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error creating the handshake pipe")
+	}
+	defer r.Close()
+	// The pipe is the first of the extra files, which the plugin inherits as fd 3. What it
+	// prints goes to the log, as libmachine's own output does.
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", localbinary.PluginEnvKey, localbinary.PluginEnvVal),
+		fmt.Sprintf("%s=%s", localbinary.PluginEnvDriverName, driverName),
+		PluginFDEnv+"=3")
+	cmd.Stdout = console.LogWriter(console.LevelCommand)
+	cmd.Stderr = console.LogWriter(console.LevelCommand)
+	glog.Infof("Launching the %s driver plugin: %s", driverName, strings.Join(cmd.Args, " "))
+	err = cmd.Start()
+	// Only the plugin holds the pipe open now, so reading it ends when the plugin exits.
+	w.Close()
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "Error launching the %s driver plugin", driverName)
+	}
+	p := &driverPlugin{cmd: cmd, done: make(chan struct{}), exited: make(chan struct{})}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			glog.Infof("The %s driver plugin exited: %s", driverName, err)
+		}
+		close(p.exited)
+	}()
+
+	addr, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		p.stop()
+		return nil, "", errors.Wrapf(err, "Error reading the address of the %s driver plugin", driverName)
+	}
+	return p, strings.TrimSpace(addr), nil
+}
+
+// stop closes the plugin, and kills it if it hasn't exited after pluginStopTimeout. Calls in
+// flight fail rather than hang behind a wedged driver.
+func (p *driverPlugin) stop() {
+	close(p.done)
+	if p.client != nil {
+		p.client.Go(rpcdriver.RPCServiceNameV1+rpcdriver.CloseMethod, struct{}{}, nil, nil)
+	}
+	select {
+	case <-p.exited:
+	case <-time.After(pluginStopTimeout):
+		p.cmd.Process.Kill()
+		<-p.exited
+	}
+	if p.client != nil {
+		p.client.Close()
+	}
+}
+
+// Close stops the plugins the factory launched, and the ones libmachine did.
+func (f *pluginDriverFactory) Close() error {
+	f.mu.Lock()
+	plugins := f.plugins
+	f.plugins = nil
+	f.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, p := range plugins {
+		wg.Add(1)
+		go func(p *driverPlugin) {
+			defer wg.Done()
+			p.stop()
+		}(p)
+	}
+	wg.Wait()
+	return f.fallback.Close()
+}
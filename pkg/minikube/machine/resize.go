@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"net/rpc"
+	"strings"
+	"sync"
+
+	"github.com/docker/machine/libmachine/drivers"
+	rpcdriver "github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/pkg/errors"
+)
+
+// MutableDriver is a driver which can change the resources of its VM while it is stopped.
+type MutableDriver interface {
+	// Resize sets the memory, in MB, and the number of CPUs of the stopped VM.
+	// A value of 0 leaves that resource as it is.
+	Resize(memoryMB, cpus int) error
+}
+
+// extensionServiceName is the RPC service the driver plugins of this binary serve the
+// operations minikube adds to libmachine's drivers on, next to libmachine's own service.
+const extensionServiceName = "MinikubeDriver"
+
+// ResizeArgs are the arguments of the Resize call of the extension service.
+type ResizeArgs struct {
+	MemoryMB int
+	CPUs     int
+}
+
+// driverExtensions serves the extension service on the driver of a plugin.
+type driverExtensions struct {
+	rpcd *rpcdriver.RPCServerDriver
+}
+
+// CanResize replies whether the driver is a MutableDriver.
+func (e *driverExtensions) CanResize(_ struct{}, reply *bool) error {
+	_, *reply = e.rpcd.ActualDriver.(MutableDriver)
+	return nil
+}
+
+// Resize resizes the VM of the driver.
+func (e *driverExtensions) Resize(args ResizeArgs, _ *struct{}) error {
+	d, ok := e.rpcd.ActualDriver.(MutableDriver)
+	if !ok {
+		return errors.Errorf("The %s driver can't resize its VM", e.rpcd.ActualDriver.DriverName())
+	}
+	return d.Resize(args.MemoryMB, args.CPUs)
+}
+
+// pluginMutableDriver resizes the VM of a driver plugin through its extension service.
+type pluginMutableDriver struct {
+	client *rpc.Client
+}
+
+func (d pluginMutableDriver) Resize(memoryMB, cpus int) error {
+	return d.client.Call(extensionServiceName+".Resize", ResizeArgs{MemoryMB: memoryMB, CPUs: cpus}, &struct{}{})
+}
+
+// serialMutableDriver holds the lock of a SerialDriver while resizing, as its other calls do.
+type serialMutableDriver struct {
+	MutableDriver
+	sync.Locker
+}
+
+func (d serialMutableDriver) Resize(memoryMB, cpus int) error {
+	d.Lock()
+	defer d.Unlock()
+	return d.MutableDriver.Resize(memoryMB, cpus)
+}
+
+// AsMutableDriver returns what resizes the VM of d, if it can be resized: d itself, or for a
+// driver loaded through a plugin, as the RPC clients load them, the extension service of the
+// plugin. The plugins of other binaries don't serve it, and can't resize their VM.
+func AsMutableDriver(d drivers.Driver) (MutableDriver, bool, error) {
+	if s, ok := d.(*drivers.SerialDriver); ok {
+		m, ok, err := AsMutableDriver(s.Driver)
+		if !ok || err != nil {
+			return nil, ok, err
+		}
+		return serialMutableDriver{MutableDriver: m, Locker: s.Locker}, true, nil
+	}
+	if m, ok := d.(MutableDriver); ok {
+		return m, true, nil
+	}
+	c, ok := d.(*rpcdriver.RPCClientDriver)
+	if !ok || c.Client == nil {
+		return nil, false, nil
+	}
+	var can bool
+	if err := c.Client.RPCClient.Call(extensionServiceName+".CanResize", struct{}{}, &can); err != nil {
+		if strings.HasPrefix(err.Error(), "rpc: can't find service") {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "Error asking the driver plugin whether it can resize its VM")
+	}
+	if !can {
+		return nil, false, nil
+	}
+	return pluginMutableDriver{client: c.Client.RPCClient}, true, nil
+}
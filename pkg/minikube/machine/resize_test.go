@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+const resizableDriverName = "resizable"
+
+// resizableDriver is the driver the plugin helper serves to test resizing through a plugin.
+type resizableDriver struct {
+	tests.ResizableDriver
+}
+
+func newResizableDriver() drivers.Driver {
+	return &resizableDriver{}
+}
+
+func (d *resizableDriver) DriverName() string {
+	return resizableDriverName
+}
+
+// newPluginRPCClient returns an RPC client whose drivers are served by the plugin helper.
+func newPluginRPCClient(storePath string) *rpcClient {
+	f := newPluginDriverFactory()
+	f.command = func() (*exec.Cmd, error) { return driverPluginHelper(), nil }
+	certsDir := filepath.Join(storePath, "certs")
+	return &rpcClient{Client: libmachine.NewClient(storePath, certsDir), certsDir: certsDir, driverFactory: f}
+}
+
+func TestResizeThroughPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The driver plugins announce their address on stdout on Windows")
+	}
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+	driverMap[resizableDriverName] = newResizableDriver
+	defer delete(driverMap, resizableDriverName)
+
+	api := newPluginRPCClient(tempDir)
+	defer api.Close()
+	d := &resizableDriver{}
+	d.MachineName = "minikube"
+	d.CurrentState = state.Stopped
+	d.Memory, d.CPU = 2048, 2
+	rawDriver, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Error encoding the driver: %s", err)
+	}
+	h, err := api.NewHost(resizableDriverName, rawDriver)
+	if err != nil {
+		t.Fatalf("Error creating the host: %s", err)
+	}
+	if err := api.Save(h); err != nil {
+		t.Fatalf("Error saving the host: %s", err)
+	}
+
+	h, err = api.Load("minikube")
+	if err != nil {
+		t.Fatalf("Error loading the host: %s", err)
+	}
+	m, ok, err := AsMutableDriver(h.Driver)
+	if err != nil || !ok {
+		t.Fatalf("Expected the driver of the plugin to be resizable, got %t: %v", ok, err)
+	}
+	if err := m.Resize(4096, 4); err != nil {
+		t.Fatalf("Error resizing through the plugin: %s", err)
+	}
+	if err := api.Save(h); err != nil {
+		t.Fatalf("Error saving the resized host: %s", err)
+	}
+
+	h, err = api.Load("minikube")
+	if err != nil {
+		t.Fatalf("Error loading the resized host: %s", err)
+	}
+	var resized resizableDriver
+	if err := json.Unmarshal(h.RawDriver, &resized); err != nil {
+		t.Fatalf("Error decoding the resized driver: %s", err)
+	}
+	if resized.Memory != 4096 || resized.CPU != 4 {
+		t.Errorf("Expected the stored driver to have 4096 MB and 4 CPUs, got %d MB and %d CPUs", resized.Memory, resized.CPU)
+	}
+}
+
+func TestResizeThroughPluginUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The driver plugins announce their address on stdout on Windows")
+	}
+	if _, ok := driverMap["none"]; !ok {
+		t.Skip("The none driver is only built on Linux")
+	}
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	api := newPluginRPCClient(tempDir)
+	defer api.Close()
+	h, err := api.NewHost("none", []byte(`{"MachineName": "minikube"}`))
+	if err != nil {
+		t.Fatalf("Error creating the host: %s", err)
+	}
+	if _, ok, err := AsMutableDriver(h.Driver); err != nil || ok {
+		t.Errorf("Expected the none driver not to be resizable, got %t: %v", ok, err)
+	}
+}
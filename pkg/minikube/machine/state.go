@@ -0,0 +1,178 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// API is implemented by all of the clients returned by NewAPIClient.
+type API interface {
+	libmachine.API
+	// State returns the state of the named machine without loading the full host.
+	// If the machine does not exist, state.None is returned.
+	State(name string) (state.State, error)
+}
+
+// GetState returns the state of the named machine. The lightweight State call
+// is used when api supports it, otherwise the host is loaded.
+func GetState(api libmachine.API, name string) (state.State, error) {
+	if a, ok := api.(API); ok {
+		return a.State(name)
+	}
+	exists, err := api.Exists(name)
+	if err != nil {
+		return state.None, errors.Wrapf(err, "Error checking that machine exists: %s", name)
+	}
+	if !exists {
+		return state.None, nil
+	}
+	h, err := api.Load(name)
+	if err != nil {
+		return state.None, errors.Wrapf(err, "Error loading machine: %s", name)
+	}
+	return h.Driver.GetState()
+}
+
+// driverConfigFile is the subset of a stored host needed to build its driver.
+type driverConfigFile struct {
+	DriverName string
+	Driver     json.RawMessage
+}
+
+// readDriverConfig reads the driver of an existing machine from its config.json.
+// A missing or undecodable config is reported as ErrCorruptConfig, once reading
+// it again shows that it isn't just being written.
+func readDriverConfig(machinesDir, name string) (driverName string, rawDriver []byte, err error) {
+	err = retryCorruptConfig(func() error {
+		driverName, rawDriver, err = readDriverConfigFile(machinesDir, name)
+		return err
+	})
+	return driverName, rawDriver, err
+}
+
+func readDriverConfigFile(machinesDir, name string) (string, []byte, error) {
+	path := filepath.Join(machinesDir, name, "config.json")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil, ErrCorruptConfig{Name: name, Path: path, Err: err}
+		}
+		return "", nil, errors.Wrap(err, "Error reading machine config")
+	}
+	var c driverConfigFile
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", nil, ErrCorruptConfig{Name: name, Path: path, Err: err}
+	}
+	if c.DriverName == "" || len(c.Driver) == 0 {
+		return "", nil, ErrCorruptConfig{Name: name, Path: path, Err: errors.New("missing driver")}
+	}
+	return c.DriverName, c.Driver, nil
+}
+
+// checkMachineConfig returns ErrCorruptConfig if the named machine exists but its
+// config can't be decoded. If newDriver is set, the driver config is decoded as well.
+func checkMachineConfig(api libmachine.API, name string, newDriver func(string, []byte) (drivers.Driver, error)) error {
+	exists, err := api.Exists(name)
+	if err != nil || !exists {
+		return nil
+	}
+	driverName, rawDriver, err := readDriverConfig(api.GetMachinesDir(), name)
+	if err != nil {
+		return err
+	}
+	if newDriver == nil {
+		return nil
+	}
+	if _, err := newDriver(driverName, rawDriver); err != nil && isJSONError(err) {
+		return ErrCorruptConfig{
+			Name: name,
+			Path: filepath.Join(api.GetMachinesDir(), name, "config.json"),
+			Err:  errors.Cause(err),
+		}
+	}
+	return nil
+}
+
+func isJSONError(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return true
+	}
+	return false
+}
+
+// storeState gets the state of a machine in a local store, building only its driver.
+func storeState(api libmachine.API, name string, newDriver func(string, []byte) (drivers.Driver, error)) (state.State, error) {
+	exists, err := api.Exists(name)
+	if err != nil {
+		return state.None, errors.Wrapf(err, "Error checking that machine exists: %s", name)
+	}
+	if !exists {
+		return state.None, nil
+	}
+	driverName, rawDriver, err := readDriverConfig(api.GetMachinesDir(), name)
+	if err != nil {
+		return state.None, err
+	}
+	d, err := newDriver(driverName, rawDriver)
+	if err != nil {
+		return state.None, errors.Wrap(err, "Error loading driver")
+	}
+	return d.GetState()
+}
+
+func (api *LocalClient) State(name string) (state.State, error) {
+	return storeState(api, name, getDriver)
+}
+
+func (api *rpcClient) State(name string) (state.State, error) {
+	return storeState(api, name, func(driverName string, rawDriver []byte) (drivers.Driver, error) {
+		return api.driverFactory.NewRPCClientDriver(driverName, rawDriver)
+	})
+}
+
+func (api *RemoteClient) State(name string) (state.State, error) {
+	exists, err := api.Exists(name)
+	if err != nil {
+		return state.None, errors.Wrapf(err, "Error checking that machine exists: %s", name)
+	}
+	if !exists {
+		return state.None, nil
+	}
+	data, err := api.readHostConfig(name)
+	if err != nil {
+		return state.None, errors.Wrap(err, "Error reading machine config from remote store")
+	}
+	var c driverConfigFile
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return state.None, errors.Wrap(err, "Error unmarshalling machine config")
+	}
+	d, err := api.newPluginDriver(c.DriverName, c.Driver)
+	if err != nil {
+		return state.None, errors.Wrap(err, "Error loading driver")
+	}
+	return d.GetState()
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+func writeMachineConfig(t *testing.T, storePath, name, config string) {
+	dir := filepath.Join(storePath, "machines", name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Error creating machine dir: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatalf("Error writing machine config: %s", err)
+	}
+}
+
+func TestLocalClientState(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	writeMachineConfig(t, tempDir, "unknown", `{"DriverName": "unknown", "Driver": {}}`)
+	writeMachineConfig(t, tempDir, "corrupt", `{"DriverName": `)
+
+	f := clientFactories[ClientTypeLocal]
+	api, _ := f.NewClient(tempDir, tempDir)
+
+	var tests = []struct {
+		description string
+		machine     string
+		expected    state.State
+		err         bool
+	}{
+		{
+			description: "machine does not exist",
+			machine:     "minikube",
+			expected:    state.None,
+		},
+		{
+			description: "machine with unknown driver",
+			machine:     "unknown",
+			err:         true,
+		},
+		{
+			description: "machine with corrupt config",
+			machine:     "corrupt",
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			s, err := GetState(api, test.machine)
+			if err != nil && !test.err {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.err {
+				t.Errorf("No error returned, but expected err")
+			}
+			if err == nil && s != test.expected {
+				t.Errorf("State did not match, expected: %s, got: %s", test.expected, s)
+			}
+		})
+	}
+}
+
+func TestGetStateLoadsHost(t *testing.T) {
+	api := tests.NewMockAPI()
+
+	s, err := GetState(api, "minikube")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if s != state.None {
+		t.Errorf("Expected state %q for a missing machine, got: %q", state.None, s)
+	}
+
+	api.Hosts["minikube"] = &host.Host{
+		Name:   "minikube",
+		Driver: &tests.MockDriver{CurrentState: state.Stopped},
+	}
+	s, err = GetState(api, "minikube")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if s != state.Stopped {
+		t.Errorf("Expected state %s, got: %s", state.Stopped, s)
+	}
+}
+
+func TestRemoteClientStateQuotesStorePath(t *testing.T) {
+	s, err := tests.NewSSHServer()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	cat := "cat '/remote/minikube store/machines/minikube/config.json'"
+	s.SetCommandToOutput(map[string]string{
+		"if [ -d '/remote/minikube store' ]; then echo exists; fi":                   "exists\n",
+		"if [ -d '/remote/minikube store/machines/minikube' ]; then echo exists; fi": "exists\n",
+		cat: `{"DriverName": `,
+	})
+
+	c, err := NewRemoteClient(RemoteConfig{
+		Host:      net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		HostKey:   hostKeyFingerprint(s.HostKey),
+		StorePath: "/remote/minikube store",
+	}, "")
+	if err != nil {
+		t.Fatalf("Error creating remote client: %s", err)
+	}
+	defer c.Close()
+
+	if _, err := c.State("minikube"); err == nil {
+		t.Errorf("Expected an error for the corrupt config")
+	}
+	if _, ok := s.Commands[cat]; !ok {
+		t.Errorf("Expected the config to be read with %s, ran: %v", cat, s.Commands)
+	}
+}
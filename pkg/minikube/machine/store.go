@@ -0,0 +1,164 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// How often reading a machine's config is tried again when it looks corrupt, as another
+// minikube process, or an older one which doesn't replace it atomically, may be writing it.
+var (
+	storeReadRetries  = 3
+	storeReadInterval = 100 * time.Millisecond
+)
+
+// opsLocker is implemented by the clients whose store is on this host.
+type opsLocker interface {
+	lockMachineOps(name string) (func(), error)
+}
+
+func (api *LocalClient) lockMachineOps(name string) (func(), error) {
+	return lockMachineOps(api.GetMachinesDir(), name)
+}
+
+func (api *rpcClient) lockMachineOps(name string) (func(), error) {
+	return lockMachineOps(api.GetMachinesDir(), name)
+}
+
+// LockMachine takes the lock of the operations changing the named machine, such as
+// creating, starting, stopping or removing it, waiting for other processes holding it,
+// and returns the function releasing it. It is held for the whole operation, so that
+// the driver isn't driven by two processes at once. Clients whose store isn't on this
+// host don't lock their machines.
+func LockMachine(api libmachine.API, name string) (func(), error) {
+	l, ok := api.(opsLocker)
+	if !ok {
+		return func() {}, nil
+	}
+	return l.lockMachineOps(name)
+}
+
+// lockMachine takes the exclusive lock of the named machine's config, waiting for
+// other processes holding it, and returns the function releasing it.
+func lockMachine(machinesDir, name string) (func(), error) {
+	return lockMachineFile(machinesDir, "."+name+".lock")
+}
+
+// lockMachineOps takes the exclusive lock of the operations on the named machine. It
+// is another file than the lock of its config, which the operations save it under.
+func lockMachineOps(machinesDir, name string) (func(), error) {
+	return lockMachineFile(machinesDir, "."+name+".ops.lock")
+}
+
+// lockMachineFile takes the exclusive lock of the lock file lockName in machinesDir.
+// The lock files sit beside the machine directories, so that removing a directory
+// doesn't remove its locks.
+func lockMachineFile(machinesDir, lockName string) (func(), error) {
+	if err := os.MkdirAll(machinesDir, 0700); err != nil {
+		return nil, errors.Wrap(err, "Error creating machines directory")
+	}
+	// The leading dot keeps the store from listing the lock file as a machine.
+	path := filepath.Join(machinesDir, lockName)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error opening lock file %s", path)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "Error locking %s", path)
+	}
+	return func() {
+		if err := unlockFile(f); err != nil {
+			glog.Warningf("Error unlocking %s: %s", path, err)
+		}
+		f.Close()
+	}, nil
+}
+
+// saveHost writes the host's config.json while holding the machine's lock. It is
+// written to a temporary file which then replaces it, so readers never see it half written.
+func saveHost(machinesDir string, h *host.Host) error {
+	data, err := json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling host")
+	}
+
+	unlock, err := lockMachine(machinesDir, h.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dir := filepath.Join(machinesDir, h.Name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "Error creating machine directory")
+	}
+	tmp, err := ioutil.TempFile(dir, "config.json.tmp")
+	if err != nil {
+		return errors.Wrap(err, "Error creating temporary config file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "Error writing temporary config file")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "Error syncing temporary config file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "Error closing temporary config file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), filepath.Join(dir, "config.json")), "Error replacing config file")
+}
+
+// removeHost removes the named machine from the store while holding its lock.
+func removeHost(machinesDir, name string, remove func(string) error) error {
+	unlock, err := lockMachine(machinesDir, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return remove(name)
+}
+
+// retryCorruptConfig runs read again while it returns ErrCorruptConfig, which a
+// config being written at the same time looks like, up to storeReadRetries times.
+func retryCorruptConfig(read func() error) error {
+	var err error
+	for i := 0; i <= storeReadRetries; i++ {
+		if i > 0 {
+			glog.Infof("Reading the machine config again: %s", err)
+			time.Sleep(storeReadInterval)
+		}
+		err = read()
+		if _, ok := errors.Cause(err).(ErrCorruptConfig); !ok {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestConcurrentStore(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	const workers = 8
+	const iterations = 20
+	errs := make(chan error, workers*iterations)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each worker has its own client, like separate minikube processes.
+			api, err := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for i := 0; i < iterations; i++ {
+				config := strings.Replace(vboxConfig, `"Memory": 16384`, fmt.Sprintf(`"Memory": %d`, 1024+w*100+i), 1)
+				h, err := api.NewHost("virtualbox", []byte(config))
+				if err != nil {
+					errs <- errors.Wrap(err, "NewHost")
+					continue
+				}
+				if err := api.Save(h); err != nil {
+					errs <- errors.Wrap(err, "Save")
+					continue
+				}
+				if _, err := api.Load(h.Name); err != nil {
+					errs <- errors.Wrap(err, "Load")
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Unexpected error using the store concurrently: %v", err)
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(tempDir, "machines", "minikube"))
+	if err != nil {
+		t.Fatalf("Error reading machine directory: %s", err)
+	}
+	for _, f := range files {
+		if f.Name() != "config.json" {
+			t.Errorf("Unexpected file left in the machine directory: %s", f.Name())
+		}
+	}
+}
+
+func TestLoadWhileConfigIsWritten(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	writeMachineConfig(t, tempDir, "minikube", `{"ConfigVersion": 3, "Driver": {"MachineName": "mini`)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Finish writing the config while the first read is being retried.
+		time.Sleep(storeReadInterval / 2)
+		config := fmt.Sprintf(`{"ConfigVersion": 3, "Name": "minikube", "DriverName": "virtualbox", "Driver": %s}`, vboxConfig)
+		writeMachineConfig(t, tempDir, "minikube", config)
+	}()
+
+	api, _ := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+	if _, err := api.Load("minikube"); err != nil {
+		t.Errorf("Unexpected error loading a config once it is written: %v", err)
+	}
+	<-done
+}
+
+func TestRemoveLeavesOtherMachines(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	api, _ := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+	h, err := api.NewHost("virtualbox", []byte(vboxConfig))
+	if err != nil {
+		t.Fatalf("Error creating host: %s", err)
+	}
+	if err := api.Save(h); err != nil {
+		t.Fatalf("Error saving host: %s", err)
+	}
+	if err := api.Remove(h.Name); err != nil {
+		t.Fatalf("Error removing host: %s", err)
+	}
+	if exists, _ := api.Exists(h.Name); exists {
+		t.Error("Expected the host to be removed")
+	}
+	if names, _ := api.List(); len(names) != 0 {
+		t.Errorf("Expected the lock file not to be listed as a machine, got: %v", names)
+	}
+}
+
+func TestLockMachineBlocksOtherMutators(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	// Each client has its own lock files open, like separate minikube processes.
+	first, _ := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+	second, _ := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+	unlock, err := LockMachine(first, "minikube")
+	if err != nil {
+		t.Fatalf("Error locking machine: %s", err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		unlock, err := LockMachine(second, "minikube")
+		if err == nil {
+			unlock()
+		}
+		locked <- err
+	}()
+	select {
+	case err := <-locked:
+		t.Fatalf("Expected the second lock to wait for the first one, got: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The config isn't locked by the operation.
+	h, err := first.NewHost("virtualbox", []byte(vboxConfig))
+	if err != nil {
+		t.Fatalf("Error creating host: %s", err)
+	}
+	if err := first.Save(h); err != nil {
+		t.Fatalf("Error saving host while holding the machine's lock: %s", err)
+	}
+
+	unlock()
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatalf("Error taking the released lock: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the second lock to be taken once the first is released")
+	}
+}
+
+func TestLockMachineOtherMachines(t *testing.T) {
+	tempDir := makeTempDir()
+	defer os.RemoveAll(tempDir)
+
+	api, _ := clientFactories[ClientTypeLocal].NewClient(tempDir, tempDir)
+	unlock, err := LockMachine(api, "minikube")
+	if err != nil {
+		t.Fatalf("Error locking machine: %s", err)
+	}
+	defer unlock()
+	other, err := LockMachine(api, "other")
+	if err != nil {
+		t.Fatalf("Error locking another machine: %s", err)
+	}
+	other()
+	if hosts, _ := api.List(); len(hosts) != 0 {
+		t.Errorf("Expected the lock files not to be listed as machines, got: %v", hosts)
+	}
+}
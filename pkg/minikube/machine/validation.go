@@ -0,0 +1,106 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// InvalidField describes a single driver config field that failed validation.
+type InvalidField struct {
+	Name   string
+	Reason string
+}
+
+// ErrInvalidDriverConfig is returned when a driver config is missing
+// required fields or contains values that are out of range.
+type ErrInvalidDriverConfig struct {
+	DriverName string
+	Fields     []InvalidField
+}
+
+func (e ErrInvalidDriverConfig) Error() string {
+	reasons := []string{}
+	for _, f := range e.Fields {
+		reasons = append(reasons, fmt.Sprintf("%s %s", f.Name, f.Reason))
+	}
+	return fmt.Sprintf("Invalid %s driver config: %s", e.DriverName, strings.Join(reasons, ", "))
+}
+
+// ErrCorruptConfig is returned when the stored config of an existing
+// machine is missing or can't be decoded.
+type ErrCorruptConfig struct {
+	Name string
+	Path string
+	Err  error
+}
+
+func (e ErrCorruptConfig) Error() string {
+	return fmt.Sprintf(`The config for machine %q at %s is corrupt: %v
+Run "minikube start --force-recreate" to delete the machine and create it again.`, e.Name, e.Path, e.Err)
+}
+
+// driverConfig holds the fields common to the supported drivers that are
+// validated before a host is created. Fields a driver doesn't have are left nil.
+type driverConfig struct {
+	MachineName *string
+	StorePath   *string
+	Memory      *int
+	MemSize     *int
+	CPU         *int
+}
+
+// validateDriverConfig checks the decoded driver config for missing or out of range fields.
+func validateDriverConfig(driverName string, rawDriver []byte) error {
+	var c driverConfig
+	if err := json.Unmarshal(rawDriver, &c); err != nil {
+		return errors.Wrapf(err, "Error unmarshalling %s driver config", driverName)
+	}
+
+	var fields []InvalidField
+	if c.MachineName == nil || *c.MachineName == "" {
+		fields = append(fields, InvalidField{"MachineName", "must not be empty"})
+	}
+	if c.StorePath == nil || *c.StorePath == "" {
+		fields = append(fields, InvalidField{"StorePath", "must not be empty"})
+	}
+	for _, m := range []struct {
+		name   string
+		memory *int
+	}{{"Memory", c.Memory}, {"MemSize", c.MemSize}} {
+		if m.memory != nil && *m.memory < constants.MinimumMemoryMB {
+			fields = append(fields, InvalidField{m.name, fmt.Sprintf("must be at least %dMB, got %dMB", constants.MinimumMemoryMB, *m.memory)})
+		}
+	}
+	// Some drivers, such as virtualbox, use -1 to mean all available CPUs.
+	if c.CPU != nil && (*c.CPU == 0 || *c.CPU < -1) {
+		fields = append(fields, InvalidField{"CPU", fmt.Sprintf("must be > 0, got %d", *c.CPU)})
+	}
+
+	if len(fields) > 0 {
+		return ErrInvalidDriverConfig{
+			DriverName: driverName,
+			Fields:     fields,
+		}
+	}
+	return nil
+}
@@ -30,44 +30,59 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/version"
 )
 
 const updateLinkPrefix = "https://github.com/kubernetes/minikube/releases/tag/v"
 
+// updateCheckTimeout bounds the update check, so that it never holds a command up for longer,
+// as when the network is down.
+const updateCheckTimeout = 2 * time.Second
+
 var (
 	timeLayout              = time.RFC1123
 	lastUpdateCheckFilePath = constants.MakeMiniPath("last_update_check")
+	httpClient              = &http.Client{Timeout: updateCheckTimeout}
+	// now is the clock the reminder period is measured with.
+	now = time.Now
 )
 
+// MaybePrintUpdateTextFromGithub checks the releases at the releases-url setting, or the
+// minikube releases by default.
 func MaybePrintUpdateTextFromGithub(output io.Writer) {
-	MaybePrintUpdateText(output, constants.GithubMinikubeReleasesURL, lastUpdateCheckFilePath)
+	url := viper.GetString(config.ReleasesURL)
+	if url == "" {
+		url = constants.GithubMinikubeReleasesURL
+	}
+	MaybePrintUpdateText(output, url, lastUpdateCheckFilePath)
 }
 
+// MaybePrintUpdateText prints a line to output if the releases at url have a newer version than
+// this one, at most once a reminder period. The prereleases only count with WantBetaUpdateNotification.
+// The check never fails the command, its errors are only logged.
 func MaybePrintUpdateText(output io.Writer, url string, lastUpdatePath string) {
 	if !shouldCheckURLVersion(lastUpdatePath) {
 		return
 	}
-	latestVersion, err := getLatestVersionFromURL(url)
+	// The check counts whatever its outcome, so that an unreachable URL isn't retried on every command.
+	if err := writeTimeToFile(lastUpdatePath, now().UTC()); err != nil {
+		console.Phase("Not recording the update check: %s", err)
+	}
+	latestVersion, err := getLatestVersionFromURL(url, viper.GetBool(config.WantBetaUpdateNotification))
 	if err != nil {
-		glog.Errorln(err)
+		console.Phase("Error checking for updates: %s", err)
 		return
 	}
 	localVersion, err := version.GetSemverVersion()
 	if err != nil {
-		glog.Errorln(err)
+		console.Phase("Error checking for updates: %s", err)
 		return
 	}
 	if localVersion.Compare(latestVersion) < 0 {
-		writeTimeToFile(lastUpdateCheckFilePath, time.Now().UTC())
-		fmt.Fprintf(output, `There is a newer version of minikube available (%s%s).  Download it here:
-%s%s
-
-To disable this notification, run the following:
-minikube config set WantUpdateNotification false
-`,
-			version.VersionPrefix, latestVersion, updateLinkPrefix, latestVersion)
+		fmt.Fprintf(output, "minikube %s%s is available (this is %s%s), download it from %s%s. To disable this notice: minikube config set WantUpdateNotification false\n",
+			version.VersionPrefix, latestVersion, version.VersionPrefix, localVersion, updateLinkPrefix, latestVersion)
 	}
 }
 
@@ -76,7 +91,7 @@ func shouldCheckURLVersion(filePath string) bool {
 		return false
 	}
 	lastUpdateTime := getTimeFromFileIfExists(filePath)
-	if time.Since(lastUpdateTime).Hours() < viper.GetFloat64(config.ReminderWaitPeriodInHours) {
+	if now().Sub(lastUpdateTime).Hours() < viper.GetFloat64(config.ReminderWaitPeriodInHours) {
 		return false
 	}
 	return true
@@ -90,7 +105,7 @@ type Release struct {
 type Releases []Release
 
 func getJson(url string, target *Releases) error {
-	r, err := http.Get(url)
+	r, err := httpClient.Get(url)
 	if err != nil {
 		return errors.Wrap(err, "Error getting minikube version url via http")
 	}
@@ -99,12 +114,31 @@ func getJson(url string, target *Releases) error {
 	return json.NewDecoder(r.Body).Decode(target)
 }
 
-func getLatestVersionFromURL(url string) (semver.Version, error) {
+// getLatestVersionFromURL returns the newest version of the releases at url, leaving the
+// prereleases out unless beta is set.
+func getLatestVersionFromURL(url string, beta bool) (semver.Version, error) {
 	r, err := GetAllVersionsFromURL(url)
 	if err != nil {
 		return semver.Version{}, err
 	}
-	return semver.Make(strings.TrimPrefix(r[0].Name, version.VersionPrefix))
+	var latest *semver.Version
+	for _, release := range r {
+		v, err := semver.Make(strings.TrimPrefix(release.Name, version.VersionPrefix))
+		if err != nil {
+			console.Phase("Skipping release %q: %s", release.Name, err)
+			continue
+		}
+		if len(v.Pre) > 0 && !beta {
+			continue
+		}
+		if latest == nil || v.GT(*latest) {
+			latest = &v
+		}
+	}
+	if latest == nil {
+		return semver.Version{}, errors.Errorf("There were no releases with a valid version at the url specified: %s", url)
+	}
+	return *latest, nil
 }
 
 func GetAllVersionsFromURL(url string) (Releases, error) {
@@ -24,6 +24,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -89,7 +90,7 @@ func TestGetLatestVersionFromURLCorrect(t *testing.T) {
 	}
 	server := httptest.NewServer(handler)
 
-	latestVersion, err := getLatestVersionFromURL(server.URL)
+	latestVersion, err := getLatestVersionFromURL(server.URL, true)
 	if err != nil {
 		t.Fatalf(err.Error())
 	}
@@ -109,7 +110,7 @@ func TestGetLatestVersionFromURLNone(t *testing.T) {
 	handler := &URLHandlerNone{}
 	server := httptest.NewServer(handler)
 
-	_, err := getLatestVersionFromURL(server.URL)
+	_, err := getLatestVersionFromURL(server.URL, false)
 	if err == nil {
 		t.Fatalf("No version value was returned from URL but no error was thrown")
 	}
@@ -127,7 +128,7 @@ func TestGetLatestVersionFromURLMalformed(t *testing.T) {
 	handler := &URLHandlerMalformed{}
 	server := httptest.NewServer(handler)
 
-	_, err := getLatestVersionFromURL(server.URL)
+	_, err := getLatestVersionFromURL(server.URL, false)
 	if err == nil {
 		t.Fatalf("Malformed version value was returned from URL but no error was thrown")
 	}
@@ -139,6 +140,9 @@ func TestMaybePrintUpdateText(t *testing.T) {
 
 	viper.Set(config.WantUpdateNotification, true)
 	viper.Set(config.ReminderWaitPeriodInHours, 24)
+	// The version of the tests is a prerelease.
+	viper.Set(config.WantBetaUpdateNotification, true)
+	defer viper.Set(config.WantBetaUpdateNotification, false)
 
 	var outputBuffer bytes.Buffer
 	lastUpdateCheckFilePath := filepath.Join(tempDir, "last_update_check")
@@ -158,7 +162,8 @@ func TestMaybePrintUpdateText(t *testing.T) {
 	}
 
 	// test that update text is printed if the latest version is greater than the current version
-	latestVersionFromURL = "100.0.0-dev"
+	os.Remove(lastUpdateCheckFilePath)
+	latestVersionFromURL = "100.0.0"
 	handler = &URLHandlerCorrect{
 		releases: []Release{{Name: version.VersionPrefix + latestVersionFromURL}},
 	}
@@ -170,4 +175,151 @@ func TestMaybePrintUpdateText(t *testing.T) {
 		t.Fatalf("Expected MaybePrintUpdateText to output text as the current version is %s and version %s was served from URL but output was [%s]",
 			version.GetVersion(), latestVersionFromURL, outputBuffer.String())
 	}
+	if lines := strings.Count(outputBuffer.String(), "\n"); lines != 1 {
+		t.Errorf("Expected a one line notice, got %d lines: %s", lines, outputBuffer.String())
+	}
+	if !strings.Contains(outputBuffer.String(), updateLinkPrefix+latestVersionFromURL) {
+		t.Errorf("Expected the notice to link to %s%s: %s", updateLinkPrefix, latestVersionFromURL, outputBuffer.String())
+	}
+}
+
+// countingHandler serves releases, counting the requests.
+type countingHandler struct {
+	URLHandlerCorrect
+	requests int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests++
+	h.URLHandlerCorrect.ServeHTTP(w, r)
+}
+
+func TestReminderPeriod(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+	lastUpdatePath := filepath.Join(tempDir, "last_update_check")
+
+	viper.Set(config.WantUpdateNotification, true)
+	viper.Set(config.ReminderWaitPeriodInHours, 24)
+	defer func(n func() time.Time) { now = n }(now)
+	clock := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+
+	handler := &countingHandler{URLHandlerCorrect: URLHandlerCorrect{releases: Releases{{Name: "v100.0.0"}}}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var tests = []struct {
+		description string
+		after       time.Duration
+		checked     bool
+	}{
+		{description: "first check", checked: true},
+		{description: "an hour later", after: time.Hour},
+		{description: "23 hours later", after: 22 * time.Hour},
+		{description: "a day later", after: time.Hour, checked: true},
+		{description: "a day and an hour later", after: time.Hour},
+		{description: "two days later", after: 23 * time.Hour, checked: true},
+	}
+
+	for _, test := range tests {
+		clock = clock.Add(test.after)
+		requests := handler.requests
+		var out bytes.Buffer
+		MaybePrintUpdateText(&out, server.URL, lastUpdatePath)
+		if checked := handler.requests > requests; checked != test.checked {
+			t.Errorf("%s: expected checked %t, got %t", test.description, test.checked, checked)
+		}
+		if printed := out.Len() > 0; printed != test.checked {
+			t.Errorf("%s: expected a notice %t, got %q", test.description, test.checked, out.String())
+		}
+	}
+}
+
+func TestLatestVersionChannels(t *testing.T) {
+	handler := &URLHandlerCorrect{
+		releases: Releases{{Name: "v0.21.0-beta.0"}, {Name: "v0.20.0"}, {Name: "not a version"}, {Name: "v0.19.1"}},
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var tests = []struct {
+		beta     bool
+		expected string
+	}{
+		{beta: false, expected: "0.20.0"},
+		{beta: true, expected: "0.21.0-beta.0"},
+	}
+
+	for _, test := range tests {
+		latest, err := getLatestVersionFromURL(server.URL, test.beta)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if latest.String() != test.expected {
+			t.Errorf("Expected the latest version with beta %t to be %s, got %s", test.beta, test.expected, latest)
+		}
+	}
+
+	handler.releases = Releases{{Name: "v0.21.0-beta.0"}}
+	if latest, err := getLatestVersionFromURL(server.URL, false); err == nil {
+		t.Errorf("Expected an error with only prereleases, got %s", latest)
+	}
+}
+
+// TestUpdateCheckOffline checks that an unreachable releases URL is swallowed, and only retried
+// after the reminder period.
+func TestUpdateCheckOffline(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+	viper.Set(config.WantUpdateNotification, true)
+	viper.Set(config.ReminderWaitPeriodInHours, 24)
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	hanging := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hanging
+	}))
+	defer server.Close()
+	defer close(hanging)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, url := range []string{server.URL, closed.URL} {
+		lastUpdatePath := filepath.Join(tempDir, "last_update_check")
+		os.Remove(lastUpdatePath)
+		var out bytes.Buffer
+		start := time.Now()
+		MaybePrintUpdateText(&out, url, lastUpdatePath)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected the check of %s to give up after its timeout, it took %s", url, elapsed)
+		}
+		if out.Len() != 0 {
+			t.Errorf("Expected no output checking %s, got %q", url, out.String())
+		}
+		if shouldCheckURLVersion(lastUpdatePath) {
+			t.Errorf("Expected the failed check of %s to wait for the reminder period", url)
+		}
+	}
+}
+
+func TestReleasesURL(t *testing.T) {
+	tempDir := tests.MakeTempDir()
+	defer os.RemoveAll(tempDir)
+	viper.Set(config.WantUpdateNotification, true)
+	defer func(path string) { lastUpdateCheckFilePath = path }(lastUpdateCheckFilePath)
+	lastUpdateCheckFilePath = filepath.Join(tempDir, "last_update_check")
+
+	handler := &countingHandler{URLHandlerCorrect: URLHandlerCorrect{releases: Releases{{Name: "v100.0.0"}}}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	viper.Set(config.ReleasesURL, server.URL)
+	defer viper.Set(config.ReleasesURL, "")
+
+	var out bytes.Buffer
+	MaybePrintUpdateTextFromGithub(&out)
+	if handler.requests != 1 || out.Len() == 0 {
+		t.Errorf("Expected the releases to be checked at releases-url, got %d requests and output %q", handler.requests, out.String())
+	}
 }
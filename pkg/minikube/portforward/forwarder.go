@@ -0,0 +1,200 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/util"
+)
+
+// podConnection forwards connections to the ports of a pod.
+type podConnection interface {
+	// forward copies the data of conn to and from the port of the pod, until either side closes.
+	forward(port int, conn net.Conn) error
+	Close() error
+}
+
+// dialer connects to the pods forwarded to.
+type dialer interface {
+	dial(pod *v1.Pod) (podConnection, error)
+}
+
+// Forwarder forwards local ports to a ready pod of its target. The local ports are listened
+// on for as long as it runs: when the pod terminates, the connections which follow are forwarded
+// to another ready pod of the target, once there is one.
+type Forwarder struct {
+	target    Target
+	pairs     []PortPair
+	addresses []string
+	resolver  *Resolver
+	dialer    dialer
+	out       io.Writer
+	// interval is how often the pod is checked, and a ready pod looked for.
+	interval time.Duration
+
+	mu      sync.Mutex
+	current *forwardedPod
+	// waiting is whether the forwarder reported it waits for a ready pod.
+	waiting bool
+}
+
+// forwardedPod is the pod connections are forwarded to.
+type forwardedPod struct {
+	*resolved
+	conn podConnection
+}
+
+const checkInterval = time.Second
+
+func newForwarder(target Target, pairs []PortPair, addresses []string, resolver *Resolver, d dialer, out io.Writer) *Forwarder {
+	return &Forwarder{target: target, pairs: pairs, addresses: addresses, resolver: resolver, dialer: d, out: out, interval: checkInterval}
+}
+
+// Run listens on the local ports and forwards their connections until stop is closed.
+func (f *Forwarder) Run(stop <-chan struct{}) error {
+	// Resolving the target first reports a wrong one at once.
+	if _, err := f.pod(stop); err != nil {
+		return err
+	}
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	for _, pair := range f.pairs {
+		for _, address := range f.addresses {
+			l, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(pair.Local)))
+			if err != nil {
+				return errors.Wrapf(err, "Error listening on port %d", pair.Local)
+			}
+			listeners = append(listeners, l)
+			fmt.Fprintf(f.out, "Forwarding from %s -> %d\n", l.Addr(), pair.Remote)
+			go f.accept(l, pair, stop)
+		}
+	}
+	f.monitor(stop)
+	f.mu.Lock()
+	f.dropLocked()
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Forwarder) accept(l net.Listener, pair PortPair, stop <-chan struct{}) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			// The listener is closed once the forwarder stops.
+			glog.Infof("Stopped accepting connections on %s: %s", l.Addr(), err)
+			return
+		}
+		go f.handle(conn, pair, stop)
+	}
+}
+
+func (f *Forwarder) handle(conn net.Conn, pair PortPair, stop <-chan struct{}) {
+	defer conn.Close()
+	p, err := f.pod(stop)
+	if err != nil {
+		glog.Errorf("Error forwarding a connection to port %d: %s", pair.Remote, err)
+		return
+	}
+	port := p.ports[pair.Remote]
+	if err := p.conn.forward(port, conn); err != nil {
+		glog.Errorf("Error forwarding a connection to port %d of pod %s: %s", port, p.pod.Name, err)
+		// The pod may be terminating, the next connection checks it.
+		f.drop(p)
+	}
+}
+
+// pod returns the pod connections are forwarded to, waiting for a ready pod of the target if
+// there is none, until stop is closed.
+func (f *Forwarder) pod(stop <-chan struct{}) (*forwardedPod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.current == nil {
+		r, err := f.resolver.resolve(f.target, f.pairs)
+		if err == nil {
+			var conn podConnection
+			conn, err = f.dialer.dial(r.pod)
+			if err == nil {
+				f.current = &forwardedPod{resolved: r, conn: conn}
+				f.waiting = false
+				fmt.Fprintf(f.out, "Forwarding to pod %s\n", r.pod.Name)
+				break
+			}
+			err = &util.RetriableError{Err: errors.Wrapf(err, "Error connecting to pod %s", r.pod.Name)}
+		}
+		if _, ok := err.(*util.RetriableError); !ok {
+			return nil, err
+		}
+		if !f.waiting {
+			fmt.Fprintf(f.out, "Waiting for a ready pod of %s: %s\n", f.target, err)
+			f.waiting = true
+		}
+		select {
+		case <-stop:
+			return nil, errors.New("The forwarder stopped")
+		case <-time.After(f.interval):
+		}
+	}
+	return f.current, nil
+}
+
+// monitor drops the pod forwarded to once it is no longer ready, until stop is closed, so that
+// the connections which follow go to another pod.
+func (f *Forwarder) monitor(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(f.interval):
+		}
+		f.mu.Lock()
+		p := f.current
+		f.mu.Unlock()
+		if p != nil && !f.resolver.stillReady(p.pod) {
+			fmt.Fprintf(f.out, "Pod %s terminated\n", p.pod.Name)
+			f.drop(p)
+		}
+	}
+}
+
+// drop stops forwarding to p, if it still is the pod forwarded to.
+func (f *Forwarder) drop(p *forwardedPod) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.current == p {
+		f.dropLocked()
+	}
+}
+
+func (f *Forwarder) dropLocked() {
+	if f.current != nil {
+		f.current.conn.Close()
+		f.current = nil
+	}
+}
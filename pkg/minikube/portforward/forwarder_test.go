@@ -0,0 +1,242 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// echoPods are TCP servers standing in for pods, which reply to a line with the name of the
+// pod and the port connected to.
+type echoPods struct {
+	mu        sync.Mutex
+	listeners map[string]net.Listener
+	// failures is how many dials fail before connecting.
+	failures int
+}
+
+func (e *echoPods) start(t *testing.T, name string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	e.mu.Lock()
+	e.listeners[name] = l
+	e.mu.Unlock()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				port, _ := r.ReadString('\n')
+				line, _ := r.ReadString('\n')
+				fmt.Fprintf(conn, "%s:%s:%s", name, strings.TrimSpace(port), line)
+			}()
+		}
+	}()
+}
+
+func (e *echoPods) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, l := range e.listeners {
+		l.Close()
+	}
+}
+
+func (e *echoPods) dial(pod *v1.Pod) (podConnection, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failures > 0 {
+		e.failures--
+		return nil, errors.New("connection refused")
+	}
+	l, ok := e.listeners[pod.Name]
+	if !ok {
+		return nil, errors.Errorf("No server for pod %s", pod.Name)
+	}
+	return &echoConnection{addr: l.Addr().String()}, nil
+}
+
+type echoConnection struct {
+	addr string
+}
+
+func (c *echoConnection) forward(port int, conn net.Conn) error {
+	target, err := net.Dial("tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	fmt.Fprintf(target, "%d\n", port)
+	go io.Copy(target, conn)
+	_, err = io.Copy(conn, target)
+	return err
+}
+
+func (c *echoConnection) Close() error {
+	return nil
+}
+
+// syncBuffer is written to by the forwarder while tests read it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// request sends a line to the local port, returning the reply or an empty string if the
+// connection fails.
+func request(port int) string {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	fmt.Fprintln(conn, "hello")
+	reply, _ := bufio.NewReader(conn).ReadString('\n')
+	return reply
+}
+
+// waitForReply requests the local port until it replies with expected.
+func waitForReply(t *testing.T, port int, expected string) {
+	var reply string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if reply = request(port); reply == expected {
+			return
+		}
+	}
+	t.Fatalf("Expected %q from port %d, got %q", expected, port, reply)
+}
+
+func runForwarder(t *testing.T, pods *mockPods, servers *echoPods, out io.Writer, pairs []PortPair) func() {
+	target := Target{Kind: Service, Name: "web", Namespace: "default"}
+	f := newForwarder(target, pairs, []string{"127.0.0.1"}, newResolver(pods), servers, out)
+	f.interval = 10 * time.Millisecond
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- f.Run(stop)
+	}()
+	return func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("Unexpected error forwarding: %s", err)
+		}
+	}
+}
+
+func TestForwarderPodRestart(t *testing.T) {
+	servers := &echoPods{listeners: map[string]net.Listener{}}
+	defer servers.close()
+	servers.start(t, "web-1")
+	servers.start(t, "web-2")
+	pods := &mockPods{}
+	pods.set(newPod("web-1", true))
+
+	out := &syncBuffer{}
+	http, other := freePort(t), freePort(t)
+	stop := runForwarder(t, pods, servers, out, []PortPair{{Local: http, Remote: 80}, {Local: other, Remote: 9090}})
+	defer stop()
+
+	waitForReply(t, http, "web-1:8080:hello\n")
+	waitForReply(t, other, "web-1:9091:hello\n")
+
+	// The pod is replaced, by one which isn't ready at first.
+	pods.set(newPod("web-2", false))
+	for deadline := time.Now().Add(5 * time.Second); !strings.Contains(out.String(), "Pod web-1 terminated"); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the pod to be reported terminated: %s", out.String())
+		}
+	}
+	pods.set(newPod("web-2", true))
+	waitForReply(t, http, "web-2:8080:hello\n")
+	waitForReply(t, other, "web-2:9091:hello\n")
+
+	for _, line := range []string{"Forwarding to pod web-1", "Forwarding to pod web-2", fmt.Sprintf("Forwarding from 127.0.0.1:%d -> 80", http)} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected %q in the output: %s", line, out.String())
+		}
+	}
+}
+
+func TestForwarderReconnect(t *testing.T) {
+	servers := &echoPods{listeners: map[string]net.Listener{}, failures: 2}
+	defer servers.close()
+	servers.start(t, "web-1")
+	pods := &mockPods{}
+	pods.set(newPod("web-1", true))
+
+	out := &syncBuffer{}
+	port := freePort(t)
+	stop := runForwarder(t, pods, servers, out, []PortPair{{Local: port, Remote: 80}})
+	defer stop()
+
+	waitForReply(t, port, "web-1:8080:hello\n")
+	if !strings.Contains(out.String(), "Waiting for a ready pod of service/web") {
+		t.Errorf("Expected the forwarder to report waiting for the pod: %s", out.String())
+	}
+
+	// The connection to the pod breaks, the next connection dials the pod again.
+	servers.mu.Lock()
+	l := servers.listeners["web-1"]
+	delete(servers.listeners, "web-1")
+	servers.mu.Unlock()
+	l.Close()
+	if reply := request(port); reply != "" {
+		t.Errorf("Expected no reply from a closed pod, got %q", reply)
+	}
+	servers.start(t, "web-1")
+	waitForReply(t, port, "web-1:8080:hello\n")
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/minikube/pkg/util"
+)
+
+// Resolver finds the ready pods of targets through the apiserver.
+type Resolver struct {
+	Pods        corev1.PodsGetter
+	Services    corev1.ServicesGetter
+	Deployments extensionsv1beta1.DeploymentsGetter
+}
+
+// resolved is a ready pod of a target, along with its ports forwarded to, by remote port.
+type resolved struct {
+	pod   *v1.Pod
+	ports map[int]int
+}
+
+// resolve returns a ready pod of the target and the ports of the pod the remote ports of pairs map
+// to. Not finding a ready pod is a retriable error, the pods of a target being replaced.
+func (r *Resolver) resolve(t Target, pairs []PortPair) (*resolved, error) {
+	var svc *v1.Service
+	var pod *v1.Pod
+	switch t.Kind {
+	case Pod:
+		p, err := r.Pods.Pods(t.Namespace).Get(t.Name, meta_v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, &util.RetriableError{Err: errors.Errorf("Pod %s doesn't exist", t.Name)}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error getting pod %s", t.Name)
+		}
+		if !podReady(p) {
+			return nil, &util.RetriableError{Err: errors.Errorf("Pod %s is not ready", t.Name)}
+		}
+		pod = p
+	case Service, Deployment:
+		selector, s, err := r.selector(t)
+		if err != nil {
+			return nil, err
+		}
+		svc = s
+		list, err := r.Pods.Pods(t.Namespace).List(meta_v1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error listing the pods of %s", t)
+		}
+		for i := range list.Items {
+			if podReady(&list.Items[i]) {
+				pod = &list.Items[i]
+				break
+			}
+		}
+		if pod == nil {
+			return nil, &util.RetriableError{Err: errors.Errorf("%s has no ready pod", t)}
+		}
+	default:
+		return nil, errors.Errorf("Unsupported kind %s", t.Kind)
+	}
+
+	ports := map[int]int{}
+	for _, pair := range pairs {
+		port := pair.Remote
+		if svc != nil {
+			var err error
+			if port, err = servicePodPort(svc, pod, pair.Remote); err != nil {
+				return nil, err
+			}
+		}
+		ports[pair.Remote] = port
+	}
+	return &resolved{pod: pod, ports: ports}, nil
+}
+
+// selector returns the label selector of the pods of a service or deployment, and the service.
+func (r *Resolver) selector(t Target) (labels.Selector, *v1.Service, error) {
+	if t.Kind == Service {
+		svc, err := r.Services.Services(t.Namespace).Get(t.Name, meta_v1.GetOptions{})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Error getting %s", t)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, nil, errors.Errorf("%s has no selector, so no pods to forward to", t)
+		}
+		return labels.SelectorFromSet(labels.Set(svc.Spec.Selector)), svc, nil
+	}
+	d, err := r.Deployments.Deployments(t.Namespace).Get(t.Name, meta_v1.GetOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Error getting %s", t)
+	}
+	if d.Spec.Selector == nil {
+		return labels.SelectorFromSet(labels.Set(d.Spec.Template.Labels)), nil, nil
+	}
+	selector, err := meta_v1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Error parsing the selector of %s", t)
+	}
+	return selector, nil, nil
+}
+
+// stillReady returns whether the pod exists and is ready, without being deleted.
+func (r *Resolver) stillReady(pod *v1.Pod) bool {
+	p, err := r.Pods.Pods(pod.Namespace).Get(pod.Name, meta_v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	if err != nil {
+		// The pod can't be checked, it is left to forwarding to fail.
+		return true
+	}
+	return p.UID == pod.UID && podReady(p)
+}
+
+func podReady(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// servicePodPort returns the port of the pod the port of the service forwards to, looking its
+// name up in the pod's containers when the target port is named.
+func servicePodPort(svc *v1.Service, pod *v1.Pod, port int) (int, error) {
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) != port {
+			continue
+		}
+		switch {
+		case p.TargetPort.Type == intstr.String:
+			for _, c := range pod.Spec.Containers {
+				for _, cp := range c.Ports {
+					if cp.Name == p.TargetPort.StrVal {
+						return int(cp.ContainerPort), nil
+					}
+				}
+			}
+			return 0, errors.Errorf("Pod %s has no port named %s, the target port of port %d of service %s", pod.Name, p.TargetPort.StrVal, port, svc.Name)
+		case p.TargetPort.IntValue() == 0:
+			return port, nil
+		default:
+			return p.TargetPort.IntValue(), nil
+		}
+	}
+	return 0, errors.Errorf("Service %s has no port %d", svc.Name, port)
+}
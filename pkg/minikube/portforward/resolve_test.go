@@ -0,0 +1,302 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"sync"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	extensionsv1beta1 "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/minikube/pkg/util"
+)
+
+// mockPods holds the pods of the default namespace, in order, which tests replace as they run.
+type mockPods struct {
+	mu   sync.Mutex
+	pods []v1.Pod
+}
+
+func (m *mockPods) set(pods ...v1.Pod) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pods = pods
+}
+
+func (m *mockPods) Pods(namespace string) corev1.PodInterface {
+	return &mockPodInterface{mock: m}
+}
+
+type mockPodInterface struct {
+	fake.FakePods
+	mock *mockPods
+}
+
+func (p *mockPodInterface) Get(name string, _ meta_v1.GetOptions) (*v1.Pod, error) {
+	p.mock.mu.Lock()
+	defer p.mock.mu.Unlock()
+	for _, pod := range p.mock.pods {
+		if pod.Name == name {
+			return &pod, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+}
+
+func (p *mockPodInterface) List(opts meta_v1.ListOptions) (*v1.PodList, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	p.mock.mu.Lock()
+	defer p.mock.mu.Unlock()
+	list := &v1.PodList{}
+	for _, pod := range p.mock.pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			list.Items = append(list.Items, pod)
+		}
+	}
+	return list, nil
+}
+
+type mockServices map[string]*v1.Service
+
+func (m mockServices) Services(namespace string) corev1.ServiceInterface {
+	return &mockServiceInterface{services: m}
+}
+
+type mockServiceInterface struct {
+	fake.FakeServices
+	services mockServices
+}
+
+func (s *mockServiceInterface) Get(name string, _ meta_v1.GetOptions) (*v1.Service, error) {
+	svc, ok := s.services[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, name)
+	}
+	return svc, nil
+}
+
+type mockDeployments map[string]*v1beta1.Deployment
+
+func (m mockDeployments) Deployments(namespace string) extensionsv1beta1.DeploymentInterface {
+	return &mockDeploymentInterface{deployments: m}
+}
+
+type mockDeploymentInterface struct {
+	extensionsv1beta1.DeploymentInterface
+	deployments mockDeployments
+}
+
+func (d *mockDeploymentInterface) Get(name string, _ meta_v1.GetOptions) (*v1beta1.Deployment, error) {
+	deployment, ok := d.deployments[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, name)
+	}
+	return deployment, nil
+}
+
+// newPod returns a running pod labeled app=web, whose container names its port 8080 http.
+func newPod(name string, ready bool) v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), Labels: map[string]string{"app": "web"}},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+		}},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func newResolver(pods *mockPods) *Resolver {
+	return &Resolver{
+		Pods: pods,
+		Services: mockServices{
+			"web": &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: v1.ServiceSpec{
+					Selector: map[string]string{"app": "web"},
+					Ports: []v1.ServicePort{
+						{Port: 80, TargetPort: intstr.FromString("http")},
+						{Port: 9090, TargetPort: intstr.FromInt(9091)},
+						{Port: 7070},
+					},
+				},
+			},
+			"external": &v1.Service{ObjectMeta: meta_v1.ObjectMeta{Name: "external", Namespace: "default"}},
+		},
+		Deployments: mockDeployments{
+			"web": &v1beta1.Deployment{
+				Spec: v1beta1.DeploymentSpec{Selector: &meta_v1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	terminating := newPod("web-0", true)
+	terminating.DeletionTimestamp = &meta_v1.Time{}
+
+	var tests = []struct {
+		description string
+		target      Target
+		pairs       []PortPair
+		pods        []v1.Pod
+		pod         string
+		ports       map[int]int
+		retriable   bool
+		shouldErr   bool
+	}{
+		{
+			description: "service ports",
+			target:      Target{Kind: Service, Name: "web", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}, {Local: 9000, Remote: 9090}, {Local: 7000, Remote: 7070}},
+			pods:        []v1.Pod{newPod("web-1", true)},
+			pod:         "web-1",
+			ports:       map[int]int{80: 8080, 9090: 9091, 7070: 7070},
+		},
+		{
+			description: "unready and terminating pods skipped",
+			target:      Target{Kind: Service, Name: "web", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			pods:        []v1.Pod{terminating, newPod("web-1", false), newPod("web-2", true)},
+			pod:         "web-2",
+			ports:       map[int]int{80: 8080},
+		},
+		{
+			description: "deployment",
+			target:      Target{Kind: Deployment, Name: "web", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 8080}},
+			pods:        []v1.Pod{newPod("web-1", true)},
+			pod:         "web-1",
+			ports:       map[int]int{8080: 8080},
+		},
+		{
+			description: "pod",
+			target:      Target{Kind: Pod, Name: "web-1", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			pods:        []v1.Pod{newPod("web-1", true)},
+			pod:         "web-1",
+			ports:       map[int]int{80: 80},
+		},
+		{
+			description: "no ready pod",
+			target:      Target{Kind: Service, Name: "web", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			pods:        []v1.Pod{newPod("web-1", false)},
+			retriable:   true,
+		},
+		{
+			description: "missing pod",
+			target:      Target{Kind: Pod, Name: "web-1", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			retriable:   true,
+		},
+		{
+			description: "missing service",
+			target:      Target{Kind: Service, Name: "db", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			shouldErr:   true,
+		},
+		{
+			description: "service without selector",
+			target:      Target{Kind: Service, Name: "external", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 80}},
+			shouldErr:   true,
+		},
+		{
+			description: "port not of the service",
+			target:      Target{Kind: Service, Name: "web", Namespace: "default"},
+			pairs:       []PortPair{{Local: 8000, Remote: 443}},
+			pods:        []v1.Pod{newPod("web-1", true)},
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			pods := &mockPods{}
+			pods.set(test.pods...)
+			r, err := newResolver(pods).resolve(test.target, test.pairs)
+			if test.retriable || test.shouldErr {
+				if err == nil {
+					t.Fatalf("Expected an error, resolved pod %s", r.pod.Name)
+				}
+				if _, ok := err.(*util.RetriableError); ok != test.retriable {
+					t.Fatalf("Expected a retriable error %t, got %s", test.retriable, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error resolving %s: %s", test.target, err)
+			}
+			if r.pod.Name != test.pod {
+				t.Errorf("Expected pod %s, got %s", test.pod, r.pod.Name)
+			}
+			for remote, port := range test.ports {
+				if r.ports[remote] != port {
+					t.Errorf("Expected port %d forwarded to %d, got %d", remote, port, r.ports[remote])
+				}
+			}
+		})
+	}
+}
+
+func TestStillReady(t *testing.T) {
+	pods := &mockPods{}
+	pod := newPod("web-1", true)
+	pods.set(pod)
+	r := newResolver(pods)
+	if !r.stillReady(&pod) {
+		t.Errorf("Expected pod %s to be ready", pod.Name)
+	}
+
+	terminating := pod
+	terminating.DeletionTimestamp = &meta_v1.Time{}
+	pods.set(terminating)
+	if r.stillReady(&pod) {
+		t.Errorf("Expected a terminating pod not to be ready")
+	}
+
+	recreated := newPod("web-1", true)
+	recreated.UID = "other"
+	pods.set(recreated)
+	if r.stillReady(&pod) {
+		t.Errorf("Expected a recreated pod not to be the one forwarded to")
+	}
+
+	pods.set()
+	if r.stillReady(&pod) {
+		t.Errorf("Expected a deleted pod not to be ready")
+	}
+}
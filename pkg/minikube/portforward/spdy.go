@@ -0,0 +1,145 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/pkg/api"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/rest"
+)
+
+// portForwardProtocol is the subprotocol of the streams of the pods' portforward subresource.
+const portForwardProtocol = "portforward.k8s.io"
+
+// NewForwarder returns a forwarder of the local ports of pairs, on each of the addresses, to the
+// pods of the target, going through the apiserver of config.
+func NewForwarder(config *rest.Config, target Target, pairs []PortPair, addresses []string, out io.Writer) (*Forwarder, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the kubernetes client")
+	}
+	resolver := &Resolver{Pods: client.Core(), Services: client.Core(), Deployments: client.Extensions()}
+	return newForwarder(target, pairs, addresses, resolver, &spdyDialer{config: config, client: client.Core().RESTClient()}, out), nil
+}
+
+// spdyDialer connects to the portforward subresource of the pods, which streams to their ports.
+type spdyDialer struct {
+	config *rest.Config
+	client rest.Interface
+}
+
+func (d *spdyDialer) dial(pod *v1.Pod) (podConnection, error) {
+	u := d.client.Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	tlsConfig, err := rest.TLSConfigFor(d.config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the TLS config")
+	}
+	upgrader := spdy.NewRoundTripper(tlsConfig)
+	rt, err := rest.HTTPWrappersForConfig(d.config, upgrader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the authentication of the apiserver")
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(httpstream.HeaderProtocolVersion, portForwardProtocol)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error requesting the port forwarding")
+	}
+	conn, err := upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &spdyConnection{conn: conn}, nil
+}
+
+// spdyConnection streams the forwarded connections over a connection to the portforward
+// subresource of a pod, each along with a stream the errors are reported on.
+type spdyConnection struct {
+	conn     httpstream.Connection
+	requests uint64
+}
+
+func (c *spdyConnection) forward(port int, conn net.Conn) error {
+	headers := http.Header{}
+	headers.Set(api.StreamType, api.StreamTypeError)
+	headers.Set(api.PortHeader, strconv.Itoa(port))
+	headers.Set(api.PortForwardRequestIDHeader, strconv.FormatUint(atomic.AddUint64(&c.requests, 1), 10))
+	errorStream, err := c.conn.CreateStream(headers)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the error stream")
+	}
+	// Only the pod writes to the error stream.
+	errorStream.Close()
+	errs := make(chan error, 1)
+	go func() {
+		message, err := ioutil.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errs <- errors.Wrap(err, "Error reading the error stream")
+		case len(message) > 0:
+			errs <- errors.New(string(message))
+		default:
+			errs <- nil
+		}
+	}()
+
+	headers.Set(api.StreamType, api.StreamTypeData)
+	dataStream, err := c.conn.CreateStream(headers)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the data stream")
+	}
+	defer dataStream.Reset()
+	remoteDone := make(chan struct{})
+	go func() {
+		io.Copy(conn, dataStream)
+		close(remoteDone)
+	}()
+	go func() {
+		io.Copy(dataStream, conn)
+		// Closing the stream tells the pod there is nothing more to read.
+		dataStream.Close()
+	}()
+
+	select {
+	case <-remoteDone:
+		return nil
+	case err := <-errs:
+		if err != nil {
+			return err
+		}
+		<-remoteDone
+		return nil
+	}
+}
+
+func (c *spdyConnection) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of the targets forwarded to.
+const (
+	Service    = "service"
+	Deployment = "deployment"
+	Pod        = "pod"
+)
+
+var kindAliases = map[string]string{
+	"service": Service, "services": Service, "svc": Service,
+	"deployment": Deployment, "deployments": Deployment, "deploy": Deployment,
+	"pod": Pod, "pods": Pod, "po": Pod,
+}
+
+// Target is the service, deployment or pod whose pods ports are forwarded to.
+type Target struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s", t.Kind, t.Name)
+}
+
+// ParseTarget parses a target of the form <kind>/<name>, such as service/web or deploy/web.
+func ParseTarget(s, namespace string) (Target, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Target{}, errors.Errorf("Invalid target %q, expected service/<name>, deployment/<name> or pod/<name>", s)
+	}
+	kind, ok := kindAliases[strings.ToLower(parts[0])]
+	if !ok {
+		return Target{}, errors.Errorf("Unsupported kind %q, expected service, deployment or pod", parts[0])
+	}
+	return Target{Kind: kind, Name: parts[1], Namespace: namespace}, nil
+}
+
+// PortPair forwards the Local port of the host to the Remote port of the target. The remote
+// port of a service is one of its ports, which is mapped to the target port of its pods.
+type PortPair struct {
+	Local  int
+	Remote int
+}
+
+func (p PortPair) String() string {
+	return fmt.Sprintf("%d:%d", p.Local, p.Remote)
+}
+
+// ParsePortPairs parses ports of the form local:remote, or a single port forwarded to the same one.
+func ParsePortPairs(args []string) ([]PortPair, error) {
+	if len(args) == 0 {
+		return nil, errors.New("At least one port to forward is required, as local:remote")
+	}
+	var pairs []PortPair
+	for _, arg := range args {
+		parts := strings.SplitN(arg, ":", 2)
+		if len(parts) == 1 {
+			parts = append(parts, parts[0])
+		}
+		var ports [2]int
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 1 || n > 65535 {
+				return nil, errors.Errorf("Invalid port %q in %q, expected local:remote", p, arg)
+			}
+			ports[i] = n
+		}
+		pairs = append(pairs, PortPair{Local: ports[0], Remote: ports[1]})
+	}
+	return pairs, nil
+}
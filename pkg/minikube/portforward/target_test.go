@@ -0,0 +1,85 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	var tests = []struct {
+		description string
+		arg         string
+		expected    Target
+		shouldErr   bool
+	}{
+		{description: "service", arg: "service/web", expected: Target{Kind: Service, Name: "web", Namespace: "default"}},
+		{description: "svc alias", arg: "svc/web", expected: Target{Kind: Service, Name: "web", Namespace: "default"}},
+		{description: "deploy alias", arg: "Deploy/web", expected: Target{Kind: Deployment, Name: "web", Namespace: "default"}},
+		{description: "pod", arg: "pods/web-1", expected: Target{Kind: Pod, Name: "web-1", Namespace: "default"}},
+		{description: "no kind", arg: "web", shouldErr: true},
+		{description: "no name", arg: "service/", shouldErr: true},
+		{description: "unsupported kind", arg: "job/web", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			target, err := ParseTarget(test.arg, "default")
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error parsing %q: %s", test.arg, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error parsing %q, got %+v", test.arg, target)
+			}
+			if target != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, target)
+			}
+		})
+	}
+}
+
+func TestParsePortPairs(t *testing.T) {
+	var tests = []struct {
+		description string
+		args        []string
+		expected    []PortPair
+		shouldErr   bool
+	}{
+		{description: "pairs", args: []string{"8080:80", "8443:443"}, expected: []PortPair{{Local: 8080, Remote: 80}, {Local: 8443, Remote: 443}}},
+		{description: "same port", args: []string{"5432"}, expected: []PortPair{{Local: 5432, Remote: 5432}}},
+		{description: "none", shouldErr: true},
+		{description: "not a number", args: []string{"8080:http"}, shouldErr: true},
+		{description: "out of range", args: []string{"70000:80"}, shouldErr: true},
+		{description: "empty local port", args: []string{":80"}, shouldErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			pairs, err := ParsePortPairs(test.args)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error parsing %v: %s", test.args, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error parsing %v, got %v", test.args, pairs)
+			}
+			if !reflect.DeepEqual(pairs, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, pairs)
+			}
+		})
+	}
+}
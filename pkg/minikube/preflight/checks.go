@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minimumVirtualBoxVersion is the oldest VirtualBox the virtualbox driver works with.
+var minimumVirtualBoxVersion = [2]int{5, 0}
+
+type virtualBoxVersionCheck struct {
+	vboxManage string
+}
+
+func (virtualBoxVersionCheck) Name() string {
+	return "VirtualBox version"
+}
+
+var vboxVersionRegexp = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+func (c virtualBoxVersionCheck) Check(env Env) error {
+	out, err := env.Command(c.vboxManage, "--version")
+	if err != nil {
+		return &Failure{
+			Reason:      fmt.Sprintf("Could not run %s: %s", c.vboxManage, err),
+			Remediation: "Install VirtualBox from https://www.virtualbox.org/wiki/Downloads, or pick another driver with --vm-driver.",
+		}
+	}
+	m := vboxVersionRegexp.FindStringSubmatch(strings.TrimSpace(out))
+	if m == nil {
+		return fmt.Errorf("Unexpected VBoxManage version %q", strings.TrimSpace(out))
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major < minimumVirtualBoxVersion[0] || (major == minimumVirtualBoxVersion[0] && minor < minimumVirtualBoxVersion[1]) {
+		return &Failure{
+			Reason:      fmt.Sprintf("VirtualBox %s is too old, the minimum version is %d.%d", strings.TrimSpace(out), minimumVirtualBoxVersion[0], minimumVirtualBoxVersion[1]),
+			Remediation: "Upgrade VirtualBox from https://www.virtualbox.org/wiki/Downloads.",
+		}
+	}
+	return nil
+}
+
+// virtualizationCheck checks that the CPU has hardware virtualization enabled.
+// It can only tell on Linux, where the CPU flags are in /proc/cpuinfo.
+type virtualizationCheck struct{}
+
+func (virtualizationCheck) Name() string {
+	return "hardware virtualization"
+}
+
+func (virtualizationCheck) Check(env Env) error {
+	if env.GOOS() != "linux" {
+		return nil
+	}
+	cpuinfo, err := env.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(cpuinfo), "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(kv[1]) {
+			if flag == "vmx" || flag == "svm" {
+				return nil
+			}
+		}
+	}
+	return &Failure{
+		Reason:      "The CPU doesn't have hardware virtualization (VT-x or AMD-V) enabled",
+		Remediation: "Enable VT-x or AMD-V in the BIOS. When running in a VM, enable nested virtualization.",
+	}
+}
+
+// diskSpaceCheck checks that there is enough free space for the VM's files.
+type diskSpaceCheck struct {
+	path       string
+	requiredMB uint64
+}
+
+func (diskSpaceCheck) Name() string {
+	return "free disk space"
+}
+
+func (c diskSpaceCheck) Check(env Env) error {
+	free, err := env.FreeDiskMB(c.path)
+	if err != nil {
+		return err
+	}
+	if free < c.requiredMB {
+		return &Failure{
+			Reason:      fmt.Sprintf("Only %dMB is free under %s, at least %dMB is needed", free, c.path, c.requiredMB),
+			Remediation: "Free up some disk space, or set MINIKUBE_HOME to a directory on a disk with more space.",
+		}
+	}
+	return nil
+}
+
+// hypervConflictCheck checks that Hyper-V isn't enabled on Windows, as
+// VirtualBox can't run VMs while it is.
+type hypervConflictCheck struct{}
+
+func (hypervConflictCheck) Name() string {
+	return "Hyper-V conflict"
+}
+
+func (hypervConflictCheck) Check(env Env) error {
+	if env.GOOS() != "windows" {
+		return nil
+	}
+	out, err := env.Command("powershell", "-NoProfile", "-NonInteractive", "(Get-CimInstance Win32_ComputerSystem).HypervisorPresent")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "True" {
+		return &Failure{
+			Reason:      "Hyper-V is enabled, which prevents VirtualBox from running VMs",
+			Remediation: "Use --vm-driver=hyperv, or disable Hyper-V by running `bcdedit /set hypervisorlaunchtype off` as administrator and rebooting.",
+		}
+	}
+	return nil
+}
+
+// iommuCheck checks that the IOMMU is enabled, which passing PCI devices through to the VM with VFIO needs.
+type iommuCheck struct{}
+
+func (iommuCheck) Name() string {
+	return "IOMMU"
+}
+
+func (iommuCheck) Check(env Env) error {
+	out, err := env.Command("ls", "/sys/kernel/iommu_groups")
+	if err == nil && strings.TrimSpace(out) != "" {
+		return nil
+	}
+	return &Failure{
+		Reason:      "The IOMMU is not enabled, so the GPUs can't be passed through to the VM",
+		Remediation: "Enable VT-d or AMD-Vi in the BIOS, add intel_iommu=on or amd_iommu=on to the kernel command line, load the vfio-pci module and reboot.",
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight checks that the host can run the minikube VM before it is
+// created, so that start fails fast with instructions rather than minutes in.
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// Env is the host the checks look at, replaced with a fake one in tests.
+type Env interface {
+	// GOOS returns the host OS.
+	GOOS() string
+	// Command runs the command and returns its combined output.
+	Command(name string, args ...string) (string, error)
+	ReadFile(path string) ([]byte, error)
+	// FreeDiskMB returns the free disk space under path, in MB.
+	FreeDiskMB(path string) (uint64, error)
+}
+
+// HostEnv is the Env of the host minikube runs on.
+type HostEnv struct{}
+
+func (HostEnv) GOOS() string {
+	return runtime.GOOS
+}
+
+func (HostEnv) Command(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	return string(out), err
+}
+
+func (HostEnv) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+func (HostEnv) FreeDiskMB(path string) (uint64, error) {
+	return freeDiskMB(path)
+}
+
+// Check is a check of the host.
+type Check interface {
+	// Name describes what is checked.
+	Name() string
+	// Check returns a *Failure if the host fails the check. Other errors
+	// mean the check could not be run, and are only logged.
+	Check(env Env) error
+}
+
+// Failure is a failed check, along with how to fix it.
+type Failure struct {
+	Reason      string
+	Remediation string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s\n  %s", f.Reason, f.Remediation)
+}
+
+// DriverChecks returns the checks for creating the VM with the given driver.
+// vboxManage is the VBoxManage command the virtualbox driver runs.
+func DriverChecks(driver, vboxManage string) []Check {
+	checks := []Check{diskSpaceCheck{path: constants.GetMinipath(), requiredMB: constants.MinimumDiskSizeMB}}
+	switch driver {
+	case "virtualbox":
+		checks = append(checks, virtualBoxVersionCheck{vboxManage: vboxManage}, virtualizationCheck{}, hypervConflictCheck{})
+	case "kvm", "kvm2":
+		checks = append(checks, virtualizationCheck{})
+	}
+	return checks
+}
+
+// GPUChecks returns the checks for passing the host's GPUs through to the VM.
+func GPUChecks() []Check {
+	return []Check{iommuCheck{}}
+}
+
+// Run runs the checks, returning an error listing those which failed.
+func Run(env Env, checks []Check) error {
+	var failures []string
+	for _, c := range checks {
+		err := c.Check(env)
+		if err == nil {
+			glog.Infof("Preflight check passed: %s", c.Name())
+			continue
+		}
+		if f, ok := err.(*Failure); ok {
+			failures = append(failures, f.Error())
+			continue
+		}
+		glog.Warningf("Could not check %s: %s", c.Name(), err)
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Preflight checks failed:\n%s\nPass --skip-preflight-checks to start anyway.", strings.Join(failures, "\n"))
+}
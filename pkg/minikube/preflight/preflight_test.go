@@ -0,0 +1,195 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type fakeEnv struct {
+	goos     string
+	commands map[string]string
+	files    map[string]string
+	freeMB   uint64
+}
+
+func (e fakeEnv) GOOS() string {
+	return e.goos
+}
+
+func (e fakeEnv) Command(name string, args ...string) (string, error) {
+	out, ok := e.commands[name]
+	if !ok {
+		return "", fmt.Errorf("exec: %q: executable file not found in $PATH", name)
+	}
+	return out, nil
+}
+
+func (e fakeEnv) ReadFile(path string) ([]byte, error) {
+	content, ok := e.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (e fakeEnv) FreeDiskMB(path string) (uint64, error) {
+	return e.freeMB, nil
+}
+
+const (
+	cpuinfoVMX = "processor\t: 0\nflags\t\t: fpu vme de pse tsc msr vmx ssse3\n"
+	cpuinfoSVM = "processor\t: 0\nflags\t\t: fpu vme de pse tsc msr svm\n"
+	cpuinfoNo  = "processor\t: 0\nflags\t\t: fpu vme de pse tsc msr\n"
+)
+
+func TestChecks(t *testing.T) {
+	var tests = []struct {
+		description string
+		check       Check
+		env         fakeEnv
+		shouldFail  bool
+		shouldErr   bool
+	}{
+		{
+			description: "virtualbox new enough",
+			check:       virtualBoxVersionCheck{vboxManage: "VBoxManage"},
+			env:         fakeEnv{commands: map[string]string{"VBoxManage": "5.1.22r115126\n"}},
+		},
+		{
+			description: "virtualbox too old",
+			check:       virtualBoxVersionCheck{vboxManage: "VBoxManage"},
+			env:         fakeEnv{commands: map[string]string{"VBoxManage": "4.3.40r110317\n"}},
+			shouldFail:  true,
+		},
+		{
+			description: "virtualbox missing",
+			check:       virtualBoxVersionCheck{vboxManage: "VBoxManage"},
+			env:         fakeEnv{},
+			shouldFail:  true,
+		},
+		{
+			description: "virtualbox unknown version",
+			check:       virtualBoxVersionCheck{vboxManage: "VBoxManage"},
+			env:         fakeEnv{commands: map[string]string{"VBoxManage": "WARNING: kernel module not loaded\n"}},
+			shouldErr:   true,
+		},
+		{
+			description: "vmx",
+			check:       virtualizationCheck{},
+			env:         fakeEnv{goos: "linux", files: map[string]string{"/proc/cpuinfo": cpuinfoVMX}},
+		},
+		{
+			description: "svm",
+			check:       virtualizationCheck{},
+			env:         fakeEnv{goos: "linux", files: map[string]string{"/proc/cpuinfo": cpuinfoSVM}},
+		},
+		{
+			description: "no virtualization",
+			check:       virtualizationCheck{},
+			env:         fakeEnv{goos: "linux", files: map[string]string{"/proc/cpuinfo": cpuinfoNo}},
+			shouldFail:  true,
+		},
+		{
+			description: "virtualization not checked on darwin",
+			check:       virtualizationCheck{},
+			env:         fakeEnv{goos: "darwin"},
+		},
+		{
+			description: "enough disk space",
+			check:       diskSpaceCheck{path: "/home", requiredMB: 2000},
+			env:         fakeEnv{freeMB: 2000},
+		},
+		{
+			description: "not enough disk space",
+			check:       diskSpaceCheck{path: "/home", requiredMB: 2000},
+			env:         fakeEnv{freeMB: 1999},
+			shouldFail:  true,
+		},
+		{
+			description: "hyper-v enabled",
+			check:       hypervConflictCheck{},
+			env:         fakeEnv{goos: "windows", commands: map[string]string{"powershell": "True\r\n"}},
+			shouldFail:  true,
+		},
+		{
+			description: "hyper-v disabled",
+			check:       hypervConflictCheck{},
+			env:         fakeEnv{goos: "windows", commands: map[string]string{"powershell": "False\r\n"}},
+		},
+		{
+			description: "iommu enabled",
+			check:       iommuCheck{},
+			env:         fakeEnv{goos: "linux", commands: map[string]string{"ls": "0\n1\n2\n"}},
+		},
+		{
+			description: "iommu disabled",
+			check:       iommuCheck{},
+			env:         fakeEnv{goos: "linux", commands: map[string]string{"ls": ""}},
+			shouldFail:  true,
+		},
+		{
+			description: "hyper-v not checked on linux",
+			check:       hypervConflictCheck{},
+			env:         fakeEnv{goos: "linux"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			err := test.check.Check(test.env)
+			_, failed := err.(*Failure)
+			if failed != test.shouldFail {
+				t.Errorf("Expected failure: %v, got %v", test.shouldFail, err)
+			}
+			if (err != nil && !failed) != test.shouldErr {
+				t.Errorf("Expected error: %v, got %v", test.shouldErr, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	env := fakeEnv{
+		goos:   "linux",
+		files:  map[string]string{"/proc/cpuinfo": cpuinfoVMX},
+		freeMB: 100,
+	}
+	checks := []Check{
+		virtualizationCheck{},
+		diskSpaceCheck{path: "/home", requiredMB: 2000},
+		// Could not be checked: not a failure.
+		virtualBoxVersionCheck{vboxManage: "VBoxManage"},
+	}
+	env.commands = map[string]string{"VBoxManage": "garbage"}
+	err := Run(env, checks)
+	if err == nil {
+		t.Fatalf("Expected the disk space check to fail")
+	}
+	if !strings.Contains(err.Error(), "Only 100MB is free under /home") || strings.Contains(err.Error(), "VirtualBox") {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	env.freeMB = 5000
+	if err := Run(env, checks); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report writes the diagnostics bundle of minikube report: a tar.gz of the files
+// collected about the host and the cluster, with their secrets redacted, and an index of them.
+package report
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// IndexFile is the file of the bundle listing the others, and whether collecting each succeeded.
+const IndexFile = "index.txt"
+
+const redacted = "REDACTED"
+
+// Item is a file of the bundle, and how it is collected.
+type Item struct {
+	// Name is the path of the file in the bundle.
+	Name string
+	// Collect writes the contents of the file to w. What it wrote before failing is kept.
+	Collect func(w io.Writer) error
+}
+
+var (
+	// urlCredentials are the user and password of URLs, as in the proxy URLs of --docker-env.
+	urlCredentials = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s:@"']+:[^/\s@"']*@`)
+	// privateKeys are PEM private keys, also when their newlines are escaped in JSON.
+	privateKeys = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+	// secretKeys are the keys of configs, JSON, YAML or environment variables, whose values are secrets.
+	secretKeys = `[\w.-]*(?i:password|passwd|secret|token|access[-_]?key|credentials|key-data)[\w.-]*|(?i:auth)`
+	// secretFlags are the flags of command lines whose values, after a space, are secrets, as in docker login --password.
+	secretFlags = regexp.MustCompile(`(^|\s)(--?[\w-]*(?i:password|passwd|secret|token|access[-_]?key)[\w-]*\s+)([^\s-]\S*)`)
+)
+
+// assignment matches the keys and values of configs, with the prefix before the key, the key, and what is
+// between it and the value captured.
+func assignment(keys string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\w.-])("?(?:` + keys + `)"?\s*[:=]\s*)("[^"]*"|[^\s,{\[]+)`)
+}
+
+// Redactor replaces the secrets of the collected files.
+type Redactor struct {
+	assignments []*regexp.Regexp
+}
+
+// NewRedactor returns a redactor of the proxy and registry credentials and private keys, and of the values
+// of keys, such as the config keys of the addons' credentials.
+func NewRedactor(keys ...string) *Redactor {
+	r := &Redactor{assignments: []*regexp.Regexp{assignment(secretKeys)}}
+	if len(keys) > 0 {
+		quoted := make([]string, len(keys))
+		for i, k := range keys {
+			quoted[i] = regexp.QuoteMeta(k)
+		}
+		r.assignments = append(r.assignments, assignment(strings.Join(quoted, "|")))
+	}
+	return r
+}
+
+// Redact returns data with its secrets replaced.
+func (r *Redactor) Redact(data []byte) []byte {
+	data = urlCredentials.ReplaceAll(data, []byte("${1}"+redacted+":"+redacted+"@"))
+	data = privateKeys.ReplaceAll(data, []byte("-----BEGIN ${1}PRIVATE KEY-----"+redacted+"-----END ${1}PRIVATE KEY-----"))
+	for _, a := range r.assignments {
+		data = a.ReplaceAllFunc(data, func(m []byte) []byte {
+			sub := a.FindSubmatch(m)
+			value := []byte(redacted)
+			if bytes.HasPrefix(sub[3], []byte(`"`)) {
+				value = []byte(`"` + redacted + `"`)
+			}
+			return append(append(append([]byte{}, sub[1]...), sub[2]...), value...)
+		})
+	}
+	return secretFlags.ReplaceAll(data, []byte("${1}${2}"+redacted))
+}
+
+// collect runs the collection of the item, turning a panic into an error so that the other items are still collected.
+func collect(item Item, w io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	return item.Collect(w)
+}
+
+// Write collects each item and writes the bundle of their redacted files to w as a tar.gz, with
+// the index first. An item failing to be collected is listed as failed in the index, the
+// others are still collected.
+func Write(w io.Writer, items []Item, r *Redactor) error {
+	now := time.Now()
+	var index bytes.Buffer
+	fmt.Fprintf(&index, "minikube report, collected at %s\n\n", now.Format(time.RFC3339))
+	files := make([][]byte, len(items))
+	for i, item := range items {
+		var b bytes.Buffer
+		if err := collect(item, &b); err != nil {
+			glog.Infof("Error collecting %s: %s", item.Name, err)
+			fmt.Fprintf(&index, "%s\tfailed: %s\n", item.Name, r.Redact([]byte(err.Error())))
+		} else {
+			fmt.Fprintf(&index, "%s\tok\n", item.Name)
+		}
+		files[i] = r.Redact(b.Bytes())
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeFile(tw, IndexFile, index.Bytes(), now); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if err := writeFile(tw, item.Name, files[i], now); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "Error closing tar")
+	}
+	return errors.Wrap(gz.Close(), "Error closing gzip")
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime}); err != nil {
+		return errors.Wrapf(err, "Error writing the header of %s", name)
+	}
+	_, err := tw.Write(data)
+	return errors.Wrapf(err, "Error writing %s", name)
+}
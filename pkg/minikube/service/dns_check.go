@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	dnsCheckNamespace = "default"
+	dnsCheckInterval  = 2 * time.Second
+	// dnsCheckScript retries the lookup while kube-dns starts.
+	dnsCheckScript = "for i in $(seq 1 30); do nslookup %s && exit 0; sleep 2; done; exit 1"
+)
+
+// podClient is the part of the pods client the DNS check uses, replaced in tests.
+type podClient interface {
+	Create(*v1.Pod) (*v1.Pod, error)
+	Get(name string, options meta_v1.GetOptions) (*v1.Pod, error)
+	Delete(name string, options *meta_v1.DeleteOptions) error
+}
+
+// CheckClusterDNS runs a pod of the image in the kubeconfig context's cluster, which looks up
+// kubernetes.default.svc.<domain>, to check that pods resolve the cluster's services in its domain.
+func CheckClusterDNS(context, domain, image string, timeout time.Duration) error {
+	client, err := CoreClientForContext(context)
+	if err != nil {
+		return err
+	}
+	return checkDNS(client.Pods(dnsCheckNamespace), domain, image, timeout, dnsCheckInterval)
+}
+
+// CoreClientForContext returns a client of the core API of the kubeconfig context's cluster.
+func CoreClientForContext(context string) (corev1.CoreV1Interface, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating kubeConfig")
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating new client from kubeConfig.ClientConfig()")
+	}
+	return client.Core(), nil
+}
+
+func dnsCheckPod(name, image string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			GenerateName: "minikube-dns-check-",
+			Namespace:    dnsCheckNamespace,
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{{
+				Name:    "nslookup",
+				Image:   image,
+				Command: []string{"sh", "-c", fmt.Sprintf(dnsCheckScript, name)},
+			}},
+		},
+	}
+}
+
+// checkDNS runs the lookup pod and waits for it to complete, removing it afterwards.
+func checkDNS(pods podClient, domain, image string, timeout, interval time.Duration) error {
+	name := "kubernetes.default.svc." + domain
+	pod, err := pods.Create(dnsCheckPod(name, image))
+	if err != nil {
+		return errors.Wrap(err, "Error creating the DNS check pod")
+	}
+	defer func() {
+		if err := pods.Delete(pod.Name, &meta_v1.DeleteOptions{}); err != nil {
+			glog.Warningf("Error deleting the DNS check pod %s: %s", pod.Name, err)
+		}
+	}()
+
+	for deadline := time.Now().Add(timeout); ; time.Sleep(interval) {
+		p, err := pods.Get(pod.Name, meta_v1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "Error getting the DNS check pod %s", pod.Name)
+		}
+		switch p.Status.Phase {
+		case v1.PodSucceeded:
+			return nil
+		case v1.PodFailed:
+			return fmt.Errorf("The pod %s could not look up %s", pod.Name, name)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("The pod %s looking up %s did not complete within %s, it is %s", pod.Name, name, timeout, p.Status.Phase)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// fakePods goes through the phases on each get of the created pod.
+type fakePods struct {
+	createErr error
+	phases    []v1.PodPhase
+	created   *v1.Pod
+	deleted   []string
+}
+
+func (f *fakePods) Create(pod *v1.Pod) (*v1.Pod, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.created = pod
+	p := *pod
+	p.Name = pod.GenerateName + "x1y2z"
+	return &p, nil
+}
+
+func (f *fakePods) Get(name string, options meta_v1.GetOptions) (*v1.Pod, error) {
+	p := v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: name}}
+	p.Status.Phase = f.phases[0]
+	if len(f.phases) > 1 {
+		f.phases = f.phases[1:]
+	}
+	return &p, nil
+}
+
+func (f *fakePods) Delete(name string, options *meta_v1.DeleteOptions) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func TestCheckDNS(t *testing.T) {
+	var tests = []struct {
+		description string
+		pods        *fakePods
+		err         string
+	}{
+		{
+			description: "lookup succeeds",
+			pods:        &fakePods{phases: []v1.PodPhase{v1.PodPending, v1.PodRunning, v1.PodSucceeded}},
+		},
+		{
+			description: "lookup fails",
+			pods:        &fakePods{phases: []v1.PodPhase{v1.PodRunning, v1.PodFailed}},
+			err:         "could not look up kubernetes.default.svc.dev.example.com",
+		},
+		{
+			description: "pod never completes",
+			pods:        &fakePods{phases: []v1.PodPhase{v1.PodPending}},
+			err:         "did not complete within",
+		},
+		{
+			description: "pod can't be created",
+			pods:        &fakePods{createErr: errors.New("forbidden")},
+			err:         "Error creating the DNS check pod",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := checkDNS(test.pods, "dev.example.com", "registry.example.com/busybox:1.24", 10*time.Millisecond, time.Millisecond)
+			if test.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Fatalf("Expected an error containing %q, got %v", test.err, err)
+			}
+			if test.pods.createErr != nil {
+				return
+			}
+			c := test.pods.created.Spec.Containers[0]
+			if c.Image != "registry.example.com/busybox:1.24" || !strings.Contains(strings.Join(c.Command, " "), "nslookup kubernetes.default.svc.dev.example.com") {
+				t.Errorf("Expected the pod to look up the domain's kubernetes service with the image, got %+v", c)
+			}
+			if len(test.pods.deleted) != 1 || test.pods.deleted[0] != "minikube-dns-check-x1y2z" {
+				t.Errorf("Expected the pod to be deleted, deleted %v", test.pods.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+)
+
+// ProxyPath returns the path the apiserver proxies to the http port of the service on, or to its
+// only port when none is named http.
+func ProxyPath(namespace, service string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/services/http:%s:/proxy", namespace, service)
+}
+
+// NewProxyHandler returns a handler forwarding the requests to the service through the apiserver
+// proxy, authenticated with the credentials of config. Served on the loopback interface, it makes
+// the service reachable from the host only, without exposing a node port to the whole network.
+func NewProxyHandler(config *rest.Config, namespace, service string) (http.Handler, error) {
+	target, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing the apiserver URL %s", config.Host)
+	}
+	if target.Scheme == "" || target.Host == "" {
+		return nil, errors.Errorf("The apiserver URL %s has no scheme or host", config.Host)
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the apiserver transport")
+	}
+	target.Path = strings.TrimSuffix(target.Path, "/") + ProxyPath(namespace, service)
+	return proxyHandler(target, transport), nil
+}
+
+func proxyHandler(target *url.URL, transport http.RoundTripper) http.Handler {
+	proxy := &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			r.URL.Path = target.Path + r.URL.Path
+			if r.URL.RawPath != "" {
+				r.URL.RawPath = target.Path + r.URL.RawPath
+			}
+			r.Host = target.Host
+			// The credentials sent to the local proxy aren't the apiserver's.
+			r.Header.Del("Authorization")
+		},
+		Transport: transport,
+		ModifyResponse: func(resp *http.Response) error {
+			// Redirects to the proxy path of the apiserver are to the same path of the local proxy.
+			location, err := url.Parse(resp.Header.Get("Location"))
+			if err != nil || !strings.HasPrefix(location.Path, target.Path) || (location.Host != "" && location.Host != target.Host) {
+				return nil
+			}
+			location.Scheme, location.Host = "", ""
+			location.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(location.Path, target.Path), "/")
+			location.RawPath = ""
+			resp.Header.Set("Location", location.String())
+			return nil
+		},
+	}
+	return loopbackHostsOnly(proxy)
+}
+
+// loopbackHostsOnly rejects the requests for other hosts than the loopback ones. Pages of other
+// sites resolving their names to the loopback address would otherwise reach the cluster through
+// the proxy.
+func loopbackHostsOnly(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "localhost" && !net.ParseIP(host).IsLoopback() {
+			http.Error(w, fmt.Sprintf("Requests for host %s are not proxied", r.Host), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ListenLoopback listens on the port of the loopback interface, or on a random one when port is 0.
+func ListenLoopback(port int) (net.Listener, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error listening on port %d of the loopback interface", port)
+	}
+	return l, nil
+}
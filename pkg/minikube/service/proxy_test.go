@@ -0,0 +1,142 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestProxyHandler(t *testing.T) {
+	const dashboardPath = "/api/v1/namespaces/kube-system/services/http:kubernetes-dashboard:/proxy"
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Expected the cluster's credentials, got Authorization %q", auth)
+		}
+		switch r.URL.Path {
+		case dashboardPath + "/":
+			w.Write([]byte("dashboard"))
+		case dashboardPath + "/api/v1/login":
+			w.Write([]byte(r.URL.RawQuery))
+		case dashboardPath + "/old":
+			http.Redirect(w, r, dashboardPath+"/new?a=b", http.StatusFound)
+		case dashboardPath + "/elsewhere":
+			http.Redirect(w, r, "https://kubernetes.io/docs/", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiserver.Close()
+
+	handler, err := NewProxyHandler(&rest.Config{Host: apiserver.URL, BearerToken: "token"}, "kube-system", "kubernetes-dashboard")
+	if err != nil {
+		t.Fatalf("Error creating the proxy handler: %s", err)
+	}
+
+	var tests = []struct {
+		description string
+		host        string
+		path        string
+		status      int
+		body        string
+		location    string
+	}{
+		{
+			description: "root",
+			host:        "127.0.0.1:43210",
+			path:        "/",
+			status:      http.StatusOK,
+			body:        "dashboard",
+		},
+		{
+			description: "query",
+			host:        "localhost:43210",
+			path:        "/api/v1/login?token=abc",
+			status:      http.StatusOK,
+			body:        "token=abc",
+		},
+		{
+			description: "redirect to the proxy path",
+			host:        "127.0.0.1:43210",
+			path:        "/old",
+			status:      http.StatusFound,
+			location:    "/new?a=b",
+		},
+		{
+			description: "redirect elsewhere",
+			host:        "127.0.0.1:43210",
+			path:        "/elsewhere",
+			status:      http.StatusFound,
+			location:    "https://kubernetes.io/docs/",
+		},
+		{
+			description: "other host",
+			host:        "attacker.example.com:43210",
+			path:        "/",
+			status:      http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://"+test.host+test.path, nil)
+			r.Header.Set("Authorization", "Basic local")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Fatalf("Expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+			if test.body != "" && w.Body.String() != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, w.Body.String())
+			}
+			if location := w.Header().Get("Location"); location != test.location {
+				t.Errorf("Expected location %q, got %q", test.location, location)
+			}
+		})
+	}
+}
+
+func TestNewProxyHandlerErrors(t *testing.T) {
+	for _, host := range []string{"192.168.99.100:8443", "https://"} {
+		if _, err := NewProxyHandler(&rest.Config{Host: host}, "kube-system", "kubernetes-dashboard"); err == nil {
+			t.Errorf("Expected an error with the apiserver URL %s", host)
+		}
+	}
+}
+
+func TestListenLoopback(t *testing.T) {
+	l, err := ListenLoopback(0)
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	defer l.Close()
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		t.Fatalf("Error splitting the address %s: %s", l.Addr(), err)
+	}
+	if host != "127.0.0.1" || port == "0" {
+		t.Errorf("Expected a random port of 127.0.0.1, got %s", l.Addr())
+	}
+	if _, err := ListenLoopback(-1); err == nil || !strings.Contains(err.Error(), "port -1") {
+		t.Errorf("Expected an error listening on port -1, got %v", err)
+	}
+}
@@ -18,28 +18,48 @@ package service
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/docker/machine/libmachine"
+	"github.com/golang/glog"
 	"github.com/pkg/browser"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"text/template"
 
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	cfg "k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/util"
 )
 
+// DefaultWaitTimeout is how long a service is waited for before its URLs are opened.
+const DefaultWaitTimeout = 2 * time.Minute
+
+const serviceWaitInterval = 2 * time.Second
+
+// probeClient requests the HTTP URLs of services to check that they respond. Their certificates
+// are seldom signed by a CA the host trusts.
+var probeClient = &http.Client{
+	Timeout:   5 * time.Second,
+	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+}
+
 type K8sClient interface {
 	GetCoreClient() (corev1.CoreV1Interface, error)
 }
@@ -53,12 +73,9 @@ func init() {
 }
 
 func (*K8sClientGetter) GetCoreClient() (corev1.CoreV1Interface, error) {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-	config, err := kubeConfig.ClientConfig()
+	config, err := GetClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("Error creating kubeConfig: %s", err)
+		return nil, err
 	}
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -67,6 +84,18 @@ func (*K8sClientGetter) GetCoreClient() (corev1.CoreV1Interface, error) {
 	return client.Core(), nil
 }
 
+// GetClientConfig returns the client config of the current profile's cluster, through its kubeconfig context.
+func GetClientConfig() (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.GetMachineName()}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	config, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Error creating kubeConfig: %s", err)
+	}
+	return config, nil
+}
+
 type ServiceURL struct {
 	Namespace string
 	Name      string
@@ -134,6 +163,27 @@ func GetServiceURLsForService(api libmachine.API, namespace, service string, t *
 }
 
 func printURLsForService(c corev1.CoreV1Interface, ip, service, namespace string, t *template.Template) ([]string, error) {
+	portURLs, err := serviceURLs(c, ip, service, namespace, t)
+	if err != nil {
+		return nil, err
+	}
+	urls := []string{}
+	for _, u := range portURLs {
+		urls = append(urls, u.URL)
+	}
+	return urls, nil
+}
+
+// portURL is the URL of a node port of a service.
+type portURL struct {
+	URL string
+	// HTTP is whether the port serves HTTP, which the URL is probed with before it is opened.
+	HTTP bool
+}
+
+// serviceURLs returns the URLs of the node ports of the service, formatted with t. Templates
+// which don't make URLs, such as "{{.IP}}:{{.Port}}", are output as they are.
+func serviceURLs(c corev1.CoreV1Interface, ip, service, namespace string, t *template.Template) ([]portURL, error) {
 	if t == nil {
 		return nil, errors.New("Error, attempted to generate service url with nil --format template")
 	}
@@ -143,38 +193,126 @@ func printURLsForService(c corev1.CoreV1Interface, ip, service, namespace string
 	if err != nil {
 		return nil, errors.Wrapf(err, "service '%s' could not be found running", service)
 	}
-	var nodePorts []int32
-	if len(svc.Spec.Ports) > 0 {
-		for _, port := range svc.Spec.Ports {
-			if port.NodePort > 0 {
-				nodePorts = append(nodePorts, port.NodePort)
-			}
+	urls := []portURL{}
+	for _, port := range svc.Spec.Ports {
+		if port.NodePort <= 0 {
+			continue
 		}
-	}
-	urls := []string{}
-	for _, port := range nodePorts {
-		var doc bytes.Buffer
-		err = t.Execute(&doc, struct {
-			IP   string
-			Port int32
-		}{
-			ip,
-			port,
-		})
+		u, err := nodePortURL(t, ip, svc, port)
 		if err != nil {
 			return nil, err
 		}
+		urls = append(urls, portURL{URL: u, HTTP: httpPort(port) && strings.HasPrefix(u, "http")})
+	}
+	return urls, nil
+}
+
+// nodePortURL formats the URL of the node port of port with t.
+func nodePortURL(t *template.Template, ip string, svc *v1.Service, port v1.ServicePort) (string, error) {
+	var doc bytes.Buffer
+	err := t.Execute(&doc, struct {
+		IP        string
+		Port      int32
+		Name      string
+		Namespace string
+		PortName  string
+	}{
+		ip,
+		port.NodePort,
+		svc.Name,
+		svc.Namespace,
+		port.Name,
+	})
+	if err != nil {
+		return "", err
+	}
 
-		u, err := url.Parse(doc.String())
+	u := doc.String()
+	if strings.Contains(u, "://") {
+		parsed, err := url.Parse(u)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
+		u = parsed.String()
+	}
+	return u, nil
+}
 
-		urls = append(urls, u.String())
+// ServicePortURL is the URL of the node port of a port of a service.
+type ServicePortURL struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// TargetPort is the name, or number, of the port of the pods the port forwards to.
+	TargetPort string `json:"targetPort"`
+	// URL is empty for the ports without a node port.
+	URL string `json:"url,omitempty"`
+}
+
+// GetServicePortURLs returns the URLs of the ports of every service in the namespace, or in
+// all of them for v1.NamespaceAll, formatted with t. Headless services are left out, and the
+// ports without a node port have no URL.
+func GetServicePortURLs(api libmachine.API, namespace string, t *template.Template) ([]ServicePortURL, error) {
+	host, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := host.Driver.GetIP()
+	if err != nil {
+		return nil, err
+	}
+	client, err := k8s.GetCoreClient()
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := client.Services(namespace).List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []ServicePortURL{}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if svc.Spec.ClusterIP == v1.ClusterIPNone {
+			continue
+		}
+		if len(svc.Spec.Ports) == 0 {
+			urls = append(urls, ServicePortURL{Namespace: svc.Namespace, Name: svc.Name})
+		}
+		for _, port := range svc.Spec.Ports {
+			u := ServicePortURL{Namespace: svc.Namespace, Name: svc.Name, TargetPort: targetPort(port)}
+			if port.NodePort > 0 {
+				if u.URL, err = nodePortURL(t, ip, svc, port); err != nil {
+					return nil, err
+				}
+			}
+			urls = append(urls, u)
+		}
 	}
 	return urls, nil
 }
 
+// targetPort returns the name, or number, of the target port of port, which defaults to its own.
+func targetPort(port v1.ServicePort) string {
+	if target := port.TargetPort.String(); target != "" && target != "0" {
+		return target
+	}
+	return strconv.Itoa(int(port.Port))
+}
+
+// httpPort returns whether the port serves HTTP, going by its name, such as "http" or
+// "https-web", or by its number.
+func httpPort(p v1.ServicePort) bool {
+	name := strings.ToLower(p.Name)
+	if name == "http" || name == "https" || strings.HasPrefix(name, "http-") || strings.HasPrefix(name, "https-") {
+		return true
+	}
+	switch p.Port {
+	case 80, 443, 8080, 8443:
+		return true
+	}
+	return false
+}
+
 // CheckService waits for the specified service to be ready by returning an error until the service is up
 // The check is done by polling the endpoint associated with the service and when the endpoint exists, returning no error->service-online
 func CheckService(namespace string, service string) error {
@@ -203,43 +341,152 @@ func checkEndpointReady(endpoints corev1.EndpointsInterface, service string) err
 	if err != nil {
 		return errors.Wrapf(err, "Error getting endpoints for service %s", service)
 	}
-	const notReadyMsg = "Waiting, endpoint for service is not ready yet...\n"
 	if len(endpoint.Subsets) == 0 {
-		fmt.Fprintf(os.Stderr, notReadyMsg)
 		return &util.RetriableError{Err: errors.New("Endpoint for service is not ready yet")}
 	}
 	for _, subset := range endpoint.Subsets {
 		if len(subset.Addresses) == 0 {
-			fmt.Fprintf(os.Stderr, notReadyMsg)
 			return &util.RetriableError{Err: errors.New("No endpoints for service are ready yet")}
 		}
 	}
 	return nil
 }
 
-func WaitAndMaybeOpenService(api libmachine.API, namespace string, service string, urlTemplate *template.Template, urlMode bool, https bool) error {
-	if err := util.RetryAfter(20, func() error { return CheckService(namespace, service) }, 6*time.Second); err != nil {
-		return errors.Wrapf(err, "Could not find finalized endpoint being pointed to by %s", service)
+// WaitAndMaybeOpenService waits for the service to be ready, for up to wait, and then opens its
+// URLs in the browser, or prints them in urlMode or when they aren't http URLs.
+func WaitAndMaybeOpenService(api libmachine.API, namespace string, service string, urlTemplate *template.Template, urlMode bool, https bool, wait time.Duration) error {
+	client, err := k8s.GetCoreClient()
+	if err != nil {
+		return errors.Wrap(err, "Error getting kubernetes client")
 	}
-
-	urls, err := GetServiceURLsForService(api, namespace, service, urlTemplate)
+	if _, err := client.Services(namespace).Get(service, meta_v1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return serviceNotFound(client.Services(namespace), namespace, service)
+		}
+		return errors.Wrapf(err, "Error getting service %s", service)
+	}
+	host, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error checking if api exist and loading it")
+	}
+	ip, err := host.Driver.GetIP()
+	if err != nil {
+		return errors.Wrap(err, "Error getting ip from host")
+	}
+	urls, err := serviceURLs(client, ip, service, namespace, urlTemplate)
 	if err != nil {
 		return errors.Wrap(err, "Check that minikube is running and that you have specified the correct namespace")
 	}
-	for _, url := range urls {
-		if https {
-			url = strings.Replace(url, "http", "https", 1)
+	for i := range urls {
+		if https && strings.HasPrefix(urls[i].URL, "http://") {
+			urls[i].URL = "https://" + strings.TrimPrefix(urls[i].URL, "http://")
+		}
+	}
+	if wait > 0 {
+		s := startSpinner(os.Stderr, fmt.Sprintf("Waiting for service %s/%s to be ready", namespace, service))
+		err := waitForService(client.Endpoints(namespace), service, urls, wait, serviceWaitInterval)
+		s.stop()
+		if err != nil {
+			return err
 		}
-		if urlMode || !strings.HasPrefix(url, "http") {
-			fmt.Fprintln(os.Stdout, url)
+	}
+
+	for _, u := range urls {
+		if urlMode || !strings.HasPrefix(u.URL, "http") {
+			fmt.Fprintln(os.Stdout, u.URL)
 		} else {
 			fmt.Fprintln(os.Stderr, "Opening kubernetes service "+namespace+"/"+service+" in default browser...")
-			browser.OpenURL(url)
+			browser.OpenURL(u.URL)
 		}
 	}
 	return nil
 }
 
+// waitForService waits for an endpoint of the service to be ready, and then for its HTTP URLs to
+// respond, for up to timeout.
+func waitForService(endpoints corev1.EndpointsInterface, service string, urls []portURL, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkEndpointReady(endpoints, service)
+		if err == nil {
+			err = probeURLs(urls)
+		}
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*util.RetriableError); !ok {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "Service %s was not ready within %s. Pass a longer --wait to wait for it longer", service, timeout)
+		}
+		glog.Infof("Waiting for service %s: %s", service, err)
+		time.Sleep(interval)
+	}
+}
+
+// probeURLs requests the HTTP URLs, returning a retriable error until each responds, with any status.
+func probeURLs(urls []portURL) error {
+	for _, u := range urls {
+		if !u.HTTP {
+			continue
+		}
+		resp, err := probeClient.Head(u.URL)
+		if err != nil {
+			return &util.RetriableError{Err: errors.Wrapf(err, "%s is not responding yet", u.URL)}
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// serviceNotFound returns the error of a service not found in the namespace, which lists the
+// services of the namespace as a hint.
+func serviceNotFound(services corev1.ServiceInterface, namespace, service string) error {
+	list, err := services.List(meta_v1.ListOptions{})
+	if err != nil || len(list.Items) == 0 {
+		return errors.Errorf("Service %s was not found in namespace %s, which has no services. Pass -n to look in another namespace", service, namespace)
+	}
+	var names []string
+	for _, s := range list.Items {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return errors.Errorf("Service %s was not found in namespace %s. Its services are: %s", service, namespace, strings.Join(names, ", "))
+}
+
+// GetServiceNames returns the sorted names of the services of the namespace, failing when the
+// cluster doesn't answer within the timeout, as when it is down.
+func GetServiceNames(namespace string, timeout time.Duration) ([]string, error) {
+	client, err := k8s.GetCoreClient()
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		list *v1.ServiceList
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		list, err := client.Services(namespace).List(meta_v1.ListOptions{})
+		done <- result{list, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, errors.Wrap(r.err, "Error listing services")
+		}
+		names := []string{}
+		for _, s := range r.list.Items {
+			names = append(names, s.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	case <-time.After(timeout):
+		return nil, errors.Errorf("Timed out listing the services after %s", timeout)
+	}
+}
+
 func GetServiceListByLabel(namespace string, key string, value string) (*v1.ServiceList, error) {
 	client, err := k8s.GetCoreClient()
 	if err != nil {
@@ -17,15 +17,19 @@ limitations under the License.
 package service
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/pkg/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	"k8s.io/client-go/pkg/api/v1"
@@ -274,6 +278,148 @@ func TestPrintURLsForService(t *testing.T) {
 	}
 }
 
+func TestServiceURLsMultiPort(t *testing.T) {
+	client := &MockCoreClient{
+		servicesMap: map[string]corev1.ServiceInterface{
+			"web": &MockServiceInterface{
+				ServiceList: &v1.ServiceList{
+					Items: []v1.Service{
+						{
+							ObjectMeta: meta_v1.ObjectMeta{Name: "shop", Namespace: "web"},
+							Spec: v1.ServiceSpec{
+								Ports: []v1.ServicePort{
+									{Name: "http", Port: 8000, NodePort: 30080},
+									{Name: "metrics", Port: 9090, NodePort: 30090},
+									{Name: "tls", Port: 443, NodePort: 30443},
+									{Name: "db", Port: 5432},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var tests = []struct {
+		description string
+		tmpl        string
+		expected    []portURL
+	}{
+		{
+			description: "default template",
+			tmpl:        defaultServiceFormat,
+			expected: []portURL{
+				{URL: "http://192.168.99.100:30080", HTTP: true},
+				{URL: "http://192.168.99.100:30090"},
+				{URL: "http://192.168.99.100:30443", HTTP: true},
+			},
+		},
+		{
+			description: "template for scripts",
+			tmpl:        "{{.Namespace}}/{{.Name}} {{.PortName}} {{.IP}}:{{.Port}}",
+			expected: []portURL{
+				{URL: "web/shop http 192.168.99.100:30080"},
+				{URL: "web/shop metrics 192.168.99.100:30090"},
+				{URL: "web/shop tls 192.168.99.100:30443"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			tmpl := template.Must(template.New("svc-template").Parse(test.tmpl))
+			urls, err := serviceURLs(client, "192.168.99.100", "shop", "web", tmpl)
+			if err != nil {
+				t.Fatalf("Error getting the service URLs: %s", err)
+			}
+			if !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, urls)
+			}
+		})
+	}
+}
+
+const defaultServiceFormat = "http://{{.IP}}:{{.Port}}"
+
+// readyAfterEndpoints has a ready endpoint once it was got a number of times.
+type readyAfterEndpoints struct {
+	fake.FakeEndpoints
+	gets  int
+	after int
+}
+
+func (e *readyAfterEndpoints) Get(name string, _ meta_v1.GetOptions) (*v1.Endpoints, error) {
+	e.gets++
+	if e.gets > e.after {
+		return endpointMap["one-ready"], nil
+	}
+	return endpointMap["not-ready"], nil
+}
+
+func TestWaitForService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	var tests = []struct {
+		description string
+		readyAfter  int
+		urls        []portURL
+		shouldErr   bool
+	}{
+		{
+			description: "ready at once",
+		},
+		{
+			description: "endpoint ready after a while",
+			readyAfter:  3,
+			urls:        []portURL{{URL: server.URL, HTTP: true}},
+		},
+		{
+			description: "endpoint never ready",
+			readyAfter:  1000,
+			shouldErr:   true,
+		},
+		{
+			description: "HTTP port not responding",
+			urls:        []portURL{{URL: closed.URL, HTTP: true}},
+			shouldErr:   true,
+		},
+		{
+			description: "other port not probed",
+			urls:        []portURL{{URL: closed.URL}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			endpoints := &readyAfterEndpoints{after: test.readyAfter}
+			err := waitForService(endpoints, "shop", test.urls, 50*time.Millisecond, time.Millisecond)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatal("Expected the service not to be ready")
+			}
+			if !test.shouldErr && endpoints.gets != test.readyAfter+1 {
+				t.Errorf("Expected the endpoints got %d times, got them %d times", test.readyAfter+1, endpoints.gets)
+			}
+		})
+	}
+}
+
+func TestServiceNotFound(t *testing.T) {
+	err := serviceNotFound(defaultNamespaceServiceInterface, "default", "dashboard")
+	if err == nil || !strings.Contains(err.Error(), "Its services are: mock-dashboard, mock-dashboard-no-ports") {
+		t.Errorf("Expected the namespace's services listed, got %v", err)
+	}
+	err = serviceNotFound(&MockServiceInterface{ServiceList: &v1.ServiceList{}}, "web", "dashboard")
+	if err == nil || !strings.Contains(err.Error(), "has no services") {
+		t.Errorf("Expected the namespace reported empty, got %v", err)
+	}
+}
+
 func TestGetServiceURLs(t *testing.T) {
 	defaultAPI := &tests.MockAPI{
 		Hosts: map[string]*host.Host{
@@ -341,6 +487,63 @@ func TestGetServiceURLs(t *testing.T) {
 	}
 }
 
+func TestGetServicePortURLs(t *testing.T) {
+	api := &tests.MockAPI{
+		Hosts: map[string]*host.Host{
+			config.GetMachineName(): {
+				Name:   config.GetMachineName(),
+				Driver: &tests.MockDriver{},
+			},
+		},
+	}
+	services := &MockServiceInterface{
+		ServiceList: &v1.ServiceList{
+			Items: []v1.Service{
+				{
+					ObjectMeta: meta_v1.ObjectMeta{Name: "shop", Namespace: "web"},
+					Spec: v1.ServiceSpec{
+						ClusterIP: "10.0.0.20",
+						Ports: []v1.ServicePort{
+							{Name: "http", Port: 80, TargetPort: intstr.FromString("http"), NodePort: 30080},
+							{Name: "metrics", Port: 9090, TargetPort: intstr.FromInt(9091), NodePort: 30090},
+							{Name: "db", Port: 5432},
+						},
+					},
+				},
+				{
+					ObjectMeta: meta_v1.ObjectMeta{Name: "no-ports", Namespace: "default"},
+					Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.21"},
+				},
+				{
+					ObjectMeta: meta_v1.ObjectMeta{Name: "headless", Namespace: "default"},
+					Spec: v1.ServiceSpec{
+						ClusterIP: v1.ClusterIPNone,
+						Ports:     []v1.ServicePort{{Port: 80}},
+					},
+				},
+			},
+		},
+	}
+	expected := []ServicePortURL{
+		{Namespace: "web", Name: "shop", TargetPort: "http", URL: "http://127.0.0.1:30080"},
+		{Namespace: "web", Name: "shop", TargetPort: "9091", URL: "http://127.0.0.1:30090"},
+		{Namespace: "web", Name: "shop", TargetPort: "5432"},
+		{Namespace: "default", Name: "no-ports"},
+	}
+
+	defer revertK8sClient(k8s)
+	k8s = &MockClientGetter{
+		servicesMap: map[string]corev1.ServiceInterface{meta_v1.NamespaceAll: services},
+	}
+	urls, err := GetServicePortURLs(api, meta_v1.NamespaceAll, template.Must(template.New("svc-template").Parse(defaultServiceFormat)))
+	if err != nil {
+		t.Fatalf("Error getting the service URLs: %s", err)
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, urls)
+	}
+}
+
 func TestGetServiceURLsForService(t *testing.T) {
 	defaultAPI := &tests.MockAPI{
 		Hosts: map[string]*host.Host{
@@ -350,7 +553,7 @@ func TestGetServiceURLsForService(t *testing.T) {
 			},
 		},
 	}
-	defaultTemplate := template.Must(template.New("svc-template").Parse("{{.IP}}:{{.Port}}"))
+	defaultTemplate := template.Must(template.New("svc-template").Parse("http://{{.IP}}:{{.Port}}"))
 
 	var tests = []struct {
 		description string
@@ -404,6 +607,32 @@ func TestGetServiceURLsForService(t *testing.T) {
 	}
 }
 
+// hangingServices never answers, as the API server of a stopped VM doesn't.
+type hangingServices struct {
+	MockServiceInterface
+}
+
+func (s hangingServices) List(opts meta_v1.ListOptions) (*v1.ServiceList, error) {
+	select {}
+}
+
+func TestGetServiceNames(t *testing.T) {
+	defer revertK8sClient(k8s)
+	k8s = &MockClientGetter{servicesMap: serviceNamespaces}
+	names, err := GetServiceNames("default", time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if expected := []string{"mock-dashboard", "mock-dashboard-no-ports"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected service names %v, got %v", expected, names)
+	}
+
+	k8s = &MockClientGetter{servicesMap: map[string]corev1.ServiceInterface{"default": &hangingServices{}}}
+	if names, err := GetServiceNames("default", 10*time.Millisecond); err == nil {
+		t.Errorf("Expected an error from a cluster which doesn't answer, got services %v", names)
+	}
+}
+
 func revertK8sClient(k K8sClient) {
 	k8s = k
 }
@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+const spinnerFrames = `|/-\`
+
+// spinner spins next to a message while minikube waits, when out is a terminal. Otherwise the
+// message is written once, unless minikube is quiet.
+type spinner struct {
+	out     io.Writer
+	message string
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func startSpinner(out io.Writer, message string) *spinner {
+	s := &spinner{out: out, message: message, done: make(chan struct{})}
+	if console.Quiet() {
+		return s
+	}
+	if !console.IsTerminal(out) {
+		fmt.Fprintln(out, message+"...")
+		return s
+	}
+	s.wg.Add(1)
+	go s.spin()
+	return s
+}
+
+func (s *spinner) spin() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		fmt.Fprintf(s.out, "\r%c %s...", spinnerFrames[i%len(spinnerFrames)], s.message)
+		select {
+		case <-s.done:
+			// Clear the line for what is written next.
+			fmt.Fprintf(s.out, "\r%s\r", strings.Repeat(" ", len(s.message)+5))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop stops the spinner, clearing its line.
+func (s *spinner) stop() {
+	close(s.done)
+	s.wg.Wait()
+}
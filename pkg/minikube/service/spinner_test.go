@@ -0,0 +1,49 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+func TestSpinnerNotTerminal(t *testing.T) {
+	defer console.SetQuiet(false)
+
+	var tests = []struct {
+		quiet    bool
+		expected string
+	}{
+		{expected: "Waiting for service default/nginx to be ready...\n"},
+		{quiet: true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("quiet=%t", test.quiet), func(t *testing.T) {
+			console.SetQuiet(test.quiet)
+			var out bytes.Buffer
+			s := startSpinner(&out, "Waiting for service default/nginx to be ready")
+			s.stop()
+			if out.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+)
+
+// CopyOptions are the options of a copy between the host and the VM.
+type CopyOptions struct {
+	// Recursive copies directories along with their contents.
+	Recursive bool
+	// Force overwrites an existing destination.
+	Force bool
+}
+
+// CopyToVM copies src on the host to dst in the VM, over SFTP.
+func CopyToVM(c *sftp.Client, src, dst string, opts CopyOptions) error {
+	return copyPath(localFS{}, sftpFS{c}, src, dst, opts)
+}
+
+// CopyFromVM copies src in the VM to dst on the host, over SFTP.
+func CopyFromVM(c *sftp.Client, src, dst string, opts CopyOptions) error {
+	return copyPath(sftpFS{c}, localFS{}, src, dst, opts)
+}
+
+// fileSystem is a side of a copy, the host's file system or the VM's.
+type fileSystem interface {
+	Stat(path string) (os.FileInfo, error)
+	ReadDir(path string) ([]os.FileInfo, error)
+	Open(path string) (io.ReadCloser, error)
+	Create(path string) (io.WriteCloser, error)
+	Mkdir(path string) error
+	Chmod(path string, mode os.FileMode) error
+	Join(elem ...string) string
+	Base(path string) string
+	String() string
+}
+
+type localFS struct{}
+
+func (localFS) Stat(p string) (os.FileInfo, error)      { return os.Stat(p) }
+func (localFS) ReadDir(p string) ([]os.FileInfo, error) { return ioutil.ReadDir(p) }
+func (localFS) Open(p string) (io.ReadCloser, error)    { return os.Open(p) }
+func (localFS) Mkdir(p string) error                    { return os.Mkdir(p, 0755) }
+func (localFS) Chmod(p string, mode os.FileMode) error  { return os.Chmod(p, mode) }
+func (localFS) Join(elem ...string) string              { return filepath.Join(elem...) }
+func (localFS) Base(p string) string                    { return filepath.Base(p) }
+func (localFS) String() string                          { return "the host" }
+func (localFS) Create(p string) (io.WriteCloser, error) { return os.Create(p) }
+
+type sftpFS struct {
+	c *sftp.Client
+}
+
+func (s sftpFS) Stat(p string) (os.FileInfo, error)      { return s.c.Stat(p) }
+func (s sftpFS) ReadDir(p string) ([]os.FileInfo, error) { return s.c.ReadDir(p) }
+func (s sftpFS) Open(p string) (io.ReadCloser, error)    { return s.c.Open(p) }
+func (s sftpFS) Create(p string) (io.WriteCloser, error) { return s.c.Create(p) }
+func (s sftpFS) Mkdir(p string) error                    { return s.c.Mkdir(p) }
+func (s sftpFS) Chmod(p string, mode os.FileMode) error  { return s.c.Chmod(p, mode) }
+func (s sftpFS) Join(elem ...string) string              { return path.Join(elem...) }
+func (s sftpFS) Base(p string) string                    { return path.Base(p) }
+func (s sftpFS) String() string                          { return "the VM" }
+
+// copyPath copies src to dst, or into dst if it is a directory, refusing to overwrite an
+// existing destination unless forced.
+func copyPath(from, to fileSystem, src, dst string, opts CopyOptions) error {
+	info, err := from.Stat(src)
+	if os.IsNotExist(err) {
+		return errors.Errorf("%s does not exist on %s", src, from)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Error getting info of %s on %s", src, from)
+	}
+	if info.IsDir() && !opts.Recursive {
+		return errors.Errorf("%s is a directory, pass -r to copy it", src)
+	}
+	if existing, err := to.Stat(dst); err == nil && existing.IsDir() {
+		dst = to.Join(dst, from.Base(src))
+	}
+	if _, err := to.Stat(dst); err == nil && !opts.Force {
+		return errors.Errorf("%s already exists on %s, pass -f to overwrite it", dst, to)
+	}
+	return copyEntry(from, to, src, dst, info)
+}
+
+// copyEntry copies the file or directory src to dst, preserving its mode.
+func copyEntry(from, to fileSystem, src, dst string, info os.FileInfo) error {
+	if info.IsDir() {
+		if err := to.Mkdir(dst); err != nil {
+			if existing, serr := to.Stat(dst); serr != nil || !existing.IsDir() {
+				return errors.Wrapf(err, "Error creating directory %s on %s", dst, to)
+			}
+		}
+		entries, err := from.ReadDir(src)
+		if err != nil {
+			return errors.Wrapf(err, "Error reading directory %s on %s", src, from)
+		}
+		for _, e := range entries {
+			child := from.Join(src, e.Name())
+			// Stat follows the symbolic links the directory may hold.
+			childInfo, err := from.Stat(child)
+			if err != nil {
+				return errors.Wrapf(err, "Error getting info of %s on %s", child, from)
+			}
+			if err := copyEntry(from, to, child, to.Join(dst, e.Name()), childInfo); err != nil {
+				return err
+			}
+		}
+		return errors.Wrapf(to.Chmod(dst, info.Mode().Perm()), "Error setting the mode of %s on %s", dst, to)
+	}
+	if !info.Mode().IsRegular() {
+		return errors.Errorf("%s on %s is neither a regular file nor a directory", src, from)
+	}
+
+	r, err := from.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "Error opening %s on %s", src, from)
+	}
+	defer r.Close()
+	w, err := to.Create(dst)
+	if err != nil {
+		return errors.Wrapf(err, "Error creating %s on %s", dst, to)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return errors.Wrapf(err, "Error copying %s to %s", src, dst)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "Error closing %s on %s", dst, to)
+	}
+	return errors.Wrapf(to.Chmod(dst, info.Mode().Perm()), "Error setting the mode of %s on %s", dst, to)
+}
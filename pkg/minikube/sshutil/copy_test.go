@@ -0,0 +1,209 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// newTestSFTPClient returns a client of an SFTP server serving the local file system, which
+// stands in for the VM's.
+func newTestSFTPClient(t *testing.T) *sftp.Client {
+	clientConn, serverConn := net.Pipe()
+	server, err := sftp.NewServer(serverConn)
+	if err != nil {
+		t.Fatalf("Error creating sftp server: %s", err)
+	}
+	go server.Serve()
+	c, err := sftp.NewClientPipe(clientConn, clientConn)
+	if err != nil {
+		t.Fatalf("Error creating sftp client: %s", err)
+	}
+	return c
+}
+
+func writeTestFile(t *testing.T, p, content string, mode os.FileMode) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatalf("Error creating directory: %s", err)
+	}
+	if err := ioutil.WriteFile(p, []byte(content), mode); err != nil {
+		t.Fatalf("Error writing file: %s", err)
+	}
+	if err := os.Chmod(p, mode); err != nil {
+		t.Fatalf("Error setting file mode: %s", err)
+	}
+}
+
+func checkTestFile(t *testing.T, p, content string, mode os.FileMode) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatalf("Error reading copied file: %s", err)
+	}
+	if string(b) != content {
+		t.Errorf("Expected %s to hold %q, got %q", p, content, b)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		t.Fatalf("Error getting info of copied file: %s", err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("Expected %s to have mode %s, got %s", p, mode, info.Mode().Perm())
+	}
+}
+
+func TestCopy(t *testing.T) {
+	c := newTestSFTPClient(t)
+	defer c.Close()
+
+	var cases = []struct {
+		description string
+		toVM        bool
+		src         string
+		dst         string
+		opts        CopyOptions
+		existing    string
+		expected    string
+		err         string
+	}{
+		{
+			description: "file to the VM",
+			toVM:        true,
+			src:         "host/script.sh",
+			dst:         "vm/copied.sh",
+			expected:    "vm/copied.sh",
+		},
+		{
+			description: "file into a directory of the VM",
+			toVM:        true,
+			src:         "host/script.sh",
+			dst:         "vm",
+			expected:    "vm/script.sh",
+		},
+		{
+			description: "file from the VM",
+			src:         "vm/script.sh",
+			dst:         "host",
+			expected:    "host/script.sh",
+		},
+		{
+			description: "existing destination",
+			toVM:        true,
+			src:         "host/script.sh",
+			dst:         "vm",
+			existing:    "vm/script.sh",
+			err:         "already exists on the VM, pass -f to overwrite it",
+		},
+		{
+			description: "existing destination forced",
+			toVM:        true,
+			src:         "host/script.sh",
+			dst:         "vm",
+			opts:        CopyOptions{Force: true},
+			existing:    "vm/script.sh",
+			expected:    "vm/script.sh",
+		},
+		{
+			description: "missing file of the VM",
+			src:         "vm/missing",
+			dst:         "host",
+			err:         "does not exist on the VM",
+		},
+		{
+			description: "directory without -r",
+			toVM:        true,
+			src:         "host",
+			dst:         "vm",
+			err:         "is a directory, pass -r to copy it",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir, err := ioutil.TempDir("", "minikube-cp")
+			if err != nil {
+				t.Fatalf("Error creating temp dir: %s", err)
+			}
+			defer os.RemoveAll(tempDir)
+			for _, dir := range []string{"host", "vm"} {
+				if err := os.Mkdir(filepath.Join(tempDir, dir), 0755); err != nil {
+					t.Fatalf("Error creating directory: %s", err)
+				}
+			}
+			from := "vm"
+			if test.toVM {
+				from = "host"
+			}
+			writeTestFile(t, filepath.Join(tempDir, from, "script.sh"), "echo host", 0750)
+			if test.existing != "" {
+				writeTestFile(t, filepath.Join(tempDir, test.existing), "echo vm", 0600)
+			}
+
+			copyFunc := CopyFromVM
+			if test.toVM {
+				copyFunc = CopyToVM
+			}
+			err = copyFunc(c, filepath.Join(tempDir, test.src), filepath.Join(tempDir, test.dst), test.opts)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("Expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error copying: %s", err)
+			}
+			checkTestFile(t, filepath.Join(tempDir, test.expected), "echo host", 0750)
+		})
+	}
+}
+
+func TestCopyRecursive(t *testing.T) {
+	c := newTestSFTPClient(t)
+	defer c.Close()
+	tempDir, err := ioutil.TempDir("", "minikube-cp")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeTestFile(t, filepath.Join(tempDir, "host", "manifests", "pod.yaml"), "kind: Pod", 0644)
+	writeTestFile(t, filepath.Join(tempDir, "host", "manifests", "bin", "run"), "#!/bin/sh", 0755)
+	if err := os.Mkdir(filepath.Join(tempDir, "vm"), 0755); err != nil {
+		t.Fatalf("Error creating directory: %s", err)
+	}
+
+	src, dst := filepath.Join(tempDir, "host", "manifests"), filepath.Join(tempDir, "vm")
+	if err := CopyToVM(c, src, dst, CopyOptions{Recursive: true}); err != nil {
+		t.Fatalf("Error copying directory: %s", err)
+	}
+	checkTestFile(t, filepath.Join(dst, "manifests", "pod.yaml"), "kind: Pod", 0644)
+	checkTestFile(t, filepath.Join(dst, "manifests", "bin", "run"), "#!/bin/sh", 0755)
+
+	if err := CopyToVM(c, src, dst, CopyOptions{Recursive: true}); err == nil {
+		t.Error("Expected an error copying over the copied directory")
+	}
+	if err := CopyToVM(c, src, dst, CopyOptions{Recursive: true, Force: true}); err != nil {
+		t.Errorf("Error copying over the copied directory with -f: %s", err)
+	}
+}
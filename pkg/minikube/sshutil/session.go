@@ -0,0 +1,258 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// keepAliveRequest is the global request OpenSSH servers answer, even if only to refuse it.
+const keepAliveRequest = "keepalive@openssh.com"
+
+// sessionTimings are how often a Session checks its connection and how long it waits
+// between attempts to re-dial it.
+type sessionTimings struct {
+	keepAlive      time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+var defaultSessionTimings = sessionTimings{
+	keepAlive:      10 * time.Second,
+	initialBackoff: time.Second,
+	maxBackoff:     30 * time.Second,
+}
+
+// Session is an SSH connection to the VM for long-running uses, such as following logs or
+// keeping a mount up. It sends keep-alives, and when the connection dies, for instance
+// because the host slept, it re-dials it with exponential backoff and runs again what was
+// registered with OnConnect, and the commands passed to Stream.
+type Session struct {
+	dial    func() (*ssh.Client, error)
+	timings sessionTimings
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	client    *ssh.Client
+	onConnect []func(*ssh.Client) error
+	closed    bool
+
+	lost chan struct{}
+	done chan struct{}
+}
+
+// NewSession dials the VM of d, returning a Session which keeps the connection up until it is closed.
+func NewSession(d drivers.Driver) (*Session, error) {
+	return newSession(func() (*ssh.Client, error) { return NewSSHClient(d) }, defaultSessionTimings)
+}
+
+func newSession(dial func() (*ssh.Client, error), timings sessionTimings) (*Session, error) {
+	c, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{
+		dial:    dial,
+		timings: timings,
+		client:  c,
+		lost:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.keepAlive()
+	return s, nil
+}
+
+// OnConnect runs f on the current connection, and again on every connection re-dialed after it.
+func (s *Session) OnConnect(f func(*ssh.Client) error) error {
+	c, err := s.waitClient(nil)
+	if err != nil {
+		return err
+	}
+	if err := f(c); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.onConnect = append(s.onConnect, f)
+	s.mu.Unlock()
+	return nil
+}
+
+// Stream runs cmd, streaming its standard output and error to stdout and stderr. When the
+// connection dies while cmd runs, cmd is run again once it is re-dialed. Stream returns
+// when cmd exits on its own or the Session is closed.
+func (s *Session) Stream(cmd string, stdout, stderr io.Writer) error {
+	var prev *ssh.Client
+	for {
+		c, err := s.waitClient(prev)
+		if err != nil {
+			return err
+		}
+		err = streamCommand(c, cmd, stdout, stderr)
+		if _, ok := err.(*ssh.ExitError); ok || err == nil {
+			return err
+		}
+		if alive(c, s.timings.keepAlive) {
+			return errors.Wrapf(err, "Error running %s", cmd)
+		}
+		glog.Warningf("SSH connection lost while running %s: %v", cmd, err)
+		s.connectionLost()
+		prev = c
+	}
+}
+
+func streamCommand(c *ssh.Client, cmd string, stdout, stderr io.Writer) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdout = stdout
+	session.Stderr = stderr
+	return session.Run(cmd)
+}
+
+// Close stops keeping the connection up and closes it.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.cond.Broadcast()
+	if s.client != nil {
+		return s.client.Close()
+	}
+	return nil
+}
+
+// waitClient returns the current connection once it isn't prev, or an error once the Session is closed.
+func (s *Session) waitClient(prev *ssh.Client) (*ssh.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.closed && (s.client == nil || s.client == prev) {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return nil, errors.New("SSH session closed")
+	}
+	return s.client, nil
+}
+
+// connectionLost has the connection re-dialed without waiting for the next keep-alive.
+func (s *Session) connectionLost() {
+	select {
+	case s.lost <- struct{}{}:
+	default:
+	}
+}
+
+// alive returns whether c answers a keep-alive within timeout.
+func alive(c *ssh.Client, timeout time.Duration) bool {
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.SendRequest(keepAliveRequest, true, nil)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		return err == nil
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (s *Session) keepAlive() {
+	ticker := time.NewTicker(s.timings.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.lost:
+		}
+		s.mu.Lock()
+		c := s.client
+		s.mu.Unlock()
+		if alive(c, s.timings.keepAlive) {
+			continue
+		}
+		glog.Warningf("SSH connection to %s lost, reconnecting", c.RemoteAddr())
+		s.mu.Lock()
+		s.client = nil
+		s.mu.Unlock()
+		c.Close()
+		if !s.reconnect() {
+			return
+		}
+	}
+}
+
+// reconnect re-dials the connection until it succeeds, doubling the wait between attempts up to
+// the maximum backoff, and returns false if the Session was closed meanwhile.
+func (s *Session) reconnect() bool {
+	backoff := s.timings.initialBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-s.done:
+			return false
+		case <-time.After(backoff):
+		}
+		c, err := s.dial()
+		if err == nil {
+			if err = s.connected(c); err == nil {
+				glog.Infof("Reconnected to %s after %d attempt(s)", c.RemoteAddr(), attempt)
+				return true
+			}
+			c.Close()
+		}
+		if backoff *= 2; backoff > s.timings.maxBackoff {
+			backoff = s.timings.maxBackoff
+		}
+		glog.Infof("Reconnect attempt %d failed: %v. Retrying in %s", attempt, err, backoff)
+	}
+}
+
+// connected runs the OnConnect functions on the re-dialed connection c and makes it the Session's.
+func (s *Session) connected(c *ssh.Client) error {
+	s.mu.Lock()
+	onConnect := append([]func(*ssh.Client) error{}, s.onConnect...)
+	s.mu.Unlock()
+	for _, f := range onConnect {
+		if err := f(c); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("SSH session closed")
+	}
+	s.client = c
+	s.cond.Broadcast()
+	return nil
+}
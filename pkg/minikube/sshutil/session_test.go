@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"golang.org/x/crypto/ssh"
+
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+var testSessionTimings = sessionTimings{
+	keepAlive:      50 * time.Millisecond,
+	initialBackoff: 10 * time.Millisecond,
+	maxBackoff:     100 * time.Millisecond,
+}
+
+func newTestSession(t *testing.T) (*Session, *tests.SSHServer) {
+	s, err := tests.NewSSHServer()
+	if err != nil {
+		t.Fatalf("Error creating ssh server: %s", err)
+	}
+	port, err := s.Start()
+	if err != nil {
+		t.Fatalf("Error starting ssh server: %s", err)
+	}
+	d := &tests.MockDriver{
+		Port:       port,
+		BaseDriver: drivers.BaseDriver{IPAddress: "127.0.0.1"},
+	}
+	session, err := newSession(func() (*ssh.Client, error) { return NewSSHClient(d) }, testSessionTimings)
+	if err != nil {
+		t.Fatalf("Error creating session: %s", err)
+	}
+	return session, s
+}
+
+func TestSessionReconnects(t *testing.T) {
+	session, s := newTestSession(t)
+	defer session.Close()
+
+	var connects int32
+	if err := session.OnConnect(func(c *ssh.Client) error {
+		atomic.AddInt32(&connects, 1)
+		return RunCommand(c, "sudo mount")
+	}); err != nil {
+		t.Fatalf("Error running on connect: %s", err)
+	}
+	if n := atomic.LoadInt32(&connects); n != 1 {
+		t.Fatalf("Expected the function to run once, ran %d times", n)
+	}
+
+	s.CloseConnections()
+	// The keep-alive notices the dead connection, and the first attempt to re-dial it succeeds.
+	budget := 10 * (testSessionTimings.keepAlive + testSessionTimings.maxBackoff)
+	deadline := time.Now().Add(budget)
+	for atomic.LoadInt32(&connects) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the session to reconnect within %s", budget)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.SetCommandToOutput(map[string]string{"journalctl -f": "-- Logs begin --\n"})
+	var stdout bytes.Buffer
+	if err := session.Stream("journalctl -f", &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Error streaming over the re-dialed connection: %s", err)
+	}
+	if stdout.String() != "-- Logs begin --\n" {
+		t.Errorf("Expected the command's output, got %q", stdout.String())
+	}
+}
+
+func TestSessionClose(t *testing.T) {
+	session, s := newTestSession(t)
+	session.Close()
+	s.CloseConnections()
+
+	if err := session.Stream("journalctl -f", &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected an error streaming over a closed session")
+	}
+	if err := session.OnConnect(func(*ssh.Client) error { return nil }); err == nil {
+		t.Error("Expected an error registering a function on a closed session")
+	}
+}
@@ -20,7 +20,7 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"path/filepath"
+	"path"
 	"strconv"
 	"sync"
 
@@ -29,6 +29,8 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/console"
+	"k8s.io/minikube/pkg/util"
 )
 
 // SSHSession provides methods for running commands on a host.
@@ -88,11 +90,12 @@ func TransferFile(f assets.CopyableFile, client *ssh.Client) error {
 		f.GetPermissions(), client)
 }
 
-// Transfer uses an SSH session to copy a file to the remote machine.
+// Transfer uses an SSH session to copy a file to the remote machine. remotedir is a path of the
+// VM, which is joined with slashes whatever the host's separator.
 func Transfer(reader io.Reader, readerLen int, remotedir, filename string, perm string, c *ssh.Client) error {
 	// Delete the old file first. This makes sure permissions get reset.
-	deleteCmd := fmt.Sprintf("sudo rm -f %s", filepath.Join(remotedir, filename))
-	mkdirCmd := fmt.Sprintf("sudo mkdir -p %s", remotedir)
+	deleteCmd := fmt.Sprintf("sudo rm -f %s", util.ShellQuote(path.Join(remotedir, filename)))
+	mkdirCmd := fmt.Sprintf("sudo mkdir -p %s", util.ShellQuote(remotedir))
 	for _, cmd := range []string{deleteCmd, mkdirCmd} {
 		if err := RunCommand(c, cmd); err != nil {
 			return errors.Wrapf(err, "Error running command: %s", cmd)
@@ -121,8 +124,11 @@ func Transfer(reader io.Reader, readerLen int, remotedir, filename string, perm
 		fmt.Fprint(w, "\x00")
 	}()
 
-	scpcmd := fmt.Sprintf("sudo scp -t %s", remotedir)
-	if err := s.Run(scpcmd); err != nil {
+	scpcmd := fmt.Sprintf("sudo scp -t %s", util.ShellQuote(remotedir))
+	r := console.StartCommand("the VM", fmt.Sprintf("%s # %s, %d bytes", scpcmd, filename, readerLen))
+	err = s.Run(scpcmd)
+	r.Done(nil, err)
+	if err != nil {
 		return errors.Wrap(err, "Error running scp command")
 	}
 	wg.Wait()
@@ -132,12 +138,29 @@ func Transfer(reader io.Reader, readerLen int, remotedir, filename string, perm
 
 func RunCommand(c *ssh.Client, cmd string) error {
 	s, err := c.NewSession()
-	defer s.Close()
 	if err != nil {
 		return errors.Wrap(err, "Error creating new session for ssh client")
 	}
+	defer s.Close()
+
+	r := console.StartCommand("the VM", cmd)
+	out, err := s.CombinedOutput(cmd)
+	r.Done(out, err)
+	return err
+}
+
+// RunCommandOutput runs cmd on the remote machine and returns its standard output.
+func RunCommandOutput(c *ssh.Client, cmd string) (string, error) {
+	s, err := c.NewSession()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating new session for ssh client")
+	}
+	defer s.Close()
 
-	return s.Run(cmd)
+	r := console.StartCommand("the VM", cmd)
+	out, err := s.Output(cmd)
+	r.Done(out, err)
+	return string(out), err
 }
 
 type sshHost struct {
@@ -170,5 +193,5 @@ func DeleteFile(f assets.CopyableFile, client *ssh.Client) error {
 }
 
 func GetDeleteFileCommand(f assets.CopyableFile) string {
-	return fmt.Sprintf("sudo rm %s", filepath.Join(f.GetTargetDir(), f.GetTargetName()))
+	return fmt.Sprintf("sudo rm %s", util.ShellQuote(path.Join(f.GetTargetDir(), f.GetTargetName())))
 }
@@ -114,4 +114,14 @@ func TestTransfer(t *testing.T) {
 	if err := Transfer(bytes.NewReader(contents), len(contents), "/tmp", dest, "0777", c); err != nil {
 		t.Fatalf("Unexpected error: %s", err)
 	}
+
+	// The paths of files synced from a MINIKUBE_HOME with spaces in it are quoted.
+	if err := Transfer(bytes.NewReader(contents), len(contents), "/etc/First Last", "my file", "0644", c); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for _, cmd := range []string{"sudo rm -f '/etc/First Last/my file'", "sudo mkdir -p '/etc/First Last'", "sudo scp -t '/etc/First Last'"} {
+		if _, ok := s.Commands[cmd]; !ok {
+			t.Errorf("Expected command: %s, got %v", cmd, s.Commands)
+		}
+	}
 }
@@ -19,6 +19,7 @@ package tests
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/auth"
@@ -34,6 +35,8 @@ type MockAPI struct {
 	CreateError bool
 	RemoveError bool
 	SaveCalled  bool
+	// LoadError, if set, is returned when loading an existing host.
+	LoadError error
 }
 
 func NewMockAPI() *MockAPI {
@@ -80,7 +83,12 @@ func (api *MockAPI) Exists(name string) (bool, error) {
 
 // List the existing hosts.
 func (api *MockAPI) List() ([]string, error) {
-	return []string{}, nil
+	names := []string{}
+	for name := range api.Hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
 // Load loads a host from disk.
@@ -92,6 +100,9 @@ func (api *MockAPI) Load(name string) (*host.Host, error) {
 		}
 
 	}
+	if api.LoadError != nil {
+		return nil, api.LoadError
+	}
 	return h, nil
 }
 
@@ -0,0 +1,49 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+// VBoxConfig is the driver config of a virtualbox machine, as libmachine stores it.
+const VBoxConfig = `
+{
+        "IPAddress": "192.168.99.101",
+        "MachineName": "minikube",
+        "SSHUser": "docker",
+        "SSHPort": 33627,
+        "SSHKeyPath": "/home/sundarp/.minikube/machines/minikube/id_rsa",
+        "StorePath": "/home/sundarp/.minikube",
+        "SwarmMaster": false,
+        "SwarmHost": "",
+        "SwarmDiscovery": "",
+        "VBoxManager": {},
+        "HostInterfaces": {},
+        "CPU": 4,
+        "Memory": 16384,
+        "DiskSize": 20000,
+        "NatNicType": "82540EM",
+        "Boot2DockerURL": "file:///home/sundarp/.minikube/cache/iso/minikube-v1.0.6.iso",
+        "Boot2DockerImportVM": "",
+        "HostDNSResolver": false,
+        "HostOnlyCIDR": "192.168.99.1/24",
+        "HostOnlyNicType": "82540EM",
+        "HostOnlyPromiscMode": "deny",
+        "UIType": "headless",
+        "HostOnlyNoDHCP": false,
+        "NoShare": false,
+        "DNSProxy": true,
+        "NoVTXCheck": false
+}
+`
@@ -18,6 +18,7 @@ package tests
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/mcnflag"
@@ -31,6 +32,12 @@ type MockDriver struct {
 	RemoveError  bool
 	HostError    bool
 	Port         int
+	// StateError, if set, is returned by GetState.
+	StateError error
+	// StartError, if set, is returned by Start.
+	StartError error
+	// RemoveErr, if set, is returned by Remove.
+	RemoveErr error
 }
 
 // Create creates a MockDriver instance
@@ -70,6 +77,9 @@ func (driver *MockDriver) GetSSHKeyPath() string {
 
 // GetState returns the state of the driver
 func (driver *MockDriver) GetState() (state.State, error) {
+	if driver.StateError != nil {
+		return state.Error, driver.StateError
+	}
 	return driver.CurrentState, nil
 }
 
@@ -86,6 +96,9 @@ func (driver *MockDriver) Kill() error {
 
 // Remove removes the machine
 func (driver *MockDriver) Remove() error {
+	if driver.RemoveErr != nil {
+		return driver.RemoveErr
+	}
 	if driver.RemoveError {
 		return fmt.Errorf("Error deleting machine.")
 	}
@@ -105,6 +118,9 @@ func (driver *MockDriver) SetConfigFromFlags(opts drivers.DriverOptions) error {
 
 // Start starts the machine
 func (driver *MockDriver) Start() error {
+	if driver.StartError != nil {
+		return driver.StartError
+	}
 	driver.CurrentState = state.Running
 	return nil
 }
@@ -114,3 +130,184 @@ func (driver *MockDriver) Stop() error {
 	driver.CurrentState = state.Stopped
 	return nil
 }
+
+// FlakyDriver is a MockDriver, named Name, whose Start and Stop fail with
+// Err the first Failures times they are called.
+type FlakyDriver struct {
+	MockDriver
+	Name     string
+	Failures int
+	Err      error
+	// Attempts counts the calls to Start and Stop.
+	Attempts int
+}
+
+// DriverName returns the name of the driver
+func (driver *FlakyDriver) DriverName() string {
+	return driver.Name
+}
+
+// Start starts the machine
+func (driver *FlakyDriver) Start() error {
+	driver.Attempts++
+	if driver.Attempts <= driver.Failures {
+		return driver.Err
+	}
+	return driver.MockDriver.Start()
+}
+
+// Stop stops the machine
+func (driver *FlakyDriver) Stop() error {
+	driver.Attempts++
+	if driver.Attempts <= driver.Failures {
+		return driver.Err
+	}
+	return driver.MockDriver.Stop()
+}
+
+// ResizableDriver is a MockDriver whose VM can be resized while it is stopped.
+type ResizableDriver struct {
+	MockDriver
+	Memory int
+	CPU    int
+}
+
+// Resize sets the memory and CPUs of the stopped machine
+func (driver *ResizableDriver) Resize(memoryMB, cpus int) error {
+	if driver.CurrentState != state.Stopped {
+		return fmt.Errorf("Machine must be stopped to be resized, it is %s", driver.CurrentState)
+	}
+	if memoryMB > 0 {
+		driver.Memory = memoryMB
+	}
+	if cpus > 0 {
+		driver.CPU = cpus
+	}
+	return nil
+}
+
+// HangingDriver is a MockDriver whose Create and Start block, like those of a
+// wedged VM, until Remove or Release is called.
+type HangingDriver struct {
+	MockDriver
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	removed bool
+}
+
+// NewHangingDriver returns a HangingDriver which blocks until released.
+func NewHangingDriver() *HangingDriver {
+	return &HangingDriver{release: make(chan struct{})}
+}
+
+// Create blocks until the driver is released
+func (driver *HangingDriver) Create() error {
+	<-driver.release
+	return fmt.Errorf("Machine was removed while it was being created")
+}
+
+// Start blocks until the driver is released
+func (driver *HangingDriver) Start() error {
+	<-driver.release
+	return fmt.Errorf("Machine was removed while it was being started")
+}
+
+// Remove releases the driver and records that the machine was removed
+func (driver *HangingDriver) Remove() error {
+	driver.mu.Lock()
+	driver.removed = true
+	driver.mu.Unlock()
+	driver.Release()
+	return nil
+}
+
+// Release unblocks the driver's Create and Start
+func (driver *HangingDriver) Release() {
+	driver.once.Do(func() { close(driver.release) })
+}
+
+// Removed returns whether Remove was called
+func (driver *HangingDriver) Removed() bool {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	return driver.removed
+}
+
+// WedgedDriver is a MockDriver recording how it is stopped, whose VM may ignore
+// being shut down, like one whose ACPI handling is wedged.
+type WedgedDriver struct {
+	MockDriver
+	// IgnoresPoweroff makes Poweroff leave the machine running.
+	IgnoresPoweroff bool
+	// StopHangs makes Stop block until the machine is killed.
+	StopHangs bool
+	mu        sync.Mutex
+	calls     []string
+	killed    chan struct{}
+	killOnce  sync.Once
+}
+
+// NewWedgedDriver returns a running WedgedDriver.
+func NewWedgedDriver() *WedgedDriver {
+	d := &WedgedDriver{killed: make(chan struct{})}
+	d.CurrentState = state.Running
+	return d
+}
+
+func (driver *WedgedDriver) record(call string, s state.State) {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	driver.calls = append(driver.calls, call)
+	if s != state.None {
+		driver.CurrentState = s
+	}
+}
+
+// Calls returns the calls made to start and stop the machine, in order.
+func (driver *WedgedDriver) Calls() []string {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	return append([]string{}, driver.calls...)
+}
+
+// GetState returns the state of the driver
+func (driver *WedgedDriver) GetState() (state.State, error) {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	return driver.CurrentState, nil
+}
+
+// Poweroff shuts the machine down like running poweroff in the guest
+func (driver *WedgedDriver) Poweroff() error {
+	if driver.IgnoresPoweroff {
+		driver.record("poweroff", state.None)
+		return nil
+	}
+	driver.record("poweroff", state.Stopped)
+	return nil
+}
+
+// Stop stops the machine, or blocks until it is killed
+func (driver *WedgedDriver) Stop() error {
+	if driver.StopHangs {
+		driver.record("stop", state.Stopping)
+		<-driver.killed
+		return fmt.Errorf("Machine was killed while it was being stopped")
+	}
+	driver.record("stop", state.Stopped)
+	return nil
+}
+
+// Kill kills the machine
+func (driver *WedgedDriver) Kill() error {
+	driver.record("kill", state.Stopped)
+	driver.killOnce.Do(func() { close(driver.killed) })
+	return nil
+}
+
+// Start starts the machine
+func (driver *WedgedDriver) Start() error {
+	driver.record("start", state.Running)
+	return nil
+}
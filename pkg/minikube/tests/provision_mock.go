@@ -29,6 +29,8 @@ import (
 // Provisioner defines distribution specific actions
 type MockProvisioner struct {
 	Provisioned bool
+	// ProvisionError, if set, is returned by Provision.
+	ProvisionError error
 }
 
 func (provisioner *MockProvisioner) String() string {
@@ -78,6 +80,9 @@ func (provisioner *MockProvisioner) AttemptIPContact(dockerPort int) {
 }
 
 func (provisioner *MockProvisioner) Provision(swarmOptions swarm.Options, authOptions auth.Options, engineOptions engine.Options) error {
+	if provisioner.ProvisionError != nil {
+		return provisioner.ProvisionError
+	}
 	provisioner.Provisioned = true
 	return nil
 }
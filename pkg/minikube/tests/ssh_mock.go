@@ -24,6 +24,7 @@ import (
 	"io"
 	"net"
 	"strconv"
+	"sync"
 	"sync/atomic"
 
 	"github.com/golang/glog"
@@ -38,11 +39,20 @@ type SSHServer struct {
 	Commands  map[string]int
 	Connected bool
 	Transfers *bytes.Buffer
+	// HostKey is the public key the server presents.
+	HostKey ssh.PublicKey
 	// Only access this with atomic ops
 	hadASessionRequested int32
 	// commandsToOutput can be used to mock what the SSHServer returns for a given command
 	// Only access this with atomic ops
 	commandToOutput atomic.Value
+	// commandToStderr and commandToExitStatus mock the standard error and exit status of a command
+	// Only access these with atomic ops
+	commandToStderr     atomic.Value
+	commandToExitStatus atomic.Value
+
+	mu    sync.Mutex
+	conns []net.Conn
 }
 
 // NewSSHServer returns a NewSSHServer instance, ready for use.
@@ -63,8 +73,11 @@ func NewSSHServer() (*SSHServer, error) {
 		return nil, errors.Wrap(err, "Error creating signer from key")
 	}
 	s.Config.AddHostKey(signer)
+	s.HostKey = signer.PublicKey()
 	s.SetSessionRequested(false)
 	s.SetCommandToOutput(map[string]string{})
+	s.SetCommandToStderr(map[string]string{})
+	s.SetCommandToExitStatus(map[string]int{})
 	return s, nil
 }
 
@@ -72,6 +85,10 @@ type execRequest struct {
 	Command string
 }
 
+type exitStatusRequest struct {
+	Status uint32
+}
+
 // Start starts the mock SSH Server, and returns the port it's listening on.
 func (s *SSHServer) Start() (int, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -87,6 +104,9 @@ func (s *SSHServer) Start() (int, error) {
 				if err != nil {
 					return
 				}
+				s.mu.Lock()
+				s.conns = append(s.conns, nConn)
+				s.mu.Unlock()
 
 				_, chans, reqs, err := ssh.NewServerConn(nConn, s.Config)
 				if err != nil {
@@ -121,7 +141,11 @@ func (s *SSHServer) Start() (int, error) {
 					if val, err := s.GetCommandToOutput(cmd.Command); err == nil {
 						channel.Write([]byte(val))
 					}
-					channel.SendRequest("exit-status", false, []byte{0, 0, 0, 0})
+					if val, ok := s.commandToStderr.Load().(map[string]string)[cmd.Command]; ok {
+						channel.Stderr().Write([]byte(val))
+					}
+					status := exitStatusRequest{Status: uint32(s.commandToExitStatus.Load().(map[string]int)[cmd.Command])}
+					channel.SendRequest("exit-status", false, ssh.Marshal(&status))
 
 					// Store anything that comes in over stdin.
 					io.Copy(s.Transfers, channel)
@@ -143,10 +167,30 @@ func (s *SSHServer) Start() (int, error) {
 	return port, nil
 }
 
+// CloseConnections closes the server side of the connections accepted so far, as a dropped network would.
+func (s *SSHServer) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
 func (s *SSHServer) SetCommandToOutput(cmdToOutput map[string]string) {
 	s.commandToOutput.Store(cmdToOutput)
 }
 
+// SetCommandToStderr sets what the SSHServer writes to the standard error of the given commands.
+func (s *SSHServer) SetCommandToStderr(cmdToStderr map[string]string) {
+	s.commandToStderr.Store(cmdToStderr)
+}
+
+// SetCommandToExitStatus sets the exit status the SSHServer returns for the given commands, 0 for the others.
+func (s *SSHServer) SetCommandToExitStatus(cmdToExitStatus map[string]int) {
+	s.commandToExitStatus.Store(cmdToExitStatus)
+}
+
 func (s *SSHServer) GetCommandToOutput(cmd string) (string, error) {
 	cmdMap := s.commandToOutput.Load().(map[string]string)
 	val, ok := cmdMap[cmd]
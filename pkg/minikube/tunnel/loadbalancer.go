@@ -0,0 +1,178 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// rewatchInterval is how long the controller waits before watching the services again once
+// their watch ended.
+var rewatchInterval = time.Second
+
+// loadBalancerController gives the services of type LoadBalancer their cluster IP as ingress,
+// which the tunnel routes to, since no cloud provider gives them one in minikube.
+type loadBalancerController struct {
+	services corev1.ServicesGetter
+	// patched are the namespace/name of the services given their cluster IP.
+	patched map[string]bool
+	// onPatch is called with the patched services whenever one is added.
+	onPatch func(patched []string)
+}
+
+func newLoadBalancerController(services corev1.ServicesGetter, onPatch func([]string)) *loadBalancerController {
+	return &loadBalancerController{services: services, patched: map[string]bool{}, onPatch: onPatch}
+}
+
+func serviceKey(s *v1.Service) string {
+	return s.Namespace + "/" + s.Name
+}
+
+// hasClusterIPIngress returns whether the only ingress of s is its cluster IP.
+func hasClusterIPIngress(s *v1.Service) bool {
+	ingress := s.Status.LoadBalancer.Ingress
+	return len(ingress) == 1 && ingress[0].IP == s.Spec.ClusterIP && ingress[0].Hostname == ""
+}
+
+// patch gives s its cluster IP as ingress if it is a LoadBalancer service without any.
+func (c *loadBalancerController) patch(s *v1.Service) error {
+	if s.Spec.Type != v1.ServiceTypeLoadBalancer || s.Spec.ClusterIP == "" || s.Spec.ClusterIP == v1.ClusterIPNone {
+		return nil
+	}
+	key := serviceKey(s)
+	switch {
+	case hasClusterIPIngress(s):
+	case len(s.Status.LoadBalancer.Ingress) == 0:
+		s.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: s.Spec.ClusterIP}}
+		if _, err := c.services.Services(s.Namespace).UpdateStatus(s); err != nil {
+			return errors.Wrapf(err, "Error updating the status of service %s", key)
+		}
+		glog.Infof("Gave service %s the ingress %s", key, s.Spec.ClusterIP)
+	default:
+		// Something else gave the service its ingress.
+		return nil
+	}
+	if !c.patched[key] {
+		c.patched[key] = true
+		if c.onPatch != nil {
+			c.onPatch(c.patchedServices())
+		}
+	}
+	return nil
+}
+
+func (c *loadBalancerController) patchedServices() []string {
+	var keys []string
+	for key := range c.patched {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sync patches the LoadBalancer services of every namespace.
+func (c *loadBalancerController) sync() error {
+	list, err := c.services.Services(meta_v1.NamespaceAll).List(meta_v1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "Error listing services")
+	}
+	var patchErr error
+	for i := range list.Items {
+		if err := c.patch(&list.Items[i]); err != nil {
+			patchErr = err
+		}
+	}
+	return patchErr
+}
+
+// run patches the LoadBalancer services as they are created or modified, until stop is closed.
+func (c *loadBalancerController) run(stop <-chan struct{}) {
+	for {
+		if err := c.sync(); err != nil {
+			glog.Errorf("Error patching the LoadBalancer services: %s", err)
+		}
+		if w, err := c.services.Services(meta_v1.NamespaceAll).Watch(meta_v1.ListOptions{}); err != nil {
+			glog.Errorf("Error watching services: %s", err)
+		} else if !c.handle(w, stop) {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(rewatchInterval):
+		}
+	}
+}
+
+// handle patches the services of the events of w, returning false once stop is closed and
+// true when the watch ends.
+func (c *loadBalancerController) handle(w watch.Interface, stop <-chan struct{}) bool {
+	defer w.Stop()
+	for {
+		select {
+		case <-stop:
+			return false
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return true
+			}
+			s, isService := e.Object.(*v1.Service)
+			if !isService || (e.Type != watch.Added && e.Type != watch.Modified) {
+				continue
+			}
+			if err := c.patch(s); err != nil {
+				glog.Errorf("Error patching the LoadBalancer service: %s", err)
+			}
+		}
+	}
+}
+
+// restoreServices removes the cluster IP ingress the services of keys, namespace/name, were
+// given, leaving the ones whose ingress has changed since.
+func restoreServices(services corev1.ServicesGetter, keys []string) error {
+	var restoreErr error
+	for _, key := range keys {
+		namespace, name := splitServiceKey(key)
+		s, err := services.Services(namespace).Get(name, meta_v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err == nil && hasClusterIPIngress(s) {
+			s.Status.LoadBalancer.Ingress = nil
+			_, err = services.Services(namespace).UpdateStatus(s)
+		}
+		if err != nil {
+			restoreErr = errors.Wrapf(err, "Error restoring the status of service %s", key)
+		}
+	}
+	return restoreErr
+}
+
+func splitServiceKey(key string) (string, string) {
+	if parts := strings.SplitN(key, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", key
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// mockServices holds services by namespace/name, which are watched through watcher.
+type mockServices struct {
+	services map[string]*v1.Service
+	updates  int
+	watcher  *watch.FakeWatcher
+}
+
+func (m *mockServices) Services(namespace string) corev1.ServiceInterface {
+	return &mockServiceInterface{mock: m, namespace: namespace}
+}
+
+type mockServiceInterface struct {
+	fake.FakeServices
+	mock      *mockServices
+	namespace string
+}
+
+func (s *mockServiceInterface) List(opts meta_v1.ListOptions) (*v1.ServiceList, error) {
+	list := &v1.ServiceList{}
+	for _, svc := range s.mock.services {
+		if s.namespace == meta_v1.NamespaceAll || svc.Namespace == s.namespace {
+			list.Items = append(list.Items, *svc)
+		}
+	}
+	return list, nil
+}
+
+func (s *mockServiceInterface) Get(name string, _ meta_v1.GetOptions) (*v1.Service, error) {
+	svc, ok := s.mock.services[s.namespace+"/"+name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, name)
+	}
+	copied := *svc
+	return &copied, nil
+}
+
+func (s *mockServiceInterface) UpdateStatus(svc *v1.Service) (*v1.Service, error) {
+	s.mock.updates++
+	copied := *svc
+	s.mock.services[serviceKey(svc)] = &copied
+	return svc, nil
+}
+
+func (s *mockServiceInterface) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+	return s.mock.watcher, nil
+}
+
+func newService(namespace, name string, serviceType v1.ServiceType, ingress ...string) *v1.Service {
+	s := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1.ServiceSpec{Type: serviceType, ClusterIP: "10.0.0.42"},
+	}
+	for _, ip := range ingress {
+		s.Status.LoadBalancer.Ingress = append(s.Status.LoadBalancer.Ingress, v1.LoadBalancerIngress{IP: ip})
+	}
+	return s
+}
+
+func newMockServices(services ...*v1.Service) *mockServices {
+	m := &mockServices{services: map[string]*v1.Service{}, watcher: watch.NewFake()}
+	for _, s := range services {
+		m.services[serviceKey(s)] = s
+	}
+	return m
+}
+
+func ingressOf(m *mockServices, key string) []v1.LoadBalancerIngress {
+	return m.services[key].Status.LoadBalancer.Ingress
+}
+
+func TestLoadBalancerControllerSync(t *testing.T) {
+	m := newMockServices(
+		newService("default", "web", v1.ServiceTypeLoadBalancer),
+		newService("kube-system", "patched", v1.ServiceTypeLoadBalancer, "10.0.0.42"),
+		newService("default", "external", v1.ServiceTypeLoadBalancer, "203.0.113.7"),
+		newService("default", "internal", v1.ServiceTypeClusterIP),
+	)
+	var reported []string
+	c := newLoadBalancerController(m, func(patched []string) { reported = patched })
+	if err := c.sync(); err != nil {
+		t.Fatalf("Error syncing: %s", err)
+	}
+
+	if ingress := ingressOf(m, "default/web"); len(ingress) != 1 || ingress[0].IP != "10.0.0.42" {
+		t.Errorf("Expected default/web given its cluster IP, got %v", ingress)
+	}
+	if m.updates != 1 {
+		t.Errorf("Expected only default/web updated, got %d updates", m.updates)
+	}
+	if ingress := ingressOf(m, "default/external"); len(ingress) != 1 || ingress[0].IP != "203.0.113.7" {
+		t.Errorf("Expected default/external left alone, got %v", ingress)
+	}
+	if ingress := ingressOf(m, "default/internal"); len(ingress) != 0 {
+		t.Errorf("Expected default/internal left alone, got %v", ingress)
+	}
+	sort.Strings(reported)
+	if len(reported) != 2 || reported[0] != "default/web" || reported[1] != "kube-system/patched" {
+		t.Errorf("Expected default/web and kube-system/patched reported, got %v", reported)
+	}
+
+	m.services["default/moved"] = newService("default", "moved", v1.ServiceTypeLoadBalancer, "203.0.113.8")
+	keys := append(reported, "default/moved", "default/deleted")
+	if err := restoreServices(m, keys); err != nil {
+		t.Fatalf("Error restoring services: %s", err)
+	}
+	for _, key := range []string{"default/web", "kube-system/patched"} {
+		if ingress := ingressOf(m, key); len(ingress) != 0 {
+			t.Errorf("Expected the ingress of %s removed, got %v", key, ingress)
+		}
+	}
+	if ingress := ingressOf(m, "default/moved"); len(ingress) != 1 {
+		t.Errorf("Expected the ingress of default/moved left, got %v", ingress)
+	}
+}
+
+func TestLoadBalancerControllerWatch(t *testing.T) {
+	m := newMockServices()
+	patched := make(chan []string, 1)
+	c := newLoadBalancerController(m, func(p []string) { patched <- p })
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		c.run(stop)
+		close(done)
+	}()
+
+	m.watcher.Add(newService("default", "internal", v1.ServiceTypeNodePort))
+	m.watcher.Add(newService("default", "web", v1.ServiceTypeLoadBalancer))
+	select {
+	case p := <-patched:
+		if len(p) != 1 || p[0] != "default/web" {
+			t.Errorf("Expected default/web patched, got %v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the created service to be patched")
+	}
+	if ingress := ingressOf(m, "default/web"); len(ingress) != 1 || ingress[0].IP != "10.0.0.42" {
+		t.Errorf("Expected default/web given its cluster IP, got %v", ingress)
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the controller to stop")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// Route sends the packets to the IPs of Destination through Gateway.
+type Route struct {
+	Destination *net.IPNet
+	// Gateway is nil for the routes of the networks the host is directly attached to.
+	Gateway net.IP
+}
+
+func (r Route) String() string {
+	return fmt.Sprintf("%s via %s", r.Destination, r.Gateway)
+}
+
+// same returns whether r and o route the same destination the same way.
+func (r Route) same(o Route) bool {
+	return r.Destination.String() == o.Destination.String() && r.Gateway.Equal(o.Gateway)
+}
+
+// checkRoute checks that route can be added to the route table, returning whether it is in it
+// already. Routes of broader networks, such as the default one, are fine since the more
+// specific route takes precedence over them. A route of the same destination through another
+// gateway, or of a narrower network, which would take part of the destination over, conflicts.
+func checkRoute(table []Route, route Route) (bool, error) {
+	ones, _ := route.Destination.Mask.Size()
+	for _, r := range table {
+		if r.same(route) {
+			return true, nil
+		}
+		if !r.Destination.Contains(route.Destination.IP) && !route.Destination.Contains(r.Destination.IP) {
+			continue
+		}
+		if existing, _ := r.Destination.Mask.Size(); existing < ones {
+			glog.Infof("Route %s is more specific than %s", route, r)
+			continue
+		}
+		return false, errors.Errorf("The route %s conflicts with the existing route %s. Remove it first", route, r)
+	}
+	return false, nil
+}
+
+// routeTable returns the routes of the host's IPv4 route table.
+func routeTable() ([]Route, error) {
+	args := routeTableCommand()
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error running %s", strings.Join(args, " "))
+	}
+	return parseRouteTable(string(out)), nil
+}
+
+// parseIPv4Net parses the destination of a route, a single IP having a /32 mask.
+func parseIPv4Net(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		s += "/32"
+	}
+	ip, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	if ip.To4() == nil {
+		return nil, errors.Errorf("%s is not an IPv4 network", s)
+	}
+	return ipNet, nil
+}
+
+// parseLinuxRoutes parses the output of "ip -4 route show", lines such as
+// "10.0.0.0/24 via 192.168.99.100 dev vboxnet0".
+func parseLinuxRoutes(out string) []Route {
+	var routes []Route
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		dest := fields[0]
+		if dest == "default" {
+			dest = "0.0.0.0/0"
+		}
+		ipNet, err := parseIPv4Net(dest)
+		if err != nil {
+			continue
+		}
+		r := Route{Destination: ipNet}
+		for i := 1; i < len(fields)-1; i++ {
+			if fields[i] == "via" {
+				r.Gateway = net.ParseIP(fields[i+1])
+			}
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// parseDarwinRoutes parses the output of "netstat -nr -f inet", whose destinations leave out
+// their trailing zero bytes, as in "10/24" or "192.168.64", and whose gateways are interfaces,
+// as in "link#17", for the routes of the attached networks.
+func parseDarwinRoutes(out string) []Route {
+	var routes []Route
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		dest := fields[0]
+		if dest == "default" {
+			dest = "0/0"
+		}
+		parts := strings.SplitN(dest, "/", 2)
+		bytes := strings.Split(parts[0], ".")
+		if len(bytes) > 4 {
+			continue
+		}
+		mask := strconv.Itoa(8 * len(bytes))
+		if len(parts) == 2 {
+			mask = parts[1]
+		}
+		for len(bytes) < 4 {
+			bytes = append(bytes, "0")
+		}
+		ipNet, err := parseIPv4Net(strings.Join(bytes, ".") + "/" + mask)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, Route{Destination: ipNet, Gateway: net.ParseIP(fields[1])})
+	}
+	return routes
+}
+
+// parseWindowsRoutes parses the active routes of "route print -4", lines of a destination,
+// netmask, gateway, interface and metric, the gateway being "On-link" for the attached networks.
+func parseWindowsRoutes(out string) []Route {
+	var routes []Route
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+		ip, mask := net.ParseIP(fields[0]).To4(), net.ParseIP(fields[1]).To4()
+		if ip == nil || mask == nil {
+			continue
+		}
+		ipMask := net.IPMask(mask)
+		routes = append(routes, Route{
+			Destination: &net.IPNet{IP: ip.Mask(ipMask), Mask: ipMask},
+			Gateway:     net.ParseIP(fields[2]),
+		})
+	}
+	return routes
+}
+
+// addRoute adds route to the host's route table, which takes privileges.
+func addRoute(route Route) error {
+	return runPrivileged(addRouteCommand(route))
+}
+
+// deleteRoute removes route from the host's route table.
+func deleteRoute(route Route) error {
+	return runPrivileged(deleteRouteCommand(route))
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+var parseRouteTable = parseDarwinRoutes
+
+func routeTableCommand() []string {
+	return []string{"netstat", "-nr", "-f", "inet"}
+}
+
+func addRouteCommand(r Route) []string {
+	return []string{"route", "-n", "add", r.Destination.String(), r.Gateway.String()}
+}
+
+func deleteRouteCommand(r Route) []string {
+	return []string{"route", "-n", "delete", r.Destination.String(), r.Gateway.String()}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"net"
+	"testing"
+)
+
+func mustRoute(t *testing.T, cidr, gateway string) Route {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %s", cidr, err)
+	}
+	return Route{Destination: ipNet, Gateway: net.ParseIP(gateway)}
+}
+
+func TestCheckRoute(t *testing.T) {
+	route := mustRoute(t, "10.0.0.0/24", "192.168.99.100")
+	var tests = []struct {
+		description string
+		table       []Route
+		exists      bool
+		shouldErr   bool
+	}{
+		{
+			description: "unrelated routes",
+			table:       []Route{mustRoute(t, "192.168.99.0/24", ""), mustRoute(t, "172.17.0.0/16", "")},
+		},
+		{
+			description: "default and broader routes",
+			table:       []Route{mustRoute(t, "0.0.0.0/0", "192.168.1.1"), mustRoute(t, "10.0.0.0/8", "10.8.0.1")},
+		},
+		{
+			description: "route added already",
+			table:       []Route{mustRoute(t, "0.0.0.0/0", "192.168.1.1"), mustRoute(t, "10.0.0.0/24", "192.168.99.100")},
+			exists:      true,
+		},
+		{
+			description: "same destination through another gateway",
+			table:       []Route{mustRoute(t, "10.0.0.0/24", "10.8.0.1")},
+			shouldErr:   true,
+		},
+		{
+			description: "same destination on a link",
+			table:       []Route{mustRoute(t, "10.0.0.0/24", "")},
+			shouldErr:   true,
+		},
+		{
+			description: "narrower route",
+			table:       []Route{mustRoute(t, "10.0.0.128/25", "10.8.0.1")},
+			shouldErr:   true,
+		},
+		{
+			description: "host route in the range",
+			table:       []Route{mustRoute(t, "10.0.0.10/32", "")},
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			exists, err := checkRoute(test.table, route)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatal("Expected a conflict")
+			}
+			if exists != test.exists {
+				t.Errorf("Expected exists %t, got %t", test.exists, exists)
+			}
+		})
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	var tests = []struct {
+		description string
+		parse       func(string) []Route
+		output      string
+	}{
+		{
+			description: "linux",
+			parse:       parseLinuxRoutes,
+			output: `default via 192.168.1.1 dev wlan0 proto dhcp metric 600
+10.0.0.0/24 via 192.168.99.100 dev vboxnet0
+192.168.99.0/24 dev vboxnet0 proto kernel scope link src 192.168.99.1
+`,
+		},
+		{
+			description: "darwin",
+			parse:       parseDarwinRoutes,
+			output: `Routing tables
+
+Internet:
+Destination        Gateway            Flags        Netif Expire
+default            192.168.1.1        UGSc           en0
+10/24              192.168.99.100     UGSc      vboxnet0
+192.168.99         link#17            UC        vboxnet0      !
+`,
+		},
+		{
+			description: "windows",
+			parse:       parseWindowsRoutes,
+			output: `IPv4 Route Table
+===========================================================================
+Active Routes:
+Network Destination        Netmask          Gateway       Interface  Metric
+          0.0.0.0          0.0.0.0      192.168.1.1    192.168.1.10     25
+         10.0.0.0    255.255.255.0   192.168.99.100    192.168.99.1     26
+     192.168.99.0    255.255.255.0         On-link     192.168.99.1    281
+===========================================================================
+Persistent Routes:
+  None
+`,
+		},
+	}
+	expected := []Route{
+		mustRoute(t, "0.0.0.0/0", "192.168.1.1"),
+		mustRoute(t, "10.0.0.0/24", "192.168.99.100"),
+		mustRoute(t, "192.168.99.0/24", ""),
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			routes := test.parse(test.output)
+			if len(routes) != len(expected) {
+				t.Fatalf("Expected routes %v, got %v", expected, routes)
+			}
+			for i := range routes {
+				if !routes[i].same(expected[i]) {
+					t.Errorf("Expected route %s, got %s", expected[i], routes[i])
+				}
+			}
+		})
+	}
+}
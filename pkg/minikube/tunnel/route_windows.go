@@ -0,0 +1,35 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"net"
+)
+
+var parseRouteTable = parseWindowsRoutes
+
+func routeTableCommand() []string {
+	return []string{"route", "print", "-4"}
+}
+
+func addRouteCommand(r Route) []string {
+	return []string{"route", "ADD", r.Destination.IP.String(), "MASK", net.IP(r.Destination.Mask).String(), r.Gateway.String()}
+}
+
+func deleteRouteCommand(r Route) []string {
+	return []string{"route", "DELETE", r.Destination.IP.String(), "MASK", net.IP(r.Destination.Mask).String(), r.Gateway.String()}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+)
+
+// stateFile is the name of the file, in the machine directory, a tunnel records the route it
+// added and the services it patched in, for them to be cleaned up even if it was killed.
+const stateFile = "tunnel.json"
+
+type tunnelState struct {
+	PID int
+	// Destination and Gateway are the route added, if any.
+	Destination string `json:",omitempty"`
+	Gateway     string `json:",omitempty"`
+	// Services are the namespace/name of the services given their cluster IP as ingress.
+	Services []string `json:",omitempty"`
+}
+
+func statePath(name string) string {
+	return filepath.Join(constants.GetMinipath(), "machines", name, stateFile)
+}
+
+func loadState(name string) (*tunnelState, error) {
+	data, err := ioutil.ReadFile(statePath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the tunnel state")
+	}
+	s := &tunnelState{}
+	return s, errors.Wrap(json.Unmarshal(data, s), "Error unmarshalling the tunnel state")
+}
+
+func writeState(name string, s *tunnelState) error {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling the tunnel state")
+	}
+	return errors.Wrap(ioutil.WriteFile(statePath(name), data, 0600), "Error writing the tunnel state")
+}
+
+// Run routes the service range of the named machine's cluster to its VM and gives the services
+// of type LoadBalancer their cluster IP as ingress, until stop is closed. It then removes the
+// route and the ingresses again. A tunnel left by a killed minikube is cleaned up first.
+func Run(api libmachine.API, name string, services corev1.ServicesGetter, out io.Writer, stop <-chan struct{}) error {
+	if err := Cleanup(name, services, out); err != nil {
+		return err
+	}
+	h, err := cluster.CheckIfApiExistsAndLoad(api)
+	if err != nil {
+		return errors.Wrap(err, "Error getting host")
+	}
+	if s, err := h.Driver.GetState(); err != nil || s != state.Running {
+		return errors.Errorf("Cannot start the tunnel: Host %q is not running", name)
+	}
+
+	s := &tunnelState{PID: os.Getpid()}
+	// With the none driver, the services are routed on the host already.
+	if h.DriverName != "none" {
+		route, err := serviceRoute(h.Driver.GetIP, name)
+		if err != nil {
+			return err
+		}
+		table, err := routeTable()
+		if err != nil {
+			return err
+		}
+		exists, err := checkRoute(table, route)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := addRoute(route); err != nil {
+				return errors.Wrapf(err, "Error adding the route %s", route)
+			}
+			// Only the route the tunnel added is removed.
+			s.Destination, s.Gateway = route.Destination.String(), route.Gateway.String()
+		}
+		fmt.Fprintf(out, "Routing the services of %s to %s\n", route.Destination, route.Gateway)
+	}
+	if err := writeState(name, s); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Giving the LoadBalancer services their cluster IP as external IP. Press Ctrl-C to stop the tunnel...")
+	c := newLoadBalancerController(services, func(patched []string) {
+		s.Services = patched
+		if err := writeState(name, s); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	})
+	c.run(stop)
+	// The state file is gone if the machine was deleted meanwhile.
+	return cleanup(name, s, services, out)
+}
+
+// serviceRoute returns the route of the machine's service range through its VM.
+func serviceRoute(vmIP func() (string, error), name string) (Route, error) {
+	ip, err := vmIP()
+	if err != nil {
+		return Route{}, errors.Wrap(err, "Error getting the VM's IP")
+	}
+	gateway := net.ParseIP(ip)
+	if gateway == nil {
+		return Route{}, errors.Errorf("Invalid VM IP %q", ip)
+	}
+	serviceCIDR, _ := cluster.StoredClusterCIDRs(name)
+	if serviceCIDR == "" {
+		serviceCIDR = util.DefaultServiceCIDR
+	}
+	_, dest, err := net.ParseCIDR(serviceCIDR)
+	if err != nil {
+		return Route{}, errors.Wrapf(err, "Error parsing the service cluster IP range %s", serviceCIDR)
+	}
+	return Route{Destination: dest, Gateway: gateway}, nil
+}
+
+// Cleanup removes the route the named machine's tunnel added and the ingresses it gave to
+// services. It refuses to while the tunnel runs in another process.
+func Cleanup(name string, services corev1.ServicesGetter, out io.Writer) error {
+	s, err := loadState(name)
+	if err != nil || s == nil {
+		return err
+	}
+	if s.PID != os.Getpid() && cluster.ProcessAlive(s.PID) {
+		return errors.Errorf("The tunnel of %s is running as process %d, stop it with Ctrl-C", name, s.PID)
+	}
+	return cleanup(name, s, services, out)
+}
+
+func cleanup(name string, s *tunnelState, services corev1.ServicesGetter, out io.Writer) error {
+	if s.Destination != "" {
+		deleted, err := cleanupRoute(s.Destination, s.Gateway)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			fmt.Fprintf(out, "Removed the route of %s to %s\n", s.Destination, s.Gateway)
+		}
+	}
+	if err := restoreServices(services, s.Services); err != nil {
+		return err
+	}
+	if len(s.Services) > 0 {
+		fmt.Fprintf(out, "Removed the external IP of %d LoadBalancer services\n", len(s.Services))
+	}
+	if err := os.Remove(statePath(name)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Error removing the tunnel state")
+	}
+	return nil
+}
+
+// cleanupRoute deletes the route of destination through gateway, returning whether it was
+// still in the route table.
+func cleanupRoute(destination, gateway string) (bool, error) {
+	_, dest, err := net.ParseCIDR(destination)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error parsing the tunnel's route %s", destination)
+	}
+	route := Route{Destination: dest, Gateway: net.ParseIP(gateway)}
+	table, err := routeTable()
+	if err != nil {
+		return false, err
+	}
+	for _, r := range table {
+		if r.same(route) {
+			return true, errors.Wrapf(deleteRoute(route), "Error deleting the route %s", route)
+		}
+	}
+	return false, nil
+}
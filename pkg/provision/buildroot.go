@@ -30,6 +30,7 @@ import (
 	"github.com/docker/machine/libmachine/provision"
 	"github.com/docker/machine/libmachine/provision/pkgaction"
 	"github.com/docker/machine/libmachine/swarm"
+	"k8s.io/minikube/pkg/minikube/console"
 	"k8s.io/minikube/pkg/util"
 )
 
@@ -123,6 +124,15 @@ WantedBy=multi-user.target
 	}, nil
 }
 
+// SSHCommand runs the command in the VM, as the provisioning does, logging and tracing it as
+// minikube's other commands.
+func (p *BuildrootProvisioner) SSHCommand(args string) (string, error) {
+	r := console.StartCommand("the VM", args)
+	out, err := drivers.RunSSHCommandFromDriver(p.Driver, args)
+	r.Done([]byte(out), err)
+	return out, err
+}
+
 func (p *BuildrootProvisioner) Package(name string, action pkgaction.PackageAction) error {
 	return nil
 }
@@ -155,7 +165,7 @@ func (p *BuildrootProvisioner) Provision(swarmOptions swarm.Options, authOptions
 		return err
 	}
 
-	return nil
+	return MountSharedFolder(p.Driver)
 }
 
 func setRemoteAuthOptions(p provision.Provisioner) auth.Options {
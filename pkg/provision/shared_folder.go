@@ -0,0 +1,84 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// sharedFolderTypes are the filesystems the natively shared folder of each driver is mounted with.
+var sharedFolderTypes = map[string]string{
+	"virtualbox": "vboxsf",
+	"kvm2":       "9p",
+}
+
+// SupportsSharedFolder returns whether the driver can share a host folder with the VM natively.
+func SupportsSharedFolder(driverName string) bool {
+	_, ok := sharedFolderTypes[driverName]
+	return ok
+}
+
+type sharedFolderConfig struct {
+	MountHostPath  string
+	MountGuestPath string
+}
+
+// MountSharedFolder mounts the host folder the driver shares with the VM, if any.
+// It does nothing if the folder is already mounted, so it can be run on every start.
+func MountSharedFolder(d drivers.Driver) error {
+	fsType, ok := sharedFolderTypes[d.DriverName()]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return errors.Wrap(err, "Error reading driver config")
+	}
+	var c sharedFolderConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		return errors.Wrap(err, "Error reading driver config")
+	}
+	if c.MountGuestPath == "" {
+		return nil
+	}
+	log.Debugf("mounting shared folder on %s", c.MountGuestPath)
+	if _, err := drivers.RunSSHCommandFromDriver(d, sharedFolderMountCommand(fsType, c.MountGuestPath)); err != nil {
+		return errors.Wrapf(err, "Error mounting shared folder on %s", c.MountGuestPath)
+	}
+	return nil
+}
+
+// sharedFolderMountCommand returns the command mounting the shared folder on guestPath.
+func sharedFolderMountCommand(fsType, guestPath string) string {
+	options := "trans=virtio,version=9p2000.L"
+	if fsType == "vboxsf" {
+		options = "uid=$(id -u),gid=$(id -g)"
+	}
+	path := shellQuote(guestPath)
+	return fmt.Sprintf("sudo mkdir -p %s && (mountpoint -q %s || sudo mount -t %s -o %s %s %s)", path, path, fsType, options, constants.SharedFolderName, path)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
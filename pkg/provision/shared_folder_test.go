@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+import "testing"
+
+func TestSharedFolderMountCommand(t *testing.T) {
+	var tests = []struct {
+		description string
+		fsType      string
+		guestPath   string
+		expected    string
+	}{
+		{
+			description: "vboxsf",
+			fsType:      "vboxsf",
+			guestPath:   "/src",
+			expected:    "sudo mkdir -p '/src' && (mountpoint -q '/src' || sudo mount -t vboxsf -o uid=$(id -u),gid=$(id -g) minikube-share '/src')",
+		},
+		{
+			description: "9p with a quote in the path",
+			fsType:      "9p",
+			guestPath:   "/o'brien",
+			expected:    `sudo mkdir -p '/o'\''brien' && (mountpoint -q '/o'\''brien' || sudo mount -t 9p -o trans=virtio,version=9p2000.L minikube-share '/o'\''brien')`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if actual := sharedFolderMountCommand(test.fsType, test.guestPath); actual != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// auditPolicyAPIVersions are the API versions of the audit policies the apiserver reads.
+var auditPolicyAPIVersions = []string{"audit.k8s.io/v1alpha1", "audit.k8s.io/v1beta1"}
+
+var auditLevels = []string{"None", "Metadata", "Request", "RequestResponse"}
+
+var auditStages = []string{"RequestReceived", "ResponseStarted", "ResponseComplete", "Panic"}
+
+// The fields each part of an audit policy may have, so that a misspelled one is caught.
+var (
+	auditPolicyFields = []string{"apiVersion", "kind", "metadata", "rules", "omitStages"}
+	auditRuleFields   = []string{"level", "users", "userGroups", "verbs", "resources", "namespaces", "nonResourceURLs", "omitStages"}
+)
+
+type auditPolicy struct {
+	APIVersion string                   `json:"apiVersion"`
+	Kind       string                   `json:"kind"`
+	Rules      []map[string]interface{} `json:"rules"`
+	OmitStages []string                 `json:"omitStages"`
+}
+
+// ValidateAuditPolicy checks that data is an audit policy which the apiserver can start with,
+// as it doesn't start with an invalid one.
+func ValidateAuditPolicy(data []byte) error {
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return errors.Wrap(err, "Error parsing the audit policy")
+	}
+	if err := checkFields("the audit policy", fields, auditPolicyFields); err != nil {
+		return err
+	}
+	var policy auditPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return errors.Wrap(err, "Error parsing the audit policy")
+	}
+	if !contains(auditPolicyAPIVersions, policy.APIVersion) {
+		return fmt.Errorf("The audit policy has apiVersion %q, it must be one of %s", policy.APIVersion, strings.Join(auditPolicyAPIVersions, ", "))
+	}
+	if policy.Kind != "Policy" {
+		return fmt.Errorf("The audit policy has kind %q, it must be Policy", policy.Kind)
+	}
+	if len(policy.Rules) == 0 {
+		return errors.New("The audit policy has no rules")
+	}
+	if err := checkStages("the audit policy", policy.OmitStages); err != nil {
+		return err
+	}
+	for i, rule := range policy.Rules {
+		name := fmt.Sprintf("rule %d of the audit policy", i+1)
+		if err := checkFields(name, rule, auditRuleFields); err != nil {
+			return err
+		}
+		level, _ := rule["level"].(string)
+		if !contains(auditLevels, level) {
+			return fmt.Errorf("Invalid level %q in %s, it must be one of %s", level, name, strings.Join(auditLevels, ", "))
+		}
+		if stages, ok := rule["omitStages"].([]interface{}); ok {
+			var names []string
+			for _, s := range stages {
+				names = append(names, fmt.Sprint(s))
+			}
+			if err := checkStages(name, names); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkFields(name string, fields map[string]interface{}, allowed []string) error {
+	var unknown []string
+	for f := range fields {
+		if !contains(allowed, f) {
+			unknown = append(unknown, f)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("Unknown fields %s in %s, it may have %s", strings.Join(unknown, ", "), name, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func checkStages(name string, stages []string) error {
+	for _, s := range stages {
+		if !contains(auditStages, s) {
+			return fmt.Errorf("Invalid stage %q omitted in %s, it must be one of %s", s, name, strings.Join(auditStages, ", "))
+		}
+	}
+	return nil
+}
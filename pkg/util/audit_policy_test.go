@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+const validAuditPolicy = `apiVersion: audit.k8s.io/v1alpha1
+kind: Policy
+omitStages:
+- RequestReceived
+rules:
+- level: None
+  users: ["system:kube-proxy"]
+  verbs: ["watch"]
+- level: RequestResponse
+  resources:
+  - group: ""
+    resources: ["pods"]
+- level: Metadata
+  omitStages: ["ResponseStarted"]
+`
+
+func TestValidateAuditPolicy(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      string
+		shouldErr   bool
+		errorMsg    string
+	}{
+		{
+			description: "valid",
+			policy:      validAuditPolicy,
+		},
+		{
+			description: "v1beta1",
+			policy:      strings.Replace(validAuditPolicy, "v1alpha1", "v1beta1", 1),
+		},
+		{
+			description: "not yaml",
+			policy:      "rules: [level: None",
+			shouldErr:   true,
+			errorMsg:    "Error parsing",
+		},
+		{
+			description: "other kind",
+			policy:      strings.Replace(validAuditPolicy, "kind: Policy", "kind: Pod", 1),
+			shouldErr:   true,
+			errorMsg:    "kind",
+		},
+		{
+			description: "other api version",
+			policy:      strings.Replace(validAuditPolicy, "audit.k8s.io/v1alpha1", "v1", 1),
+			shouldErr:   true,
+			errorMsg:    "apiVersion",
+		},
+		{
+			description: "no rules",
+			policy:      "apiVersion: audit.k8s.io/v1alpha1\nkind: Policy\n",
+			shouldErr:   true,
+			errorMsg:    "no rules",
+		},
+		{
+			description: "misspelled level",
+			policy:      strings.Replace(validAuditPolicy, "level: Metadata", "level: metadata", 1),
+			shouldErr:   true,
+			errorMsg:    `Invalid level "metadata" in rule 3`,
+		},
+		{
+			description: "misspelled field",
+			policy:      strings.Replace(validAuditPolicy, "verbs:", "verb:", 1),
+			shouldErr:   true,
+			errorMsg:    "Unknown fields verb in rule 1",
+		},
+		{
+			description: "misspelled top level field",
+			policy:      strings.Replace(validAuditPolicy, "rules:", "rules:\nrule:\n", 1),
+			shouldErr:   true,
+			errorMsg:    "Unknown fields rule in the audit policy",
+		},
+		{
+			description: "unknown stage",
+			policy:      strings.Replace(validAuditPolicy, `["ResponseStarted"]`, `["ResponseSent"]`, 1),
+			shouldErr:   true,
+			errorMsg:    `Invalid stage "ResponseSent" omitted in rule 3`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateAuditPolicy([]byte(test.policy))
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error")
+			}
+			if err != nil && !strings.Contains(err.Error(), test.errorMsg) {
+				t.Errorf("Expected the error to contain %q, got %q", test.errorMsg, err)
+			}
+		})
+	}
+}
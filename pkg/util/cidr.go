@@ -0,0 +1,78 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	// serviceIPIndex is the address of the service range the apiserver's kubernetes service gets.
+	serviceIPIndex = 1
+	// dnsIPIndex is the address of the service range kube-dns gets.
+	dnsIPIndex = 10
+	// maxClusterCIDRPrefix is the smallest range, a /28, which still holds the DNS IP.
+	maxClusterCIDRPrefix = 28
+)
+
+// ParseClusterCIDR parses an IPv4 range of the cluster's service or pod IPs.
+func ParseClusterCIDR(cidr string) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	if ipNet.IP.To4() == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 range", cidr)
+	}
+	if ones, _ := ipNet.Mask.Size(); ones > maxClusterCIDRPrefix {
+		return nil, fmt.Errorf("%s is too small, the range must be a /%d or larger", cidr, maxClusterCIDRPrefix)
+	}
+	return ipNet, nil
+}
+
+// NthIP returns the nth address of the range, counting its network address as the 0th.
+func NthIP(ipNet *net.IPNet, n int) (net.IP, error) {
+	ip := make(net.IP, net.IPv4len)
+	copy(ip, ipNet.IP.To4())
+	carry := n
+	for i := len(ip) - 1; i >= 0 && carry > 0; i-- {
+		sum := int(ip[i]) + carry
+		ip[i] = byte(sum)
+		carry = sum >> 8
+	}
+	if carry > 0 || !ipNet.Contains(ip) {
+		return nil, fmt.Errorf("%s has no address %d", ipNet, n)
+	}
+	return ip, nil
+}
+
+// ServiceIP returns the IP of the kubernetes service in the service range.
+func ServiceIP(serviceNet *net.IPNet) (net.IP, error) {
+	return NthIP(serviceNet, serviceIPIndex)
+}
+
+// DNSIP returns the IP of the kube-dns service in the service range.
+func DNSIP(serviceNet *net.IPNet) (net.IP, error) {
+	return NthIP(serviceNet, dnsIPIndex)
+}
+
+// CIDRsOverlap returns whether the ranges share any address. As ranges are
+// aligned to their size, they overlap when one holds the start of the other.
+func CIDRsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseClusterCIDR(t *testing.T) {
+	tests := []struct {
+		description string
+		cidr        string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "default service range",
+			cidr:        DefaultServiceCIDR,
+			expected:    "10.0.0.0/24",
+		},
+		{
+			description: "host bits are masked",
+			cidr:        "172.30.5.7/16",
+			expected:    "172.30.0.0/16",
+		},
+		{
+			description: "smallest range",
+			cidr:        "10.96.0.0/28",
+			expected:    "10.96.0.0/28",
+		},
+		{
+			description: "too small",
+			cidr:        "10.96.0.0/29",
+			shouldErr:   true,
+		},
+		{
+			description: "IPv6",
+			cidr:        "fd00::/64",
+			shouldErr:   true,
+		},
+		{
+			description: "not a range",
+			cidr:        "10.96.0.1",
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ipNet, err := ParseClusterCIDR(test.cidr)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error, got %s", ipNet)
+			}
+			if err == nil && ipNet.String() != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, ipNet)
+			}
+		})
+	}
+}
+
+func TestNthIP(t *testing.T) {
+	tests := []struct {
+		description string
+		cidr        string
+		n           int
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "default service IP",
+			cidr:        DefaultServiceCIDR,
+			n:           serviceIPIndex,
+			expected:    DefaultServiceClusterIP,
+		},
+		{
+			description: "default DNS IP",
+			cidr:        DefaultServiceCIDR,
+			n:           dnsIPIndex,
+			expected:    DefaultDNSIP,
+		},
+		{
+			description: "carries into the next byte",
+			cidr:        "10.96.0.0/16",
+			n:           300,
+			expected:    "10.96.1.44",
+		},
+		{
+			description: "outside the range",
+			cidr:        "10.96.0.0/28",
+			n:           16,
+			shouldErr:   true,
+		},
+		{
+			description: "past the end of the address space",
+			cidr:        "255.255.255.240/28",
+			n:           16,
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, ipNet, err := net.ParseCIDR(test.cidr)
+			if err != nil {
+				t.Fatalf("Error parsing %s: %s", test.cidr, err)
+			}
+			ip, err := NthIP(ipNet, test.n)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error, got %s", ip)
+			}
+			if err == nil && ip.String() != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, ip)
+			}
+		})
+	}
+}
+
+func TestCIDRsOverlap(t *testing.T) {
+	tests := []struct {
+		description string
+		a           string
+		b           string
+		expected    bool
+	}{
+		{
+			description: "disjoint",
+			a:           "10.0.0.0/24",
+			b:           "192.168.99.0/24",
+		},
+		{
+			description: "adjacent",
+			a:           "10.0.0.0/24",
+			b:           "10.0.1.0/24",
+		},
+		{
+			description: "same",
+			a:           "10.0.0.0/24",
+			b:           "10.0.0.0/24",
+			expected:    true,
+		},
+		{
+			description: "first holds second",
+			a:           "10.0.0.0/8",
+			b:           "10.96.0.0/12",
+			expected:    true,
+		},
+		{
+			description: "second holds first",
+			a:           "192.168.99.0/24",
+			b:           "192.168.0.0/16",
+			expected:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, a, _ := net.ParseCIDR(test.a)
+			_, b, _ := net.ParseCIDR(test.b)
+			if actual := CIDRsOverlap(a, b); actual != test.expected {
+				t.Errorf("Expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ const (
 	DefaultServiceClusterIP   = "10.0.0.1"
 	DefaultDNSDomain          = "cluster.local"
 	DefaultDNSIP              = "10.0.0.10"
+	DefaultServiceCIDR        = "10.0.0.0/24"
+	DefaultPodCIDR            = "10.180.1.0/24"
 )
 
 func GetAlternateDNS(domain string) []string {
@@ -23,6 +23,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net"
@@ -73,17 +74,9 @@ func GenerateSignedCert(certPath, keyPath string, ips []net.IP, alternateDNS []s
 	if err != nil {
 		return errors.Wrap(err, "Error parsing certificate: decodedSignerCert.Bytes")
 	}
-	signerKeyBytes, err := ioutil.ReadFile(signerKeyPath)
+	signerKey, err := ReadPrivateKey(signerKeyPath)
 	if err != nil {
-		return errors.Wrap(err, "Error reading file: signerKeyPath")
-	}
-	decodedSignerKey, _ := pem.Decode(signerKeyBytes)
-	if decodedSignerKey == nil {
-		return errors.New("Unable to decode key.")
-	}
-	signerKey, err := x509.ParsePKCS1PrivateKey(decodedSignerKey.Bytes)
-	if err != nil {
-		return errors.Wrap(err, "Error parsing prive key: decodedSignerKey.Bytes")
+		return errors.Wrap(err, "Error reading signer key")
 	}
 
 	template := x509.Certificate{
@@ -111,6 +104,30 @@ func GenerateSignedCert(certPath, keyPath string, ips []net.IP, alternateDNS []s
 	return writeCertsAndKeys(&template, certPath, priv, keyPath, signerCert, signerKey)
 }
 
+// ReadPrivateKey reads the PEM RSA private key at keyPath, in PKCS #1 or PKCS #8 form.
+func ReadPrivateKey(keyPath string) (*rsa.PrivateKey, error) {
+	keyBytes, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading file: %s", keyPath)
+	}
+	decodedKey, _ := pem.Decode(keyBytes)
+	if decodedKey == nil {
+		return nil, fmt.Errorf("No PEM key in %s", keyPath)
+	}
+	if priv, err := x509.ParsePKCS1PrivateKey(decodedKey.Bytes); err == nil {
+		return priv, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(decodedKey.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing private key %s", keyPath)
+	}
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("The private key %s is not an RSA key", keyPath)
+	}
+	return priv, nil
+}
+
 func loadOrGeneratePrivateKey(keyPath string) (*rsa.PrivateKey, error) {
 	keyBytes, err := ioutil.ReadFile(keyPath)
 	if err == nil {
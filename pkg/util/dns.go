@@ -0,0 +1,40 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dnsLabel matches a lowercase DNS label, as Kubernetes names are made of.
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateDNSDomain checks that domain can be the cluster's DNS domain, which
+// kube-dns serves the services under, such as kubernetes.default.svc.<domain>.
+func ValidateDNSDomain(domain string) error {
+	if domain == "" || len(domain) > 253 {
+		return fmt.Errorf("Invalid DNS domain %q, it must be 1 to 253 characters long", domain)
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if len(label) > 63 || !dnsLabel.MatchString(label) {
+			return fmt.Errorf("Invalid DNS domain %q, each of its dot separated labels must be made of lowercase letters, digits and dashes, such as cluster.local", domain)
+		}
+	}
+	return nil
+}
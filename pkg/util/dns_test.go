@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDNSDomain(t *testing.T) {
+	tests := []struct {
+		description string
+		domain      string
+		shouldErr   bool
+	}{
+		{
+			description: "default",
+			domain:      DefaultDNSDomain,
+		},
+		{
+			description: "single label",
+			domain:      "k8s",
+		},
+		{
+			description: "dashes and digits",
+			domain:      "dev-1.example.com",
+		},
+		{
+			description: "empty",
+			shouldErr:   true,
+		},
+		{
+			description: "trailing dot",
+			domain:      "cluster.local.",
+			shouldErr:   true,
+		},
+		{
+			description: "uppercase",
+			domain:      "Cluster.Local",
+			shouldErr:   true,
+		},
+		{
+			description: "label starting with a dash",
+			domain:      "-cluster.local",
+			shouldErr:   true,
+		},
+		{
+			description: "label too long",
+			domain:      strings.Repeat("a", 64) + ".local",
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateDNSDomain(test.domain)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error for %q", test.domain)
+			}
+		})
+	}
+}
@@ -17,15 +17,20 @@ limitations under the License.
 package util
 
 import (
-	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/golang/glog"
-	download "github.com/jimmidyson/go-download"
 	"github.com/pkg/errors"
+	pb "gopkg.in/cheggaaa/pb.v1"
 	"k8s.io/minikube/pkg/minikube/constants"
 )
 
@@ -36,7 +41,20 @@ type ISODownloader interface {
 	CacheMinikubeISOFromURL(isoURL string) error
 }
 
-type DefaultDownloader struct{}
+// DefaultDownloader caches ISOs under the minikube directory.
+type DefaultDownloader struct {
+	// Offline makes CacheMinikubeISOFromURL fail rather than download an ISO which isn't cached.
+	Offline bool
+	// Progress is where the download progress is written, stdout if it is nil.
+	Progress io.Writer
+}
+
+func (f DefaultDownloader) progress() io.Writer {
+	if f.Progress == nil {
+		return TerminalWriter(os.Stdout)
+	}
+	return f.Progress
+}
 
 func (f DefaultDownloader) GetISOFileURI(isoURL string) string {
 	urlObj, err := url.Parse(isoURL)
@@ -46,40 +64,224 @@ func (f DefaultDownloader) GetISOFileURI(isoURL string) string {
 	if urlObj.Scheme == fileScheme {
 		return isoURL
 	}
-	isoPath := filepath.Join(constants.GetMinipath(), "cache", "iso", filepath.Base(isoURL))
+	isoPath := constants.MakeCachePath("iso", filepath.Base(isoURL))
 	// As this is a file URL there should be no backslashes regardless of platform running on.
 	return "file://" + filepath.ToSlash(isoPath)
 }
 
+// CacheMinikubeISOFromURL downloads the ISO at isoURL into the cache. It is
+// downloaded to a temporary file, resuming a download which was interrupted,
+// and only moved into the cache once its SHA256 checksum, published next to it,
+// matches. An ISO at a file URL is used in place, after checking it against the
+// checksum file next to it if there is one.
 func (f DefaultDownloader) CacheMinikubeISOFromURL(isoURL string) error {
+	urlObj, err := url.Parse(isoURL)
+	if err != nil {
+		return errors.Wrapf(err, "Error parsing ISO URL %s", isoURL)
+	}
+	if urlObj.Scheme == fileScheme {
+		return verifyLocalISO(fileURLPath(urlObj))
+	}
 	if !f.ShouldCacheMinikubeISO(isoURL) {
 		glog.Infof("Not caching ISO, using %s", isoURL)
 		return nil
 	}
-
-	options := download.FileOptions{
-		Mkdirs: download.MkdirAll,
-		Options: download.Options{
-			ProgressBars: &download.ProgressBarOptions{
-				MaxWidth: 80,
-			},
-		},
+	if f.Offline {
+		return fmt.Errorf("Minikube ISO %s is not cached at %s and can't be downloaded offline", isoURL, f.GetISOCacheFilepath(isoURL))
 	}
 
-	// Validate the ISO if it was the default URL, before writing it to disk.
-	if isoURL == constants.DefaultIsoUrl {
-		options.Checksum = constants.DefaultIsoShaUrl
-		options.ChecksumHash = crypto.SHA256
+	checksum, err := fetchChecksum(isoURL)
+	if err != nil {
+		return errors.Wrap(err, "Error getting Minikube ISO checksum")
 	}
 
-	fmt.Println("Downloading Minikube ISO")
-	if err := download.ToFile(isoURL, f.GetISOCacheFilepath(isoURL), options); err != nil {
+	isoPath := f.GetISOCacheFilepath(isoURL)
+	if err := os.MkdirAll(filepath.Dir(isoPath), 0755); err != nil {
+		return errors.Wrap(err, "Error creating ISO cache directory")
+	}
+	tmpPath := isoPath + ".tmp"
+	fmt.Fprintln(f.progress(), "Downloading Minikube ISO")
+	if err := downloadToFile(isoURL, tmpPath, f.progress()); err != nil {
 		return errors.Wrap(err, "Error downloading Minikube ISO")
 	}
+	if checksum != "" {
+		if err := verifyChecksum(tmpPath, checksum); err != nil {
+			// Start over next time, the partial download can't be trusted.
+			os.Remove(tmpPath)
+			return errors.Wrap(err, "Error verifying Minikube ISO")
+		}
+	}
+	return errors.Wrap(os.Rename(tmpPath, isoPath), "Error moving Minikube ISO into the cache")
+}
 
+// fetchChecksum gets the SHA256 checksum published next to the ISO at isoURL.
+// When there is none, an empty checksum is returned, except for the default ISO
+// which must always be verified. Object stores answer for a missing checksum with
+// a 403 rather than a 404, so both mean that it isn't published. Any other failure
+// fails the download, the ISO can't be left unverified because the server erred.
+func fetchChecksum(isoURL string) (string, error) {
+	shaURL := isoURL + constants.ShaSuffix
+	resp, err := http.Get(shaURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting %s", shaURL)
+	}
+	defer resp.Body.Close()
+	notPublished := resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden
+	if notPublished && isoURL != constants.DefaultIsoUrl {
+		glog.Warningf("Not verifying ISO, there is no checksum at %s: %s", shaURL, resp.Status)
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error getting %s: %s", shaURL, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error reading %s", shaURL)
+	}
+	return parseChecksum(b)
+}
+
+// parseChecksum reads the checksum out of a sha256sum style file, which may
+// only hold the hex encoded checksum or may follow it with the file name.
+func parseChecksum(b []byte) (string, error) {
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return "", errors.New("Checksum file is empty")
+	}
+	sum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("Invalid SHA256 checksum: %q", fields[0])
+	}
+	return sum, nil
+}
+
+// downloadToFile downloads the file at rawURL to path, showing its progress on w. If path
+// holds part of the file from an interrupted download, only the rest of it is requested.
+func downloadToFile(rawURL, path string, w io.Writer) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		glog.Infof("Resuming download of %s from byte %d", rawURL, offset)
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The file was completely downloaded already.
+		return nil
+	case http.StatusOK:
+		// The server doesn't support resuming, start over.
+		offset = 0
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Error getting %s: %s", rawURL, resp.Status)
+	}
+
+	total := resp.ContentLength
+	if total > 0 {
+		total += offset
+	}
+	bar := pb.New64(total).SetUnits(pb.U_BYTES).SetMaxWidth(80)
+	bar.Output = w
+	bar.Set64(offset)
+	bar.Start()
+	var body io.Reader = resp.Body
+	if t, ok := w.(progressTicker); ok {
+		body = &tickReader{Reader: resp.Body, t: t, current: offset, total: total}
+	}
+	_, err = io.Copy(out, bar.NewProxyReader(body))
+	bar.Finish()
+	if err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// FileChecksum returns the hex encoded SHA256 checksum of the file at path.
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "Error reading %s", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyChecksum checks that the SHA256 checksum of the file at path is the hex encoded checksum.
+func verifyChecksum(path, checksum string) error {
+	sum, err := FileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if sum != checksum {
+		return fmt.Errorf("Checksum of %s is %s, expected %s", path, sum, checksum)
+	}
 	return nil
 }
 
+// ReadChecksumFile reads the SHA256 checksum out of the sha256sum style file at path.
+// An error reading the file is returned as it is, so a missing one can be told apart.
+func ReadChecksumFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	checksum, err := parseChecksum(b)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error parsing %s", path)
+	}
+	return checksum, nil
+}
+
+// verifyLocalISO checks the ISO at path against the checksum file next to it, if there is one.
+func verifyLocalISO(path string) error {
+	checksum, err := ReadChecksumFile(path + constants.ShaSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "Error reading ISO checksum")
+	}
+	return errors.Wrap(verifyChecksum(path, checksum), "Error verifying Minikube ISO")
+}
+
+// fileURLPath returns the local path of a file URL. The path of a Windows file
+// URL, such as file:///C:/minikube.iso, starts with a slash before its drive letter.
+func fileURLPath(u *url.URL) string {
+	p := u.Path
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
 func (f DefaultDownloader) ShouldCacheMinikubeISO(isoURL string) bool {
 	// store the miniube-iso inside the .minikube dir
 
@@ -97,7 +299,7 @@ func (f DefaultDownloader) ShouldCacheMinikubeISO(isoURL string) bool {
 }
 
 func (f DefaultDownloader) GetISOCacheFilepath(isoURL string) string {
-	return filepath.Join(constants.GetMinipath(), "cache", "iso", filepath.Base(isoURL))
+	return constants.MakeCachePath("iso", filepath.Base(isoURL))
 }
 
 func (f DefaultDownloader) IsMinikubeISOCached(isoURL string) bool {
@@ -17,14 +17,18 @@ limitations under the License.
 package util
 
 import (
-	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/tests"
@@ -35,7 +39,7 @@ func TestGetISOFileURI(t *testing.T) {
 
 	tests := map[string]string{
 		"file:///test/path/minikube-test.iso":                           "file:///test/path/minikube-test.iso",
-		"https://storage.googleapis.com/minikube/iso/minikube-test.iso": "file://" + filepath.ToSlash(filepath.Join(constants.GetMinipath(), "cache", "iso", "minikube-test.iso")),
+		"https://storage.googleapis.com/minikube/iso/minikube-test.iso": "file://" + filepath.ToSlash(constants.MakeCachePath("iso", "minikube-test.iso")),
 	}
 
 	for input, expected := range tests {
@@ -48,31 +52,216 @@ func TestGetISOFileURI(t *testing.T) {
 
 var testISOString = "hello"
 
+// testISOChecksum is the SHA256 checksum of testISOString.
+const testISOChecksum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+// isoServer serves testISOString, with range requests, and its checksum, or the
+// status of shaStatus for it. The first truncated requests for the ISO are cut
+// short halfway through it.
+type isoServer struct {
+	checksum  string
+	shaStatus int
+	truncated int
+	ranges    []string
+}
+
+func (s *isoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, constants.ShaSuffix) {
+		if s.shaStatus != 0 {
+			http.Error(w, http.StatusText(s.shaStatus), s.shaStatus)
+			return
+		}
+		if s.checksum == "" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, s.checksum+"  minikube-test.iso\n")
+		return
+	}
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	if s.truncated > 0 {
+		s.truncated--
+		w.Header().Set("Content-Length", strconv.Itoa(len(testISOString)))
+		io.WriteString(w, testISOString[:len(testISOString)/2])
+		return
+	}
+	http.ServeContent(w, r, "minikube-test.iso", time.Time{}, strings.NewReader(testISOString))
+}
+
 func TestCacheMinikubeISOFromURL(t *testing.T) {
+	var cases = []struct {
+		description string
+		checksum    string
+		shaStatus   int
+		truncated   int
+		shouldErr   bool
+		errContains string
+		ranges      []string
+	}{
+		{
+			description: "verified",
+			checksum:    testISOChecksum,
+			ranges:      []string{""},
+		},
+		{
+			description: "no checksum",
+			ranges:      []string{""},
+		},
+		{
+			description: "checksum forbidden",
+			shaStatus:   http.StatusForbidden,
+			ranges:      []string{""},
+		},
+		{
+			description: "checksum server error",
+			shaStatus:   http.StatusInternalServerError,
+			shouldErr:   true,
+			errContains: "500 Internal Server Error",
+		},
+		{
+			description: "checksum unavailable",
+			shaStatus:   http.StatusServiceUnavailable,
+			shouldErr:   true,
+			errContains: "503 Service Unavailable",
+		},
+		{
+			description: "checksum mismatch",
+			checksum:    strings.Repeat("0", 64),
+			shouldErr:   true,
+			ranges:      []string{""},
+		},
+		{
+			description: "invalid checksum",
+			checksum:    "hello",
+			shouldErr:   true,
+		},
+		{
+			description: "interrupted",
+			checksum:    testISOChecksum,
+			truncated:   1,
+			shouldErr:   true,
+			ranges:      []string{""},
+		},
+	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir := tests.MakeTempDir()
+			defer os.RemoveAll(tempDir)
+			dler := DefaultDownloader{}
+			isoPath := constants.MakeCachePath("iso", "minikube-test.iso")
+
+			s := &isoServer{checksum: test.checksum, shaStatus: test.shaStatus, truncated: test.truncated}
+			server := httptest.NewServer(s)
+			defer server.Close()
+			err := dler.CacheMinikubeISOFromURL(server.URL + "/minikube-test.iso")
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error from CacheMinikubeISOFromURL: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatal("Expected an error from CacheMinikubeISOFromURL")
+			}
+			if err != nil && !strings.Contains(err.Error(), test.errContains) {
+				t.Errorf("Expected the error to contain %q, got: %s", test.errContains, err)
+			}
+			if !reflect.DeepEqual(s.ranges, test.ranges) {
+				t.Errorf("Expected ISO requests with ranges %q, got %q", test.ranges, s.ranges)
+			}
+
+			transferred, err := ioutil.ReadFile(isoPath)
+			if test.shouldErr {
+				if err == nil {
+					t.Fatalf("Expected the ISO not to be cached, it contains: %s", transferred)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("File not copied. Could not open file at path: %s", isoPath)
+			}
+			if string(transferred) != testISOString {
+				t.Fatalf("Expected the cached ISO to be %q, it was %q", testISOString, transferred)
+			}
+		})
+	}
+}
+
+func TestCacheMinikubeISOFromURLResumes(t *testing.T) {
 	tempDir := tests.MakeTempDir()
 	defer os.RemoveAll(tempDir)
 	dler := DefaultDownloader{}
-	isoPath := filepath.Join(constants.GetMinipath(), "cache", "iso", "minikube-test.iso")
+	isoPath := constants.MakeCachePath("iso", "minikube-test.iso")
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		io.WriteString(w, testISOString)
-	}))
+	s := &isoServer{checksum: testISOChecksum, truncated: 1}
+	server := httptest.NewServer(s)
+	defer server.Close()
 	isoURL := server.URL + "/minikube-test.iso"
+	if err := dler.CacheMinikubeISOFromURL(isoURL); err == nil {
+		t.Fatal("Expected the interrupted download to fail")
+	}
+	if _, err := os.Stat(isoPath + ".tmp"); err != nil {
+		t.Fatalf("Expected the partial download to be kept: %s", err)
+	}
 	if err := dler.CacheMinikubeISOFromURL(isoURL); err != nil {
-		t.Fatalf("Unexpected error from CacheMinikubeISOFromURL: %s", err)
+		t.Fatalf("Unexpected error resuming the download: %s", err)
 	}
 
-	transferred, err := ioutil.ReadFile(filepath.Join(isoPath))
+	expected := []string{"", fmt.Sprintf("bytes=%d-", len(testISOString)/2)}
+	if !reflect.DeepEqual(s.ranges, expected) {
+		t.Errorf("Expected ISO requests with ranges %q, got %q", expected, s.ranges)
+	}
+	transferred, err := ioutil.ReadFile(isoPath)
 	if err != nil {
 		t.Fatalf("File not copied. Could not open file at path: %s", isoPath)
 	}
+	if string(transferred) != testISOString {
+		t.Fatalf("Expected the cached ISO to be %q, it was %q", testISOString, transferred)
+	}
+	if _, err := os.Stat(isoPath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be moved into the cache")
+	}
+}
 
-	//test that the ISO is transferred properly
-	contents := []byte(testISOString)
-	if !bytes.Contains(transferred, contents) {
-		t.Fatalf("Expected transfers to contain: %s. It was: %s", contents, transferred)
+func TestCacheMinikubeISOFromFileURL(t *testing.T) {
+	var cases = []struct {
+		description string
+		checksum    string
+		shouldErr   bool
+	}{
+		{
+			description: "no checksum file",
+		},
+		{
+			description: "matching checksum",
+			checksum:    testISOChecksum + "  minikube-test.iso\n",
+		},
+		{
+			description: "checksum mismatch",
+			checksum:    strings.Repeat("0", 64),
+			shouldErr:   true,
+		},
 	}
+	for _, test := range cases {
+		t.Run(test.description, func(t *testing.T) {
+			tempDir := tests.MakeTempDir()
+			defer os.RemoveAll(tempDir)
+			isoPath := filepath.Join(tempDir, "minikube-test.iso")
+			if err := ioutil.WriteFile(isoPath, []byte(testISOString), 0644); err != nil {
+				t.Fatalf("Error writing ISO: %s", err)
+			}
+			if test.checksum != "" {
+				if err := ioutil.WriteFile(isoPath+constants.ShaSuffix, []byte(test.checksum), 0644); err != nil {
+					t.Fatalf("Error writing ISO checksum: %s", err)
+				}
+			}
 
+			err := DefaultDownloader{}.CacheMinikubeISOFromURL("file://" + filepath.ToSlash(isoPath))
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error from CacheMinikubeISOFromURL: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatal("Expected an error from CacheMinikubeISOFromURL")
+			}
+		})
+	}
 }
 
 func TestShouldCacheMinikubeISO(t *testing.T) {
@@ -103,7 +292,7 @@ func TestIsMinikubeISOCached(t *testing.T) {
 		t.Fatalf("Expected IsMinikubeISOCached with input to return %s but instead got: %s", testFileURI, expected, out)
 	}
 
-	ioutil.WriteFile(filepath.Join(constants.GetMinipath(), "cache", "iso", "minikube-test.iso"), []byte(testISOString), os.FileMode(int(0644)))
+	ioutil.WriteFile(constants.MakeCachePath("iso", "minikube-test.iso"), []byte(testISOString), os.FileMode(int(0644)))
 
 	expected = true
 	if out := dler.IsMinikubeISOCached(testFileURI); out != expected {
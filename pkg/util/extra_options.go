@@ -18,9 +18,16 @@ package util
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+// ExtraConfigComponents are the components localkube takes extra config for.
+var ExtraConfigComponents = []string{"apiserver", "controller-manager", "scheduler", "kubelet", "proxy", "etcd"}
+
+// extraConfigField matches a field of a component's options, which localkube sets.
+var extraConfigField = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
 type ExtraOption struct {
 	Component string
 	Key       string
@@ -67,3 +74,66 @@ func (es *ExtraOptionSlice) String() string {
 func (es *ExtraOptionSlice) Type() string {
 	return "ExtraOption"
 }
+
+// ParseExtraOptions parses each of values as the --extra-config flag does.
+func ParseExtraOptions(values []string) (ExtraOptionSlice, error) {
+	es := ExtraOptionSlice{}
+	for _, v := range values {
+		if err := es.Set(v); err != nil {
+			return nil, err
+		}
+	}
+	return es, nil
+}
+
+// Strings returns each option as the --extra-config flag takes it.
+func (es ExtraOptionSlice) Strings() []string {
+	s := []string{}
+	for _, e := range es {
+		s = append(s, e.String())
+	}
+	return s
+}
+
+// Validate checks that each option is for one of the components localkube runs,
+// and sets a field of the component's options, such as apiserver.Authorization.Mode.
+// Values can't hold whitespace, as the localkube command line is split on it.
+func (es ExtraOptionSlice) Validate() error {
+	for _, e := range es {
+		known := false
+		for _, c := range ExtraConfigComponents {
+			known = known || e.Component == c
+		}
+		if !known {
+			return fmt.Errorf("Invalid component %q in extra config %s, valid components are: %s", e.Component, e.String(), strings.Join(ExtraConfigComponents, ", "))
+		}
+		for _, field := range strings.Split(e.Key, ".") {
+			if !extraConfigField.MatchString(field) {
+				return fmt.Errorf("Invalid key %q in extra config %s: keys are the fields of the component's options, such as apiserver.Authorization.Mode, not its flags", e.Key, e.String())
+			}
+		}
+		if strings.ContainsAny(e.Value, " \t\n") {
+			return fmt.Errorf("Invalid value in extra config %s: values can't contain whitespace", e.String())
+		}
+	}
+	return nil
+}
+
+// Merge returns the options of es, with the values of other replacing the ones for
+// the same component and key, followed by the other options of other.
+func (es ExtraOptionSlice) Merge(other ExtraOptionSlice) ExtraOptionSlice {
+	merged := append(ExtraOptionSlice{}, es...)
+	for _, o := range other {
+		replaced := false
+		for i, e := range merged {
+			if e.Component == o.Component && e.Key == o.Key {
+				merged[i].Value = o.Value
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
@@ -78,3 +78,111 @@ func TestValidFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateExtraOptions(t *testing.T) {
+	var tests = []struct {
+		description string
+		value       string
+		shouldErr   bool
+	}{
+		{
+			description: "apiserver field",
+			value:       "apiserver.Authorization.Mode=RBAC",
+		},
+		{
+			description: "kubelet field",
+			value:       "kubelet.CgroupDriver=systemd",
+		},
+		{
+			description: "unknown component",
+			value:       "kube-dns.Domain=cluster.local",
+			shouldErr:   true,
+		},
+		{
+			description: "flag name",
+			value:       "apiserver.authorization-mode=RBAC",
+			shouldErr:   true,
+		},
+		{
+			description: "whitespace",
+			value:       "apiserver.AdmissionControl=a b",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			e, err := ParseExtraOptions([]string{test.value})
+			if err != nil {
+				t.Fatalf("Error parsing %s: %s", test.value, err)
+			}
+			err = e.Validate()
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error validating %s: %s", test.value, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error validating %s", test.value)
+			}
+		})
+	}
+}
+
+func TestMergeExtraOptions(t *testing.T) {
+	var tests = []struct {
+		description string
+		stored      []string
+		passed      []string
+		expected    []string
+	}{
+		{
+			description: "nothing stored",
+			passed:      []string{"kubelet.MaxPods=5"},
+			expected:    []string{"kubelet.MaxPods=5"},
+		},
+		{
+			description: "nothing passed",
+			stored:      []string{"kubelet.MaxPods=5"},
+			expected:    []string{"kubelet.MaxPods=5"},
+		},
+		{
+			description: "new key",
+			stored:      []string{"kubelet.MaxPods=5"},
+			passed:      []string{"apiserver.Authorization.Mode=RBAC"},
+			expected:    []string{"kubelet.MaxPods=5", "apiserver.Authorization.Mode=RBAC"},
+		},
+		{
+			description: "replaced value",
+			stored:      []string{"kubelet.MaxPods=5", "apiserver.Authorization.Mode=AlwaysAllow"},
+			passed:      []string{"apiserver.Authorization.Mode=RBAC", "kubelet.MaxPods=10"},
+			expected:    []string{"kubelet.MaxPods=10", "apiserver.Authorization.Mode=RBAC"},
+		},
+		{
+			description: "same key for another component",
+			stored:      []string{"kubelet.MaxPods=5"},
+			passed:      []string{"scheduler.MaxPods=10"},
+			expected:    []string{"kubelet.MaxPods=5", "scheduler.MaxPods=10"},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			stored, err := ParseExtraOptions(test.stored)
+			if err != nil {
+				t.Fatalf("Error parsing %v: %s", test.stored, err)
+			}
+			passed, err := ParseExtraOptions(test.passed)
+			if err != nil {
+				t.Fatalf("Error parsing %v: %s", test.passed, err)
+			}
+			merged := stored.Merge(passed).Strings()
+			if !reflect.DeepEqual(merged, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, merged)
+			}
+			if len(stored) != len(test.stored) {
+				t.Errorf("Expected merging not to change the stored options, got %v", stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// featureGateName matches the name of a Kubernetes feature gate, such as AllAlpha.
+var featureGateName = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// ParseFeatureGates parses a comma separated list of key=bool pairs, as the
+// --feature-gates flag of the Kubernetes components takes them.
+func ParseFeatureGates(gates string) (map[string]bool, error) {
+	parsed := map[string]bool{}
+	for _, pair := range strings.Split(gates, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid feature gate %q, feature gates must be given as key=true or key=false", pair)
+		}
+		key := strings.TrimSpace(kv[0])
+		if !featureGateName.MatchString(key) {
+			return nil, fmt.Errorf("Invalid feature gate name %q in %q", key, pair)
+		}
+		value, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for feature gate %s in %q, it must be true or false", key, pair)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// NormalizeFeatureGates validates the feature gates and returns them sorted by name,
+// so that the same gates passed in another order give the same value.
+func NormalizeFeatureGates(gates string) (string, error) {
+	parsed, err := ParseFeatureGates(gates)
+	if err != nil {
+		return "", err
+	}
+	return FormatFeatureGates(parsed), nil
+}
+
+// FormatFeatureGates returns the feature gates as the --feature-gates flag takes them, sorted by name.
+func FormatFeatureGates(gates map[string]bool) string {
+	keys := []string{}
+	for k := range gates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", k, gates[k]))
+	}
+	return strings.Join(pairs, ",")
+}
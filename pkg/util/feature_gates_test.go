@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "testing"
+
+func TestNormalizeFeatureGates(t *testing.T) {
+	tests := []struct {
+		description string
+		gates       string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "empty",
+			gates:       "",
+			expected:    "",
+		},
+		{
+			description: "sorted",
+			gates:       "StreamingProxyRedirects=false,AllAlpha=true",
+			expected:    "AllAlpha=true,StreamingProxyRedirects=false",
+		},
+		{
+			description: "spaces and other bool spellings",
+			gates:       " AllAlpha = True , AppArmor=0,",
+			expected:    "AllAlpha=true,AppArmor=false",
+		},
+		{
+			description: "repeated gate keeps the last value",
+			gates:       "AllAlpha=true,AllAlpha=false",
+			expected:    "AllAlpha=false",
+		},
+		{
+			description: "missing value",
+			gates:       "AllAlpha",
+			shouldErr:   true,
+		},
+		{
+			description: "not a bool",
+			gates:       "AllAlpha=yes",
+			shouldErr:   true,
+		},
+		{
+			description: "not a gate name",
+			gates:       "all-alpha=true",
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := NormalizeFeatureGates(test.gates)
+			if err != nil && !test.shouldErr {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err == nil && test.shouldErr {
+				t.Fatalf("Expected an error, got %q", actual)
+			}
+			if actual != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"k8s.io/minikube/pkg/minikube/console"
+)
+
+// ProgressReporter writes the output of concurrent tasks a whole line at a time,
+// so that their lines don't interleave. Progress bars, which redraw their line
+// after a carriage return, only have their final state written.
+type ProgressReporter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewProgressReporter(out io.Writer) *ProgressReporter {
+	return &ProgressReporter{out: out}
+}
+
+// Println writes a line.
+func (p *ProgressReporter) Println(a ...interface{}) {
+	p.writeLine(strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+}
+
+// Writer returns a writer for the output of one task.
+func (p *ProgressReporter) Writer() io.Writer {
+	return &lineWriter{reporter: p}
+}
+
+func (p *ProgressReporter) writeLine(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.out, line)
+}
+
+// TerminalWriter returns w if it is a terminal, which progress bars can redraw their line on.
+// Otherwise it returns a writer which writes whole lines to w, only the final state of
+// progress bars, so that no control characters end up in logs.
+func TerminalWriter(w io.Writer) io.Writer {
+	if console.IsTerminal(w) {
+		return w
+	}
+	return NewProgressReporter(w).Writer()
+}
+
+// lineWriter buffers the current line until it is complete. A carriage return starts
+// the line over, except for the one of a CRLF, which ends the line as a newline does.
+type lineWriter struct {
+	reporter *ProgressReporter
+	line     []byte
+	// cr is whether the last byte was a carriage return. The line is only started over
+	// at the byte after it, so that a CRLF split across writes still ends the line.
+	cr bool
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	for _, c := range b {
+		switch {
+		case c == '\n':
+			w.endLine()
+		case c == '\r':
+		case w.cr:
+			w.line = append(w.line[:0], c)
+		default:
+			w.line = append(w.line, c)
+		}
+		w.cr = c == '\r'
+	}
+	return len(b), nil
+}
+
+// Close writes the pending line, such as the final state of a progress bar which
+// wasn't followed by a newline. It doesn't close the output of the reporter.
+func (w *lineWriter) Close() error {
+	if len(w.line) > 0 {
+		w.endLine()
+	}
+	return nil
+}
+
+func (w *lineWriter) endLine() {
+	w.reporter.writeLine(strings.TrimRight(string(w.line), " "))
+	w.line = w.line[:0]
+}
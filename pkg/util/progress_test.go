@@ -0,0 +1,121 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "gopkg.in/cheggaaa/pb.v1"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgressReporter(&out)
+	var wg sync.WaitGroup
+	for _, name := range []string{"iso", "localkube"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			w := p.Writer()
+			fmt.Fprintf(w, "Downloading %s\n", name)
+			for i := 0; i <= 100; i += 10 {
+				// Written in pieces like a progress bar would be.
+				fmt.Fprintf(w, "\r%s ", name)
+				fmt.Fprintf(w, "%d%%   ", i)
+			}
+			fmt.Fprintln(w)
+		}(name)
+	}
+	p.Println("Starting VM...")
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	sort.Strings(lines)
+	expected := []string{"Downloading iso", "Downloading localkube", "Starting VM...", "iso 100%", "localkube 100%"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+}
+
+func TestTerminalWriterPiped(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Error creating pipe: %s", err)
+	}
+	defer r.Close()
+	go func() {
+		defer w.Close()
+		out := TerminalWriter(w)
+		fmt.Fprintln(out, "Downloading Minikube ISO")
+		bar := pb.New64(100).SetUnits(pb.U_BYTES).SetMaxWidth(80)
+		bar.Output = out
+		// Updated by hand rather than started, which would redraw it from another goroutine.
+		for i := 0; i < 100; i += 10 {
+			bar.Add(10)
+			bar.Update()
+		}
+		bar.Finish()
+	}()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error reading pipe: %s", err)
+	}
+	if strings.ContainsAny(string(data), "\r\x1b") {
+		t.Errorf("Expected no control characters written to a pipe, got %q", data)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "Downloading Minikube ISO" || !strings.Contains(lines[1], "100.00%") {
+		t.Errorf("Expected the message and the final state of the bar, got %q", lines)
+	}
+}
+
+func TestLineWriterCarriageReturns(t *testing.T) {
+	var tests = []struct {
+		description string
+		written     []string
+		expected    string
+	}{
+		{description: "crlf", written: []string{"Pulling\r\n", "Done\r\n"}, expected: "Pulling\nDone\n"},
+		{description: "crlf split across writes", written: []string{"Pulling\r", "\nDone\r", "\n"}, expected: "Pulling\nDone\n"},
+		{description: "progress bar", written: []string{"\r 50%", "\r 50%  \r100%\n"}, expected: "100%\n"},
+		{description: "final state ended by a carriage return", written: []string{"\r 50%", "\r100%\r"}, expected: "100%\n"},
+		{description: "final state without a newline", written: []string{"\r 50%\r100%"}, expected: "100%\n"},
+		{description: "empty lines", written: []string{"a\n\nb\n\r\r"}, expected: "a\n\nb\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			var out bytes.Buffer
+			w := NewProgressReporter(&out).Writer()
+			for _, s := range test.written {
+				fmt.Fprint(w, s)
+			}
+			w.(io.Closer).Close()
+			if out.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ValidateInsecureRegistry checks that entry is a registry the docker daemon can
+// trust without TLS: a host:port, such as registry.lan:5000, or a CIDR, such as 192.168.1.0/24.
+func ValidateInsecureRegistry(entry string) error {
+	if strings.Contains(entry, "/") {
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("Invalid insecure registry %q, a CIDR such as 192.168.1.0/24 was expected", entry)
+		}
+		return nil
+	}
+	host, port, err := net.SplitHostPort(entry)
+	if err != nil || host == "" || (strings.ContainsAny(host, ":@ ") && net.ParseIP(host) == nil) {
+		return fmt.Errorf("Invalid insecure registry %q, a host:port such as registry.lan:5000 was expected", entry)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("Invalid insecure registry %q, its port must be a number from 1 to 65535", entry)
+	}
+	return nil
+}
+
+// MergeInsecureRegistries merges the lists of insecure registries, keeping the first
+// of each entry. CIDRs are compared by their network, so 10.0.0.1/24 is 10.0.0.0/24.
+func MergeInsecureRegistries(lists ...[]string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, entry := range list {
+			entry = strings.TrimSpace(entry)
+			if _, n, err := net.ParseCIDR(entry); err == nil {
+				entry = n.String()
+			}
+			if entry == "" || seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// InsecureRegistryCovered returns whether the docker daemon trusts registry, a host:port, without
+// TLS given the insecure registries: it is one of them, or its IP is in one of their CIDRs.
+func InsecureRegistryCovered(registries []string, registry string) bool {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+	}
+	ip := net.ParseIP(host)
+	for _, entry := range registries {
+		entry = strings.TrimSpace(entry)
+		if entry == registry {
+			return true
+		}
+		if _, n, err := net.ParseCIDR(entry); err == nil && ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRegistryMirror checks that mirror is the URL of a registry mirror, such as https://mirror.lan:5000.
+func ValidateRegistryMirror(mirror string) error {
+	u, err := url.Parse(mirror)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid registry mirror %q, an http or https URL such as https://mirror.lan:5000 was expected", mirror)
+	}
+	return nil
+}
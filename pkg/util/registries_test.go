@@ -0,0 +1,207 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateInsecureRegistry(t *testing.T) {
+	tests := []struct {
+		description string
+		entry       string
+		shouldErr   bool
+	}{
+		{
+			description: "host and port",
+			entry:       "registry.lan:5000",
+		},
+		{
+			description: "ip and port",
+			entry:       "192.168.1.20:5000",
+		},
+		{
+			description: "ipv6 and port",
+			entry:       "[fd00::20]:5000",
+		},
+		{
+			description: "cidr",
+			entry:       "192.168.1.0/24",
+		},
+		{
+			description: "host without port",
+			entry:       "registry.lan",
+			shouldErr:   true,
+		},
+		{
+			description: "port out of range",
+			entry:       "registry.lan:70000",
+			shouldErr:   true,
+		},
+		{
+			description: "url",
+			entry:       "http://registry.lan:5000",
+			shouldErr:   true,
+		},
+		{
+			description: "invalid cidr",
+			entry:       "192.168.1.0/33",
+			shouldErr:   true,
+		},
+		{
+			description: "empty",
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateInsecureRegistry(test.entry)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error validating %q: %s", test.entry, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error validating %q", test.entry)
+			}
+		})
+	}
+}
+
+func TestMergeInsecureRegistries(t *testing.T) {
+	tests := []struct {
+		description string
+		lists       [][]string
+		expected    []string
+	}{
+		{
+			description: "none",
+			expected:    []string{},
+		},
+		{
+			description: "appended in order",
+			lists:       [][]string{{"10.0.0.0/24"}, {"registry.lan:5000"}, {"192.168.1.0/24"}},
+			expected:    []string{"10.0.0.0/24", "registry.lan:5000", "192.168.1.0/24"},
+		},
+		{
+			description: "duplicates dropped",
+			lists:       [][]string{{"10.0.0.0/24", "registry.lan:5000"}, {"registry.lan:5000", " 10.0.0.0/24"}},
+			expected:    []string{"10.0.0.0/24", "registry.lan:5000"},
+		},
+		{
+			description: "cidrs compared by network",
+			lists:       [][]string{{"192.168.1.0/24"}, {"192.168.1.7/24"}},
+			expected:    []string{"192.168.1.0/24"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if merged := MergeInsecureRegistries(test.lists...); !reflect.DeepEqual(merged, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, merged)
+			}
+		})
+	}
+}
+
+func TestInsecureRegistryCovered(t *testing.T) {
+	tests := []struct {
+		description string
+		registries  []string
+		registry    string
+		expected    bool
+	}{
+		{
+			description: "none",
+			registry:    "192.168.99.100:30500",
+		},
+		{
+			description: "listed",
+			registries:  []string{"10.0.0.0/24", "192.168.99.100:30500"},
+			registry:    "192.168.99.100:30500",
+			expected:    true,
+		},
+		{
+			description: "other port",
+			registries:  []string{"192.168.99.100:5000"},
+			registry:    "192.168.99.100:30500",
+		},
+		{
+			description: "in a cidr",
+			registries:  []string{"192.168.99.0/24"},
+			registry:    "192.168.99.100:30500",
+			expected:    true,
+		},
+		{
+			description: "outside the cidrs",
+			registries:  []string{"10.0.0.0/24", "192.168.1.0/24"},
+			registry:    "192.168.99.100:30500",
+		},
+		{
+			description: "host name",
+			registries:  []string{"192.168.99.0/24"},
+			registry:    "registry.lan:5000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if covered := InsecureRegistryCovered(test.registries, test.registry); covered != test.expected {
+				t.Errorf("Expected covered %t, got %t", test.expected, covered)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryMirror(t *testing.T) {
+	tests := []struct {
+		description string
+		mirror      string
+		shouldErr   bool
+	}{
+		{
+			description: "https",
+			mirror:      "https://mirror.lan:5000",
+		},
+		{
+			description: "http",
+			mirror:      "http://192.168.1.20",
+		},
+		{
+			description: "no scheme",
+			mirror:      "mirror.lan:5000",
+			shouldErr:   true,
+		},
+		{
+			description: "other scheme",
+			mirror:      "tcp://mirror.lan:5000",
+			shouldErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := ValidateRegistryMirror(test.mirror)
+			if err != nil && !test.shouldErr {
+				t.Errorf("Unexpected error validating %q: %s", test.mirror, err)
+			}
+			if err == nil && test.shouldErr {
+				t.Errorf("Expected an error validating %q", test.mirror)
+			}
+		})
+	}
+}
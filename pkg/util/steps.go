@@ -0,0 +1,271 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// The phases minikube start reports its progress in.
+const (
+	StepPreflight         = "preflight"
+	StepISODownload       = "iso-download"
+	StepLocalkubeDownload = "localkube-download"
+	StepCreatingVM        = "creating-vm"
+	StepProvisioning      = "provisioning"
+	StepBootstrapping     = "bootstrapping"
+	StepKubeconfig        = "kubeconfig"
+	StepWaiting           = "waiting"
+)
+
+// The types of StepEvent.
+const (
+	EventStarted   = "started"
+	EventCompleted = "completed"
+	EventProgress  = "progress"
+	EventError     = "error"
+)
+
+// StepEvent is an event of the machine-readable stream of a StepReporter.
+type StepEvent struct {
+	Type string `json:"type"`
+	Step string `json:"step,omitempty"`
+	// Percent, Current and Total are how far a download got, in progress events.
+	Percent *int  `json:"percent,omitempty"`
+	Current int64 `json:"current,omitempty"`
+	Total   int64 `json:"total,omitempty"`
+	// Error is what ended the start, in error events.
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// StepReporter reports the phases of a start. The human readable text goes through a
+// ProgressReporter. A JSON StepReporter also writes a StepEvent, one JSON object per line,
+// each time a phase starts or completes and each time a download gets a percent further.
+// It also times the phases. A nil StepReporter prints the text to stdout.
+type StepReporter struct {
+	text *ProgressReporter
+
+	mu        sync.Mutex
+	events    *json.Encoder
+	active    []string
+	done      bool
+	now       func() time.Time
+	began     time.Time
+	started   map[string]time.Time
+	durations map[string]time.Duration
+}
+
+// NewStepReporter returns a StepReporter which only writes text to out.
+func NewStepReporter(out io.Writer) *StepReporter {
+	return &StepReporter{
+		text:      NewProgressReporter(out),
+		now:       time.Now,
+		began:     time.Now(),
+		started:   map[string]time.Time{},
+		durations: map[string]time.Duration{},
+	}
+}
+
+// NewJSONStepReporter returns a StepReporter which writes its events to events and text to out.
+func NewJSONStepReporter(events, out io.Writer) *StepReporter {
+	r := NewStepReporter(out)
+	r.events = json.NewEncoder(events)
+	return r
+}
+
+// JSON returns whether the reporter writes events.
+func (r *StepReporter) JSON() bool {
+	return r != nil && r.events != nil
+}
+
+// Println writes a line of text.
+func (r *StepReporter) Println(a ...interface{}) {
+	if r == nil {
+		fmt.Println(a...)
+		return
+	}
+	r.text.Println(a...)
+}
+
+// Writer returns a writer for the text of one task of step. Downloads written
+// to it report their progress as events of step.
+func (r *StepReporter) Writer(step string) io.Writer {
+	if r == nil {
+		return TerminalWriter(os.Stdout)
+	}
+	return &stepWriter{Writer: r.text.Writer(), reporter: r, step: step, percent: -1}
+}
+
+// Start reports that step started. Starting a step which is in progress does nothing,
+// so that the tasks making up a step can each start it.
+func (r *StepReporter) Start(step string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexOf(step) >= 0 {
+		return
+	}
+	r.active = append(r.active, step)
+	r.started[step] = r.now()
+	r.emit(StepEvent{Type: EventStarted, Step: step})
+}
+
+// Complete reports that step completed.
+func (r *StepReporter) Complete(step string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(step)
+	if i < 0 {
+		return
+	}
+	r.active = append(r.active[:i], r.active[i+1:]...)
+	r.durations[step] = r.now().Sub(r.started[step])
+	r.emit(StepEvent{Type: EventCompleted, Step: step})
+}
+
+// Fail reports err as ending the start, in whichever of steps started last and hasn't
+// completed, or in the first of steps if none is in progress. Without steps, the step
+// started last is blamed. No events are written after it.
+func (r *StepReporter) Fail(err error, steps ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step := ""
+	if len(steps) > 0 {
+		step = steps[0]
+	}
+	for i := len(r.active) - 1; i >= 0; i-- {
+		if len(steps) == 0 || contains(steps, r.active[i]) {
+			step = r.active[i]
+			break
+		}
+	}
+	if start, ok := r.started[step]; ok && r.indexOf(step) >= 0 {
+		r.durations[step] = r.now().Sub(start)
+	}
+	r.emit(StepEvent{Type: EventError, Step: step, Error: err.Error()})
+	r.done = true
+}
+
+// Durations returns how long each step took, by step name. A step which
+// failed took until it failed, steps still in progress are left out.
+func (r *StepReporter) Durations() map[string]time.Duration {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	durations := map[string]time.Duration{}
+	for step, d := range r.durations {
+		durations[step] = d
+	}
+	return durations
+}
+
+// Elapsed returns how long ago the reporter was created, which is when the start began.
+func (r *StepReporter) Elapsed() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.now().Sub(r.began)
+}
+
+func (r *StepReporter) progress(step string, percent int, current, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emit(StepEvent{Type: EventProgress, Step: step, Percent: &percent, Current: current, Total: total})
+}
+
+// emit writes e, with r.mu held.
+func (r *StepReporter) emit(e StepEvent) {
+	if r.events == nil || r.done {
+		return
+	}
+	e.Time = r.now()
+	r.events.Encode(e)
+}
+
+func (r *StepReporter) indexOf(step string) int {
+	for i, s := range r.active {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// progressTicker is implemented by writers which report how far a download got.
+type progressTicker interface {
+	Tick(current, total int64)
+}
+
+// stepWriter writes the text of a task, and reports the progress of its downloads.
+type stepWriter struct {
+	io.Writer
+	reporter *StepReporter
+	step     string
+	percent  int
+}
+
+// Tick reports that current bytes out of total were downloaded, once per percent.
+func (w *stepWriter) Tick(current, total int64) {
+	if !w.reporter.JSON() || total <= 0 {
+		return
+	}
+	percent := int(current * 100 / total)
+	if percent == w.percent {
+		return
+	}
+	w.percent = percent
+	w.reporter.progress(w.step, percent, current, total)
+}
+
+// tickReader ticks t as a download is read.
+type tickReader struct {
+	io.Reader
+	t              progressTicker
+	current, total int64
+}
+
+func (r *tickReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.current += int64(n)
+	r.t.Tick(r.current, r.total)
+	return n, err
+}
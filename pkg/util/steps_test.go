@@ -0,0 +1,287 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var stepNames = map[string]bool{
+	StepPreflight:         true,
+	StepISODownload:       true,
+	StepLocalkubeDownload: true,
+	StepCreatingVM:        true,
+	StepProvisioning:      true,
+	StepBootstrapping:     true,
+	StepKubeconfig:        true,
+	StepWaiting:           true,
+}
+
+// readEvents parses the event stream, checking that each line is a JSON object with
+// only the fields of its event type.
+func readEvents(t *testing.T, stream []byte) []StepEvent {
+	var events []StepEvent
+	scanner := bufio.NewScanner(bytes.NewReader(stream))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var fields map[string]interface{}
+		if err := json.Unmarshal(line, &fields); err != nil {
+			t.Fatalf("Event %q is not a JSON object: %s", line, err)
+		}
+		allowed := map[string]bool{"type": true, "step": true, "time": true}
+		switch fields["type"] {
+		case EventStarted, EventCompleted:
+		case EventProgress:
+			allowed["percent"], allowed["current"], allowed["total"] = true, true, true
+			if _, ok := fields["percent"].(float64); !ok {
+				t.Errorf("Progress event %q has no percent", line)
+			}
+		case EventError:
+			allowed["error"] = true
+			if msg, _ := fields["error"].(string); msg == "" {
+				t.Errorf("Error event %q has no error", line)
+			}
+		default:
+			t.Errorf("Event %q has an unknown type", line)
+		}
+		for k := range fields {
+			if !allowed[k] {
+				t.Errorf("Event %q has an unexpected field %s", line, k)
+			}
+		}
+		if step, ok := fields["step"].(string); ok && !stepNames[step] {
+			t.Errorf("Event %q has an unknown step", line)
+		}
+		if _, err := time.Parse(time.RFC3339, fmt.Sprint(fields["time"])); err != nil {
+			t.Errorf("Event %q has an invalid time: %s", line, err)
+		}
+
+		var e StepEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatalf("Error parsing event %q: %s", line, err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// eventSummary describes an event as type:step[:percent].
+func eventSummary(e StepEvent) string {
+	s := e.Type + ":" + e.Step
+	if e.Percent != nil {
+		s += fmt.Sprintf(":%d", *e.Percent)
+	}
+	return s
+}
+
+func TestStepReporterDurations(t *testing.T) {
+	r := NewStepReporter(ioutil.Discard)
+	clock := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return clock }
+	r.began = clock
+	tick := func(d time.Duration) { clock = clock.Add(d) }
+
+	// A fake start, whose VM fails to be provisioned.
+	r.Start(StepPreflight)
+	tick(time.Second)
+	r.Complete(StepPreflight)
+	r.Start(StepISODownload)
+	r.Start(StepCreatingVM)
+	tick(10 * time.Second)
+	r.Complete(StepISODownload)
+	tick(20 * time.Second)
+	r.Complete(StepCreatingVM)
+	r.Start(StepProvisioning)
+	r.Start(StepLocalkubeDownload)
+	tick(5 * time.Second)
+	r.Fail(errors.New("ssh: handshake failed"), StepCreatingVM, StepProvisioning)
+
+	expected := map[string]time.Duration{
+		StepPreflight:    time.Second,
+		StepISODownload:  10 * time.Second,
+		StepCreatingVM:   30 * time.Second,
+		StepProvisioning: 5 * time.Second,
+	}
+	if got := r.Durations(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected durations %v, got %v", expected, got)
+	}
+	if got := r.Elapsed(); got != 36*time.Second {
+		t.Errorf("Expected the start to take 36s, got %s", got)
+	}
+}
+
+func TestStepReporterJSON(t *testing.T) {
+	var events, text bytes.Buffer
+	r := NewJSONStepReporter(&events, &text)
+	r.now = func() time.Time { return time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	r.Start(StepPreflight)
+	r.Complete(StepPreflight)
+	r.Start(StepISODownload)
+	w := r.Writer(StepISODownload)
+	fmt.Fprintln(w, "Downloading Minikube ISO")
+	for _, n := range []int64{0, 1, 2, 50, 100} {
+		w.(progressTicker).Tick(n, 100)
+	}
+	r.Complete(StepISODownload)
+	r.Start(StepCreatingVM)
+	r.Start(StepBootstrapping)
+	r.Start(StepBootstrapping)
+	r.Complete(StepBootstrapping)
+	r.Println("Starting VM...")
+	r.Fail(errors.New("VT-x is not available"), StepCreatingVM, StepProvisioning)
+	r.Complete(StepCreatingVM)
+
+	var got []string
+	for _, e := range readEvents(t, events.Bytes()) {
+		got = append(got, eventSummary(e))
+	}
+	expected := []string{
+		"started:preflight",
+		"completed:preflight",
+		"started:iso-download",
+		"progress:iso-download:0",
+		"progress:iso-download:1",
+		"progress:iso-download:2",
+		"progress:iso-download:50",
+		"progress:iso-download:100",
+		"completed:iso-download",
+		"started:creating-vm",
+		"started:bootstrapping",
+		"completed:bootstrapping",
+		"error:creating-vm",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected events %q, got %q", expected, got)
+	}
+	if text.String() != "Downloading Minikube ISO\nStarting VM...\n" {
+		t.Errorf("Expected the text to be written apart from the events, got %q", text.String())
+	}
+}
+
+func TestStepReporterFail(t *testing.T) {
+	var tests = []struct {
+		description string
+		started     []string
+		steps       []string
+		expected    string
+	}{
+		{
+			description: "step in progress",
+			started:     []string{StepCreatingVM, StepProvisioning},
+			steps:       []string{StepCreatingVM, StepProvisioning},
+			expected:    StepProvisioning,
+		},
+		{
+			description: "other steps in progress",
+			started:     []string{StepLocalkubeDownload},
+			steps:       []string{StepISODownload},
+			expected:    StepISODownload,
+		},
+		{
+			description: "step started last",
+			started:     []string{StepPreflight, StepKubeconfig},
+			expected:    StepKubeconfig,
+		},
+		{
+			description: "nothing started",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			var events bytes.Buffer
+			r := NewJSONStepReporter(&events, ioutil.Discard)
+			for _, s := range test.started {
+				r.Start(s)
+			}
+			r.Fail(errors.New("failed"), test.steps...)
+
+			all := readEvents(t, events.Bytes())
+			last := all[len(all)-1]
+			if last.Type != EventError || last.Step != test.expected || last.Error != "failed" {
+				t.Errorf("Expected an error event in %q, got %+v", test.expected, last)
+			}
+		})
+	}
+}
+
+func TestStepReporterText(t *testing.T) {
+	var text bytes.Buffer
+	r := NewStepReporter(&text)
+	r.Start(StepISODownload)
+	w := r.Writer(StepISODownload)
+	fmt.Fprint(w, "\r 50%\r100%\n")
+	w.(progressTicker).Tick(100, 100)
+	r.Fail(errors.New("failed"))
+	if r.JSON() || text.String() != "100%\n" {
+		t.Errorf("Expected only the text, got %q", text.String())
+	}
+
+	var nilReporter *StepReporter
+	nilReporter.Start(StepPreflight)
+	nilReporter.Fail(errors.New("failed"))
+}
+
+func TestDownloadProgressEvents(t *testing.T) {
+	data := strings.Repeat("minikube", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "minikube.iso", time.Time{}, strings.NewReader(data))
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "minikube-steps")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var events bytes.Buffer
+	r := NewJSONStepReporter(&events, ioutil.Discard)
+	if err := downloadToFile(server.URL, filepath.Join(dir, "minikube.iso"), r.Writer(StepISODownload)); err != nil {
+		t.Fatalf("Error downloading: %s", err)
+	}
+
+	all := readEvents(t, events.Bytes())
+	if len(all) == 0 {
+		t.Fatal("Expected progress events")
+	}
+	previous := -1
+	for _, e := range all {
+		if e.Type != EventProgress || e.Step != StepISODownload || *e.Percent <= previous || e.Total != int64(len(data)) {
+			t.Fatalf("Expected increasing progress events of %s, got %+v", StepISODownload, e)
+		}
+		previous = *e.Percent
+	}
+	if previous != 100 {
+		t.Errorf("Expected the download to end at 100%%, it ended at %d%%", previous)
+	}
+}
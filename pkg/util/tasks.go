@@ -0,0 +1,135 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Task is a step of a command which runs concurrently with the steps it doesn't depend on.
+type Task struct {
+	Name string
+	// Deps are the names of the tasks which must succeed before this one starts.
+	Deps []string
+	Run  func() error
+}
+
+// TaskError is the error of the task which failed first.
+type TaskError struct {
+	Task string
+	Err  error
+}
+
+func (e TaskError) Error() string { return fmt.Sprintf("%s: %s", e.Task, e.Err) }
+
+// RunTasks runs each task as soon as the tasks it depends on have succeeded.
+// Once a task fails no other task is started, and when the running ones have
+// finished its error is returned as a TaskError. The durations of the tasks
+// which ran are returned either way.
+func RunTasks(tasks []Task) (map[string]time.Duration, error) {
+	if err := checkTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		durations = map[string]time.Duration{}
+		firstErr  error
+	)
+	done := map[string]chan struct{}{}
+	for _, t := range tasks {
+		done[t.Name] = make(chan struct{})
+	}
+	for _, t := range tasks {
+		wg.Add(1)
+		go func(t Task) {
+			defer wg.Done()
+			defer close(done[t.Name])
+			for _, d := range t.Deps {
+				<-done[d]
+			}
+			mu.Lock()
+			failed := firstErr != nil
+			mu.Unlock()
+			if failed {
+				return
+			}
+
+			start := time.Now()
+			err := t.Run()
+			mu.Lock()
+			defer mu.Unlock()
+			durations[t.Name] = time.Since(start)
+			if err != nil && firstErr == nil {
+				firstErr = TaskError{Task: t.Name, Err: err}
+			}
+		}(t)
+	}
+	wg.Wait()
+	return durations, firstErr
+}
+
+// checkTasks checks that the task names are unique and that their
+// dependencies exist and don't form a cycle, which would never finish.
+func checkTasks(tasks []Task) error {
+	deps := map[string][]string{}
+	for _, t := range tasks {
+		if _, ok := deps[t.Name]; ok {
+			return fmt.Errorf("Task %s is defined twice", t.Name)
+		}
+		deps[t.Name] = t.Deps
+	}
+	for _, t := range tasks {
+		for _, d := range t.Deps {
+			if _, ok := deps[d]; !ok {
+				return fmt.Errorf("Task %s depends on unknown task %s", t.Name, d)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("Task %s depends on itself", name)
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		for _, d := range deps[name] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+		return nil
+	}
+	for _, t := range tasks {
+		if err := visit(t.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
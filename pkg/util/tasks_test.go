@@ -0,0 +1,216 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// taskRecorder records the order in which fake tasks start and finish.
+type taskRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *taskRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *taskRecorder) index(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *taskRecorder) task(name string, err error, deps ...string) Task {
+	return Task{
+		Name: name,
+		Deps: deps,
+		Run: func() error {
+			r.record("start " + name)
+			time.Sleep(time.Millisecond)
+			r.record("finish " + name)
+			return err
+		},
+	}
+}
+
+func TestRunTasksOrder(t *testing.T) {
+	var tests = []struct {
+		description string
+		deps        map[string][]string
+	}{
+		{
+			description: "independent tasks",
+			deps:        map[string][]string{"iso": nil, "localkube": nil, "preflight": nil},
+		},
+		{
+			description: "chain",
+			deps:        map[string][]string{"iso": nil, "vm": {"iso"}, "cluster": {"vm"}},
+		},
+		{
+			description: "start",
+			deps: map[string][]string{
+				"iso":       nil,
+				"localkube": nil,
+				"preflight": nil,
+				"vm":        {"iso", "preflight"},
+				"images":    {"vm"},
+				"update":    {"vm", "localkube"},
+				"certs":     {"vm"},
+				"cluster":   {"update", "certs", "images"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			r := &taskRecorder{}
+			var tasks []Task
+			for name, deps := range test.deps {
+				tasks = append(tasks, r.task(name, nil, deps...))
+			}
+			durations, err := RunTasks(tasks)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			for name, deps := range test.deps {
+				if _, ok := durations[name]; !ok {
+					t.Errorf("No duration for task %s", name)
+				}
+				start := r.index("start " + name)
+				if start < 0 {
+					t.Errorf("Task %s didn't run", name)
+				}
+				for _, d := range deps {
+					if finish := r.index("finish " + d); finish > start {
+						t.Errorf("Task %s started before its dependency %s finished: %v", name, d, r.events)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRunTasksConcurrently(t *testing.T) {
+	isoStarted := make(chan struct{})
+	preflightStarted := make(chan struct{})
+	// Each task waits for the other one to start, which only happens if they run at the same time.
+	task := func(name string, started, other chan struct{}) Task {
+		return Task{Name: name, Run: func() error {
+			close(started)
+			select {
+			case <-other:
+				return nil
+			case <-time.After(5 * time.Second):
+				return fmt.Errorf("The other task didn't start")
+			}
+		}}
+	}
+	tasks := []Task{
+		task("iso", isoStarted, preflightStarted),
+		task("preflight", preflightStarted, isoStarted),
+	}
+	if _, err := RunTasks(tasks); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestRunTasksFailure(t *testing.T) {
+	r := &taskRecorder{}
+	failure := fmt.Errorf("No ISO")
+	tasks := []Task{
+		r.task("iso", failure),
+		{
+			Name: "localkube",
+			Run: func() error {
+				// Still running when iso fails, so it is waited for.
+				time.Sleep(20 * time.Millisecond)
+				r.record("finish localkube")
+				return nil
+			},
+		},
+		r.task("vm", nil, "iso"),
+		r.task("cluster", nil, "vm", "localkube"),
+	}
+	durations, err := RunTasks(tasks)
+	taskErr, ok := err.(TaskError)
+	if !ok {
+		t.Fatalf("Expected a TaskError, got %v", err)
+	}
+	if taskErr.Task != "iso" || taskErr.Err != failure {
+		t.Errorf("Expected the error of iso, got %v", taskErr)
+	}
+	if r.index("finish localkube") < 0 {
+		t.Errorf("The running task wasn't waited for: %v", r.events)
+	}
+	for _, name := range []string{"vm", "cluster"} {
+		if r.index("start "+name) >= 0 {
+			t.Errorf("Task %s ran after its dependency failed", name)
+		}
+		if _, ok := durations[name]; ok {
+			t.Errorf("Task %s which didn't run has a duration", name)
+		}
+	}
+}
+
+func TestRunTasksInvalid(t *testing.T) {
+	noop := func() error { return nil }
+	var tests = []struct {
+		description string
+		tasks       []Task
+	}{
+		{
+			description: "duplicate task",
+			tasks:       []Task{{Name: "iso", Run: noop}, {Name: "iso", Run: noop}},
+		},
+		{
+			description: "unknown dependency",
+			tasks:       []Task{{Name: "vm", Deps: []string{"iso"}, Run: noop}},
+		},
+		{
+			description: "cycle",
+			tasks: []Task{
+				{Name: "iso", Run: noop},
+				{Name: "vm", Deps: []string{"iso", "cluster"}, Run: noop},
+				{Name: "cluster", Deps: []string{"vm"}, Run: noop},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+			if _, err := RunTasks(test.tasks); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -162,3 +162,46 @@ Error 2`
 		t.Fatalf("Unexpected error: %s", err)
 	}
 }
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		distance int
+	}{
+		{a: "memory", b: "memory", distance: 0},
+		{a: "memroy", b: "memory", distance: 2},
+		{a: "vm_driver", b: "vm-driver", distance: 1},
+		{a: "cpu", b: "cpus", distance: 1},
+		{a: "", b: "cpus", distance: 4},
+		{a: "kitten", b: "sitting", distance: 3},
+	}
+
+	for _, test := range tests {
+		if d := EditDistance(test.a, test.b); d != test.distance {
+			t.Errorf("Expected the distance between %q and %q to be %d, got %d", test.a, test.b, test.distance, d)
+		}
+		if d := EditDistance(test.b, test.a); d != test.distance {
+			t.Errorf("Expected the distance between %q and %q to be %d, got %d", test.b, test.a, test.distance, d)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected string
+	}{
+		{s: "/var/lib/localkube/certs", expected: "/var/lib/localkube/certs"},
+		{s: "/home/docker/First Last", expected: "'/home/docker/First Last'"},
+		{s: "/tmp/Jürgen", expected: "'/tmp/Jürgen'"},
+		{s: "it's", expected: `'it'\''s'`},
+		{s: "$HOME", expected: "'$HOME'"},
+		{s: "", expected: "''"},
+	}
+
+	for _, test := range tests {
+		if q := ShellQuote(test.s); q != test.expected {
+			t.Errorf("Expected %q to be quoted as %s, got %s", test.s, test.expected, q)
+		}
+	}
+}
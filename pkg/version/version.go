@@ -32,6 +32,9 @@ var isoVersion = "v0.0.0-unset"
 
 var isoPath = "minikube/iso"
 
+// gitCommitID is the commit minikube was built from, set with -X k8s.io/minikube/pkg/version.gitCommitID.
+var gitCommitID = ""
+
 func GetVersion() string {
 	return version
 }
@@ -44,6 +47,10 @@ func GetIsoPath() string {
 	return isoPath
 }
 
+func GetGitCommitID() string {
+	return gitCommitID
+}
+
 func GetSemverVersion() (semver.Version, error) {
 	return semver.Make(strings.TrimPrefix(GetVersion(), VersionPrefix))
 }
@@ -19,9 +19,13 @@ limitations under the License.
 package integration
 
 import (
+	"bufio"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -91,8 +95,8 @@ func testDashboard(t *testing.T) {
 			return &commonutil.RetriableError{Err: fmt.Errorf("Not enough pods running. Expected %d, got %d.", rc.Status.Replicas, rc.Status.FullyLabeledReplicas)}
 		}
 
-		if svc.Spec.Ports[0].NodePort != 30000 {
-			return fmt.Errorf("Dashboard is exposed on wrong port, expected 30000, actual %d", svc.Spec.Ports[0].NodePort)
+		if svc.Spec.Type != api.ServiceTypeClusterIP {
+			return fmt.Errorf("Dashboard is exposed as a %s service, expected ClusterIP", svc.Spec.Type)
 		}
 
 		return nil
@@ -102,7 +106,20 @@ func testDashboard(t *testing.T) {
 		t.Fatalf("Dashboard is unhealthy: %s", err)
 	}
 
-	dashboardURL := minikubeRunner.RunCommand("dashboard --url", true)
+	path, _ := filepath.Abs(minikubeRunner.BinaryPath)
+	cmd := exec.Command(path, "dashboard", "--url")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Error getting the stdout of minikube dashboard: %s", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Error running minikube dashboard: %s", err)
+	}
+	defer cmd.Process.Kill()
+	dashboardURL, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Error reading the dashboard URL: %s", err)
+	}
 	u, err := url.Parse(strings.TrimSpace(dashboardURL))
 	if err != nil {
 		t.Fatalf("failed to parse dashboard URL %s: %v", dashboardURL, err)
@@ -110,12 +127,20 @@ func testDashboard(t *testing.T) {
 	if u.Scheme != "http" {
 		t.Fatalf("wrong scheme in dashboard URL, expected http, actual %s", u.Scheme)
 	}
-	_, port, err := net.SplitHostPort(u.Host)
+	host, _, err := net.SplitHostPort(u.Host)
 	if err != nil {
 		t.Fatalf("failed to split dashboard host %s: %v", u.Host, err)
 	}
-	if port != "30000" {
-		t.Fatalf("Dashboard is exposed on wrong port, expected 30000, actual %s", port)
+	if host != "127.0.0.1" {
+		t.Fatalf("Dashboard is proxied on the wrong host, expected 127.0.0.1, actual %s", host)
+	}
+	resp, err := http.Get(u.String())
+	if err != nil {
+		t.Fatalf("Error requesting the proxied dashboard: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Proxied dashboard responded %s", resp.Status)
 	}
 }
 
@@ -19,12 +19,14 @@ limitations under the License.
 package integration
 
 import (
+	"fmt"
 	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/docker/machine/libmachine/state"
+	"k8s.io/minikube/pkg/minikube/constants"
 	commonutil "k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/test/integration/util"
 )
@@ -36,7 +38,7 @@ func TestStartStop(t *testing.T) {
 		BinaryPath: *binaryPath,
 		T:          t}
 	runner.RunCommand("delete", false)
-	runner.CheckStatus(state.None.String())
+	runner.CheckStatus(constants.MachineDoesNotExist)
 
 	runner.Start()
 	runner.CheckStatus(state.Running.String())
@@ -64,5 +66,32 @@ func TestStartStop(t *testing.T) {
 	runner.CheckStatus(state.Running.String())
 
 	runner.RunCommand("delete", true)
-	runner.CheckStatus(state.None.String())
+	runner.CheckStatus(constants.MachineDoesNotExist)
+}
+
+func TestStartStopQuiet(t *testing.T) {
+	runner := util.MinikubeRunner{
+		Args:       *args,
+		BinaryPath: *binaryPath,
+		T:          t}
+	runner.RunCommand("delete", false)
+
+	// RunCommand pipes stdout, so minikube isn't interactive and mustn't redraw progress bars.
+	var tests = []struct {
+		command  string
+		expected string
+	}{
+		{command: fmt.Sprintf("start --quiet %s", runner.Args), expected: "Kubectl is now configured to use the cluster.\n"},
+		{command: "stop --quiet", expected: "Machine stopped.\n"},
+	}
+	for _, test := range tests {
+		out := runner.RunCommand(test.command, true)
+		if strings.ContainsAny(out, "\r\x1b") {
+			t.Errorf("Expected no control characters in the output of %s, got %q", test.command, out)
+		}
+		if out != test.expected {
+			t.Errorf("Expected only the result %q from %s, got %q", test.expected, test.command, out)
+		}
+	}
+	runner.RunCommand("delete", true)
 }
@@ -28,6 +28,21 @@ type ufsFid struct {
 type Ufs struct {
 	Srv
 	Root string
+	// FileMode and DirMode, when not 0, replace the permissions of the files and
+	// directories served.
+	FileMode uint32
+	DirMode  uint32
+}
+
+// mapMode replaces the permissions of st by the ones served for its type, if any.
+func (u *Ufs) mapMode(st *Dir) {
+	mode := u.FileMode
+	if st.Mode&DMDIR != 0 {
+		mode = u.DirMode
+	}
+	if mode != 0 {
+		st.Mode = st.Mode&^0777 | mode&0777
+	}
 }
 
 func toError(err error) *Error {
@@ -316,7 +331,7 @@ func (*Ufs) Create(req *SrvReq) {
 	req.RespondRcreate(dir2Qid(fid.st), 0)
 }
 
-func (*Ufs) Read(req *SrvReq) {
+func (u *Ufs) Read(req *SrvReq) {
 	fid := req.Fid.Aux.(*ufsFid)
 	tc := req.Tc
 	rc := req.Rc
@@ -353,6 +368,7 @@ func (*Ufs) Read(req *SrvReq) {
 				if st == nil {
 					continue
 				}
+				u.mapMode(st)
 				b := PackDir(st, req.Conn.Dotu)
 				fid.dirents = append(fid.dirents, b...)
 				count += len(b)
@@ -438,7 +454,7 @@ func (*Ufs) Remove(req *SrvReq) {
 	req.RespondRremove()
 }
 
-func (*Ufs) Stat(req *SrvReq) {
+func (u *Ufs) Stat(req *SrvReq) {
 	fid := req.Fid.Aux.(*ufsFid)
 	err := fid.stat()
 	if err != nil {
@@ -451,6 +467,7 @@ func (*Ufs) Stat(req *SrvReq) {
 		req.RespondError(derr)
 		return
 	}
+	u.mapMode(st)
 
 	req.RespondRstat(st)
 }
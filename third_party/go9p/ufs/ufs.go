@@ -11,12 +11,49 @@ import (
 	"k8s.io/minikube/third_party/go9p"
 )
 
-func StartServer(addrVal string, debugVal int, rootVal string) {
+// Options map the ownership and permissions of the files served, and size the 9P messages.
+type Options struct {
+	// UID and GID, when not negative, own every file served.
+	UID int
+	GID int
+	// FileMode and DirMode, when not 0, replace the permissions of the files and directories served.
+	FileMode uint32
+	DirMode  uint32
+	// Msize is the maximum size of the 9P messages, the default one when 0.
+	Msize uint32
+}
+
+// mappedUsers serves every file as owned by the same user and group.
+type mappedUsers struct {
+	go9p.Users
+	uid int
+	gid int
+}
+
+func (m mappedUsers) Uid2User(uid int) go9p.User {
+	if m.uid >= 0 {
+		uid = m.uid
+	}
+	return m.Users.Uid2User(uid)
+}
+
+func (m mappedUsers) Gid2Group(gid int) go9p.Group {
+	if m.gid >= 0 {
+		gid = m.gid
+	}
+	return m.Users.Gid2Group(gid)
+}
+
+func StartServer(addrVal string, debugVal int, rootVal string, opts Options) {
 	ufs := new(go9p.Ufs)
 	ufs.Dotu = true
 	ufs.Id = "ufs"
 	ufs.Root = rootVal
 	ufs.Debuglevel = debugVal
+	ufs.Msize = opts.Msize
+	ufs.FileMode = opts.FileMode
+	ufs.DirMode = opts.DirMode
+	ufs.Upool = mappedUsers{Users: go9p.OsUsers, uid: opts.UID, gid: opts.GID}
 	ufs.Start(ufs)
 
 	fmt.Print("ufs starting\n")